@@ -9,7 +9,6 @@ import (
 	"net/http"
 	"os"
 	"os/exec"
-	"strings"
 	"sync"
 	"time"
 )
@@ -19,6 +18,25 @@ var (
 	commit  = "none"
 )
 
+// agentToken is handed out by mkube with the job and authenticates the
+// heartbeat, log and completion calls.
+var (
+	agentTokenMu sync.Mutex
+	agentToken   string
+)
+
+func setAgentToken(t string) {
+	agentTokenMu.Lock()
+	agentToken = t
+	agentTokenMu.Unlock()
+}
+
+func currentAgentToken() string {
+	agentTokenMu.Lock()
+	defer agentTokenMu.Unlock()
+	return agentToken
+}
+
 // agentJob mirrors the Job type from mkube, with only the fields the agent needs.
 type agentJob struct {
 	Metadata struct {
@@ -106,16 +124,51 @@ func pollForWork(apiURL string) (*agentJob, error) {
 			return nil, fmt.Errorf("decoding job: %w", err)
 		}
 		resp.Body.Close()
+		setAgentToken(resp.Header.Get("X-Agent-Token"))
 		return &job, nil
 	}
 
 	return nil, fmt.Errorf("gave up after %d attempts", maxRetries)
 }
 
+// refreshAgentToken fetches a new token for the running job, needed when
+// mkube restarted and forgot the tokens it handed out.
+func refreshAgentToken(client *http.Client, apiURL string) {
+	resp, err := client.Get(apiURL + "/api/v1/agent/work")
+	if err != nil {
+		log.Printf("token refresh error: %v", err)
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		setAgentToken(resp.Header.Get("X-Agent-Token"))
+	}
+}
+
+// agentPost sends an authenticated POST to mkube, refreshing the agent token
+// and retrying once if it is rejected.
+func agentPost(client *http.Client, apiURL, path, contentType string, body []byte) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequest(http.MethodPost, apiURL+path, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", contentType)
+		if t := currentAgentToken(); t != "" {
+			req.Header.Set("Authorization", "Bearer "+t)
+		}
+		resp, err := client.Do(req)
+		if err != nil || resp.StatusCode != http.StatusUnauthorized || attempt > 0 {
+			return resp, err
+		}
+		resp.Body.Close()
+		refreshAgentToken(client, apiURL)
+	}
+}
+
 // heartbeat sends periodic heartbeats to mkube.
 func heartbeat(apiURL string, stop <-chan struct{}) {
 	client := &http.Client{Timeout: 5 * time.Second}
-	url := apiURL + "/api/v1/agent/heartbeat"
 	ticker := time.NewTicker(30 * time.Second)
 	defer ticker.Stop()
 
@@ -124,7 +177,7 @@ func heartbeat(apiURL string, stop <-chan struct{}) {
 		case <-stop:
 			return
 		case <-ticker.C:
-			resp, err := client.Post(url, "application/json", strings.NewReader("{}"))
+			resp, err := agentPost(client, apiURL, "/api/v1/agent/heartbeat", "application/json", []byte("{}"))
 			if err != nil {
 				log.Printf("heartbeat error: %v", err)
 				continue
@@ -185,7 +238,6 @@ func executeScript(apiURL string, job *agentJob) (int, error) {
 // streamLogs reads from the pipe and sends log chunks to mkube.
 func streamLogs(apiURL string, r io.Reader) {
 	client := &http.Client{Timeout: 10 * time.Second}
-	url := apiURL + "/api/v1/agent/logs"
 
 	buf := make([]byte, 4096)
 	var batch []byte
@@ -194,7 +246,7 @@ func streamLogs(apiURL string, r io.Reader) {
 		if len(batch) == 0 {
 			return
 		}
-		resp, err := client.Post(url, "text/plain", bytes.NewReader(batch))
+		resp, err := agentPost(client, apiURL, "/api/v1/agent/logs", "text/plain", batch)
 		if err != nil {
 			log.Printf("log stream error: %v", err)
 		} else {
@@ -237,7 +289,6 @@ func streamLogs(apiURL string, r io.Reader) {
 // reportComplete sends the exit code to mkube.
 func reportComplete(apiURL string, exitCode int, execErr error) {
 	client := &http.Client{Timeout: 10 * time.Second}
-	url := apiURL + "/api/v1/agent/complete"
 
 	errMsg := ""
 	if execErr != nil {
@@ -249,7 +300,7 @@ func reportComplete(apiURL string, exitCode int, execErr error) {
 		"errorMessage": errMsg,
 	})
 
-	resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+	resp, err := agentPost(client, apiURL, "/api/v1/agent/complete", "application/json", body)
 	if err != nil {
 		log.Printf("complete report error: %v", err)
 		return
@@ -1,5 +1,6 @@
 // mkube: A single-binary Virtual Kubelet provider for heterogeneous clusters.
-// Supports MikroTik RouterOS (REST API), StormBase (gRPC), and Proxmox VE (REST API) backends.
+// Supports MikroTik RouterOS (REST API), StormBase (gRPC), Linux (stormd
+// workloads on a netlink-managed host network), and Proxmox VE (REST API) backends.
 
 package main
 
@@ -17,6 +18,7 @@ import (
 
 	"github.com/glennswest/mkube/pkg/cluster"
 	"github.com/glennswest/mkube/pkg/config"
+	"github.com/glennswest/mkube/pkg/dashboard"
 	"github.com/glennswest/mkube/pkg/discovery"
 	"github.com/glennswest/mkube/pkg/dns"
 	"github.com/glennswest/mkube/pkg/dzo"
@@ -53,7 +55,7 @@ func main() {
 	f.String("kubeconfig", "", "Path to kubeconfig (optional, for standalone mode)")
 	f.String("node-name", "mkube-node", "Kubernetes node name for this device")
 	f.Bool("standalone", false, "Run without a Kubernetes API server (local reconciler only)")
-	f.String("backend", "", "Backend type: routeros (default) or stormbase")
+	f.String("backend", "", "Backend type: routeros (default), stormbase, linux or proxmox")
 
 	// RouterOS connection
 	f.String("routeros-address", "192.168.200.1:8728", "RouterOS API address")
@@ -102,7 +104,7 @@ func run(cmd *cobra.Command, args []string) error {
 	if cfg.IsProxmox() {
 		return runProxmox(ctx, cfg, log)
 	}
-	if cfg.IsStormBase() {
+	if cfg.IsStormBase() || cfg.IsLinux() {
 		return runStormBase(ctx, cfg, log)
 	}
 	return runRouterOS(ctx, cfg, log)
@@ -215,7 +217,9 @@ func runRouterOS(ctx context.Context, cfg *config.Config, log *zap.SugaredLogger
 	return runSharedServices(ctx, cfg, rt, netMgr, storageMgr, lcMgr, dnsClient, rosClient, log, bootStart)
 }
 
-// runStormBase initializes the StormBase gRPC backend.
+// runStormBase initializes the StormBase gRPC backend. The linux backend
+// takes the same path but programs the host network itself with the Linux
+// driver, and runs each workload in a named network namespace.
 func runStormBase(ctx context.Context, cfg *config.Config, log *zap.SugaredLogger) error {
 	// ── StormBase gRPC Client ───────────────────────────────────────
 	sbClient, err := stormbase.NewClient(stormbase.ClientConfig{
@@ -246,13 +250,24 @@ func runStormBase(ctx context.Context, cfg *config.Config, log *zap.SugaredLogge
 	}
 
 	// ── Network Driver ──────────────────────────────────────────────
-	sbDriver := netdriver.NewStormBase(sbClient, cfg.NodeName, log)
+	var driver network.NetworkDriver = netdriver.NewStormBase(sbClient, cfg.NodeName, log)
+	var rt runtime.ContainerRuntime = sbClient
+	if cfg.IsLinux() {
+		driver = netdriver.NewLinux(cfg.NodeName, log)
+		rt = runtime.NewNamedNetNS(sbClient)
+	}
 
 	// ── Network Manager (IPAM + veth + DNS) ─────────────────────────
-	netMgr, err := network.NewManager(cfg.Networks, sbDriver, dnsClient, log)
+	netMgr, err := network.NewManager(cfg.Networks, driver, dnsClient, log)
 	if err != nil {
 		return fmt.Errorf("initializing network manager: %w", err)
 	}
+	if cfg.IsLinux() {
+		if err := netMgr.ProvisionHost(ctx, cfg.Linux.ACLs); err != nil {
+			return fmt.Errorf("provisioning host network: %w", err)
+		}
+		log.Infow("host network provisioned", "acls", len(cfg.Linux.ACLs))
+	}
 	netMgr.InitDNSZones(ctx)
 	for _, n := range cfg.Networks {
 		log.Infow("network ready", "name", n.Name, "cidr", n.CIDR, "dns_zone", n.DNS.Zone)
@@ -280,7 +295,7 @@ func runStormBase(ctx context.Context, cfg *config.Config, log *zap.SugaredLogge
 	go lcMgr.RunWatchdog(ctx)
 	log.Info("lifecycle manager ready (stormbase)")
 
-	return runSharedServices(ctx, cfg, sbClient, netMgr, storageMgr, lcMgr, dnsClient, nil, log, time.Now())
+	return runSharedServices(ctx, cfg, rt, netMgr, storageMgr, lcMgr, dnsClient, nil, log, time.Now())
 }
 
 // runProxmox initializes the Proxmox VE LXC backend.
@@ -513,14 +528,26 @@ func runSharedServices(
 		p.LoadRegistriesFromStore(ctx)
 		p.MigrateRegistryConfig(ctx)
 		p.LoadConfigMapsFromStore(ctx)
+		p.LoadSecretsFromStore(ctx)
 		p.LoadISCSICdromsFromStore(ctx)
 		p.LoadBootConfigsFromStore(ctx)
 		p.LoadHostReservationsFromStore(ctx)
+		p.LoadIPAddressClaimsFromStore(ctx)
+		p.LoadPortForwardsFromStore(ctx)
 		p.LoadJobRunnersFromStore(ctx)
 		p.LoadJobsFromStore(ctx)
+		p.LoadAlertRulesFromStore(ctx)
+		p.LoadWebhookConfigurationsFromStore(ctx)
+		netMgr.SetStore(kvStore)
 	}
+	p.StartFallbackDNS()
+	p.StartDNSUpdateServer()
+	go netMgr.RunLeaseRenewer(ctx)
 	go p.RunDHCPWatcher(ctx)
 	go p.RunJobScheduler(ctx)
+	go p.RunAlertEngine(ctx)
+	go p.RunGarbageCollector(ctx)
+	go p.RunPortForwards(ctx)
 	p.StartInfraHealthWatchers(ctx)
 	p.StartISOScanner(ctx, 30*time.Second)
 
@@ -532,15 +559,22 @@ func runSharedServices(
 		case "proxmox", "stormbase":
 			arch = "amd64"
 		}
+		if cfg.Cluster.Token == "" {
+			log.Warnw("cluster.token is not set: peer sync is unauthenticated", "apiTokens", len(cfg.API.Tokens) > 0)
+		}
 		clusterMgr := cluster.New(cfg.NodeName, cfg.Cluster, kvStore, arch, log)
 		clusterMgr.Start(ctx)
 		p.SetClusterManager(clusterMgr)
 		clusterMgr.RegisterRoutes(mux)
+		go p.RunOverlayMesh(ctx)
 		log.Infow("BOOT: cluster manager started", "peers", len(cfg.Cluster.Peers), "arch", arch)
 	}
 
 	// ── Register routes and start HTTP server ───────────────────────
 	p.RegisterRoutes(mux)
+	if cfg.Dashboard.Enabled {
+		dashboard.RegisterRoutes(mux)
+	}
 
 	go func() {
 		srv := &http.Server{
@@ -582,6 +616,7 @@ func runSharedServices(
 					log.Warnw("NATS migration failed", "error", err)
 				}
 				p.SetStore(s)
+				netMgr.SetStore(s)
 				if nsMgr != nil {
 					nsMgr.SetStore(s)
 				}
@@ -0,0 +1,171 @@
+// Package admission implements mkube's admission control: in-process policy
+// plugins and calls to external Mutating/Validating admission webhooks.
+//
+// Plugins are compiled in and enabled by name through config
+// (admission.plugins). Out-of-tree plugins register themselves with
+// Register from an init function, the same way the built-ins do.
+package admission
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/glennswest/mkube/pkg/config"
+)
+
+// Operation is the kind of write being admitted.
+type Operation string
+
+const (
+	Create Operation = "CREATE"
+	Update Operation = "UPDATE"
+	Delete Operation = "DELETE"
+)
+
+// Attributes describe a write request under admission. Objects are the
+// decoded JSON of the resource so plugins can handle any kind.
+type Attributes struct {
+	Operation Operation
+	Kind      string // e.g. "Pod"
+	Resource  string // e.g. "pods"
+	Namespace string // empty for cluster-scoped resources
+	Name      string
+	User      string                 // API token name, empty when auth is disabled
+	Object    map[string]interface{} // nil on DELETE; mutating plugins edit it in place
+	OldObject map[string]interface{} // nil on CREATE
+}
+
+// Plugin is an in-process admission policy. A plugin implements Mutator,
+// Validator, or both.
+type Plugin interface {
+	Name() string
+}
+
+// Mutator modifies objects before they are validated and stored.
+type Mutator interface {
+	Plugin
+	Admit(ctx context.Context, a *Attributes) error
+}
+
+// Validator accepts or rejects objects. A non-nil error denies the request.
+type Validator interface {
+	Plugin
+	Validate(ctx context.Context, a *Attributes) error
+}
+
+// Env is what plugins may depend on when they are constructed.
+type Env struct {
+	Config config.AdmissionConfig
+	// NamespaceNetwork returns the network a namespace is attached to, or "".
+	NamespaceNetwork func(namespace string) string
+}
+
+// Factory builds a plugin instance.
+type Factory func(env Env) (Plugin, error)
+
+var (
+	registryMu sync.Mutex
+	registry   = map[string]Factory{}
+)
+
+// Register makes a plugin available under name. It panics on duplicates,
+// since that can only be a programming error.
+func Register(name string, f Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, dup := registry[name]; dup {
+		panic("admission: plugin registered twice: " + name)
+	}
+	registry[name] = f
+}
+
+// Registered returns the names of all registered plugins, sorted.
+func Registered() []string {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Chain is an ordered set of enabled plugins.
+type Chain struct {
+	mutators   []Mutator
+	validators []Validator
+}
+
+// NewChain instantiates the plugins enabled in env.Config, in config order.
+func NewChain(env Env) (*Chain, error) {
+	c := &Chain{}
+	for _, name := range env.Config.Plugins {
+		registryMu.Lock()
+		f, ok := registry[name]
+		registryMu.Unlock()
+		if !ok {
+			return nil, fmt.Errorf("unknown admission plugin %q (available: %v)", name, Registered())
+		}
+		pl, err := f(env)
+		if err != nil {
+			return nil, fmt.Errorf("admission plugin %q: %w", name, err)
+		}
+		m, isMutator := pl.(Mutator)
+		v, isValidator := pl.(Validator)
+		if !isMutator && !isValidator {
+			return nil, fmt.Errorf("admission plugin %q implements neither Mutator nor Validator", name)
+		}
+		if isMutator {
+			c.mutators = append(c.mutators, m)
+		}
+		if isValidator {
+			c.validators = append(c.validators, v)
+		}
+	}
+	return c, nil
+}
+
+// Empty reports whether the chain has no plugins.
+func (c *Chain) Empty() bool {
+	return c == nil || len(c.mutators)+len(c.validators) == 0
+}
+
+// Admit runs every mutating plugin in order.
+func (c *Chain) Admit(ctx context.Context, a *Attributes) error {
+	if c == nil {
+		return nil
+	}
+	for _, m := range c.mutators {
+		if err := m.Admit(ctx, a); err != nil {
+			return &DeniedError{Source: "plugin " + m.Name(), Message: err.Error()}
+		}
+	}
+	return nil
+}
+
+// Validate runs every validating plugin in order, stopping at the first denial.
+func (c *Chain) Validate(ctx context.Context, a *Attributes) error {
+	if c == nil {
+		return nil
+	}
+	for _, v := range c.validators {
+		if err := v.Validate(ctx, a); err != nil {
+			return &DeniedError{Source: "plugin " + v.Name(), Message: err.Error()}
+		}
+	}
+	return nil
+}
+
+// DeniedError is returned when a plugin or webhook rejects a request.
+type DeniedError struct {
+	Source  string // e.g. `plugin ForbidLatestTag` or `webhook "policy.example.com"`
+	Message string
+	Code    int // HTTP status from the webhook's result, 0 if unset
+}
+
+func (e *DeniedError) Error() string {
+	return fmt.Sprintf("admission %s denied the request: %s", e.Source, e.Message)
+}
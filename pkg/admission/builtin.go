@@ -0,0 +1,188 @@
+package admission
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Built-in plugin names, for use in admission.plugins.
+const (
+	PluginNamespaceNetworkDefault = "NamespaceNetworkDefault"
+	PluginForbidLatestTag         = "ForbidLatestTag"
+	PluginRequireBMHOwner         = "RequireBMHOwner"
+)
+
+// annotationNetwork selects the network a pod is attached to.
+const annotationNetwork = "vkube.io/network"
+
+func init() {
+	Register(PluginNamespaceNetworkDefault, func(env Env) (Plugin, error) {
+		return &namespaceNetworkDefault{overrides: env.Config.NamespaceNetworks, lookup: env.NamespaceNetwork}, nil
+	})
+	Register(PluginForbidLatestTag, func(env Env) (Plugin, error) {
+		dev := make(map[string]bool, len(env.Config.DevNamespaces))
+		for _, ns := range env.Config.DevNamespaces {
+			dev[ns] = true
+		}
+		return &forbidLatestTag{devNamespaces: dev}, nil
+	})
+	Register(PluginRequireBMHOwner, func(env Env) (Plugin, error) {
+		if env.Config.OwnerLabel == "" {
+			return nil, fmt.Errorf("admission.ownerLabel is empty")
+		}
+		return &requireBMHOwner{label: env.Config.OwnerLabel}, nil
+	})
+}
+
+// ─── NamespaceNetworkDefault ────────────────────────────────────────────────
+
+// namespaceNetworkDefault sets the vkube.io/network annotation on pods and
+// deployment templates that don't specify one, using the namespace's network.
+type namespaceNetworkDefault struct {
+	overrides map[string]string
+	lookup    func(namespace string) string
+}
+
+func (p *namespaceNetworkDefault) Name() string { return PluginNamespaceNetworkDefault }
+
+func (p *namespaceNetworkDefault) Admit(_ context.Context, a *Attributes) error {
+	if a.Operation == Delete || a.Object == nil {
+		return nil
+	}
+
+	var metaPath []string
+	switch a.Kind {
+	case "Pod":
+		metaPath = []string{"metadata"}
+	case "Deployment":
+		metaPath = []string{"spec", "template", "metadata"}
+	default:
+		return nil
+	}
+	annPath := append(metaPath, "annotations")
+	if nestedString(a.Object, append(annPath, annotationNetwork)...) != "" {
+		return nil
+	}
+
+	network := p.overrides[a.Namespace]
+	if network == "" && p.lookup != nil {
+		network = p.lookup(a.Namespace)
+	}
+	if network != "" {
+		nestedMap(a.Object, annPath...)[annotationNetwork] = network
+	}
+	return nil
+}
+
+// ─── ForbidLatestTag ────────────────────────────────────────────────────────
+
+// forbidLatestTag rejects pods and deployments whose images use the latest
+// tag, explicitly or by omitting a tag, outside the configured dev namespaces.
+type forbidLatestTag struct {
+	devNamespaces map[string]bool
+}
+
+func (p *forbidLatestTag) Name() string { return PluginForbidLatestTag }
+
+func (p *forbidLatestTag) Validate(_ context.Context, a *Attributes) error {
+	if a.Operation == Delete || a.Object == nil || p.devNamespaces[a.Namespace] {
+		return nil
+	}
+
+	var podSpec map[string]interface{}
+	switch a.Kind {
+	case "Pod":
+		podSpec = nestedValue(a.Object, "spec")
+	case "Deployment":
+		podSpec = nestedValue(a.Object, "spec", "template", "spec")
+	}
+	if podSpec == nil {
+		return nil
+	}
+
+	for _, field := range []string{"initContainers", "containers"} {
+		containers, _ := podSpec[field].([]interface{})
+		for _, c := range containers {
+			cm, _ := c.(map[string]interface{})
+			image, _ := cm["image"].(string)
+			if image != "" && usesLatestTag(image) {
+				name, _ := cm["name"].(string)
+				return fmt.Errorf("container %q uses image %q: the latest tag is only allowed in dev namespaces", name, image)
+			}
+		}
+	}
+	return nil
+}
+
+// usesLatestTag reports whether an image reference resolves to :latest.
+// Digest references are pinned and never count.
+func usesLatestTag(image string) bool {
+	if strings.Contains(image, "@") {
+		return false
+	}
+	last := image[strings.LastIndex(image, "/")+1:]
+	i := strings.LastIndex(last, ":")
+	if i < 0 {
+		return true
+	}
+	return last[i+1:] == "latest"
+}
+
+// ─── RequireBMHOwner ────────────────────────────────────────────────────────
+
+// requireBMHOwner rejects BareMetalHosts without a non-empty owner label.
+type requireBMHOwner struct {
+	label string
+}
+
+func (p *requireBMHOwner) Name() string { return PluginRequireBMHOwner }
+
+func (p *requireBMHOwner) Validate(_ context.Context, a *Attributes) error {
+	if a.Kind != "BareMetalHost" || a.Operation == Delete || a.Object == nil {
+		return nil
+	}
+	if nestedString(a.Object, "metadata", "labels", p.label) == "" {
+		return fmt.Errorf("BareMetalHost must have a %q label", p.label)
+	}
+	return nil
+}
+
+// ─── Helpers ────────────────────────────────────────────────────────────────
+
+// nestedValue walks obj along path, returning nil if any step is missing.
+func nestedValue(obj map[string]interface{}, path ...string) map[string]interface{} {
+	cur := obj
+	for _, key := range path {
+		next, ok := cur[key].(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		cur = next
+	}
+	return cur
+}
+
+// nestedString returns the string at path, or "".
+func nestedString(obj map[string]interface{}, path ...string) string {
+	if len(path) == 0 {
+		return ""
+	}
+	parent := nestedValue(obj, path[:len(path)-1]...)
+	s, _ := parent[path[len(path)-1]].(string)
+	return s
+}
+
+// nestedMap walks obj along path, creating empty maps where missing.
+func nestedMap(obj map[string]interface{}, path ...string) map[string]interface{} {
+	cur := obj
+	for _, key := range path {
+		next, ok := cur[key].(map[string]interface{})
+		if !ok {
+			next = map[string]interface{}{}
+			cur[key] = next
+		}
+		cur = next
+	}
+	return cur
+}
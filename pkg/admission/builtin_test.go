@@ -0,0 +1,146 @@
+package admission
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/glennswest/mkube/pkg/config"
+)
+
+func newTestChain(t *testing.T, plugins ...string) *Chain {
+	t.Helper()
+	c, err := NewChain(Env{
+		Config: config.AdmissionConfig{
+			Plugins:           plugins,
+			NamespaceNetworks: map[string]string{"web": "dmz"},
+			DevNamespaces:     []string{"dev"},
+			OwnerLabel:        "owner",
+		},
+		NamespaceNetwork: func(ns string) string {
+			if ns == "infra" {
+				return "gt"
+			}
+			return ""
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewChain: %v", err)
+	}
+	return c
+}
+
+func TestNewChainUnknownPlugin(t *testing.T) {
+	_, err := NewChain(Env{Config: config.AdmissionConfig{Plugins: []string{"Nope"}}})
+	if err == nil || !strings.Contains(err.Error(), `unknown admission plugin "Nope"`) {
+		t.Fatalf("expected unknown plugin error, got %v", err)
+	}
+}
+
+func TestNamespaceNetworkDefault(t *testing.T) {
+	c := newTestChain(t, PluginNamespaceNetworkDefault)
+
+	tests := []struct {
+		name string
+		a    Attributes
+		path []string
+		want string
+	}{
+		{"from namespace", Attributes{Operation: Create, Kind: "Pod", Namespace: "infra",
+			Object: map[string]interface{}{"metadata": map[string]interface{}{"name": "p"}}},
+			[]string{"metadata", "annotations", annotationNetwork}, "gt"},
+		{"config override", Attributes{Operation: Create, Kind: "Pod", Namespace: "web",
+			Object: map[string]interface{}{}},
+			[]string{"metadata", "annotations", annotationNetwork}, "dmz"},
+		{"explicit kept", Attributes{Operation: Update, Kind: "Pod", Namespace: "infra",
+			Object: map[string]interface{}{"metadata": map[string]interface{}{
+				"annotations": map[string]interface{}{annotationNetwork: "mine"}}}},
+			[]string{"metadata", "annotations", annotationNetwork}, "mine"},
+		{"deployment template", Attributes{Operation: Create, Kind: "Deployment", Namespace: "infra",
+			Object: map[string]interface{}{"spec": map[string]interface{}{}}},
+			[]string{"spec", "template", "metadata", "annotations", annotationNetwork}, "gt"},
+		{"unknown namespace", Attributes{Operation: Create, Kind: "Pod", Namespace: "other",
+			Object: map[string]interface{}{}},
+			[]string{"metadata", "annotations", annotationNetwork}, ""},
+	}
+	for _, tt := range tests {
+		a := tt.a
+		if err := c.Admit(context.Background(), &a); err != nil {
+			t.Fatalf("%s: Admit: %v", tt.name, err)
+		}
+		if got := nestedString(a.Object, tt.path...); got != tt.want {
+			t.Errorf("%s: network = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestForbidLatestTag(t *testing.T) {
+	c := newTestChain(t, PluginForbidLatestTag)
+
+	pod := func(ns, image string) *Attributes {
+		return &Attributes{Operation: Create, Kind: "Pod", Namespace: ns, Object: map[string]interface{}{
+			"spec": map[string]interface{}{"containers": []interface{}{
+				map[string]interface{}{"name": "app", "image": image},
+			}},
+		}}
+	}
+
+	tests := []struct {
+		a      *Attributes
+		denied bool
+	}{
+		{pod("prod", "registry:5000/app:latest"), true},
+		{pod("prod", "registry:5000/app"), true},
+		{pod("prod", "registry:5000/app:1.2"), false},
+		{pod("prod", "registry:5000/app@sha256:abc"), false},
+		{pod("dev", "registry:5000/app:latest"), false},
+	}
+	for _, tt := range tests {
+		err := c.Validate(context.Background(), tt.a)
+		image := tt.a.Object["spec"].(map[string]interface{})["containers"].([]interface{})[0].(map[string]interface{})["image"]
+		if tt.denied != (err != nil) {
+			t.Errorf("%s in %s: denied=%v, want %v (%v)", image, tt.a.Namespace, err != nil, tt.denied, err)
+		}
+		var denied *DeniedError
+		if err != nil && !errors.As(err, &denied) {
+			t.Errorf("expected *DeniedError, got %T", err)
+		}
+	}
+}
+
+func TestUsesLatestTag(t *testing.T) {
+	tests := map[string]bool{
+		"nginx":                      true,
+		"nginx:latest":               true,
+		"nginx:1.25":                 false,
+		"registry:5000/nginx":        true,
+		"registry:5000/nginx:stable": false,
+		"nginx@sha256:0123":          false,
+	}
+	for image, want := range tests {
+		if got := usesLatestTag(image); got != want {
+			t.Errorf("usesLatestTag(%q) = %v, want %v", image, got, want)
+		}
+	}
+}
+
+func TestRequireBMHOwner(t *testing.T) {
+	c := newTestChain(t, PluginRequireBMHOwner)
+
+	bmh := &Attributes{Operation: Create, Kind: "BareMetalHost", Namespace: "default",
+		Object: map[string]interface{}{"metadata": map[string]interface{}{"name": "server1"}}}
+	if err := c.Validate(context.Background(), bmh); err == nil {
+		t.Error("expected BMH without owner label to be denied")
+	}
+
+	bmh.Object["metadata"].(map[string]interface{})["labels"] = map[string]interface{}{"owner": "team-a"}
+	if err := c.Validate(context.Background(), bmh); err != nil {
+		t.Errorf("expected labeled BMH to be admitted, got %v", err)
+	}
+
+	del := &Attributes{Operation: Delete, Kind: "BareMetalHost", Namespace: "default", Name: "server1"}
+	if err := c.Validate(context.Background(), del); err != nil {
+		t.Errorf("deletes must not be checked, got %v", err)
+	}
+}
@@ -0,0 +1,252 @@
+package admission
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// jsonPatchOp is one RFC 6902 operation, as returned by mutating webhooks.
+type jsonPatchOp struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// ApplyJSONPatch applies an RFC 6902 JSON Patch to obj and returns the
+// patched document. obj is not modified if the patch fails.
+func ApplyJSONPatch(obj map[string]interface{}, patch []byte) (map[string]interface{}, error) {
+	var ops []jsonPatchOp
+	if err := json.Unmarshal(patch, &ops); err != nil {
+		return nil, fmt.Errorf("decoding JSON patch: %w", err)
+	}
+
+	// Work on a copy so a failing op leaves the original untouched
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	var doc interface{}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+
+	for i, op := range ops {
+		var value interface{}
+		if op.Op == "add" || op.Op == "replace" || op.Op == "test" {
+			if len(op.Value) == 0 {
+				return nil, fmt.Errorf("patch op %d (%s): missing value", i, op.Op)
+			}
+			if err := json.Unmarshal(op.Value, &value); err != nil {
+				return nil, fmt.Errorf("patch op %d: decoding value: %w", i, err)
+			}
+		}
+
+		switch op.Op {
+		case "add":
+			doc, err = patchAdd(doc, op.Path, value)
+		case "remove":
+			doc, _, err = patchRemove(doc, op.Path)
+		case "replace":
+			if doc, _, err = patchRemove(doc, op.Path); err == nil {
+				doc, err = patchAdd(doc, op.Path, value)
+			}
+		case "move":
+			var moved interface{}
+			if doc, moved, err = patchRemove(doc, op.From); err == nil {
+				doc, err = patchAdd(doc, op.Path, moved)
+			}
+		case "copy":
+			var src interface{}
+			if src, err = patchGet(doc, op.From); err == nil {
+				doc, err = patchAdd(doc, op.Path, deepCopyJSON(src))
+			}
+		case "test":
+			var cur interface{}
+			if cur, err = patchGet(doc, op.Path); err == nil && !reflect.DeepEqual(cur, value) {
+				err = fmt.Errorf("test failed at %s", op.Path)
+			}
+		default:
+			err = fmt.Errorf("unsupported op %q", op.Op)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("patch op %d (%s %s): %w", i, op.Op, op.Path, err)
+		}
+	}
+
+	out, ok := doc.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("patched document is not an object")
+	}
+	return out, nil
+}
+
+// splitPointer decodes an RFC 6901 JSON pointer into reference tokens.
+func splitPointer(ptr string) ([]string, error) {
+	if ptr == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(ptr, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer %q", ptr)
+	}
+	parts := strings.Split(ptr[1:], "/")
+	for i, p := range parts {
+		parts[i] = strings.ReplaceAll(strings.ReplaceAll(p, "~1", "/"), "~0", "~")
+	}
+	return parts, nil
+}
+
+func arrayIndex(tok string, n int, allowEnd bool) (int, error) {
+	if allowEnd && tok == "-" {
+		return n, nil
+	}
+	idx, err := strconv.Atoi(tok)
+	if err != nil || idx < 0 || idx > n || (!allowEnd && idx == n) {
+		return 0, fmt.Errorf("array index %q out of range", tok)
+	}
+	return idx, nil
+}
+
+func patchGet(doc interface{}, ptr string) (interface{}, error) {
+	toks, err := splitPointer(ptr)
+	if err != nil {
+		return nil, err
+	}
+	cur := doc
+	for _, tok := range toks {
+		switch c := cur.(type) {
+		case map[string]interface{}:
+			v, ok := c[tok]
+			if !ok {
+				return nil, fmt.Errorf("path %s not found", ptr)
+			}
+			cur = v
+		case []interface{}:
+			idx, err := arrayIndex(tok, len(c), false)
+			if err != nil {
+				return nil, err
+			}
+			cur = c[idx]
+		default:
+			return nil, fmt.Errorf("path %s not found", ptr)
+		}
+	}
+	return cur, nil
+}
+
+// patchAdd sets value at ptr, inserting into arrays. It returns the new
+// root, since replacing the root or growing an array allocates.
+func patchAdd(doc interface{}, ptr string, value interface{}) (interface{}, error) {
+	toks, err := splitPointer(ptr)
+	if err != nil {
+		return nil, err
+	}
+	if len(toks) == 0 {
+		return value, nil
+	}
+	return setIn(doc, toks, func(parent interface{}, last string) (interface{}, error) {
+		switch c := parent.(type) {
+		case map[string]interface{}:
+			c[last] = value
+			return c, nil
+		case []interface{}:
+			idx, err := arrayIndex(last, len(c), true)
+			if err != nil {
+				return nil, err
+			}
+			c = append(c, nil)
+			copy(c[idx+1:], c[idx:])
+			c[idx] = value
+			return c, nil
+		}
+		return nil, fmt.Errorf("parent of %s is not a container", ptr)
+	})
+}
+
+// patchRemove deletes the value at ptr and returns the new root and the
+// removed value.
+func patchRemove(doc interface{}, ptr string) (interface{}, interface{}, error) {
+	toks, err := splitPointer(ptr)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(toks) == 0 {
+		return nil, nil, fmt.Errorf("cannot remove the document root")
+	}
+	var removed interface{}
+	root, err := setIn(doc, toks, func(parent interface{}, last string) (interface{}, error) {
+		switch c := parent.(type) {
+		case map[string]interface{}:
+			v, ok := c[last]
+			if !ok {
+				return nil, fmt.Errorf("path %s not found", ptr)
+			}
+			removed = v
+			delete(c, last)
+			return c, nil
+		case []interface{}:
+			idx, err := arrayIndex(last, len(c), false)
+			if err != nil {
+				return nil, err
+			}
+			removed = c[idx]
+			return append(c[:idx], c[idx+1:]...), nil
+		}
+		return nil, fmt.Errorf("parent of %s is not a container", ptr)
+	})
+	return root, removed, err
+}
+
+// setIn walks to the parent of toks and lets fn replace it, propagating the
+// (possibly reallocated) container back up to the root.
+func setIn(doc interface{}, toks []string, fn func(parent interface{}, last string) (interface{}, error)) (interface{}, error) {
+	if len(toks) == 1 {
+		return fn(doc, toks[0])
+	}
+	switch c := doc.(type) {
+	case map[string]interface{}:
+		child, ok := c[toks[0]]
+		if !ok {
+			return nil, fmt.Errorf("path element %q not found", toks[0])
+		}
+		nc, err := setIn(child, toks[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		c[toks[0]] = nc
+		return c, nil
+	case []interface{}:
+		idx, err := arrayIndex(toks[0], len(c), false)
+		if err != nil {
+			return nil, err
+		}
+		nc, err := setIn(c[idx], toks[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		c[idx] = nc
+		return c, nil
+	}
+	return nil, fmt.Errorf("path element %q is not a container", toks[0])
+}
+
+func deepCopyJSON(v interface{}) interface{} {
+	switch c := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(c))
+		for k, val := range c {
+			out[k] = deepCopyJSON(val)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(c))
+		for i, val := range c {
+			out[i] = deepCopyJSON(val)
+		}
+		return out
+	}
+	return v
+}
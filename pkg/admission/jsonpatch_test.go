@@ -0,0 +1,71 @@
+package admission
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestApplyJSONPatch(t *testing.T) {
+	doc := func() map[string]interface{} {
+		var m map[string]interface{}
+		_ = json.Unmarshal([]byte(`{"metadata":{"name":"p","labels":{"a/b":"1"}},"spec":{"containers":[{"name":"c1"},{"name":"c2"}]}}`), &m)
+		return m
+	}
+
+	tests := []struct {
+		name  string
+		patch string
+		want  string
+	}{
+		{"add annotation map",
+			`[{"op":"add","path":"/metadata/annotations","value":{"x":"y"}}]`,
+			`{"metadata":{"name":"p","labels":{"a/b":"1"},"annotations":{"x":"y"}},"spec":{"containers":[{"name":"c1"},{"name":"c2"}]}}`},
+		{"escaped key",
+			`[{"op":"replace","path":"/metadata/labels/a~1b","value":"2"}]`,
+			`{"metadata":{"name":"p","labels":{"a/b":"2"}},"spec":{"containers":[{"name":"c1"},{"name":"c2"}]}}`},
+		{"append to array",
+			`[{"op":"add","path":"/spec/containers/-","value":{"name":"sidecar"}}]`,
+			`{"metadata":{"name":"p","labels":{"a/b":"1"}},"spec":{"containers":[{"name":"c1"},{"name":"c2"},{"name":"sidecar"}]}}`},
+		{"insert into array",
+			`[{"op":"add","path":"/spec/containers/0","value":{"name":"init"}}]`,
+			`{"metadata":{"name":"p","labels":{"a/b":"1"}},"spec":{"containers":[{"name":"init"},{"name":"c1"},{"name":"c2"}]}}`},
+		{"remove and move",
+			`[{"op":"remove","path":"/spec/containers/1"},{"op":"move","from":"/metadata/labels","path":"/spec/labels"}]`,
+			`{"metadata":{"name":"p"},"spec":{"containers":[{"name":"c1"}],"labels":{"a/b":"1"}}}`},
+		{"copy and test",
+			`[{"op":"test","path":"/metadata/name","value":"p"},{"op":"copy","from":"/metadata/name","path":"/spec/host"}]`,
+			`{"metadata":{"name":"p","labels":{"a/b":"1"}},"spec":{"containers":[{"name":"c1"},{"name":"c2"}],"host":"p"}}`},
+	}
+	for _, tt := range tests {
+		got, err := ApplyJSONPatch(doc(), []byte(tt.patch))
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		var want map[string]interface{}
+		_ = json.Unmarshal([]byte(tt.want), &want)
+		if !reflect.DeepEqual(got, want) {
+			g, _ := json.Marshal(got)
+			t.Errorf("%s:\n got %s\nwant %s", tt.name, g, tt.want)
+		}
+	}
+}
+
+func TestApplyJSONPatchErrorsLeaveOriginal(t *testing.T) {
+	orig := map[string]interface{}{"metadata": map[string]interface{}{"name": "p"}}
+
+	for _, patch := range []string{
+		`[{"op":"add","path":"/metadata/labels/x","value":"1"}]`,         // missing parent
+		`[{"op":"remove","path":"/spec"}]`,                               // missing path
+		`[{"op":"test","path":"/metadata/name","value":"q"}]`,            // failed test
+		`[{"op":"add","path":"/metadata/x","value":"1"},{"op":"bogus"}]`, // unsupported op after a change
+	} {
+		if _, err := ApplyJSONPatch(orig, []byte(patch)); err == nil {
+			t.Errorf("expected error for %s", patch)
+		}
+	}
+	if len(orig["metadata"].(map[string]interface{})) != 1 {
+		t.Errorf("original modified: %v", orig)
+	}
+}
@@ -0,0 +1,347 @@
+package admission
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	admissionv1 "k8s.io/api/admission/v1"
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	defaultWebhookTimeout = 10 * time.Second
+	maxWebhookTimeout     = 30 * time.Second
+)
+
+// Webhook is one entry of a Mutating- or ValidatingWebhookConfiguration,
+// normalized to the fields mkube acts on.
+type Webhook struct {
+	Name              string
+	Configuration     string // owning configuration name
+	ClientConfig      admissionregv1.WebhookClientConfig
+	Rules             []admissionregv1.RuleWithOperations
+	FailurePolicy     *admissionregv1.FailurePolicyType
+	NamespaceSelector *metav1.LabelSelector
+	ObjectSelector    *metav1.LabelSelector
+	TimeoutSeconds    *int32
+	MatchConditions   []admissionregv1.MatchCondition
+}
+
+// FromMutating normalizes a mutating webhook entry.
+func FromMutating(configuration string, w admissionregv1.MutatingWebhook) Webhook {
+	return Webhook{
+		Name: w.Name, Configuration: configuration, ClientConfig: w.ClientConfig,
+		Rules: w.Rules, FailurePolicy: w.FailurePolicy,
+		NamespaceSelector: w.NamespaceSelector, ObjectSelector: w.ObjectSelector,
+		TimeoutSeconds: w.TimeoutSeconds, MatchConditions: w.MatchConditions,
+	}
+}
+
+// FromValidating normalizes a validating webhook entry.
+func FromValidating(configuration string, w admissionregv1.ValidatingWebhook) Webhook {
+	return Webhook{
+		Name: w.Name, Configuration: configuration, ClientConfig: w.ClientConfig,
+		Rules: w.Rules, FailurePolicy: w.FailurePolicy,
+		NamespaceSelector: w.NamespaceSelector, ObjectSelector: w.ObjectSelector,
+		TimeoutSeconds: w.TimeoutSeconds, MatchConditions: w.MatchConditions,
+	}
+}
+
+// Check returns every reason the webhook can't be used by mkube.
+func (wh *Webhook) Check() []string {
+	var errs []string
+	if wh.Name == "" {
+		errs = append(errs, "name is required")
+	}
+	prefix := fmt.Sprintf("webhook %q: ", wh.Name)
+
+	if wh.ClientConfig.Service != nil {
+		errs = append(errs, prefix+"clientConfig.service is not supported, use clientConfig.url")
+	}
+	if wh.ClientConfig.URL == nil || *wh.ClientConfig.URL == "" {
+		errs = append(errs, prefix+"clientConfig.url is required")
+	} else if u, err := url.Parse(*wh.ClientConfig.URL); err != nil || u.Scheme != "https" || u.Host == "" {
+		errs = append(errs, prefix+"clientConfig.url must be an https:// URL")
+	}
+	if len(wh.ClientConfig.CABundle) > 0 && !x509.NewCertPool().AppendCertsFromPEM(wh.ClientConfig.CABundle) {
+		errs = append(errs, prefix+"clientConfig.caBundle contains no PEM certificates")
+	}
+
+	if fp := wh.FailurePolicy; fp != nil && *fp != admissionregv1.Fail && *fp != admissionregv1.Ignore {
+		errs = append(errs, prefix+fmt.Sprintf("unsupported failurePolicy %q", *fp))
+	}
+	if t := wh.TimeoutSeconds; t != nil && (*t < 1 || time.Duration(*t)*time.Second > maxWebhookTimeout) {
+		errs = append(errs, prefix+"timeoutSeconds must be between 1 and 30")
+	}
+	if len(wh.MatchConditions) > 0 {
+		errs = append(errs, prefix+"matchConditions are not supported")
+	}
+	for _, sel := range []*metav1.LabelSelector{wh.NamespaceSelector, wh.ObjectSelector} {
+		if _, err := metav1.LabelSelectorAsSelector(sel); err != nil {
+			errs = append(errs, prefix+err.Error())
+		}
+	}
+	for i, rule := range wh.Rules {
+		for _, op := range rule.Operations {
+			switch op {
+			case admissionregv1.OperationAll, admissionregv1.Create, admissionregv1.Update, admissionregv1.Delete:
+			default:
+				errs = append(errs, prefix+fmt.Sprintf("rules[%d]: unsupported operation %q", i, op))
+			}
+		}
+	}
+	return errs
+}
+
+// Timeout returns the configured call timeout, defaulting to 10s.
+func (wh *Webhook) Timeout() time.Duration {
+	if wh.TimeoutSeconds == nil || *wh.TimeoutSeconds <= 0 {
+		return defaultWebhookTimeout
+	}
+	if d := time.Duration(*wh.TimeoutSeconds) * time.Second; d < maxWebhookTimeout {
+		return d
+	}
+	return maxWebhookTimeout
+}
+
+// failOpen reports whether call errors should be ignored.
+func (wh *Webhook) failOpen() bool {
+	return wh.FailurePolicy != nil && *wh.FailurePolicy == admissionregv1.Ignore
+}
+
+// Matches reports whether the request falls under the webhook's rules and
+// selectors. namespaceLabels are the labels of the request's namespace; they
+// are ignored for cluster-scoped resources.
+func (wh *Webhook) Matches(a *Attributes, namespaceLabels map[string]string) bool {
+	ruleMatch := false
+	for _, rule := range wh.Rules {
+		if ruleMatches(rule, a) {
+			ruleMatch = true
+			break
+		}
+	}
+	if !ruleMatch {
+		return false
+	}
+
+	if a.Namespace != "" && wh.NamespaceSelector != nil {
+		sel, err := metav1.LabelSelectorAsSelector(wh.NamespaceSelector)
+		if err != nil || !sel.Matches(labels.Set(namespaceLabels)) {
+			return false
+		}
+	}
+
+	if wh.ObjectSelector != nil {
+		sel, err := metav1.LabelSelectorAsSelector(wh.ObjectSelector)
+		if err != nil {
+			return false
+		}
+		// Like Kubernetes, match if either the new or the old object matches
+		if !sel.Matches(objectLabels(a.Object)) && !sel.Matches(objectLabels(a.OldObject)) {
+			return false
+		}
+	}
+	return true
+}
+
+func ruleMatches(rule admissionregv1.RuleWithOperations, a *Attributes) bool {
+	opMatch := false
+	for _, op := range rule.Operations {
+		if op == admissionregv1.OperationAll || string(op) == string(a.Operation) {
+			opMatch = true
+			break
+		}
+	}
+	if !opMatch ||
+		!matchesAny(rule.APIGroups, "") ||
+		!matchesAny(rule.APIVersions, "v1") ||
+		!(matchesAny(rule.Resources, a.Resource) || containsString(rule.Resources, "*/*")) {
+		return false
+	}
+	if rule.Scope != nil {
+		switch *rule.Scope {
+		case admissionregv1.NamespacedScope:
+			return a.Namespace != ""
+		case admissionregv1.ClusterScope:
+			return a.Namespace == ""
+		}
+	}
+	return true
+}
+
+func matchesAny(list []string, v string) bool {
+	for _, s := range list {
+		if s == "*" || s == v {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+func objectLabels(obj map[string]interface{}) labels.Set {
+	set := labels.Set{}
+	for k, v := range nestedValue(obj, "metadata", "labels") {
+		if s, ok := v.(string); ok {
+			set[k] = s
+		}
+	}
+	return set
+}
+
+// Invoke calls the webhook with the request. For mutating webhooks a
+// returned JSON patch is applied to a.Object. It returns the webhook's
+// warnings, a *DeniedError if the webhook rejected the request, or another
+// error if the call failed and the failure policy is Fail.
+func (wh *Webhook) Invoke(ctx context.Context, a *Attributes, mutate bool) ([]string, error) {
+	resp, err := wh.call(ctx, a)
+	if err != nil {
+		if wh.failOpen() {
+			return []string{fmt.Sprintf("webhook %q failed and was ignored: %v", wh.Name, err)}, nil
+		}
+		return nil, fmt.Errorf("failed calling webhook %q: %w", wh.Name, err)
+	}
+
+	if !resp.Allowed {
+		denied := &DeniedError{Source: fmt.Sprintf("webhook %q", wh.Name), Message: "no reason given"}
+		if resp.Result != nil {
+			if resp.Result.Message != "" {
+				denied.Message = resp.Result.Message
+			}
+			denied.Code = int(resp.Result.Code)
+		}
+		return resp.Warnings, denied
+	}
+
+	if mutate && len(resp.Patch) > 0 {
+		if resp.PatchType == nil || *resp.PatchType != admissionv1.PatchTypeJSONPatch {
+			return nil, fmt.Errorf("webhook %q returned an unsupported patch type", wh.Name)
+		}
+		if a.Object == nil {
+			return nil, fmt.Errorf("webhook %q returned a patch for a request without an object", wh.Name)
+		}
+		patched, err := ApplyJSONPatch(a.Object, resp.Patch)
+		if err != nil {
+			return nil, fmt.Errorf("webhook %q returned an invalid patch: %w", wh.Name, err)
+		}
+		a.Object = patched
+	}
+	return resp.Warnings, nil
+}
+
+// call posts an AdmissionReview to the webhook and returns its response.
+func (wh *Webhook) call(ctx context.Context, a *Attributes) (*admissionv1.AdmissionResponse, error) {
+	if errs := wh.Check(); len(errs) > 0 {
+		return nil, fmt.Errorf("%s", errs[0])
+	}
+
+	uid := types.UID(uuid.NewString())
+	gvk := metav1.GroupVersionKind{Version: "v1", Kind: a.Kind}
+	gvr := metav1.GroupVersionResource{Version: "v1", Resource: a.Resource}
+	dryRun := false
+	req := &admissionv1.AdmissionRequest{
+		UID:             uid,
+		Kind:            gvk,
+		Resource:        gvr,
+		RequestKind:     &gvk,
+		RequestResource: &gvr,
+		Name:            a.Name,
+		Namespace:       a.Namespace,
+		Operation:       admissionv1.Operation(a.Operation),
+		UserInfo:        authenticationv1.UserInfo{Username: a.User},
+		DryRun:          &dryRun,
+	}
+	var err error
+	if req.Object, err = rawObject(a.Object); err != nil {
+		return nil, err
+	}
+	if req.OldObject, err = rawObject(a.OldObject); err != nil {
+		return nil, err
+	}
+
+	body, err := json.Marshal(admissionv1.AdmissionReview{
+		TypeMeta: metav1.TypeMeta{APIVersion: "admission.k8s.io/v1", Kind: "AdmissionReview"},
+		Request:  req,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if len(wh.ClientConfig.CABundle) > 0 {
+		pool := x509.NewCertPool()
+		pool.AppendCertsFromPEM(wh.ClientConfig.CABundle)
+		tlsConfig.RootCAs = pool
+	}
+	client := &http.Client{
+		Timeout:   wh.Timeout(),
+		Transport: &http.Transport{TLSClientConfig: tlsConfig, DisableKeepAlives: true},
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, wh.Timeout())
+	defer cancel()
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, *wh.ClientConfig.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 3<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, bytes.TrimSpace(data))
+	}
+
+	var review admissionv1.AdmissionReview
+	if err := json.Unmarshal(data, &review); err != nil {
+		return nil, fmt.Errorf("decoding AdmissionReview: %w", err)
+	}
+	if review.Response == nil {
+		return nil, fmt.Errorf("AdmissionReview has no response")
+	}
+	if review.Response.UID != uid {
+		return nil, fmt.Errorf("response UID %q does not match request UID %q", review.Response.UID, uid)
+	}
+	return review.Response, nil
+}
+
+func rawObject(obj map[string]interface{}) (runtime.RawExtension, error) {
+	if obj == nil {
+		return runtime.RawExtension{}, nil
+	}
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		return runtime.RawExtension{}, err
+	}
+	return runtime.RawExtension{Raw: raw}, nil
+}
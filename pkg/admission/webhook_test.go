@@ -0,0 +1,180 @@
+package admission
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// newWebhookServer starts a TLS server that answers AdmissionReviews with
+// respond, and returns a Webhook pointing at it.
+func newWebhookServer(t *testing.T, respond func(req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse) Webhook {
+	t.Helper()
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var review admissionv1.AdmissionReview
+		if err := json.NewDecoder(r.Body).Decode(&review); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		resp := respond(review.Request)
+		resp.UID = review.Request.UID
+		review.Request = nil
+		review.Response = resp
+		_ = json.NewEncoder(w).Encode(review)
+	}))
+	t.Cleanup(srv.Close)
+
+	url := srv.URL
+	return Webhook{
+		Name: "policy.example.com",
+		ClientConfig: admissionregv1.WebhookClientConfig{
+			URL:      &url,
+			CABundle: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}),
+		},
+		Rules: []admissionregv1.RuleWithOperations{{
+			Operations: []admissionregv1.OperationType{admissionregv1.Create, admissionregv1.Update},
+			Rule: admissionregv1.Rule{
+				APIGroups:   []string{""},
+				APIVersions: []string{"v1"},
+				Resources:   []string{"pods"},
+			},
+		}},
+	}
+}
+
+func TestWebhookMutates(t *testing.T) {
+	wh := newWebhookServer(t, func(req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+		if req.Kind.Kind != "Pod" || req.Namespace != "infra" || req.UserInfo.Username != "ci" {
+			return &admissionv1.AdmissionResponse{Allowed: false, Result: &metav1.Status{Message: "unexpected request"}}
+		}
+		pt := admissionv1.PatchTypeJSONPatch
+		return &admissionv1.AdmissionResponse{
+			Allowed:   true,
+			PatchType: &pt,
+			Patch:     []byte(`[{"op":"add","path":"/metadata/labels","value":{"injected":"yes"}}]`),
+			Warnings:  []string{"label injected"},
+		}
+	})
+
+	a := &Attributes{Operation: Create, Kind: "Pod", Resource: "pods", Namespace: "infra", Name: "p", User: "ci",
+		Object: map[string]interface{}{"metadata": map[string]interface{}{"name": "p"}}}
+	warnings, err := wh.Invoke(context.Background(), a, true)
+	if err != nil {
+		t.Fatalf("Invoke: %v", err)
+	}
+	if nestedString(a.Object, "metadata", "labels", "injected") != "yes" {
+		t.Errorf("patch not applied: %v", a.Object)
+	}
+	if len(warnings) != 1 || warnings[0] != "label injected" {
+		t.Errorf("unexpected warnings %v", warnings)
+	}
+}
+
+func TestWebhookDenies(t *testing.T) {
+	wh := newWebhookServer(t, func(req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+		return &admissionv1.AdmissionResponse{Allowed: false, Result: &metav1.Status{Message: "no privileged pods", Code: 403}}
+	})
+
+	a := &Attributes{Operation: Create, Kind: "Pod", Resource: "pods", Namespace: "infra", Object: map[string]interface{}{}}
+	_, err := wh.Invoke(context.Background(), a, false)
+	var denied *DeniedError
+	if !errors.As(err, &denied) {
+		t.Fatalf("expected *DeniedError, got %v", err)
+	}
+	if denied.Message != "no privileged pods" || denied.Code != 403 {
+		t.Errorf("unexpected denial %+v", denied)
+	}
+}
+
+func TestWebhookFailurePolicy(t *testing.T) {
+	url := "https://127.0.0.1:1/admit" // nothing listens here
+	one := int32(1)
+	wh := Webhook{Name: "down.example.com", ClientConfig: admissionregv1.WebhookClientConfig{URL: &url}, TimeoutSeconds: &one}
+	a := &Attributes{Operation: Create, Kind: "Pod", Resource: "pods", Object: map[string]interface{}{}}
+
+	if _, err := wh.Invoke(context.Background(), a, false); err == nil || !strings.Contains(err.Error(), "failed calling webhook") {
+		t.Errorf("failurePolicy Fail: expected call error, got %v", err)
+	}
+
+	ignore := admissionregv1.Ignore
+	wh.FailurePolicy = &ignore
+	warnings, err := wh.Invoke(context.Background(), a, false)
+	if err != nil {
+		t.Errorf("failurePolicy Ignore: expected no error, got %v", err)
+	}
+	if len(warnings) != 1 {
+		t.Errorf("failurePolicy Ignore: expected a warning, got %v", warnings)
+	}
+}
+
+func TestWebhookMatches(t *testing.T) {
+	url := "https://hook.example.com/admit"
+	ns := admissionregv1.NamespacedScope
+	wh := Webhook{
+		Name:         "m",
+		ClientConfig: admissionregv1.WebhookClientConfig{URL: &url},
+		Rules: []admissionregv1.RuleWithOperations{{
+			Operations: []admissionregv1.OperationType{admissionregv1.OperationAll},
+			Rule: admissionregv1.Rule{APIGroups: []string{"*"}, APIVersions: []string{"*"},
+				Resources: []string{"pods", "baremetalhosts"}, Scope: &ns},
+		}},
+		NamespaceSelector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{
+			Key: "kubernetes.io/metadata.name", Operator: metav1.LabelSelectorOpNotIn, Values: []string{"kube-system"},
+		}}},
+		ObjectSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "web"}},
+	}
+
+	labeled := map[string]interface{}{"metadata": map[string]interface{}{"labels": map[string]interface{}{"tier": "web"}}}
+	nsLabels := func(ns string) map[string]string { return map[string]string{"kubernetes.io/metadata.name": ns} }
+
+	tests := []struct {
+		name string
+		a    Attributes
+		ns   string
+		want bool
+	}{
+		{"match", Attributes{Operation: Create, Resource: "pods", Namespace: "infra", Object: labeled}, "infra", true},
+		{"old object labeled", Attributes{Operation: Delete, Resource: "pods", Namespace: "infra", OldObject: labeled}, "infra", true},
+		{"excluded namespace", Attributes{Operation: Create, Resource: "pods", Namespace: "kube-system", Object: labeled}, "kube-system", false},
+		{"object selector", Attributes{Operation: Create, Resource: "pods", Namespace: "infra", Object: map[string]interface{}{}}, "infra", false},
+		{"other resource", Attributes{Operation: Create, Resource: "jobs", Namespace: "infra", Object: labeled}, "infra", false},
+		{"cluster scoped", Attributes{Operation: Create, Resource: "pods", Object: labeled}, "", false},
+	}
+	for _, tt := range tests {
+		if got := wh.Matches(&tt.a, nsLabels(tt.ns)); got != tt.want {
+			t.Errorf("%s: Matches = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestWebhookCheck(t *testing.T) {
+	httpURL := "http://hook.example.com/admit"
+	fp := admissionregv1.FailurePolicyType("Sometimes")
+	big := int32(60)
+	wh := Webhook{
+		Name:           "bad",
+		ClientConfig:   admissionregv1.WebhookClientConfig{URL: &httpURL, CABundle: []byte("not pem")},
+		FailurePolicy:  &fp,
+		TimeoutSeconds: &big,
+	}
+	errs := strings.Join(wh.Check(), "; ")
+	for _, want := range []string{"https://", "caBundle", "failurePolicy", "timeoutSeconds"} {
+		if !strings.Contains(errs, want) {
+			t.Errorf("expected error mentioning %q, got %q", want, errs)
+		}
+	}
+
+	if d := (&Webhook{}).Timeout(); d != 10*time.Second {
+		t.Errorf("default timeout = %v, want 10s", d)
+	}
+}
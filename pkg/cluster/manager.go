@@ -109,6 +109,19 @@ func (m *Manager) HealthyNodes() []string {
 	return nodes
 }
 
+// IsLeader reports whether this node has the lowest name among the healthy
+// nodes. Work that must happen once per cluster, such as sending alert
+// notifications, runs only on the leader; leadership moves to the next
+// node as soon as the peer monitor marks the leader down.
+func (m *Manager) IsLeader() bool {
+	for _, name := range m.HealthyNodes()[1:] {
+		if name < m.nodeName {
+			return false
+		}
+	}
+	return true
+}
+
 // FailoverTimeout returns the configured failover timeout in seconds (default 300).
 func (m *Manager) FailoverTimeout() int {
 	if m.cfg.FailoverTimeout > 0 {
@@ -293,6 +306,14 @@ func (m *Manager) PeerNodeStatus(name string) *NodeStatus {
 	return nil
 }
 
+// SetPeerHealthy overrides a peer's health until the peer monitor's next
+// check (for tests).
+func (m *Manager) SetPeerHealthy(name string, healthy bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.peerUp[name] = healthy
+}
+
 // SetPeerAddress updates a peer's address (for tests).
 func (m *Manager) SetPeerAddress(name, address string) {
 	for i := range m.cfg.Peers {
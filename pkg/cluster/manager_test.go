@@ -0,0 +1,30 @@
+package cluster
+
+import (
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/glennswest/mkube/pkg/config"
+)
+
+func TestIsLeader(t *testing.T) {
+	cfg := config.ClusterConfig{Peers: []config.PeerConfig{{Name: "node-a"}, {Name: "node-c"}}}
+	m := New("node-b", cfg, nil, "arm64", zap.NewNop().Sugar())
+
+	if !m.IsLeader() {
+		t.Error("node-b alone should lead")
+	}
+	m.peerUp["node-c"] = true
+	if !m.IsLeader() {
+		t.Error("node-b should lead over node-c")
+	}
+	m.peerUp["node-a"] = true
+	if m.IsLeader() {
+		t.Error("node-a is healthy and sorts first; node-b must not lead")
+	}
+	m.peerUp["node-a"] = false
+	if !m.IsLeader() {
+		t.Error("node-b should take over once node-a is down")
+	}
+}
@@ -3,10 +3,12 @@ package cluster
 import (
 	"bytes"
 	"context"
+	"crypto/subtle"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"go.uber.org/zap"
@@ -38,11 +40,15 @@ type SyncEntry struct {
 }
 
 // syncedBuckets lists the bucket names that participate in peer sync.
-// NODE_STATUS is excluded (local heartbeat only, 60s TTL).
+// NODE_STATUS is excluded (local heartbeat only, 60s TTL), and so are
+// SECRETS, which stay on the node they were created on, and DHCPDEVICES,
+// the inventory of leases the node's own DHCP watcher has seen. Alert
+// rules and silences are synced so the leader evaluates every node's rules
+// and its successor inherits their firing state.
 var syncedBuckets = []string{
 	"PODS", "CONFIGMAPS", "NAMESPACES", "BAREMETALHOSTS",
 	"DEPLOYMENTS", "PVCS", "NETWORKS", "REGISTRIES",
-	"ISCSICDROMS", "BOOTCONFIGS",
+	"ISCSICDROMS", "BOOTCONFIGS", "ALERTRULES", "ALERTSILENCES",
 }
 
 // SyncManager handles push-on-write replication and full resync.
@@ -96,7 +102,15 @@ func (s *SyncManager) pushToPeer(peer config.PeerConfig, evt SyncEvent) {
 	}
 
 	url := peer.Address + "/api/v1/cluster/sync"
-	resp, err := s.client.Post(url, "application/json", bytes.NewReader(body))
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		s.log.Warnw("failed to create sync request", "peer", peer.Name, "error", err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	s.setPeerAuth(req)
+
+	resp, err := s.client.Do(req)
 	if err != nil {
 		s.log.Debugw("sync push failed", "peer", peer.Name, "bucket", evt.Bucket, "key", evt.Key, "error", err)
 		return
@@ -108,8 +122,34 @@ func (s *SyncManager) pushToPeer(peer config.PeerConfig, evt SyncEvent) {
 	}
 }
 
+// setPeerAuth adds the cluster token to a request sent to a peer.
+func (s *SyncManager) setPeerAuth(req *http.Request) {
+	if s.cfg.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.cfg.Token)
+	}
+}
+
+// peerAuthorized reports whether r carries the cluster token. Sync requests
+// overwrite local state, so when a token is configured nothing else is
+// accepted.
+func (s *SyncManager) peerAuthorized(w http.ResponseWriter, r *http.Request) bool {
+	if s.cfg.Token == "" {
+		return true
+	}
+	h := r.Header.Get("Authorization")
+	if len(h) > 7 && strings.EqualFold(h[:7], "Bearer ") &&
+		subtle.ConstantTimeCompare([]byte(strings.TrimSpace(h[7:])), []byte(s.cfg.Token)) == 1 {
+		return true
+	}
+	respondError(w, http.StatusUnauthorized, "invalid cluster token")
+	return false
+}
+
 // HandleSyncEvent processes an incoming sync event from a peer.
 func (s *SyncManager) HandleSyncEvent(w http.ResponseWriter, r *http.Request) {
+	if !s.peerAuthorized(w, r) {
+		return
+	}
 	var evt SyncEvent
 	if err := json.NewDecoder(r.Body).Decode(&evt); err != nil {
 		respondError(w, http.StatusBadRequest, "invalid sync event")
@@ -161,6 +201,9 @@ func (s *SyncManager) HandleSyncEvent(w http.ResponseWriter, r *http.Request) {
 
 // HandleFullSyncExport exports all synced bucket state as a FullSyncPayload.
 func (s *SyncManager) HandleFullSyncExport(w http.ResponseWriter, r *http.Request) {
+	if !s.peerAuthorized(w, r) {
+		return
+	}
 	ctx := r.Context()
 	payload := FullSyncPayload{
 		NodeName: s.nodeName,
@@ -198,6 +241,9 @@ func (s *SyncManager) HandleFullSyncExport(w http.ResponseWriter, r *http.Reques
 
 // HandleFullSyncImport merges an incoming full state dump with local state.
 func (s *SyncManager) HandleFullSyncImport(w http.ResponseWriter, r *http.Request) {
+	if !s.peerAuthorized(w, r) {
+		return
+	}
 	var payload FullSyncPayload
 	body, err := io.ReadAll(io.LimitReader(r.Body, 64*1024*1024))
 	if err != nil {
@@ -243,6 +289,7 @@ func (s *SyncManager) FullResyncWithPeer(ctx context.Context, peer config.PeerCo
 		s.log.Warnw("full resync: failed to create request", "peer", peer.Name, "error", err)
 		return
 	}
+	s.setPeerAuth(req)
 
 	resp, err := s.client.Do(req)
 	if err != nil {
@@ -299,6 +346,7 @@ func (s *SyncManager) FullResyncWithPeer(ctx context.Context, peer config.PeerCo
 		return
 	}
 	pushReq.Header.Set("Content-Type", "application/json")
+	s.setPeerAuth(pushReq)
 
 	pushResp, err := s.client.Do(pushReq)
 	if err != nil {
@@ -0,0 +1,37 @@
+package cluster
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/glennswest/mkube/pkg/config"
+)
+
+func TestSyncRequiresClusterToken(t *testing.T) {
+	s := NewSyncManager("node-a", config.ClusterConfig{Token: "peer-secret"}, nil, zap.NewNop().Sugar())
+
+	for _, token := range []string{"", "wrong"} {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/cluster/sync", strings.NewReader(`{}`))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		s.HandleSyncEvent(rec, req)
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("token %q: expected 401, got %d", token, rec.Code)
+		}
+	}
+
+	// The right token gets past auth; our own echoed event is then ignored
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/cluster/sync", strings.NewReader(`{"nodeName":"node-a"}`))
+	s.setPeerAuth(req)
+	rec := httptest.NewRecorder()
+	s.HandleSyncEvent(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("cluster token: expected 200, got %d (%s)", rec.Code, rec.Body.String())
+	}
+}
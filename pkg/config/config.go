@@ -16,10 +16,11 @@ type Config struct {
 	NodeName   string          `yaml:"nodeName"`
 	Standalone bool            `yaml:"standalone"`
 	KubeConfig string          `yaml:"kubeconfig"`
-	Backend    string          `yaml:"backend"` // "routeros" (default), "stormbase", or "proxmox"
+	Backend    string          `yaml:"backend"` // "routeros" (default), "stormbase", "linux", or "proxmox"
 	RouterOS   RouterOSConfig  `yaml:"routeros"`
 	StormBase  StormBaseConfig `yaml:"stormbase"`
 	Proxmox    ProxmoxConfig   `yaml:"proxmox"`
+	Linux      LinuxConfig     `yaml:"linux"`
 	Networks   []NetworkDef    `yaml:"networks"`
 	Storage    StorageConfig   `yaml:"storage"`
 	Lifecycle  LifecycleConfig `yaml:"lifecycle"`
@@ -30,6 +31,13 @@ type Config struct {
 	NATS       NATSConfig      `yaml:"nats"`
 	Cluster    ClusterConfig   `yaml:"cluster"`
 	BMH        BMHConfig       `yaml:"bmh"`
+	Alerting   AlertingConfig  `yaml:"alerting"`
+	API        APIConfig       `yaml:"api"`
+	Dashboard  DashboardConfig `yaml:"dashboard"`
+	Admission  AdmissionConfig `yaml:"admission"`
+
+	FallbackDNS FallbackDNSConfig `yaml:"fallbackDNS"`
+	DNSUpdate   DNSUpdateConfig   `yaml:"dnsUpdate"`
 
 	// Deprecated: single-network config for backward compatibility.
 	// If present and Networks is empty, it is migrated into Networks.
@@ -41,6 +49,11 @@ func (c *Config) IsStormBase() bool {
 	return c.Backend == "stormbase"
 }
 
+// IsLinux returns true if the backend is linux.
+func (c *Config) IsLinux() bool {
+	return c.Backend == "linux"
+}
+
 // IsProxmox returns true if the backend is proxmox.
 func (c *Config) IsProxmox() bool {
 	return c.Backend == "proxmox"
@@ -75,12 +88,31 @@ type ClusterConfig struct {
 	Enabled          bool         `yaml:"enabled"`
 	Peers            []PeerConfig `yaml:"peers"`
 	FailoverTimeout  int          `yaml:"failoverTimeout"`  // seconds before rescheduling failed node pods (default 300)
+	TunnelAddress    string       `yaml:"tunnelAddress"`    // local underlay IP for overlay tunnels (spanNodes networks)
+	Token            string       `yaml:"token"`            // shared secret peers present on sync requests
+	WireGuard        WireGuardConfig `yaml:"wireguard"`
+}
+
+// WireGuardConfig enables encrypted site links to every peer. The node's
+// private key is generated on first start and kept in the node-local Secret
+// kube-system/wireguard-<node>; its public key is published in the
+// ConfigMap of the same name.
+type WireGuardConfig struct {
+	Enabled    bool `yaml:"enabled"`
+	ListenPort int  `yaml:"listenPort"` // base UDP port, offset per node pair (default 51820)
 }
 
 // PeerConfig defines a cluster peer node.
 type PeerConfig struct {
 	Name    string `yaml:"name"`    // peer node name, e.g. "pvex"
 	Address string `yaml:"address"` // peer HTTP address, e.g. "http://192.168.1.160:8082"
+
+	// Underlay IP for overlay tunnels to this peer (default: host of Address)
+	TunnelAddress string `yaml:"tunnelAddress,omitempty"`
+
+	// Networks behind this peer; their CIDRs are routed over the WireGuard
+	// site link when cluster.wireguard is enabled
+	Networks []string `yaml:"networks,omitempty"`
 }
 
 // BMHConfig configures BareMetalHost management.
@@ -90,10 +122,117 @@ type BMHConfig struct {
 	WatchInterval int    `yaml:"watchInterval"`  // seconds, default: 30
 }
 
+// APIConfig configures authentication for the mkube HTTP API.
+// With no tokens configured the API is open (the historical behaviour).
+type APIConfig struct {
+	Tokens []APIToken `yaml:"tokens"`
+}
+
+// APIToken is a static bearer token accepted by the API.
+type APIToken struct {
+	Name     string `yaml:"name"`     // identity shown in logs and the dashboard
+	Token    string `yaml:"token"`    // sent as "Authorization: Bearer <token>"
+	ReadOnly bool   `yaml:"readOnly"` // only GET/HEAD (including watches) allowed
+}
+
+// DashboardConfig configures the embedded web UI served at /ui/.
+type DashboardConfig struct {
+	Enabled  bool `yaml:"enabled"`  // default: true
+	ReadOnly bool `yaml:"readOnly"` // hide write actions for every user
+}
+
+// AlertingConfig configures the AlertRule evaluation engine and its sinks.
+type AlertingConfig struct {
+	EvalInterval int        `yaml:"evalInterval"` // seconds between rule evaluations, default: 30
+	SMTP         SMTPConfig `yaml:"smtp"`         // mail relay for "email" sinks
+}
+
+// SMTPConfig specifies the mail relay used for email alert notifications.
+type SMTPConfig struct {
+	Host     string `yaml:"host"`     // e.g. "mail.gt.lo"
+	Port     int    `yaml:"port"`     // default: 25
+	From     string `yaml:"from"`     // e.g. "mkube@gt.lo"
+	Username string `yaml:"username"` // optional PLAIN auth
+	Password string `yaml:"password"`
+}
+
+// AdmissionConfig enables built-in admission plugins and sets their policy.
+// Admission webhooks are configured through the API, not here.
+type AdmissionConfig struct {
+	Plugins           []string          `yaml:"plugins"`           // enabled built-in plugins, e.g. ["NamespaceNetworkDefault", "ForbidLatestTag"]
+	NamespaceNetworks map[string]string `yaml:"namespaceNetworks"` // namespace -> default network, overrides the namespace's own network
+	DevNamespaces     []string          `yaml:"devNamespaces"`     // namespaces allowed to use :latest images, default: ["dev"]
+	OwnerLabel        string            `yaml:"ownerLabel"`        // label required on BareMetalHosts, default: "owner"
+}
+
+// FallbackDNSConfig configures the DNS responder mkube runs for its managed
+// zones while their microdns instances do not answer on port 53.
+type FallbackDNSConfig struct {
+	Enabled bool   `yaml:"enabled"` // default: false
+	Listen  string `yaml:"listen"`  // UDP listen address, default: ":53"
+}
+
+// DNSUpdateConfig configures the RFC 2136 dynamic update listener that lets
+// hosts outside mkube register names in the managed zones. It runs when at
+// least one key is configured.
+type DNSUpdateConfig struct {
+	Listen string          `yaml:"listen"` // UDP and TCP listen address, default: ":5353"
+	Keys   []TSIGKeyConfig `yaml:"keys"`
+}
+
+// TSIGKeyConfig is a TSIG key and what updates signed with it may change.
+type TSIGKeyConfig struct {
+	Name      string   `yaml:"name"`            // key name, e.g. "acme"
+	Algorithm string   `yaml:"algorithm"`       // hmac-sha256 (default), hmac-sha1, hmac-sha224, hmac-sha384, hmac-sha512
+	Secret    string   `yaml:"secret"`          // base64, as in a BIND key file
+	Zones     []string `yaml:"zones"`           // zones the key may update, e.g. ["gt.lo"]
+	Types     []string `yaml:"types,omitempty"` // record types it may change; empty allows all
+}
+
 // NamespaceConfig configures the namespace manager.
 type NamespaceConfig struct {
 	StatePath   string `yaml:"statePath"`   // e.g. "/etc/mkube/namespace-state.yaml"
 	DefaultMode string `yaml:"defaultMode"` // "open" or "nested", overrides DZO defaultMode
+
+	// Bandwidth is the default pod shaping per Kubernetes namespace, used
+	// where a pod has no kubernetes.io/{ingress,egress}-bandwidth annotation.
+	Bandwidth map[string]BandwidthConfig `yaml:"bandwidth,omitempty"`
+
+	// DNSTTL is the DNS record TTL policy per Kubernetes namespace. It takes
+	// precedence over the network's policy; a vkube.io/dns-ttl annotation
+	// on a pod takes precedence over both.
+	DNSTTL map[string]DNSTTLConfig `yaml:"dnsTTL,omitempty"`
+
+	// DNSViews are split-horizon views of a namespace's own zone
+	// (<namespace>.<zone>), keyed by the namespace named in
+	// vkube.io/namespace. They are served by the namespace network's
+	// microdns next to the Network's spec.dns.views, so a view name both
+	// use must match the same clients.
+	DNSViews map[string][]DNSViewConfig `yaml:"dnsViews,omitempty"`
+}
+
+// DNSViewConfig is a split-horizon view: queries from a source address in
+// Clients are answered from Records first, and from the zone for names and
+// types the view does not override.
+type DNSViewConfig struct {
+	Name    string                `yaml:"name"`              // e.g. "lan"
+	Clients []string              `yaml:"clients"`           // source CIDRs matched to this view
+	Records []DNSViewRecordConfig `yaml:"records,omitempty"` // answers replacing the zone's
+}
+
+// DNSViewRecordConfig is one answer override of a DNSViewConfig.
+type DNSViewRecordConfig struct {
+	Name string `yaml:"name"`           // relative to the zone; "@" for the apex
+	Type string `yaml:"type,omitempty"` // A (default), AAAA, CNAME or TXT
+	Data string `yaml:"data"`           // address, target name or text
+	TTL  int    `yaml:"ttl,omitempty"`  // defaults to 300
+}
+
+// BandwidthConfig is a pair of rate quantities in bits per second ("10M").
+// Empty means unlimited.
+type BandwidthConfig struct {
+	Ingress string `yaml:"ingress,omitempty"`
+	Egress  string `yaml:"egress,omitempty"`
 }
 
 // NetworkDef defines a network that containers can be placed on.
@@ -108,6 +247,15 @@ type NetworkDef struct {
 	IPAMStart   string    `yaml:"ipamStart,omitempty"` // first IP for container IPAM allocation
 	IPAMEnd     string    `yaml:"ipamEnd,omitempty"`   // last IP for container IPAM allocation
 	ExternalDNS bool      `yaml:"externalDNS,omitempty"` // DNS server is external (not managed by mkube)
+	SpanNodes   bool      `yaml:"spanNodes,omitempty"`   // stretch across cluster nodes over overlay tunnels
+	Masquerade  bool      `yaml:"masquerade,omitempty"`  // NAT traffic leaving the network to the host address (Linux driver)
+
+	// Optional IPv6 subnet for dual-stack networks. When set, every
+	// container on the network also gets an address from this subnet.
+	CIDR6      string `yaml:"cidr6,omitempty"`
+	Gateway6   string `yaml:"gateway6,omitempty"`
+	IPAMStart6 string `yaml:"ipamStart6,omitempty"`
+	IPAMEnd6   string `yaml:"ipamEnd6,omitempty"`
 }
 
 // DNSConfig specifies the MicroDNS instance for a network.
@@ -117,6 +265,61 @@ type DNSConfig struct {
 	Server        string         `yaml:"server"`   // DNS server IP for containers, e.g. "192.168.200.199"
 	DHCP          DHCPConfig     `yaml:"dhcp"`     // DHCP server config for this network
 	StaticRecords []StaticRecord `yaml:"staticRecords,omitempty"` // infrastructure hosts registered at startup
+	TTL           DNSTTLConfig   `yaml:"ttl,omitempty"`           // record TTL policy for the zone
+}
+
+// Kinds of DNS records a TTL policy distinguishes.
+const (
+	DNSTTLPods    = "pods"    // container and pod name records
+	DNSTTLAliases = "aliases" // vkube.io/aliases names
+	DNSTTLBMH     = "bmh"     // BareMetalHost hostnames
+	DNSTTLStatic  = "static"  // static, infrastructure and reservation records
+	DNSTTLRollout = "rollout" // pods and aliases while a rolling update or migration runs
+)
+
+// DNSTTLConfig is a DNS record TTL policy in seconds. A zero field falls
+// back to Default; Rollout has no fallback, as a rollout TTL only makes
+// sense below the usual one.
+type DNSTTLConfig struct {
+	Default int `yaml:"default,omitempty"`
+	Pods    int `yaml:"pods,omitempty"`
+	Aliases int `yaml:"aliases,omitempty"`
+	BMH     int `yaml:"bmh,omitempty"`
+	Static  int `yaml:"static,omitempty"`
+	Rollout int `yaml:"rollout,omitempty"`
+
+	// Negative is how long the network's microdns caches negative answers
+	// (NXDOMAIN, NODATA). Only used on networks.
+	Negative int `yaml:"negative,omitempty"`
+}
+
+// For returns the policy's TTL for a kind of record, 0 when it sets none.
+func (c DNSTTLConfig) For(kind string) int {
+	var v int
+	switch kind {
+	case DNSTTLPods:
+		v = c.Pods
+	case DNSTTLAliases:
+		v = c.Aliases
+	case DNSTTLBMH:
+		v = c.BMH
+	case DNSTTLStatic:
+		v = c.Static
+	case DNSTTLRollout:
+		return c.Rollout
+	}
+	if v == 0 {
+		v = c.Default
+	}
+	return v
+}
+
+// TTL returns the policy's TTL for a kind of record, or fallback.
+func (c DNSTTLConfig) TTL(kind string, fallback int) int {
+	if v := c.For(kind); v > 0 {
+		return v
+	}
+	return fallback
 }
 
 // DHCPConfig specifies DHCP settings for a MicroDNS instance.
@@ -194,6 +397,24 @@ type StormBaseConfig struct {
 	Insecure   bool   `yaml:"insecure"`   // skip TLS (dev/test only)
 }
 
+// LinuxConfig configures the linux backend: workloads run under stormd
+// (reached with the stormbase settings) while mkube programs the host's
+// bridges, veths and nftables itself.
+type LinuxConfig struct {
+	ACLs []ACLDef `yaml:"acls"` // static forwarding filter rules
+}
+
+// ACLDef is a forwarding filter rule from the config file. Empty fields
+// match anything; accept rules take precedence over drop rules.
+type ACLDef struct {
+	Name        string `yaml:"name"`
+	Action      string `yaml:"action"`   // "accept" or "drop"
+	Protocol    string `yaml:"protocol"` // "tcp", "udp" or "icmp"
+	Source      string `yaml:"source"`
+	Destination string `yaml:"destination"`
+	Port        int    `yaml:"port"`
+}
+
 // ProxmoxConfig holds connection settings for a Proxmox VE node.
 type ProxmoxConfig struct {
 	URL            string `yaml:"url"`            // e.g. "https://pvex.gw.lo:8006"
@@ -343,6 +564,25 @@ func Load(flags *pflag.FlagSet) (*Config, error) {
 			DHCPLeaseURL:  "http://dns.g11.lo:8080",
 			WatchInterval: 30,
 		},
+		Dashboard: DashboardConfig{
+			Enabled: true,
+		},
+		Alerting: AlertingConfig{
+			EvalInterval: 30,
+			SMTP: SMTPConfig{
+				Port: 25,
+			},
+		},
+		Admission: AdmissionConfig{
+			DevNamespaces: []string{"dev"},
+			OwnerLabel:    "owner",
+		},
+		FallbackDNS: FallbackDNSConfig{
+			Listen: ":53",
+		},
+		DNSUpdate: DNSUpdateConfig{
+			Listen: ":5353",
+		},
 	}
 
 	// Load from file if it exists
@@ -71,6 +71,9 @@ func TestLoadDefaults(t *testing.T) {
 	if !cfg.Registry.Enabled {
 		t.Error("expected registry enabled by default")
 	}
+	if cfg.FallbackDNS.Enabled {
+		t.Error("expected fallback DNS disabled by default")
+	}
 }
 
 func TestLoadFromYAML_MultiNetwork(t *testing.T) {
@@ -0,0 +1,40 @@
+// Package dashboard serves the embedded single-page mkube web UI.
+//
+// The UI is static HTML/JS that talks to the regular mkube JSON API
+// (and its ?watch=true streams) from the browser, so it needs no server-side
+// state of its own. When API tokens are configured the browser prompts for
+// one and sends it as a bearer token; read-only tokens hide write actions.
+package dashboard
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+//go:embed static
+var staticFiles embed.FS
+
+// RegisterRoutes mounts the dashboard at /ui/.
+func RegisterRoutes(mux *http.ServeMux) {
+	mux.Handle("GET /ui/", Handler())
+	mux.HandleFunc("GET /ui", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/ui/", http.StatusMovedPermanently)
+	})
+}
+
+// Handler returns an http.Handler serving the embedded assets under /ui/.
+func Handler() http.Handler {
+	sub, err := fs.Sub(staticFiles, "static")
+	if err != nil {
+		// Only possible if the embed directive is broken at build time.
+		panic(err)
+	}
+	files := http.StripPrefix("/ui/", http.FileServer(http.FS(sub)))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Assets are versioned with the binary; make browsers revalidate
+		// so an mkube upgrade never serves a stale UI.
+		w.Header().Set("Cache-Control", "no-cache")
+		files.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,51 @@
+package dashboard
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestServesIndex(t *testing.T) {
+	mux := http.NewServeMux()
+	RegisterRoutes(mux)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ui/", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "<title>mkube</title>") {
+		t.Error("expected index.html body")
+	}
+}
+
+func TestServesAssets(t *testing.T) {
+	mux := http.NewServeMux()
+	RegisterRoutes(mux)
+
+	for _, path := range []string{"/ui/app.js", "/ui/style.css"} {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusOK {
+			t.Errorf("%s: expected 200, got %d", path, rec.Code)
+		}
+	}
+}
+
+func TestRedirectsBarePath(t *testing.T) {
+	mux := http.NewServeMux()
+	RegisterRoutes(mux)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ui", nil))
+
+	if rec.Code != http.StatusMovedPermanently {
+		t.Fatalf("expected 301, got %d", rec.Code)
+	}
+	if loc := rec.Header().Get("Location"); loc != "/ui/" {
+		t.Errorf("expected redirect to /ui/, got %q", loc)
+	}
+}
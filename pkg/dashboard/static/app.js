@@ -0,0 +1,376 @@
+// mkube dashboard — talks to the mkube JSON API from the browser.
+// No build step: plain ES2017, one file.
+(function () {
+  "use strict";
+
+  var TOKEN_KEY = "mkube.token";
+  var session = { authEnabled: false, readOnly: false, user: "" };
+  var timers = {};
+  var watches = {};
+
+  // ─── HTTP ────────────────────────────────────────────────────────────────
+
+  function headers() {
+    var h = { Accept: "application/json" };
+    var tok = localStorage.getItem(TOKEN_KEY);
+    if (tok) h.Authorization = "Bearer " + tok;
+    return h;
+  }
+
+  function api(path, opts) {
+    opts = opts || {};
+    opts.headers = Object.assign(headers(), opts.headers || {});
+    return fetch(path, opts).then(function (resp) {
+      if (resp.status === 401) {
+        showLogin("Token rejected");
+        throw new Error("unauthorized");
+      }
+      if (!resp.ok) {
+        return resp.text().then(function (t) { throw new Error(resp.status + ": " + t); });
+      }
+      var ct = resp.headers.get("Content-Type") || "";
+      return ct.indexOf("json") >= 0 ? resp.json() : resp.text();
+    });
+  }
+
+  // watch streams newline-delimited K8sWatchEvents from a ?watch=true
+  // endpoint and calls onEvent for each. fetch is used instead of
+  // EventSource so the Authorization header can be sent.
+  function watch(name, path, onEvent) {
+    if (watches[name]) return;
+    var ctrl = new AbortController();
+    watches[name] = ctrl;
+    setLive(name, "connecting");
+
+    fetch(path, { headers: headers(), signal: ctrl.signal }).then(function (resp) {
+      if (!resp.ok || !resp.body) throw new Error("watch " + resp.status);
+      setLive(name, "live");
+      var reader = resp.body.getReader();
+      var decoder = new TextDecoder();
+      var buf = "";
+      function pump() {
+        return reader.read().then(function (r) {
+          if (r.done) throw new Error("closed");
+          buf += decoder.decode(r.value, { stream: true });
+          var lines = buf.split("\n");
+          buf = lines.pop();
+          lines.forEach(function (l) {
+            if (!l.trim()) return;
+            try { onEvent(JSON.parse(l)); } catch (e) { /* partial or non-JSON line */ }
+          });
+          return pump();
+        });
+      }
+      return pump();
+    }).catch(function () {
+      delete watches[name];
+      if (ctrl.signal.aborted) return;
+      // Watches need the NATS store; fall back to polling when unavailable.
+      setLive(name, "polling");
+      setTimeout(function () { watch(name, path, onEvent); }, 15000);
+    });
+  }
+
+  function stopWatches() {
+    Object.keys(watches).forEach(function (k) { watches[k].abort(); delete watches[k]; });
+  }
+
+  function setLive(name, state) {
+    var el = document.getElementById(name + "-live");
+    if (el) el.textContent = state;
+  }
+
+  function debounce(key, fn, ms) {
+    clearTimeout(timers[key]);
+    timers[key] = setTimeout(fn, ms);
+  }
+
+  // ─── Rendering helpers ───────────────────────────────────────────────────
+
+  function esc(v) {
+    return String(v === undefined || v === null ? "" : v)
+      .replace(/&/g, "&amp;").replace(/</g, "&lt;").replace(/>/g, "&gt;").replace(/"/g, "&quot;");
+  }
+
+  function table(el, cols, rows, rowAttrs) {
+    var html = "<tr>" + cols.map(function (c) { return "<th>" + esc(c) + "</th>"; }).join("") + "</tr>";
+    rows.forEach(function (r, i) {
+      var attrs = rowAttrs ? rowAttrs(i) : "";
+      html += "<tr " + attrs + ">" + r.map(function (c) { return "<td>" + c + "</td>"; }).join("") + "</tr>";
+    });
+    if (!rows.length) html += '<tr><td class="muted" colspan="' + cols.length + '">none</td></tr>';
+    el.innerHTML = html;
+  }
+
+  function status(s) {
+    var cls = { pass: "pass", Running: "pass", Completed: "pass", Provisioned: "pass", Active: "pass",
+      warn: "warn", Pending: "warn", Scheduling: "warn", Provisioning: "warn",
+      fail: "fail", Failed: "fail", TimedOut: "fail", Error: "fail" }[s] || "";
+    return '<span class="' + cls + '">' + esc(s || "-") + "</span>";
+  }
+
+  function age(ts) {
+    if (!ts) return "-";
+    var s = Math.max(0, (Date.now() - new Date(ts).getTime()) / 1000);
+    if (s < 120) return Math.floor(s) + "s";
+    if (s < 7200) return Math.floor(s / 60) + "m";
+    if (s < 172800) return Math.floor(s / 3600) + "h";
+    return Math.floor(s / 86400) + "d";
+  }
+
+  function fail(el, err) {
+    el.innerHTML = '<p class="error">' + esc(err.message || err) + "</p>";
+  }
+
+  // ─── Views ───────────────────────────────────────────────────────────────
+
+  function loadPods() {
+    var el = document.getElementById("pods");
+    return api("/api/v1/pods").then(function (list) {
+      var byNet = {};
+      (list.items || []).forEach(function (p) {
+        var ann = p.metadata.annotations || {};
+        var net = ann["vkube.io/network"] || p.metadata.namespace;
+        (byNet[net] = byNet[net] || []).push(p);
+      });
+      var html = "";
+      Object.keys(byNet).sort().forEach(function (net) {
+        var pods = byNet[net].sort(function (a, b) {
+          return (a.metadata.namespace + a.metadata.name).localeCompare(b.metadata.namespace + b.metadata.name);
+        });
+        html += "<h3>" + esc(net) + " <span class=\"muted\">(" + pods.length + ")</span></h3><table>";
+        html += "<tr><th>Namespace</th><th>Name</th><th>Phase</th><th>IP</th><th>Ready</th><th>Node</th><th>Age</th></tr>";
+        pods.forEach(function (p) {
+          var st = p.status || {};
+          var cs = st.containerStatuses || [];
+          var ready = cs.filter(function (c) { return c.ready; }).length + "/" + cs.length;
+          var ips = (st.podIPs || []).map(function (i) { return i.ip; }).join(", ") || st.podIP || "-";
+          html += "<tr><td>" + esc(p.metadata.namespace) + "</td><td>" + esc(p.metadata.name) + "</td><td>" +
+            status(st.phase) + "</td><td>" + esc(ips) + "</td><td>" + ready + "</td><td>" +
+            esc((p.metadata.annotations || {})["vkube.io/node"] || "-") + "</td><td>" +
+            age(p.metadata.creationTimestamp) + "</td></tr>";
+        });
+        html += "</table>";
+      });
+      el.innerHTML = html || '<p class="muted">no pods</p>';
+    }).catch(function (e) { fail(el, e); });
+  }
+
+  function loadDeployments() {
+    var el = document.getElementById("deployments");
+    return api("/api/v1/deployments").then(function (list) {
+      var rows = (list.items || []).map(function (d) {
+        var st = d.status || {};
+        return [esc(d.metadata.namespace), esc(d.metadata.name),
+          esc((st.readyReplicas || 0) + "/" + (d.spec.replicas || 0)),
+          esc(((d.spec.template || {}).spec || {}).containers ? d.spec.template.spec.containers.map(function (c) { return c.image; }).join(", ") : "-"),
+          esc(st.updatedAt || "-")];
+      });
+      table(el, ["Namespace", "Name", "Ready", "Images", "Updated"], rows);
+    }).catch(function (e) { fail(el, e); });
+  }
+
+  function loadBMH() {
+    var el = document.getElementById("bmh");
+    return api("/api/v1/baremetalhosts").then(function (list) {
+      var items = list.items || [];
+      var rows = items.map(function (h) {
+        var st = h.status || {};
+        return [esc(h.metadata.namespace), esc(h.metadata.name), status(st.phase),
+          st.poweredOn ? '<span class="pass">on</span>' : '<span class="muted">off</span>',
+          esc(h.spec.image || "-"), esc(h.spec.bootConfigRef || "-"), esc(st.ip || h.spec.ip || "-"),
+          esc(st.lastBoot || "-"), esc(st.bootCount || 0),
+          st.errorMessage ? '<span class="fail">' + esc(st.errorMessage) + "</span>" : ""];
+      });
+      table(el, ["Namespace", "Name", "Phase", "Power", "Image", "Boot config", "IP", "Last boot", "Boots", "Error"], rows);
+    }).catch(function (e) { fail(el, e); });
+  }
+
+  var logJob = null;
+
+  function loadJobs() {
+    var el = document.getElementById("jobs");
+    return api("/api/v1/jobs").then(function (list) {
+      var order = { Running: 0, Provisioning: 1, Scheduling: 2, Pending: 3 };
+      var items = (list.items || []).sort(function (a, b) {
+        var oa = a.status.phase in order ? order[a.status.phase] : 9;
+        var ob = b.status.phase in order ? order[b.status.phase] : 9;
+        if (oa !== ob) return oa - ob;
+        return (b.spec.priority || 0) - (a.spec.priority || 0);
+      });
+      var rows = items.map(function (j) {
+        var st = j.status || {};
+        return [esc(j.metadata.namespace), esc(j.metadata.name), esc(j.spec.pool), esc(j.spec.priority || 0),
+          status(st.phase), esc(st.bmhRef || "-"), esc(st.startedAt ? age(st.startedAt) : "-"),
+          esc(st.exitCode === undefined ? "-" : st.exitCode), esc(st.logLines || 0)];
+      });
+      table(el, ["Namespace", "Name", "Pool", "Priority", "Phase", "Host", "Running", "Exit", "Log lines"], rows,
+        function (i) {
+          return 'class="clickable" data-ns="' + esc(items[i].metadata.namespace) + '" data-name="' + esc(items[i].metadata.name) + '"';
+        });
+      el.querySelectorAll("tr.clickable").forEach(function (tr) {
+        tr.onclick = function () { openJobLog(tr.dataset.ns, tr.dataset.name); };
+      });
+    }).catch(function (e) { fail(el, e); });
+  }
+
+  function openJobLog(ns, name) {
+    logJob = { ns: ns, name: name };
+    document.getElementById("joblog-panel").hidden = false;
+    document.getElementById("joblog-name").textContent = ns + "/" + name;
+    refreshJobLog();
+  }
+
+  function refreshJobLog() {
+    clearTimeout(timers.joblog);
+    if (!logJob) return;
+    var pre = document.getElementById("joblog");
+    var atBottom = pre.scrollTop + pre.clientHeight >= pre.scrollHeight - 4;
+    var base = "/api/v1/namespaces/" + encodeURIComponent(logJob.ns) + "/jobs/" + encodeURIComponent(logJob.name);
+    Promise.all([api(base + "/logs"), api(base)]).then(function (res) {
+      pre.textContent = res[0];
+      if (atBottom) pre.scrollTop = pre.scrollHeight;
+      var phase = (res[1].status || {}).phase;
+      // Keep tailing while the job can still produce output
+      if (["Completed", "Failed", "TimedOut", "Cancelled"].indexOf(phase) < 0) {
+        timers.joblog = setTimeout(refreshJobLog, 2000);
+      }
+    }).catch(function (e) { pre.textContent = String(e.message || e); });
+  }
+
+  function loadRegistry() {
+    var el = document.getElementById("registry");
+    return api("/api/v1/registries").then(function (list) {
+      var regs = list.items || [];
+      if (!regs.length) { el.innerHTML = '<p class="muted">no registries</p>'; return; }
+      el.innerHTML = regs.map(function (r) {
+        return "<h3>" + esc(r.metadata.name) + ' <span class="muted">' + esc(r.spec.hostname || r.spec.staticIP) +
+          "</span></h3><div id=\"catalog-" + esc(r.metadata.name) + '" class="muted">loading…</div>';
+      }).join("");
+      regs.forEach(function (r) {
+        var target = document.getElementById("catalog-" + r.metadata.name);
+        api("/api/v1/registries/" + encodeURIComponent(r.metadata.name) + "/catalog").then(function (c) {
+          var repos = c.repositories || [];
+          target.className = "";
+          target.innerHTML = repos.length ? "<table><tr><th>Repository</th></tr>" +
+            repos.map(function (n) { return "<tr><td>" + esc(n) + "</td></tr>"; }).join("") + "</table>"
+            : '<p class="muted">empty</p>';
+        }).catch(function (e) { fail(target, e); });
+      });
+    }).catch(function (e) { fail(el, e); });
+  }
+
+  function loadIPAM() {
+    var el = document.getElementById("ipam");
+    return api("/api/v1/ipam").then(function (usage) {
+      var rows = (usage || []).map(function (u) {
+        var pct = u.capacity ? Math.round(100 * u.allocated / u.capacity) : 0;
+        return [esc(u.network), esc(u.cidr), esc(u.allocated + " / " + u.capacity),
+          '<div class="bar"><div class="' + (pct >= 90 ? "high" : "") + '" style="width:' + pct + '%"></div></div> ' + pct + "%"];
+      });
+      table(el, ["Network", "CIDR", "Allocated", "Utilization"], rows);
+    }).catch(function (e) { fail(el, e); });
+  }
+
+  function loadConsistency() {
+    var el = document.getElementById("consistency");
+    var sum = document.getElementById("consistency-summary");
+    sum.textContent = "running checks…";
+    return api("/api/v1/consistency").then(function (rep) {
+      var s = rep.summary || {};
+      sum.innerHTML = status("pass") + " " + (s.pass || 0) + " &nbsp; " + '<span class="warn">warn</span> ' + (s.warn || 0) +
+        " &nbsp; " + '<span class="fail">fail</span> ' + (s.fail || 0) + ' &nbsp; <span class="muted">' + esc(rep.timestamp) + "</span>";
+      var html = "";
+      Object.keys(rep.checks || {}).forEach(function (cat) {
+        var items = (rep.checks[cat] || []).filter(function (i) { return i.status !== "pass"; });
+        if (!items.length) return;
+        html += "<h3>" + esc(cat) + "</h3><table><tr><th>Status</th><th>Name</th><th>Message</th></tr>";
+        items.forEach(function (i) {
+          html += "<tr><td>" + status(i.status) + "</td><td>" + esc(i.name) + "</td><td>" + esc(i.message) +
+            (i.details ? ' <span class="muted">' + esc(i.details) + "</span>" : "") + "</td></tr>";
+        });
+        html += "</table>";
+      });
+      el.innerHTML = html || '<p class="pass">all checks passing</p>';
+    }).catch(function (e) { sum.textContent = ""; fail(el, e); });
+  }
+
+  function repair() {
+    if (!confirm("Release IPAM allocations whose veth no longer exists?")) return;
+    api("/api/v1/consistency/repair", { method: "POST" }).then(function (res) {
+      alert("Released " + (res.count || 0) + " allocation(s)");
+      loadConsistency();
+    }).catch(function (e) { alert(e.message || e); });
+  }
+
+  // ─── Routing ─────────────────────────────────────────────────────────────
+
+  var views = {
+    pods: function () {
+      loadPods();
+      watch("pods", "/api/v1/pods?watch=true", function () { debounce("pods", loadPods, 500); });
+      timers.poll = setInterval(loadPods, 15000);
+    },
+    deployments: function () { loadDeployments(); timers.poll = setInterval(loadDeployments, 15000); },
+    bmh: function () { loadBMH(); timers.poll = setInterval(loadBMH, 15000); },
+    jobs: function () {
+      loadJobs();
+      watch("jobs", "/api/v1/jobs?watch=true", function () { debounce("jobs", loadJobs, 500); });
+      timers.poll = setInterval(loadJobs, 15000);
+    },
+    registry: loadRegistry,
+    ipam: function () { loadIPAM(); timers.poll = setInterval(loadIPAM, 15000); },
+    consistency: loadConsistency,
+  };
+
+  function route() {
+    var name = (location.hash || "#pods").slice(1);
+    if (!views[name]) name = "pods";
+    clearInterval(timers.poll);
+    stopWatches();
+    document.querySelectorAll(".view").forEach(function (v) { v.classList.toggle("active", v.id === "view-" + name); });
+    document.querySelectorAll("#tabs a").forEach(function (a) { a.classList.toggle("active", a.getAttribute("href") === "#" + name); });
+    views[name]();
+  }
+
+  // ─── Session ─────────────────────────────────────────────────────────────
+
+  function showLogin(msg) {
+    document.getElementById("login").hidden = false;
+    document.getElementById("login-error").textContent = msg || "";
+  }
+
+  function loadSession() {
+    return api("/api/v1/auth/whoami").then(function (s) {
+      session = s;
+      document.body.classList.toggle("readonly", !!s.readOnly);
+      var who = s.authEnabled ? (s.user || "token") : "auth disabled";
+      if (s.readOnly) who += " (read-only)";
+      document.getElementById("whoami").textContent = who;
+      document.getElementById("logout").hidden = !s.authEnabled;
+      document.getElementById("login").hidden = true;
+      route();
+    });
+  }
+
+  document.getElementById("login-form").onsubmit = function (ev) {
+    ev.preventDefault();
+    localStorage.setItem(TOKEN_KEY, document.getElementById("token").value);
+    loadSession().catch(function () {});
+  };
+  document.getElementById("logout").onclick = function () {
+    localStorage.removeItem(TOKEN_KEY);
+    stopWatches();
+    showLogin();
+  };
+  document.getElementById("joblog-close").onclick = function () {
+    logJob = null;
+    clearTimeout(timers.joblog);
+    document.getElementById("joblog-panel").hidden = true;
+  };
+  document.getElementById("consistency-refresh").onclick = loadConsistency;
+  document.getElementById("consistency-repair").onclick = repair;
+  window.addEventListener("hashchange", route);
+
+  loadSession().catch(function () {});
+})();
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"strings"
 	"sync"
@@ -64,20 +65,51 @@ type Client struct {
 	batchMode      bool
 	recordCache    map[string][]Record // "endpoint:zoneID" -> records
 	failedEndpoints map[string]bool    // endpoints that timed out this batch
+
+	reverseZones map[string][]ReverseZone // endpoint -> zones from EnsureReverseZones
+	zoneNames    map[string]string        // "endpoint:zoneID" -> zone name
+	ownedTXT     map[string]bool          // "endpoint:zoneID:recordID" of TXT records SyncTXT manages
 }
 
 // Zone represents a MicroDNS zone.
 type Zone struct {
 	ID   string `json:"id"`
 	Name string `json:"name"`
+	View string `json:"view,omitempty"` // split-horizon view; "" for the zone itself
 }
 
 // RecordData represents the typed data payload in a MicroDNS record.
+// Structured data (SRV, MX, CAA) is kept as its compact JSON encoding so a
+// zone holding such records still lists; see SRV.
 type RecordData struct {
 	Type string `json:"type"`
 	Data string `json:"data"`
 }
 
+// UnmarshalJSON accepts both string and object data.
+func (d *RecordData) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		Type string          `json:"type"`
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	d.Type, d.Data = raw.Type, ""
+	if len(raw.Data) == 0 || string(raw.Data) == "null" {
+		return nil
+	}
+	if raw.Data[0] == '"' {
+		return json.Unmarshal(raw.Data, &d.Data)
+	}
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, raw.Data); err != nil {
+		return err
+	}
+	d.Data = buf.String()
+	return nil
+}
+
 // Record represents a MicroDNS DNS record.
 type Record struct {
 	ID   string     `json:"id"`
@@ -89,6 +121,7 @@ type Record struct {
 
 type createZoneRequest struct {
 	Name string `json:"name"`
+	View string `json:"view,omitempty"`
 }
 
 type createRecordRequest struct {
@@ -172,21 +205,19 @@ func (c *Client) listRecordsCached(ctx context.Context, endpoint, zoneID string)
 	return c.ListRecords(ctx, endpoint, zoneID)
 }
 
-// EnsureZone finds an existing zone by name or creates it.
-// Returns the zone UUID.
-func (c *Client) EnsureZone(ctx context.Context, endpoint, zoneName string) (string, error) {
+// ListZones returns the zones of a microdns instance.
+func (c *Client) ListZones(ctx context.Context, endpoint string) ([]Zone, error) {
 	// Skip endpoints known to be unreachable this batch
 	c.mu.Lock()
 	if c.batchMode && c.failedEndpoints[endpoint] {
 		c.mu.Unlock()
-		return "", fmt.Errorf("endpoint %s previously failed this batch, skipping", endpoint)
+		return nil, fmt.Errorf("endpoint %s previously failed this batch, skipping", endpoint)
 	}
 	c.mu.Unlock()
 
-	// List existing zones
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+"/api/v1/zones", nil)
 	if err != nil {
-		return "", fmt.Errorf("building zone list request: %w", err)
+		return nil, fmt.Errorf("building zone list request: %w", err)
 	}
 
 	resp, err := c.http.Do(req)
@@ -196,37 +227,54 @@ func (c *Client) EnsureZone(ctx context.Context, endpoint, zoneName string) (str
 			c.failedEndpoints[endpoint] = true
 		}
 		c.mu.Unlock()
-		return "", fmt.Errorf("listing zones from %s: %w", endpoint, err)
+		return nil, fmt.Errorf("listing zones from %s: %w", endpoint, err)
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("reading zone list response: %w", err)
+		return nil, fmt.Errorf("reading zone list response: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("listing zones: HTTP %d: %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("listing zones: HTTP %d: %s", resp.StatusCode, string(body))
 	}
 
 	var zones []Zone
 	if err := json.Unmarshal(body, &zones); err != nil {
-		return "", fmt.Errorf("decoding zones: %w", err)
+		return nil, fmt.Errorf("decoding zones: %w", err)
+	}
+	for _, z := range zones {
+		c.rememberZone(endpoint, z)
+	}
+	return zones, nil
+}
+
+// EnsureZone finds an existing zone by name or creates it.
+// Returns the zone UUID.
+func (c *Client) EnsureZone(ctx context.Context, endpoint, zoneName string) (string, error) {
+	return c.ensureZone(ctx, endpoint, zoneName, "")
+}
+
+func (c *Client) ensureZone(ctx context.Context, endpoint, zoneName, view string) (string, error) {
+	zones, err := c.ListZones(ctx, endpoint)
+	if err != nil {
+		return "", err
 	}
 
 	// Find existing zone
 	for _, z := range zones {
-		if z.Name == zoneName {
-			c.log.Debugw("found existing zone", "zone", zoneName, "id", z.ID, "endpoint", endpoint)
+		if z.Name == zoneName && z.View == view {
+			c.log.Debugw("found existing zone", "zone", zoneName, "view", view, "id", z.ID, "endpoint", endpoint)
 			return z.ID, nil
 		}
 	}
 
 	// Create zone
-	c.log.Infow("creating zone", "zone", zoneName, "endpoint", endpoint)
-	payload, _ := json.Marshal(createZoneRequest{Name: zoneName})
+	c.log.Infow("creating zone", "zone", zoneName, "view", view, "endpoint", endpoint)
+	payload, _ := json.Marshal(createZoneRequest{Name: zoneName, View: view})
 
-	req, err = http.NewRequestWithContext(ctx, http.MethodPost, endpoint+"/api/v1/zones", bytes.NewReader(payload))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+"/api/v1/zones", bytes.NewReader(payload))
 	if err != nil {
 		return "", fmt.Errorf("building zone create request: %w", err)
 	}
@@ -238,7 +286,7 @@ func (c *Client) EnsureZone(ctx context.Context, endpoint, zoneName string) (str
 	}
 	defer resp2.Body.Close()
 
-	body, err = io.ReadAll(resp2.Body)
+	body, err := io.ReadAll(resp2.Body)
 	if err != nil {
 		return "", fmt.Errorf("reading zone create response: %w", err)
 	}
@@ -252,13 +300,39 @@ func (c *Client) EnsureZone(ctx context.Context, endpoint, zoneName string) (str
 		return "", fmt.Errorf("decoding created zone: %w", err)
 	}
 
-	c.log.Infow("zone created", "zone", zoneName, "id", created.ID, "endpoint", endpoint)
+	c.rememberZone(endpoint, created)
+	c.log.Infow("zone created", "zone", zoneName, "view", view, "id", created.ID, "endpoint", endpoint)
 	return created.ID, nil
 }
 
-// RegisterHost creates an A record in the specified zone.
-// It is idempotent: if a matching A record (same hostname + IP) already
-// exists, the call is a no-op.
+// hostRecordType returns the address record type for ip: AAAA for IPv6,
+// A otherwise.
+func hostRecordType(ip string) string {
+	if parsed := net.ParseIP(ip); parsed != nil && parsed.To4() == nil {
+		return "AAAA"
+	}
+	return "A"
+}
+
+// isHostRecord reports whether r is an A or AAAA record.
+func isHostRecord(r Record) bool {
+	return r.Type == "A" || r.Type == "AAAA"
+}
+
+// sameIP compares two textual IPs, tolerating different IPv6 spellings.
+func sameIP(a, b string) bool {
+	if a == b {
+		return true
+	}
+	pa, pb := net.ParseIP(a), net.ParseIP(b)
+	return pa != nil && pb != nil && pa.Equal(pb)
+}
+
+// RegisterHost creates an A record (AAAA for IPv6 addresses) in the
+// specified zone, plus a PTR when the endpoint has a reverse zone for the
+// address (see EnsureReverseZones). It is idempotent: if a matching record
+// (same hostname + IP) already exists, only its TTL is brought in line and
+// a missing PTR added, so re-registering applies TTL policy changes.
 func (c *Client) RegisterHost(ctx context.Context, endpoint, zoneID, hostname, ip string, ttl int) error {
 	// Skip endpoints known to be unreachable this batch
 	c.mu.Lock()
@@ -269,10 +343,22 @@ func (c *Client) RegisterHost(ctx context.Context, endpoint, zoneID, hostname, i
 	c.mu.Unlock()
 
 	// Check for existing record to avoid creating duplicates.
+	rtype := hostRecordType(ip)
 	records, err := c.listRecordsCached(ctx, endpoint, zoneID)
 	if err == nil {
 		for _, r := range records {
-			if r.Type == "A" && r.Name == hostname && r.Data.Data == ip {
+			if r.Type == rtype && r.Name == hostname && sameIP(r.Data.Data, ip) {
+				if ttl > 0 && r.TTL > 0 && r.TTL != ttl {
+					if _, err := c.UpdateFullRecord(ctx, endpoint, zoneID, r.ID, createRecordRequest{
+						Name: hostname,
+						TTL:  ttl,
+						Data: RecordData{Type: rtype, Data: r.Data.Data},
+					}); err != nil {
+						return fmt.Errorf("updating TTL of %s: %w", hostname, err)
+					}
+					c.log.Infow("DNS record TTL updated", "hostname", hostname, "ip", ip, "zone", zoneID, "from", r.TTL, "to", ttl)
+				}
+				c.registerPTR(ctx, endpoint, zoneID, hostname, ip, ttl)
 				return nil
 			}
 		}
@@ -281,7 +367,7 @@ func (c *Client) RegisterHost(ctx context.Context, endpoint, zoneID, hostname, i
 	payload, _ := json.Marshal(createRecordRequest{
 		Name: hostname,
 		TTL:  ttl,
-		Data: RecordData{Type: "A", Data: ip},
+		Data: RecordData{Type: rtype, Data: ip},
 	})
 
 	url := fmt.Sprintf("%s/api/v1/zones/%s/records", endpoint, zoneID)
@@ -309,6 +395,7 @@ func (c *Client) RegisterHost(ctx context.Context, endpoint, zoneID, hostname, i
 
 	c.invalidateCache(endpoint, zoneID)
 	c.log.Infow("DNS record registered", "hostname", hostname, "ip", ip, "zone", zoneID)
+	c.registerPTR(ctx, endpoint, zoneID, hostname, ip, ttl)
 	return nil
 }
 
@@ -375,7 +462,8 @@ func (c *Client) ListRecords(ctx context.Context, endpoint, zoneID string) ([]Re
 	return all, nil
 }
 
-// DeregisterHost removes all A records matching the given hostname from a zone.
+// DeregisterHost removes all A and AAAA records matching the given hostname
+// from a zone, along with their PTR records.
 func (c *Client) DeregisterHost(ctx context.Context, endpoint, zoneID, hostname string) error {
 	records, err := c.listRecordsCached(ctx, endpoint, zoneID)
 	if err != nil {
@@ -384,12 +472,13 @@ func (c *Client) DeregisterHost(ctx context.Context, endpoint, zoneID, hostname
 
 	deleted := false
 	for _, r := range records {
-		if r.Name == hostname && r.Type == "A" {
+		if r.Name == hostname && isHostRecord(r) {
 			if err := c.DeleteRecord(ctx, endpoint, zoneID, r.ID); err != nil {
 				return err
 			}
 			deleted = true
 			c.log.Infow("DNS record deregistered", "hostname", hostname, "record_id", r.ID, "zone", zoneID)
+			c.deregisterPTR(ctx, endpoint, zoneID, hostname, r.Data.Data)
 		}
 	}
 
@@ -399,7 +488,7 @@ func (c *Client) DeregisterHost(ctx context.Context, endpoint, zoneID, hostname
 	return nil
 }
 
-// DeregisterHostByIP removes A records matching both hostname and IP from a zone.
+// DeregisterHostByIP removes A/AAAA records matching both hostname and IP from a zone.
 // Unlike DeregisterHost which removes all A records for a hostname, this only
 // removes the specific record for one IP — used for cleaning up pod-level
 // round-robin records without removing other containers' entries.
@@ -411,12 +500,13 @@ func (c *Client) DeregisterHostByIP(ctx context.Context, endpoint, zoneID, hostn
 
 	deleted := false
 	for _, r := range records {
-		if r.Name == hostname && r.Type == "A" && r.Data.Data == ip {
+		if r.Name == hostname && isHostRecord(r) && sameIP(r.Data.Data, ip) {
 			if err := c.DeleteRecord(ctx, endpoint, zoneID, r.ID); err != nil {
 				return err
 			}
 			deleted = true
 			c.log.Infow("DNS record deregistered by IP", "hostname", hostname, "ip", ip, "record_id", r.ID, "zone", zoneID)
+			c.deregisterPTR(ctx, endpoint, zoneID, hostname, r.Data.Data)
 		}
 	}
 
@@ -426,18 +516,21 @@ func (c *Client) DeregisterHostByIP(ctx context.Context, endpoint, zoneID, hostn
 	return nil
 }
 
-// CleanStaleRecords removes A records for a hostname where the IP doesn't
-// match the given current IP. Used to clean up stale records when a pod
-// gets a new IP on recreation.
+// CleanStaleRecords removes A records (and their PTRs) for a hostname
+// where the IP doesn't match the given current IP. Used to clean up stale records when a pod
+// gets a new IP on recreation. Only records of currentIP's family are
+// touched (AAAA for an IPv6 address), so a dual-stack host keeps its
+// other-family record.
 func (c *Client) CleanStaleRecords(ctx context.Context, endpoint, zoneID, hostname, currentIP string) error {
 	records, err := c.listRecordsCached(ctx, endpoint, zoneID)
 	if err != nil {
 		return err
 	}
 
+	rtype := hostRecordType(currentIP)
 	deleted := false
 	for _, r := range records {
-		if r.Name == hostname && r.Type == "A" && r.Data.Data != currentIP {
+		if r.Name == hostname && r.Type == rtype && !sameIP(r.Data.Data, currentIP) {
 			if err := c.DeleteRecord(ctx, endpoint, zoneID, r.ID); err != nil {
 				c.log.Warnw("failed to delete stale DNS record",
 					"hostname", hostname, "stale_ip", r.Data.Data,
@@ -447,6 +540,7 @@ func (c *Client) CleanStaleRecords(ctx context.Context, endpoint, zoneID, hostna
 				c.log.Infow("removed stale DNS record",
 					"hostname", hostname, "stale_ip", r.Data.Data,
 					"current_ip", currentIP)
+				c.deregisterPTR(ctx, endpoint, zoneID, hostname, r.Data.Data)
 			}
 		}
 	}
@@ -474,6 +568,9 @@ type DHCPPool struct {
 	BootFileEFI   string   `json:"boot_file_efi,omitempty"`
 	IPXEBootURL   string   `json:"ipxe_boot_url,omitempty"`
 	RootPath      string   `json:"root_path,omitempty"`
+
+	Options       []DHCPOption      `json:"options,omitempty"`
+	VendorClasses []DHCPVendorClass `json:"vendor_classes,omitempty"`
 }
 
 // ListDHCPPools returns all DHCP pools from a microdns instance.
@@ -570,6 +667,8 @@ type DHCPReservation struct {
 	BootFileEFI string   `json:"boot_file_efi,omitempty"`
 	IPXEBootURL string   `json:"ipxe_boot_url,omitempty"`
 	RootPath    string   `json:"root_path,omitempty"`
+
+	Options []DHCPOption `json:"options,omitempty"`
 }
 
 // ListDHCPReservations returns all DHCP reservations from a microdns instance.
@@ -95,6 +95,44 @@ func TestRegisterHost(t *testing.T) {
 	}
 }
 
+func TestRegisterHost_UpdatesTTL(t *testing.T) {
+	records := []Record{{ID: "rec-1", Name: "myapp", Type: "A", TTL: 300, Data: RecordData{Type: "A", Data: "192.168.200.5"}}}
+	var updated createRecordRequest
+	var puts int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet:
+			_ = json.NewEncoder(w).Encode(records)
+		case r.Method == http.MethodPut && r.URL.Path == "/api/v1/zones/zone-123/records/rec-1":
+			puts++
+			_ = json.NewDecoder(r.Body).Decode(&updated)
+			records[0].TTL = updated.TTL
+			_ = json.NewEncoder(w).Encode(records[0])
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer srv.Close()
+
+	c := NewClient(testLogger())
+	ctx := context.Background()
+	if err := c.RegisterHost(ctx, srv.URL, "zone-123", "myapp", "192.168.200.5", 5); err != nil {
+		t.Fatalf("RegisterHost: %v", err)
+	}
+	if puts != 1 || updated.TTL != 5 || updated.Data.Data != "192.168.200.5" {
+		t.Fatalf("expected one TTL update to 5, got %d puts, %+v", puts, updated)
+	}
+
+	// Same TTL again is a no-op
+	if err := c.RegisterHost(ctx, srv.URL, "zone-123", "myapp", "192.168.200.5", 5); err != nil {
+		t.Fatalf("RegisterHost: %v", err)
+	}
+	if puts != 1 {
+		t.Errorf("expected no further update, got %d puts", puts)
+	}
+}
+
 func TestDeregisterHost(t *testing.T) {
 	deleted := make(map[string]bool)
 	records := []Record{
@@ -136,6 +174,56 @@ func TestDeregisterHost(t *testing.T) {
 	}
 }
 
+func TestDualStackHostRecords(t *testing.T) {
+	var created createRecordRequest
+	deleted := make(map[string]bool)
+	records := []Record{
+		{ID: "rec-1", Name: "myapp", Type: "A", Data: RecordData{Type: "A", Data: "192.168.200.5"}},
+		{ID: "rec-2", Name: "myapp", Type: "AAAA", Data: RecordData{Type: "AAAA", Data: "fd00::5"}},
+		{ID: "rec-3", Name: "myapp", Type: "AAAA", Data: RecordData{Type: "AAAA", Data: "fd00::9"}},
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			_ = json.NewEncoder(w).Encode(records)
+		case http.MethodPost:
+			_ = json.NewDecoder(r.Body).Decode(&created)
+			w.WriteHeader(http.StatusCreated)
+		case http.MethodDelete:
+			deleted[r.URL.Path] = true
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer srv.Close()
+
+	c := NewClient(testLogger())
+	ctx := context.Background()
+
+	// Existing AAAA (spelled differently) is a no-op; a new one is AAAA
+	if err := c.RegisterHost(ctx, srv.URL, "zone-123", "myapp", "fd00:0::5", 60); err != nil {
+		t.Fatalf("RegisterHost: %v", err)
+	}
+	if created.Name != "" {
+		t.Fatalf("duplicate AAAA record created: %+v", created)
+	}
+	if err := c.RegisterHost(ctx, srv.URL, "zone-123", "other", "fd00::7", 60); err != nil {
+		t.Fatalf("RegisterHost: %v", err)
+	}
+	if created.Data.Type != "AAAA" {
+		t.Errorf("expected record type AAAA, got %q", created.Data.Type)
+	}
+
+	// Cleaning stale IPv6 records leaves the A record alone
+	if err := c.CleanStaleRecords(ctx, srv.URL, "zone-123", "myapp", "fd00::5"); err != nil {
+		t.Fatalf("CleanStaleRecords: %v", err)
+	}
+	want := map[string]bool{"/api/v1/zones/zone-123/records/rec-3": true}
+	if len(deleted) != 1 || !deleted["/api/v1/zones/zone-123/records/rec-3"] {
+		t.Errorf("deleted %v, want %v", deleted, want)
+	}
+}
+
 func TestEnsureZone_ServerError(t *testing.T) {
 	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusInternalServerError)
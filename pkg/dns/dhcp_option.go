@@ -0,0 +1,210 @@
+package dns
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// ─── DHCP Options ───────────────────────────────────────────────────────────
+
+// DHCPOption is an extra DHCPv4 option microdns adds to its offers and
+// acks. Value is the human form in the encoding named by Type; Data is the
+// wire form (hex) filled in by EncodeDHCPOptions, which microdns sends
+// verbatim.
+type DHCPOption struct {
+	Code  int    `json:"code"`
+	Type  string `json:"type"`
+	Value string `json:"value"`
+	Data  string `json:"data,omitempty"`
+}
+
+// DHCPVendorClass applies options to clients whose vendor class identifier
+// (option 60) starts with Match, e.g. "PXEClient" or "udhcp".
+type DHCPVendorClass struct {
+	Match   string       `json:"match"`
+	Options []DHCPOption `json:"options"`
+}
+
+// DHCP option value encodings.
+const (
+	DHCPOptionIP     = "ip"     // one IPv4 address
+	DHCPOptionIPs    = "ips"    // comma-separated IPv4 addresses
+	DHCPOptionString = "string" // text
+	DHCPOptionUint8  = "uint8"
+	DHCPOptionUint16 = "uint16"
+	DHCPOptionUint32 = "uint32"
+	DHCPOptionBool   = "bool"
+	DHCPOptionHex    = "hex"    // raw bytes, e.g. "01:04:c0:a8:01:01"
+	DHCPOptionRoutes = "routes" // "10.0.0.0/8 via 192.168.1.1, ..." (RFC 3442)
+)
+
+// defaultDHCPOptionTypes is the encoding of well-known options when an
+// option does not name one.
+var defaultDHCPOptionTypes = map[int]string{
+	4:   DHCPOptionIPs,    // time servers
+	7:   DHCPOptionIPs,    // log servers
+	26:  DHCPOptionUint16, // interface MTU
+	28:  DHCPOptionIP,     // broadcast address
+	42:  DHCPOptionIPs,    // NTP servers
+	43:  DHCPOptionHex,    // vendor specific information
+	44:  DHCPOptionIPs,    // NetBIOS name servers
+	66:  DHCPOptionString, // TFTP server name
+	67:  DHCPOptionString, // bootfile name
+	121: DHCPOptionRoutes, // classless static routes
+	150: DHCPOptionIPs,    // TFTP server addresses (Cisco)
+	249: DHCPOptionRoutes, // classless static routes (Microsoft)
+	252: DHCPOptionString, // WPAD URL
+}
+
+// managedDHCPOptions are set by microdns from the pool and reservation
+// fields or belong to the protocol itself, so they cannot be overridden.
+var managedDHCPOptions = map[int]string{
+	0:   "pad",
+	1:   "subnet mask (from the subnet)",
+	3:   "router (use gateway)",
+	6:   "DNS servers (use dnsServers)",
+	15:  "domain name (use domain)",
+	50:  "requested address",
+	51:  "lease time (use leaseTime)",
+	53:  "message type",
+	54:  "server identifier",
+	55:  "parameter request list",
+	57:  "maximum message size",
+	61:  "client identifier",
+	82:  "relay agent information",
+	255: "end",
+}
+
+// DHCPOptionType returns the encoding of an option: its own Type, else the
+// default for its code.
+func DHCPOptionType(opt DHCPOption) string {
+	if opt.Type != "" {
+		return strings.ToLower(opt.Type)
+	}
+	return defaultDHCPOptionTypes[opt.Code]
+}
+
+// EncodeDHCPOption validates an option and returns its wire bytes.
+func EncodeDHCPOption(opt DHCPOption) ([]byte, error) {
+	if opt.Code < 1 || opt.Code > 254 {
+		return nil, fmt.Errorf("code %d is out of range 1-254", opt.Code)
+	}
+	if why, ok := managedDHCPOptions[opt.Code]; ok {
+		return nil, fmt.Errorf("option %d (%s) is managed by the DHCP server", opt.Code, why)
+	}
+	typ := DHCPOptionType(opt)
+	if typ == "" {
+		return nil, fmt.Errorf("option %d needs a type (ip, ips, string, uint8, uint16, uint32, bool, hex, routes)", opt.Code)
+	}
+	value := strings.TrimSpace(opt.Value)
+	if value == "" {
+		return nil, fmt.Errorf("option %d has no value", opt.Code)
+	}
+
+	var data []byte
+	switch typ {
+	case DHCPOptionIP, DHCPOptionIPs:
+		for _, s := range strings.Split(value, ",") {
+			ip := net.ParseIP(strings.TrimSpace(s)).To4()
+			if ip == nil {
+				return nil, fmt.Errorf("option %d: %q is not an IPv4 address", opt.Code, strings.TrimSpace(s))
+			}
+			data = append(data, ip...)
+		}
+		if typ == DHCPOptionIP && len(data) != 4 {
+			return nil, fmt.Errorf("option %d takes a single address", opt.Code)
+		}
+	case DHCPOptionString:
+		data = []byte(value)
+	case DHCPOptionUint8, DHCPOptionUint16, DHCPOptionUint32:
+		bits := map[string]int{DHCPOptionUint8: 8, DHCPOptionUint16: 16, DHCPOptionUint32: 32}[typ]
+		n, err := strconv.ParseUint(value, 10, bits)
+		if err != nil {
+			return nil, fmt.Errorf("option %d: %q is not a %s", opt.Code, value, typ)
+		}
+		for i := bits/8 - 1; i >= 0; i-- {
+			data = append(data, byte(n>>(8*i)))
+		}
+	case DHCPOptionBool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("option %d: %q is not a bool", opt.Code, value)
+		}
+		data = []byte{0}
+		if b {
+			data[0] = 1
+		}
+	case DHCPOptionHex:
+		raw, err := hex.DecodeString(strings.NewReplacer(":", "", " ", "").Replace(value))
+		if err != nil {
+			return nil, fmt.Errorf("option %d: %q is not hex", opt.Code, value)
+		}
+		data = raw
+	case DHCPOptionRoutes:
+		raw, err := encodeClasslessRoutes(value)
+		if err != nil {
+			return nil, fmt.Errorf("option %d: %w", opt.Code, err)
+		}
+		data = raw
+	default:
+		return nil, fmt.Errorf("option %d: unknown type %q", opt.Code, typ)
+	}
+
+	if len(data) > 255 {
+		return nil, fmt.Errorf("option %d is %d bytes, the limit is 255", opt.Code, len(data))
+	}
+	return data, nil
+}
+
+// encodeClasslessRoutes encodes "dest/len via router" pairs as RFC 3442
+// descriptors: prefix length, the significant octets of the destination,
+// then the router.
+func encodeClasslessRoutes(value string) ([]byte, error) {
+	var data []byte
+	for _, route := range strings.Split(value, ",") {
+		fields := strings.Fields(route)
+		if len(fields) != 3 || fields[1] != "via" {
+			return nil, fmt.Errorf("route %q is not \"<cidr> via <router>\"", strings.TrimSpace(route))
+		}
+		_, dest, err := net.ParseCIDR(fields[0])
+		if err != nil || dest.IP.To4() == nil {
+			return nil, fmt.Errorf("route destination %q is not an IPv4 CIDR", fields[0])
+		}
+		router := net.ParseIP(fields[2]).To4()
+		if router == nil {
+			return nil, fmt.Errorf("router %q is not an IPv4 address", fields[2])
+		}
+		ones, _ := dest.Mask.Size()
+		data = append(data, byte(ones))
+		data = append(data, dest.IP.To4()[:(ones+7)/8]...)
+		data = append(data, router...)
+	}
+	return data, nil
+}
+
+// EncodeDHCPOptions validates options and returns copies with Type and
+// Data filled in, ready to push to microdns. Codes must not repeat.
+func EncodeDHCPOptions(opts []DHCPOption) ([]DHCPOption, error) {
+	if len(opts) == 0 {
+		return nil, nil
+	}
+	out := make([]DHCPOption, 0, len(opts))
+	seen := make(map[int]bool)
+	for _, opt := range opts {
+		if seen[opt.Code] {
+			return nil, fmt.Errorf("option %d is set twice", opt.Code)
+		}
+		seen[opt.Code] = true
+		data, err := EncodeDHCPOption(opt)
+		if err != nil {
+			return nil, err
+		}
+		opt.Type = DHCPOptionType(opt)
+		opt.Data = hex.EncodeToString(data)
+		out = append(out, opt)
+	}
+	return out, nil
+}
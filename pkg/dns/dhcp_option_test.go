@@ -0,0 +1,63 @@
+package dns
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestEncodeDHCPOption(t *testing.T) {
+	tests := []struct {
+		opt  DHCPOption
+		want string // hex, or "error: <substring>"
+	}{
+		{DHCPOption{Code: 42, Value: "192.168.1.1, 192.168.1.2"}, "c0a80101c0a80102"},
+		{DHCPOption{Code: 66, Value: "tftp.gt.lo"}, hex.EncodeToString([]byte("tftp.gt.lo"))},
+		{DHCPOption{Code: 43, Value: "01:04:c0:a8:01:0a"}, "0104c0a8010a"},
+		{DHCPOption{Code: 26, Value: "9000"}, "2328"},
+		{DHCPOption{Code: 224, Type: "uint32", Value: "1"}, "00000001"},
+		{DHCPOption{Code: 224, Type: "bool", Value: "true"}, "01"},
+		// RFC 3442: 10.0.0.0/8 via 192.168.1.1, 0.0.0.0/0 via 192.168.1.254
+		{DHCPOption{Code: 121, Value: "10.0.0.0/8 via 192.168.1.1, 0.0.0.0/0 via 192.168.1.254"}, "080ac0a8010100c0a801fe"},
+		{DHCPOption{Code: 121, Value: "172.16.0.0/12 via 10.1.1.1"}, "0cac100a010101"},
+
+		{DHCPOption{Code: 3, Value: "192.168.1.1"}, "error: managed"},
+		{DHCPOption{Code: 300, Type: "string", Value: "x"}, "error: out of range"},
+		{DHCPOption{Code: 224, Value: "x"}, "error: needs a type"},
+		{DHCPOption{Code: 42, Value: "ntp.gt.lo"}, "error: not an IPv4 address"},
+		{DHCPOption{Code: 28, Value: "192.168.1.255,192.168.2.255"}, "error: single address"},
+		{DHCPOption{Code: 26, Value: "70000"}, "error: not a uint16"},
+		{DHCPOption{Code: 121, Value: "10.0.0.0/8 192.168.1.1"}, "error: via"},
+		{DHCPOption{Code: 67, Value: "  "}, "error: no value"},
+		{DHCPOption{Code: 67, Value: strings.Repeat("x", 256)}, "error: limit is 255"},
+	}
+	for _, tt := range tests {
+		data, err := EncodeDHCPOption(tt.opt)
+		if want, ok := strings.CutPrefix(tt.want, "error: "); ok {
+			if err == nil || !strings.Contains(err.Error(), want) {
+				t.Errorf("option %d %q: error = %v, want %q", tt.opt.Code, tt.opt.Value, err, want)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("option %d %q: %v", tt.opt.Code, tt.opt.Value, err)
+			continue
+		}
+		if got := hex.EncodeToString(data); got != tt.want {
+			t.Errorf("option %d %q = %s, want %s", tt.opt.Code, tt.opt.Value, got, tt.want)
+		}
+	}
+}
+
+func TestEncodeDHCPOptions(t *testing.T) {
+	out, err := EncodeDHCPOptions([]DHCPOption{{Code: 42, Value: "10.0.0.1"}, {Code: 67, Value: "undionly.kpxe"}})
+	if err != nil {
+		t.Fatalf("EncodeDHCPOptions: %v", err)
+	}
+	if out[0].Type != DHCPOptionIPs || out[0].Data != "0a000001" || out[1].Type != DHCPOptionString {
+		t.Errorf("encoded = %+v", out)
+	}
+	if _, err := EncodeDHCPOptions([]DHCPOption{{Code: 42, Value: "10.0.0.1"}, {Code: 42, Value: "10.0.0.2"}}); err == nil {
+		t.Error("duplicate option code accepted")
+	}
+}
@@ -0,0 +1,426 @@
+package dns
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// ─── Fallback Responder ─────────────────────────────────────────────────────
+
+// fallbackMaxTTL caps the TTL of every answer the responder gives, so
+// resolvers go back to microdns soon after it recovers.
+const fallbackMaxTTL = 30
+
+// maxUDPResponse is the classic DNS-over-UDP limit; larger responses are
+// truncated.
+const maxUDPResponse = 512
+
+// Responder is a minimal authoritative DNS server over UDP for zones whose
+// records it is handed in memory. mkube runs one while the microdns
+// instances are unreachable, so names like the registry keep resolving and
+// microdns itself can be pulled and restarted. It answers A, AAAA, CNAME,
+// TXT, SRV and MX from the records, SOA and NS at each zone apex, and PTR
+// for any address one of the zones holds. Names outside the zones are
+// refused: the responder does not recurse.
+type Responder struct {
+	addr string
+	log  *zap.SugaredLogger
+
+	mu    sync.RWMutex
+	zones map[string][]Record // zone name (lower case, no trailing dot) -> records relative to it
+	conn  net.PacketConn
+}
+
+// NewResponder creates a stopped responder that will listen on addr
+// (e.g. ":53").
+func NewResponder(addr string, log *zap.SugaredLogger) *Responder {
+	return &Responder{
+		addr:  addr,
+		log:   log.Named("fallback-dns"),
+		zones: make(map[string][]Record),
+	}
+}
+
+// SetZones replaces the served zones. Record names are relative to their
+// zone ("@" for the apex).
+func (s *Responder) SetZones(zones map[string][]Record) {
+	out := make(map[string][]Record, len(zones))
+	for zone, records := range zones {
+		zone = strings.ToLower(strings.TrimSuffix(zone, "."))
+		out[zone] = append(out[zone], records...)
+	}
+	s.mu.Lock()
+	s.zones = out
+	s.mu.Unlock()
+}
+
+// Start binds the listener and serves until Stop. Starting a running
+// responder is a no-op.
+func (s *Responder) Start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn != nil {
+		return nil
+	}
+	conn, err := net.ListenPacket("udp", s.addr)
+	if err != nil {
+		return fmt.Errorf("fallback DNS listen on %s: %w", s.addr, err)
+	}
+	s.conn = conn
+	go s.serve(conn)
+	s.log.Infow("fallback DNS responder started", "addr", conn.LocalAddr().String(), "zones", len(s.zones))
+	return nil
+}
+
+// Stop closes the listener. Stopping a stopped responder is a no-op.
+func (s *Responder) Stop() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	s.log.Infow("fallback DNS responder stopped")
+	return err
+}
+
+// Running reports whether the responder is serving.
+func (s *Responder) Running() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.conn != nil
+}
+
+// LocalAddr returns the address to query the running responder at, with
+// an unspecified listen address replaced by loopback; "" when stopped.
+func (s *Responder) LocalAddr() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.conn == nil {
+		return ""
+	}
+	addr, ok := s.conn.LocalAddr().(*net.UDPAddr)
+	if !ok {
+		return s.conn.LocalAddr().String()
+	}
+	ip := addr.IP
+	if ip == nil || ip.IsUnspecified() {
+		ip = net.IPv4(127, 0, 0, 1)
+	}
+	return net.JoinHostPort(ip.String(), fmt.Sprint(addr.Port))
+}
+
+func (s *Responder) serve(conn net.PacketConn) {
+	buf := make([]byte, 65535)
+	for {
+		n, from, err := conn.ReadFrom(buf)
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				s.log.Warnw("fallback DNS read failed", "error", err)
+			}
+			return
+		}
+		if resp := s.answer(buf[:n]); resp != nil {
+			_, _ = conn.WriteTo(resp, from)
+		}
+	}
+}
+
+// answer builds the response to one query message; nil drops it.
+func (s *Responder) answer(query []byte) []byte {
+	var p dnsmessage.Parser
+	hdr, err := p.Start(query)
+	if err != nil || hdr.Response {
+		return nil
+	}
+	q, err := p.Question()
+	if err != nil {
+		return nil
+	}
+
+	resp := dnsmessage.Message{
+		Header: dnsmessage.Header{
+			ID:               hdr.ID,
+			Response:         true,
+			OpCode:           hdr.OpCode,
+			RecursionDesired: hdr.RecursionDesired,
+		},
+		Questions: []dnsmessage.Question{q},
+	}
+	switch {
+	case hdr.OpCode != 0:
+		resp.RCode = dnsmessage.RCodeNotImplemented
+	case q.Class != dnsmessage.ClassINET && q.Class != dnsmessage.ClassANY:
+		resp.RCode = dnsmessage.RCodeRefused
+	default:
+		s.mu.RLock()
+		s.resolve(&resp, q)
+		s.mu.RUnlock()
+	}
+
+	out, err := resp.Pack()
+	if err != nil {
+		s.log.Warnw("fallback DNS response not packable", "name", q.Name.String(), "error", err)
+		return nil
+	}
+	if len(out) > maxUDPResponse {
+		resp.Truncated = true
+		resp.Answers, resp.Authorities = nil, nil
+		out, _ = resp.Pack()
+	}
+	return out
+}
+
+// resolve fills in the answer to q, following CNAMEs within the zones.
+func (s *Responder) resolve(resp *dnsmessage.Message, q dnsmessage.Question) {
+	name := strings.ToLower(strings.TrimSuffix(q.Name.String(), "."))
+	zone, rel, ok := s.findZone(name)
+	if !ok {
+		if q.Type == dnsmessage.TypePTR {
+			if target, ok := s.reverse(name); ok {
+				resp.Authoritative = true
+				s.appendRR(&resp.Answers, q.Name, "", Record{Type: "PTR", TTL: fallbackMaxTTL, Data: RecordData{Type: "PTR", Data: target}})
+				return
+			}
+		}
+		resp.RCode = dnsmessage.RCodeRefused
+		return
+	}
+	resp.Authoritative = true
+
+	owner := q.Name
+	for hop := 0; hop < 8; hop++ {
+		records, exists := s.lookup(zone, rel)
+		if !exists {
+			if hop == 0 {
+				resp.RCode = dnsmessage.RCodeNameError
+			}
+			s.appendSOA(&resp.Authorities, zone)
+			return
+		}
+
+		if q.Type != dnsmessage.TypeCNAME {
+			if cname, ok := findType(records, "CNAME"); ok {
+				s.appendRR(&resp.Answers, owner, zone, cname)
+				target := fqdn(cname.Data.Data, zone)
+				next, nextRel, ok := s.findZone(strings.TrimSuffix(target, "."))
+				if !ok {
+					return // the resolver follows targets outside our zones
+				}
+				owner, zone, rel = mustName(target), next, nextRel
+				continue
+			}
+		}
+
+		answered := false
+		for _, r := range records {
+			if q.Type == dnsmessage.TypeALL || recordType(r.Type) == q.Type {
+				answered = s.appendRR(&resp.Answers, owner, zone, r) || answered
+			}
+		}
+		if rel == "@" {
+			switch q.Type {
+			case dnsmessage.TypeSOA:
+				s.appendSOA(&resp.Answers, zone)
+				answered = true
+			case dnsmessage.TypeNS:
+				resp.Answers = append(resp.Answers, dnsmessage.Resource{
+					Header: rrHeader(owner, dnsmessage.TypeNS, fallbackMaxTTL),
+					Body:   &dnsmessage.NSResource{NS: mustName("dns." + zone + ".")},
+				})
+				answered = true
+			}
+		}
+		if !answered {
+			s.appendSOA(&resp.Authorities, zone)
+		}
+		return
+	}
+}
+
+// findZone returns the most specific zone holding name and name relative
+// to it ("@" for the apex).
+func (s *Responder) findZone(name string) (zone, rel string, ok bool) {
+	for z := range s.zones {
+		switch {
+		case name == z:
+			if len(z) > len(zone) {
+				zone, rel, ok = z, "@", true
+			}
+		case strings.HasSuffix(name, "."+z):
+			if len(z) > len(zone) {
+				zone, rel, ok = z, strings.TrimSuffix(name, "."+z), true
+			}
+		}
+	}
+	return zone, rel, ok
+}
+
+// lookup returns the records at rel. exists is false for names that hold
+// no records and have none below them.
+func (s *Responder) lookup(zone, rel string) (records []Record, exists bool) {
+	exists = rel == "@"
+	for _, r := range s.zones[zone] {
+		name := strings.ToLower(r.Name)
+		if name == "" {
+			name = "@"
+		}
+		switch {
+		case name == rel:
+			records = append(records, r)
+			exists = true
+		case strings.HasSuffix(name, "."+rel):
+			exists = true // empty non-terminal
+		}
+	}
+	return records, exists
+}
+
+// reverse finds the host owning the address of a PTR query name.
+func (s *Responder) reverse(name string) (string, bool) {
+	for zone, records := range s.zones {
+		for _, r := range records {
+			if r.Type != "A" && r.Type != "AAAA" {
+				continue
+			}
+			ip := net.ParseIP(r.Data.Data)
+			if ip != nil && ReverseName(ip) == name {
+				return fqdn(r.Name, zone), true
+			}
+		}
+	}
+	return "", false
+}
+
+func findType(records []Record, rtype string) (Record, bool) {
+	for _, r := range records {
+		if r.Type == rtype {
+			return r, true
+		}
+	}
+	return Record{}, false
+}
+
+// fqdn qualifies a record name or target relative to zone.
+func fqdn(name, zone string) string {
+	switch {
+	case name == "" || name == "@":
+		return zone + "."
+	case strings.HasSuffix(name, "."):
+		return name
+	}
+	return name + "." + zone + "."
+}
+
+func mustName(name string) dnsmessage.Name {
+	n, err := dnsmessage.NewName(name)
+	if err != nil {
+		return dnsmessage.MustNewName(".")
+	}
+	return n
+}
+
+func recordType(rtype string) dnsmessage.Type {
+	switch rtype {
+	case "A":
+		return dnsmessage.TypeA
+	case "AAAA":
+		return dnsmessage.TypeAAAA
+	case "CNAME":
+		return dnsmessage.TypeCNAME
+	case "TXT":
+		return dnsmessage.TypeTXT
+	case "SRV":
+		return dnsmessage.TypeSRV
+	case "MX":
+		return dnsmessage.TypeMX
+	case "PTR":
+		return dnsmessage.TypePTR
+	}
+	return 0
+}
+
+func rrHeader(owner dnsmessage.Name, rtype dnsmessage.Type, ttl int) dnsmessage.ResourceHeader {
+	if ttl <= 0 || ttl > fallbackMaxTTL {
+		ttl = fallbackMaxTTL
+	}
+	return dnsmessage.ResourceHeader{Name: owner, Type: rtype, Class: dnsmessage.ClassINET, TTL: uint32(ttl)}
+}
+
+// appendRR converts a record to a resource; records with malformed data
+// are skipped and reported false.
+func (s *Responder) appendRR(out *[]dnsmessage.Resource, owner dnsmessage.Name, zone string, r Record) bool {
+	var body dnsmessage.ResourceBody
+	data := r.Data.Data
+	switch r.Type {
+	case "A":
+		ip := net.ParseIP(data).To4()
+		if ip == nil {
+			return false
+		}
+		body = &dnsmessage.AResource{A: [4]byte(ip)}
+	case "AAAA":
+		ip := net.ParseIP(data)
+		if ip == nil || ip.To4() != nil {
+			return false
+		}
+		body = &dnsmessage.AAAAResource{AAAA: [16]byte(ip.To16())}
+	case "CNAME":
+		body = &dnsmessage.CNAMEResource{CNAME: mustName(fqdn(data, zone))}
+	case "PTR":
+		body = &dnsmessage.PTRResource{PTR: mustName(fqdn(data, zone))}
+	case "TXT":
+		var chunks []string
+		for len(data) > 255 {
+			chunks, data = append(chunks, data[:255]), data[255:]
+		}
+		body = &dnsmessage.TXTResource{TXT: append(chunks, data)}
+	case "SRV":
+		srv, ok := r.Data.SRV()
+		if !ok {
+			return false
+		}
+		body = &dnsmessage.SRVResource{
+			Priority: uint16(srv.Priority),
+			Weight:   uint16(srv.Weight),
+			Port:     uint16(srv.Port),
+			Target:   mustName(fqdn(srv.Target, zone)),
+		}
+	case "MX":
+		var mx MXData
+		if err := json.Unmarshal([]byte(data), &mx); err != nil {
+			return false
+		}
+		body = &dnsmessage.MXResource{Pref: uint16(mx.Preference), MX: mustName(fqdn(mx.Exchange, zone))}
+	default:
+		return false
+	}
+	*out = append(*out, dnsmessage.Resource{Header: rrHeader(owner, recordType(r.Type), r.TTL), Body: body})
+	return true
+}
+
+// appendSOA adds a synthesized SOA for zone; its minimum TTL keeps negative
+// answers as short-lived as positive ones.
+func (s *Responder) appendSOA(out *[]dnsmessage.Resource, zone string) {
+	*out = append(*out, dnsmessage.Resource{
+		Header: rrHeader(mustName(zone+"."), dnsmessage.TypeSOA, fallbackMaxTTL),
+		Body: &dnsmessage.SOAResource{
+			NS:      mustName("dns." + zone + "."),
+			MBox:    mustName("hostmaster." + zone + "."),
+			Serial:  uint32(time.Now().Unix()),
+			Refresh: 60,
+			Retry:   30,
+			Expire:  300,
+			MinTTL:  fallbackMaxTTL,
+		},
+	})
+}
@@ -0,0 +1,140 @@
+package dns
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+func testRecord(name, rtype, data string) Record {
+	return Record{Name: name, Type: rtype, TTL: 300, Data: RecordData{Type: rtype, Data: data}}
+}
+
+// query sends one question to the responder over UDP and parses the reply.
+func query(t *testing.T, addr, name string, qtype dnsmessage.Type) dnsmessage.Message {
+	t.Helper()
+	msg := dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: 42, RecursionDesired: true},
+		Questions: []dnsmessage.Question{{Name: dnsmessage.MustNewName(name), Type: qtype, Class: dnsmessage.ClassINET}},
+	}
+	packed, err := msg.Pack()
+	if err != nil {
+		t.Fatalf("pack: %v", err)
+	}
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(2 * time.Second))
+	if _, err := conn.Write(packed); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	buf := make([]byte, 1500)
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatalf("read %s: %v", name, err)
+	}
+	var resp dnsmessage.Message
+	if err := resp.Unpack(buf[:n]); err != nil {
+		t.Fatalf("unpack: %v", err)
+	}
+	if resp.ID != 42 || !resp.Response {
+		t.Fatalf("bad header: %+v", resp.Header)
+	}
+	return resp
+}
+
+func TestResponder(t *testing.T) {
+	s := NewResponder("127.0.0.1:0", zap.NewNop().Sugar())
+	s.SetZones(map[string][]Record{
+		"gt.lo": {
+			testRecord("registry", "A", "192.168.200.3"),
+			testRecord("dns", "A", "192.168.200.199"),
+			testRecord("www", "CNAME", "registry"),
+			testRecord("registry", "TXT", "role=registry"),
+			testRecord("_http._tcp.registry", "SRV", `{"priority":0,"weight":0,"port":5000,"target":"registry.gt.lo."}`),
+			testRecord("v6", "AAAA", "fd00::3"),
+		},
+		"kube.gt.lo.": {
+			testRecord("api.mkube", "A", "192.168.200.10"),
+		},
+	})
+	if s.Running() || s.LocalAddr() != "" {
+		t.Fatal("new responder should be stopped")
+	}
+	if err := s.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer s.Stop()
+	addr := s.LocalAddr()
+
+	resp := query(t, addr, "registry.gt.lo.", dnsmessage.TypeA)
+	if !resp.Authoritative || len(resp.Answers) != 1 {
+		t.Fatalf("A registry: %+v", resp)
+	}
+	if a := resp.Answers[0].Body.(*dnsmessage.AResource).A; net.IP(a[:]).String() != "192.168.200.3" {
+		t.Errorf("A registry = %v", a)
+	}
+	if ttl := resp.Answers[0].Header.TTL; ttl != fallbackMaxTTL {
+		t.Errorf("TTL = %d, want %d", ttl, fallbackMaxTTL)
+	}
+
+	// CNAME targets inside the zones are followed
+	resp = query(t, addr, "WWW.gt.lo.", dnsmessage.TypeA)
+	if len(resp.Answers) != 2 || resp.Answers[0].Header.Type != dnsmessage.TypeCNAME || resp.Answers[1].Header.Type != dnsmessage.TypeA {
+		t.Errorf("CNAME chain: %+v", resp.Answers)
+	}
+
+	resp = query(t, addr, "_http._tcp.registry.gt.lo.", dnsmessage.TypeSRV)
+	if len(resp.Answers) != 1 || resp.Answers[0].Body.(*dnsmessage.SRVResource).Port != 5000 {
+		t.Errorf("SRV: %+v", resp.Answers)
+	}
+	resp = query(t, addr, "registry.gt.lo.", dnsmessage.TypeTXT)
+	if len(resp.Answers) != 1 || resp.Answers[0].Body.(*dnsmessage.TXTResource).TXT[0] != "role=registry" {
+		t.Errorf("TXT: %+v", resp.Answers)
+	}
+
+	// The most specific zone wins
+	resp = query(t, addr, "api.mkube.kube.gt.lo.", dnsmessage.TypeA)
+	if len(resp.Answers) != 1 {
+		t.Errorf("nested zone: %+v", resp)
+	}
+
+	// Missing names get NXDOMAIN, missing types NODATA, both with the SOA
+	resp = query(t, addr, "nope.gt.lo.", dnsmessage.TypeA)
+	if resp.RCode != dnsmessage.RCodeNameError || len(resp.Authorities) != 1 {
+		t.Errorf("NXDOMAIN: %+v", resp)
+	}
+	resp = query(t, addr, "v6.gt.lo.", dnsmessage.TypeA)
+	if resp.RCode != dnsmessage.RCodeSuccess || len(resp.Answers) != 0 || len(resp.Authorities) != 1 {
+		t.Errorf("NODATA: %+v", resp)
+	}
+	// "_tcp.registry" only exists below it
+	resp = query(t, addr, "_tcp.registry.gt.lo.", dnsmessage.TypeA)
+	if resp.RCode != dnsmessage.RCodeSuccess {
+		t.Errorf("empty non-terminal: %v", resp.RCode)
+	}
+
+	resp = query(t, addr, "gt.lo.", dnsmessage.TypeSOA)
+	if len(resp.Answers) != 1 || resp.Answers[0].Header.Type != dnsmessage.TypeSOA {
+		t.Errorf("SOA: %+v", resp.Answers)
+	}
+	resp = query(t, addr, "3.200.168.192.in-addr.arpa.", dnsmessage.TypePTR)
+	if len(resp.Answers) != 1 || resp.Answers[0].Body.(*dnsmessage.PTRResource).PTR.String() != "registry.gt.lo." {
+		t.Errorf("PTR: %+v", resp.Answers)
+	}
+
+	// No recursion for names outside the zones
+	resp = query(t, addr, "example.com.", dnsmessage.TypeA)
+	if resp.RCode != dnsmessage.RCodeRefused {
+		t.Errorf("outside zones: %v", resp.RCode)
+	}
+
+	if err := s.Stop(); err != nil || s.Running() {
+		t.Fatalf("Stop: %v", err)
+	}
+}
@@ -0,0 +1,380 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net"
+	"sort"
+	"strings"
+)
+
+// ─── Reverse Zones (PTR) ────────────────────────────────────────────────────
+
+// ReverseZone is an in-addr.arpa or ip6.arpa zone on a microdns instance,
+// covering the addresses of Subnet.
+type ReverseZone struct {
+	Name   string
+	ID     string
+	Subnet *net.IPNet
+}
+
+// maxReverseZones bounds how many zones one CIDR is split into. A network
+// whose prefix is not on an octet (nibble for IPv6) boundary gets one zone
+// per enclosed boundary subnet (a /22 gets four /24 zones); beyond this many
+// it gets a single zone for the enclosing boundary subnet instead.
+const maxReverseZones = 16
+
+// ReverseZoneNames returns the reverse zones covering cidr, e.g.
+// "0.60.10.in-addr.arpa" for 10.60.0.0/24.
+func ReverseZoneNames(cidr string) ([]string, error) {
+	_, subnet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CIDR %q: %w", cidr, err)
+	}
+	ones, bits := subnet.Mask.Size()
+	step := 8
+	base := subnet.IP.To4()
+	if base == nil {
+		step, base = 4, subnet.IP.To16()
+	}
+
+	zoneLen := (ones + step - 1) / step * step
+	if 1<<(zoneLen-ones) > maxReverseZones {
+		zoneLen = ones / step * step
+		base = base.Mask(net.CIDRMask(zoneLen, bits))
+	}
+
+	var out []string
+	start := new(big.Int).SetBytes(base)
+	for i := 0; i < 1<<max(zoneLen-ones, 0); i++ {
+		n := new(big.Int).Add(start, new(big.Int).Lsh(big.NewInt(int64(i)), uint(bits-zoneLen)))
+		ip := make(net.IP, len(base))
+		n.FillBytes(ip)
+		out = append(out, reverseName(ip, zoneLen))
+	}
+	return out, nil
+}
+
+// ReverseName returns the fully qualified reverse name of ip, e.g.
+// "5.0.60.10.in-addr.arpa".
+func ReverseName(ip net.IP) string {
+	if ip.To4() != nil {
+		return reverseName(ip, 32)
+	}
+	return reverseName(ip, 128)
+}
+
+// reverseName renders the first prefixLen bits of ip, which must be on an
+// octet (IPv4) or nibble (IPv6) boundary, as a reverse name.
+func reverseName(ip net.IP, prefixLen int) string {
+	var labels []string
+	if v4 := ip.To4(); v4 != nil {
+		for i := prefixLen/8 - 1; i >= 0; i-- {
+			labels = append(labels, fmt.Sprint(v4[i]))
+		}
+		return strings.Join(append(labels, "in-addr.arpa"), ".")
+	}
+	v6 := ip.To16()
+	for i := prefixLen/4 - 1; i >= 0; i-- {
+		b := v6[i/2]
+		if i%2 == 0 {
+			b >>= 4
+		}
+		labels = append(labels, fmt.Sprintf("%x", b&0xf))
+	}
+	return strings.Join(append(labels, "ip6.arpa"), ".")
+}
+
+// reverseZoneSubnet parses a reverse zone name back into the subnet it
+// covers.
+func reverseZoneSubnet(name string) (*net.IPNet, error) {
+	if rest, ok := strings.CutSuffix(name, ".in-addr.arpa"); ok {
+		labels := strings.Split(rest, ".")
+		ip := make(net.IP, 4)
+		for i, l := range labels {
+			var b int
+			if _, err := fmt.Sscanf(l, "%d", &b); err != nil || i >= 4 || b > 255 {
+				return nil, fmt.Errorf("invalid reverse zone %q", name)
+			}
+			ip[len(labels)-1-i] = byte(b)
+		}
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(8*len(labels), 32)}, nil
+	}
+	if rest, ok := strings.CutSuffix(name, ".ip6.arpa"); ok {
+		labels := strings.Split(rest, ".")
+		ip := make(net.IP, 16)
+		for i, l := range labels {
+			var nib int
+			if _, err := fmt.Sscanf(l, "%x", &nib); err != nil || i >= 32 || nib > 15 {
+				return nil, fmt.Errorf("invalid reverse zone %q", name)
+			}
+			pos := len(labels) - 1 - i
+			if pos%2 == 0 {
+				ip[pos/2] |= byte(nib) << 4
+			} else {
+				ip[pos/2] |= byte(nib)
+			}
+		}
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(4*len(labels), 128)}, nil
+	}
+	return nil, fmt.Errorf("%q is not a reverse zone", name)
+}
+
+// EnsureReverseZones creates the reverse zones covering each CIDR on a
+// microdns instance and remembers them, so RegisterHost and friends keep a
+// PTR for every address record they write on that endpoint.
+func (c *Client) EnsureReverseZones(ctx context.Context, endpoint string, cidrs []string) ([]ReverseZone, error) {
+	var out []ReverseZone
+	for _, cidr := range cidrs {
+		if cidr == "" {
+			continue
+		}
+		names, err := ReverseZoneNames(cidr)
+		if err != nil {
+			return out, err
+		}
+		for _, name := range names {
+			id, err := c.EnsureZone(ctx, endpoint, name)
+			if err != nil {
+				return out, err
+			}
+			subnet, _ := reverseZoneSubnet(name)
+			rz := ReverseZone{Name: name, ID: id, Subnet: subnet}
+			c.addReverseZone(endpoint, rz)
+			out = append(out, rz)
+		}
+	}
+	return out, nil
+}
+
+func (c *Client) addReverseZone(endpoint string, rz ReverseZone) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.reverseZones == nil {
+		c.reverseZones = make(map[string][]ReverseZone)
+	}
+	zones := c.reverseZones[endpoint]
+	for i, z := range zones {
+		if z.Name == rz.Name {
+			zones[i] = rz
+			return
+		}
+	}
+	zones = append(zones, rz)
+	// Most specific first, so lookups find the narrowest zone
+	sort.SliceStable(zones, func(i, j int) bool {
+		a, _ := zones[i].Subnet.Mask.Size()
+		b, _ := zones[j].Subnet.Mask.Size()
+		return a > b
+	})
+	c.reverseZones[endpoint] = zones
+}
+
+// ReverseZones returns the reverse zones known for an endpoint.
+func (c *Client) ReverseZones(endpoint string) []ReverseZone {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]ReverseZone(nil), c.reverseZones[endpoint]...)
+}
+
+func (c *Client) rememberZone(endpoint string, z Zone) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.zoneNames == nil {
+		c.zoneNames = make(map[string]string)
+	}
+	c.zoneNames[endpoint+":"+z.ID] = z.Name
+}
+
+// zoneName returns the name of a zone, listing the endpoint's zones when it
+// has not been seen yet.
+func (c *Client) zoneName(ctx context.Context, endpoint, zoneID string) (string, error) {
+	c.mu.Lock()
+	name, ok := c.zoneNames[endpoint+":"+zoneID]
+	c.mu.Unlock()
+	if ok {
+		return name, nil
+	}
+	if _, err := c.ListZones(ctx, endpoint); err != nil {
+		return "", err
+	}
+	c.mu.Lock()
+	name, ok = c.zoneNames[endpoint+":"+zoneID]
+	c.mu.Unlock()
+	if !ok {
+		return "", fmt.Errorf("zone %s not found at %s", zoneID, endpoint)
+	}
+	return name, nil
+}
+
+// PTR is where the reverse record of an address record lives: Name in
+// Zone, pointing at Target.
+type PTR struct {
+	Zone   ReverseZone
+	Name   string // relative to Zone, e.g. "5" for 10.60.0.5 in 0.60.10.in-addr.arpa
+	Target string // fully qualified with trailing dot, e.g. "web.lab.lo."
+}
+
+// ReverseRecord returns the PTR that mirrors hostname -> ip in the forward
+// zone zoneID, or ok=false when no known reverse zone covers ip.
+func (c *Client) ReverseRecord(ctx context.Context, endpoint, zoneID, hostname, ip string) (ptr PTR, ok bool, err error) {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return PTR{}, false, nil
+	}
+	var zone *ReverseZone
+	for _, z := range c.ReverseZones(endpoint) {
+		if z.ID == zoneID {
+			return PTR{}, false, nil // a record in a reverse zone itself
+		}
+		if zone == nil && z.Subnet.Contains(addr) {
+			zone = &z
+		}
+	}
+	if zone == nil {
+		return PTR{}, false, nil
+	}
+	domain, err := c.zoneName(ctx, endpoint, zoneID)
+	if err != nil {
+		return PTR{}, false, err
+	}
+	target := strings.TrimSuffix(hostname, ".") + "." + domain + "."
+	if hostname == "@" || hostname == "" {
+		target = domain + "."
+	}
+	return PTR{
+		Zone:   *zone,
+		Name:   strings.TrimSuffix(ReverseName(addr), "."+zone.Name),
+		Target: target,
+	}, true, nil
+}
+
+// SameName compares two DNS names case-insensitively, ignoring a trailing dot.
+func SameName(a, b string) bool {
+	return strings.EqualFold(strings.TrimSuffix(a, "."), strings.TrimSuffix(b, "."))
+}
+
+// registerPTR adds the PTR mirroring hostname -> ip if it is missing, or
+// gives an existing one the forward record's TTL. Failures are logged, not
+// returned: callers depend on the forward record.
+func (c *Client) registerPTR(ctx context.Context, endpoint, zoneID, hostname, ip string, ttl int) {
+	ptr, ok, err := c.ReverseRecord(ctx, endpoint, zoneID, hostname, ip)
+	if err != nil {
+		c.log.Warnw("cannot resolve PTR for host", "hostname", hostname, "ip", ip, "error", err)
+		return
+	}
+	if !ok {
+		return
+	}
+	records, err := c.listRecordsCached(ctx, endpoint, ptr.Zone.ID)
+	if err != nil {
+		c.log.Warnw("listing reverse zone failed", "zone", ptr.Zone.Name, "error", err)
+		return
+	}
+	for _, r := range records {
+		if r.Type == "PTR" && r.Name == ptr.Name && SameName(r.Data.Data, ptr.Target) {
+			if ttl > 0 && r.TTL > 0 && r.TTL != ttl {
+				if _, err := c.UpdateFullRecord(ctx, endpoint, ptr.Zone.ID, r.ID, createRecordRequest{
+					Name: r.Name,
+					TTL:  ttl,
+					Data: RecordData{Type: "PTR", Data: r.Data.Data},
+				}); err != nil {
+					c.log.Warnw("failed to update PTR TTL", "ip", ip, "zone", ptr.Zone.Name, "error", err)
+				}
+			}
+			return
+		}
+	}
+	_, err = c.CreateFullRecord(ctx, endpoint, ptr.Zone.ID, createRecordRequest{
+		Name: ptr.Name,
+		TTL:  ttl,
+		Data: RecordData{Type: "PTR", Data: ptr.Target},
+	})
+	if err != nil {
+		c.log.Warnw("failed to register PTR record", "ip", ip, "target", ptr.Target, "zone", ptr.Zone.Name, "error", err)
+		return
+	}
+	c.log.Infow("PTR record registered", "ip", ip, "target", ptr.Target, "zone", ptr.Zone.Name)
+}
+
+// deregisterPTR removes the PTRs mirroring hostname -> ip.
+func (c *Client) deregisterPTR(ctx context.Context, endpoint, zoneID, hostname, ip string) {
+	ptr, ok, err := c.ReverseRecord(ctx, endpoint, zoneID, hostname, ip)
+	if err != nil || !ok {
+		return
+	}
+	records, err := c.listRecordsCached(ctx, endpoint, ptr.Zone.ID)
+	if err != nil {
+		c.log.Warnw("listing reverse zone failed", "zone", ptr.Zone.Name, "error", err)
+		return
+	}
+	deleted := false
+	for _, r := range records {
+		if r.Type != "PTR" || r.Name != ptr.Name || !SameName(r.Data.Data, ptr.Target) {
+			continue
+		}
+		if err := c.DeleteRecord(ctx, endpoint, ptr.Zone.ID, r.ID); err != nil {
+			c.log.Warnw("failed to delete PTR record", "ip", ip, "target", ptr.Target, "error", err)
+			continue
+		}
+		deleted = true
+		c.log.Infow("PTR record deregistered", "ip", ip, "target", ptr.Target, "zone", ptr.Zone.Name)
+	}
+	if deleted {
+		c.invalidateCache(endpoint, ptr.Zone.ID)
+	}
+}
+
+// SyncReverseRecords brings the endpoint's reverse zones in line with the
+// forward zone zoneID: every A/AAAA record gets its PTR, and PTRs pointing
+// into the forward zone with no matching address record are removed.
+func (c *Client) SyncReverseRecords(ctx context.Context, endpoint, zoneID string) error {
+	if len(c.ReverseZones(endpoint)) == 0 {
+		return nil
+	}
+	records, err := c.listRecordsCached(ctx, endpoint, zoneID)
+	if err != nil {
+		return err
+	}
+	domain, err := c.zoneName(ctx, endpoint, zoneID)
+	if err != nil {
+		return err
+	}
+
+	want := make(map[string]bool) // "zoneID name target"
+	for _, r := range records {
+		if !isHostRecord(r) {
+			continue
+		}
+		c.registerPTR(ctx, endpoint, zoneID, r.Name, r.Data.Data, r.TTL)
+		if ptr, ok, _ := c.ReverseRecord(ctx, endpoint, zoneID, r.Name, r.Data.Data); ok {
+			want[ptr.Zone.ID+" "+ptr.Name+" "+strings.ToLower(strings.TrimSuffix(ptr.Target, "."))] = true
+		}
+	}
+
+	suffix := "." + strings.ToLower(domain)
+	for _, z := range c.ReverseZones(endpoint) {
+		ptrs, err := c.listRecordsCached(ctx, endpoint, z.ID)
+		if err != nil {
+			return err
+		}
+		deleted := false
+		for _, r := range ptrs {
+			target := strings.ToLower(strings.TrimSuffix(r.Data.Data, "."))
+			if r.Type != "PTR" || !(strings.HasSuffix(target, suffix) || target == suffix[1:]) || want[z.ID+" "+r.Name+" "+target] {
+				continue
+			}
+			if err := c.DeleteRecord(ctx, endpoint, z.ID, r.ID); err != nil {
+				c.log.Warnw("failed to delete orphaned PTR record", "zone", z.Name, "name", r.Name, "target", r.Data.Data, "error", err)
+				continue
+			}
+			deleted = true
+			c.log.Infow("removed orphaned PTR record", "zone", z.Name, "name", r.Name, "target", r.Data.Data)
+		}
+		if deleted {
+			c.invalidateCache(endpoint, z.ID)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,194 @@
+package dns
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestReverseZoneNames(t *testing.T) {
+	tests := []struct {
+		cidr string
+		want []string
+	}{
+		{"10.60.0.0/24", []string{"0.60.10.in-addr.arpa"}},
+		{"10.60.4.0/22", []string{"4.60.10.in-addr.arpa", "5.60.10.in-addr.arpa", "6.60.10.in-addr.arpa", "7.60.10.in-addr.arpa"}},
+		{"172.16.0.0/16", []string{"16.172.in-addr.arpa"}},
+		{"172.16.32.0/19", []string{"16.172.in-addr.arpa"}}, // 32 zones is too many
+		{"10.60.0.128/25", []string{"0.60.10.in-addr.arpa"}},
+		{"fd00:1:2:3::/64", []string{"3.0.0.0.2.0.0.0.1.0.0.0.0.0.d.f.ip6.arpa"}},
+	}
+	for _, tt := range tests {
+		got, err := ReverseZoneNames(tt.cidr)
+		if err != nil {
+			t.Fatalf("ReverseZoneNames(%s): %v", tt.cidr, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ReverseZoneNames(%s) = %v, want %v", tt.cidr, got, tt.want)
+		}
+		for _, name := range got {
+			subnet, err := reverseZoneSubnet(name)
+			if err != nil {
+				t.Errorf("reverseZoneSubnet(%s): %v", name, err)
+				continue
+			}
+			if back := reverseName(subnet.IP, maskLen(subnet)); back != name {
+				t.Errorf("zone %s round-trips to %s", name, back)
+			}
+		}
+	}
+	if _, err := ReverseZoneNames("10.60.0.0"); err == nil {
+		t.Error("expected an error for a bare address")
+	}
+
+	if got := ReverseName(net.ParseIP("10.60.0.5")); got != "5.0.60.10.in-addr.arpa" {
+		t.Errorf("ReverseName(v4) = %s", got)
+	}
+	if got := ReverseName(net.ParseIP("fd00::1")); !strings.HasPrefix(got, "1.0.0.0.") || !strings.HasSuffix(got, ".0.0.d.f.ip6.arpa") {
+		t.Errorf("ReverseName(v6) = %s", got)
+	}
+}
+
+func maskLen(n *net.IPNet) int {
+	ones, _ := n.Mask.Size()
+	return ones
+}
+
+// fakeMicroDNS is an in-memory microdns zone and record API.
+type fakeMicroDNS struct {
+	mu      sync.Mutex
+	zones   []Zone
+	records map[string][]Record // zone ID -> records
+	nextID  int
+}
+
+func (f *fakeMicroDNS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/") // api v1 zones [id records [rid]]
+	switch {
+	case len(parts) == 3 && r.Method == http.MethodGet:
+		_ = json.NewEncoder(w).Encode(f.zones)
+	case len(parts) == 3 && r.Method == http.MethodPost:
+		var req createZoneRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		f.nextID++
+		z := Zone{ID: fmt.Sprintf("z%d", f.nextID), Name: req.Name}
+		f.zones = append(f.zones, z)
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(z)
+	case len(parts) == 5 && r.Method == http.MethodGet:
+		_ = json.NewEncoder(w).Encode(f.records[parts[3]])
+	case len(parts) == 5 && r.Method == http.MethodPost:
+		var req createRecordRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		f.nextID++
+		rec := Record{ID: fmt.Sprintf("r%d", f.nextID), Name: req.Name, Type: req.Data.Type, Data: req.Data, TTL: req.TTL}
+		f.records[parts[3]] = append(f.records[parts[3]], rec)
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(rec)
+	case len(parts) == 6 && r.Method == http.MethodDelete:
+		recs := f.records[parts[3]]
+		for i, rec := range recs {
+			if rec.ID == parts[5] {
+				f.records[parts[3]] = append(recs[:i], recs[i+1:]...)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+		}
+		http.NotFound(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (f *fakeMicroDNS) ptrs(zoneID string) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []string
+	for _, r := range f.records[zoneID] {
+		if r.Type == "PTR" {
+			out = append(out, r.Name+" "+r.Data.Data)
+		}
+	}
+	return out
+}
+
+func TestHostRecordsMaintainPTR(t *testing.T) {
+	f := &fakeMicroDNS{zones: []Zone{{ID: "fwd", Name: "lab.lo"}}, records: map[string][]Record{}}
+	srv := httptest.NewServer(f)
+	defer srv.Close()
+
+	ctx := context.Background()
+	c := NewClient(testLogger())
+	zones, err := c.EnsureReverseZones(ctx, srv.URL, []string{"10.60.0.0/24", "fd00:60::/64"})
+	if err != nil {
+		t.Fatalf("EnsureReverseZones: %v", err)
+	}
+	if len(zones) != 2 || zones[0].Name != "0.60.10.in-addr.arpa" {
+		t.Fatalf("reverse zones = %+v", zones)
+	}
+	v4 := zones[0].ID
+
+	// Already-present zones are reused, not recreated
+	if again, err := c.EnsureReverseZones(ctx, srv.URL, []string{"10.60.0.0/24"}); err != nil || again[0].ID != v4 {
+		t.Fatalf("second EnsureReverseZones = %+v, %v", again, err)
+	}
+
+	if err := c.RegisterHost(ctx, srv.URL, "fwd", "web", "10.60.0.5", 60); err != nil {
+		t.Fatalf("RegisterHost: %v", err)
+	}
+	if err := c.RegisterHost(ctx, srv.URL, "fwd", "web", "10.60.0.5", 60); err != nil {
+		t.Fatalf("RegisterHost again: %v", err)
+	}
+	if err := c.RegisterHost(ctx, srv.URL, "fwd", "outside", "192.168.1.9", 60); err != nil {
+		t.Fatalf("RegisterHost outside: %v", err)
+	}
+	if got, want := f.ptrs(v4), []string{"5 web.lab.lo."}; !reflect.DeepEqual(got, want) {
+		t.Errorf("PTRs after register = %v, want %v", got, want)
+	}
+
+	if err := c.DeregisterHost(ctx, srv.URL, "fwd", "web"); err != nil {
+		t.Fatalf("DeregisterHost: %v", err)
+	}
+	if got := f.ptrs(v4); len(got) != 0 {
+		t.Errorf("PTRs after deregister = %v", got)
+	}
+}
+
+func TestSyncReverseRecords(t *testing.T) {
+	f := &fakeMicroDNS{
+		zones: []Zone{{ID: "fwd", Name: "lab.lo"}, {ID: "rev", Name: "0.60.10.in-addr.arpa"}},
+		records: map[string][]Record{
+			"fwd": {{ID: "a1", Name: "db", Type: "A", Data: RecordData{Type: "A", Data: "10.60.0.7"}}},
+			"rev": {
+				{ID: "p1", Name: "9", Type: "PTR", Data: RecordData{Type: "PTR", Data: "gone.lab.lo."}},
+				{ID: "p2", Name: "10", Type: "PTR", Data: RecordData{Type: "PTR", Data: "printer.office.lo."}},
+			},
+		},
+		nextID: 100,
+	}
+	srv := httptest.NewServer(f)
+	defer srv.Close()
+
+	ctx := context.Background()
+	c := NewClient(testLogger())
+	if _, err := c.EnsureReverseZones(ctx, srv.URL, []string{"10.60.0.0/24"}); err != nil {
+		t.Fatalf("EnsureReverseZones: %v", err)
+	}
+	if err := c.SyncReverseRecords(ctx, srv.URL, "fwd"); err != nil {
+		t.Fatalf("SyncReverseRecords: %v", err)
+	}
+	// The orphan into lab.lo goes, the foreign PTR stays, db gets its PTR
+	want := []string{"10 printer.office.lo.", "7 db.lab.lo."}
+	if got := f.ptrs("rev"); !reflect.DeepEqual(got, want) {
+		t.Errorf("PTRs after sync = %v, want %v", got, want)
+	}
+}
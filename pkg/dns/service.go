@@ -0,0 +1,180 @@
+package dns
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+)
+
+// ─── Service Records (SRV, TXT) ─────────────────────────────────────────────
+
+// SRVData is the payload of an SRV record as microdns stores it.
+type SRVData struct {
+	Priority int    `json:"priority"`
+	Weight   int    `json:"weight"`
+	Port     int    `json:"port"`
+	Target   string `json:"target"` // fully qualified with trailing dot
+}
+
+// SRV is one SRV record: Name is relative to the zone, e.g. "_http._tcp.app".
+type SRV struct {
+	Name string
+	SRVData
+}
+
+// SRV decodes the data of an SRV record. ok is false for other types or
+// malformed data.
+func (d RecordData) SRV() (srv SRVData, ok bool) {
+	if d.Type != "SRV" {
+		return SRVData{}, false
+	}
+	if err := json.Unmarshal([]byte(d.Data), &srv); err != nil {
+		return SRVData{}, false
+	}
+	return srv, true
+}
+
+type createSRVRequest struct {
+	Name string         `json:"name"`
+	TTL  int            `json:"ttl"`
+	Data FullRecordData `json:"data"`
+}
+
+// SyncSRV makes the SRV records pointing at target exactly want: records
+// for target that are not wanted are deleted and missing ones created.
+// Scoping by target lets each pod replica own its share of a service's
+// SRV set without touching the other replicas' records. An empty want
+// removes every SRV record for target.
+func (c *Client) SyncSRV(ctx context.Context, endpoint, zoneID, target string, want []SRV, ttl int) error {
+	records, err := c.listRecordsCached(ctx, endpoint, zoneID)
+	if err != nil {
+		return err
+	}
+
+	kept := make([]bool, len(want))
+	changed := false
+	for _, r := range records {
+		have, ok := r.Data.SRV()
+		if !ok || !SameName(have.Target, target) {
+			continue
+		}
+		found := false
+		for i, w := range want {
+			if !kept[i] && r.Name == w.Name && have == w.SRVData {
+				kept[i], found = true, true
+				break
+			}
+		}
+		if found {
+			continue
+		}
+		if err := c.DeleteRecord(ctx, endpoint, zoneID, r.ID); err != nil {
+			return err
+		}
+		changed = true
+		c.log.Infow("SRV record deregistered", "name", r.Name, "target", have.Target, "port", have.Port, "zone", zoneID)
+	}
+
+	for i, w := range want {
+		if kept[i] {
+			continue
+		}
+		_, err := c.CreateFullRecord(ctx, endpoint, zoneID, createSRVRequest{
+			Name: w.Name,
+			TTL:  ttl,
+			Data: FullRecordData{Type: "SRV", Data: w.SRVData},
+		})
+		if err != nil {
+			return err
+		}
+		changed = true
+		c.log.Infow("SRV record registered", "name", w.Name, "target", w.Target, "port", w.Port, "zone", zoneID)
+	}
+
+	if changed {
+		c.invalidateCache(endpoint, zoneID)
+	}
+	return nil
+}
+
+// SyncTXT makes the TXT records at name that mkube manages exactly texts,
+// one record per string. An empty texts removes them all. Only records
+// SyncTXT created, or adopted because they already held a wanted string,
+// are ever deleted: other TXT records at the same name, such as ACME
+// RFC 2136 challenges, are left alone. Ownership lives in memory, so a
+// string dropped from texts across a restart is left behind too.
+func (c *Client) SyncTXT(ctx context.Context, endpoint, zoneID, name string, texts []string, ttl int) error {
+	records, err := c.listRecordsCached(ctx, endpoint, zoneID)
+	if err != nil {
+		return err
+	}
+
+	want := make(map[string]bool, len(texts))
+	for _, t := range texts {
+		want[t] = true
+	}
+	changed := false
+	for _, r := range records {
+		if r.Type != "TXT" || r.Name != name {
+			continue
+		}
+		if want[r.Data.Data] {
+			delete(want, r.Data.Data)
+			c.setOwnedTXT(endpoint, zoneID, r.ID, true)
+			continue
+		}
+		if !c.ownsTXT(endpoint, zoneID, r.ID) {
+			continue
+		}
+		if err := c.DeleteRecord(ctx, endpoint, zoneID, r.ID); err != nil {
+			return err
+		}
+		c.setOwnedTXT(endpoint, zoneID, r.ID, false)
+		changed = true
+		c.log.Infow("TXT record deregistered", "name", name, "text", r.Data.Data, "zone", zoneID)
+	}
+
+	missing := make([]string, 0, len(want))
+	for t := range want {
+		missing = append(missing, t)
+	}
+	sort.Strings(missing)
+	for _, t := range missing {
+		rec, err := c.CreateFullRecord(ctx, endpoint, zoneID, createRecordRequest{
+			Name: name,
+			TTL:  ttl,
+			Data: RecordData{Type: "TXT", Data: t},
+		})
+		if err != nil {
+			return err
+		}
+		c.setOwnedTXT(endpoint, zoneID, rec.ID, true)
+		changed = true
+		c.log.Infow("TXT record registered", "name", name, "text", t, "zone", zoneID)
+	}
+
+	if changed {
+		c.invalidateCache(endpoint, zoneID)
+	}
+	return nil
+}
+
+func (c *Client) setOwnedTXT(endpoint, zoneID, id string, owned bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := endpoint + ":" + zoneID + ":" + id
+	if !owned {
+		delete(c.ownedTXT, key)
+		return
+	}
+	if c.ownedTXT == nil {
+		c.ownedTXT = make(map[string]bool)
+	}
+	c.ownedTXT[key] = true
+}
+
+func (c *Client) ownsTXT(endpoint, zoneID, id string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ownedTXT[endpoint+":"+zoneID+":"+id]
+}
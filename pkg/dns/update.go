@@ -0,0 +1,742 @@
+package dns
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"net"
+	"strings"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// ─── Dynamic Updates (RFC 2136) ─────────────────────────────────────────────
+
+// RCode is a DNS response code.
+type RCode = dnsmessage.RCode
+
+// Response codes of dynamic updates (RFC 2136 section 2.2) and TSIG
+// errors (RFC 8945 section 5.3).
+const (
+	RCodeSuccess  = dnsmessage.RCodeSuccess
+	RCodeFormErr  = dnsmessage.RCodeFormatError
+	RCodeServFail = dnsmessage.RCodeServerFailure
+	RCodeNXDomain = dnsmessage.RCodeNameError
+	RCodeNotImp   = dnsmessage.RCodeNotImplemented
+	RCodeRefused  = dnsmessage.RCodeRefused
+	RCodeYXDomain = dnsmessage.RCode(6)  // name exists when it should not
+	RCodeYXRRSet  = dnsmessage.RCode(7)  // RRset exists when it should not
+	RCodeNXRRSet  = dnsmessage.RCode(8)  // RRset that should exist does not
+	RCodeNotAuth  = dnsmessage.RCode(9)  // not authoritative for the zone, or TSIG failed
+	RCodeNotZone  = dnsmessage.RCode(10) // name not within the zone
+
+	TSIGBadSig  = 16
+	TSIGBadKey  = 17
+	TSIGBadTime = 18
+)
+
+const (
+	opcodeUpdate = 5
+	typeTSIG     = dnsmessage.Type(250)
+	typeANY      = dnsmessage.Type(255)
+	classNONE    = dnsmessage.Class(254)
+	classANY     = dnsmessage.ClassANY
+
+	// tsigFudge is the clock skew allowed for signed messages and the
+	// fudge mkube puts in its own signatures.
+	tsigFudge = 300
+)
+
+var rcodeNames = map[RCode]string{
+	RCodeSuccess: "NOERROR", RCodeFormErr: "FORMERR", RCodeServFail: "SERVFAIL", RCodeNXDomain: "NXDOMAIN",
+	RCodeNotImp: "NOTIMP", RCodeRefused: "REFUSED", RCodeYXDomain: "YXDOMAIN", RCodeYXRRSet: "YXRRSET",
+	RCodeNXRRSet: "NXRRSET", RCodeNotAuth: "NOTAUTH", RCodeNotZone: "NOTZONE",
+}
+
+// RCodeName returns the mnemonic of a response code, e.g. "NXRRSET".
+func RCodeName(rc RCode) string {
+	if name, ok := rcodeNames[rc]; ok {
+		return name
+	}
+	return fmt.Sprintf("RCODE%d", rc)
+}
+
+// UpdateRR is one record of the prerequisite or update section of an
+// update. Class and Data carry the RFC 2136 meaning: CLASS ANY with no
+// data names a whole RRset (or every RRset for type ANY), CLASS NONE a
+// single record to delete. Data is what microdns stores for the type
+// (a string, MXData or SRVData), nil when the record has no data or a
+// type mkube does not handle.
+type UpdateRR struct {
+	Name  string // absolute, lower case, without the trailing dot
+	Type  string // "A", "TXT", ... "ANY", or "TYPE<n>"
+	Class string // "IN", "ANY" or "NONE"
+	TTL   int
+	Data  interface{}
+}
+
+// UpdateMessage is a parsed RFC 2136 UPDATE.
+type UpdateMessage struct {
+	ID      uint16
+	Zone    string // lower case, without the trailing dot
+	Prereqs []UpdateRR
+	Updates []UpdateRR
+	TSIG    *TSIG // nil when unsigned
+
+	raw        []byte
+	tsigOffset int
+}
+
+// TSIG is the transaction signature of a message (RFC 8945).
+type TSIG struct {
+	KeyName    string // lower case, without the trailing dot
+	Algorithm  string // e.g. "hmac-sha256"
+	TimeSigned uint64
+	Fudge      uint16
+	MAC        []byte
+	OriginalID uint16
+	Error      uint16
+	Other      []byte
+}
+
+// TSIGKey is a shared secret clients sign updates with.
+type TSIGKey struct {
+	Name      string // e.g. "acme"; case and trailing dot do not matter
+	Algorithm string // hmac-sha1, hmac-sha224, hmac-sha256 (default), hmac-sha384 or hmac-sha512
+	Secret    []byte
+}
+
+var tsigAlgorithms = map[string]func() hash.Hash{
+	"hmac-sha1":   sha1.New,
+	"hmac-sha224": sha256.New224,
+	"hmac-sha256": sha256.New,
+	"hmac-sha384": sha512.New384,
+	"hmac-sha512": sha512.New,
+}
+
+// TSIGAlgorithm normalizes an algorithm name and reports whether it is
+// supported; "" means hmac-sha256.
+func TSIGAlgorithm(name string) (string, bool) {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	if name == "" {
+		name = "hmac-sha256"
+	}
+	_, ok := tsigAlgorithms[name]
+	return name, ok
+}
+
+// ParseUpdate parses an UPDATE message. A message that is not an update,
+// or whose sections are malformed, is an error.
+func ParseUpdate(msg []byte) (*UpdateMessage, error) {
+	var p dnsmessage.Parser
+	hdr, err := p.Start(msg)
+	if err != nil {
+		return nil, err
+	}
+	if hdr.OpCode != opcodeUpdate || hdr.Response {
+		return nil, fmt.Errorf("not an update (opcode %d)", hdr.OpCode)
+	}
+	qs, err := p.AllQuestions()
+	if err != nil {
+		return nil, err
+	}
+	if len(qs) != 1 || qs[0].Type != dnsmessage.TypeSOA {
+		return nil, errors.New("the zone section must hold exactly one SOA entry")
+	}
+	m := &UpdateMessage{ID: hdr.ID, Zone: lowerName(qs[0].Name), raw: msg}
+
+	for {
+		h, err := p.AnswerHeader()
+		if errors.Is(err, dnsmessage.ErrSectionDone) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		rr, err := parseUpdateRR(&p, h)
+		if err != nil {
+			return nil, fmt.Errorf("prerequisite %s: %w", h.Name, err)
+		}
+		m.Prereqs = append(m.Prereqs, rr)
+	}
+	for {
+		h, err := p.AuthorityHeader()
+		if errors.Is(err, dnsmessage.ErrSectionDone) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		rr, err := parseUpdateRR(&p, h)
+		if err != nil {
+			return nil, fmt.Errorf("update %s: %w", h.Name, err)
+		}
+		m.Updates = append(m.Updates, rr)
+	}
+
+	var additional []dnsmessage.ResourceHeader
+	var tsigData []byte
+	for {
+		h, err := p.AdditionalHeader()
+		if errors.Is(err, dnsmessage.ErrSectionDone) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		u, err := p.UnknownResource()
+		if err != nil {
+			return nil, err
+		}
+		additional = append(additional, h)
+		if h.Type == typeTSIG {
+			tsigData = u.Data
+		}
+	}
+	for i, h := range additional {
+		if h.Type != typeTSIG {
+			continue
+		}
+		if i != len(additional)-1 {
+			return nil, errors.New("TSIG must be the last record")
+		}
+		if m.TSIG, err = parseTSIG(lowerName(h.Name), tsigData); err != nil {
+			return nil, err
+		}
+		if m.tsigOffset, err = lastRecordOffset(msg); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// parseUpdateRR reads the data of a record whose header was just parsed.
+func parseUpdateRR(p *dnsmessage.Parser, h dnsmessage.ResourceHeader) (UpdateRR, error) {
+	rr := UpdateRR{Name: lowerName(h.Name), Type: typeName(h.Type), TTL: int(h.TTL)}
+	switch h.Class {
+	case dnsmessage.ClassINET:
+		rr.Class = "IN"
+	case classANY:
+		rr.Class = "ANY"
+	case classNONE:
+		rr.Class = "NONE"
+	default:
+		return rr, fmt.Errorf("class %d is not IN, ANY or NONE", h.Class)
+	}
+	if h.Length == 0 {
+		_, err := p.UnknownResource()
+		return rr, err
+	}
+
+	var err error
+	switch h.Type {
+	case dnsmessage.TypeA:
+		var r dnsmessage.AResource
+		if r, err = p.AResource(); err == nil {
+			rr.Data = net.IP(r.A[:]).String()
+		}
+	case dnsmessage.TypeAAAA:
+		var r dnsmessage.AAAAResource
+		if r, err = p.AAAAResource(); err == nil {
+			rr.Data = net.IP(r.AAAA[:]).String()
+		}
+	case dnsmessage.TypeCNAME:
+		var r dnsmessage.CNAMEResource
+		if r, err = p.CNAMEResource(); err == nil {
+			rr.Data = r.CNAME.String()
+		}
+	case dnsmessage.TypePTR:
+		var r dnsmessage.PTRResource
+		if r, err = p.PTRResource(); err == nil {
+			rr.Data = r.PTR.String()
+		}
+	case dnsmessage.TypeMX:
+		var r dnsmessage.MXResource
+		if r, err = p.MXResource(); err == nil {
+			rr.Data = MXData{Preference: int(r.Pref), Exchange: r.MX.String()}
+		}
+	case dnsmessage.TypeSRV:
+		var r dnsmessage.SRVResource
+		if r, err = p.SRVResource(); err == nil {
+			rr.Data = SRVData{Priority: int(r.Priority), Weight: int(r.Weight), Port: int(r.Port), Target: r.Target.String()}
+		}
+	case dnsmessage.TypeTXT:
+		var r dnsmessage.TXTResource
+		if r, err = p.TXTResource(); err == nil {
+			rr.Data = strings.Join(r.TXT, "")
+		}
+	default:
+		_, err = p.UnknownResource()
+	}
+	return rr, err
+}
+
+func typeName(t dnsmessage.Type) string {
+	for _, name := range []string{"A", "AAAA", "CNAME", "TXT", "SRV", "MX", "PTR"} {
+		if recordType(name) == t {
+			return name
+		}
+	}
+	switch t {
+	case typeANY:
+		return "ANY"
+	case dnsmessage.TypeSOA:
+		return "SOA"
+	case dnsmessage.TypeNS:
+		return "NS"
+	}
+	return fmt.Sprintf("TYPE%d", t)
+}
+
+func lowerName(n dnsmessage.Name) string {
+	return strings.ToLower(strings.TrimSuffix(n.String(), "."))
+}
+
+// ─── TSIG ───────────────────────────────────────────────────────────────────
+
+// parseTSIG decodes TSIG RDATA; its algorithm name is never compressed.
+func parseTSIG(keyName string, data []byte) (*TSIG, error) {
+	alg, off, err := readWireName(data, 0)
+	if err != nil || len(data) < off+10 {
+		return nil, errors.New("malformed TSIG record")
+	}
+	t := &TSIG{KeyName: keyName, Algorithm: alg}
+	t.TimeSigned = uint64(binary.BigEndian.Uint16(data[off:]))<<32 | uint64(binary.BigEndian.Uint32(data[off+2:]))
+	t.Fudge = binary.BigEndian.Uint16(data[off+6:])
+	macLen := int(binary.BigEndian.Uint16(data[off+8:]))
+	off += 10
+	if len(data) < off+macLen+6 {
+		return nil, errors.New("malformed TSIG record")
+	}
+	t.MAC = data[off : off+macLen]
+	off += macLen
+	t.OriginalID = binary.BigEndian.Uint16(data[off:])
+	t.Error = binary.BigEndian.Uint16(data[off+2:])
+	otherLen := int(binary.BigEndian.Uint16(data[off+4:]))
+	off += 6
+	if len(data) != off+otherLen {
+		return nil, errors.New("malformed TSIG record")
+	}
+	t.Other = data[off:]
+	return t, nil
+}
+
+// readWireName reads an uncompressed name, returning it lower case
+// without the trailing dot.
+func readWireName(data []byte, off int) (string, int, error) {
+	var labels []string
+	for {
+		if off >= len(data) {
+			return "", 0, errors.New("name overruns the record")
+		}
+		n := int(data[off])
+		off++
+		if n == 0 {
+			return strings.ToLower(strings.Join(labels, ".")), off, nil
+		}
+		if n > 63 || off+n > len(data) {
+			return "", 0, errors.New("bad label")
+		}
+		labels = append(labels, string(data[off:off+n]))
+		off += n
+	}
+}
+
+// wireName encodes a name in canonical (lower case, uncompressed) form.
+func wireName(name string) []byte {
+	var b []byte
+	for _, label := range strings.Split(strings.ToLower(strings.TrimSuffix(name, ".")), ".") {
+		if label == "" {
+			continue
+		}
+		b = append(b, byte(len(label)))
+		b = append(b, label...)
+	}
+	return append(b, 0)
+}
+
+// lastRecordOffset returns where the last record of a message starts.
+func lastRecordOffset(msg []byte) (int, error) {
+	if len(msg) < 12 {
+		return 0, errors.New("short message")
+	}
+	counts := [4]int{}
+	for i := range counts {
+		counts[i] = int(binary.BigEndian.Uint16(msg[4+2*i:]))
+	}
+	off := 12
+	var err error
+	for i := 0; i < counts[0]; i++ {
+		if off, err = skipWireName(msg, off); err != nil {
+			return 0, err
+		}
+		off += 4
+	}
+	last := -1
+	for i := 0; i < counts[1]+counts[2]+counts[3]; i++ {
+		last = off
+		if off, err = skipWireName(msg, off); err != nil {
+			return 0, err
+		}
+		if off+10 > len(msg) {
+			return 0, errors.New("record overruns the message")
+		}
+		off += 10 + int(binary.BigEndian.Uint16(msg[off+8:]))
+	}
+	if last < 0 || off > len(msg) {
+		return 0, errors.New("no records")
+	}
+	return last, nil
+}
+
+func skipWireName(msg []byte, off int) (int, error) {
+	for off < len(msg) {
+		n := int(msg[off])
+		switch {
+		case n == 0:
+			return off + 1, nil
+		case n&0xC0 == 0xC0:
+			return off + 2, nil
+		}
+		off += 1 + n
+	}
+	return 0, errors.New("name overruns the message")
+}
+
+// tsigVariables are the TSIG fields covered by the MAC besides the message.
+func tsigVariables(t *TSIG) []byte {
+	b := wireName(t.KeyName)
+	b = binary.BigEndian.AppendUint16(b, uint16(classANY))
+	b = binary.BigEndian.AppendUint32(b, 0)
+	b = append(b, wireName(t.Algorithm)...)
+	b = binary.BigEndian.AppendUint16(b, uint16(t.TimeSigned>>32))
+	b = binary.BigEndian.AppendUint32(b, uint32(t.TimeSigned))
+	b = binary.BigEndian.AppendUint16(b, t.Fudge)
+	b = binary.BigEndian.AppendUint16(b, t.Error)
+	b = binary.BigEndian.AppendUint16(b, uint16(len(t.Other)))
+	return append(b, t.Other...)
+}
+
+func tsigMAC(key TSIGKey, parts ...[]byte) []byte {
+	alg, _ := TSIGAlgorithm(key.Algorithm)
+	mac := hmac.New(tsigAlgorithms[alg], key.Secret)
+	for _, p := range parts {
+		mac.Write(p)
+	}
+	return mac.Sum(nil)
+}
+
+// VerifyTSIG checks the message signature against key and returns the
+// TSIG error: 0, TSIGBadKey, TSIGBadSig or TSIGBadTime.
+func (m *UpdateMessage) VerifyTSIG(key TSIGKey, now time.Time) uint16 {
+	t := m.TSIG
+	alg, _ := TSIGAlgorithm(key.Algorithm)
+	if t == nil || !strings.EqualFold(t.KeyName, strings.TrimSuffix(key.Name, ".")) || t.Algorithm != alg {
+		return TSIGBadKey
+	}
+	signed := append([]byte(nil), m.raw[:m.tsigOffset]...)
+	binary.BigEndian.PutUint16(signed, t.OriginalID)
+	binary.BigEndian.PutUint16(signed[10:], binary.BigEndian.Uint16(signed[10:])-1)
+	if !hmac.Equal(tsigMAC(key, signed, tsigVariables(t)), t.MAC) {
+		return TSIGBadSig
+	}
+	if skew := now.Unix() - int64(t.TimeSigned); skew > int64(t.Fudge) || -skew > int64(t.Fudge) {
+		return TSIGBadTime
+	}
+	return 0
+}
+
+// UpdateResponse builds the response to an update: the header and zone
+// section, signed with key when the request was. A BADKEY or BADSIG
+// response carries an empty MAC, as the client's key cannot be trusted.
+func UpdateResponse(m *UpdateMessage, rcode RCode, key *TSIGKey, tsigErr uint16, now time.Time) ([]byte, error) {
+	zone, err := dnsmessage.NewName(m.Zone + ".")
+	if err != nil {
+		zone = dnsmessage.MustNewName(".")
+	}
+	b := dnsmessage.NewBuilder(nil, dnsmessage.Header{ID: m.ID, Response: true, OpCode: opcodeUpdate, RCode: rcode})
+	if err := b.StartQuestions(); err != nil {
+		return nil, err
+	}
+	if err := b.Question(dnsmessage.Question{Name: zone, Type: dnsmessage.TypeSOA, Class: dnsmessage.ClassINET}); err != nil {
+		return nil, err
+	}
+	resp, err := b.Finish()
+	if err != nil || m.TSIG == nil {
+		return resp, err
+	}
+
+	t := &TSIG{
+		KeyName:    m.TSIG.KeyName,
+		Algorithm:  m.TSIG.Algorithm,
+		TimeSigned: uint64(now.Unix()),
+		Fudge:      tsigFudge,
+		OriginalID: m.ID,
+		Error:      tsigErr,
+	}
+	if tsigErr == TSIGBadTime {
+		t.TimeSigned = m.TSIG.TimeSigned
+		t.Other = binary.BigEndian.AppendUint16(nil, uint16(uint64(now.Unix())>>32))
+		t.Other = binary.BigEndian.AppendUint32(t.Other, uint32(now.Unix()))
+	}
+	if key != nil && tsigErr != TSIGBadKey && tsigErr != TSIGBadSig {
+		prior := binary.BigEndian.AppendUint16(nil, uint16(len(m.TSIG.MAC)))
+		t.MAC = tsigMAC(*key, prior, m.TSIG.MAC, resp, tsigVariables(t))
+	}
+	return appendTSIG(resp, t), nil
+}
+
+// appendTSIG adds a TSIG record to a packed message.
+func appendTSIG(msg []byte, t *TSIG) []byte {
+	rdata := wireName(t.Algorithm)
+	rdata = binary.BigEndian.AppendUint16(rdata, uint16(t.TimeSigned>>32))
+	rdata = binary.BigEndian.AppendUint32(rdata, uint32(t.TimeSigned))
+	rdata = binary.BigEndian.AppendUint16(rdata, t.Fudge)
+	rdata = binary.BigEndian.AppendUint16(rdata, uint16(len(t.MAC)))
+	rdata = append(rdata, t.MAC...)
+	rdata = binary.BigEndian.AppendUint16(rdata, t.OriginalID)
+	rdata = binary.BigEndian.AppendUint16(rdata, t.Error)
+	rdata = binary.BigEndian.AppendUint16(rdata, uint16(len(t.Other)))
+	rdata = append(rdata, t.Other...)
+
+	out := append([]byte(nil), msg...)
+	binary.BigEndian.PutUint16(out[10:], binary.BigEndian.Uint16(out[10:])+1)
+	out = append(out, wireName(t.KeyName)...)
+	out = binary.BigEndian.AppendUint16(out, uint16(typeTSIG))
+	out = binary.BigEndian.AppendUint16(out, uint16(classANY))
+	out = binary.BigEndian.AppendUint32(out, 0)
+	out = binary.BigEndian.AppendUint16(out, uint16(len(rdata)))
+	return append(out, rdata...)
+}
+
+// SignTSIG signs a packed message with key, as a client does.
+func SignTSIG(msg []byte, key TSIGKey, now time.Time) []byte {
+	alg, _ := TSIGAlgorithm(key.Algorithm)
+	t := &TSIG{
+		KeyName:    strings.ToLower(strings.TrimSuffix(key.Name, ".")),
+		Algorithm:  alg,
+		TimeSigned: uint64(now.Unix()),
+		Fudge:      tsigFudge,
+		OriginalID: binary.BigEndian.Uint16(msg),
+	}
+	t.MAC = tsigMAC(key, msg, tsigVariables(t))
+	return appendTSIG(msg, t)
+}
+
+// ─── Update Planning ────────────────────────────────────────────────────────
+
+// updateRecord is a record of the zone while an update is applied.
+type updateRecord struct {
+	rec     FullRecord
+	name    string // absolute, lower case
+	data    string // canonicalData
+	exists  bool   // in the zone before the update
+	deleted bool
+	ttl     int
+}
+
+// PlanUpdate checks an update's prerequisites against the zone's records
+// and works out the record changes its update section makes, following
+// RFC 2136 sections 3.2 to 3.4. A failed prerequisite or a malformed
+// update returns its response code and no changes. Records of types zone
+// files do not carry are left alone, as are SOA and NS at the apex.
+func PlanUpdate(zone string, have []FullRecord, m *UpdateMessage) (ZoneDiff, RCode) {
+	origin := strings.ToLower(strings.TrimSuffix(zone, "."))
+	zone = origin + "."
+	inZone := func(name string) bool {
+		return name == origin || strings.HasSuffix(name, "."+origin)
+	}
+
+	var recs []*updateRecord
+	for _, r := range have {
+		if !r.Enabled || !ZoneFileType(r.Type) {
+			continue
+		}
+		r.Type = strings.ToUpper(r.Type)
+		recs = append(recs, &updateRecord{
+			rec: r, name: canonicalName(ownerName(r.Name), zone), data: canonicalData(r.Data, zone),
+			exists: true, ttl: r.TTL,
+		})
+	}
+	live := func(name, rtype string) []*updateRecord {
+		name += "."
+		var out []*updateRecord
+		for _, r := range recs {
+			if !r.deleted && r.name == name && (rtype == "ANY" || r.rec.Type == rtype) {
+				out = append(out, r)
+			}
+		}
+		return out
+	}
+
+	// Prerequisites (3.2)
+	valueSets := map[[2]string][]string{}
+	for _, pr := range m.Prereqs {
+		if !inZone(pr.Name) {
+			return ZoneDiff{}, RCodeNotZone
+		}
+		if pr.TTL != 0 {
+			return ZoneDiff{}, RCodeFormErr
+		}
+		switch {
+		case pr.Class == "ANY" && pr.Data == nil:
+			if len(live(pr.Name, pr.Type)) == 0 {
+				if pr.Type == "ANY" {
+					return ZoneDiff{}, RCodeNXDomain
+				}
+				return ZoneDiff{}, RCodeNXRRSet
+			}
+		case pr.Class == "NONE" && pr.Data == nil:
+			if len(live(pr.Name, pr.Type)) > 0 {
+				if pr.Type == "ANY" {
+					return ZoneDiff{}, RCodeYXDomain
+				}
+				return ZoneDiff{}, RCodeYXRRSet
+			}
+		case pr.Class == "IN" && pr.Type != "ANY":
+			key := [2]string{pr.Name, pr.Type}
+			valueSets[key] = append(valueSets[key], updateData(pr, zone))
+		default:
+			return ZoneDiff{}, RCodeFormErr
+		}
+	}
+	for key, want := range valueSets {
+		got := map[string]bool{}
+		for _, r := range live(key[0], key[1]) {
+			got[r.data] = true
+		}
+		wantSet := map[string]bool{}
+		for _, d := range want {
+			wantSet[d] = true
+		}
+		if len(got) != len(wantSet) {
+			return ZoneDiff{}, RCodeNXRRSet
+		}
+		for d := range wantSet {
+			if !got[d] {
+				return ZoneDiff{}, RCodeNXRRSet
+			}
+		}
+	}
+
+	// Prescan (3.4.1)
+	for _, u := range m.Updates {
+		if !inZone(u.Name) {
+			return ZoneDiff{}, RCodeNotZone
+		}
+		switch u.Class {
+		case "IN":
+			if u.Type == "ANY" {
+				return ZoneDiff{}, RCodeFormErr
+			}
+			if !ZoneFileType(u.Type) || u.Data == nil {
+				return ZoneDiff{}, RCodeNotImp
+			}
+		case "ANY":
+			if u.TTL != 0 || u.Data != nil {
+				return ZoneDiff{}, RCodeFormErr
+			}
+		case "NONE":
+			if u.TTL != 0 || u.Type == "ANY" {
+				return ZoneDiff{}, RCodeFormErr
+			}
+		}
+	}
+
+	// Updates (3.4.2)
+	protected := func(r *updateRecord) bool {
+		return r.name == zone && (r.rec.Type == "SOA" || r.rec.Type == "NS")
+	}
+	for _, u := range m.Updates {
+		switch u.Class {
+		case "IN":
+			data := updateData(u, zone)
+			cnames := live(u.Name, "CNAME")
+			others := len(live(u.Name, "ANY")) - len(cnames)
+			if (u.Type == "CNAME" && others > 0) || (u.Type != "CNAME" && len(cnames) > 0) {
+				continue // CNAME and other data never share a name
+			}
+			if u.Type == "CNAME" {
+				for _, r := range cnames {
+					if r.data != data {
+						r.deleted = true
+					}
+				}
+			}
+			dup := false
+			for _, r := range live(u.Name, u.Type) {
+				if r.data == data {
+					r.ttl, dup = u.TTL, true
+				}
+			}
+			if !dup {
+				rel, _ := relName(u.Name+".", zone)
+				recs = append(recs, &updateRecord{
+					rec:  FullRecord{Name: rel, TTL: u.TTL, Type: u.Type, Data: FullRecordData{Type: u.Type, Data: updateStoredData(u)}, Enabled: true},
+					name: u.Name + ".", data: data, ttl: u.TTL,
+				})
+			}
+		case "ANY":
+			for _, r := range live(u.Name, u.Type) {
+				if !protected(r) {
+					r.deleted = true
+				}
+			}
+		case "NONE":
+			data := updateData(u, zone)
+			for _, r := range live(u.Name, u.Type) {
+				if r.data == data && !protected(r) {
+					r.deleted = true
+				}
+			}
+		}
+	}
+
+	var diff ZoneDiff
+	for _, r := range recs {
+		switch {
+		case r.exists && r.deleted:
+			diff.Delete = append(diff.Delete, r.rec)
+		case r.exists && r.ttl != r.rec.TTL:
+			next := r.rec
+			next.TTL = r.ttl
+			diff.Update = append(diff.Update, ZoneUpdate{Old: r.rec, New: next})
+		case r.exists:
+			diff.Unchanged++
+		case !r.deleted:
+			r.rec.TTL = r.ttl
+			diff.Create = append(diff.Create, r.rec)
+		}
+	}
+	return diff, RCodeSuccess
+}
+
+// updateStoredData is the record data as microdns stores it: names in
+// data fully qualified with the trailing dot.
+func updateStoredData(u UpdateRR) interface{} {
+	switch d := u.Data.(type) {
+	case string:
+		if u.Type == "CNAME" || u.Type == "PTR" {
+			return strings.TrimSuffix(d, ".") + "."
+		}
+		return d
+	case MXData:
+		d.Exchange = strings.TrimSuffix(d.Exchange, ".") + "."
+		return d
+	case SRVData:
+		d.Target = strings.TrimSuffix(d.Target, ".") + "."
+		return d
+	}
+	return u.Data
+}
+
+// updateData is the canonical form of an update record's data.
+func updateData(u UpdateRR, zone string) string {
+	return canonicalData(FullRecordData{Type: u.Type, Data: updateStoredData(u)}, zone)
+}
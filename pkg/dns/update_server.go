@@ -0,0 +1,228 @@
+package dns
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// ─── Dynamic Update Server ──────────────────────────────────────────────────
+
+// updateTimeout bounds the handling of one update, REST calls included,
+// and how long a TCP connection may sit idle.
+const updateTimeout = 30 * time.Second
+
+// UpdateRequest is an authenticated update handed to an UpdateHandler.
+type UpdateRequest struct {
+	Msg    *UpdateMessage
+	Key    string // TSIG key name the update was signed with; "" when unsigned
+	Client string // source address
+}
+
+// UpdateHandler applies updates the server has authenticated and hears
+// about those it rejected.
+type UpdateHandler interface {
+	// ApplyUpdate applies an update and returns the response code.
+	ApplyUpdate(ctx context.Context, req *UpdateRequest) RCode
+	// RejectUpdate reports an update refused before reaching ApplyUpdate.
+	RejectUpdate(req *UpdateRequest, reason string)
+}
+
+// UpdateServer accepts RFC 2136 updates over UDP and TCP. Every update
+// must carry a valid TSIG signature by one of its keys; the handler
+// decides what each key may change.
+type UpdateServer struct {
+	addr    string
+	keys    map[string]TSIGKey // lower case name without trailing dot -> key
+	handler UpdateHandler
+	log     *zap.SugaredLogger
+	now     func() time.Time
+
+	mu   sync.Mutex
+	conn net.PacketConn
+	ln   net.Listener
+}
+
+// NewUpdateServer creates a stopped server that will listen on addr.
+func NewUpdateServer(addr string, keys []TSIGKey, handler UpdateHandler, log *zap.SugaredLogger) *UpdateServer {
+	s := &UpdateServer{
+		addr:    addr,
+		keys:    make(map[string]TSIGKey, len(keys)),
+		handler: handler,
+		log:     log.Named("dns-update"),
+		now:     time.Now,
+	}
+	for _, k := range keys {
+		s.keys[strings.ToLower(strings.TrimSuffix(k.Name, "."))] = k
+	}
+	return s
+}
+
+// Start binds the UDP and TCP listeners and serves until Stop.
+func (s *UpdateServer) Start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn != nil {
+		return nil
+	}
+	conn, err := net.ListenPacket("udp", s.addr)
+	if err != nil {
+		return fmt.Errorf("DNS update listen on udp %s: %w", s.addr, err)
+	}
+	// TCP on the port UDP got, so ":0" works in tests
+	ln, err := net.Listen("tcp", conn.LocalAddr().String())
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("DNS update listen on tcp %s: %w", s.addr, err)
+	}
+	s.conn, s.ln = conn, ln
+	go s.serveUDP(conn)
+	go s.serveTCP(ln)
+	s.log.Infow("DNS update server started", "addr", conn.LocalAddr().String(), "keys", len(s.keys))
+	return nil
+}
+
+// Stop closes the listeners.
+func (s *UpdateServer) Stop() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	err := errors.Join(s.conn.Close(), s.ln.Close())
+	s.conn, s.ln = nil, nil
+	return err
+}
+
+// LocalAddr returns the address the server listens on; "" when stopped.
+func (s *UpdateServer) LocalAddr() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return ""
+	}
+	return s.conn.LocalAddr().String()
+}
+
+func (s *UpdateServer) serveUDP(conn net.PacketConn) {
+	buf := make([]byte, 65535)
+	for {
+		n, from, err := conn.ReadFrom(buf)
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				s.log.Warnw("DNS update read failed", "error", err)
+			}
+			return
+		}
+		msg := append([]byte(nil), buf[:n]...)
+		go func() {
+			if resp := s.Handle(msg, from.String()); resp != nil {
+				_, _ = conn.WriteTo(resp, from)
+			}
+		}()
+	}
+}
+
+func (s *UpdateServer) serveTCP(ln net.Listener) {
+	for {
+		c, err := ln.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				s.log.Warnw("DNS update accept failed", "error", err)
+			}
+			return
+		}
+		go s.serveConn(c)
+	}
+}
+
+// serveConn answers length-prefixed messages on one TCP connection.
+func (s *UpdateServer) serveConn(c net.Conn) {
+	defer c.Close()
+	for {
+		_ = c.SetDeadline(time.Now().Add(updateTimeout))
+		var size [2]byte
+		if _, err := io.ReadFull(c, size[:]); err != nil {
+			return
+		}
+		msg := make([]byte, binary.BigEndian.Uint16(size[:]))
+		if _, err := io.ReadFull(c, msg); err != nil {
+			return
+		}
+		resp := s.Handle(msg, c.RemoteAddr().String())
+		if resp == nil {
+			return
+		}
+		if _, err := c.Write(append(binary.BigEndian.AppendUint16(nil, uint16(len(resp))), resp...)); err != nil {
+			return
+		}
+	}
+}
+
+// Handle authenticates one update, passes it to the handler and returns
+// the packed response; nil drops a message that is not an update.
+func (s *UpdateServer) Handle(msg []byte, client string) []byte {
+	m, err := ParseUpdate(msg)
+	if err != nil {
+		if len(msg) < 12 {
+			return nil
+		}
+		s.log.Debugw("malformed DNS update", "client", client, "error", err)
+		var p dnsmessage.Parser
+		hdr, perr := p.Start(msg)
+		if perr != nil || hdr.Response {
+			return nil
+		}
+		rcode := RCodeFormErr
+		if hdr.OpCode != opcodeUpdate {
+			rcode = RCodeNotImp
+		}
+		return s.respond(&UpdateMessage{ID: hdr.ID}, rcode, nil, 0)
+	}
+
+	req := &UpdateRequest{Msg: m, Client: client}
+	if m.TSIG == nil {
+		s.handler.RejectUpdate(req, "update is not signed")
+		return s.respond(m, RCodeRefused, nil, 0)
+	}
+	req.Key = m.TSIG.KeyName
+	key, ok := s.keys[m.TSIG.KeyName]
+	if !ok {
+		s.handler.RejectUpdate(req, fmt.Sprintf("unknown TSIG key %q", m.TSIG.KeyName))
+		return s.respond(m, RCodeNotAuth, nil, TSIGBadKey)
+	}
+	switch tsigErr := m.VerifyTSIG(key, s.now()); tsigErr {
+	case 0:
+	case TSIGBadTime:
+		s.handler.RejectUpdate(req, "TSIG time outside the allowed clock skew")
+		return s.respond(m, RCodeNotAuth, &key, tsigErr)
+	case TSIGBadKey:
+		s.handler.RejectUpdate(req, fmt.Sprintf("TSIG algorithm %s does not match key %q", m.TSIG.Algorithm, m.TSIG.KeyName))
+		return s.respond(m, RCodeNotAuth, nil, tsigErr)
+	default:
+		s.handler.RejectUpdate(req, "bad TSIG signature")
+		return s.respond(m, RCodeNotAuth, nil, tsigErr)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), updateTimeout)
+	defer cancel()
+	return s.respond(m, s.handler.ApplyUpdate(ctx, req), &key, 0)
+}
+
+func (s *UpdateServer) respond(m *UpdateMessage, rcode RCode, key *TSIGKey, tsigErr uint16) []byte {
+	resp, err := UpdateResponse(m, rcode, key, tsigErr, s.now())
+	if err != nil {
+		s.log.Warnw("DNS update response not packable", "zone", m.Zone, "error", err)
+		return nil
+	}
+	return resp
+}
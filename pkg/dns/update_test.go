@@ -0,0 +1,255 @@
+package dns
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+var testUpdateKey = TSIGKey{Name: "acme.", Algorithm: "hmac-sha256", Secret: []byte("0123456789abcdef0123456789abcdef")}
+
+func rr(name string, class dnsmessage.Class, ttl uint32, body dnsmessage.ResourceBody) dnsmessage.Resource {
+	return dnsmessage.Resource{
+		Header: dnsmessage.ResourceHeader{Name: dnsmessage.MustNewName(name), Class: class, TTL: ttl},
+		Body:   body,
+	}
+}
+
+func emptyRR(name string, class dnsmessage.Class, t dnsmessage.Type) dnsmessage.Resource {
+	return rr(name, class, 0, &dnsmessage.UnknownResource{Type: t})
+}
+
+// packUpdate builds an UPDATE for zone gt.lo.
+func packUpdate(t *testing.T, prereqs, updates []dnsmessage.Resource) []byte {
+	t.Helper()
+	m := dnsmessage.Message{
+		Header:      dnsmessage.Header{ID: 0x4242, OpCode: opcodeUpdate},
+		Questions:   []dnsmessage.Question{{Name: dnsmessage.MustNewName("gt.lo."), Type: dnsmessage.TypeSOA, Class: dnsmessage.ClassINET}},
+		Answers:     prereqs,
+		Authorities: updates,
+	}
+	b, err := m.Pack()
+	if err != nil {
+		t.Fatalf("Pack: %v", err)
+	}
+	return b
+}
+
+func TestPlanUpdate(t *testing.T) {
+	have := []FullRecord{
+		{ID: "r1", Name: "web", TTL: 300, Type: "A", Data: FullRecordData{Type: "A", Data: "192.168.200.5"}, Enabled: true},
+		{ID: "r2", Name: "web", TTL: 300, Type: "A", Data: FullRecordData{Type: "A", Data: "192.168.200.6"}, Enabled: true},
+		{ID: "r3", Name: "www", TTL: 300, Type: "CNAME", Data: FullRecordData{Type: "CNAME", Data: "web"}, Enabled: true},
+		{ID: "r4", Name: "_acme-challenge", TTL: 60, Type: "TXT", Data: FullRecordData{Type: "TXT", Data: "old"}, Enabled: true},
+	}
+	a := func(ip string) *dnsmessage.AResource {
+		var r dnsmessage.AResource
+		copy(r.A[:], net.ParseIP(ip).To4())
+		return &r
+	}
+	in, none, any := dnsmessage.ClassINET, classNONE, classANY
+
+	tests := []struct {
+		name                  string
+		prereqs, updates      []dnsmessage.Resource
+		rcode                 RCode
+		create, update, del   int
+		createName, createVal string
+	}{
+		{name: "add host",
+			updates: []dnsmessage.Resource{rr("laptop.gt.lo.", in, 120, a("192.168.200.40"))},
+			create:  1, createName: "laptop", createVal: "192.168.200.40"},
+		{name: "existing record only changes TTL",
+			updates: []dnsmessage.Resource{rr("web.gt.lo.", in, 60, a("192.168.200.5"))},
+			update:  1},
+		{name: "replace TXT challenge",
+			updates: []dnsmessage.Resource{
+				emptyRR("_acme-challenge.gt.lo.", any, dnsmessage.TypeTXT),
+				rr("_acme-challenge.gt.lo.", in, 60, &dnsmessage.TXTResource{TXT: []string{"token"}}),
+			},
+			create: 1, del: 1, createName: "_acme-challenge", createVal: "token"},
+		{name: "delete one record",
+			updates: []dnsmessage.Resource{rr("web.gt.lo.", none, 0, a("192.168.200.6"))},
+			del:     1},
+		{name: "delete name",
+			updates: []dnsmessage.Resource{emptyRR("web.gt.lo.", any, typeANY)},
+			del:     2},
+		{name: "CNAME blocks other data",
+			updates: []dnsmessage.Resource{rr("www.gt.lo.", in, 60, a("192.168.200.7"))}},
+		{name: "add then delete in one message",
+			updates: []dnsmessage.Resource{
+				rr("tmp.gt.lo.", in, 60, a("192.168.200.8")),
+				emptyRR("tmp.gt.lo.", any, typeANY),
+			}},
+		{name: "name not in use",
+			prereqs: []dnsmessage.Resource{emptyRR("web.gt.lo.", none, typeANY)},
+			updates: []dnsmessage.Resource{rr("web.gt.lo.", in, 60, a("192.168.200.9"))},
+			rcode:   RCodeYXDomain},
+		{name: "name in use",
+			prereqs: []dnsmessage.Resource{emptyRR("nope.gt.lo.", any, typeANY)},
+			rcode:   RCodeNXDomain},
+		{name: "value-dependent RRset",
+			prereqs: []dnsmessage.Resource{rr("web.gt.lo.", in, 0, a("192.168.200.6")), rr("web.gt.lo.", in, 0, a("192.168.200.5"))},
+			updates: []dnsmessage.Resource{rr("web.gt.lo.", none, 0, a("192.168.200.6"))},
+			del:     1},
+		{name: "value-dependent RRset mismatch",
+			prereqs: []dnsmessage.Resource{rr("web.gt.lo.", in, 0, a("192.168.200.5"))},
+			rcode:   RCodeNXRRSet},
+		{name: "outside the zone",
+			updates: []dnsmessage.Resource{rr("host.other.lo.", in, 60, a("10.0.0.1"))},
+			rcode:   RCodeNotZone},
+		{name: "delete with a TTL",
+			updates: []dnsmessage.Resource{rr("web.gt.lo.", none, 60, a("192.168.200.6"))},
+			rcode:   RCodeFormErr},
+	}
+	for _, tt := range tests {
+		m, err := ParseUpdate(packUpdate(t, tt.prereqs, tt.updates))
+		if err != nil {
+			t.Fatalf("%s: ParseUpdate: %v", tt.name, err)
+		}
+		diff, rcode := PlanUpdate("gt.lo", have, m)
+		if rcode != tt.rcode {
+			t.Errorf("%s: rcode %s, want %s", tt.name, RCodeName(rcode), RCodeName(tt.rcode))
+			continue
+		}
+		if len(diff.Create) != tt.create || len(diff.Update) != tt.update || len(diff.Delete) != tt.del {
+			t.Errorf("%s: diff = %+v", tt.name, diff)
+			continue
+		}
+		if tt.createName != "" && (diff.Create[0].Name != tt.createName || diff.Create[0].Data.Data != tt.createVal) {
+			t.Errorf("%s: created %+v", tt.name, diff.Create[0])
+		}
+	}
+}
+
+// fakeUpdateHandler records what the server passes on.
+type fakeUpdateHandler struct {
+	applied  []*UpdateRequest
+	rejected []string
+}
+
+func (f *fakeUpdateHandler) ApplyUpdate(_ context.Context, req *UpdateRequest) RCode {
+	f.applied = append(f.applied, req)
+	return RCodeSuccess
+}
+
+func (f *fakeUpdateHandler) RejectUpdate(_ *UpdateRequest, reason string) {
+	f.rejected = append(f.rejected, reason)
+}
+
+// responseTSIG returns the rcode and TSIG of a response, and whether its
+// MAC verifies against the request MAC.
+func responseTSIG(t *testing.T, resp, reqMAC []byte, key TSIGKey) (RCode, *TSIG, bool) {
+	t.Helper()
+	var p dnsmessage.Parser
+	hdr, err := p.Start(resp)
+	if err != nil {
+		t.Fatalf("response: %v", err)
+	}
+	off, err := lastRecordOffset(resp)
+	if err != nil {
+		return hdr.RCode, nil, false
+	}
+	nameEnd, _ := skipWireName(resp, off)
+	sig, err := parseTSIG(strings.TrimSuffix(strings.ToLower(string(resp[off+1:nameEnd-1])), "."), resp[nameEnd+10:])
+	if err != nil {
+		t.Fatalf("response TSIG: %v", err)
+	}
+	unsigned := append([]byte(nil), resp[:off]...)
+	binary.BigEndian.PutUint16(unsigned[10:], binary.BigEndian.Uint16(unsigned[10:])-1)
+	prior := binary.BigEndian.AppendUint16(nil, uint16(len(reqMAC)))
+	ok := len(sig.MAC) > 0 && string(sig.MAC) == string(tsigMAC(key, prior, reqMAC, unsigned, tsigVariables(sig)))
+	return hdr.RCode, sig, ok
+}
+
+func TestUpdateServerTSIG(t *testing.T) {
+	h := &fakeUpdateHandler{}
+	srv := NewUpdateServer("127.0.0.1:0", []TSIGKey{testUpdateKey}, h, zap.NewNop().Sugar())
+	now := time.Unix(1_792_000_000, 0)
+	srv.now = func() time.Time { return now }
+
+	var a dnsmessage.AResource
+	copy(a.A[:], net.IPv4(192, 168, 200, 40).To4())
+	msg := packUpdate(t, nil, []dnsmessage.Resource{rr("laptop.gt.lo.", dnsmessage.ClassINET, 60, &a)})
+
+	// Valid signature: applied, response signed over the request MAC
+	signed := SignTSIG(msg, testUpdateKey, now.Add(-time.Minute))
+	req, _ := ParseUpdate(signed)
+	rcode, sig, ok := responseTSIG(t, srv.Handle(signed, "192.0.2.1:5300"), req.TSIG.MAC, testUpdateKey)
+	if rcode != RCodeSuccess || !ok || sig.Error != 0 {
+		t.Fatalf("signed update: rcode %s, tsig %+v, verified %v", RCodeName(rcode), sig, ok)
+	}
+	if len(h.applied) != 1 || h.applied[0].Key != "acme" || h.applied[0].Msg.Updates[0].Data != "192.168.200.40" {
+		t.Fatalf("applied = %+v", h.applied)
+	}
+
+	// Wrong secret: BADSIG with an empty MAC, not applied
+	bad := testUpdateKey
+	bad.Secret = []byte("not the secret")
+	rcode, sig, _ = responseTSIG(t, srv.Handle(SignTSIG(msg, bad, now), "192.0.2.1:5300"), nil, testUpdateKey)
+	if rcode != RCodeNotAuth || sig.Error != TSIGBadSig || len(sig.MAC) != 0 {
+		t.Errorf("bad signature: rcode %s, tsig %+v", RCodeName(rcode), sig)
+	}
+
+	// Unknown key
+	other := testUpdateKey
+	other.Name = "laptop"
+	if rcode, sig, _ = responseTSIG(t, srv.Handle(SignTSIG(msg, other, now), ""), nil, testUpdateKey); sig.Error != TSIGBadKey {
+		t.Errorf("unknown key: rcode %s, tsig %+v", RCodeName(rcode), sig)
+	}
+
+	// Stale signature: BADTIME, still signed
+	stale := SignTSIG(msg, testUpdateKey, now.Add(-time.Hour))
+	req, _ = ParseUpdate(stale)
+	if rcode, sig, ok = responseTSIG(t, srv.Handle(stale, ""), req.TSIG.MAC, testUpdateKey); sig.Error != TSIGBadTime || !ok {
+		t.Errorf("stale: rcode %s, tsig %+v, verified %v", RCodeName(rcode), sig, ok)
+	}
+
+	// Unsigned: refused
+	if rcode, _, _ = responseTSIG(t, srv.Handle(msg, ""), nil, testUpdateKey); rcode != RCodeRefused {
+		t.Errorf("unsigned: rcode %s", RCodeName(rcode))
+	}
+	if len(h.applied) != 1 || len(h.rejected) != 4 {
+		t.Errorf("applied %d, rejected %v", len(h.applied), h.rejected)
+	}
+
+	// Over the wire
+	if err := srv.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer srv.Stop()
+	for _, network := range []string{"udp", "tcp"} {
+		conn, err := net.Dial(network, srv.LocalAddr())
+		if err != nil {
+			t.Fatalf("dial %s: %v", network, err)
+		}
+		out := SignTSIG(msg, testUpdateKey, now)
+		if network == "tcp" {
+			out = append(binary.BigEndian.AppendUint16(nil, uint16(len(out))), out...)
+		}
+		_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+		if _, err := conn.Write(out); err != nil {
+			t.Fatalf("%s write: %v", network, err)
+		}
+		buf := make([]byte, 1024)
+		n, err := conn.Read(buf)
+		conn.Close()
+		if err != nil {
+			t.Fatalf("%s read: %v", network, err)
+		}
+		resp := buf[:n]
+		if network == "tcp" {
+			resp = resp[2:]
+		}
+		var p dnsmessage.Parser
+		if hdr, err := p.Start(resp); err != nil || hdr.RCode != RCodeSuccess || hdr.ID != 0x4242 {
+			t.Errorf("%s response: %+v %v", network, hdr, err)
+		}
+	}
+}
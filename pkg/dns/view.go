@@ -0,0 +1,126 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ─── Split-Horizon Views ────────────────────────────────────────────────────
+
+// A view zone is a microdns zone carrying a view name next to the zone it
+// shadows. microdns answers clients matched to the view (see the
+// [[dns.auth.views]] TOML section) from the view zone first and falls back
+// to the zone itself for names and types the view does not hold, so a view
+// zone only needs the overrides.
+
+// EnsureViewZone finds or creates the view zone of zoneName for view.
+// Returns the zone UUID.
+func (c *Client) EnsureViewZone(ctx context.Context, endpoint, zoneName, view string) (string, error) {
+	if view == "" {
+		return "", fmt.Errorf("view name is required")
+	}
+	return c.ensureZone(ctx, endpoint, zoneName, view)
+}
+
+// ViewZones returns the view zones shadowing zoneName, keyed by view name.
+func (c *Client) ViewZones(ctx context.Context, endpoint, zoneName string) (map[string]string, error) {
+	zones, err := c.ListZones(ctx, endpoint)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]string)
+	for _, z := range zones {
+		if z.View != "" && z.Name == zoneName {
+			out[z.View] = z.ID
+		}
+	}
+	return out, nil
+}
+
+// DeleteZone removes a zone and all its records.
+func (c *Client) DeleteZone(ctx context.Context, endpoint, zoneID string) error {
+	url := fmt.Sprintf("%s/api/v1/zones/%s", endpoint, zoneID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, nil)
+	if err != nil {
+		return fmt.Errorf("building zone delete request: %w", err)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("deleting zone %s: %w", zoneID, err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("deleting zone %s: HTTP %d", zoneID, resp.StatusCode)
+	}
+	c.invalidateCache(endpoint, zoneID)
+	return nil
+}
+
+// SameRecord reports whether two records carry the same name, type, TTL
+// and data, comparing addresses and target names by value.
+func SameRecord(a, b Record) bool {
+	if !strings.EqualFold(a.Name, b.Name) || a.Type != b.Type || a.TTL != b.TTL {
+		return false
+	}
+	switch a.Type {
+	case "A", "AAAA":
+		return sameIP(a.Data.Data, b.Data.Data)
+	case "CNAME":
+		return SameName(a.Data.Data, b.Data.Data)
+	}
+	return a.Data.Data == b.Data.Data
+}
+
+// SyncViewRecords makes a view zone hold exactly want: records that are not
+// wanted are deleted and missing ones created. View zones belong to the
+// Network spec alone, so nothing else is kept.
+func (c *Client) SyncViewRecords(ctx context.Context, endpoint, zoneID string, want []Record) (created, deleted int, err error) {
+	records, err := c.ListRecords(ctx, endpoint, zoneID)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	kept := make([]bool, len(want))
+	for _, r := range records {
+		found := false
+		for i, w := range want {
+			if !kept[i] && SameRecord(r, w) {
+				kept[i], found = true, true
+				break
+			}
+		}
+		if found {
+			continue
+		}
+		if err := c.DeleteRecord(ctx, endpoint, zoneID, r.ID); err != nil {
+			return created, deleted, err
+		}
+		deleted++
+		c.log.Infow("view record removed", "name", r.Name, "type", r.Type, "data", r.Data.Data, "zone", zoneID)
+	}
+
+	for i, w := range want {
+		if kept[i] {
+			continue
+		}
+		_, err := c.CreateFullRecord(ctx, endpoint, zoneID, createRecordRequest{
+			Name: w.Name,
+			TTL:  w.TTL,
+			Data: w.Data,
+		})
+		if err != nil {
+			return created, deleted, err
+		}
+		created++
+		c.log.Infow("view record registered", "name", w.Name, "type", w.Type, "data", w.Data.Data, "zone", zoneID)
+	}
+
+	if created+deleted > 0 {
+		c.invalidateCache(endpoint, zoneID)
+	}
+	return created, deleted, nil
+}
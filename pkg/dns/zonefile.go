@@ -0,0 +1,569 @@
+package dns
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ─── Zone Files (RFC 1035 master format) ────────────────────────────────────
+
+// defaultZoneTTL is used for records with neither an explicit TTL nor a
+// $TTL directive in effect, and for exported records stored without one.
+const defaultZoneTTL = 300
+
+// zoneFileTypes are the record types zone files carry. Records of other
+// types are never exported as data, imported, or deleted by an import.
+var zoneFileTypes = map[string]bool{
+	"A": true, "AAAA": true, "CNAME": true, "PTR": true, "SRV": true, "TXT": true, "MX": true,
+}
+
+// ZoneFileType reports whether rtype is carried by zone-file import/export.
+func ZoneFileType(rtype string) bool {
+	return zoneFileTypes[strings.ToUpper(rtype)]
+}
+
+// MXData is the payload of an MX record as microdns stores it.
+type MXData struct {
+	Preference int    `json:"preference"`
+	Exchange   string `json:"exchange"`
+}
+
+// decodeRecordData converts structured record data (a typed value or the
+// generic map JSON decoding produced) into into.
+func decodeRecordData(data interface{}, into interface{}) error {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, into)
+}
+
+// Text renders record data in master-file presentation format, e.g.
+// "10 50 8080 web.gt.lo." for SRV or a quoted string for TXT.
+func (d FullRecordData) Text() string {
+	switch d.Type {
+	case "SRV":
+		var s SRVData
+		if decodeRecordData(d.Data, &s) == nil {
+			return fmt.Sprintf("%d %d %d %s", s.Priority, s.Weight, s.Port, s.Target)
+		}
+	case "MX":
+		var m MXData
+		if decodeRecordData(d.Data, &m) == nil {
+			return fmt.Sprintf("%d %s", m.Preference, m.Exchange)
+		}
+	case "TXT":
+		if s, ok := d.Data.(string); ok {
+			return quoteTXT(s)
+		}
+	}
+	if s, ok := d.Data.(string); ok {
+		return s
+	}
+	b, _ := json.Marshal(d.Data)
+	return string(b)
+}
+
+// quoteTXT quotes a TXT string, split into the 255-byte character-strings
+// a TXT record is made of.
+func quoteTXT(s string) string {
+	var parts []string
+	for {
+		chunk := s
+		if len(chunk) > 255 {
+			chunk = s[:255]
+		}
+		r := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+		parts = append(parts, `"`+r.Replace(chunk)+`"`)
+		s = s[len(chunk):]
+		if s == "" {
+			return strings.Join(parts, " ")
+		}
+	}
+}
+
+// WriteZoneFile writes records as a master file for origin. Disabled
+// records and types outside ZoneFileType are written as comments, so the
+// dump is complete but re-importing it leaves them alone.
+func WriteZoneFile(w io.Writer, origin string, records []FullRecord) error {
+	origin = strings.TrimSuffix(origin, ".")
+	sorted := append([]FullRecord(nil), records...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.Name != b.Name {
+			return ownerName(a.Name) < ownerName(b.Name)
+		}
+		return a.Type < b.Type
+	})
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "; zone %s\n$ORIGIN %s.\n$TTL %d\n\n", origin, origin, defaultZoneTTL)
+	for _, r := range sorted {
+		ttl := r.TTL
+		if ttl <= 0 {
+			ttl = defaultZoneTTL
+		}
+		line := fmt.Sprintf("%-30s %6d IN %-5s %s", ownerName(r.Name), ttl, r.Type, r.Data.Text())
+		switch {
+		case !ZoneFileType(r.Type):
+			line = "; unsupported: " + line
+		case !r.Enabled:
+			line = "; disabled: " + line
+		}
+		fmt.Fprintln(bw, line)
+	}
+	return bw.Flush()
+}
+
+// ownerName renders a record name as a zone-file owner.
+func ownerName(name string) string {
+	if name == "" {
+		return "@"
+	}
+	return name
+}
+
+// ─── Zone File Parsing ──────────────────────────────────────────────────────
+
+type zoneToken struct {
+	text   string
+	quoted bool
+}
+
+// zoneLine is one logical entry: a physical line, or several joined by
+// parentheses.
+type zoneLine struct {
+	num        int
+	blankOwner bool // starts with whitespace: owner is the previous one
+	tokens     []zoneToken
+}
+
+// splitZoneLines tokenizes master-file text, dropping comments and
+// joining parenthesized continuations.
+func splitZoneLines(data string) ([]zoneLine, error) {
+	var lines []zoneLine
+	var cur zoneLine
+	depth, num := 0, 1
+	lineStart := true
+
+	for i := 0; i < len(data); {
+		c := data[i]
+		switch {
+		case c == '\n':
+			num++
+			i++
+			if depth == 0 {
+				if len(cur.tokens) > 0 {
+					lines = append(lines, cur)
+				}
+				cur = zoneLine{}
+				lineStart = true
+			}
+			continue
+		case c == ';':
+			for i < len(data) && data[i] != '\n' {
+				i++
+			}
+			continue
+		case c == ' ' || c == '\t' || c == '\r':
+			if lineStart && depth == 0 && len(cur.tokens) == 0 {
+				cur.blankOwner = true
+			}
+			i++
+		case c == '(':
+			depth++
+			i++
+		case c == ')':
+			if depth == 0 {
+				return nil, fmt.Errorf("line %d: unbalanced ')'", num)
+			}
+			depth--
+			i++
+		default:
+			quoted := c == '"'
+			if quoted {
+				i++
+			}
+			if cur.num == 0 {
+				cur.num = num
+			}
+			var b strings.Builder
+			for ; i < len(data); i++ {
+				c := data[i]
+				if quoted && c == '"' {
+					i++
+					break
+				}
+				if !quoted && strings.IndexByte(" \t\r\n;()\"", c) >= 0 {
+					break
+				}
+				if c == '\n' {
+					num++
+				}
+				if c == '\\' && i+1 < len(data) {
+					if i+3 < len(data) && isDigits(data[i+1:i+4]) {
+						v, _ := strconv.Atoi(data[i+1 : i+4])
+						b.WriteByte(byte(v))
+						i += 3
+						continue
+					}
+					i++
+					c = data[i]
+				}
+				b.WriteByte(c)
+			}
+			cur.tokens = append(cur.tokens, zoneToken{text: b.String(), quoted: quoted})
+		}
+		lineStart = false
+	}
+	if depth != 0 {
+		return nil, fmt.Errorf("line %d: unbalanced '('", num)
+	}
+	if len(cur.tokens) > 0 {
+		lines = append(lines, cur)
+	}
+	return lines, nil
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// parseTTL parses a TTL in seconds, accepting BIND unit suffixes ("1h30m").
+func parseTTL(s string) (int, bool) {
+	if s == "" {
+		return 0, false
+	}
+	if n, err := strconv.Atoi(s); err == nil {
+		return n, n >= 0
+	}
+	total, n := 0, -1
+	for _, c := range strings.ToLower(s) {
+		if c >= '0' && c <= '9' {
+			if n < 0 {
+				n = 0
+			}
+			n = n*10 + int(c-'0')
+			continue
+		}
+		unit := map[rune]int{'s': 1, 'm': 60, 'h': 3600, 'd': 86400, 'w': 604800}[c]
+		if unit == 0 || n < 0 {
+			return 0, false
+		}
+		total += n * unit
+		n = -1
+	}
+	return total, n < 0
+}
+
+// ParseZoneFile parses a master file for the zone origin into records
+// named relative to origin ("@" for the apex). SOA and NS records are
+// skipped: microdns serves its own. $INCLUDE and $GENERATE are rejected.
+func ParseZoneFile(r io.Reader, origin string) ([]FullRecord, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	lines, err := splitZoneLines(string(data))
+	if err != nil {
+		return nil, err
+	}
+
+	zone := strings.ToLower(strings.TrimSuffix(origin, ".")) + "."
+	cur := zone // $ORIGIN in effect
+	defTTL := defaultZoneTTL
+	owner := ""
+	var out []FullRecord
+
+	for _, l := range lines {
+		t := l.tokens
+		if !t[0].quoted && strings.HasPrefix(t[0].text, "$") {
+			switch dir := strings.ToUpper(t[0].text); dir {
+			case "$ORIGIN":
+				if len(t) != 2 {
+					return nil, fmt.Errorf("line %d: $ORIGIN needs one name", l.num)
+				}
+				cur = absName(t[1].text, cur)
+			case "$TTL":
+				ttl, ok := 0, len(t) == 2
+				if ok {
+					ttl, ok = parseTTL(t[1].text)
+				}
+				if !ok {
+					return nil, fmt.Errorf("line %d: invalid $TTL", l.num)
+				}
+				defTTL = ttl
+			default:
+				return nil, fmt.Errorf("line %d: %s is not supported", l.num, dir)
+			}
+			continue
+		}
+
+		if !l.blankOwner {
+			name, err := relName(absName(t[0].text, cur), zone)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", l.num, err)
+			}
+			owner, t = name, t[1:]
+		} else if owner == "" {
+			return nil, fmt.Errorf("line %d: record without an owner name", l.num)
+		}
+
+		ttl := -1
+		for len(t) > 0 {
+			if v, ok := parseTTL(t[0].text); ok && ttl < 0 {
+				ttl, t = v, t[1:]
+				continue
+			}
+			if class := strings.ToUpper(t[0].text); class == "IN" {
+				t = t[1:]
+				continue
+			} else if class == "CH" || class == "HS" || class == "CS" {
+				return nil, fmt.Errorf("line %d: class %s is not supported", l.num, class)
+			}
+			break
+		}
+		if len(t) == 0 {
+			return nil, fmt.Errorf("line %d: missing record type", l.num)
+		}
+		if ttl < 0 {
+			ttl = defTTL
+		}
+		rtype, rdata := strings.ToUpper(t[0].text), t[1:]
+		if rtype == "SOA" || rtype == "NS" {
+			continue
+		}
+
+		value, err := parseRData(rtype, rdata, cur, zone)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s %s: %w", l.num, owner, rtype, err)
+		}
+		out = append(out, FullRecord{
+			Name:    owner,
+			TTL:     ttl,
+			Type:    rtype,
+			Data:    FullRecordData{Type: rtype, Data: value},
+			Enabled: true,
+		})
+	}
+	return out, nil
+}
+
+// parseRData parses the data fields of one record into the value microdns
+// stores: a string, SRVData or MXData.
+func parseRData(rtype string, rdata []zoneToken, cur, zone string) (interface{}, error) {
+	want := map[string]int{"A": 1, "AAAA": 1, "CNAME": 1, "PTR": 1, "MX": 2, "SRV": 4}
+	if n, ok := want[rtype]; ok && len(rdata) != n {
+		return nil, fmt.Errorf("expected %d data fields, got %d", n, len(rdata))
+	}
+	num := func(s string) (int, error) {
+		v, err := strconv.Atoi(s)
+		if err != nil || v < 0 || v > 65535 {
+			return 0, fmt.Errorf("%q is not a 16-bit number", s)
+		}
+		return v, nil
+	}
+
+	switch rtype {
+	case "A", "AAAA":
+		ip := net.ParseIP(rdata[0].text)
+		if ip == nil || (ip.To4() != nil) != (rtype == "A") {
+			return nil, fmt.Errorf("%q is not an %s address", rdata[0].text, rtype)
+		}
+		return ip.String(), nil
+	case "CNAME", "PTR":
+		return targetName(rdata[0].text, cur, zone), nil
+	case "MX":
+		pref, err := num(rdata[0].text)
+		if err != nil {
+			return nil, err
+		}
+		return MXData{Preference: pref, Exchange: targetName(rdata[1].text, cur, zone)}, nil
+	case "SRV":
+		var v [3]int
+		for i := range v {
+			n, err := num(rdata[i].text)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = n
+		}
+		return SRVData{Priority: v[0], Weight: v[1], Port: v[2], Target: targetName(rdata[3].text, cur, zone)}, nil
+	case "TXT":
+		if len(rdata) == 0 {
+			return nil, fmt.Errorf("no text")
+		}
+		var b strings.Builder
+		for _, s := range rdata {
+			b.WriteString(s.text)
+		}
+		return b.String(), nil
+	}
+	return nil, fmt.Errorf("record type %s is not supported", rtype)
+}
+
+// absName qualifies a zone-file name against the $ORIGIN in effect.
+func absName(name, cur string) string {
+	switch {
+	case name == "@":
+		return cur
+	case strings.HasSuffix(name, "."):
+		return name
+	}
+	return name + "." + cur
+}
+
+// relName makes an absolute name relative to the zone, "@" for the apex.
+func relName(abs, zone string) (string, error) {
+	if strings.EqualFold(abs, zone) {
+		return "@", nil
+	}
+	if len(abs) > len(zone) && strings.EqualFold(abs[len(abs)-len(zone)-1:], "."+zone) {
+		return abs[:len(abs)-len(zone)-1], nil
+	}
+	return "", fmt.Errorf("%s is outside zone %s", abs, zone)
+}
+
+// targetName keeps a relative target as written when it is relative to
+// the zone itself, and qualifies it otherwise.
+func targetName(name, cur, zone string) string {
+	if name != "@" && !strings.HasSuffix(name, ".") && strings.EqualFold(cur, zone) {
+		return name
+	}
+	return absName(name, cur)
+}
+
+// ─── Zone Diff ──────────────────────────────────────────────────────────────
+
+// ZoneUpdate replaces Old (an existing record, with ID) by New.
+type ZoneUpdate struct {
+	Old, New FullRecord
+}
+
+// ZoneDiff is what it takes to turn a zone's records into a zone file's.
+type ZoneDiff struct {
+	Create    []FullRecord
+	Update    []ZoneUpdate
+	Delete    []FullRecord
+	Unchanged int
+}
+
+// DiffZone compares existing records (from ListFullRecords) with want
+// (from ParseZoneFile). Records are matched by owner and type: identical
+// data is kept (updated if only the TTL differs), remaining records of an
+// owner and type are updated pairwise, and the rest created or deleted.
+// Disabled records and types outside ZoneFileType are ignored.
+func DiffZone(origin string, have, want []FullRecord) ZoneDiff {
+	zone := strings.ToLower(strings.TrimSuffix(origin, ".")) + "."
+	type group struct{ have, want []FullRecord }
+	groups := make(map[string]*group)
+	key := func(r FullRecord) string {
+		return canonicalName(ownerName(r.Name), zone) + " " + strings.ToUpper(r.Type)
+	}
+	get := func(k string) *group {
+		if groups[k] == nil {
+			groups[k] = &group{}
+		}
+		return groups[k]
+	}
+	for _, r := range have {
+		if r.Enabled && ZoneFileType(r.Type) {
+			g := get(key(r))
+			g.have = append(g.have, r)
+		}
+	}
+	for _, r := range want {
+		g := get(key(r))
+		g.want = append(g.want, r)
+	}
+
+	keys := make([]string, 0, len(groups))
+	for k := range groups {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var d ZoneDiff
+	for _, k := range keys {
+		g := groups[k]
+		usedHave := make([]bool, len(g.have))
+		var pending []FullRecord
+		for _, w := range g.want {
+			wd := canonicalData(w.Data, zone)
+			match := -1
+			for i, h := range g.have {
+				if !usedHave[i] && canonicalData(h.Data, zone) == wd {
+					match = i
+					break
+				}
+			}
+			if match < 0 {
+				pending = append(pending, w)
+				continue
+			}
+			usedHave[match] = true
+			if g.have[match].TTL == w.TTL {
+				d.Unchanged++
+			} else {
+				d.Update = append(d.Update, ZoneUpdate{Old: g.have[match], New: w})
+			}
+		}
+		for i, h := range g.have {
+			if usedHave[i] {
+				continue
+			}
+			if len(pending) > 0 {
+				d.Update = append(d.Update, ZoneUpdate{Old: h, New: pending[0]})
+				pending = pending[1:]
+				continue
+			}
+			d.Delete = append(d.Delete, h)
+		}
+		d.Create = append(d.Create, pending...)
+	}
+	return d
+}
+
+// canonicalName qualifies and lower-cases a name for comparison.
+func canonicalName(name, zone string) string {
+	return strings.ToLower(absName(name, zone))
+}
+
+// canonicalData renders record data for comparison: names qualified and
+// lower-cased, addresses in canonical form.
+func canonicalData(d FullRecordData, zone string) string {
+	switch strings.ToUpper(d.Type) {
+	case "A", "AAAA":
+		if s, ok := d.Data.(string); ok {
+			if ip := net.ParseIP(s); ip != nil {
+				return ip.String()
+			}
+		}
+	case "CNAME", "PTR":
+		if s, ok := d.Data.(string); ok {
+			return canonicalName(s, zone)
+		}
+	case "MX":
+		var m MXData
+		if decodeRecordData(d.Data, &m) == nil {
+			return fmt.Sprintf("%d %s", m.Preference, canonicalName(m.Exchange, zone))
+		}
+	case "SRV":
+		var s SRVData
+		if decodeRecordData(d.Data, &s) == nil {
+			return fmt.Sprintf("%d %d %d %s", s.Priority, s.Weight, s.Port, canonicalName(s.Target, zone))
+		}
+	}
+	return d.Text()
+}
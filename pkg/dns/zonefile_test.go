@@ -0,0 +1,152 @@
+package dns
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testZoneFile = `; dns-backup/gt.lo
+$ORIGIN gt.lo.
+$TTL 1h
+@        IN SOA ns.gt.lo. admin.gt.lo. (
+             2026101801 ; serial
+             3600 900 604800 300 )
+         IN NS  ns.gt.lo.
+@        60 IN MX 10 mail
+web      60 IN A  192.168.200.5
+         60 IN A  192.168.200.6
+web.gt.lo.  IN AAAA fd00::0005
+www      CNAME web
+_http._tcp.web 30 IN SRV 0 50 8080 web.gt.lo.
+info     TXT "version=2; path=\"/api\"" " more"
+$ORIGIN lab.gt.lo.
+printer  120 A 192.168.200.9
+scan        CNAME printer
+`
+
+func TestParseZoneFile(t *testing.T) {
+	got, err := ParseZoneFile(strings.NewReader(testZoneFile), "gt.lo")
+	if err != nil {
+		t.Fatalf("ParseZoneFile: %v", err)
+	}
+	rec := func(name string, ttl int, rtype string, data interface{}) FullRecord {
+		return FullRecord{Name: name, TTL: ttl, Type: rtype, Data: FullRecordData{Type: rtype, Data: data}, Enabled: true}
+	}
+	want := []FullRecord{
+		rec("@", 60, "MX", MXData{Preference: 10, Exchange: "mail"}),
+		rec("web", 60, "A", "192.168.200.5"),
+		rec("web", 60, "A", "192.168.200.6"),
+		rec("web", 3600, "AAAA", "fd00::5"),
+		rec("www", 3600, "CNAME", "web"),
+		rec("_http._tcp.web", 30, "SRV", SRVData{Priority: 0, Weight: 50, Port: 8080, Target: "web.gt.lo."}),
+		rec("info", 3600, "TXT", `version=2; path="/api" more`),
+		rec("printer.lab", 120, "A", "192.168.200.9"),
+		rec("scan.lab", 3600, "CNAME", "printer.lab.gt.lo."),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parsed:\n%+v\nwant:\n%+v", got, want)
+	}
+
+	for _, bad := range []string{
+		"web IN A 10.0.0.300",
+		"web IN AAAA 10.0.0.1",
+		"web.other.lo. IN A 10.0.0.1",
+		"web IN HINFO a b",
+		"$INCLUDE other.zone",
+		"web IN SRV 0 0 http web",
+		"@ IN SOA ns admin ( 1 2 3",
+		"  IN A 10.0.0.1",
+	} {
+		if _, err := ParseZoneFile(strings.NewReader(bad), "gt.lo"); err == nil {
+			t.Errorf("ParseZoneFile accepted %q", bad)
+		}
+	}
+}
+
+func TestZoneFileRoundTrip(t *testing.T) {
+	records, err := ParseZoneFile(strings.NewReader(testZoneFile), "gt.lo")
+	if err != nil {
+		t.Fatalf("ParseZoneFile: %v", err)
+	}
+	// What microdns would hand back: generic maps, IDs, plus records
+	// zone files leave alone
+	var have []FullRecord
+	for i, r := range records {
+		var data interface{} = r.Data.Data
+		if _, ok := data.(string); !ok {
+			var m map[string]interface{}
+			_ = decodeRecordData(data, &m)
+			data = m
+		}
+		r.ID, r.Data.Data = string(rune('a'+i)), data
+		have = append(have, r)
+	}
+	have = append(have,
+		FullRecord{ID: "x", Name: "@", TTL: 300, Type: "CAA", Data: FullRecordData{Type: "CAA", Data: map[string]interface{}{"tag": "issue"}}, Enabled: true},
+		FullRecord{ID: "y", Name: "old", TTL: 60, Type: "A", Data: FullRecordData{Type: "A", Data: "10.0.0.1"}},
+	)
+
+	var buf bytes.Buffer
+	if err := WriteZoneFile(&buf, "gt.lo", have); err != nil {
+		t.Fatalf("WriteZoneFile: %v", err)
+	}
+	out := buf.String()
+	for _, line := range []string{
+		"$ORIGIN gt.lo.",
+		"_http._tcp.web                     30 IN SRV   0 50 8080 web.gt.lo.",
+		`info                             3600 IN TXT   "version=2; path=\"/api\" more"`,
+		"; unsupported: @",
+		"; disabled: old",
+	} {
+		if !strings.Contains(out, line) {
+			t.Errorf("export lacks %q:\n%s", line, out)
+		}
+	}
+
+	again, err := ParseZoneFile(strings.NewReader(out), "gt.lo")
+	if err != nil {
+		t.Fatalf("re-parsing export: %v\n%s", err, out)
+	}
+	if d := DiffZone("gt.lo", have, again); len(d.Create)+len(d.Update)+len(d.Delete) != 0 || d.Unchanged != len(records) {
+		t.Errorf("export does not round-trip: %+v", d)
+	}
+}
+
+func TestDiffZone(t *testing.T) {
+	a := func(id, name string, ttl int, ip string) FullRecord {
+		return FullRecord{ID: id, Name: name, TTL: ttl, Type: "A", Data: FullRecordData{Type: "A", Data: ip}, Enabled: true}
+	}
+	have := []FullRecord{
+		a("1", "web", 60, "10.0.0.5"),
+		a("2", "web", 60, "10.0.0.6"),
+		a("3", "db", 60, "10.0.0.7"),
+		a("4", "gone", 60, "10.0.0.8"),
+		{ID: "5", Name: "alias", TTL: 60, Type: "CNAME", Data: FullRecordData{Type: "CNAME", Data: "web.gt.lo."}, Enabled: true},
+	}
+	want := []FullRecord{
+		a("", "web", 60, "10.0.0.6"),  // kept
+		a("", "web", 60, "10.0.0.9"),  // replaces .5
+		a("", "db", 300, "10.0.0.7"),  // TTL change
+		a("", "new", 60, "10.0.0.10"), // created
+		{Name: "ALIAS", TTL: 60, Type: "CNAME", Data: FullRecordData{Type: "CNAME", Data: "web"}}, // same target, relative
+	}
+	d := DiffZone("gt.lo", have, want)
+	if d.Unchanged != 2 {
+		t.Errorf("unchanged = %d, want 2", d.Unchanged)
+	}
+	if len(d.Create) != 1 || d.Create[0].Name != "new" {
+		t.Errorf("creates = %+v", d.Create)
+	}
+	if len(d.Delete) != 1 || d.Delete[0].ID != "4" {
+		t.Errorf("deletes = %+v", d.Delete)
+	}
+	var updated []string
+	for _, u := range d.Update {
+		updated = append(updated, u.Old.ID+"->"+u.New.Data.Text())
+	}
+	if !reflect.DeepEqual(updated, []string{"3->10.0.0.7", "1->10.0.0.9"}) {
+		t.Errorf("updates = %v", updated)
+	}
+}
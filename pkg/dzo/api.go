@@ -21,6 +21,10 @@ func (o *Operator) RegisterRoutes(mux *http.ServeMux) {
 	// Instances (read-only)
 	mux.HandleFunc("GET /api/v1/instances", o.handleListInstances)
 	mux.HandleFunc("GET /api/v1/instances/{name}", o.handleGetInstance)
+
+	// Delegation and forwarding mesh
+	mux.HandleFunc("GET /api/v1/dnsmesh", o.handleGetMesh)
+	mux.HandleFunc("POST /api/v1/dnsmesh/reconcile", o.handleReconcileMesh)
 }
 
 // RunAPI starts the DZO REST API server on its own mux (convenience wrapper).
@@ -117,6 +121,16 @@ func (o *Operator) handleGetInstance(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, inst)
 }
 
+// ─── Mesh Handlers ──────────────────────────────────────────────────────────
+
+func (o *Operator) handleGetMesh(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, o.Mesh())
+}
+
+func (o *Operator) handleReconcileMesh(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, o.ReconcileMesh(r.Context()))
+}
+
 // ─── Health ─────────────────────────────────────────────────────────────────
 
 func (o *Operator) handleHealthz(w http.ResponseWriter, r *http.Request) {
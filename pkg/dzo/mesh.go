@@ -0,0 +1,361 @@
+package dzo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/glennswest/mkube/pkg/dns"
+)
+
+// ─── Delegation & Forwarding Mesh ───────────────────────────────────────────
+
+// delegationTTL is the TTL of NS and glue records written for delegations.
+const delegationTTL = 3600
+
+// Delegation is the NS record and glue a parent zone holds for a child zone
+// served by another MicroDNS instance.
+type Delegation struct {
+	Zone       string `json:"zone"`       // "kube.gt.lo"
+	Parent     string `json:"parent"`     // "gt.lo"
+	NameServer string `json:"nameServer"` // "ns.kube.gt.lo"
+	IP         string `json:"ip"`         // glue address of the child's instance
+}
+
+// Forwarder is a forward zone on one MicroDNS instance pointing at the
+// instance serving the zone.
+type Forwarder struct {
+	Instance string   `json:"instance"`
+	Endpoint string   `json:"endpoint"`
+	Zone     string   `json:"zone"`
+	Servers  []string `json:"servers"`
+}
+
+// MeshStatus is the outcome of the last mesh reconcile.
+type MeshStatus struct {
+	Delegations  []Delegation `json:"delegations"`
+	Forwarders   []Forwarder  `json:"forwarders"`
+	Errors       []string     `json:"errors,omitempty"`
+	ReconciledAt time.Time    `json:"reconciledAt"`
+}
+
+// Mesh returns the outcome of the last mesh reconcile.
+func (o *Operator) Mesh() MeshStatus {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return o.mesh
+}
+
+// ReconcileMesh recomputes delegations and forwarders and applies them.
+func (o *Operator) ReconcileMesh(ctx context.Context) MeshStatus {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.reconcileMesh(ctx)
+	if err := o.saveState(); err != nil {
+		o.log.Warnw("failed to save state after mesh reconcile", "error", err)
+	}
+	return o.mesh
+}
+
+// planMesh works out the delegations and forwarders that let every
+// instance resolve every zone: each zone served by a different instance
+// than its parent is delegated with NS and glue in the parent, and every
+// instance forwards each zone it does not serve to the instance that does.
+// Instances on external DNS networks are forwarded to but never configured.
+func (o *Operator) planMesh() ([]Delegation, []Forwarder) {
+	zoneNames := make([]string, 0, len(o.state.Zones))
+	for name := range o.state.Zones {
+		zoneNames = append(zoneNames, name)
+	}
+	sort.Strings(zoneNames)
+
+	var delegations []Delegation
+	for _, name := range zoneNames {
+		z := o.state.Zones[name]
+		parent, ok := o.state.Zones[z.Parent]
+		if !ok || parent.Endpoint == z.Endpoint {
+			continue
+		}
+		inst := o.instanceFor(z.Endpoint)
+		if inst == nil || inst.IP == "" {
+			continue
+		}
+		delegations = append(delegations, Delegation{
+			Zone:       z.Name,
+			Parent:     parent.Name,
+			NameServer: "ns." + z.Name,
+			IP:         inst.IP,
+		})
+	}
+
+	var forwarders []Forwarder
+	for _, endpoint := range o.meshEndpoints() {
+		inst := o.instanceFor(endpoint)
+		for _, name := range zoneNames {
+			z := o.state.Zones[name]
+			if z.Endpoint == endpoint {
+				continue
+			}
+			target := o.instanceFor(z.Endpoint)
+			if target == nil || target.IP == "" {
+				continue
+			}
+			forwarders = append(forwarders, Forwarder{
+				Instance: inst.Name,
+				Endpoint: endpoint,
+				Zone:     z.Name,
+				Servers:  []string{target.IP + ":53"},
+			})
+		}
+	}
+	return delegations, forwarders
+}
+
+// meshEndpoints returns the endpoints of the instances the operator
+// configures forwarders on, sorted.
+func (o *Operator) meshEndpoints() []string {
+	seen := make(map[string]bool)
+	var endpoints []string
+	for _, inst := range o.state.Instances {
+		if inst.Endpoint == "" || seen[inst.Endpoint] {
+			continue
+		}
+		if netDef := o.findNetwork(inst.Network); netDef != nil && netDef.ExternalDNS {
+			continue
+		}
+		seen[inst.Endpoint] = true
+		endpoints = append(endpoints, inst.Endpoint)
+	}
+	sort.Strings(endpoints)
+	return endpoints
+}
+
+// instanceFor returns the instance behind an endpoint; instances sharing
+// one are picked by name so the choice is stable.
+func (o *Operator) instanceFor(endpoint string) *MicroDNSInstance {
+	var found *MicroDNSInstance
+	for _, inst := range o.state.Instances {
+		if inst.Endpoint == endpoint && (found == nil || inst.Name < found.Name) {
+			found = inst
+		}
+	}
+	return found
+}
+
+// reconcileMesh applies the planned delegations and forwarders and removes
+// those it created earlier that are no longer planned. Failures are
+// collected in the status rather than aborting, so one unreachable
+// instance does not hold back the rest. Caller holds o.mu and saves state.
+func (o *Operator) reconcileMesh(ctx context.Context) {
+	delegations, forwarders := o.planMesh()
+	var errs []string
+
+	delegated := make(map[string][]string)
+	for _, d := range delegations {
+		if err := o.ensureDelegation(ctx, d); err != nil {
+			errs = append(errs, fmt.Sprintf("delegating %s in %s: %v", d.Zone, d.Parent, err))
+		}
+		delegated[d.Parent] = append(delegated[d.Parent], d.Zone)
+	}
+	for parent, children := range o.state.Delegations {
+		for _, child := range children {
+			if containsString(delegated[parent], child) {
+				continue
+			}
+			if err := o.removeDelegation(ctx, parent, child); err != nil {
+				errs = append(errs, fmt.Sprintf("removing delegation of %s from %s: %v", child, parent, err))
+				delegated[parent] = append(delegated[parent], child) // retry next time
+			}
+		}
+	}
+	o.state.Delegations = delegated
+
+	byEndpoint := make(map[string][]Forwarder)
+	for _, f := range forwarders {
+		byEndpoint[f.Endpoint] = append(byEndpoint[f.Endpoint], f)
+	}
+	owned := make(map[string][]string)
+	for _, endpoint := range o.meshEndpoints() {
+		zones, err := o.syncForwarders(ctx, endpoint, byEndpoint[endpoint], o.state.Forwarders[endpoint])
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("forwarders on %s: %v", endpoint, err))
+		}
+		if len(zones) > 0 {
+			owned[endpoint] = zones
+		}
+	}
+	o.state.Forwarders = owned
+
+	o.mesh = MeshStatus{
+		Delegations:  delegations,
+		Forwarders:   forwarders,
+		Errors:       errs,
+		ReconciledAt: time.Now(),
+	}
+	o.log.Infow("DNS mesh reconciled",
+		"delegations", len(delegations),
+		"forwarders", len(forwarders),
+		"errors", len(errs),
+	)
+}
+
+// ensureDelegation writes the NS record and glue for a child zone into its
+// parent, and the name server's address into the child itself.
+func (o *Operator) ensureDelegation(ctx context.Context, d Delegation) error {
+	parent, child := o.state.Zones[d.Parent], o.state.Zones[d.Zone]
+	label := strings.TrimSuffix(d.Zone, "."+d.Parent)
+	if err := o.ensureMeshRecord(ctx, parent, label, "NS", d.NameServer+"."); err != nil {
+		return err
+	}
+	if err := o.ensureMeshRecord(ctx, parent, "ns."+label, "A", d.IP); err != nil {
+		return err
+	}
+	return o.ensureMeshRecord(ctx, child, "ns", "A", d.IP)
+}
+
+// removeDelegation deletes the NS record and glue of child from parent.
+// A parent that no longer exists has nothing left to clean.
+func (o *Operator) removeDelegation(ctx context.Context, parentName, child string) error {
+	parent, ok := o.state.Zones[parentName]
+	if !ok {
+		return nil
+	}
+	zoneID, err := o.zoneID(ctx, parent)
+	if err != nil {
+		return err
+	}
+	records, err := o.dns.ListFullRecords(ctx, parent.Endpoint, zoneID)
+	if err != nil {
+		return err
+	}
+	label := strings.TrimSuffix(child, "."+parentName)
+	for _, r := range records {
+		if (strings.EqualFold(r.Name, label) && r.Type == "NS") || (strings.EqualFold(r.Name, "ns."+label) && r.Type == "A") {
+			if err := o.dns.DeleteRecord(ctx, parent.Endpoint, zoneID, r.ID); err != nil {
+				return err
+			}
+		}
+	}
+	o.log.Infow("delegation removed", "zone", child, "parent", parentName)
+	return nil
+}
+
+// ensureMeshRecord makes name hold a single record of type rtype with data,
+// updating an existing record rather than adding a second one.
+func (o *Operator) ensureMeshRecord(ctx context.Context, zone *Zone, name, rtype, data string) error {
+	zoneID, err := o.zoneID(ctx, zone)
+	if err != nil {
+		return err
+	}
+	records, err := o.dns.ListFullRecords(ctx, zone.Endpoint, zoneID)
+	if err != nil {
+		return err
+	}
+	payload := map[string]interface{}{
+		"name":    name,
+		"ttl":     delegationTTL,
+		"data":    dns.FullRecordData{Type: rtype, Data: data},
+		"enabled": true,
+	}
+	for _, r := range records {
+		if !strings.EqualFold(r.Name, name) || r.Type != rtype {
+			continue
+		}
+		if strings.EqualFold(strings.TrimSuffix(fmt.Sprint(r.Data.Data), "."), strings.TrimSuffix(data, ".")) {
+			return nil
+		}
+		_, err := o.dns.UpdateFullRecord(ctx, zone.Endpoint, zoneID, r.ID, payload)
+		return err
+	}
+	_, err = o.dns.CreateFullRecord(ctx, zone.Endpoint, zoneID, payload)
+	return err
+}
+
+// zoneID returns the MicroDNS ID of a zone, ensuring the zone exists when
+// bootstrap could not reach its instance.
+func (o *Operator) zoneID(ctx context.Context, zone *Zone) (string, error) {
+	if zone.MicroDNSID != "" {
+		return zone.MicroDNSID, nil
+	}
+	id, err := o.dns.EnsureZone(ctx, zone.Endpoint, zone.Name)
+	if err != nil {
+		return "", err
+	}
+	zone.MicroDNSID = id
+	return id, nil
+}
+
+// syncForwarders brings the forward zones of one instance in line with
+// want and deletes those in owned that are no longer wanted. Forwarders the
+// operator did not create are only touched when a wanted zone needs other
+// servers. Returns the zones the operator now owns on the instance.
+func (o *Operator) syncForwarders(ctx context.Context, endpoint string, want []Forwarder, owned []string) ([]string, error) {
+	existing, err := o.dns.ListDNSForwarders(ctx, endpoint)
+	if err != nil {
+		return owned, err
+	}
+	have := make(map[string][]string, len(existing))
+	for _, f := range existing {
+		have[strings.ToLower(f.Zone)] = f.Servers
+	}
+
+	var errs []string
+	var zones []string
+	wanted := make(map[string]bool, len(want))
+	for _, f := range want {
+		wanted[f.Zone] = true
+		zones = append(zones, f.Zone)
+		servers, ok := have[strings.ToLower(f.Zone)]
+		if ok && equalStrings(servers, f.Servers) {
+			continue
+		}
+		if ok {
+			if err := o.dns.DeleteDNSForwarder(ctx, endpoint, f.Zone); err != nil {
+				errs = append(errs, err.Error())
+				continue
+			}
+		}
+		if err := o.dns.EnsureDNSForwarder(ctx, endpoint, dns.DNSForwarder{Zone: f.Zone, Servers: f.Servers}); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	for _, zone := range owned {
+		if wanted[zone] {
+			continue
+		}
+		if err := o.dns.DeleteDNSForwarder(ctx, endpoint, zone); err != nil {
+			errs = append(errs, err.Error())
+			zones = append(zones, zone) // retry next time
+			continue
+		}
+		o.log.Infow("DNS forwarder removed", "zone", zone, "endpoint", endpoint)
+	}
+	if len(errs) > 0 {
+		return zones, errors.New(strings.Join(errs, "; "))
+	}
+	return zones, nil
+}
+
+func containsString(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
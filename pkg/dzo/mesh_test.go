@@ -0,0 +1,246 @@
+package dzo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/glennswest/mkube/pkg/config"
+	"github.com/glennswest/mkube/pkg/dns"
+)
+
+// meshServer is an in-memory MicroDNS with zones, records and forwarders.
+type meshServer struct {
+	*httptest.Server
+
+	mu         sync.Mutex
+	zones      map[string]string // name -> id
+	records    map[string][]dns.FullRecord
+	forwarders map[string][]string // zone -> servers
+	nextID     int
+}
+
+func newMeshServer(t *testing.T) *meshServer {
+	t.Helper()
+	s := &meshServer{
+		zones:      make(map[string]string),
+		records:    make(map[string][]dns.FullRecord),
+		forwarders: make(map[string][]string),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/zones", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		var out []dns.Zone
+		for name, id := range s.zones {
+			out = append(out, dns.Zone{ID: id, Name: name})
+		}
+		_ = json.NewEncoder(w).Encode(out)
+	})
+	mux.HandleFunc("POST /api/v1/zones", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Name string `json:"name"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.nextID++
+		id := fmt.Sprintf("z%d", s.nextID)
+		s.zones[req.Name] = id
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(dns.Zone{ID: id, Name: req.Name})
+	})
+	mux.HandleFunc("GET /api/v1/zones/{id}/records", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		_ = json.NewEncoder(w).Encode(append([]dns.FullRecord{}, s.records[r.PathValue("id")]...))
+	})
+	mux.HandleFunc("POST /api/v1/zones/{id}/records", func(w http.ResponseWriter, r *http.Request) {
+		var rec dns.FullRecord
+		_ = json.NewDecoder(r.Body).Decode(&rec)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.nextID++
+		rec.ID = fmt.Sprintf("r%d", s.nextID)
+		rec.Type = rec.Data.Type
+		id := r.PathValue("id")
+		s.records[id] = append(s.records[id], rec)
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(rec)
+	})
+	mux.HandleFunc("PUT /api/v1/zones/{id}/records/{rid}", func(w http.ResponseWriter, r *http.Request) {
+		var rec dns.FullRecord
+		_ = json.NewDecoder(r.Body).Decode(&rec)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		recs := s.records[r.PathValue("id")]
+		for i := range recs {
+			if recs[i].ID == r.PathValue("rid") {
+				recs[i].Data, recs[i].TTL = rec.Data, rec.TTL
+				_ = json.NewEncoder(w).Encode(recs[i])
+				return
+			}
+		}
+		http.NotFound(w, r)
+	})
+	mux.HandleFunc("DELETE /api/v1/zones/{id}/records/{rid}", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		id := r.PathValue("id")
+		var keep []dns.FullRecord
+		for _, rec := range s.records[id] {
+			if rec.ID != r.PathValue("rid") {
+				keep = append(keep, rec)
+			}
+		}
+		s.records[id] = keep
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("GET /api/v1/dns/forwarders", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		out := []dns.DNSForwarder{}
+		for zone, servers := range s.forwarders {
+			out = append(out, dns.DNSForwarder{Zone: zone, Servers: servers})
+		}
+		_ = json.NewEncoder(w).Encode(out)
+	})
+	mux.HandleFunc("POST /api/v1/dns/forwarders", func(w http.ResponseWriter, r *http.Request) {
+		var fwd dns.DNSForwarder
+		_ = json.NewDecoder(r.Body).Decode(&fwd)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if _, ok := s.forwarders[fwd.Zone]; ok {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		s.forwarders[fwd.Zone] = fwd.Servers
+		w.WriteHeader(http.StatusCreated)
+	})
+	mux.HandleFunc("DELETE /api/v1/dns/forwarders/{zone}", func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.forwarders, r.PathValue("zone"))
+		w.WriteHeader(http.StatusNoContent)
+	})
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	return s
+}
+
+// forwarded renders the forwarders as sorted "zone=servers" strings.
+func (s *meshServer) forwarded() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []string
+	for zone, servers := range s.forwarders {
+		out = append(out, zone+"="+strings.Join(servers, ","))
+	}
+	sort.Strings(out)
+	return strings.Join(out, " ")
+}
+
+// list renders the records of a zone as sorted "name type data" strings.
+func (s *meshServer) list(zone string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []string
+	for _, r := range s.records[s.zones[zone]] {
+		out = append(out, fmt.Sprintf("%s %s %v", r.Name, r.Type, r.Data.Data))
+	}
+	sort.Strings(out)
+	return strings.Join(out, ", ")
+}
+
+func TestReconcileMesh(t *testing.T) {
+	gt, g10, kube := newMeshServer(t), newMeshServer(t), newMeshServer(t)
+	gt.forwarders["corp.example"] = []string{"10.0.0.53:53"} // not the operator's
+	g10.forwarders["gt.lo"] = []string{"192.168.200.250:53"} // stale server
+
+	log := zap.NewNop().Sugar()
+	networks := []config.NetworkDef{
+		{Name: "gt", DNS: config.DNSConfig{Endpoint: gt.URL, Zone: "gt.lo", Server: "192.168.200.199"}},
+		{Name: "g10", DNS: config.DNSConfig{Endpoint: g10.URL, Zone: "g10.lo", Server: "192.168.10.199"}},
+	}
+	cfg := config.DZOConfig{Enabled: true, StatePath: filepath.Join(t.TempDir(), "state.yaml")}
+	op := NewOperator(cfg, networks, dns.NewClient(log), nil, nil, nil, log)
+	ctx := context.Background()
+
+	if err := op.Bootstrap(ctx); err != nil {
+		t.Fatalf("bootstrap: %v", err)
+	}
+	if got, want := gt.forwarded(), "corp.example=10.0.0.53:53 g10.lo=192.168.10.199:53"; got != want {
+		t.Errorf("gt forwarders = %q, want %q", got, want)
+	}
+	if got, want := g10.forwarded(), "gt.lo=192.168.200.199:53"; got != want {
+		t.Errorf("g10 forwarders = %q, want %q", got, want)
+	}
+
+	// A dedicated child zone, as CreateZone leaves it
+	kubeID, _ := op.dns.EnsureZone(ctx, kube.URL, "kube.gt.lo")
+	op.mu.Lock()
+	op.state.Instances["mdns-kube-gt-lo"] = &MicroDNSInstance{
+		Name: "mdns-kube-gt-lo", Network: "gt", IP: "192.168.200.198", Endpoint: kube.URL,
+		Zones: []string{"kube.gt.lo"}, Managed: true,
+	}
+	op.state.Zones["kube.gt.lo"] = &Zone{
+		Name: "kube.gt.lo", Parent: "gt.lo", MicroDNSID: kubeID, Endpoint: kube.URL, Network: "gt", Dedicated: true,
+	}
+	op.mu.Unlock()
+
+	status := op.ReconcileMesh(ctx)
+	if len(status.Errors) > 0 {
+		t.Fatalf("reconcile errors: %v", status.Errors)
+	}
+	if len(status.Delegations) != 1 || status.Delegations[0].IP != "192.168.200.198" {
+		t.Errorf("delegations = %+v", status.Delegations)
+	}
+	if got, want := gt.list("gt.lo"), "kube NS ns.kube.gt.lo., ns.kube A 192.168.200.198"; got != want {
+		t.Errorf("gt.lo records = %q, want %q", got, want)
+	}
+	if got, want := kube.list("kube.gt.lo"), "ns A 192.168.200.198"; got != want {
+		t.Errorf("kube.gt.lo records = %q, want %q", got, want)
+	}
+	if got, want := gt.forwarded(), "corp.example=10.0.0.53:53 g10.lo=192.168.10.199:53 kube.gt.lo=192.168.200.198:53"; got != want {
+		t.Errorf("gt forwarders = %q, want %q", got, want)
+	}
+	if got, want := g10.forwarded(), "gt.lo=192.168.200.199:53 kube.gt.lo=192.168.200.198:53"; got != want {
+		t.Errorf("g10 forwarders = %q, want %q", got, want)
+	}
+	if got, want := kube.forwarded(), "g10.lo=192.168.10.199:53 gt.lo=192.168.200.199:53"; got != want {
+		t.Errorf("kube forwarders = %q, want %q", got, want)
+	}
+
+	// Idempotent
+	op.ReconcileMesh(ctx)
+	if got := gt.list("gt.lo"); got != "kube NS ns.kube.gt.lo., ns.kube A 192.168.200.198" {
+		t.Errorf("gt.lo records after second reconcile = %q", got)
+	}
+
+	// Removing the zone and its instance withdraws delegation and forwarders
+	op.mu.Lock()
+	delete(op.state.Zones, "kube.gt.lo")
+	delete(op.state.Instances, "mdns-kube-gt-lo")
+	op.mu.Unlock()
+	if status := op.ReconcileMesh(ctx); len(status.Errors) > 0 {
+		t.Fatalf("reconcile errors: %v", status.Errors)
+	}
+	if got := gt.list("gt.lo"); got != "" {
+		t.Errorf("gt.lo records after removal = %q, want none", got)
+	}
+	if got, want := gt.forwarded(), "corp.example=10.0.0.53:53 g10.lo=192.168.10.199:53"; got != want {
+		t.Errorf("gt forwarders after removal = %q, want %q", got, want)
+	}
+	if got, want := g10.forwarded(), "gt.lo=192.168.200.199:53"; got != want {
+		t.Errorf("g10 forwarders after removal = %q, want %q", got, want)
+	}
+}
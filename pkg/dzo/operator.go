@@ -31,6 +31,7 @@ type Operator struct {
 
 	mu    sync.RWMutex
 	state *State
+	mesh  MeshStatus // last delegation/forwarder reconcile
 }
 
 // NewOperator creates a DZO operator.
@@ -133,7 +134,10 @@ func (o *Operator) Bootstrap(ctx context.Context) error {
 		o.log.Infow("zone verified", "zone", zoneName, "id", zoneID, "endpoint", zone.Endpoint)
 	}
 
-	// 4. Persist
+	// 4. Delegate child zones and forward every zone to every instance
+	o.reconcileMesh(ctx)
+
+	// 5. Persist
 	if err := o.saveState(); err != nil {
 		o.log.Warnw("failed to save state after bootstrap", "error", err)
 	}
@@ -253,6 +257,7 @@ func (o *Operator) CreateZone(ctx context.Context, req CreateZoneRequest) (*Zone
 	}
 
 	o.state.Zones[req.Name] = zone
+	o.reconcileMesh(ctx)
 	if err := o.saveState(); err != nil {
 		o.log.Warnw("failed to save state after zone create", "error", err)
 	}
@@ -284,6 +289,7 @@ func (o *Operator) DeleteZone(ctx context.Context, name string) error {
 	}
 
 	delete(o.state.Zones, name)
+	o.reconcileMesh(ctx)
 	if err := o.saveState(); err != nil {
 		o.log.Warnw("failed to save state after zone delete", "error", err)
 	}
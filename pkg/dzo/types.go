@@ -27,6 +27,11 @@ type MicroDNSInstance struct {
 type State struct {
 	Zones     map[string]*Zone             `yaml:"zones"`
 	Instances map[string]*MicroDNSInstance `yaml:"instances"`
+
+	// Mesh bookkeeping, so records and forwarders the operator created can
+	// be removed once their zone or instance is gone.
+	Delegations map[string][]string `yaml:"delegations,omitempty"` // parent zone -> delegated child zones
+	Forwarders  map[string][]string `yaml:"forwarders,omitempty"`  // instance endpoint -> forwarded zones
 }
 
 // NewState returns an empty initialized state.
@@ -6,6 +6,7 @@ import (
 	"net"
 	"net/http"
 	"sort"
+	"strconv"
 	"sync"
 	"time"
 
@@ -423,7 +424,7 @@ func (m *Manager) runProbe(ctx context.Context, cfg *ProbeConfig, containerIP st
 
 func (m *Manager) httpProbe(ctx context.Context, ip string, port int, path string, timeout time.Duration) bool {
 	client := &http.Client{Timeout: timeout}
-	url := fmt.Sprintf("http://%s:%d%s", ip, port, path)
+	url := "http://" + net.JoinHostPort(ip, strconv.Itoa(port)) + path
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
@@ -440,7 +441,7 @@ func (m *Manager) httpProbe(ctx context.Context, ip string, port int, path strin
 }
 
 func (m *Manager) tcpProbe(ctx context.Context, ip string, port int, timeout time.Duration) bool {
-	addr := fmt.Sprintf("%s:%d", ip, port)
+	addr := net.JoinHostPort(ip, strconv.Itoa(port))
 	conn, err := net.DialTimeout("tcp", addr, timeout)
 	if err != nil {
 		m.log.Debugw("tcp probe failed", "addr", addr, "timeout", timeout, "error", err)
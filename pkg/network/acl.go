@@ -0,0 +1,222 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/glennswest/mkube/pkg/config"
+)
+
+// aclTag prefixes the comment of every filter rule mkube owns.
+const aclTag = "mkube-acl: "
+
+// ACLRule accepts or drops forwarded traffic from Source to Destination.
+// Accept rules are evaluated before drop rules, so an accept carves an
+// exception out of a broader drop. Rules are stateless: a drop also hits
+// replies to connections opened from the other side.
+type ACLRule struct {
+	Owner       string `json:"owner"`                 // "namespace/name" of the object the rule belongs to
+	Action      string `json:"action"`                // "accept" or "drop"
+	Protocol    string `json:"protocol,omitempty"`    // "tcp", "udp", "icmp"; empty = any
+	Source      string `json:"source,omitempty"`      // address or CIDR; empty = any
+	Destination string `json:"destination,omitempty"` // address or CIDR; empty = any
+	Port        int    `json:"port,omitempty"`        // destination port (tcp/udp); 0 = any
+
+	ID string `json:"-"` // backend rule ID (nftables handles), set by ListACLs
+}
+
+// ACLComment returns the comment that tags owner's filter rules.
+func ACLComment(owner string) string {
+	return aclTag + owner
+}
+
+// ACLOwner extracts the owner from a filter rule comment, or "" when the
+// rule is not mkube's.
+func ACLOwner(comment string) string {
+	owner, ok := strings.CutPrefix(comment, aclTag)
+	if !ok {
+		return ""
+	}
+	return owner
+}
+
+// Validate checks a rule is one drivers can program: a known action and
+// protocol, parseable addresses of a single family, and a port only with
+// tcp or udp.
+func (r ACLRule) Validate() error {
+	if r.Owner == "" {
+		return fmt.Errorf("acl rule has no owner")
+	}
+	switch r.Action {
+	case "accept", "drop":
+	default:
+		return fmt.Errorf("acl action %q must be accept or drop", r.Action)
+	}
+	switch r.Protocol {
+	case "", "tcp", "udp", "icmp":
+	default:
+		return fmt.Errorf("acl protocol %q must be tcp, udp or icmp", r.Protocol)
+	}
+	if r.Port < 0 || r.Port > 65535 {
+		return fmt.Errorf("acl port %d out of range", r.Port)
+	}
+	if r.Port > 0 && r.Protocol != "tcp" && r.Protocol != "udp" {
+		return fmt.Errorf("acl port needs protocol tcp or udp")
+	}
+	v6 := -1
+	for _, a := range []string{r.Source, r.Destination} {
+		if a == "" {
+			continue
+		}
+		ip := net.ParseIP(a)
+		if ip == nil {
+			var err error
+			if ip, _, err = net.ParseCIDR(a); err != nil {
+				return fmt.Errorf("acl address %q is not an IP or CIDR", a)
+			}
+		}
+		fam := 0
+		if ip.To4() == nil {
+			fam = 1
+		}
+		if v6 >= 0 && v6 != fam {
+			return fmt.Errorf("acl source %s and destination %s are different address families", r.Source, r.Destination)
+		}
+		v6 = fam
+	}
+	return nil
+}
+
+// IPv6 reports whether the rule matches IPv6 addresses.
+func (r ACLRule) IPv6() bool {
+	for _, a := range []string{r.Source, r.Destination} {
+		if a != "" {
+			return strings.Contains(a, ":")
+		}
+	}
+	return false
+}
+
+// matches reports whether two rules filter the same traffic the same way,
+// ignoring the backend ID.
+func (r ACLRule) matches(o ACLRule) bool {
+	r.ID, o.ID = "", ""
+	return r == o
+}
+
+// SyncACLs makes the driver's mkube-owned filter rules exactly want: rules
+// of deleted owners or changed content are removed and missing rules are
+// added. Rules without the mkube tag are never touched.
+func (m *Manager) SyncACLs(ctx context.Context, want []ACLRule) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	acl, ok := m.driver.(ACLEnforcer)
+	if !ok || !m.driver.Capabilities().ACLs {
+		if len(want) == 0 {
+			return nil
+		}
+		return fmt.Errorf("driver on %s cannot program ACLs: %w", m.driver.NodeName(), ErrNotSupported)
+	}
+	for _, w := range want {
+		if err := w.Validate(); err != nil {
+			return err
+		}
+	}
+
+	have, err := acl.ListACLs(ctx)
+	if err != nil {
+		return fmt.Errorf("listing ACLs: %w", err)
+	}
+
+	var errs []error
+	kept := make([]bool, len(want))
+	for _, rule := range have {
+		found := false
+		for i, w := range want {
+			if !kept[i] && rule.matches(w) {
+				kept[i], found = true, true
+				break
+			}
+		}
+		if found {
+			continue
+		}
+		if err := acl.RemoveACL(ctx, rule); err != nil {
+			errs = append(errs, fmt.Errorf("removing ACL of %s: %w", rule.Owner, err))
+			continue
+		}
+		m.log.Infow("ACL removed", "owner", rule.Owner, "action", rule.Action, "source", rule.Source, "destination", rule.Destination)
+	}
+
+	add := make([]ACLRule, 0, len(want))
+	for i, w := range want {
+		if !kept[i] {
+			add = append(add, w)
+		}
+	}
+	sort.SliceStable(add, func(i, j int) bool { return add[i].Owner < add[j].Owner })
+	for _, w := range add {
+		if err := acl.AddACL(ctx, w); err != nil {
+			errs = append(errs, fmt.Errorf("adding ACL of %s: %w", w.Owner, err))
+			continue
+		}
+		m.log.Infow("ACL added", "owner", w.Owner, "action", w.Action, "protocol", w.Protocol,
+			"source", w.Source, "destination", w.Destination, "port", w.Port)
+	}
+	return errors.Join(errs...)
+}
+
+// ConfigACLs converts the config file's ACL definitions into rules owned by
+// "config/<name>"; unnamed rules are named by position.
+func ConfigACLs(defs []config.ACLDef) []ACLRule {
+	rules := make([]ACLRule, 0, len(defs))
+	for i, d := range defs {
+		name := d.Name
+		if name == "" {
+			name = fmt.Sprintf("acl-%d", i)
+		}
+		rules = append(rules, ACLRule{
+			Owner:       "config/" + name,
+			Action:      d.Action,
+			Protocol:    d.Protocol,
+			Source:      d.Source,
+			Destination: d.Destination,
+			Port:        d.Port,
+		})
+	}
+	return rules
+}
+
+// ProvisionHost sets up a node whose driver owns the host network (Linux):
+// every network's bridge via EnsureBridges, the DHCP relays of networks
+// served from another network's microdns via SyncDHCPRelays, then the
+// configured ACLs via SyncACLs.
+func (m *Manager) ProvisionHost(ctx context.Context, acls []config.ACLDef) error {
+	if err := m.EnsureBridges(ctx); err != nil {
+		return fmt.Errorf("creating bridges: %w", err)
+	}
+	if err := m.SyncDHCPRelays(ctx); err != nil {
+		return fmt.Errorf("starting DHCP relays: %w", err)
+	}
+	if err := m.SyncACLs(ctx, ConfigACLs(acls)); err != nil {
+		return fmt.Errorf("syncing ACLs: %w", err)
+	}
+	return nil
+}
+
+// ACLs returns the mkube-owned filter rules the driver currently has.
+func (m *Manager) ACLs(ctx context.Context) ([]ACLRule, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	acl, ok := m.driver.(ACLEnforcer)
+	if !ok {
+		return nil, ErrNotSupported
+	}
+	return acl.ListACLs(ctx)
+}
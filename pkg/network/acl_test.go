@@ -0,0 +1,158 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/glennswest/mkube/pkg/config"
+)
+
+// aclDriver is a fakeDriver that also enforces ACLs and records bridges
+// and DHCP relays.
+type aclDriver struct {
+	*fakeDriver
+	rules   []ACLRule
+	nextID  int
+	bridges map[string]BridgeOpts
+	relays  []DHCPRelay
+}
+
+func (d *aclDriver) Capabilities() DriverCapabilities { return DriverCapabilities{ACLs: true} }
+func (d *aclDriver) CreateBridge(_ context.Context, name string, opts BridgeOpts) error {
+	d.bridges[name] = opts
+	return nil
+}
+func (d *aclDriver) SetDHCPRelays(_ context.Context, relays []DHCPRelay) error {
+	d.relays = relays
+	return nil
+}
+func (d *aclDriver) ListACLs(context.Context) ([]ACLRule, error) {
+	return append([]ACLRule(nil), d.rules...), nil
+}
+func (d *aclDriver) AddACL(_ context.Context, r ACLRule) error {
+	d.nextID++
+	r.ID = fmt.Sprint(d.nextID)
+	d.rules = append(d.rules, r)
+	return nil
+}
+func (d *aclDriver) RemoveACL(_ context.Context, r ACLRule) error {
+	for i, have := range d.rules {
+		if have.ID == r.ID {
+			d.rules = append(d.rules[:i], d.rules[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("no rule %s", r.ID)
+}
+
+func TestSyncACLs(t *testing.T) {
+	drv := &aclDriver{fakeDriver: &fakeDriver{created: make(map[string][2]string)}, bridges: make(map[string]BridgeOpts)}
+	mgr, err := NewManager(nil, drv, nil, zap.NewNop().Sugar())
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	ctx := context.Background()
+
+	ssh := ACLRule{Owner: "default/lab", Action: "accept", Protocol: "tcp", Destination: "10.0.2.5", Port: 22}
+	deny := ACLRule{Owner: "default/lab", Action: "drop", Source: "10.0.1.0/24", Destination: "10.0.2.0/24"}
+	drv.rules = []ACLRule{{Owner: "default/old", Action: "drop", Source: "10.0.9.0/24", ID: "90"}}
+	if err := mgr.SyncACLs(ctx, []ACLRule{ssh, deny}); err != nil {
+		t.Fatalf("SyncACLs: %v", err)
+	}
+	if len(drv.rules) != 2 || !drv.rules[0].matches(ssh) || !drv.rules[1].matches(deny) {
+		t.Fatalf("rules after sync = %+v", drv.rules)
+	}
+
+	// Unchanged rules keep their backend IDs
+	ids := []string{drv.rules[0].ID, drv.rules[1].ID}
+	if err := mgr.SyncACLs(ctx, []ACLRule{deny, ssh}); err != nil {
+		t.Fatalf("second SyncACLs: %v", err)
+	}
+	if got := []string{drv.rules[0].ID, drv.rules[1].ID}; !reflect.DeepEqual(got, ids) {
+		t.Errorf("resync replaced rules: IDs %v, want %v", got, ids)
+	}
+
+	for _, bad := range []ACLRule{
+		{Owner: "x", Action: "reject"},
+		{Owner: "x", Action: "drop", Port: 22},
+		{Owner: "x", Action: "drop", Source: "10.0.0.0/8", Destination: "fd00::/64"},
+		{Owner: "x", Action: "drop", Source: "lab"},
+	} {
+		if err := mgr.SyncACLs(ctx, []ACLRule{bad}); err == nil {
+			t.Errorf("SyncACLs accepted %+v", bad)
+		}
+	}
+}
+
+func TestEnsureBridges(t *testing.T) {
+	drv := &aclDriver{fakeDriver: &fakeDriver{created: make(map[string][2]string)}, bridges: make(map[string]BridgeOpts)}
+	mgr, err := NewManager([]config.NetworkDef{
+		{Name: "pods", Bridge: "br0", CIDR: "10.0.1.0/24", Masquerade: true, CIDR6: "fd00:1::/64"},
+		{Name: "lab", Bridge: "br0", CIDR: "10.0.2.0/24", Gateway: "10.0.2.254", VLAN: 20},
+		{Name: "ipmi", Bridge: "br1", CIDR: "10.0.3.0/24"},
+	}, drv, nil, zap.NewNop().Sugar())
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	if err := mgr.EnsureBridges(context.Background()); err != nil {
+		t.Fatalf("EnsureBridges: %v", err)
+	}
+	want := map[string]BridgeOpts{
+		"br0": {
+			Addresses:     []string{"10.0.1.1/24", "fd00:1::1/64"},
+			VLANAddresses: map[int][]string{20: {"10.0.2.254/24"}},
+			Masquerade:    []string{"10.0.1.0/24", "fd00:1::/64"},
+		},
+		"br1": {Addresses: []string{"10.0.3.1/24"}},
+	}
+	if !reflect.DeepEqual(drv.bridges, want) {
+		t.Errorf("bridges = %+v, want %+v", drv.bridges, want)
+	}
+}
+
+func TestProvisionHost(t *testing.T) {
+	drv := &aclDriver{fakeDriver: &fakeDriver{created: make(map[string][2]string)}, bridges: make(map[string]BridgeOpts)}
+	mgr, err := NewManager([]config.NetworkDef{
+		{Name: "pods", Bridge: "br0", CIDR: "10.0.1.0/24", DNS: config.DNSConfig{
+			Server: "10.0.1.2", DHCP: config.DHCPConfig{Enabled: true}}},
+		{Name: "lab", Bridge: "br0", CIDR: "10.0.2.0/24", Gateway: "10.0.2.254", VLAN: 20, DNS: config.DNSConfig{
+			DHCP: config.DHCPConfig{Enabled: true, ServerNetwork: "pods"}}},
+		{Name: "ipmi", Bridge: "br1", CIDR: "10.0.3.0/24", DNS: config.DNSConfig{
+			DHCP: config.DHCPConfig{ServerNetwork: "pods"}}},
+	}, drv, nil, zap.NewNop().Sugar())
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	drv.rules = []ACLRule{{Owner: "config/gone", Action: "drop", Source: "10.0.9.0/24", ID: "90"}}
+	err = mgr.ProvisionHost(context.Background(), []config.ACLDef{
+		{Name: "ssh", Action: "accept", Protocol: "tcp", Destination: "10.0.1.5", Port: 22},
+		{Action: "drop", Source: "10.0.2.0/24", Destination: "10.0.1.0/24"},
+	})
+	if err != nil {
+		t.Fatalf("ProvisionHost: %v", err)
+	}
+	if _, ok := drv.bridges["br0"]; !ok {
+		t.Errorf("bridge br0 not created: %+v", drv.bridges)
+	}
+	// Only lab is served from another network's microdns; pods serves
+	// itself and ipmi has DHCP off.
+	if want := []DHCPRelay{{Interface: "br0.20", Server: "10.0.1.2", Local: "10.0.2.254"}}; !reflect.DeepEqual(drv.relays, want) {
+		t.Errorf("DHCP relays = %+v, want %+v", drv.relays, want)
+	}
+	var owners []string
+	for _, r := range drv.rules {
+		owners = append(owners, r.Owner)
+	}
+	if want := []string{"config/acl-1", "config/ssh"}; !reflect.DeepEqual(owners, want) {
+		t.Errorf("ACL owners = %v, want %v", owners, want)
+	}
+
+	err = mgr.ProvisionHost(context.Background(), []config.ACLDef{{Name: "bad", Action: "reject"}})
+	if err == nil {
+		t.Error("ProvisionHost accepted an invalid ACL")
+	}
+}
@@ -12,10 +12,15 @@ import (
 //	GET /api/v1/networks/{name}   — get switch details + ports
 //	GET /api/v1/networks/{name}/ports — list ports on a switch
 //	GET /api/v1/allocations       — IPAM dump
+//	GET /api/v1/allocations?history=true[&network=][&ip=] — claim/release history
+//	GET /api/v1/ipam              — IPAM utilization per network
+//	GET /api/v1/overlay/tunnels   — overlay tunnels built by this node
 func (m *Manager) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("/api/v1/networks", m.handleNetworks)
 	mux.HandleFunc("/api/v1/networks/", m.handleNetworkDetail)
 	mux.HandleFunc("/api/v1/allocations", m.handleAllocations)
+	mux.HandleFunc("/api/v1/ipam", m.handleIPAMUsage)
+	mux.HandleFunc("/api/v1/overlay/tunnels", m.handleTunnels)
 }
 
 func (m *Manager) handleNetworks(w http.ResponseWriter, r *http.Request) {
@@ -122,9 +127,41 @@ func (m *Manager) handleAllocations(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	q := r.URL.Query()
+	if q.Get("history") == "true" {
+		events, err := m.AllocationHistory(r.Context(), q.Get("network"), q.Get("ip"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		if events == nil {
+			events = []AllocationEvent{}
+		}
+		writeJSON(w, events)
+		return
+	}
+
 	writeJSON(w, m.GetAllocations())
 }
 
+func (m *Manager) handleIPAMUsage(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	writeJSON(w, m.GetIPAMUsage())
+}
+
+func (m *Manager) handleTunnels(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	writeJSON(w, m.Tunnels())
+}
+
 func writeJSON(w http.ResponseWriter, v interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(v)
@@ -0,0 +1,122 @@
+package network
+
+import (
+	"context"
+	"fmt"
+)
+
+// Bandwidth is a pair of rates in bits per second, seen from the pod:
+// Ingress flows toward it, Egress away from it. As a limit, 0 means
+// unlimited.
+type Bandwidth struct {
+	Ingress int64 `json:"ingress,omitempty"`
+	Egress  int64 `json:"egress,omitempty"`
+}
+
+// IsZero reports whether neither direction is set.
+func (b Bandwidth) IsZero() bool {
+	return b.Ingress == 0 && b.Egress == 0
+}
+
+// shapedPort is the shaping applied to a veth.
+type shapedPort struct {
+	ip    string
+	limit Bandwidth
+}
+
+// SetPortBandwidth shapes a veth to limit, matching on its current IPv4
+// address. It is a no-op when the same limit is already applied to the same
+// address, so callers can re-apply on every pass; an address change
+// re-targets the shaping. A zero limit removes it. Veths without an IPv4
+// address cannot be shaped (see PortShapeable).
+func (m *Manager) SetPortBandwidth(ctx context.Context, vethName string, limit Bandwidth) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if limit.IsZero() {
+		return m.clearPortBandwidth(ctx, vethName)
+	}
+	shaper, ok := m.driver.(BandwidthShaper)
+	if !ok {
+		return fmt.Errorf("driver on %s cannot shape bandwidth: %w", m.driver.NodeName(), ErrNotSupported)
+	}
+	alloc, ok := m.allocs[vethName]
+	if !ok || alloc.ip.To4() == nil {
+		return fmt.Errorf("no IPv4 address allocated to %s", vethName)
+	}
+	want := shapedPort{ip: alloc.ip.String(), limit: limit}
+	if have, ok := m.shaped[vethName]; ok && have == want {
+		return nil
+	}
+
+	if err := shaper.SetPortBandwidth(ctx, vethName, want.ip, limit); err != nil {
+		return fmt.Errorf("shaping %s: %w", vethName, err)
+	}
+	if have, ok := m.shaped[vethName]; ok && have.ip != want.ip {
+		m.log.Infow("bandwidth shaping re-targeted", "veth", vethName, "from", have.ip, "to", want.ip)
+	}
+	m.shaped[vethName] = want
+	m.log.Infow("bandwidth shaped", "veth", vethName, "ip", want.ip, "ingress", limit.Ingress, "egress", limit.Egress)
+	return nil
+}
+
+// clearPortBandwidth removes a veth's shaping. Must be called with m.mu held.
+func (m *Manager) clearPortBandwidth(ctx context.Context, vethName string) error {
+	if _, ok := m.shaped[vethName]; !ok {
+		return nil
+	}
+	if shaper, ok := m.driver.(BandwidthShaper); ok {
+		if err := shaper.ClearPortBandwidth(ctx, vethName); err != nil {
+			return fmt.Errorf("removing shaping of %s: %w", vethName, err)
+		}
+	}
+	delete(m.shaped, vethName)
+	delete(m.rates, vethName)
+	m.log.Infow("bandwidth shaping removed", "veth", vethName)
+	return nil
+}
+
+// PortShapeable reports whether a veth has an IPv4 address shaping can
+// match on.
+func (m *Manager) PortShapeable(vethName string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	alloc, ok := m.allocs[vethName]
+	return ok && alloc.ip.To4() != nil
+}
+
+// RefreshPortRates reads the current rates of every shaped veth from the
+// driver, for PortBandwidth to report. Meant to run from a periodic loop so
+// that status reads never wait on the driver.
+func (m *Manager) RefreshPortRates(ctx context.Context) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	shaper, ok := m.driver.(BandwidthShaper)
+	if !ok {
+		return
+	}
+	for veth := range m.shaped {
+		r, err := shaper.PortRates(ctx, veth)
+		if err != nil {
+			m.log.Debugw("failed to read port rates", "veth", veth, "error", err)
+			continue
+		}
+		m.rates[veth] = r
+	}
+}
+
+// PortBandwidth returns a shaped veth's limit and its rates as of the last
+// RefreshPortRates (zero before the first). ok is false when the veth is
+// not shaped.
+func (m *Manager) PortBandwidth(vethName string) (limit, rate Bandwidth, ok bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	sp, ok := m.shaped[vethName]
+	if !ok {
+		return Bandwidth{}, Bandwidth{}, false
+	}
+	return sp.limit, m.rates[vethName], true
+}
@@ -0,0 +1,80 @@
+package network
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/glennswest/mkube/pkg/config"
+)
+
+func TestSetPortBandwidth(t *testing.T) {
+	drv := &fakeDriver{created: make(map[string][2]string), shaping: make(map[string]shapedPort)}
+	mgr, err := NewManager([]config.NetworkDef{{Name: "g10", CIDR: "192.168.10.0/24", Gateway: "192.168.10.1"}},
+		drv, nil, zap.NewNop().Sugar())
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	ctx := context.Background()
+	if _, _, _, err := mgr.AllocateInterface(ctx, "veth_g10_web_0", "web", "g10", ""); err != nil {
+		t.Fatalf("AllocateInterface: %v", err)
+	}
+
+	if err := mgr.SetPortBandwidth(ctx, "veth_g10_nope_0", Bandwidth{Ingress: 1}); err == nil {
+		t.Error("shaping an unallocated veth should fail")
+	}
+
+	limit := Bandwidth{Ingress: 10_000_000, Egress: 1_000_000}
+	for i := 0; i < 2; i++ {
+		if err := mgr.SetPortBandwidth(ctx, "veth_g10_web_0", limit); err != nil {
+			t.Fatalf("SetPortBandwidth: %v", err)
+		}
+	}
+	if drv.sets != 1 || drv.shaping["veth_g10_web_0"] != (shapedPort{ip: "192.168.10.2", limit: limit}) {
+		t.Errorf("driver shaping = %+v after %d calls, want one call", drv.shaping, drv.sets)
+	}
+
+	// An address change re-targets the shaping
+	mgr.allocs["veth_g10_web_0"].ip = net.ParseIP("192.168.10.9")
+	if err := mgr.SetPortBandwidth(ctx, "veth_g10_web_0", limit); err != nil {
+		t.Fatalf("SetPortBandwidth: %v", err)
+	}
+	if drv.sets != 2 || drv.shaping["veth_g10_web_0"].ip != "192.168.10.9" {
+		t.Errorf("shaping not re-targeted: %+v", drv.shaping)
+	}
+
+	// Rates are only read from the driver by RefreshPortRates
+	if _, rate, _ := mgr.PortBandwidth("veth_g10_web_0"); rate.Ingress != 0 {
+		t.Errorf("rates read before RefreshPortRates: %+v", rate)
+	}
+	mgr.RefreshPortRates(ctx)
+	got, rate, ok := mgr.PortBandwidth("veth_g10_web_0")
+	if !ok || got != limit || rate.Ingress != 4000 {
+		t.Errorf("PortBandwidth = %+v, %+v, %v", got, rate, ok)
+	}
+
+	// Shaping matches on IPv4, so an IPv6-only veth is not shapeable
+	if !mgr.PortShapeable("veth_g10_web_0") {
+		t.Error("IPv4 veth not shapeable")
+	}
+	mgr.allocs["veth_g10_web_0"].ip = net.ParseIP("fd00:10::9")
+	if mgr.PortShapeable("veth_g10_web_0") {
+		t.Error("IPv6-only veth reported shapeable")
+	}
+	if err := mgr.SetPortBandwidth(ctx, "veth_g10_web_0", Bandwidth{Ingress: 1}); err == nil {
+		t.Error("shaped an IPv6-only veth")
+	}
+	mgr.allocs["veth_g10_web_0"].ip = net.ParseIP("192.168.10.9")
+
+	if err := mgr.ReleaseInterface(ctx, "veth_g10_web_0"); err != nil {
+		t.Fatalf("ReleaseInterface: %v", err)
+	}
+	if len(drv.shaping) != 0 {
+		t.Errorf("shaping left after release: %+v", drv.shaping)
+	}
+	if _, _, ok := mgr.PortBandwidth("veth_g10_web_0"); ok {
+		t.Error("released veth still reported as shaped")
+	}
+}
@@ -0,0 +1,370 @@
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/glennswest/mkube/pkg/store"
+)
+
+// IP claims record every address handed out in the NATS IPAM bucket under
+// claims.<pool>.<ip> using compare-and-swap writes, so allocators sharing
+// the bucket cannot hand out the same address. A claim stays valid while
+// its node keeps renewing leases.<node>; claims of nodes whose lease
+// expired can be taken over. Releases are written as a new revision rather
+// than a delete, so the bucket's per-key history doubles as the allocation
+// log.
+//
+// Each node runs its own embedded NATS and the IPAM bucket is not synced
+// between peers, so claims only guard this node's allocations. Networks
+// spanning nodes are kept apart by IPAM slices instead (see
+// applyNodeSlice and checkSlice).
+
+const (
+	claimLeaseInterval = 30 * time.Second // node lease renewal period
+	claimLeaseTTL      = 3 * claimLeaseInterval
+	maxClaimAttempts   = 16 // addresses tried before giving up on an allocation
+)
+
+// Claim is the persisted record of an IP allocation.
+type Claim struct {
+	Network    string     `json:"network"`
+	Pool       string     `json:"pool"` // IPAM pool ("g10" or "g10/v6")
+	IP         string     `json:"ip"`
+	Owner      string     `json:"owner"` // veth name
+	Hostname   string     `json:"hostname,omitempty"`
+	Node       string     `json:"node"`
+	ClaimedAt  time.Time  `json:"claimedAt"`
+	ReleasedAt *time.Time `json:"releasedAt,omitempty"`
+	Reason     string     `json:"reason,omitempty"` // why a claim was released
+}
+
+// nodeLease marks a node as alive; its claims expire with it.
+type nodeLease struct {
+	Node      string    `json:"node"`
+	RenewedAt time.Time `json:"renewedAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// AllocationEvent is one entry of the allocation history.
+type AllocationEvent struct {
+	Network  string    `json:"network"`
+	IP       string    `json:"ip"`
+	Event    string    `json:"event"` // claimed, released
+	Owner    string    `json:"owner"`
+	Hostname string    `json:"hostname,omitempty"`
+	Node     string    `json:"node"`
+	Time     time.Time `json:"time"`
+	Reason   string    `json:"reason,omitempty"`
+}
+
+// ClaimConflictError reports an address held by another live claim.
+type ClaimConflictError struct {
+	IP     string
+	Holder Claim
+}
+
+func (e *ClaimConflictError) Error() string {
+	return fmt.Sprintf("IP %s is claimed by %s on node %s since %s",
+		e.IP, e.Holder.Owner, e.Holder.Node, e.Holder.ClaimedAt.Format(time.RFC3339))
+}
+
+// SetStore attaches the NATS store. Existing allocations are claimed
+// immediately; afterwards RunLeaseRenewer keeps the node lease alive.
+func (m *Manager) SetStore(s *store.Store) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if s == nil || s.IPAM == nil {
+		return
+	}
+	m.claims = s.IPAM
+	ctx := context.Background()
+	if err := m.renewLease(ctx); err != nil {
+		m.log.Warnw("failed to write IPAM node lease", "error", err)
+	}
+	m.adoptClaims(ctx)
+}
+
+// RunLeaseRenewer renews this node's IPAM lease and claims any allocation
+// that isn't recorded in NATS yet. It is a no-op until SetStore is called.
+func (m *Manager) RunLeaseRenewer(ctx context.Context) {
+	ticker := time.NewTicker(claimLeaseInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.mu.Lock()
+			if m.claims != nil {
+				if err := m.renewLease(ctx); err != nil {
+					m.log.Warnw("failed to renew IPAM node lease", "error", err)
+				}
+				m.adoptClaims(ctx)
+			}
+			m.mu.Unlock()
+		}
+	}
+}
+
+// ClaimConflicts returns veth -> description for local allocations whose
+// address is claimed by someone else in NATS.
+func (m *Manager) ClaimConflicts() map[string]string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	out := make(map[string]string, len(m.conflicts))
+	for k, v := range m.conflicts {
+		out[k] = v
+	}
+	return out
+}
+
+// AllocationHistory returns claim and release events from the IPAM bucket
+// history, oldest first, optionally filtered by network and IP.
+func (m *Manager) AllocationHistory(ctx context.Context, network, ip string) ([]AllocationEvent, error) {
+	m.mu.Lock()
+	b := m.claims
+	m.mu.Unlock()
+	if b == nil {
+		return nil, fmt.Errorf("allocation history requires the NATS store")
+	}
+
+	var want net.IP
+	if ip != "" {
+		if want = net.ParseIP(ip); want == nil {
+			return nil, fmt.Errorf("invalid IP %q", ip)
+		}
+	}
+
+	keys, err := b.Keys(ctx, "claims.")
+	if err != nil {
+		return nil, err
+	}
+	var events []AllocationEvent
+	for _, key := range keys {
+		revisions, err := b.History(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		for _, rev := range revisions {
+			if rev.Deleted {
+				continue
+			}
+			var c Claim
+			if err := json.Unmarshal(rev.Value, &c); err != nil {
+				continue
+			}
+			if network != "" && c.Network != network {
+				continue
+			}
+			if want != nil && !want.Equal(net.ParseIP(c.IP)) {
+				continue
+			}
+			ev := AllocationEvent{
+				Network:  c.Network,
+				IP:       c.IP,
+				Event:    "claimed",
+				Owner:    c.Owner,
+				Hostname: c.Hostname,
+				Node:     c.Node,
+				Time:     c.ClaimedAt,
+			}
+			if c.ReleasedAt != nil {
+				ev.Event = "released"
+				ev.Time = *c.ReleasedAt
+				ev.Reason = c.Reason
+			}
+			events = append(events, ev)
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Time.Before(events[j].Time) })
+	return events, nil
+}
+
+// claimKey returns the IPAM bucket key for an address in a pool. NATS keys
+// can't hold ':' so IPv6 colons (and IPv4 dots) are rewritten.
+func claimKey(pool string, ip net.IP) string {
+	return "claims." + pool + "." + strings.NewReplacer(".", "-", ":", "_").Replace(ip.String())
+}
+
+// claimAddress claims ip for owner in NATS and, for dynamically chosen
+// addresses, probes the bridge for a host already using it. Must be called
+// with m.mu held.
+func (m *Manager) claimAddress(ctx context.Context, ns *networkState, pool string, ip net.IP, owner, hostname string, probe bool) error {
+	if m.claims != nil {
+		if err := m.claim(ctx, ns.def.Name, pool, ip, owner, hostname); err != nil {
+			return err
+		}
+	}
+	if !probe {
+		return nil
+	}
+	prober, ok := m.driver.(AddressProber)
+	if !ok {
+		return nil
+	}
+	// On a shared VLAN bridge the network's L3 interface is the VLAN interface
+	iface := ns.def.Bridge
+	if ns.def.VLAN > 0 {
+		iface = VLANInterfaceName(ns.def.Bridge, ns.def.VLAN)
+	}
+	inUse, err := prober.ProbeAddress(ctx, iface, ip.String())
+	if err != nil {
+		// A failed probe must not block allocation
+		m.log.Debugw("duplicate-address probe failed", "ip", ip, "interface", iface, "error", err)
+		return nil
+	}
+	if inUse {
+		m.releaseClaim(ctx, pool, ip, owner, "duplicate address detected by probe")
+		return fmt.Errorf("IP %s answered a duplicate-address probe on %s", ip, iface)
+	}
+	return nil
+}
+
+// claim records ip as held by owner on this node. An existing claim by the
+// same owner is kept as-is; one left by a dead node or a vanished local veth
+// is taken over. Must be called with m.mu held and m.claims set.
+func (m *Manager) claim(ctx context.Context, network, pool string, ip net.IP, owner, hostname string) error {
+	key := claimKey(pool, ip)
+	c := Claim{
+		Network:   network,
+		Pool:      pool,
+		IP:        ip.String(),
+		Owner:     owner,
+		Hostname:  hostname,
+		Node:      m.driver.NodeName(),
+		ClaimedAt: time.Now().UTC(),
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+
+	raw, rev, err := m.claims.Get(ctx, key)
+	if store.IsNotFound(err) {
+		if _, err := m.claims.Create(ctx, key, data); err != nil {
+			if store.IsConflict(err) {
+				return fmt.Errorf("IP %s was claimed concurrently by another node", ip)
+			}
+			return fmt.Errorf("claiming %s: %w", ip, err)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("reading claim for %s: %w", ip, err)
+	}
+
+	var cur Claim
+	if err := json.Unmarshal(raw, &cur); err == nil && cur.ReleasedAt == nil {
+		if cur.Owner == owner && cur.Node == c.Node {
+			return nil
+		}
+		if !m.claimStale(ctx, cur) {
+			return &ClaimConflictError{IP: ip.String(), Holder: cur}
+		}
+		m.log.Infow("taking over stale IP claim", "ip", ip, "owner", cur.Owner, "node", cur.Node)
+	}
+
+	if _, err := m.claims.Update(ctx, key, data, rev); err != nil {
+		if store.IsConflict(err) {
+			return fmt.Errorf("IP %s was claimed concurrently by another node", ip)
+		}
+		return fmt.Errorf("claiming %s: %w", ip, err)
+	}
+	return nil
+}
+
+// claimStale reports whether a live-looking claim can be taken over: its
+// local veth (or reservation) no longer exists, or its node's lease has
+// expired.
+func (m *Manager) claimStale(ctx context.Context, c Claim) bool {
+	if c.Node == m.driver.NodeName() {
+		_, exists := m.allocs[c.Owner]
+		_, reserved := m.reservations[c.Owner]
+		return !exists && !reserved
+	}
+	var lease nodeLease
+	if _, err := m.claims.GetJSON(ctx, "leases."+c.Node, &lease); err != nil {
+		return store.IsNotFound(err)
+	}
+	return time.Now().After(lease.ExpiresAt)
+}
+
+// releaseClaim marks owner's claim on ip as released. Must be called with
+// m.mu held.
+func (m *Manager) releaseClaim(ctx context.Context, pool string, ip net.IP, owner, reason string) {
+	if m.claims == nil || ip == nil {
+		return
+	}
+	key := claimKey(pool, ip)
+	raw, rev, err := m.claims.Get(ctx, key)
+	if err != nil {
+		return
+	}
+	var c Claim
+	if err := json.Unmarshal(raw, &c); err != nil || c.ReleasedAt != nil ||
+		c.Owner != owner || c.Node != m.driver.NodeName() {
+		return
+	}
+	now := time.Now().UTC()
+	c.ReleasedAt = &now
+	c.Reason = reason
+	data, _ := json.Marshal(c)
+	if _, err := m.claims.Update(ctx, key, data, rev); err != nil {
+		m.log.Warnw("failed to release IP claim", "ip", ip, "owner", owner, "error", err)
+	}
+}
+
+// renewLease extends this node's lease. Must be called with m.mu held.
+func (m *Manager) renewLease(ctx context.Context) error {
+	now := time.Now().UTC()
+	_, err := m.claims.PutJSON(ctx, "leases."+m.driver.NodeName(), nodeLease{
+		Node:      m.driver.NodeName(),
+		RenewedAt: now,
+		ExpiresAt: now.Add(claimLeaseTTL),
+	})
+	return err
+}
+
+// adoptClaims makes sure every local allocation and reservation is claimed
+// in NATS and records the ones that conflict with another holder. Must be
+// called with m.mu held and m.claims set.
+func (m *Manager) adoptClaims(ctx context.Context) {
+	conflicts := make(map[string]string)
+	owned := make(map[string]*allocation, len(m.allocs)+len(m.reservations))
+	for veth, alloc := range m.allocs {
+		owned[veth] = alloc
+	}
+	for key, res := range m.reservations {
+		owned[key] = res
+	}
+	for veth, alloc := range owned {
+		ns, ok := m.networks[alloc.networkName]
+		if !ok {
+			continue
+		}
+		for _, a := range []struct {
+			pool string
+			ip   net.IP
+		}{{alloc.networkName, alloc.ip}, {pool6(alloc.networkName), alloc.ip6}} {
+			if a.ip == nil {
+				continue
+			}
+			if err := m.claim(ctx, ns.def.Name, a.pool, a.ip, veth, alloc.hostname); err != nil {
+				conflicts[veth] = err.Error()
+				if _, known := m.conflicts[veth]; !known {
+					m.log.Warnw("IP claim conflict", "veth", veth, "ip", a.ip, "error", err)
+				}
+			}
+		}
+	}
+	m.conflicts = conflicts
+}
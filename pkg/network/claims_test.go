@@ -0,0 +1,189 @@
+package network
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"go.uber.org/zap"
+
+	"github.com/glennswest/mkube/pkg/config"
+	"github.com/glennswest/mkube/pkg/store"
+)
+
+func testClaimStore(t *testing.T) *store.Store {
+	t.Helper()
+	ns, err := server.NewServer(&server.Options{
+		Host:      "127.0.0.1",
+		Port:      -1,
+		NoLog:     true,
+		JetStream: true,
+		StoreDir:  t.TempDir(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	go ns.Start()
+	if !ns.ReadyForConnections(5 * time.Second) {
+		t.Fatal("NATS server not ready")
+	}
+	nc, err := nats.Connect(ns.ClientURL())
+	if err != nil {
+		ns.Shutdown()
+		t.Fatal(err)
+	}
+	s, err := store.NewFromConn(context.Background(), nc, 1, zap.NewNop().Sugar())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		s.Close()
+		ns.Shutdown()
+	})
+	return s
+}
+
+func testClaimManager(t *testing.T, node string, s *store.Store) (*Manager, *fakeDriver) {
+	t.Helper()
+	drv := &fakeDriver{node: node, created: make(map[string][2]string), inUse: make(map[string]bool)}
+	mgr, err := NewManager([]config.NetworkDef{{
+		Name:    "g10",
+		CIDR:    "192.168.10.0/24",
+		Gateway: "192.168.10.1",
+	}}, drv, nil, zap.NewNop().Sugar())
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	if s != nil {
+		mgr.SetStore(s)
+	}
+	return mgr, drv
+}
+
+// TestClaimsSharedBucket covers the claim protocol between allocators that
+// share one IPAM bucket. Cluster nodes each have their own; see
+// TestClaimsAcrossNodes.
+func TestClaimsSharedBucket(t *testing.T) {
+	s := testClaimStore(t)
+	ctx := context.Background()
+	a, _ := testClaimManager(t, "node-a", s)
+	b, _ := testClaimManager(t, "node-b", s)
+
+	ipA, _, _, err := a.AllocateInterface(ctx, "veth_g10_web_0", "web", "g10", "")
+	if err != nil || ipA != "192.168.10.2/24" {
+		t.Fatalf("node-a allocate: %s, %v", ipA, err)
+	}
+
+	// node-b's local IPAM also thinks .2 is free, but node-a holds the claim
+	ipB, _, _, err := b.AllocateInterface(ctx, "veth_g10_db_0", "db", "g10", "")
+	if err != nil || ipB != "192.168.10.3/24" {
+		t.Fatalf("node-b allocate: %s, %v (expected .2 to be skipped)", ipB, err)
+	}
+	if _, _, _, err := b.AllocateInterface(ctx, "veth_g10_api_0", "api", "g10", "192.168.10.2"); err == nil {
+		t.Fatal("node-b took a static IP claimed by node-a")
+	}
+
+	if err := a.ReleaseInterface(ctx, "veth_g10_web_0"); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	if _, _, _, err := b.AllocateInterface(ctx, "veth_g10_api_0", "api", "g10", "192.168.10.2"); err != nil {
+		t.Fatalf("node-b static IP after release: %v", err)
+	}
+
+	history, err := b.AllocationHistory(ctx, "g10", "192.168.10.2")
+	if err != nil {
+		t.Fatalf("history: %v", err)
+	}
+	var got []string
+	for _, ev := range history {
+		got = append(got, ev.Event+"/"+ev.Node+"/"+ev.Owner)
+	}
+	want := []string{"claimed/node-a/veth_g10_web_0", "released/node-a/veth_g10_web_0", "claimed/node-b/veth_g10_api_0"}
+	if len(got) != len(want) {
+		t.Fatalf("history = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("history[%d] = %s, want %s", i, got[i], want[i])
+		}
+	}
+}
+
+func TestClaimsAcrossNodes(t *testing.T) {
+	ctx := context.Background()
+	// Every node has its own NATS store; nothing is shared between them
+	a, _ := testClaimManager(t, "node-a", testClaimStore(t))
+	b, _ := testClaimManager(t, "node-b", testClaimStore(t))
+	nodes := []string{"node-a", "node-b"}
+	for _, m := range []*Manager{a, b} {
+		m.mu.Lock()
+		err := m.applyNodeSlice(m.networks["g10"], m.driver.NodeName(), nodes)
+		m.mu.Unlock()
+		if err != nil {
+			t.Fatalf("applyNodeSlice: %v", err)
+		}
+	}
+
+	ipA, _, _, err := a.AllocateInterface(ctx, "veth_g10_web_0", "web", "g10", "")
+	if err != nil {
+		t.Fatalf("node-a allocate: %v", err)
+	}
+	ipB, _, _, err := b.AllocateInterface(ctx, "veth_g10_db_0", "db", "g10", "")
+	if err != nil {
+		t.Fatalf("node-b allocate: %v", err)
+	}
+	if ipA == ipB {
+		t.Fatalf("both nodes allocated %s", ipA)
+	}
+
+	// A static address in the other node's slice is refused on both sides
+	if _, _, _, err := b.AllocateInterface(ctx, "veth_g10_api_0", "api", "g10", strings.Split(ipA, "/")[0]); err == nil {
+		t.Error("node-b took a static IP from node-a's slice")
+	}
+	if _, err := a.ReserveAddress(ctx, "default/vip", "g10", strings.Split(ipB, "/")[0], ""); err == nil {
+		t.Error("node-a reserved an address from node-b's slice")
+	}
+}
+
+func TestClaimTakeoverFromDeadNode(t *testing.T) {
+	s := testClaimStore(t)
+	ctx := context.Background()
+	a, _ := testClaimManager(t, "node-a", s)
+	b, _ := testClaimManager(t, "node-b", s)
+
+	if _, _, _, err := a.AllocateInterface(ctx, "veth_g10_web_0", "web", "g10", ""); err != nil {
+		t.Fatalf("node-a allocate: %v", err)
+	}
+
+	// node-a stops renewing its lease
+	if _, err := s.IPAM.PutJSON(ctx, "leases.node-a", nodeLease{Node: "node-a", ExpiresAt: time.Now().Add(-time.Minute)}); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, _, err := b.AllocateInterface(ctx, "veth_g10_web_0", "web", "g10", "192.168.10.2"); err != nil {
+		t.Fatalf("takeover of expired claim: %v", err)
+	}
+
+	// node-a's copy now conflicts and is reported
+	a.mu.Lock()
+	a.adoptClaims(ctx)
+	a.mu.Unlock()
+	if _, ok := a.ClaimConflicts()["veth_g10_web_0"]; !ok {
+		t.Error("expected node-a to report a claim conflict")
+	}
+}
+
+func TestAllocateSkipsProbedAddress(t *testing.T) {
+	mgr, drv := testClaimManager(t, "node-a", nil)
+	drv.inUse["192.168.10.2"] = true
+
+	ip, _, _, err := mgr.AllocateInterface(context.Background(), "veth_g10_web_0", "web", "g10", "")
+	if err != nil {
+		t.Fatalf("allocate: %v", err)
+	}
+	if ip != "192.168.10.3/24" {
+		t.Errorf("got %s, want the address after the one answering the probe", ip)
+	}
+}
@@ -17,7 +17,9 @@ type NetworkDriver interface {
 	DeleteBridge(ctx context.Context, name string) error
 	ListBridges(ctx context.Context) ([]BridgeInfo, error)
 
-	// Port operations (veth for containers)
+	// Port operations (veth for containers). On dual-stack networks address
+	// and gateway are comma-separated lists, primary family first
+	// ("10.0.0.5/24,fd00::5/64" and "10.0.0.1,fd00::1").
 	CreatePort(ctx context.Context, name, address, gateway string) error
 	DeletePort(ctx context.Context, name string) error
 	AttachPort(ctx context.Context, bridge, port string) error
@@ -37,11 +39,71 @@ type NetworkDriver interface {
 	Capabilities() DriverCapabilities
 }
 
+// AddressProber is implemented by drivers that can check whether an address
+// is already answering on a bridge (ARP or ICMP). The Manager probes each
+// dynamically chosen address before handing it out and skips it if it is
+// in use.
+type AddressProber interface {
+	ProbeAddress(ctx context.Context, bridge, ip string) (inUse bool, err error)
+}
+
+// PortIsolator is implemented by drivers that can stop a bridge forwarding
+// between ports marked isolated (RouterOS bridge horizon, Linux bridge port
+// isolation). Overlay tunnels are isolated from each other so a full mesh
+// bridged into one bridge cannot loop broadcasts.
+type PortIsolator interface {
+	IsolatePort(ctx context.Context, bridge, port string) error
+}
+
+// PortForwarder is implemented by drivers that can program destination NAT
+// (RouterOS /ip/firewall/nat, Linux nftables). Rules are tagged with
+// PortForwardComment so the driver lists and removes only mkube's own.
+type PortForwarder interface {
+	ListPortForwards(ctx context.Context) ([]PortForward, error)
+	AddPortForward(ctx context.Context, pf PortForward) error
+	RemovePortForward(ctx context.Context, pf PortForward) error
+}
+
+// PortNamespacer is implemented by drivers that can put the container end
+// of a port into a network namespace (Linux). SetPortNamespace is called
+// before CreatePort; netns is a namespace path such as /proc/<pid>/ns/net
+// or /var/run/netns/<name>.
+type PortNamespacer interface {
+	SetPortNamespace(port, netns string) error
+}
+
+// ACLEnforcer is implemented by drivers that can filter forwarded traffic
+// (Linux nftables). Rules are tagged with ACLComment so the driver lists
+// and removes only mkube's own.
+type ACLEnforcer interface {
+	ListACLs(ctx context.Context) ([]ACLRule, error)
+	AddACL(ctx context.Context, rule ACLRule) error
+	RemoveACL(ctx context.Context, rule ACLRule) error
+}
+
+// DHCPRelayer is implemented by drivers that relay DHCP themselves (Linux).
+// SetDHCPRelays replaces the driver's relays with relays; an empty set
+// stops relaying.
+type DHCPRelayer interface {
+	SetDHCPRelays(ctx context.Context, relays []DHCPRelay) error
+}
+
+// BandwidthShaper is implemented by drivers that can rate-limit a port
+// (RouterOS simple queues, Linux tc). ip is the port's IPv4 address, which
+// RouterOS queues match on; SetPortBandwidth replaces earlier limits.
+type BandwidthShaper interface {
+	SetPortBandwidth(ctx context.Context, port, ip string, limit Bandwidth) error
+	ClearPortBandwidth(ctx context.Context, port string) error
+	PortRates(ctx context.Context, port string) (Bandwidth, error)
+}
+
 // DriverCapabilities advertises which optional features a driver supports.
 type DriverCapabilities struct {
-	VLANs   bool
-	Tunnels bool
-	ACLs    bool
+	VLANs      bool
+	Tunnels    bool
+	ACLs       bool
+	TunnelType string // TunnelSpec.Type the driver builds overlays with ("eoip", "vxlan", "wireguard")
+	WireGuard  bool   // CreateTunnel accepts Type "wireguard"
 }
 
 // BridgeOpts are options for CreateBridge.
@@ -49,6 +111,25 @@ type BridgeOpts struct {
 	VLAN   int               // default PVID, 0 = none
 	MTU    int               // 0 = driver default
 	Labels map[string]string // arbitrary metadata
+
+	// Gateway addresses in CIDR form ("10.0.0.1/24"). Addresses go on the
+	// bridge itself; VLANAddresses on a <bridge>.<vid> interface per VLAN,
+	// which turns on VLAN filtering.
+	Addresses     []string
+	VLANAddresses map[int][]string
+
+	// Source CIDRs whose traffic leaving through another interface is
+	// masqueraded to the host's address.
+	Masquerade []string
+}
+
+// DHCPRelay forwards the DHCP requests clients broadcast on Interface to
+// the DHCP server at Server, stamping Local (the network's gateway) as the
+// relay address so the server leases from that network's pool.
+type DHCPRelay struct {
+	Interface string // bridge or <bridge>.<vid> the clients are on
+	Server    string
+	Local     string
 }
 
 // BridgeInfo describes a bridge returned by ListBridges.
@@ -71,4 +152,11 @@ type TunnelSpec struct {
 	LocalIP  string
 	RemoteIP string
 	VNI      int // VXLAN network identifier
+
+	// WireGuard: the tunnel is an interface with a single peer at
+	// RemoteIP:ListenPort, routing AllowedIPs through it.
+	PrivateKey    string   // base64 local private key
+	PeerPublicKey string   // base64 public key of the remote end
+	ListenPort    int      // UDP port of both ends
+	AllowedIPs    []string // CIDRs reachable through the peer
 }
@@ -4,11 +4,25 @@ package driver
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netns"
 	"go.uber.org/zap"
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
 
 	nw "github.com/glennswest/mkube/pkg/network"
 )
@@ -18,6 +32,11 @@ import (
 type Linux struct {
 	nodeName string
 	log      *zap.SugaredLogger
+
+	mu      sync.Mutex
+	samples map[string]linkSample    // port -> last byte counters read by PortRates
+	netns   map[string]portNamespace // port -> namespace set by SetPortNamespace
+	relay   *dhcpRelay               // running DHCP relay, nil without relays
 }
 
 // NewLinux returns a NetworkDriver backed by Linux netlink.
@@ -25,28 +44,105 @@ func NewLinux(nodeName string, log *zap.SugaredLogger) *Linux {
 	return &Linux{
 		nodeName: nodeName,
 		log:      log.Named("linux-driver"),
+		samples:  make(map[string]linkSample),
+		netns:    make(map[string]portNamespace),
 	}
 }
 
 // ─── Bridge Operations ───────────────────────────────────────────────────────
 
+// CreateBridge creates the bridge, or adopts an existing one, and brings it
+// up with its gateway addresses. VLAN gateways go on <bridge>.<vid>
+// interfaces with VLAN filtering on. Addresses are only ever added, so a
+// bridge that also carries the host's own address keeps it. IP forwarding
+// is switched on for each gateway family, since the bridge now routes.
 func (d *Linux) CreateBridge(ctx context.Context, name string, opts nw.BridgeOpts) error {
-	br := &netlink.Bridge{
-		LinkAttrs: netlink.LinkAttrs{Name: name},
-	}
-	if opts.MTU > 0 {
-		br.LinkAttrs.MTU = opts.MTU
+	link, err := netlink.LinkByName(name)
+	if err == nil {
+		if _, ok := link.(*netlink.Bridge); !ok {
+			return fmt.Errorf("%s exists and is a %s, not a bridge", name, link.Type())
+		}
+	} else {
+		br := &netlink.Bridge{
+			LinkAttrs: netlink.LinkAttrs{Name: name},
+		}
+		if opts.MTU > 0 {
+			br.LinkAttrs.MTU = opts.MTU
+		}
+		if err := netlink.LinkAdd(br); err != nil {
+			return fmt.Errorf("netlink bridge add %s: %w", name, err)
+		}
+		link = br
 	}
-	if err := netlink.LinkAdd(br); err != nil {
-		return fmt.Errorf("netlink bridge add %s: %w", name, err)
+	if opts.MTU > 0 && link.Attrs().MTU != opts.MTU {
+		if err := netlink.LinkSetMTU(link, opts.MTU); err != nil {
+			return fmt.Errorf("netlink set mtu %s: %w", name, err)
+		}
 	}
-	if err := netlink.LinkSetUp(br); err != nil {
+	if err := netlink.LinkSetUp(link); err != nil {
 		return fmt.Errorf("netlink bridge up %s: %w", name, err)
 	}
-	d.log.Infow("bridge created", "name", name)
+
+	gateways := append([]string(nil), opts.Addresses...)
+	if err := addAddresses(&netlink.Handle{}, link, opts.Addresses); err != nil {
+		return err
+	}
+
+	vids := make([]int, 0, len(opts.VLANAddresses))
+	for vid := range opts.VLANAddresses {
+		vids = append(vids, vid)
+	}
+	sort.Ints(vids)
+	if len(vids) > 0 {
+		if err := netlink.BridgeSetVlanFiltering(link, true); err != nil {
+			return fmt.Errorf("netlink vlan filtering on %s: %w", name, err)
+		}
+	}
+	for _, vid := range vids {
+		if err := d.ensureVLANInterface(link, vid, opts.VLANAddresses[vid]); err != nil {
+			return err
+		}
+		gateways = append(gateways, opts.VLANAddresses[vid]...)
+	}
+
+	for _, fam := range addressFamilies(gateways) {
+		if err := enableForwarding(fam); err != nil {
+			return err
+		}
+	}
+	if err := d.setMasquerade(ctx, name, opts.Masquerade); err != nil {
+		return err
+	}
+	d.log.Infow("bridge ready", "name", name, "addresses", opts.Addresses, "vlans", vids, "masquerade", opts.Masquerade)
 	return nil
 }
 
+// ensureVLANInterface makes the bridge itself a tagged member of vid and
+// puts addrs on a <bridge>.<vid> interface, creating it if needed.
+func (d *Linux) ensureVLANInterface(br netlink.Link, vid int, addrs []string) error {
+	if err := netlink.BridgeVlanAdd(br, uint16(vid), false, false, true, false); err != nil {
+		return fmt.Errorf("netlink bridge self vlan add vid=%d on %s: %w", vid, br.Attrs().Name, err)
+	}
+	name := fmt.Sprintf("%s.%d", br.Attrs().Name, vid)
+	link, err := netlink.LinkByName(name)
+	if err != nil {
+		vlan := &netlink.Vlan{
+			LinkAttrs: netlink.LinkAttrs{Name: name, ParentIndex: br.Attrs().Index},
+			VlanId:    vid,
+		}
+		if err := netlink.LinkAdd(vlan); err != nil {
+			return fmt.Errorf("netlink vlan add %s: %w", name, err)
+		}
+		link = vlan
+	}
+	if err := netlink.LinkSetUp(link); err != nil {
+		return fmt.Errorf("netlink vlan up %s: %w", name, err)
+	}
+	return addAddresses(&netlink.Handle{}, link, addrs)
+}
+
+// DeleteBridge removes the bridge, its VLAN interfaces with it, and its
+// masquerade rules.
 func (d *Linux) DeleteBridge(ctx context.Context, name string) error {
 	link, err := netlink.LinkByName(name)
 	if err != nil {
@@ -55,6 +151,9 @@ func (d *Linux) DeleteBridge(ctx context.Context, name string) error {
 	if err := netlink.LinkDel(link); err != nil {
 		return fmt.Errorf("netlink bridge del %s: %w", name, err)
 	}
+	if err := d.setMasquerade(ctx, name, nil); err != nil {
+		return err
+	}
 	d.log.Infow("bridge deleted", "name", name)
 	return nil
 }
@@ -76,8 +175,80 @@ func (d *Linux) ListBridges(ctx context.Context) ([]nw.BridgeInfo, error) {
 	return out, nil
 }
 
+// addAddresses assigns each CIDR address to link through h; addresses
+// already present are left alone.
+func addAddresses(h *netlink.Handle, link netlink.Link, addrs []string) error {
+	for _, a := range addrs {
+		addr, err := netlink.ParseAddr(strings.TrimSpace(a))
+		if err != nil {
+			return fmt.Errorf("parsing address %s: %w", a, err)
+		}
+		if err := h.AddrReplace(link, addr); err != nil {
+			return fmt.Errorf("netlink addr add %s on %s: %w", a, link.Attrs().Name, err)
+		}
+	}
+	return nil
+}
+
+// addressFamilies returns "ipv4" and/or "ipv6" for the addresses given.
+func addressFamilies(addrs []string) []string {
+	var v4, v6 bool
+	for _, a := range addrs {
+		if strings.Contains(a, ":") {
+			v6 = true
+		} else {
+			v4 = true
+		}
+	}
+	var out []string
+	if v4 {
+		out = append(out, "ipv4")
+	}
+	if v6 {
+		out = append(out, "ipv6")
+	}
+	return out
+}
+
+// enableForwarding turns on IP forwarding for family ("ipv4" or "ipv6") in
+// the current network namespace.
+func enableForwarding(family string) error {
+	path := "/proc/sys/net/ipv4/ip_forward"
+	if family == "ipv6" {
+		path = "/proc/sys/net/ipv6/conf/all/forwarding"
+	}
+	if err := os.WriteFile(path, []byte("1\n"), 0o644); err != nil {
+		return fmt.Errorf("enabling %s forwarding: %w", family, err)
+	}
+	return nil
+}
+
 // ─── Port Operations ─────────────────────────────────────────────────────────
 
+// portNamespace is where the container end of a port lives: the namespace
+// path and, once CreatePort has moved it there, its interface name.
+type portNamespace struct {
+	path   string
+	ifname string
+}
+
+// SetPortNamespace records the network namespace CreatePort moves port's
+// container end into. Ports without one keep both ends on the host with
+// the address on the host end, as before namespaces were supported.
+func (d *Linux) SetPortNamespace(port, netnsPath string) error {
+	if _, err := os.Stat(netnsPath); err != nil {
+		return fmt.Errorf("network namespace for %s: %w", port, err)
+	}
+	d.mu.Lock()
+	d.netns[port] = portNamespace{path: netnsPath}
+	d.mu.Unlock()
+	return nil
+}
+
+// CreatePort creates the veth pair name/name-p. When SetPortNamespace gave
+// the port a namespace, the peer moves into it as the first free ethN and
+// gets the address, loopback and default routes via gateway there; the
+// host end stays unaddressed for AttachPort.
 func (d *Linux) CreatePort(ctx context.Context, name, address, gateway string) error {
 	peerName := name + "-p"
 	veth := &netlink.Veth{
@@ -88,21 +259,27 @@ func (d *Linux) CreatePort(ctx context.Context, name, address, gateway string) e
 		return fmt.Errorf("netlink veth add %s: %w", name, err)
 	}
 
-	// Parse and add address
-	addr, err := netlink.ParseAddr(address)
-	if err != nil {
-		_ = netlink.LinkDel(veth)
-		return fmt.Errorf("parsing address %s: %w", address, err)
-	}
-
 	link, err := netlink.LinkByName(name)
 	if err != nil {
 		return fmt.Errorf("netlink lookup %s after create: %w", name, err)
 	}
 
-	if err := netlink.AddrAdd(link, addr); err != nil {
+	d.mu.Lock()
+	pns, namespaced := d.netns[name]
+	d.mu.Unlock()
+	if namespaced {
+		pns.ifname, err = moveToNamespace(peerName, pns.path, address, gateway)
+		if err == nil {
+			d.mu.Lock()
+			d.netns[name] = pns
+			d.mu.Unlock()
+		}
+	} else {
+		err = addAddresses(&netlink.Handle{}, link, strings.Split(address, ","))
+	}
+	if err != nil {
 		_ = netlink.LinkDel(veth)
-		return fmt.Errorf("netlink addr add %s on %s: %w", address, name, err)
+		return err
 	}
 
 	if err := netlink.LinkSetUp(link); err != nil {
@@ -110,18 +287,88 @@ func (d *Linux) CreatePort(ctx context.Context, name, address, gateway string) e
 		return fmt.Errorf("netlink link up %s: %w", name, err)
 	}
 
-	d.log.Infow("port created", "name", name, "address", address)
+	d.log.Infow("port created", "name", name, "address", address, "netns", pns.path, "ifname", pns.ifname)
 	return nil
 }
 
+// moveToNamespace moves peer into the namespace at nsPath, names it the
+// first free ethN and configures it there. It returns the new name.
+func moveToNamespace(peer, nsPath, address, gateway string) (string, error) {
+	ns, err := netns.GetFromPath(nsPath)
+	if err != nil {
+		return "", fmt.Errorf("opening network namespace %s: %w", nsPath, err)
+	}
+	defer ns.Close()
+
+	link, err := netlink.LinkByName(peer)
+	if err != nil {
+		return "", fmt.Errorf("netlink lookup %s: %w", peer, err)
+	}
+	if err := netlink.LinkSetNsFd(link, int(ns)); err != nil {
+		return "", fmt.Errorf("moving %s into %s: %w", peer, nsPath, err)
+	}
+
+	h, err := netlink.NewHandleAt(ns)
+	if err != nil {
+		return "", fmt.Errorf("netlink handle in %s: %w", nsPath, err)
+	}
+	defer h.Close()
+
+	if link, err = h.LinkByName(peer); err != nil {
+		return "", fmt.Errorf("netlink lookup %s in %s: %w", peer, nsPath, err)
+	}
+	ifname := ""
+	for i := 0; ifname == ""; i++ {
+		if _, err := h.LinkByName(fmt.Sprintf("eth%d", i)); err != nil {
+			ifname = fmt.Sprintf("eth%d", i)
+		}
+	}
+	if err := h.LinkSetName(link, ifname); err != nil {
+		return "", fmt.Errorf("renaming %s to %s: %w", peer, ifname, err)
+	}
+	if err := addAddresses(h, link, strings.Split(address, ",")); err != nil {
+		return "", err
+	}
+	if err := h.LinkSetUp(link); err != nil {
+		return "", fmt.Errorf("netlink link up %s in %s: %w", ifname, nsPath, err)
+	}
+	if lo, err := h.LinkByName("lo"); err == nil {
+		_ = h.LinkSetUp(lo)
+	}
+	for _, g := range strings.Split(gateway, ",") {
+		gw := net.ParseIP(strings.TrimSpace(g))
+		if gw == nil {
+			continue
+		}
+		if err := h.RouteReplace(&netlink.Route{LinkIndex: link.Attrs().Index, Gw: gw}); err != nil {
+			return "", fmt.Errorf("default route via %s in %s: %w", gw, nsPath, err)
+		}
+	}
+	return ifname, nil
+}
+
+// DeletePort removes the veth pair. A namespaced port whose pair is already
+// gone went away with its namespace, which is not an error.
 func (d *Linux) DeletePort(ctx context.Context, name string) error {
+	d.mu.Lock()
+	_, namespaced := d.netns[name]
+	d.mu.Unlock()
+
 	link, err := netlink.LinkByName(name)
-	if err != nil {
+	var notFound netlink.LinkNotFoundError
+	switch {
+	case err != nil && namespaced && errors.As(err, &notFound):
+	case err != nil:
 		return fmt.Errorf("netlink lookup %s: %w", name, err)
+	default:
+		if err := netlink.LinkDel(link); err != nil {
+			return fmt.Errorf("netlink del %s: %w", name, err)
+		}
 	}
-	if err := netlink.LinkDel(link); err != nil {
-		return fmt.Errorf("netlink del %s: %w", name, err)
-	}
+
+	d.mu.Lock()
+	delete(d.netns, name)
+	d.mu.Unlock()
 	d.log.Infow("port deleted", "name", name)
 	return nil
 }
@@ -164,10 +411,14 @@ func (d *Linux) ListPorts(ctx context.Context) ([]nw.PortInfo, error) {
 		if _, ok := l.(*netlink.Veth); ok {
 			pi := nw.PortInfo{Name: l.Attrs().Name}
 
-			// Get address if assigned
-			addrs, err := netlink.AddrList(l, netlink.FAMILY_V4)
-			if err == nil && len(addrs) > 0 {
-				pi.Address = addrs[0].IPNet.String()
+			// Namespaced ports carry their address on the container end
+			d.mu.Lock()
+			pns, namespaced := d.netns[pi.Name]
+			d.mu.Unlock()
+			if namespaced {
+				pi.Address = namespaceAddresses(pns)
+			} else {
+				pi.Address = linkAddresses(&netlink.Handle{}, l)
 			}
 
 			// Get bridge master
@@ -184,6 +435,139 @@ func (d *Linux) ListPorts(ctx context.Context) ([]nw.PortInfo, error) {
 	return out, nil
 }
 
+// linkAddresses lists link's addresses: IPv4 first, then a global IPv6
+// address on dual-stack ports (link-local is always present).
+func linkAddresses(h *netlink.Handle, l netlink.Link) string {
+	var assigned []string
+	if addrs, err := h.AddrList(l, netlink.FAMILY_V4); err == nil && len(addrs) > 0 {
+		assigned = append(assigned, addrs[0].IPNet.String())
+	}
+	if addrs, err := h.AddrList(l, netlink.FAMILY_V6); err == nil {
+		for _, a := range addrs {
+			if a.IP.IsGlobalUnicast() {
+				assigned = append(assigned, a.IPNet.String())
+				break
+			}
+		}
+	}
+	return strings.Join(assigned, ",")
+}
+
+// namespaceAddresses lists the addresses of a port's container end, or ""
+// when its namespace is gone.
+func namespaceAddresses(pns portNamespace) string {
+	if pns.ifname == "" {
+		return ""
+	}
+	ns, err := netns.GetFromPath(pns.path)
+	if err != nil {
+		return ""
+	}
+	defer ns.Close()
+	h, err := netlink.NewHandleAt(ns)
+	if err != nil {
+		return ""
+	}
+	defer h.Close()
+	l, err := h.LinkByName(pns.ifname)
+	if err != nil {
+		return ""
+	}
+	return linkAddresses(h, l)
+}
+
+// ProbeAddress reports whether ip already answers on the bridge: an ICMP
+// echo reply, or a live neighbor entry (hosts that drop ICMP still answer
+// ARP/NDP, which the echo attempt triggers).
+func (d *Linux) ProbeAddress(ctx context.Context, bridge, ip string) (bool, error) {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return false, fmt.Errorf("invalid IP %q", ip)
+	}
+
+	replied, err := icmpEcho(ctx, addr, probeTimeout)
+	if err != nil {
+		return false, err
+	}
+	if replied {
+		return true, nil
+	}
+
+	br, err := netlink.LinkByName(bridge)
+	if err != nil {
+		return false, fmt.Errorf("netlink lookup bridge %s: %w", bridge, err)
+	}
+	family := netlink.FAMILY_V4
+	if addr.To4() == nil {
+		family = netlink.FAMILY_V6
+	}
+	neighs, err := netlink.NeighList(br.Attrs().Index, family)
+	if err != nil {
+		return false, fmt.Errorf("netlink neigh list %s: %w", bridge, err)
+	}
+	const live = netlink.NUD_REACHABLE | netlink.NUD_STALE | netlink.NUD_DELAY | netlink.NUD_PROBE | netlink.NUD_PERMANENT
+	for _, n := range neighs {
+		if n.IP.Equal(addr) && n.State&live != 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// probeTimeout bounds how long a duplicate-address probe waits for a reply.
+const probeTimeout = 500 * time.Millisecond
+
+// icmpEcho sends one ICMP (or ICMPv6) echo request and waits for a reply
+// from addr. Requires CAP_NET_RAW.
+func icmpEcho(ctx context.Context, addr net.IP, timeout time.Duration) (bool, error) {
+	network, proto := "ip4:icmp", 1
+	var typ icmp.Type = ipv4.ICMPTypeEcho
+	if addr.To4() == nil {
+		network, proto, typ = "ip6:ipv6-icmp", 58, ipv6.ICMPTypeEchoRequest
+	}
+
+	conn, err := icmp.ListenPacket(network, "")
+	if err != nil {
+		return false, fmt.Errorf("opening ICMP socket: %w", err)
+	}
+	defer conn.Close()
+
+	id := os.Getpid() & 0xffff
+	msg := icmp.Message{Type: typ, Body: &icmp.Echo{ID: id, Seq: 1, Data: []byte("mkube-dad")}}
+	wb, err := msg.Marshal(nil)
+	if err != nil {
+		return false, err
+	}
+	if _, err := conn.WriteTo(wb, &net.IPAddr{IP: addr}); err != nil {
+		return false, fmt.Errorf("sending ICMP echo to %s: %w", addr, err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	_ = conn.SetReadDeadline(deadline)
+
+	rb := make([]byte, 1500)
+	for {
+		n, peer, err := conn.ReadFrom(rb)
+		if err != nil {
+			return false, nil // timeout: no reply
+		}
+		if pa, ok := peer.(*net.IPAddr); !ok || !pa.IP.Equal(addr) {
+			continue
+		}
+		reply, err := icmp.ParseMessage(proto, rb[:n])
+		if err != nil {
+			continue
+		}
+		if echo, ok := reply.Body.(*icmp.Echo); ok && echo.ID == id &&
+			(reply.Type == ipv4.ICMPTypeEchoReply || reply.Type == ipv6.ICMPTypeEchoReply) {
+			return true, nil
+		}
+	}
+}
+
 // ─── VLAN Operations ─────────────────────────────────────────────────────────
 
 func (d *Linux) SetPortVLAN(ctx context.Context, port string, vid int, tagged bool) error {
@@ -218,11 +602,67 @@ func (d *Linux) CreateTunnel(ctx context.Context, name string, spec nw.TunnelSpe
 	switch spec.Type {
 	case "vxlan":
 		return d.createVXLAN(name, spec)
+	case "wireguard":
+		return d.createWireGuard(ctx, name, spec)
 	default:
 		return fmt.Errorf("tunnel type %q not supported", spec.Type)
 	}
 }
 
+// createWireGuard creates a WireGuard link with a single peer and routes
+// the allowed CIDRs through it. netlink only creates the device; keys and
+// the peer are set with wg(8), the private key passed on stdin so it never
+// shows up in the process list. A failure removes the link again.
+func (d *Linux) createWireGuard(ctx context.Context, name string, spec nw.TunnelSpec) error {
+	if spec.PrivateKey == "" || spec.PeerPublicKey == "" {
+		return fmt.Errorf("wireguard tunnel %s needs a private key and a peer public key", name)
+	}
+	link := &netlink.Wireguard{LinkAttrs: netlink.LinkAttrs{Name: name}}
+	if err := netlink.LinkAdd(link); err != nil {
+		return fmt.Errorf("netlink wireguard add %s: %w", name, err)
+	}
+	if err := d.configureWireGuard(ctx, name, spec); err != nil {
+		_ = netlink.LinkDel(link)
+		return err
+	}
+	d.log.Infow("WireGuard tunnel created", "name", name, "peer", spec.RemoteIP, "port", spec.ListenPort,
+		"allowedIPs", spec.AllowedIPs)
+	return nil
+}
+
+func (d *Linux) configureWireGuard(ctx context.Context, name string, spec nw.TunnelSpec) error {
+	port := strconv.Itoa(spec.ListenPort)
+	args := []string{"set", name, "listen-port", port, "private-key", "/dev/stdin",
+		"peer", spec.PeerPublicKey, "endpoint", net.JoinHostPort(spec.RemoteIP, port),
+		"persistent-keepalive", "25"}
+	if len(spec.AllowedIPs) > 0 {
+		args = append(args, "allowed-ips", strings.Join(spec.AllowedIPs, ","))
+	}
+	cmd := exec.CommandContext(ctx, "wg", args...)
+	cmd.Stdin = strings.NewReader(spec.PrivateKey + "\n")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("wg set %s: %w: %s", name, err, strings.TrimSpace(string(out)))
+	}
+
+	link, err := netlink.LinkByName(name)
+	if err != nil {
+		return fmt.Errorf("netlink lookup %s: %w", name, err)
+	}
+	if err := netlink.LinkSetUp(link); err != nil {
+		return fmt.Errorf("netlink wireguard up %s: %w", name, err)
+	}
+	for _, cidr := range spec.AllowedIPs {
+		_, dst, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return fmt.Errorf("invalid allowed IP %q: %w", cidr, err)
+		}
+		if err := netlink.RouteReplace(&netlink.Route{LinkIndex: link.Attrs().Index, Dst: dst}); err != nil {
+			return fmt.Errorf("netlink route %s via %s: %w", cidr, name, err)
+		}
+	}
+	return nil
+}
+
 func (d *Linux) createVXLAN(name string, spec nw.TunnelSpec) error {
 	localIP := net.ParseIP(spec.LocalIP)
 	remoteIP := net.ParseIP(spec.RemoteIP)
@@ -259,6 +699,756 @@ func (d *Linux) DeleteTunnel(ctx context.Context, name string) error {
 	return nil
 }
 
+// IsolatePort marks port isolated on its bridge; the kernel does not
+// forward between two isolated ports.
+func (d *Linux) IsolatePort(ctx context.Context, bridge, port string) error {
+	link, err := netlink.LinkByName(port)
+	if err != nil {
+		return fmt.Errorf("netlink lookup %s: %w", port, err)
+	}
+	if err := netlink.LinkSetIsolated(link, true); err != nil {
+		return fmt.Errorf("netlink set isolated %s: %w", port, err)
+	}
+	d.log.Infow("port isolated", "bridge", bridge, "port", port)
+	return nil
+}
+
+// ─── Port Forward Operations ─────────────────────────────────────────────────
+
+// Port forwards live in their own nftables table so mkube never touches
+// rules it did not write; the chain is created on first use.
+const (
+	nftTable        = "mkube"
+	nftForwardChain = "portforward"
+)
+
+// nft runs nft(8) with args, feeding stdin if non-empty, and returns stdout.
+func (d *Linux) nft(ctx context.Context, stdin string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "nft", args...)
+	if stdin != "" {
+		cmd.Stdin = strings.NewReader(stdin)
+	}
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("nft %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+func (d *Linux) ensureForwardChain(ctx context.Context) error {
+	_, err := d.nft(ctx, fmt.Sprintf(`table ip %s {
+	chain %s {
+		type nat hook prerouting priority dstnat; policy accept;
+	}
+}
+`, nftTable, nftForwardChain), "-f", "-")
+	return err
+}
+
+// ListPortForwards returns the dnat rules in mkube's portforward chain.
+func (d *Linux) ListPortForwards(ctx context.Context) ([]nw.PortForward, error) {
+	if err := d.ensureForwardChain(ctx); err != nil {
+		return nil, err
+	}
+	out, err := d.nft(ctx, "", "-j", "-a", "list", "chain", "ip", nftTable, nftForwardChain)
+	if err != nil {
+		return nil, err
+	}
+	return parseNftPortForwards(out)
+}
+
+func (d *Linux) AddPortForward(ctx context.Context, pf nw.PortForward) error {
+	if err := d.ensureForwardChain(ctx); err != nil {
+		return err
+	}
+	args := []string{"add", "rule", "ip", nftTable, nftForwardChain}
+	if pf.Interface != "" {
+		args = append(args, "iifname", strconv.Quote(pf.Interface))
+	}
+	if pf.ExternalIP != "" {
+		args = append(args, "ip", "daddr", pf.ExternalIP)
+	}
+	args = append(args, pf.Protocol, "dport", strconv.Itoa(pf.ExternalPort),
+		"dnat", "to", net.JoinHostPort(pf.TargetIP, strconv.Itoa(pf.TargetPort)),
+		"comment", strconv.Quote(nw.PortForwardComment(pf.Owner)))
+	_, err := d.nft(ctx, "", args...)
+	return err
+}
+
+func (d *Linux) RemovePortForward(ctx context.Context, pf nw.PortForward) error {
+	_, err := d.nft(ctx, "", "delete", "rule", "ip", nftTable, nftForwardChain, "handle", pf.ID)
+	return err
+}
+
+// parseNftPortForwards decodes `nft -j -a list chain` output, keeping the
+// rules tagged as mkube's. Only the rule shape AddPortForward writes is
+// understood; anything else in the chain is skipped.
+func parseNftPortForwards(data []byte) ([]nw.PortForward, error) {
+	rules, err := nftRules(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []nw.PortForward
+	for _, r := range rules {
+		owner := nw.PortForwardOwner(r.Comment)
+		if owner == "" {
+			continue
+		}
+		pf := nw.PortForward{Owner: owner, ID: strconv.Itoa(r.Handle)}
+		for _, raw := range r.Expr {
+			var e struct {
+				Match *struct {
+					Left struct {
+						Meta    *struct{ Key string } `json:"meta"`
+						Payload *struct {
+							Protocol string `json:"protocol"`
+							Field    string `json:"field"`
+						} `json:"payload"`
+					} `json:"left"`
+					Right json.RawMessage `json:"right"`
+				} `json:"match"`
+				DNAT *struct {
+					Addr string `json:"addr"`
+					Port int    `json:"port"`
+				} `json:"dnat"`
+			}
+			if err := json.Unmarshal(raw, &e); err != nil {
+				continue
+			}
+			switch {
+			case e.DNAT != nil:
+				pf.TargetIP, pf.TargetPort = e.DNAT.Addr, e.DNAT.Port
+			case e.Match == nil:
+			case e.Match.Left.Meta != nil && e.Match.Left.Meta.Key == "iifname":
+				_ = json.Unmarshal(e.Match.Right, &pf.Interface)
+			case e.Match.Left.Payload == nil:
+			case e.Match.Left.Payload.Field == "daddr":
+				_ = json.Unmarshal(e.Match.Right, &pf.ExternalIP)
+			case e.Match.Left.Payload.Field == "dport":
+				pf.Protocol = e.Match.Left.Payload.Protocol
+				_ = json.Unmarshal(e.Match.Right, &pf.ExternalPort)
+			}
+		}
+		out = append(out, pf)
+	}
+	return out, nil
+}
+
+// ─── ACL and NAT Operations ──────────────────────────────────────────────────
+
+// ACLs filter routed traffic in an inet-family forward chain and traffic
+// bridged between two ports of one bridge, which never reaches the inet
+// forward hook, in a bridge-family one. Each rule is written to both
+// chains in one transaction. Masquerade rules live in the inet table too.
+const (
+	nftACLChain        = "acl"
+	nftMasqueradeChain = "masquerade"
+	natTag             = "mkube-nat: "
+)
+
+func (d *Linux) ensureFilterChains(ctx context.Context) error {
+	_, err := d.nft(ctx, fmt.Sprintf(`table inet %[1]s {
+	chain %[2]s {
+		type filter hook forward priority filter; policy accept;
+	}
+	chain %[3]s {
+		type nat hook postrouting priority srcnat; policy accept;
+	}
+}
+table bridge %[1]s {
+	chain %[2]s {
+		type filter hook forward priority filter; policy accept;
+	}
+}
+`, nftTable, nftACLChain, nftMasqueradeChain), "-f", "-")
+	return err
+}
+
+// nftACLExpr renders rule as an nft rule body.
+func nftACLExpr(r nw.ACLRule) string {
+	fam := "ip"
+	if r.IPv6() {
+		fam = "ip6"
+	}
+	var parts []string
+	if r.Source != "" {
+		parts = append(parts, fam, "saddr", r.Source)
+	}
+	if r.Destination != "" {
+		parts = append(parts, fam, "daddr", r.Destination)
+	}
+	switch {
+	case r.Port > 0:
+		parts = append(parts, r.Protocol, "dport", strconv.Itoa(r.Port))
+	case r.Protocol == "icmp" && r.IPv6():
+		parts = append(parts, "meta", "l4proto", "ipv6-icmp")
+	case r.Protocol != "":
+		parts = append(parts, "meta", "l4proto", r.Protocol)
+	}
+	parts = append(parts, r.Action, "comment", strconv.Quote(nw.ACLComment(r.Owner)))
+	return strings.Join(parts, " ")
+}
+
+// ListACLs returns mkube's filter rules. A rule's ID pairs its inet and
+// bridge handles as "inet/bridge".
+func (d *Linux) ListACLs(ctx context.Context) ([]nw.ACLRule, error) {
+	if err := d.ensureFilterChains(ctx); err != nil {
+		return nil, err
+	}
+	out, err := d.nft(ctx, "", "-j", "-a", "list", "chain", "inet", nftTable, nftACLChain)
+	if err != nil {
+		return nil, err
+	}
+	routed, err := parseNftACLs(out)
+	if err != nil {
+		return nil, err
+	}
+	if out, err = d.nft(ctx, "", "-j", "-a", "list", "chain", "bridge", nftTable, nftACLChain); err != nil {
+		return nil, err
+	}
+	bridged, err := parseNftACLs(out)
+	if err != nil {
+		return nil, err
+	}
+	return pairACLs(routed, bridged), nil
+}
+
+// pairACLs matches each routed rule with an identical bridged one, joining
+// their handles. Rules present in only one chain keep an empty half.
+func pairACLs(routed, bridged []nw.ACLRule) []nw.ACLRule {
+	used := make([]bool, len(bridged))
+	same := func(a, b nw.ACLRule) bool {
+		a.ID, b.ID = "", ""
+		return a == b
+	}
+	out := make([]nw.ACLRule, 0, len(routed))
+	for _, r := range routed {
+		half := ""
+		for i, b := range bridged {
+			if !used[i] && same(r, b) {
+				used[i], half = true, b.ID
+				break
+			}
+		}
+		r.ID += "/" + half
+		out = append(out, r)
+	}
+	for i, b := range bridged {
+		if !used[i] {
+			b.ID = "/" + b.ID
+			out = append(out, b)
+		}
+	}
+	return out
+}
+
+// AddACL writes rule to both chains: accepts at the head, drops at the
+// tail, so accepts win over drops.
+func (d *Linux) AddACL(ctx context.Context, rule nw.ACLRule) error {
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+	if err := d.ensureFilterChains(ctx); err != nil {
+		return err
+	}
+	verb := "add"
+	if rule.Action == "accept" {
+		verb = "insert"
+	}
+	expr := nftACLExpr(rule)
+	_, err := d.nft(ctx, fmt.Sprintf("%[1]s rule inet %[2]s %[3]s %[4]s\n%[1]s rule bridge %[2]s %[3]s %[4]s\n",
+		verb, nftTable, nftACLChain, expr), "-f", "-")
+	return err
+}
+
+func (d *Linux) RemoveACL(ctx context.Context, rule nw.ACLRule) error {
+	routed, bridged, _ := strings.Cut(rule.ID, "/")
+	var b strings.Builder
+	if routed != "" {
+		fmt.Fprintf(&b, "delete rule inet %s %s handle %s\n", nftTable, nftACLChain, routed)
+	}
+	if bridged != "" {
+		fmt.Fprintf(&b, "delete rule bridge %s %s handle %s\n", nftTable, nftACLChain, bridged)
+	}
+	if b.Len() == 0 {
+		return fmt.Errorf("ACL of %s has no rule handles", rule.Owner)
+	}
+	_, err := d.nft(ctx, b.String(), "-f", "-")
+	return err
+}
+
+// nftRule is a rule in `nft -j -a list chain` output.
+type nftRule struct {
+	Handle  int               `json:"handle"`
+	Comment string            `json:"comment"`
+	Expr    []json.RawMessage `json:"expr"`
+}
+
+// nftRules decodes the rules of `nft -j -a list chain` output.
+func nftRules(data []byte) ([]nftRule, error) {
+	var doc struct {
+		Nftables []struct {
+			Rule *nftRule `json:"rule"`
+		} `json:"nftables"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("decoding nft output: %w", err)
+	}
+	var out []nftRule
+	for _, item := range doc.Nftables {
+		if item.Rule != nil {
+			out = append(out, *item.Rule)
+		}
+	}
+	return out, nil
+}
+
+// parseNftACLs decodes mkube's filter rules from `nft -j -a list chain`
+// output. Only the rule shape AddACL writes is understood.
+func parseNftACLs(data []byte) ([]nw.ACLRule, error) {
+	rules, err := nftRules(data)
+	if err != nil {
+		return nil, err
+	}
+	var out []nw.ACLRule
+	for _, r := range rules {
+		owner := nw.ACLOwner(r.Comment)
+		if owner == "" {
+			continue
+		}
+		acl := nw.ACLRule{Owner: owner, ID: strconv.Itoa(r.Handle)}
+		for _, raw := range r.Expr {
+			var e struct {
+				Match *struct {
+					Left struct {
+						Meta    *struct{ Key string } `json:"meta"`
+						Payload *struct {
+							Protocol string `json:"protocol"`
+							Field    string `json:"field"`
+						} `json:"payload"`
+					} `json:"left"`
+					Right json.RawMessage `json:"right"`
+				} `json:"match"`
+			}
+			// Verdicts are {"accept": null} and {"drop": null}
+			var verdict map[string]json.RawMessage
+			if err := json.Unmarshal(raw, &verdict); err != nil {
+				continue
+			}
+			if err := json.Unmarshal(raw, &e); err != nil {
+				continue
+			}
+			_, accept := verdict["accept"]
+			_, drop := verdict["drop"]
+			switch {
+			case accept:
+				acl.Action = "accept"
+			case drop:
+				acl.Action = "drop"
+			case e.Match == nil:
+			case e.Match.Left.Meta != nil && e.Match.Left.Meta.Key == "l4proto":
+				_ = json.Unmarshal(e.Match.Right, &acl.Protocol)
+				if acl.Protocol == "ipv6-icmp" || acl.Protocol == "icmpv6" {
+					acl.Protocol = "icmp"
+				}
+			case e.Match.Left.Payload == nil:
+			case e.Match.Left.Payload.Field == "saddr":
+				acl.Source = nftAddress(e.Match.Right)
+			case e.Match.Left.Payload.Field == "daddr":
+				acl.Destination = nftAddress(e.Match.Right)
+			case e.Match.Left.Payload.Field == "dport":
+				acl.Protocol = e.Match.Left.Payload.Protocol
+				_ = json.Unmarshal(e.Match.Right, &acl.Port)
+			}
+		}
+		out = append(out, acl)
+	}
+	return out, nil
+}
+
+// nftAddress decodes an address match operand: a plain address, or a
+// {"prefix": {"addr", "len"}} object for a CIDR.
+func nftAddress(raw json.RawMessage) string {
+	var addr string
+	if json.Unmarshal(raw, &addr) == nil {
+		return addr
+	}
+	var p struct {
+		Prefix struct {
+			Addr string `json:"addr"`
+			Len  int    `json:"len"`
+		} `json:"prefix"`
+	}
+	if json.Unmarshal(raw, &p) != nil || p.Prefix.Addr == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s/%d", p.Prefix.Addr, p.Prefix.Len)
+}
+
+// setMasquerade replaces bridge's masquerade rules with one per CIDR:
+// traffic from the CIDR leaving through any other interface takes that
+// interface's address. Clearing is a no-op on hosts without nft.
+func (d *Linux) setMasquerade(ctx context.Context, bridge string, cidrs []string) error {
+	if len(cidrs) == 0 {
+		if _, err := exec.LookPath("nft"); err != nil {
+			return nil
+		}
+	}
+	if err := d.ensureFilterChains(ctx); err != nil {
+		return err
+	}
+	out, err := d.nft(ctx, "", "-j", "-a", "list", "chain", "inet", nftTable, nftMasqueradeChain)
+	if err != nil {
+		return err
+	}
+	rules, err := nftRules(out)
+	if err != nil {
+		return err
+	}
+	var b strings.Builder
+	for _, r := range rules {
+		if r.Comment == natTag+bridge {
+			fmt.Fprintf(&b, "delete rule inet %s %s handle %d\n", nftTable, nftMasqueradeChain, r.Handle)
+		}
+	}
+	for _, c := range cidrs {
+		fmt.Fprintf(&b, "add rule inet %s %s %s\n", nftTable, nftMasqueradeChain, nftMasqueradeExpr(bridge, c))
+	}
+	if b.Len() == 0 {
+		return nil
+	}
+	if _, err := d.nft(ctx, b.String(), "-f", "-"); err != nil {
+		return err
+	}
+	for _, fam := range addressFamilies(cidrs) {
+		if err := enableForwarding(fam); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nftMasqueradeExpr renders the masquerade rule body for cidr on bridge.
+func nftMasqueradeExpr(bridge, cidr string) string {
+	fam := "ip"
+	if strings.Contains(cidr, ":") {
+		fam = "ip6"
+	}
+	return fmt.Sprintf("%s saddr %s oifname != %s masquerade comment %s",
+		fam, cidr, strconv.Quote(bridge), strconv.Quote(natTag+bridge))
+}
+
+// ─── Bandwidth Operations ────────────────────────────────────────────────────
+
+// Shaping happens on the host end of the pod's veth: its egress is the
+// pod's ingress and gets a tbf root qdisc; its ingress is the pod's egress
+// and gets a policing filter, since ingress traffic can only be dropped.
+
+// linkSample is a reading of a port's byte counters.
+type linkSample struct {
+	at      time.Time
+	txBytes uint64
+	rxBytes uint64
+}
+
+// tc runs tc(8) with args.
+func (d *Linux) tc(ctx context.Context, args ...string) error {
+	out, err := exec.CommandContext(ctx, "tc", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("tc %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// tcShapeCommands returns the tc invocations that shape port to limit once
+// its existing qdiscs are removed. A zero direction is left unshaped.
+func tcShapeCommands(port string, limit nw.Bandwidth) [][]string {
+	var cmds [][]string
+	if limit.Ingress > 0 {
+		cmds = append(cmds, []string{"qdisc", "replace", "dev", port, "root", "tbf",
+			"rate", fmt.Sprintf("%dbit", limit.Ingress), "burst", tcBurst(limit.Ingress), "latency", "50ms"})
+	}
+	if limit.Egress > 0 {
+		cmds = append(cmds,
+			[]string{"qdisc", "add", "dev", port, "handle", "ffff:", "ingress"},
+			[]string{"filter", "add", "dev", port, "parent", "ffff:", "protocol", "all", "prio", "1",
+				"matchall", "action", "police", "rate", fmt.Sprintf("%dbit", limit.Egress),
+				"burst", tcBurst(limit.Egress), "conform-exceed", "drop"})
+	}
+	return cmds
+}
+
+// tcBurst sizes a bucket to 100ms of traffic at rate bits/s, and never
+// below two full-size frames.
+func tcBurst(rate int64) string {
+	burst := rate / 80
+	if burst < 3200 {
+		burst = 3200
+	}
+	return strconv.FormatInt(burst, 10)
+}
+
+// SetPortBandwidth replaces port's qdiscs with ones enforcing limit. ip is
+// unused: the veth itself identifies the pod.
+func (d *Linux) SetPortBandwidth(ctx context.Context, port, _ string, limit nw.Bandwidth) error {
+	if err := d.ClearPortBandwidth(ctx, port); err != nil {
+		return err
+	}
+	for _, args := range tcShapeCommands(port, limit) {
+		if err := d.tc(ctx, args...); err != nil {
+			return err
+		}
+	}
+	d.log.Infow("port shaped", "port", port, "ingress", limit.Ingress, "egress", limit.Egress)
+	return nil
+}
+
+// ClearPortBandwidth removes port's root and ingress qdiscs. Missing
+// qdiscs are not an error; a missing port is.
+func (d *Linux) ClearPortBandwidth(ctx context.Context, port string) error {
+	if _, err := netlink.LinkByName(port); err != nil {
+		return fmt.Errorf("finding %s: %w", port, err)
+	}
+	_ = d.tc(ctx, "qdisc", "del", "dev", port, "root")
+	_ = d.tc(ctx, "qdisc", "del", "dev", port, "ingress")
+	d.mu.Lock()
+	delete(d.samples, port)
+	d.mu.Unlock()
+	return nil
+}
+
+// PortRates derives port's rates from its byte counters since the previous
+// call. The first call only takes a sample and reports zero.
+func (d *Linux) PortRates(_ context.Context, port string) (nw.Bandwidth, error) {
+	link, err := netlink.LinkByName(port)
+	if err != nil {
+		return nw.Bandwidth{}, fmt.Errorf("finding %s: %w", port, err)
+	}
+	stats := link.Attrs().Statistics
+	if stats == nil {
+		return nw.Bandwidth{}, fmt.Errorf("no statistics for %s", port)
+	}
+	now := linkSample{at: time.Now(), txBytes: stats.TxBytes, rxBytes: stats.RxBytes}
+
+	d.mu.Lock()
+	prev, ok := d.samples[port]
+	d.samples[port] = now
+	d.mu.Unlock()
+	if !ok {
+		return nw.Bandwidth{}, nil
+	}
+	return sampleRates(prev, now), nil
+}
+
+// sampleRates converts two counter readings of a host-side veth into pod
+// rates in bits/s: what the host transmits is the pod's ingress. Counter
+// resets yield zero.
+func sampleRates(prev, cur linkSample) nw.Bandwidth {
+	secs := cur.at.Sub(prev.at).Seconds()
+	if secs <= 0 {
+		return nw.Bandwidth{}
+	}
+	rate := func(from, to uint64) int64 {
+		if to < from {
+			return 0
+		}
+		return int64(float64(to-from) * 8 / secs)
+	}
+	return nw.Bandwidth{
+		Ingress: rate(prev.txBytes, cur.txBytes),
+		Egress:  rate(prev.rxBytes, cur.rxBytes),
+	}
+}
+
+// ─── DHCP Relay ──────────────────────────────────────────────────────────────
+
+// DHCP message layout (RFC 2131): op, hops, ciaddr and giaddr sit at fixed
+// offsets ahead of the options, which follow a magic cookie at byte 236.
+const (
+	dhcpServerPort = 67
+	dhcpClientPort = 68
+	dhcpMinLen     = 240
+	dhcpMaxHops    = 16
+	dhcpBootReq    = 1
+	dhcpBootReply  = 2
+)
+
+// dhcpRelay is the running relay agent: one UDP socket on the host's port
+// 67 that learns from IP_PKTINFO which interface a request arrived on.
+type dhcpRelay struct {
+	conn *ipv4.PacketConn
+
+	mu      sync.Mutex
+	targets map[int]relayTarget // client interface index -> relay
+}
+
+// relayTarget is a resolved nw.DHCPRelay.
+type relayTarget struct {
+	iface  string
+	index  int
+	server net.IP
+	local  net.IP
+}
+
+// SetDHCPRelays relays DHCPv4 from each relay's interface to its server,
+// replacing earlier relays. The relay socket is opened on the first relay
+// and closed again when relays is empty. Requests are forwarded with giaddr
+// set to the relay's local address; replies the server sends back to that
+// address are broadcast on the client's interface, or unicast to a client
+// that already has an address.
+func (d *Linux) SetDHCPRelays(ctx context.Context, relays []nw.DHCPRelay) error {
+	targets := make(map[int]relayTarget, len(relays))
+	for _, r := range relays {
+		link, err := netlink.LinkByName(r.Interface)
+		if err != nil {
+			return fmt.Errorf("DHCP relay interface %s: %w", r.Interface, err)
+		}
+		server, local := net.ParseIP(r.Server).To4(), net.ParseIP(r.Local).To4()
+		if server == nil || local == nil {
+			return fmt.Errorf("DHCP relay on %s: server %q and local %q must be IPv4 addresses", r.Interface, r.Server, r.Local)
+		}
+		targets[link.Attrs().Index] = relayTarget{iface: r.Interface, index: link.Attrs().Index, server: server, local: local}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(targets) == 0 {
+		if d.relay != nil {
+			d.relay.conn.Close()
+			d.relay = nil
+			d.log.Infow("DHCP relay stopped")
+		}
+		return nil
+	}
+	if d.relay == nil {
+		conn, err := listenDHCPRelay(ctx)
+		if err != nil {
+			return err
+		}
+		d.relay = &dhcpRelay{conn: conn}
+		go d.relay.serve(d.log)
+	}
+	d.relay.mu.Lock()
+	d.relay.targets = targets
+	d.relay.mu.Unlock()
+	for _, t := range targets {
+		d.log.Infow("DHCP relay", "interface", t.iface, "server", t.server, "local", t.local)
+	}
+	return nil
+}
+
+// listenDHCPRelay opens the relay socket on port 67 of every interface,
+// with broadcast allowed and the arrival interface reported per packet.
+func listenDHCPRelay(ctx context.Context) (*ipv4.PacketConn, error) {
+	lc := net.ListenConfig{Control: func(_, _ string, c syscall.RawConn) error {
+		var serr error
+		err := c.Control(func(fd uintptr) {
+			if serr = syscall.SetsockoptInt(int(fd), syscall.SOL_SOCKET, syscall.SO_REUSEADDR, 1); serr == nil {
+				serr = syscall.SetsockoptInt(int(fd), syscall.SOL_SOCKET, syscall.SO_BROADCAST, 1)
+			}
+		})
+		if err != nil {
+			return err
+		}
+		return serr
+	}}
+	pc, err := lc.ListenPacket(ctx, "udp4", ":"+strconv.Itoa(dhcpServerPort))
+	if err != nil {
+		return nil, fmt.Errorf("listening for DHCP on port %d: %w", dhcpServerPort, err)
+	}
+	conn := ipv4.NewPacketConn(pc)
+	if err := conn.SetControlMessage(ipv4.FlagInterface, true); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("enabling IP_PKTINFO on DHCP relay socket: %w", err)
+	}
+	return conn, nil
+}
+
+// serve relays packets until the socket is closed. Requests are only taken
+// from relayed interfaces and replies only from the server of the relay
+// whose address they carry in giaddr.
+func (r *dhcpRelay) serve(log *zap.SugaredLogger) {
+	buf := make([]byte, 1500)
+	for {
+		n, cm, src, err := r.conn.ReadFrom(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			log.Warnw("DHCP relay read failed", "error", err)
+			continue
+		}
+		pkt := buf[:n]
+		if n < dhcpMinLen || cm == nil {
+			continue
+		}
+
+		r.mu.Lock()
+		var t relayTarget
+		found := false
+		switch pkt[0] {
+		case dhcpBootReq:
+			t, found = r.targets[cm.IfIndex]
+		case dhcpBootReply:
+			giaddr := net.IP(pkt[24:28])
+			for _, cand := range r.targets {
+				if cand.local.Equal(giaddr) {
+					t, found = cand, true
+					break
+				}
+			}
+		}
+		r.mu.Unlock()
+		if !found {
+			continue
+		}
+
+		if pkt[0] == dhcpBootReq {
+			if !relayRequest(pkt, t.local) {
+				continue
+			}
+			if _, err := r.conn.WriteTo(pkt, nil, &net.UDPAddr{IP: t.server, Port: dhcpServerPort}); err != nil {
+				log.Warnw("DHCP relay forward failed", "interface", t.iface, "server", t.server, "error", err)
+			}
+			continue
+		}
+		if from, ok := src.(*net.UDPAddr); !ok || !from.IP.Equal(t.server) {
+			continue
+		}
+		out := &ipv4.ControlMessage{IfIndex: t.index, Src: t.local}
+		if _, err := r.conn.WriteTo(pkt, out, relayReplyDest(pkt)); err != nil {
+			log.Warnw("DHCP relay reply failed", "interface", t.iface, "error", err)
+		}
+	}
+}
+
+// relayRequest prepares a client's BOOTREQUEST for the server: it counts
+// the hop and, unless a relay closer to the client already did, sets
+// giaddr to giaddr. It reports false for messages to drop.
+func relayRequest(pkt []byte, giaddr net.IP) bool {
+	if len(pkt) < dhcpMinLen || pkt[0] != dhcpBootReq || pkt[3] >= dhcpMaxHops {
+		return false
+	}
+	pkt[3]++
+	if net.IP(pkt[24:28]).Equal(net.IPv4zero) {
+		copy(pkt[24:28], giaddr.To4())
+	}
+	return true
+}
+
+// relayReplyDest returns where a BOOTREPLY goes on the client's network:
+// the client's own address when it has one (ciaddr), else the broadcast
+// address, as an unconfigured client cannot answer ARP.
+func relayReplyDest(pkt []byte) *net.UDPAddr {
+	if ciaddr := net.IP(pkt[12:16]); !ciaddr.Equal(net.IPv4zero) {
+		return &net.UDPAddr{IP: append(net.IP(nil), ciaddr...), Port: dhcpClientPort}
+	}
+	return &net.UDPAddr{IP: net.IPv4bcast, Port: dhcpClientPort}
+}
+
 // ─── Introspection ───────────────────────────────────────────────────────────
 
 func (d *Linux) NodeName() string {
@@ -267,11 +1457,19 @@ func (d *Linux) NodeName() string {
 
 func (d *Linux) Capabilities() nw.DriverCapabilities {
 	return nw.DriverCapabilities{
-		VLANs:   true,
-		Tunnels: true,
-		ACLs:    false,
+		VLANs:      true,
+		Tunnels:    true,
+		ACLs:       true,
+		TunnelType: "vxlan",
+		WireGuard:  true,
 	}
 }
 
 // Ensure Linux implements NetworkDriver at compile time.
 var _ nw.NetworkDriver = (*Linux)(nil)
+var _ nw.PortForwarder = (*Linux)(nil)
+var _ nw.PortIsolator = (*Linux)(nil)
+var _ nw.BandwidthShaper = (*Linux)(nil)
+var _ nw.PortNamespacer = (*Linux)(nil)
+var _ nw.ACLEnforcer = (*Linux)(nil)
+var _ nw.DHCPRelayer = (*Linux)(nil)
@@ -0,0 +1,342 @@
+//go:build linux
+
+package driver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"runtime"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netns"
+	"go.uber.org/zap"
+
+	"github.com/glennswest/mkube/pkg/network"
+)
+
+// inTestNamespace runs fn on a locked thread inside a fresh network
+// namespace standing in for the host, so bridges and veths never touch the
+// real one. fn gets that namespace's handle. Needs root.
+func inTestNamespace(t *testing.T, fn func(host netns.NsHandle)) {
+	if testing.Short() {
+		t.Skip("skipping network namespace test in short mode")
+	}
+	if os.Geteuid() != 0 {
+		t.Skip("network namespace tests need root")
+	}
+
+	runtime.LockOSThread()
+	orig, err := netns.Get()
+	if err != nil {
+		runtime.UnlockOSThread()
+		t.Fatalf("current namespace: %v", err)
+	}
+	host, err := netns.New()
+	if err != nil {
+		runtime.UnlockOSThread()
+		orig.Close()
+		t.Skipf("cannot create network namespaces: %v", err)
+	}
+	defer func() {
+		// A thread stuck in the test namespace stays locked and dies
+		// with the goroutine.
+		if netns.Set(orig) == nil {
+			runtime.UnlockOSThread()
+		}
+		host.Close()
+		orig.Close()
+	}()
+	fn(host)
+}
+
+// podNamespace creates a named namespace for a pod and returns its path,
+// leaving the thread in host.
+func podNamespace(t *testing.T, host netns.NsHandle, name string) (string, netns.NsHandle) {
+	name = fmt.Sprintf("mkube-test-%d-%s", os.Getpid(), name)
+	ns, err := netns.NewNamed(name)
+	if err != nil {
+		t.Fatalf("creating namespace %s: %v", name, err)
+	}
+	if err := netns.Set(host); err != nil {
+		t.Fatalf("returning to host namespace: %v", err)
+	}
+	t.Cleanup(func() {
+		ns.Close()
+		_ = netns.DeleteNamed(name)
+	})
+	return "/var/run/netns/" + name, ns
+}
+
+// echoFrom pings ip from inside ns.
+func echoFrom(t *testing.T, host, ns netns.NsHandle, ip string) bool {
+	t.Helper()
+	if err := netns.Set(ns); err != nil {
+		t.Fatalf("entering pod namespace: %v", err)
+	}
+	replied, err := icmpEcho(context.Background(), net.ParseIP(ip), probeTimeout)
+	if err := netns.Set(host); err != nil {
+		t.Fatalf("returning to host namespace: %v", err)
+	}
+	if err != nil {
+		t.Fatalf("echo %s: %v", ip, err)
+	}
+	return replied
+}
+
+func TestLinuxNamespacePorts(t *testing.T) {
+	inTestNamespace(t, func(host netns.NsHandle) {
+		ctx := context.Background()
+		d := NewLinux("netns-test", zap.NewNop().Sugar())
+		podPath, pod := podNamespace(t, host, "web")
+
+		opts := network.BridgeOpts{Addresses: []string{"10.77.0.1/24"}}
+		for i := 0; i < 2; i++ { // the second call adopts the bridge
+			if err := d.CreateBridge(ctx, "mk-br", opts); err != nil {
+				t.Fatalf("CreateBridge #%d: %v", i+1, err)
+			}
+		}
+		br, err := netlink.LinkByName("mk-br")
+		if err != nil {
+			t.Fatalf("bridge: %v", err)
+		}
+		if got := linkAddresses(&netlink.Handle{}, br); got != "10.77.0.1/24" {
+			t.Errorf("bridge addresses = %q, want the gateway", got)
+		}
+
+		if err := d.SetPortNamespace("mk-web", podPath); err != nil {
+			t.Fatalf("SetPortNamespace: %v", err)
+		}
+		if err := d.CreatePort(ctx, "mk-web", "10.77.0.5/24", "10.77.0.1"); err != nil {
+			t.Fatalf("CreatePort: %v", err)
+		}
+		if err := d.AttachPort(ctx, "mk-br", "mk-web"); err != nil {
+			t.Fatalf("AttachPort: %v", err)
+		}
+		if _, err := netlink.LinkByName("mk-web-p"); err == nil {
+			t.Error("container end of the veth was left on the host")
+		}
+
+		h, err := netlink.NewHandleAt(pod)
+		if err != nil {
+			t.Fatalf("handle in pod namespace: %v", err)
+		}
+		defer h.Close()
+		eth0, err := h.LinkByName("eth0")
+		if err != nil {
+			t.Fatalf("eth0 in pod namespace: %v", err)
+		}
+		if got := linkAddresses(h, eth0); got != "10.77.0.5/24" {
+			t.Errorf("pod eth0 addresses = %q", got)
+		}
+		routes, _ := h.RouteList(eth0, netlink.FAMILY_V4)
+		var gw string
+		for _, r := range routes {
+			if r.Dst == nil || r.Dst.IP.IsUnspecified() {
+				gw = r.Gw.String()
+			}
+		}
+		if gw != "10.77.0.1" {
+			t.Errorf("pod default route via %q, want 10.77.0.1 (routes %v)", gw, routes)
+		}
+
+		ports, err := d.ListPorts(ctx)
+		if err != nil {
+			t.Fatalf("ListPorts: %v", err)
+		}
+		if len(ports) != 1 || ports[0] != (network.PortInfo{Name: "mk-web", Address: "10.77.0.5/24", Bridge: "mk-br"}) {
+			t.Errorf("ports = %+v", ports)
+		}
+
+		// The pod answers through the bridge, and reaches its gateway
+		if inUse, err := d.ProbeAddress(ctx, "mk-br", "10.77.0.5"); err != nil || !inUse {
+			t.Errorf("ProbeAddress(pod) = %v, %v; want in use", inUse, err)
+		}
+		if !echoFrom(t, host, pod, "10.77.0.1") {
+			t.Error("pod cannot reach its gateway")
+		}
+
+		if err := d.DeletePort(ctx, "mk-web"); err != nil {
+			t.Fatalf("DeletePort: %v", err)
+		}
+		if _, err := h.LinkByName("eth0"); err == nil {
+			t.Error("pod eth0 survived DeletePort")
+		}
+	})
+}
+
+func TestLinuxVLANGateway(t *testing.T) {
+	inTestNamespace(t, func(host netns.NsHandle) {
+		d := NewLinux("netns-test", zap.NewNop().Sugar())
+		err := d.CreateBridge(context.Background(), "mk-br", network.BridgeOpts{
+			VLANAddresses: map[int][]string{30: {"10.78.0.1/24"}},
+		})
+		if errors.Is(err, syscall.EOPNOTSUPP) {
+			t.Skip("kernel has no bridge VLAN filtering")
+		}
+		if err != nil {
+			t.Fatalf("CreateBridge: %v", err)
+		}
+		l, err := netlink.LinkByName("mk-br.30")
+		if err != nil {
+			t.Fatalf("VLAN gateway interface: %v", err)
+		}
+		if got := linkAddresses(&netlink.Handle{}, l); got != "10.78.0.1/24" {
+			t.Errorf("mk-br.30 addresses = %q, want the gateway", got)
+		}
+	})
+}
+
+func TestLinuxACLs(t *testing.T) {
+	if _, err := exec.LookPath("nft"); err != nil {
+		t.Skip("nft not installed")
+	}
+	inTestNamespace(t, func(host netns.NsHandle) {
+		ctx := context.Background()
+		d := NewLinux("netns-test", zap.NewNop().Sugar())
+		if err := d.CreateBridge(ctx, "mk-br", network.BridgeOpts{Addresses: []string{"10.77.0.1/24"}}); err != nil {
+			t.Fatalf("CreateBridge: %v", err)
+		}
+		pods := map[string]netns.NsHandle{}
+		for i, name := range []string{"a", "b"} {
+			path, ns := podNamespace(t, host, name)
+			pods[name] = ns
+			veth := "mk-" + name
+			if err := d.SetPortNamespace(veth, path); err != nil {
+				t.Fatalf("SetPortNamespace: %v", err)
+			}
+			if err := d.CreatePort(ctx, veth, fmt.Sprintf("10.77.0.%d/24", 5+i), "10.77.0.1"); err != nil {
+				t.Fatalf("CreatePort %s: %v", veth, err)
+			}
+			if err := d.AttachPort(ctx, "mk-br", veth); err != nil {
+				t.Fatalf("AttachPort %s: %v", veth, err)
+			}
+		}
+		if !echoFrom(t, host, pods["a"], "10.77.0.6") {
+			t.Fatal("pods cannot reach each other before any ACL")
+		}
+
+		drop := network.ACLRule{Owner: "default/isolate", Action: "drop", Protocol: "icmp",
+			Source: "10.77.0.5", Destination: "10.77.0.6"}
+		if err := d.AddACL(ctx, drop); err != nil {
+			t.Fatalf("AddACL: %v", err)
+		}
+		if echoFrom(t, host, pods["a"], "10.77.0.6") {
+			t.Error("drop ACL did not stop bridged traffic")
+		}
+		rules, err := d.ListACLs(ctx)
+		if err != nil || len(rules) != 1 || rules[0].Owner != drop.Owner {
+			t.Fatalf("ListACLs = %+v, %v", rules, err)
+		}
+		if err := d.RemoveACL(ctx, rules[0]); err != nil {
+			t.Fatalf("RemoveACL: %v", err)
+		}
+		if !echoFrom(t, host, pods["a"], "10.77.0.6") {
+			t.Error("traffic still blocked after RemoveACL")
+		}
+	})
+}
+
+// udpIn opens a broadcast-capable UDP socket on port inside ns.
+func udpIn(t *testing.T, host, ns netns.NsHandle, port int) *net.UDPConn {
+	t.Helper()
+	if err := netns.Set(ns); err != nil {
+		t.Fatalf("entering namespace: %v", err)
+	}
+	lc := net.ListenConfig{Control: func(_, _ string, c syscall.RawConn) error {
+		return c.Control(func(fd uintptr) {
+			_ = syscall.SetsockoptInt(int(fd), syscall.SOL_SOCKET, syscall.SO_BROADCAST, 1)
+		})
+	}}
+	pc, err := lc.ListenPacket(context.Background(), "udp4", fmt.Sprintf(":%d", port))
+	if err := netns.Set(host); err != nil {
+		t.Fatalf("returning to host namespace: %v", err)
+	}
+	if err != nil {
+		t.Fatalf("listening on port %d: %v", port, err)
+	}
+	t.Cleanup(func() { pc.Close() })
+	return pc.(*net.UDPConn)
+}
+
+func TestLinuxDHCPRelay(t *testing.T) {
+	inTestNamespace(t, func(host netns.NsHandle) {
+		ctx := context.Background()
+		d := NewLinux("netns-test", zap.NewNop().Sugar())
+		pods := map[string]netns.NsHandle{}
+		for _, n := range []struct{ bridge, pod, gw, addr string }{
+			{"mk-cl", "client", "10.79.1.1", "10.79.1.5/24"},
+			{"mk-sv", "dhcp", "10.79.2.1", "10.79.2.5/24"},
+		} {
+			if err := d.CreateBridge(ctx, n.bridge, network.BridgeOpts{Addresses: []string{n.gw + "/24"}}); err != nil {
+				t.Fatalf("CreateBridge %s: %v", n.bridge, err)
+			}
+			path, ns := podNamespace(t, host, n.pod)
+			pods[n.pod] = ns
+			veth := "mk-" + n.pod
+			if err := d.SetPortNamespace(veth, path); err != nil {
+				t.Fatalf("SetPortNamespace: %v", err)
+			}
+			if err := d.CreatePort(ctx, veth, n.addr, n.gw); err != nil {
+				t.Fatalf("CreatePort %s: %v", veth, err)
+			}
+			if err := d.AttachPort(ctx, n.bridge, veth); err != nil {
+				t.Fatalf("AttachPort %s: %v", veth, err)
+			}
+		}
+		if err := d.SetDHCPRelays(ctx, []network.DHCPRelay{{Interface: "mk-cl", Server: "10.79.2.5", Local: "10.79.1.1"}}); err != nil {
+			t.Fatalf("SetDHCPRelays: %v", err)
+		}
+		defer d.SetDHCPRelays(ctx, nil)
+
+		// The server answers every relayed request to its giaddr
+		server := udpIn(t, host, pods["dhcp"], dhcpServerPort)
+		relayed := make(chan []byte, 1)
+		go func() {
+			buf := make([]byte, 1500)
+			n, _, err := server.ReadFromUDP(buf)
+			if err != nil {
+				return
+			}
+			req := append([]byte(nil), buf[:n]...)
+			relayed <- req
+			reply := append([]byte(nil), req...)
+			reply[0] = dhcpBootReply
+			copy(reply[16:20], net.ParseIP("10.79.1.50").To4())
+			_, _ = server.WriteToUDP(reply, &net.UDPAddr{IP: net.IP(req[24:28]), Port: dhcpServerPort})
+		}()
+
+		client := udpIn(t, host, pods["client"], dhcpClientPort)
+		discover := make([]byte, dhcpMinLen)
+		discover[0], discover[1], discover[2] = dhcpBootReq, 1, 6
+		copy(discover[4:8], []byte{0xde, 0xad, 0xbe, 0xef})
+		if _, err := client.WriteToUDP(discover, &net.UDPAddr{IP: net.IPv4bcast, Port: dhcpServerPort}); err != nil {
+			t.Fatalf("broadcasting request: %v", err)
+		}
+
+		select {
+		case req := <-relayed:
+			if req[3] != 1 || !net.IP(req[24:28]).Equal(net.ParseIP("10.79.1.1")) {
+				t.Errorf("relayed request hops %d giaddr %v, want 1 and the client network's gateway", req[3], net.IP(req[24:28]))
+			}
+		case <-time.After(3 * time.Second):
+			t.Fatal("request never reached the DHCP server")
+		}
+
+		_ = client.SetReadDeadline(time.Now().Add(3 * time.Second))
+		buf := make([]byte, 1500)
+		n, _, err := client.ReadFromUDP(buf)
+		if err != nil {
+			t.Fatalf("client got no reply: %v", err)
+		}
+		if n < dhcpMinLen || buf[0] != dhcpBootReply || !net.IP(buf[16:20]).Equal(net.ParseIP("10.79.1.50")) || string(buf[4:8]) != "\xde\xad\xbe\xef" {
+			t.Errorf("client got op %d yiaddr %v xid %x", buf[0], net.IP(buf[16:20]), buf[4:8])
+		}
+	})
+}
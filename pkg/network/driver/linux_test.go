@@ -3,7 +3,11 @@
 package driver
 
 import (
+	"net"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/glennswest/mkube/pkg/network"
 	"go.uber.org/zap"
@@ -24,8 +28,8 @@ func TestLinuxCapabilities(t *testing.T) {
 	if !caps.Tunnels {
 		t.Error("Linux driver should support Tunnels")
 	}
-	if caps.ACLs {
-		t.Error("Linux driver should not support ACLs")
+	if !caps.ACLs {
+		t.Error("Linux driver should support ACLs")
 	}
 
 	if d.NodeName() != "linux-test" {
@@ -35,3 +39,156 @@ func TestLinuxCapabilities(t *testing.T) {
 	// Verify it satisfies the interface
 	var _ network.NetworkDriver = d
 }
+
+func TestParseNftPortForwards(t *testing.T) {
+	out := []byte(`{"nftables": [
+		{"metainfo": {"version": "1.0.9", "json_schema_version": 1}},
+		{"chain": {"family": "ip", "table": "mkube", "name": "portforward", "handle": 1}},
+		{"rule": {"family": "ip", "table": "mkube", "chain": "portforward", "handle": 4,
+			"comment": "mkube-pf: default/http",
+			"expr": [
+				{"match": {"op": "==", "left": {"meta": {"key": "iifname"}}, "right": "eth0"}},
+				{"match": {"op": "==", "left": {"payload": {"protocol": "ip", "field": "daddr"}}, "right": "203.0.113.7"}},
+				{"match": {"op": "==", "left": {"payload": {"protocol": "tcp", "field": "dport"}}, "right": 8080}},
+				{"dnat": {"addr": "172.20.0.5", "port": 80}}
+			]}},
+		{"rule": {"family": "ip", "table": "mkube", "chain": "portforward", "handle": 5,
+			"comment": "hand-made",
+			"expr": [{"dnat": {"addr": "10.0.0.1", "port": 22}}]}}
+	]}`)
+
+	got, err := parseNftPortForwards(out)
+	if err != nil {
+		t.Fatalf("parseNftPortForwards: %v", err)
+	}
+	want := network.PortForward{Owner: "default/http", Protocol: "tcp", Interface: "eth0",
+		ExternalIP: "203.0.113.7", ExternalPort: 8080, TargetIP: "172.20.0.5", TargetPort: 80, ID: "4"}
+	if len(got) != 1 || got[0] != want {
+		t.Errorf("parsed %+v, want %+v", got, want)
+	}
+}
+
+func TestNftACLs(t *testing.T) {
+	ssh := network.ACLRule{Owner: "default/lab", Action: "accept", Protocol: "tcp",
+		Source: "10.0.1.0/24", Destination: "10.0.2.5", Port: 22}
+	if got, want := nftACLExpr(ssh), `ip saddr 10.0.1.0/24 ip daddr 10.0.2.5 tcp dport 22 accept comment "mkube-acl: default/lab"`; got != want {
+		t.Errorf("nftACLExpr = %s, want %s", got, want)
+	}
+	ping6 := network.ACLRule{Owner: "default/lab", Action: "drop", Protocol: "icmp", Destination: "fd00::/64"}
+	if got, want := nftACLExpr(ping6), `ip6 daddr fd00::/64 meta l4proto ipv6-icmp drop comment "mkube-acl: default/lab"`; got != want {
+		t.Errorf("nftACLExpr = %s, want %s", got, want)
+	}
+
+	list := func(handle int, rules string) []byte {
+		return []byte(`{"nftables": [
+		{"metainfo": {"version": "1.0.9", "json_schema_version": 1}},
+		{"rule": {"family": "inet", "table": "mkube", "chain": "acl", "handle": ` + strconv.Itoa(handle) + `,
+			"comment": "mkube-acl: default/lab",
+			"expr": [
+				{"match": {"op": "==", "left": {"payload": {"protocol": "ip", "field": "saddr"}}, "right": {"prefix": {"addr": "10.0.1.0", "len": 24}}}},
+				{"match": {"op": "==", "left": {"payload": {"protocol": "ip", "field": "daddr"}}, "right": "10.0.2.5"}},
+				{"match": {"op": "==", "left": {"payload": {"protocol": "tcp", "field": "dport"}}, "right": 22}},
+				{"accept": null}
+			]}}` + rules + `
+	]}`)
+	}
+	routed, err := parseNftACLs(list(7, `,
+		{"rule": {"family": "inet", "table": "mkube", "chain": "acl", "handle": 8,
+			"comment": "mkube-acl: default/lab",
+			"expr": [
+				{"match": {"op": "==", "left": {"payload": {"protocol": "ip6", "field": "daddr"}}, "right": {"prefix": {"addr": "fd00::", "len": 64}}}},
+				{"match": {"op": "==", "left": {"meta": {"key": "l4proto"}}, "right": "ipv6-icmp"}},
+				{"drop": null}
+			]}},
+		{"rule": {"family": "inet", "table": "mkube", "chain": "acl", "handle": 9, "comment": "hand-made", "expr": [{"drop": null}]}}`))
+	if err != nil {
+		t.Fatalf("parseNftACLs: %v", err)
+	}
+	bridged, err := parseNftACLs(list(3, ""))
+	if err != nil {
+		t.Fatalf("parseNftACLs: %v", err)
+	}
+
+	got := pairACLs(routed, bridged)
+	ssh.ID, ping6.ID = "7/3", "8/"
+	if len(got) != 2 || got[0] != ssh || got[1] != ping6 {
+		t.Errorf("ACLs = %+v, want %+v and %+v", got, ssh, ping6)
+	}
+}
+
+func TestNftMasqueradeExpr(t *testing.T) {
+	if got, want := nftMasqueradeExpr("pods", "10.0.1.0/24"), `ip saddr 10.0.1.0/24 oifname != "pods" masquerade comment "mkube-nat: pods"`; got != want {
+		t.Errorf("nftMasqueradeExpr = %s, want %s", got, want)
+	}
+	if got := nftMasqueradeExpr("pods", "fd00:1::/64"); !strings.HasPrefix(got, "ip6 saddr fd00:1::/64 ") {
+		t.Errorf("IPv6 masquerade = %s", got)
+	}
+}
+
+func TestTCShapeCommands(t *testing.T) {
+	cmds := tcShapeCommands("veth-web-0", network.Bandwidth{Ingress: 10_000_000, Egress: 1_000_000})
+	if len(cmds) != 3 {
+		t.Fatalf("got %d commands, want 3: %v", len(cmds), cmds)
+	}
+	if got := strings.Join(cmds[0], " "); got != "qdisc replace dev veth-web-0 root tbf rate 10000000bit burst 125000 latency 50ms" {
+		t.Errorf("root qdisc = %q", got)
+	}
+	if got := strings.Join(cmds[2], " "); !strings.Contains(got, "police rate 1000000bit burst 12500") {
+		t.Errorf("police filter = %q", got)
+	}
+
+	if cmds := tcShapeCommands("veth-web-0", network.Bandwidth{Egress: 64_000}); len(cmds) != 2 || cmds[0][4] != "handle" ||
+		!strings.Contains(strings.Join(cmds[1], " "), "burst 3200") {
+		t.Errorf("egress-only commands = %v", cmds)
+	}
+}
+
+func TestSampleRates(t *testing.T) {
+	t0 := time.Now()
+	prev := linkSample{at: t0, txBytes: 1000, rxBytes: 5000}
+	cur := linkSample{at: t0.Add(2 * time.Second), txBytes: 251000, rxBytes: 4000}
+	bw := sampleRates(prev, cur)
+	if bw.Ingress != 1_000_000 || bw.Egress != 0 {
+		t.Errorf("sampleRates = %+v, want ingress 1000000 and egress 0 after a counter reset", bw)
+	}
+}
+
+func TestRelayRequest(t *testing.T) {
+	pkt := make([]byte, dhcpMinLen)
+	pkt[0] = dhcpBootReq
+	if !relayRequest(pkt, net.ParseIP("10.0.1.1")) {
+		t.Fatal("relayRequest dropped a client request")
+	}
+	if pkt[3] != 1 || !net.IP(pkt[24:28]).Equal(net.ParseIP("10.0.1.1")) {
+		t.Errorf("hops %d giaddr %v, want 1 and 10.0.1.1", pkt[3], net.IP(pkt[24:28]))
+	}
+
+	// A relay closer to the client keeps its giaddr
+	if !relayRequest(pkt, net.ParseIP("10.0.2.1")) || pkt[3] != 2 || !net.IP(pkt[24:28]).Equal(net.ParseIP("10.0.1.1")) {
+		t.Errorf("second relay: hops %d giaddr %v", pkt[3], net.IP(pkt[24:28]))
+	}
+
+	pkt[3] = dhcpMaxHops
+	if relayRequest(pkt, net.ParseIP("10.0.1.1")) {
+		t.Error("relayRequest forwarded a request past the hop limit")
+	}
+	if relayRequest(pkt[:100], net.ParseIP("10.0.1.1")) {
+		t.Error("relayRequest forwarded a truncated request")
+	}
+	reply := make([]byte, dhcpMinLen)
+	reply[0] = dhcpBootReply
+	if relayRequest(reply, net.ParseIP("10.0.1.1")) {
+		t.Error("relayRequest forwarded a reply as a request")
+	}
+}
+
+func TestRelayReplyDest(t *testing.T) {
+	pkt := make([]byte, dhcpMinLen)
+	if got := relayReplyDest(pkt); !got.IP.Equal(net.IPv4bcast) || got.Port != dhcpClientPort {
+		t.Errorf("reply to an unconfigured client goes to %v, want broadcast", got)
+	}
+	copy(pkt[12:16], net.ParseIP("10.0.1.7").To4())
+	if got := relayReplyDest(pkt); !got.IP.Equal(net.ParseIP("10.0.1.7")) || got.Port != dhcpClientPort {
+		t.Errorf("reply to a renewing client goes to %v, want 10.0.1.7:68", got)
+	}
+}
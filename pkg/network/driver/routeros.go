@@ -3,6 +3,9 @@ package driver
 import (
 	"context"
 	"fmt"
+	"net"
+	"strconv"
+	"strings"
 
 	"go.uber.org/zap"
 
@@ -79,42 +82,257 @@ func (d *RouterOS) ListPorts(ctx context.Context) ([]network.PortInfo, error) {
 	}
 	out := make([]network.PortInfo, len(veths))
 	for i, v := range veths {
+		gw := v.Gateway
+		if v.Gateway6 != "" {
+			gw += "," + v.Gateway6
+		}
 		out[i] = network.PortInfo{
 			Name:    v.Name,
 			Address: v.Address,
-			Gateway: v.Gateway,
+			Gateway: gw,
 		}
 	}
 	return out, nil
 }
 
+// ProbeAddress ARP-pings an IPv4 address on the bridge (ICMPv6 echo for
+// IPv6) and reports whether anything answered.
+func (d *RouterOS) ProbeAddress(ctx context.Context, bridge, ip string) (bool, error) {
+	v4 := net.ParseIP(ip).To4() != nil
+	received, err := d.client.Ping(ctx, ip, bridge, 2, v4)
+	if err != nil {
+		return false, err
+	}
+	return received > 0, nil
+}
+
 // ─── VLAN Operations ─────────────────────────────────────────────────────────
 
+// SetPortVLAN makes port an access port for vid (untagged, PVID) or adds it
+// as a tagged member of vid in its bridge's VLAN table. The port must
+// already be attached to a bridge.
 func (d *RouterOS) SetPortVLAN(ctx context.Context, port string, vid int, tagged bool) error {
-	return d.client.AddBridgeVLAN(ctx, port, vid, tagged, !tagged, !tagged)
+	if !tagged {
+		return d.client.SetBridgePortPVID(ctx, port, vid)
+	}
+	bridge, err := d.portBridge(ctx, port)
+	if err != nil {
+		return err
+	}
+	_, err = d.client.EnsureBridgeVLAN(ctx, bridge, vid, []string{port})
+	return err
 }
 
+// RemovePortVLAN drops port from vid: a tagged membership is removed from
+// the bridge VLAN table and an access port goes back to PVID 1.
 func (d *RouterOS) RemovePortVLAN(ctx context.Context, port string, vid int) error {
-	return d.client.RemoveBridgeVLAN(ctx, port, vid)
+	bridge, err := d.portBridge(ctx, port)
+	if err != nil {
+		return err
+	}
+	if err := d.client.RemoveBridgeVLANMember(ctx, bridge, vid, port); err != nil {
+		return err
+	}
+	return d.client.SetBridgePortPVID(ctx, port, 1)
+}
+
+// portBridge returns the bridge a port is attached to.
+func (d *RouterOS) portBridge(ctx context.Context, port string) (string, error) {
+	ports, err := d.client.ListBridgePorts(ctx)
+	if err != nil {
+		return "", err
+	}
+	for _, p := range ports {
+		if p.Interface == port {
+			return p.Bridge, nil
+		}
+	}
+	return "", fmt.Errorf("%q is not attached to a bridge", port)
 }
 
 // ─── Tunnel Operations ───────────────────────────────────────────────────────
 
-// CreateTunnel creates an EoIP tunnel on RouterOS (the closest equivalent to VXLAN).
-// RouterOS EoIP uses tunnel-id as the VNI equivalent.
+// CreateTunnel creates an EoIP tunnel on RouterOS (the closest equivalent to VXLAN)
+// or a WireGuard site link. RouterOS EoIP uses tunnel-id as the VNI equivalent.
 func (d *RouterOS) CreateTunnel(ctx context.Context, name string, spec network.TunnelSpec) error {
 	switch spec.Type {
 	case "eoip":
 		return d.client.CreateEoIPTunnel(ctx, name, spec.LocalIP, spec.RemoteIP, spec.VNI)
+	case "wireguard":
+		return d.createWireGuard(ctx, name, spec)
 	default:
-		return fmt.Errorf("RouterOS tunnel type %q not supported (use 'eoip')", spec.Type)
+		return fmt.Errorf("RouterOS tunnel type %q not supported (use 'eoip' or 'wireguard')", spec.Type)
 	}
 }
 
+// createWireGuard creates a WireGuard interface with a single peer and a
+// route for each allowed CIDR through it. A failure after the interface
+// exists removes it again.
+func (d *RouterOS) createWireGuard(ctx context.Context, name string, spec network.TunnelSpec) error {
+	if spec.PrivateKey == "" || spec.PeerPublicKey == "" {
+		return fmt.Errorf("wireguard tunnel %s needs a private key and a peer public key", name)
+	}
+	if err := d.client.CreateWireGuardInterface(ctx, name, spec.PrivateKey, spec.ListenPort); err != nil {
+		return err
+	}
+	err := d.client.AddWireGuardPeer(ctx, name, spec.PeerPublicKey, spec.RemoteIP, spec.ListenPort, spec.AllowedIPs)
+	for _, cidr := range spec.AllowedIPs {
+		if err != nil {
+			break
+		}
+		err = d.client.AddRoute(ctx, cidr, name, "mkube: "+name)
+	}
+	if err != nil {
+		_ = d.client.DeleteWireGuardInterface(ctx, name)
+		return fmt.Errorf("configuring wireguard tunnel %s: %w", name, err)
+	}
+	return nil
+}
+
+// DeleteTunnel removes the EoIP or WireGuard interface called name.
 func (d *RouterOS) DeleteTunnel(ctx context.Context, name string) error {
+	if err := d.client.DeleteWireGuardInterface(ctx, name); err != nil {
+		return err
+	}
 	return d.client.DeleteEoIPTunnel(ctx, name)
 }
 
+// overlayHorizon is the bridge split-horizon group shared by overlay tunnel
+// ports, so frames from one peer are never flooded back into another.
+const overlayHorizon = 1
+
+// IsolatePort puts port in the overlay split-horizon group of bridge.
+func (d *RouterOS) IsolatePort(ctx context.Context, bridge, port string) error {
+	return d.client.SetBridgePortHorizon(ctx, bridge, port, overlayHorizon)
+}
+
+// ─── Port Forward Operations ─────────────────────────────────────────────────
+
+// ListPortForwards returns the dst-nat rules tagged as mkube's.
+func (d *RouterOS) ListPortForwards(ctx context.Context) ([]network.PortForward, error) {
+	rules, err := d.client.ListNATRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var out []network.PortForward
+	for _, r := range rules {
+		owner := network.PortForwardOwner(r.Comment)
+		if owner == "" || r.Chain != "dstnat" || r.Action != "dst-nat" {
+			continue
+		}
+		extPort, _ := strconv.Atoi(r.DstPort)
+		toPort, _ := strconv.Atoi(r.ToPorts)
+		out = append(out, network.PortForward{
+			Owner:        owner,
+			Protocol:     r.Protocol,
+			Interface:    r.InInterface,
+			ExternalIP:   r.DstAddress,
+			ExternalPort: extPort,
+			TargetIP:     r.ToAddresses,
+			TargetPort:   toPort,
+			ID:           r.ID,
+		})
+	}
+	return out, nil
+}
+
+func (d *RouterOS) AddPortForward(ctx context.Context, pf network.PortForward) error {
+	return d.client.AddNATRule(ctx, routeros.NATRule{
+		Chain:       "dstnat",
+		Action:      "dst-nat",
+		Protocol:    pf.Protocol,
+		InInterface: pf.Interface,
+		DstAddress:  pf.ExternalIP,
+		DstPort:     strconv.Itoa(pf.ExternalPort),
+		ToAddresses: pf.TargetIP,
+		ToPorts:     strconv.Itoa(pf.TargetPort),
+		Comment:     network.PortForwardComment(pf.Owner),
+	})
+}
+
+func (d *RouterOS) RemovePortForward(ctx context.Context, pf network.PortForward) error {
+	return d.client.RemoveNATRule(ctx, pf.ID)
+}
+
+// ─── Bandwidth Operations ────────────────────────────────────────────────────
+
+// bandwidthQueueName returns the simple queue that shapes port.
+func bandwidthQueueName(port string) string {
+	return "mkube-bw-" + port
+}
+
+// findQueue returns the simple queue shaping port, or nil.
+func (d *RouterOS) findQueue(ctx context.Context, port string) (*routeros.SimpleQueue, error) {
+	queues, err := d.client.ListSimpleQueues(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range queues {
+		if queues[i].Name == bandwidthQueueName(port) {
+			return &queues[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// SetPortBandwidth shapes port with a simple queue targeting ip/32. A
+// queue's upload is what the target sends, i.e. the pod's egress.
+func (d *RouterOS) SetPortBandwidth(ctx context.Context, port, ip string, limit network.Bandwidth) error {
+	target := ip + "/32"
+	maxLimit := fmt.Sprintf("%d/%d", limit.Egress, limit.Ingress)
+	q, err := d.findQueue(ctx, port)
+	if err != nil {
+		return err
+	}
+	if q != nil {
+		return d.client.SetSimpleQueue(ctx, q.ID, target, maxLimit)
+	}
+	return d.client.AddSimpleQueue(ctx, routeros.SimpleQueue{
+		Name:     bandwidthQueueName(port),
+		Target:   target,
+		MaxLimit: maxLimit,
+		Comment:  "mkube: " + port,
+	})
+}
+
+// ClearPortBandwidth removes port's simple queue if it has one.
+func (d *RouterOS) ClearPortBandwidth(ctx context.Context, port string) error {
+	q, err := d.findQueue(ctx, port)
+	if err != nil || q == nil {
+		return err
+	}
+	return d.client.RemoveSimpleQueue(ctx, q.ID)
+}
+
+// PortRates returns the current rates RouterOS reports for port's queue.
+func (d *RouterOS) PortRates(ctx context.Context, port string) (network.Bandwidth, error) {
+	q, err := d.findQueue(ctx, port)
+	if err != nil {
+		return network.Bandwidth{}, err
+	}
+	if q == nil {
+		return network.Bandwidth{}, fmt.Errorf("no simple queue for %s", port)
+	}
+	return parseQueueRate(q.Rate)
+}
+
+// parseQueueRate parses a simple queue's "upload/download" rate pair.
+func parseQueueRate(s string) (network.Bandwidth, error) {
+	up, down, ok := strings.Cut(s, "/")
+	if !ok {
+		return network.Bandwidth{}, fmt.Errorf("malformed queue rate %q", s)
+	}
+	egress, err := strconv.ParseInt(up, 10, 64)
+	if err != nil {
+		return network.Bandwidth{}, fmt.Errorf("malformed queue rate %q: %w", s, err)
+	}
+	ingress, err := strconv.ParseInt(down, 10, 64)
+	if err != nil {
+		return network.Bandwidth{}, fmt.Errorf("malformed queue rate %q: %w", s, err)
+	}
+	return network.Bandwidth{Ingress: ingress, Egress: egress}, nil
+}
+
 // ─── Introspection ───────────────────────────────────────────────────────────
 
 func (d *RouterOS) NodeName() string {
@@ -123,11 +341,16 @@ func (d *RouterOS) NodeName() string {
 
 func (d *RouterOS) Capabilities() network.DriverCapabilities {
 	return network.DriverCapabilities{
-		VLANs:   true,
-		Tunnels: true,
-		ACLs:    false,
+		VLANs:      true,
+		Tunnels:    true,
+		ACLs:       false,
+		TunnelType: "eoip",
+		WireGuard:  true,
 	}
 }
 
 // Ensure RouterOS implements NetworkDriver at compile time.
 var _ network.NetworkDriver = (*RouterOS)(nil)
+var _ network.PortIsolator = (*RouterOS)(nil)
+var _ network.PortForwarder = (*RouterOS)(nil)
+var _ network.BandwidthShaper = (*RouterOS)(nil)
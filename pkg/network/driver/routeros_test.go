@@ -250,3 +250,15 @@ func TestNodeNameAndCapabilities(t *testing.T) {
 		t.Errorf("RouterOS driver should not support ACLs, got %+v", caps)
 	}
 }
+
+func TestParseQueueRate(t *testing.T) {
+	bw, err := parseQueueRate("1200/48000")
+	if err != nil || bw.Egress != 1200 || bw.Ingress != 48000 {
+		t.Errorf("parseQueueRate = %+v, %v", bw, err)
+	}
+	for _, bad := range []string{"", "1200", "a/1", "1/b"} {
+		if _, err := parseQueueRate(bad); err == nil {
+			t.Errorf("parseQueueRate(%q) succeeded", bad)
+		}
+	}
+}
@@ -142,9 +142,11 @@ func (d *StormBase) NodeName() string {
 
 func (d *StormBase) Capabilities() network.DriverCapabilities {
 	return network.DriverCapabilities{
-		VLANs:   false, // VLAN management stays on MikroTik
-		Tunnels: true,  // WireGuard mesh
-		ACLs:    true,  // BPF-based network policy
+		VLANs:      false, // VLAN management stays on MikroTik
+		Tunnels:    true,  // WireGuard mesh
+		ACLs:       true,  // BPF-based network policy
+		TunnelType: "wireguard",
+		WireGuard:  true,
 	}
 }
 
@@ -3,23 +3,32 @@ package ipam
 import (
 	"encoding/binary"
 	"fmt"
+	"math"
+	"math/big"
 	"net"
 	"sync"
 )
 
-// Pool tracks IP allocation state for a single subnet.
+// Pool tracks IP allocation state for a single IPv4 or IPv6 subnet.
+// Offsets are big integers so the same code handles /24s and /64s.
 type Pool struct {
 	Subnet     *net.IPNet
 	Gateway    net.IP
 	Allocated  map[string]net.IP // key (e.g. veth name) -> allocated IP
-	NextIP     uint32            // offset from network base
-	AllocStart uint32            // first allocatable offset (from network base)
-	AllocEnd   uint32            // last allocatable offset (from network base)
+	Reserved   map[string]net.IP // key (e.g. IPAddressClaim) -> reserved IP, never handed out
+	NextIP     *big.Int          // offset from network base
+	AllocStart *big.Int          // first allocatable offset (from network base)
+	AllocEnd   *big.Int          // last allocatable offset (from network base)
+
+	// The configured range, which AllocStart/AllocEnd narrow to when the
+	// pool is split between nodes (see SetSlice).
+	rangeStart *big.Int
+	rangeEnd   *big.Int
 }
 
 // PoolOpts are optional parameters for AddPool.
 type PoolOpts struct {
-	AllocStart net.IP // first allocatable IP (nil = .2)
+	AllocStart net.IP // first allocatable IP (nil = base+2)
 	AllocEnd   net.IP // last allocatable IP (nil = max usable host)
 }
 
@@ -44,19 +53,16 @@ func (a *Allocator) AddPool(name string, subnet *net.IPNet, gateway net.IP, opts
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
-	ones, bits := subnet.Mask.Size()
-	maxHosts := uint32(1<<(bits-ones)) - 2
-
-	start := uint32(2)
-	end := maxHosts
-	baseIP := IPToUint32(subnet.IP)
+	baseIP := IPToInt(subnet.IP)
+	start := big.NewInt(2)
+	end := new(big.Int).Sub(subnetSize(subnet), big.NewInt(2))
 
 	if len(opts) > 0 {
 		if opts[0].AllocStart != nil {
-			start = IPToUint32(opts[0].AllocStart) - baseIP
+			start = new(big.Int).Sub(IPToInt(opts[0].AllocStart), baseIP)
 		}
 		if opts[0].AllocEnd != nil {
-			end = IPToUint32(opts[0].AllocEnd) - baseIP
+			end = new(big.Int).Sub(IPToInt(opts[0].AllocEnd), baseIP)
 		}
 	}
 
@@ -64,10 +70,69 @@ func (a *Allocator) AddPool(name string, subnet *net.IPNet, gateway net.IP, opts
 		Subnet:     subnet,
 		Gateway:    gateway,
 		Allocated:  make(map[string]net.IP),
-		NextIP:     start,
+		Reserved:   make(map[string]net.IP),
+		NextIP:     new(big.Int).Set(start),
 		AllocStart: start,
 		AllocEnd:   end,
+		rangeStart: new(big.Int).Set(start),
+		rangeEnd:   new(big.Int).Set(end),
+	}
+}
+
+// SetSlice narrows the named pool's allocation range to slice index of count
+// equal slices of its configured range, so nodes sharing a subnet hand out
+// disjoint addresses. count <= 1 restores the full range. Existing
+// allocations outside the new slice are kept. Returns the slice bounds.
+func (a *Allocator) SetSlice(poolName string, index, count int) (net.IP, net.IP, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	pool, ok := a.pools[poolName]
+	if !ok {
+		return nil, nil, fmt.Errorf("IPAM pool %q not found", poolName)
+	}
+	start, end := new(big.Int).Set(pool.rangeStart), new(big.Int).Set(pool.rangeEnd)
+	if count > 1 {
+		if index < 0 || index >= count {
+			return nil, nil, fmt.Errorf("slice %d out of range for %d slices", index, count)
+		}
+		size := new(big.Int).Sub(pool.rangeEnd, pool.rangeStart)
+		size.Add(size, big.NewInt(1))
+		per := new(big.Int).Div(size, big.NewInt(int64(count)))
+		if per.Sign() == 0 {
+			return nil, nil, fmt.Errorf("pool %s range of %s addresses is too small for %d slices", poolName, size, count)
+		}
+		start.Add(pool.rangeStart, new(big.Int).Mul(per, big.NewInt(int64(index))))
+		if index < count-1 {
+			end.Add(start, per)
+			end.Sub(end, big.NewInt(1))
+		}
 	}
+
+	pool.AllocStart, pool.AllocEnd = start, end
+	if pool.NextIP.Cmp(start) < 0 || pool.NextIP.Cmp(end) > 0 {
+		pool.NextIP.Set(start)
+	}
+	baseIP := IPToInt(pool.Subnet.IP)
+	v6 := isIPv6(pool.Subnet)
+	return IntToIP(new(big.Int).Add(baseIP, start), v6), IntToIP(new(big.Int).Add(baseIP, end), v6), nil
+}
+
+// InOtherSlice reports whether ip lies in the configured range of a split
+// pool but outside this node's slice, i.e. in the share another node hands
+// out. Addresses outside the configured range belong to no slice.
+func (a *Allocator) InOtherSlice(poolName string, ip net.IP) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	pool, ok := a.pools[poolName]
+	if !ok || !pool.Subnet.Contains(ip) {
+		return false
+	}
+	off := new(big.Int).Sub(IPToInt(ip), IPToInt(pool.Subnet.IP))
+	inRange := off.Cmp(pool.rangeStart) >= 0 && off.Cmp(pool.rangeEnd) <= 0
+	inSlice := off.Cmp(pool.AllocStart) >= 0 && off.Cmp(pool.AllocEnd) <= 0
+	return inRange && !inSlice
 }
 
 // RemovePool deletes a pool from the allocator (used when networks are deleted).
@@ -113,10 +178,97 @@ func (a *Allocator) AllocateStatic(poolName, key string, ip net.IP) error {
 			return fmt.Errorf("IP %s already allocated to %s", ip, k)
 		}
 	}
+	if holder := reservedBy(pool, ip); holder != "" {
+		return fmt.Errorf("IP %s is reserved by %s", ip, holder)
+	}
 	pool.Allocated[key] = ip
 	return nil
 }
 
+// Reserve sets aside ip (or the next free IP when ip is nil) in the named
+// pool under key. Reserved addresses are kept apart from Allocated: they are
+// never handed out by Allocate or AllocateStatic and survive allocation
+// re-syncs, since nothing on the device backs them.
+func (a *Allocator) Reserve(poolName, key string, ip net.IP) (net.IP, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	pool, ok := a.pools[poolName]
+	if !ok {
+		return nil, fmt.Errorf("IPAM pool %q not found", poolName)
+	}
+	if existing, ok := pool.Reserved[key]; ok {
+		if ip == nil || existing.Equal(ip) {
+			return existing, nil // idempotent
+		}
+		return nil, fmt.Errorf("%s already reserves %s", key, existing)
+	}
+
+	if ip == nil {
+		// Borrow the allocation walk, then move the result across
+		picked, err := allocateFromPool(pool, key)
+		if err != nil {
+			return nil, err
+		}
+		delete(pool.Allocated, key)
+		pool.Reserved[key] = picked
+		return picked, nil
+	}
+
+	if !pool.Subnet.Contains(ip) {
+		return nil, fmt.Errorf("IP %s not in subnet %s", ip, pool.Subnet)
+	}
+	if ip.Equal(pool.Gateway) {
+		return nil, fmt.Errorf("IP %s is the gateway", ip)
+	}
+	for k, existing := range pool.Allocated {
+		if existing.Equal(ip) {
+			return nil, fmt.Errorf("IP %s already allocated to %s", ip, k)
+		}
+	}
+	if holder := reservedBy(pool, ip); holder != "" {
+		return nil, fmt.Errorf("IP %s is reserved by %s", ip, holder)
+	}
+	pool.Reserved[key] = ip
+	return ip, nil
+}
+
+// Unreserve frees the IP reserved under key in the named pool.
+func (a *Allocator) Unreserve(poolName, key string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if pool, ok := a.pools[poolName]; ok {
+		delete(pool.Reserved, key)
+	}
+}
+
+// PoolReservations returns a snapshot of reservations for a pool.
+func (a *Allocator) PoolReservations(poolName string) map[string]net.IP {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	pool, ok := a.pools[poolName]
+	if !ok {
+		return nil
+	}
+	out := make(map[string]net.IP, len(pool.Reserved))
+	for k, v := range pool.Reserved {
+		out[k] = v
+	}
+	return out
+}
+
+// reservedBy returns the key reserving ip in pool, or "".
+func reservedBy(pool *Pool, ip net.IP) string {
+	for k, existing := range pool.Reserved {
+		if existing.Equal(ip) {
+			return k
+		}
+	}
+	return ""
+}
+
 // Release frees the IP held by key in the named pool.
 func (a *Allocator) Release(poolName, key string) {
 	a.mu.Lock()
@@ -179,6 +331,43 @@ func (a *Allocator) PoolAllocations(poolName string) map[string]net.IP {
 	return out
 }
 
+// PoolUsage summarizes how much of a pool's allocation range is in use.
+type PoolUsage struct {
+	Allocated int // addresses currently allocated inside [AllocStart, AllocEnd]
+	Capacity  int // total addresses in [AllocStart, AllocEnd]
+}
+
+// Usage returns allocation counts for the named pool, reservations included.
+// Addresses outside the allocation range (static IPs) are not counted.
+func (a *Allocator) Usage(poolName string) (PoolUsage, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	pool, ok := a.pools[poolName]
+	if !ok {
+		return PoolUsage{}, false
+	}
+
+	baseIP := IPToInt(pool.Subnet.IP)
+	capacity := rangeSize(pool)
+	u := PoolUsage{Capacity: math.MaxInt}
+	if capacity.IsInt64() && capacity.Int64() < math.MaxInt {
+		u.Capacity = int(capacity.Int64())
+	}
+	for _, m := range []map[string]net.IP{pool.Allocated, pool.Reserved} {
+		for _, ip := range m {
+			if !pool.Subnet.Contains(ip) {
+				continue
+			}
+			off := new(big.Int).Sub(IPToInt(ip), baseIP)
+			if off.Cmp(pool.AllocStart) >= 0 && off.Cmp(pool.AllocEnd) <= 0 {
+				u.Allocated++
+			}
+		}
+	}
+	return u, true
+}
+
 // AllAllocations returns all allocations across all pools as key -> IP string.
 func (a *Allocator) AllAllocations() map[string]string {
 	a.mu.Lock()
@@ -209,55 +398,93 @@ func (a *Allocator) PoolForIP(ip net.IP) string {
 // allocateFromPool picks the next free IP from a single pool, constrained
 // to the [AllocStart, AllocEnd] range.
 func allocateFromPool(pool *Pool, key string) (net.IP, error) {
-	rangeSize := pool.AllocEnd - pool.AllocStart + 1
-	baseIP := IPToUint32(pool.Subnet.IP)
-
-	for attempts := uint32(0); attempts < rangeSize; attempts++ {
-		candidate := baseIP + pool.NextIP
-		candidateIP := Uint32ToIP(candidate)
-
-		taken := false
-		for _, existing := range pool.Allocated {
-			if existing.Equal(candidateIP) {
-				taken = true
-				break
-			}
-		}
+	size := rangeSize(pool)
+	baseIP := IPToInt(pool.Subnet.IP)
+	v6 := isIPv6(pool.Subnet)
 
-		pool.NextIP++
-		if pool.NextIP > pool.AllocEnd {
-			pool.NextIP = pool.AllocStart
+	taken := make(map[string]bool, len(pool.Allocated)+1)
+	for _, existing := range pool.Allocated {
+		taken[existing.String()] = true
+	}
+	for _, existing := range pool.Reserved {
+		taken[existing.String()] = true
+	}
+	if pool.Gateway != nil {
+		taken[pool.Gateway.String()] = true
+	}
+
+	one := big.NewInt(1)
+	for attempts := new(big.Int); attempts.Cmp(size) < 0; attempts.Add(attempts, one) {
+		candidateIP := IntToIP(new(big.Int).Add(baseIP, pool.NextIP), v6)
+
+		pool.NextIP.Add(pool.NextIP, one)
+		if pool.NextIP.Cmp(pool.AllocEnd) > 0 {
+			pool.NextIP.Set(pool.AllocStart)
 		}
 
-		if !taken && !candidateIP.Equal(pool.Gateway) {
+		if !taken[candidateIP.String()] {
 			pool.Allocated[key] = candidateIP
 			return candidateIP, nil
 		}
 	}
 
-	return nil, fmt.Errorf("IPAM: no available IPs in %s range %s-%s (all %d addresses allocated)",
+	return nil, fmt.Errorf("IPAM: no available IPs in %s range %s-%s (all %s addresses allocated)",
 		pool.Subnet.String(),
-		Uint32ToIP(baseIP+pool.AllocStart).String(),
-		Uint32ToIP(baseIP+pool.AllocEnd).String(),
-		rangeSize)
+		IntToIP(new(big.Int).Add(baseIP, pool.AllocStart), v6).String(),
+		IntToIP(new(big.Int).Add(baseIP, pool.AllocEnd), v6).String(),
+		size.String())
 }
 
-// MaxUsableIP returns the highest usable host IP in a subnet (broadcast - 1).
-func MaxUsableIP(subnet *net.IPNet) net.IP {
+// rangeSize returns the number of addresses in the pool's allocation range.
+func rangeSize(pool *Pool) *big.Int {
+	n := new(big.Int).Sub(pool.AllocEnd, pool.AllocStart)
+	return n.Add(n, big.NewInt(1))
+}
+
+// subnetSize returns the number of addresses in subnet.
+func subnetSize(subnet *net.IPNet) *big.Int {
 	ones, bits := subnet.Mask.Size()
-	baseIP := IPToUint32(subnet.IP)
-	broadcast := baseIP | uint32((1<<(bits-ones))-1)
-	return Uint32ToIP(broadcast - 1)
+	return new(big.Int).Lsh(big.NewInt(1), uint(bits-ones))
+}
+
+// isIPv6 reports whether subnet is an IPv6 subnet.
+func isIPv6(subnet *net.IPNet) bool {
+	return subnet.IP.To4() == nil
+}
+
+// MaxUsableIP returns the highest usable host IP in a subnet (broadcast - 1
+// for IPv4, the last address - 1 for IPv6).
+func MaxUsableIP(subnet *net.IPNet) net.IP {
+	n := new(big.Int).Add(IPToInt(subnet.IP), subnetSize(subnet))
+	return IntToIP(n.Sub(n, big.NewInt(2)), isIPv6(subnet))
 }
 
 // DNSServerIP returns the IP address a MicroDNS instance should use on a
 // subnet. It is MaxUsableIP - 2, leaving the top two addresses free for
 // routers or other infrastructure.
 func DNSServerIP(subnet *net.IPNet) net.IP {
-	ones, bits := subnet.Mask.Size()
-	baseIP := IPToUint32(subnet.IP)
-	broadcast := baseIP | uint32((1<<(bits-ones))-1)
-	return Uint32ToIP(broadcast - 3)
+	n := new(big.Int).Add(IPToInt(subnet.IP), subnetSize(subnet))
+	return IntToIP(n.Sub(n, big.NewInt(4)), isIPv6(subnet))
+}
+
+// IPToInt converts an IPv4 or IPv6 address to a big integer. IPv4 addresses
+// (including IPv4-mapped IPv6 forms) use their 4-byte value.
+func IPToInt(ip net.IP) *big.Int {
+	if v4 := ip.To4(); v4 != nil {
+		return new(big.Int).SetBytes(v4)
+	}
+	return new(big.Int).SetBytes(ip.To16())
+}
+
+// IntToIP converts a big integer back to an IPv4 (v6 false) or IPv6 address.
+func IntToIP(n *big.Int, v6 bool) net.IP {
+	size := net.IPv4len
+	if v6 {
+		size = net.IPv6len
+	}
+	ip := make(net.IP, size)
+	n.FillBytes(ip)
+	return ip
 }
 
 // IPToUint32 converts a net.IP (IPv4) to a uint32.
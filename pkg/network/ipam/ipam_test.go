@@ -1,6 +1,7 @@
 package ipam
 
 import (
+	"math"
 	"net"
 	"testing"
 )
@@ -224,6 +225,56 @@ func TestAllocateStaticDuplicate(t *testing.T) {
 	}
 }
 
+func TestReserve(t *testing.T) {
+	a := NewAllocator()
+	_, subnet, _ := net.ParseCIDR("192.168.200.0/24")
+	gw := net.ParseIP("192.168.200.1")
+	a.AddPool("gt", subnet, gw)
+
+	// Specific reservation blocks static and dynamic allocation
+	if _, err := a.Reserve("gt", "ipclaim/default/vip", net.ParseIP("192.168.200.2")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := a.AllocateStatic("gt", "veth-0", net.ParseIP("192.168.200.2")); err == nil {
+		t.Error("expected AllocateStatic to refuse a reserved IP")
+	}
+	ip, err := a.Allocate("gt", "veth-0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ip.String() != "192.168.200.3" {
+		t.Errorf("expected dynamic allocation to skip the reservation, got %s", ip)
+	}
+
+	// Next-free reservation, idempotent per key
+	next, err := a.Reserve("gt", "ipclaim/default/appliance", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if next.String() != "192.168.200.4" {
+		t.Errorf("expected 192.168.200.4, got %s", next)
+	}
+	if again, _ := a.Reserve("gt", "ipclaim/default/appliance", nil); !again.Equal(next) {
+		t.Errorf("expected repeat Reserve to return %s, got %s", next, again)
+	}
+	if _, err := a.Reserve("gt", "ipclaim/default/other", net.ParseIP("192.168.200.3")); err == nil {
+		t.Error("expected Reserve to refuse an allocated IP")
+	}
+
+	// Reservations are kept out of the allocation map but count as usage
+	if _, ok := a.AllAllocations()["ipclaim/default/vip"]; ok {
+		t.Error("reservation leaked into allocations")
+	}
+	if u, _ := a.Usage("gt"); u.Allocated != 3 {
+		t.Errorf("expected 3 addresses in use, got %d", u.Allocated)
+	}
+
+	a.Unreserve("gt", "ipclaim/default/vip")
+	if err := a.AllocateStatic("gt", "veth-1", net.ParseIP("192.168.200.2")); err != nil {
+		t.Errorf("expected IP to be free after Unreserve: %v", err)
+	}
+}
+
 func TestAllocateStaticGateway(t *testing.T) {
 	a := NewAllocator()
 	_, subnet, _ := net.ParseCIDR("192.168.200.0/24")
@@ -405,3 +456,148 @@ func TestDNSServerIP(t *testing.T) {
 		}
 	}
 }
+
+func TestUsage(t *testing.T) {
+	a := NewAllocator()
+	_, subnet, _ := net.ParseCIDR("192.168.200.0/24")
+	a.AddPool("gt", subnet, net.ParseIP("192.168.200.1"), PoolOpts{
+		AllocStart: net.ParseIP("192.168.200.10"),
+		AllocEnd:   net.ParseIP("192.168.200.19"),
+	})
+
+	_, _ = a.Allocate("gt", "veth-0")
+	_, _ = a.Allocate("gt", "veth-1")
+	// Static IP outside the range is not counted
+	_ = a.AllocateStatic("gt", "veth-dns", net.ParseIP("192.168.200.252"))
+
+	u, ok := a.Usage("gt")
+	if !ok {
+		t.Fatal("expected pool gt")
+	}
+	if u.Capacity != 10 {
+		t.Errorf("expected capacity 10, got %d", u.Capacity)
+	}
+	if u.Allocated != 2 {
+		t.Errorf("expected 2 allocated, got %d", u.Allocated)
+	}
+
+	if _, ok := a.Usage("nonexistent"); ok {
+		t.Error("expected unknown pool to report !ok")
+	}
+}
+
+func TestAllocateIPv6(t *testing.T) {
+	a := NewAllocator()
+	_, subnet, _ := net.ParseCIDR("fd00:20::/64")
+	a.AddPool("v6", subnet, net.ParseIP("fd00:20::1"))
+
+	ip1, err := a.Allocate("v6", "veth-0")
+	if err != nil {
+		t.Fatalf("Allocate: %v", err)
+	}
+	if ip1.String() != "fd00:20::2" {
+		t.Errorf("expected fd00:20::2, got %s", ip1)
+	}
+	ip2, _ := a.Allocate("v6", "veth-1")
+	if ip2.String() != "fd00:20::3" {
+		t.Errorf("expected fd00:20::3, got %s", ip2)
+	}
+
+	if err := a.AllocateStatic("v6", "veth-2", net.ParseIP("fd00:20::4")); err != nil {
+		t.Fatalf("AllocateStatic: %v", err)
+	}
+	ip3, _ := a.Allocate("v6", "veth-3")
+	if ip3.String() != "fd00:20::5" {
+		t.Errorf("expected static fd00:20::4 to be skipped, got %s", ip3)
+	}
+
+	if got := a.PoolForIP(net.ParseIP("fd00:20::ffff")); got != "v6" {
+		t.Errorf("PoolForIP = %q, want v6", got)
+	}
+	u, _ := a.Usage("v6")
+	if u.Allocated != 4 || u.Capacity != math.MaxInt {
+		t.Errorf("unexpected usage %+v", u)
+	}
+}
+
+func TestAllocateIPv6Range(t *testing.T) {
+	a := NewAllocator()
+	_, subnet, _ := net.ParseCIDR("fd00:20::/64")
+	a.AddPool("v6", subnet, net.ParseIP("fd00:20::1"), PoolOpts{
+		AllocStart: net.ParseIP("fd00:20::1:0"),
+		AllocEnd:   net.ParseIP("fd00:20::1:1"),
+	})
+
+	for _, want := range []string{"fd00:20::1:0", "fd00:20::1:1"} {
+		ip, err := a.Allocate("v6", want)
+		if err != nil || ip.String() != want {
+			t.Fatalf("expected %s, got %s (%v)", want, ip, err)
+		}
+	}
+	if _, err := a.Allocate("v6", "extra"); err == nil {
+		t.Error("expected range exhaustion")
+	}
+	if u, _ := a.Usage("v6"); u.Capacity != 2 || u.Allocated != 2 {
+		t.Errorf("unexpected usage %+v", u)
+	}
+}
+
+func TestIPv6SubnetHelpers(t *testing.T) {
+	_, subnet, _ := net.ParseCIDR("fd00:20::/120")
+	if got := MaxUsableIP(subnet).String(); got != "fd00:20::fe" {
+		t.Errorf("MaxUsableIP = %s, want fd00:20::fe", got)
+	}
+	if got := DNSServerIP(subnet).String(); got != "fd00:20::fc" {
+		t.Errorf("DNSServerIP = %s, want fd00:20::fc", got)
+	}
+	n := IPToInt(net.ParseIP("fd00:20::1:2"))
+	if back := IntToIP(n, true); back.String() != "fd00:20::1:2" {
+		t.Errorf("IntToIP round trip = %s", back)
+	}
+	if back := IntToIP(IPToInt(net.ParseIP("10.0.0.7")), false); back.String() != "10.0.0.7" {
+		t.Errorf("IPv4 round trip = %s", back)
+	}
+}
+
+func TestSetSlice(t *testing.T) {
+	_, subnet, _ := net.ParseCIDR("10.0.0.0/24")
+	a := NewAllocator()
+	a.AddPool("net", subnet, net.ParseIP("10.0.0.1"), PoolOpts{
+		AllocStart: net.ParseIP("10.0.0.10"),
+		AllocEnd:   net.ParseIP("10.0.0.109"),
+	})
+
+	tests := []struct {
+		index, count int
+		start, end   string
+	}{
+		{0, 3, "10.0.0.10", "10.0.0.42"},
+		{1, 3, "10.0.0.43", "10.0.0.75"},
+		{2, 3, "10.0.0.76", "10.0.0.109"}, // last slice takes the remainder
+		{0, 1, "10.0.0.10", "10.0.0.109"},
+	}
+	for _, tt := range tests {
+		start, end, err := a.SetSlice("net", tt.index, tt.count)
+		if err != nil {
+			t.Fatalf("SetSlice(%d, %d): %v", tt.index, tt.count, err)
+		}
+		if start.String() != tt.start || end.String() != tt.end {
+			t.Errorf("SetSlice(%d, %d) = %s-%s, want %s-%s", tt.index, tt.count, start, end, tt.start, tt.end)
+		}
+	}
+
+	if _, _, err := a.SetSlice("net", 1, 3); err != nil {
+		t.Fatal(err)
+	}
+	if ip, _ := a.Allocate("net", "a"); ip.String() != "10.0.0.43" {
+		t.Errorf("first allocation in slice = %s, want 10.0.0.43", ip)
+	}
+	for ip, want := range map[string]bool{"10.0.0.42": true, "10.0.0.50": false, "10.0.0.76": true, "10.0.0.5": false} {
+		if got := a.InOtherSlice("net", net.ParseIP(ip)); got != want {
+			t.Errorf("InOtherSlice(%s) = %v, want %v", ip, got, want)
+		}
+	}
+	if _, _, err := a.SetSlice("net", 0, 200); err == nil {
+		t.Error("expected error for slices smaller than one address")
+	}
+}
@@ -2,6 +2,7 @@ package network
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
 	"strings"
@@ -12,23 +13,33 @@ import (
 	"github.com/glennswest/mkube/pkg/config"
 	"github.com/glennswest/mkube/pkg/dns"
 	"github.com/glennswest/mkube/pkg/network/ipam"
+	"github.com/glennswest/mkube/pkg/store"
 )
 
 // networkState holds per-network config and cached zone ID.
 type networkState struct {
-	def     config.NetworkDef
-	subnet  *net.IPNet
-	gateway net.IP
-	zoneID  string // cached MicroDNS zone UUID
+	def      config.NetworkDef
+	subnet   *net.IPNet
+	gateway  net.IP
+	subnet6  *net.IPNet // dual-stack IPv6 subnet, nil when single-stack
+	gateway6 net.IP
+	zoneID   string // cached MicroDNS zone UUID
+	slice    string // "i/n" when the IPAM range is split between overlay nodes
 }
 
 // allocation tracks which network a veth belongs to.
 type allocation struct {
 	networkName string
 	ip          net.IP
+	ip6         net.IP // dual-stack IPv6 address, nil when single-stack
 	hostname    string
 }
 
+// pool6 returns the IPAM pool name holding a network's IPv6 addresses.
+func pool6(networkName string) string {
+	return networkName + "/v6"
+}
+
 // Manager handles IP address allocation (IPAM), veth interface creation,
 // DNS registration, and bridge port management across multiple networks.
 type Manager struct {
@@ -40,8 +51,14 @@ type Manager struct {
 	state    *stateStore
 	log      *zap.SugaredLogger
 
-	mu     sync.Mutex
-	allocs map[string]*allocation // veth name -> allocation info
+	mu           sync.Mutex
+	allocs       map[string]*allocation // veth name -> allocation info
+	reservations map[string]*allocation // reservation key -> reserved address
+	claims       *store.Bucket          // NATS IPAM bucket, nil until SetStore
+	conflicts    map[string]string      // veth or reservation key -> claim conflict seen by adoptClaims
+	tunnels      map[string]*Tunnel     // overlay tunnel name -> tunnel built by ReconcileMesh
+	shaped       map[string]shapedPort  // veth name -> bandwidth shaping applied by SetPortBandwidth
+	rates        map[string]Bandwidth   // veth name -> rates last read by RefreshPortRates
 }
 
 // ManagerOpts are optional settings for NewManager.
@@ -60,68 +77,23 @@ func NewManager(networks []config.NetworkDef, driver NetworkDriver, dnsClient *d
 	ss := newStateStore(o.StatePath)
 
 	mgr := &Manager{
-		networks: make(map[string]*networkState, len(networks)),
-		driver:   driver,
-		dns:      dnsClient,
-		ipam:     alloc,
-		state:    ss,
-		log:      log,
-		allocs:   make(map[string]*allocation),
+		networks:     make(map[string]*networkState, len(networks)),
+		driver:       driver,
+		dns:          dnsClient,
+		ipam:         alloc,
+		state:        ss,
+		log:          log,
+		allocs:       make(map[string]*allocation),
+		reservations: make(map[string]*allocation),
+		tunnels:      make(map[string]*Tunnel),
+		shaped:       make(map[string]shapedPort),
+		rates:        make(map[string]Bandwidth),
 	}
 
 	for _, netDef := range networks {
-		_, subnet, err := net.ParseCIDR(netDef.CIDR)
-		if err != nil {
-			return nil, fmt.Errorf("parsing CIDR %q for network %s: %w", netDef.CIDR, netDef.Name, err)
-		}
-
-		gateway := make(net.IP, len(subnet.IP))
-		copy(gateway, subnet.IP)
-		gateway[len(gateway)-1] = 1
-
-		if netDef.Gateway != "" {
-			gateway = net.ParseIP(netDef.Gateway)
-			if gateway == nil {
-				return nil, fmt.Errorf("invalid gateway IP %q for network %s", netDef.Gateway, netDef.Name)
-			}
-		}
-
-		ns := &networkState{
-			def:     netDef,
-			subnet:  subnet,
-			gateway: gateway,
-		}
-
-		mgr.networks[netDef.Name] = ns
-		mgr.netOrder = append(mgr.netOrder, netDef.Name)
-
-		// Register IPAM pool with optional allocation range
-		var poolOpts []ipam.PoolOpts
-		if netDef.IPAMStart != "" || netDef.IPAMEnd != "" {
-			o := ipam.PoolOpts{}
-			if netDef.IPAMStart != "" {
-				o.AllocStart = net.ParseIP(netDef.IPAMStart)
-				if o.AllocStart == nil {
-					return nil, fmt.Errorf("invalid ipamStart %q for network %s", netDef.IPAMStart, netDef.Name)
-				}
-			}
-			if netDef.IPAMEnd != "" {
-				o.AllocEnd = net.ParseIP(netDef.IPAMEnd)
-				if o.AllocEnd == nil {
-					return nil, fmt.Errorf("invalid ipamEnd %q for network %s", netDef.IPAMEnd, netDef.Name)
-				}
-			}
-			poolOpts = append(poolOpts, o)
+		if err := mgr.addNetwork(netDef); err != nil {
+			return nil, err
 		}
-		alloc.AddPool(netDef.Name, subnet, gateway, poolOpts...)
-
-		// Register logical switch in state
-		ss.setSwitch(&LogicalSwitch{
-			Name:    netDef.Name,
-			Bridge:  netDef.Bridge,
-			CIDR:    netDef.CIDR,
-			Gateway: gateway.String(),
-		})
 	}
 
 	// Try loading persisted state
@@ -137,7 +109,8 @@ func NewManager(networks []config.NetworkDef, driver NetworkDriver, dnsClient *d
 	return mgr, nil
 }
 
-// InitDNSZones calls EnsureZone for each network that has DNS configured.
+// InitDNSZones calls EnsureZone for each network that has DNS configured,
+// along with EnsureReverseZones for its subnets, and backfills PTR records.
 // Should be called at startup after NewManager.
 func (m *Manager) InitDNSZones(ctx context.Context) {
 	if m.dns == nil {
@@ -167,9 +140,16 @@ func (m *Manager) InitDNSZones(ctx context.Context) {
 		ns.zoneID = zoneID
 		m.log.Infow("DNS zone ready", "network", name, "zone", ns.def.DNS.Zone, "zoneID", zoneID)
 
+		// Reverse zones for the network's subnets, so every address record
+		// registered on this endpoint gets a PTR
+		if _, err := m.dns.EnsureReverseZones(ctx, ns.def.DNS.Endpoint, []string{ns.def.CIDR, ns.def.CIDR6}); err != nil {
+			m.log.Warnw("failed to ensure reverse DNS zones", "network", name, "cidr", ns.def.CIDR, "error", err)
+		}
+
 		// Fetch existing records once to avoid creating duplicates on restart,
 		// and remove any duplicate A records that have accumulated.
 		existing := make(map[string]string) // "name:ip" -> first record ID
+		existingTTL := make(map[string]int) // "name:ip" -> its TTL
 		if records, err := m.dns.ListRecords(ctx, ns.def.DNS.Endpoint, zoneID); err == nil {
 			for _, r := range records {
 				if r.Type != "A" {
@@ -185,16 +165,18 @@ func (m *Manager) InitDNSZones(ctx context.Context) {
 					}
 				} else {
 					existing[key] = r.ID
+					existingTTL[key] = r.TTL
 				}
 			}
 		}
 
 		// Register static DNS records for infrastructure hosts (routers, gateways, etc.)
+		staticTTL := ns.def.DNS.TTL.TTL(config.DNSTTLStatic, 300)
 		for _, rec := range ns.def.DNS.StaticRecords {
-			if _, found := existing[rec.Name+":"+rec.IP]; found {
+			if ttl, found := existingTTL[rec.Name+":"+rec.IP]; found && ttl == staticTTL {
 				continue
 			}
-			if err := m.dns.RegisterHost(ctx, ns.def.DNS.Endpoint, zoneID, rec.Name, rec.IP, 300); err != nil {
+			if err := m.dns.RegisterHost(ctx, ns.def.DNS.Endpoint, zoneID, rec.Name, rec.IP, staticTTL); err != nil {
 				m.log.Warnw("failed to register static DNS record", "network", name, "name", rec.Name, "ip", rec.IP, "error", err)
 			} else {
 				m.log.Infow("static DNS record registered", "network", name, "name", rec.Name, "ip", rec.IP)
@@ -212,21 +194,46 @@ func (m *Manager) InitDNSZones(ctx context.Context) {
 			if infra.ip == "" {
 				continue
 			}
-			if _, found := existing[infra.name+":"+infra.ip]; found {
+			if ttl, found := existingTTL[infra.name+":"+infra.ip]; found && ttl == staticTTL {
 				continue
 			}
-			if err := m.dns.RegisterHost(ctx, ns.def.DNS.Endpoint, zoneID, infra.name, infra.ip, 300); err != nil {
+			if err := m.dns.RegisterHost(ctx, ns.def.DNS.Endpoint, zoneID, infra.name, infra.ip, staticTTL); err != nil {
 				m.log.Warnw("failed to register infra DNS record", "network", name, "name", infra.name, "ip", infra.ip, "error", err)
 			} else {
 				m.log.Infow("infra DNS record registered", "network", name, "name", infra.name, "ip", infra.ip)
 			}
 		}
+
+		// Backfill PTRs for records that predate the reverse zones and drop
+		// PTRs whose address record is gone
+		if err := m.dns.SyncReverseRecords(ctx, ns.def.DNS.Endpoint, zoneID); err != nil {
+			m.log.Warnw("failed to sync reverse DNS records", "network", name, "error", err)
+		}
 	}
 }
 
+// SetPortNamespace tells the driver which network namespace the container
+// end of vethName belongs in; call it before AllocateInterface. Drivers
+// without namespaces return ErrNotSupported.
+func (m *Manager) SetPortNamespace(vethName, netns string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	pn, ok := m.driver.(PortNamespacer)
+	if !ok {
+		return ErrNotSupported
+	}
+	return pn.SetPortNamespace(vethName, netns)
+}
+
 // AllocateInterface creates a veth, assigns an IP from the specified network,
 // registers a DNS A record, and adds it to the network's bridge.
 // If networkName is empty, the first (default) network is used.
+//
+// On dual-stack networks the veth also gets an IPv6 address (and AAAA
+// record); a static IP pins the address of its own family and the other one
+// is allocated dynamically. The returned ip and gateway are always the
+// primary family — use GetPortIPs for the full list.
 func (m *Manager) AllocateInterface(ctx context.Context, vethName, hostname, networkName, staticIP string) (ip string, gateway string, dnsServer string, err error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -236,43 +243,73 @@ func (m *Manager) AllocateInterface(ctx context.Context, vethName, hostname, net
 		return "", "", "", err
 	}
 
-	// Allocate an IP via IPAM
-	var allocatedIP net.IP
+	var static, static6 net.IP
 	if staticIP != "" {
-		allocatedIP = net.ParseIP(staticIP)
-		if allocatedIP == nil {
+		static = net.ParseIP(staticIP)
+		if static == nil {
 			return "", "", "", fmt.Errorf("invalid static IP %q", staticIP)
 		}
-		if err := m.ipam.AllocateStatic(ns.def.Name, vethName, allocatedIP); err != nil {
-			return "", "", "", err
+		if ns.subnet6 != nil && static.To4() == nil {
+			static, static6 = nil, static
 		}
-	} else {
-		allocatedIP, err = m.ipam.Allocate(ns.def.Name, vethName)
+	}
+
+	// Allocate an IP via IPAM
+	allocatedIP, err := m.allocateFrom(ctx, ns, ns.def.Name, vethName, hostname, static)
+	if err != nil {
+		return "", "", "", err
+	}
+	var allocatedIP6 net.IP
+	if ns.subnet6 != nil {
+		allocatedIP6, err = m.allocateFrom(ctx, ns, pool6(ns.def.Name), vethName, hostname, static6)
 		if err != nil {
+			m.releaseAddress(ctx, ns.def.Name, vethName, allocatedIP, "allocation failed")
 			return "", "", "", err
 		}
 	}
+	release := func() {
+		m.releaseAddress(ctx, ns.def.Name, vethName, allocatedIP, "allocation failed")
+		if allocatedIP6 != nil {
+			m.releaseAddress(ctx, pool6(ns.def.Name), vethName, allocatedIP6, "allocation failed")
+		}
+	}
 
 	ones, _ := ns.subnet.Mask.Size()
 	ipCIDR := fmt.Sprintf("%s/%d", allocatedIP.String(), ones)
 	gw := ns.gateway.String()
+	addrs, gws := ipCIDR, gw
+	if allocatedIP6 != nil {
+		ones6, _ := ns.subnet6.Mask.Size()
+		addrs += fmt.Sprintf(",%s/%d", allocatedIP6, ones6)
+		gws += "," + ns.gateway6.String()
+	}
 
 	// Create port via network driver
-	if err := m.driver.CreatePort(ctx, vethName, ipCIDR, gw); err != nil {
-		m.ipam.Release(ns.def.Name, vethName)
+	if err := m.driver.CreatePort(ctx, vethName, addrs, gws); err != nil {
+		release()
 		return "", "", "", fmt.Errorf("creating veth %s: %w", vethName, err)
 	}
 
 	// Attach to bridge
 	if err := m.driver.AttachPort(ctx, ns.def.Bridge, vethName); err != nil {
 		_ = m.driver.DeletePort(ctx, vethName)
-		m.ipam.Release(ns.def.Name, vethName)
+		release()
 		return "", "", "", fmt.Errorf("adding %s to bridge %s: %w", vethName, ns.def.Bridge, err)
 	}
 
+	// Networks sharing a VLAN-filtering bridge get an access port on their VLAN
+	if ns.def.VLAN > 0 {
+		if err := m.driver.SetPortVLAN(ctx, vethName, ns.def.VLAN, false); err != nil {
+			_ = m.driver.DeletePort(ctx, vethName)
+			release()
+			return "", "", "", fmt.Errorf("tagging %s with VLAN %d: %w", vethName, ns.def.VLAN, err)
+		}
+	}
+
 	m.allocs[vethName] = &allocation{
 		networkName: ns.def.Name,
 		ip:          allocatedIP,
+		ip6:         allocatedIP6,
 		hostname:    hostname,
 	}
 
@@ -280,30 +317,86 @@ func (m *Manager) AllocateInterface(ctx context.Context, vethName, hostname, net
 	m.state.setPort(&LogicalPort{
 		Name:     vethName,
 		Switch:   ns.def.Name,
-		Address:  ipCIDR,
-		Gateway:  gw,
+		Address:  addrs,
+		Gateway:  gws,
 		Hostname: hostname,
+		VLANTag:  ns.def.VLAN,
 		NodeName: m.driver.NodeName(),
 	})
 	if err := m.state.save(); err != nil {
 		m.log.Warnw("failed to persist network state", "error", err)
 	}
 
-	// Register DNS A record
+	// Register DNS A (and AAAA) records
 	if m.dns != nil && ns.zoneID != "" && hostname != "" {
-		if regErr := m.dns.RegisterHost(ctx, ns.def.DNS.Endpoint, ns.zoneID, hostname, allocatedIP.String(), 60); regErr != nil {
-			m.log.Warnw("failed to register DNS", "hostname", hostname, "ip", allocatedIP, "error", regErr)
+		for _, addr := range []net.IP{allocatedIP, allocatedIP6} {
+			if addr == nil {
+				continue
+			}
+			if regErr := m.dns.RegisterHost(ctx, ns.def.DNS.Endpoint, ns.zoneID, hostname, addr.String(), ns.def.DNS.TTL.TTL(config.DNSTTLPods, 60)); regErr != nil {
+				m.log.Warnw("failed to register DNS", "hostname", hostname, "ip", addr, "error", regErr)
+			}
 		}
 	}
 
 	dnsServerIP := ns.def.DNS.Server
 	m.log.Infow("interface allocated",
-		"veth", vethName, "ip", ipCIDR, "bridge", ns.def.Bridge,
+		"veth", vethName, "ip", addrs, "bridge", ns.def.Bridge, "vlan", ns.def.VLAN,
 		"network", ns.def.Name, "dns", dnsServerIP)
 
 	return ipCIDR, gw, dnsServerIP, nil
 }
 
+// allocateFrom claims static in the named pool, or the next free address
+// when static is nil. Dynamic addresses that are claimed elsewhere in the
+// cluster or answer a duplicate-address probe are skipped.
+func (m *Manager) allocateFrom(ctx context.Context, ns *networkState, pool, key, hostname string, static net.IP) (net.IP, error) {
+	if static != nil {
+		if err := m.checkSlice(ns, pool, static); err != nil {
+			return nil, err
+		}
+		if err := m.ipam.AllocateStatic(pool, key, static); err != nil {
+			return nil, err
+		}
+		if err := m.claimAddress(ctx, ns, pool, static, key, hostname, false); err != nil {
+			m.ipam.Release(pool, key)
+			return nil, err
+		}
+		return static, nil
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < maxClaimAttempts; attempt++ {
+		ip, err := m.ipam.Allocate(pool, key)
+		if err != nil {
+			return nil, err
+		}
+		if lastErr = m.claimAddress(ctx, ns, pool, ip, key, hostname, true); lastErr == nil {
+			return ip, nil
+		}
+		m.ipam.Release(pool, key)
+		m.log.Warnw("skipping unavailable IP", "pool", pool, "ip", ip, "veth", key, "error", lastErr)
+	}
+	return nil, fmt.Errorf("no usable IP in pool %s after %d attempts: %w", pool, maxClaimAttempts, lastErr)
+}
+
+// checkSlice refuses a requested address in another node's slice of a
+// network split between nodes. Claims are only checked against this
+// node's store, so the slices are what keep two nodes from taking the same
+// address. Must be called with m.mu held.
+func (m *Manager) checkSlice(ns *networkState, pool string, ip net.IP) error {
+	if ns.slice != "" && m.ipam.InOtherSlice(pool, ip) {
+		return fmt.Errorf("IP %s is in another node's share of network %s (this node allocates slice %s)", ip, ns.def.Name, ns.slice)
+	}
+	return nil
+}
+
+// releaseAddress frees key's address in the local pool and its NATS claim.
+func (m *Manager) releaseAddress(ctx context.Context, pool, key string, ip net.IP, reason string) {
+	m.ipam.Release(pool, key)
+	m.releaseClaim(ctx, pool, ip, key, reason)
+}
+
 // ReleaseInterface removes the veth, deregisters DNS, and returns the IP.
 func (m *Manager) ReleaseInterface(ctx context.Context, vethName string) error {
 	m.mu.Lock()
@@ -318,6 +411,10 @@ func (m *Manager) ReleaseInterface(ctx context.Context, vethName string) error {
 		}
 	}
 
+	if err := m.clearPortBandwidth(ctx, vethName); err != nil {
+		m.log.Warnw("failed to remove bandwidth shaping", "veth", vethName, "error", err)
+	}
+
 	if err := m.driver.DeletePort(ctx, vethName); err != nil {
 		m.log.Warnw("error removing veth — keeping internal state for retry", "name", vethName, "error", err)
 		return fmt.Errorf("delete veth %s: %w", vethName, err)
@@ -325,8 +422,12 @@ func (m *Manager) ReleaseInterface(ctx context.Context, vethName string) error {
 
 	// Clean up allocation only after the physical veth is gone
 	if alloc, ok := m.allocs[vethName]; ok {
-		m.ipam.Release(alloc.networkName, vethName)
+		m.releaseAddress(ctx, alloc.networkName, vethName, alloc.ip, "interface released")
+		if alloc.ip6 != nil {
+			m.releaseAddress(ctx, pool6(alloc.networkName), vethName, alloc.ip6, "interface released")
+		}
 		delete(m.allocs, vethName)
+		delete(m.conflicts, vethName)
 	} else {
 		// m.allocs may have been cleared (e.g. by a prior release or network
 		// unregister) while the IPAM pool still holds the key. Fall back to
@@ -361,8 +462,26 @@ func (m *Manager) GetPortInfo(vethName string) (ip, networkName string, ok bool)
 	return alloc.ip.String(), alloc.networkName, true
 }
 
-// RegisterDNS registers an additional A record in the named network's DNS zone.
-func (m *Manager) RegisterDNS(ctx context.Context, networkName, hostname, ip string) error {
+// GetPortIPs returns every address (without mask) assigned to a veth,
+// primary family first. Dual-stack ports return an IPv4 and an IPv6 address.
+func (m *Manager) GetPortIPs(vethName string) []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	alloc, exists := m.allocs[vethName]
+	if !exists {
+		return nil
+	}
+	ips := []string{alloc.ip.String()}
+	if alloc.ip6 != nil {
+		ips = append(ips, alloc.ip6.String())
+	}
+	return ips
+}
+
+// RegisterDNS registers an additional A record in the named network's DNS
+// zone. A ttl of 0 uses the network's TTL policy for pods.
+func (m *Manager) RegisterDNS(ctx context.Context, networkName, hostname, ip string, ttl int) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -373,7 +492,31 @@ func (m *Manager) RegisterDNS(ctx context.Context, networkName, hostname, ip str
 	if m.dns == nil || ns.zoneID == "" {
 		return nil
 	}
-	return m.dns.RegisterHost(ctx, ns.def.DNS.Endpoint, ns.zoneID, hostname, ip, 60)
+	if ttl <= 0 {
+		ttl = ns.def.DNS.TTL.TTL(config.DNSTTLPods, 60)
+	}
+	return m.dns.RegisterHost(ctx, ns.def.DNS.Endpoint, ns.zoneID, hostname, ip, ttl)
+}
+
+// SetDNSTTL replaces the named network's TTL policy. Records pick it up
+// when they are next registered.
+func (m *Manager) SetDNSTTL(networkName string, policy config.DNSTTLConfig) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if ns, ok := m.networks[networkName]; ok {
+		ns.def.DNS.TTL = policy
+	}
+}
+
+// DNSTTL returns the named network's TTL policy.
+func (m *Manager) DNSTTL(networkName string) config.DNSTTLConfig {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ns, err := m.resolveNetwork(networkName)
+	if err != nil {
+		return config.DNSTTLConfig{}
+	}
+	return ns.def.DNS.TTL
 }
 
 // CleanStaleDNS removes A records for hostname that don't match the given IP.
@@ -407,9 +550,93 @@ func (m *Manager) DeregisterDNS(ctx context.Context, networkName, hostname, ip s
 	return m.dns.DeregisterHostByIP(ctx, ns.def.DNS.Endpoint, ns.zoneID, hostname, ip)
 }
 
-// GetAllocations returns a snapshot of current IP allocations across all networks.
+// SyncDNSServices makes the SRV records pointing at target (a fully
+// qualified hostname) in the named network's DNS zone exactly srvs.
+func (m *Manager) SyncDNSServices(ctx context.Context, networkName, target string, srvs []dns.SRV) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ns, err := m.resolveNetwork(networkName)
+	if err != nil {
+		return err
+	}
+	if m.dns == nil || ns.zoneID == "" {
+		return nil
+	}
+	return m.dns.SyncSRV(ctx, ns.def.DNS.Endpoint, ns.zoneID, target, srvs, 60)
+}
+
+// SyncDNSText makes the TXT records at hostname in the named network's DNS
+// zone exactly texts.
+func (m *Manager) SyncDNSText(ctx context.Context, networkName, hostname string, texts []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ns, err := m.resolveNetwork(networkName)
+	if err != nil {
+		return err
+	}
+	if m.dns == nil || ns.zoneID == "" {
+		return nil
+	}
+	return m.dns.SyncTXT(ctx, ns.def.DNS.Endpoint, ns.zoneID, hostname, texts, 60)
+}
+
+// GetAllocations returns a snapshot of current IP allocations across all
+// networks as veth -> primary IP. IPv6 addresses of dual-stack ports are
+// reported by GetPortIPs.
 func (m *Manager) GetAllocations() map[string]string {
-	return m.ipam.AllAllocations()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	out := make(map[string]string)
+	for name := range m.networks {
+		for key, ip := range m.ipam.PoolAllocations(name) {
+			out[key] = ip.String()
+		}
+	}
+	return out
+}
+
+// IPAMUsage describes allocation utilization for one network.
+type IPAMUsage struct {
+	Network   string `json:"network"`
+	CIDR      string `json:"cidr"`
+	Allocated int    `json:"allocated"`
+	Capacity  int    `json:"capacity"`
+	Slice     string `json:"slice,omitempty"` // this node's share ("2/3") of an overlay network's range
+}
+
+// GetIPAMUsage returns per-network IPAM utilization in network order.
+func (m *Manager) GetIPAMUsage() []IPAMUsage {
+	out := make([]IPAMUsage, 0, len(m.netOrder))
+	for _, name := range m.netOrder {
+		ns, ok := m.networks[name]
+		if !ok {
+			continue
+		}
+		u, ok := m.ipam.Usage(name)
+		if !ok {
+			continue
+		}
+		out = append(out, IPAMUsage{
+			Network:   name,
+			CIDR:      ns.def.CIDR,
+			Allocated: u.Allocated,
+			Capacity:  u.Capacity,
+			Slice:     ns.slice,
+		})
+		if u6, ok := m.ipam.Usage(pool6(name)); ok {
+			out = append(out, IPAMUsage{
+				Network:   name,
+				CIDR:      ns.def.CIDR6,
+				Allocated: u6.Allocated,
+				Capacity:  u6.Capacity,
+				Slice:     ns.slice,
+			})
+		}
+	}
+	return out
 }
 
 // Networks returns the ordered list of network names.
@@ -464,27 +691,40 @@ func (m *Manager) syncExistingAllocations(ctx context.Context) error {
 		if p.Address == "" {
 			continue
 		}
-		ip, _, err := net.ParseCIDR(p.Address)
-		if err != nil {
-			ip = net.ParseIP(p.Address)
-		}
-		if ip == nil {
-			continue
-		}
 
-		// Find which network this IP belongs to
-		poolName := m.ipam.PoolForIP(ip)
-		if poolName == "" {
-			continue
-		}
+		// Dual-stack ports carry a comma-separated list, primary first
+		var alloc *allocation
+		for _, addr := range strings.Split(p.Address, ",") {
+			addr = strings.TrimSpace(addr)
+			ip, _, err := net.ParseCIDR(addr)
+			if err != nil {
+				ip = net.ParseIP(addr)
+			}
+			if ip == nil {
+				continue
+			}
 
-		m.ipam.Record(poolName, p.Name, ip)
-		m.allocs[p.Name] = &allocation{
-			networkName: poolName,
-			ip:          ip,
-			hostname:    extractHostname(p.Name),
+			// Find which network this IP belongs to
+			poolName := m.ipam.PoolForIP(ip)
+			if poolName == "" {
+				continue
+			}
+			m.ipam.Record(poolName, p.Name, ip)
+
+			networkName, isV6 := strings.CutSuffix(poolName, "/v6")
+			if alloc == nil {
+				alloc = &allocation{networkName: networkName, hostname: extractHostname(p.Name)}
+			}
+			if isV6 {
+				alloc.ip6 = ip
+			} else {
+				alloc.ip = ip
+			}
+			m.log.Debugw("synced existing allocation", "veth", p.Name, "ip", ip, "network", networkName)
+		}
+		if alloc != nil && alloc.ip != nil {
+			m.allocs[p.Name] = alloc
 		}
-		m.log.Debugw("synced existing allocation", "veth", p.Name, "ip", ip, "network", poolName)
 	}
 
 	total := len(m.ipam.AllAllocations())
@@ -505,20 +745,25 @@ func (m *Manager) RegisterNetwork(netDef config.NetworkDef) error {
 		return nil
 	}
 
-	_, subnet, err := net.ParseCIDR(netDef.CIDR)
-	if err != nil {
-		return fmt.Errorf("parsing CIDR %q for network %s: %w", netDef.CIDR, netDef.Name, err)
+	if err := m.addNetwork(netDef); err != nil {
+		return err
 	}
 
-	gateway := make(net.IP, len(subnet.IP))
-	copy(gateway, subnet.IP)
-	gateway[len(gateway)-1] = 1
+	m.log.Infow("registered dynamic network", "name", netDef.Name, "cidr", netDef.CIDR, "bridge", netDef.Bridge)
+	return nil
+}
 
-	if netDef.Gateway != "" {
-		gateway = net.ParseIP(netDef.Gateway)
-		if gateway == nil {
-			return fmt.Errorf("invalid gateway IP %q for network %s", netDef.Gateway, netDef.Name)
-		}
+// addNetwork parses a network definition and registers its state, IPAM
+// pools and logical switch. Must be called with m.mu held (or before the
+// manager is shared).
+func (m *Manager) addNetwork(netDef config.NetworkDef) error {
+	subnet, gateway, err := parseSubnet(netDef.Name, netDef.CIDR, netDef.Gateway)
+	if err != nil {
+		return err
+	}
+	poolOpts, err := parsePoolOpts(netDef.Name, "ipamStart", netDef.IPAMStart, "ipamEnd", netDef.IPAMEnd)
+	if err != nil {
+		return err
 	}
 
 	ns := &networkState{
@@ -527,40 +772,85 @@ func (m *Manager) RegisterNetwork(netDef config.NetworkDef) error {
 		gateway: gateway,
 	}
 
-	m.networks[netDef.Name] = ns
-	m.netOrder = append(m.netOrder, netDef.Name)
-
-	// Register IPAM pool
-	var poolOpts []ipam.PoolOpts
-	if netDef.IPAMStart != "" || netDef.IPAMEnd != "" {
-		o := ipam.PoolOpts{}
-		if netDef.IPAMStart != "" {
-			o.AllocStart = net.ParseIP(netDef.IPAMStart)
-			if o.AllocStart == nil {
-				return fmt.Errorf("invalid ipamStart %q for network %s", netDef.IPAMStart, netDef.Name)
-			}
+	var poolOpts6 []ipam.PoolOpts
+	if netDef.CIDR6 != "" {
+		ns.subnet6, ns.gateway6, err = parseSubnet(netDef.Name, netDef.CIDR6, netDef.Gateway6)
+		if err != nil {
+			return err
 		}
-		if netDef.IPAMEnd != "" {
-			o.AllocEnd = net.ParseIP(netDef.IPAMEnd)
-			if o.AllocEnd == nil {
-				return fmt.Errorf("invalid ipamEnd %q for network %s", netDef.IPAMEnd, netDef.Name)
-			}
+		if ns.subnet6.IP.To4() != nil {
+			return fmt.Errorf("cidr6 %q for network %s is not an IPv6 subnet", netDef.CIDR6, netDef.Name)
+		}
+		poolOpts6, err = parsePoolOpts(netDef.Name, "ipamStart6", netDef.IPAMStart6, "ipamEnd6", netDef.IPAMEnd6)
+		if err != nil {
+			return err
 		}
-		poolOpts = append(poolOpts, o)
 	}
-	m.ipam.AddPool(netDef.Name, subnet, gateway, poolOpts...)
 
-	m.state.setSwitch(&LogicalSwitch{
+	m.networks[netDef.Name] = ns
+	m.netOrder = append(m.netOrder, netDef.Name)
+
+	m.ipam.AddPool(netDef.Name, subnet, gateway, poolOpts...)
+	sw := &LogicalSwitch{
 		Name:    netDef.Name,
 		Bridge:  netDef.Bridge,
 		CIDR:    netDef.CIDR,
 		Gateway: gateway.String(),
-	})
-
-	m.log.Infow("registered dynamic network", "name", netDef.Name, "cidr", netDef.CIDR, "bridge", netDef.Bridge)
+	}
+	if netDef.VLAN > 0 {
+		sw.VLANs = []int{netDef.VLAN}
+	}
+	if ns.subnet6 != nil {
+		m.ipam.AddPool(pool6(netDef.Name), ns.subnet6, ns.gateway6, poolOpts6...)
+		sw.CIDR6 = netDef.CIDR6
+		sw.Gateway6 = ns.gateway6.String()
+	}
+	m.state.setSwitch(sw)
 	return nil
 }
 
+// parseSubnet parses a network CIDR and its gateway, defaulting the gateway
+// to the first host address of the subnet.
+func parseSubnet(name, cidr, gw string) (*net.IPNet, net.IP, error) {
+	_, subnet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parsing CIDR %q for network %s: %w", cidr, name, err)
+	}
+
+	gateway := make(net.IP, len(subnet.IP))
+	copy(gateway, subnet.IP)
+	gateway[len(gateway)-1] = 1
+
+	if gw != "" {
+		gateway = net.ParseIP(gw)
+		if gateway == nil {
+			return nil, nil, fmt.Errorf("invalid gateway IP %q for network %s", gw, name)
+		}
+	}
+	return subnet, gateway, nil
+}
+
+// parsePoolOpts builds the optional IPAM allocation range for a pool.
+func parsePoolOpts(name, startField, start, endField, end string) ([]ipam.PoolOpts, error) {
+	if start == "" && end == "" {
+		return nil, nil
+	}
+	o := ipam.PoolOpts{}
+	if start != "" {
+		o.AllocStart = net.ParseIP(start)
+		if o.AllocStart == nil {
+			return nil, fmt.Errorf("invalid %s %q for network %s", startField, start, name)
+		}
+	}
+	if end != "" {
+		o.AllocEnd = net.ParseIP(end)
+		if o.AllocEnd == nil {
+			return nil, fmt.Errorf("invalid %s %q for network %s", endField, end, name)
+		}
+	}
+	return []ipam.PoolOpts{o}, nil
+}
+
 // UnregisterNetwork removes a dynamically registered network from the manager
 // and IPAM allocator. This is the reverse of RegisterNetwork and is called
 // when a Network CRD is deleted. It is a no-op if the network doesn't exist.
@@ -574,6 +864,7 @@ func (m *Manager) UnregisterNetwork(name string) {
 
 	delete(m.networks, name)
 	m.ipam.RemovePool(name)
+	m.ipam.RemovePool(pool6(name))
 
 	// Remove from ordered list
 	for i, n := range m.netOrder {
@@ -599,6 +890,109 @@ func (m *Manager) UnregisterNetwork(name string) {
 	m.log.Infow("unregistered dynamic network", "name", name)
 }
 
+// EnsureBridges creates every network's bridge with the network's gateway
+// addresses, for drivers that own their bridges (Linux); RouterOS networks
+// are provisioned by the provider instead. Networks sharing a bridge are
+// merged into one CreateBridge call, VLAN networks get their gateway on a
+// VLAN interface, and masquerade networks have their subnets NATed.
+func (m *Manager) EnsureBridges(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	opts := make(map[string]*BridgeOpts)
+	var order []string
+	for _, name := range m.netOrder {
+		ns := m.networks[name]
+		if ns.def.Bridge == "" {
+			continue
+		}
+		o := opts[ns.def.Bridge]
+		if o == nil {
+			o = &BridgeOpts{}
+			opts[ns.def.Bridge] = o
+			order = append(order, ns.def.Bridge)
+		}
+
+		ones, _ := ns.subnet.Mask.Size()
+		addrs := []string{fmt.Sprintf("%s/%d", ns.gateway, ones)}
+		cidrs := []string{ns.subnet.String()}
+		if ns.subnet6 != nil {
+			ones6, _ := ns.subnet6.Mask.Size()
+			addrs = append(addrs, fmt.Sprintf("%s/%d", ns.gateway6, ones6))
+			cidrs = append(cidrs, ns.subnet6.String())
+		}
+		if ns.def.VLAN > 0 {
+			if o.VLANAddresses == nil {
+				o.VLANAddresses = make(map[int][]string)
+			}
+			o.VLANAddresses[ns.def.VLAN] = append(o.VLANAddresses[ns.def.VLAN], addrs...)
+		} else {
+			o.Addresses = append(o.Addresses, addrs...)
+		}
+		if ns.def.Masquerade {
+			o.Masquerade = append(o.Masquerade, cidrs...)
+		}
+	}
+
+	var errs []error
+	for _, br := range order {
+		if err := m.driver.CreateBridge(ctx, br, *opts[br]); err != nil {
+			errs = append(errs, fmt.Errorf("creating bridge %s: %w", br, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// DHCPRelays returns the relays networks with dhcp.serverNetwork need:
+// requests on the network's bridge (or its VLAN interface) go to the
+// server network's DNS server, which hands out the leases. Networks that
+// serve DHCP themselves need none, as their microdns sits on their own
+// bridge.
+func (m *Manager) DHCPRelays() []DHCPRelay {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var relays []DHCPRelay
+	for _, name := range m.netOrder {
+		ns := m.networks[name]
+		dhcp := ns.def.DNS.DHCP
+		if !dhcp.Enabled || dhcp.ServerNetwork == "" || dhcp.ServerNetwork == name || ns.def.Bridge == "" {
+			continue
+		}
+		server, ok := m.networks[dhcp.ServerNetwork]
+		if !ok || server.def.DNS.Server == "" {
+			m.log.Warnw("DHCP server network has no DNS server, not relaying", "network", name, "serverNetwork", dhcp.ServerNetwork)
+			continue
+		}
+		iface := ns.def.Bridge
+		if ns.def.VLAN > 0 {
+			iface = VLANInterfaceName(iface, ns.def.VLAN)
+		}
+		relays = append(relays, DHCPRelay{
+			Interface: iface,
+			Server:    server.def.DNS.Server,
+			Local:     ns.gateway.String(),
+		})
+	}
+	return relays
+}
+
+// SyncDHCPRelays programs the driver with DHCPRelays.
+func (m *Manager) SyncDHCPRelays(ctx context.Context) error {
+	relays := m.DHCPRelays()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	relayer, ok := m.driver.(DHCPRelayer)
+	if !ok {
+		if len(relays) == 0 {
+			return nil
+		}
+		return fmt.Errorf("driver on %s cannot relay DHCP: %w", m.driver.NodeName(), ErrNotSupported)
+	}
+	return relayer.SetDHCPRelays(ctx, relays)
+}
+
 // ResyncAllocations re-queries all veths from the device and fills in any
 // missing IPAM allocations. Idempotent — existing entries are overwritten
 // with the same data. Called during reconcile to ensure IPAM tracks veths
@@ -1,9 +1,13 @@
 package network
 
 import (
+	"context"
+	"fmt"
 	"net"
 	"testing"
 
+	"go.uber.org/zap"
+
 	"github.com/glennswest/mkube/pkg/config"
 	"github.com/glennswest/mkube/pkg/network/ipam"
 )
@@ -85,3 +89,159 @@ func TestExtractHostname(t *testing.T) {
 		}
 	}
 }
+
+// fakeDriver records CreatePort calls and serves a fixed port list.
+type fakeDriver struct {
+	node    string
+	ports   []PortInfo
+	created map[string][2]string  // name -> {address, gateway}
+	inUse   map[string]bool       // addresses that answer ProbeAddress
+	tagged  map[string]int        // port -> untagged VLAN from SetPortVLAN
+	tunnels map[string]TunnelSpec // live tunnels from CreateTunnel
+	bridged map[string]string     // tunnel -> bridge from AttachPort
+	shaping map[string]shapedPort // port -> shaping from SetPortBandwidth
+	sets    int                   // SetPortBandwidth calls
+}
+
+func (d *fakeDriver) CreateBridge(context.Context, string, BridgeOpts) error { return nil }
+func (d *fakeDriver) DeleteBridge(context.Context, string) error             { return nil }
+func (d *fakeDriver) ListBridges(context.Context) ([]BridgeInfo, error)      { return nil, nil }
+func (d *fakeDriver) CreatePort(_ context.Context, name, address, gateway string) error {
+	d.created[name] = [2]string{address, gateway}
+	return nil
+}
+func (d *fakeDriver) DeletePort(context.Context, string) error          { return nil }
+func (d *fakeDriver) DetachPort(context.Context, string, string) error  { return nil }
+func (d *fakeDriver) ListPorts(context.Context) ([]PortInfo, error)     { return d.ports, nil }
+func (d *fakeDriver) RemovePortVLAN(context.Context, string, int) error { return nil }
+func (d *fakeDriver) NodeName() string                                  { return d.node }
+func (d *fakeDriver) AttachPort(_ context.Context, bridge, port string) error {
+	if _, ok := d.tunnels[port]; ok {
+		d.bridged[port] = bridge
+	}
+	return nil
+}
+func (d *fakeDriver) CreateTunnel(_ context.Context, name string, spec TunnelSpec) error {
+	if _, ok := d.tunnels[name]; ok {
+		return fmt.Errorf("tunnel %s exists", name)
+	}
+	d.tunnels[name] = spec
+	return nil
+}
+func (d *fakeDriver) DeleteTunnel(_ context.Context, name string) error {
+	delete(d.tunnels, name)
+	delete(d.bridged, name)
+	return nil
+}
+func (d *fakeDriver) Capabilities() DriverCapabilities {
+	return DriverCapabilities{Tunnels: true, TunnelType: "vxlan", WireGuard: true}
+}
+func (d *fakeDriver) SetPortVLAN(_ context.Context, port string, vid int, tagged bool) error {
+	if !tagged {
+		d.tagged[port] = vid
+	}
+	return nil
+}
+func (d *fakeDriver) SetPortBandwidth(_ context.Context, port, ip string, limit Bandwidth) error {
+	d.sets++
+	d.shaping[port] = shapedPort{ip: ip, limit: limit}
+	return nil
+}
+func (d *fakeDriver) ClearPortBandwidth(_ context.Context, port string) error {
+	delete(d.shaping, port)
+	return nil
+}
+func (d *fakeDriver) PortRates(context.Context, string) (Bandwidth, error) {
+	return Bandwidth{Ingress: 4000, Egress: 1000}, nil
+}
+func (d *fakeDriver) ProbeAddress(_ context.Context, _, ip string) (bool, error) {
+	return d.inUse[ip], nil
+}
+
+func TestAllocateInterfaceDualStack(t *testing.T) {
+	drv := &fakeDriver{
+		created: make(map[string][2]string),
+		ports: []PortInfo{
+			{Name: "veth_g10_old_0", Address: "192.168.10.2/24,fd00:10::2/64"},
+		},
+	}
+	mgr, err := NewManager([]config.NetworkDef{{
+		Name:     "g10",
+		CIDR:     "192.168.10.0/24",
+		Gateway:  "192.168.10.1",
+		CIDR6:    "fd00:10::/64",
+		Gateway6: "fd00:10::1",
+	}}, drv, nil, zap.NewNop().Sugar())
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+
+	// Both addresses of the existing port were synced
+	if got := mgr.GetPortIPs("veth_g10_old_0"); len(got) != 2 || got[1] != "fd00:10::2" {
+		t.Fatalf("synced port IPs = %v", got)
+	}
+
+	ctx := context.Background()
+	ip, gw, _, err := mgr.AllocateInterface(ctx, "veth_g10_web_0", "web", "g10", "")
+	if err != nil {
+		t.Fatalf("AllocateInterface: %v", err)
+	}
+	if ip != "192.168.10.3/24" || gw != "192.168.10.1" {
+		t.Errorf("primary = %s via %s, want 192.168.10.3/24 via 192.168.10.1", ip, gw)
+	}
+	want := [2]string{"192.168.10.3/24,fd00:10::3/64", "192.168.10.1,fd00:10::1"}
+	if got := drv.created["veth_g10_web_0"]; got != want {
+		t.Errorf("CreatePort(%q, %q), want %q", got[0], got[1], want)
+	}
+
+	// A static IPv6 address pins only the IPv6 side
+	if _, _, _, err := mgr.AllocateInterface(ctx, "veth_g10_db_0", "db", "g10", "fd00:10::53"); err != nil {
+		t.Fatalf("AllocateInterface static v6: %v", err)
+	}
+	if got := mgr.GetPortIPs("veth_g10_db_0"); len(got) != 2 || got[0] != "192.168.10.4" || got[1] != "fd00:10::53" {
+		t.Errorf("static v6 port IPs = %v", got)
+	}
+	if allocs := mgr.GetAllocations(); allocs["veth_g10_db_0"] != "192.168.10.4" {
+		t.Errorf("GetAllocations should report the primary IP, got %q", allocs["veth_g10_db_0"])
+	}
+
+	if err := mgr.ReleaseInterface(ctx, "veth_g10_web_0"); err != nil {
+		t.Fatalf("ReleaseInterface: %v", err)
+	}
+	if got := mgr.ipam.Get(pool6("g10"), "veth_g10_web_0"); got != nil {
+		t.Errorf("IPv6 address %s not released", got)
+	}
+
+	usage := mgr.GetIPAMUsage()
+	if len(usage) != 2 || usage[1].CIDR != "fd00:10::/64" || usage[1].Allocated != 2 {
+		t.Errorf("IPAM usage = %+v", usage)
+	}
+}
+
+func TestAllocateInterfaceVLAN(t *testing.T) {
+	drv := &fakeDriver{created: make(map[string][2]string), tagged: make(map[string]int)}
+	mgr, err := NewManager([]config.NetworkDef{
+		{Name: "lab", Bridge: "trunk", CIDR: "10.60.0.0/24", Gateway: "10.60.0.1", VLAN: 60},
+		{Name: "dmz", Bridge: "trunk", CIDR: "10.70.0.0/24", Gateway: "10.70.0.1", VLAN: 70},
+		{Name: "flat", Bridge: "br-flat", CIDR: "10.80.0.0/24", Gateway: "10.80.0.1"},
+	}, drv, nil, zap.NewNop().Sugar())
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+
+	ctx := context.Background()
+	for veth, netName := range map[string]string{"veth_lab_a_0": "lab", "veth_dmz_b_0": "dmz", "veth_flat_c_0": "flat"} {
+		if _, _, _, err := mgr.AllocateInterface(ctx, veth, "c", netName, ""); err != nil {
+			t.Fatalf("AllocateInterface %s: %v", veth, err)
+		}
+	}
+	want := map[string]int{"veth_lab_a_0": 60, "veth_dmz_b_0": 70}
+	if len(drv.tagged) != len(want) {
+		t.Errorf("tagged ports = %v, want %v", drv.tagged, want)
+	}
+	for veth, vid := range want {
+		if drv.tagged[veth] != vid {
+			t.Errorf("%s tagged with VLAN %d, want %d", veth, drv.tagged[veth], vid)
+		}
+	}
+}
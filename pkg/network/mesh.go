@@ -0,0 +1,419 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"sort"
+	"strings"
+)
+
+// The overlay mesh stretches a network across cluster nodes: every node
+// builds a point-to-point tunnel to every healthy peer for each spanning
+// network and bridges it into the network's bridge. Tunnel ports share a
+// split horizon so the full mesh cannot loop, and each node allocates from
+// its own slice of the network's IPAM range so nodes never pick the same
+// address; their IPAM claims live in separate stores and never meet.
+//
+// With a WireGuard key configured, each node also keeps an encrypted
+// point-to-point site link to every peer that has published a public key,
+// routing the CIDRs of the networks behind that peer through it.
+
+// MeshPeer is a remote node that spanning networks are tunnelled to.
+type MeshPeer struct {
+	Name       string   // node name
+	Address    string   // underlay IP the peer's tunnel endpoints listen on
+	PublicKey  string   // peer's WireGuard public key; empty = no site link
+	AllowedIPs []string // CIDRs routed through the site link to the peer
+}
+
+// MeshConfig is the cluster view ReconcileMesh builds the overlay from.
+type MeshConfig struct {
+	LocalNode    string     // this node's name
+	LocalAddress string     // this node's underlay IP
+	Nodes        []string   // every cluster node, up or not; fixes each node's IPAM slice
+	Peers        []MeshPeer // peers that are up; tunnels to anyone else are torn down
+	Networks     []string   // networks to span (spanNodes: true)
+
+	WireGuardKey  string // this node's base64 private key; empty disables site links
+	WireGuardPort int    // base UDP port of site links (0 = DefaultWireGuardPort)
+}
+
+// Tunnel IDs, WireGuard ports and interface names start from a hash of
+// what they identify and probe to the next free value on a collision.
+const (
+	maxOverlayVNI     = 65534 // EoIP tunnel-id is 16 bits; 0 and 65535 are kept out
+	wireGuardPortSpan = 1000  // site link ports are base..base+999
+)
+
+// meshIDs holds the collision-free tunnel IDs, site link ports and local
+// interface names of one mesh pass.
+type meshIDs struct {
+	vnis  map[string]int    // network/nodeA/nodeB (sorted pair) -> tunnel ID
+	ports map[string]int    // nodeA/nodeB (sorted pair) -> WireGuard UDP port
+	names map[string]string // network/peer, or peer for site links -> interface name
+}
+
+// planMeshIDs assigns the tunnel IDs and ports of every node pair in the
+// cluster, not only this node's, so that both ends of a pair, working from
+// the same nodes and networks, arrive at the same values. Interface names
+// are local and only cover this node's tunnels; they are hashed to stay
+// within the 15-byte Linux limit.
+func planMeshIDs(cfg MeshConfig, networks []string) (*meshIDs, error) {
+	seen := map[string]bool{cfg.LocalNode: true}
+	nodes := []string{cfg.LocalNode}
+	for _, n := range cfg.Nodes {
+		if !seen[n] {
+			seen[n] = true
+			nodes = append(nodes, n)
+		}
+	}
+	for _, peer := range cfg.Peers {
+		if !seen[peer.Name] {
+			seen[peer.Name] = true
+			nodes = append(nodes, peer.Name)
+		}
+	}
+	sort.Strings(nodes)
+	var pairs []string
+	for i := range nodes {
+		for j := i + 1; j < len(nodes); j++ {
+			pairs = append(pairs, nodes[i]+"/"+nodes[j])
+		}
+	}
+
+	ids := &meshIDs{vnis: make(map[string]int), ports: make(map[string]int), names: make(map[string]string)}
+	var vniKeys, ovlKeys []string
+	for _, network := range networks {
+		for _, pair := range pairs {
+			vniKeys = append(vniKeys, network+"/"+pair)
+		}
+		for _, peer := range cfg.Peers {
+			ovlKeys = append(ovlKeys, network+"/"+peer.Name)
+		}
+	}
+	vnis, err := probeSlots(vniKeys, maxOverlayVNI)
+	if err != nil {
+		return nil, fmt.Errorf("assigning overlay tunnel IDs: %w", err)
+	}
+	for key, slot := range vnis {
+		ids.vnis[key] = int(slot) + 1
+	}
+	for key, slot := range mustProbeNames(ovlKeys) {
+		ids.names[key] = fmt.Sprintf("ovl-%08x", slot)
+	}
+
+	if cfg.WireGuardKey != "" {
+		base := cfg.WireGuardPort
+		if base <= 0 {
+			base = DefaultWireGuardPort
+		}
+		ports, err := probeSlots(pairs, wireGuardPortSpan)
+		if err != nil {
+			return nil, fmt.Errorf("assigning WireGuard ports: %w", err)
+		}
+		for key, slot := range ports {
+			ids.ports[key] = base + int(slot)
+		}
+		var wgKeys []string
+		for _, peer := range cfg.Peers {
+			wgKeys = append(wgKeys, peer.Name)
+		}
+		for key, slot := range mustProbeNames(wgKeys) {
+			ids.names[key] = fmt.Sprintf("wg-%08x", slot)
+		}
+	}
+	return ids, nil
+}
+
+// vni returns the tunnel ID of network between nodeA and nodeB.
+func (ids *meshIDs) vni(network, nodeA, nodeB string) int {
+	return ids.vnis[network+"/"+nodePair(nodeA, nodeB)]
+}
+
+// port returns the WireGuard port of the site link between nodeA and nodeB.
+func (ids *meshIDs) port(nodeA, nodeB string) int {
+	return ids.ports[nodePair(nodeA, nodeB)]
+}
+
+// overlayName returns the interface name of the tunnel carrying network to
+// peer.
+func (ids *meshIDs) overlayName(network, peer string) string {
+	return ids.names[network+"/"+peer]
+}
+
+// siteLinkName returns the interface name of the site link to peer.
+func (ids *meshIDs) siteLinkName(peer string) string {
+	return ids.names[peer]
+}
+
+func nodePair(nodeA, nodeB string) string {
+	if nodeB < nodeA {
+		nodeA, nodeB = nodeB, nodeA
+	}
+	return nodeA + "/" + nodeB
+}
+
+// probeSlots gives each key its own slot in [0, size): the key's hash, or
+// the next free slot after it when an earlier key already holds it. Keys are
+// taken in sorted order, so every node holding the same keys assigns the
+// same slots, and a key only moves when a new key collides with it.
+func probeSlots(keys []string, size uint64) (map[string]uint64, error) {
+	sorted := append([]string(nil), keys...)
+	sort.Strings(sorted)
+	out := make(map[string]uint64, len(sorted))
+	taken := make(map[uint64]bool, len(sorted))
+	for _, key := range sorted {
+		if _, ok := out[key]; ok {
+			continue
+		}
+		if uint64(len(taken)) >= size {
+			return nil, fmt.Errorf("%d values needed, only %d available", len(sorted), size)
+		}
+		slot := uint64(crc32.ChecksumIEEE([]byte(key))) % size
+		for taken[slot] {
+			slot = (slot + 1) % size
+		}
+		taken[slot] = true
+		out[key] = slot
+	}
+	return out, nil
+}
+
+// mustProbeNames is probeSlots over the full 32-bit hash space, which a
+// node's own tunnels cannot exhaust.
+func mustProbeNames(keys []string) map[string]uint64 {
+	slots, _ := probeSlots(keys, 1<<32)
+	return slots
+}
+
+// ReconcileMesh brings the node's overlay tunnels and IPAM slices in line
+// with cfg: tunnels to new peers are created and bridged, tunnels to peers
+// that left (or of networks that stopped spanning) are torn down, and
+// spanning networks allocate only from this node's slice. WireGuard site
+// links follow the peers' published keys the same way. Errors for
+// individual tunnels are collected; the rest of the mesh is still built.
+func (m *Manager) ReconcileMesh(ctx context.Context, cfg MeshConfig) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	caps := m.driver.Capabilities()
+	var errs []error
+	networks := cfg.Networks
+	if len(networks) > 0 && (!caps.Tunnels || caps.TunnelType == "") {
+		errs = append(errs, fmt.Errorf("driver on %s cannot build overlay tunnels: %w", cfg.LocalNode, ErrNotSupported))
+		networks = nil
+	}
+
+	ids, err := planMeshIDs(cfg, networks)
+	if err != nil {
+		// Leave the current tunnels up rather than renumber them
+		return errors.Join(append(errs, err)...)
+	}
+
+	spanning := make(map[string]bool, len(networks))
+	desired := make(map[string]Tunnel)
+	if cfg.WireGuardKey != "" {
+		if err := m.desiredSiteLinks(cfg, caps, ids, desired); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, name := range networks {
+		ns, ok := m.networks[name]
+		if !ok {
+			continue
+		}
+		spanning[name] = true
+		if err := m.applyNodeSlice(ns, cfg.LocalNode, cfg.Nodes); err != nil {
+			errs = append(errs, err)
+		}
+		for _, peer := range cfg.Peers {
+			t := Tunnel{
+				Name:       ids.overlayName(name, peer.Name),
+				Type:       caps.TunnelType,
+				LocalNode:  cfg.LocalNode,
+				RemoteNode: peer.Name,
+				LocalIP:    cfg.LocalAddress,
+				RemoteIP:   peer.Address,
+				VNI:        ids.vni(name, cfg.LocalNode, peer.Name),
+				Network:    name,
+				Bridge:     ns.def.Bridge,
+			}
+			desired[t.Name] = t
+		}
+	}
+
+	// Networks that stopped spanning get their full range back
+	for name, ns := range m.networks {
+		if ns.slice != "" && !spanning[name] {
+			if err := m.applyNodeSlice(ns, cfg.LocalNode, nil); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	for name, t := range m.tunnels {
+		if want, ok := desired[name]; ok && want == *t {
+			continue
+		}
+		if err := m.teardownTunnel(ctx, t); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		delete(m.tunnels, name)
+	}
+
+	names := make([]string, 0, len(desired))
+	for name := range desired {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, ok := m.tunnels[name]; ok {
+			continue
+		}
+		t := desired[name]
+		if err := m.buildTunnel(ctx, &t, cfg.WireGuardKey); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		m.tunnels[name] = &t
+	}
+	return errors.Join(errs...)
+}
+
+// Tunnels returns the overlay tunnels this node has built, sorted by name.
+func (m *Manager) Tunnels() []Tunnel {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	out := make([]Tunnel, 0, len(m.tunnels))
+	for _, t := range m.tunnels {
+		out = append(out, *t)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// desiredSiteLinks adds a WireGuard site link for every peer with a public
+// key to desired. A rotated key on either end changes the link, so it is
+// rebuilt with the new keys. Must be called with m.mu held.
+func (m *Manager) desiredSiteLinks(cfg MeshConfig, caps DriverCapabilities, ids *meshIDs, desired map[string]Tunnel) error {
+	if !caps.WireGuard {
+		return fmt.Errorf("driver on %s cannot build WireGuard site links: %w", cfg.LocalNode, ErrNotSupported)
+	}
+	localKey, err := WireGuardPublicKey(cfg.WireGuardKey)
+	if err != nil {
+		return err
+	}
+	for _, peer := range cfg.Peers {
+		if peer.PublicKey == "" {
+			continue
+		}
+		allowed := append([]string(nil), peer.AllowedIPs...)
+		sort.Strings(allowed)
+		t := Tunnel{
+			Name:       ids.siteLinkName(peer.Name),
+			Type:       "wireguard",
+			LocalNode:  cfg.LocalNode,
+			RemoteNode: peer.Name,
+			LocalIP:    cfg.LocalAddress,
+			RemoteIP:   peer.Address,
+			ListenPort: ids.port(cfg.LocalNode, peer.Name),
+			LocalKey:   localKey,
+			RemoteKey:  peer.PublicKey,
+			AllowedIPs: strings.Join(allowed, ","),
+		}
+		desired[t.Name] = t
+	}
+	return nil
+}
+
+// buildTunnel creates t, bridges it into its network's bridge and isolates
+// it from the other tunnel ports. A leftover interface of the same name
+// (from before a restart) is removed first. wgKey is the local private key
+// for WireGuard site links. Must be called with m.mu held.
+func (m *Manager) buildTunnel(ctx context.Context, t *Tunnel, wgKey string) error {
+	_ = m.driver.DeleteTunnel(ctx, t.Name)
+
+	spec := TunnelSpec{Type: t.Type, LocalIP: t.LocalIP, RemoteIP: t.RemoteIP, VNI: t.VNI}
+	if t.Type == "wireguard" {
+		spec.PrivateKey = wgKey
+		spec.PeerPublicKey = t.RemoteKey
+		spec.ListenPort = t.ListenPort
+		if t.AllowedIPs != "" {
+			spec.AllowedIPs = strings.Split(t.AllowedIPs, ",")
+		}
+	}
+	if err := m.driver.CreateTunnel(ctx, t.Name, spec); err != nil {
+		if t.Network == "" {
+			return fmt.Errorf("creating %s site link %s to %s: %w", t.Type, t.Name, t.RemoteNode, err)
+		}
+		return fmt.Errorf("creating tunnel %s to %s for network %s: %w", t.Name, t.RemoteNode, t.Network, err)
+	}
+	if t.Bridge != "" {
+		if err := m.driver.AttachPort(ctx, t.Bridge, t.Name); err != nil {
+			_ = m.driver.DeleteTunnel(ctx, t.Name)
+			return fmt.Errorf("bridging tunnel %s into %s: %w", t.Name, t.Bridge, err)
+		}
+		if iso, ok := m.driver.(PortIsolator); ok {
+			if err := iso.IsolatePort(ctx, t.Bridge, t.Name); err != nil {
+				m.log.Warnw("failed to isolate overlay tunnel port", "tunnel", t.Name, "bridge", t.Bridge, "error", err)
+			}
+		}
+	}
+
+	m.log.Infow("overlay tunnel up", "tunnel", t.Name, "network", t.Network, "peer", t.RemoteNode,
+		"remote", t.RemoteIP, "type", t.Type, "vni", t.VNI)
+	return nil
+}
+
+// teardownTunnel unbridges and deletes t. Must be called with m.mu held.
+func (m *Manager) teardownTunnel(ctx context.Context, t *Tunnel) error {
+	if t.Bridge != "" {
+		if err := m.driver.DetachPort(ctx, t.Bridge, t.Name); err != nil {
+			m.log.Warnw("failed to detach overlay tunnel", "tunnel", t.Name, "bridge", t.Bridge, "error", err)
+		}
+	}
+	if err := m.driver.DeleteTunnel(ctx, t.Name); err != nil {
+		return fmt.Errorf("deleting tunnel %s to %s: %w", t.Name, t.RemoteNode, err)
+	}
+	m.log.Infow("overlay tunnel down", "tunnel", t.Name, "network", t.Network, "peer", t.RemoteNode)
+	return nil
+}
+
+// applyNodeSlice narrows a network's IPAM pools to this node's slice: the
+// node's position among the sorted cluster nodes picks one of len(nodes)
+// equal parts of the range. A node missing from nodes (or a nil list)
+// restores the full range. Must be called with m.mu held.
+func (m *Manager) applyNodeSlice(ns *networkState, local string, nodes []string) error {
+	sorted := append([]string(nil), nodes...)
+	sort.Strings(sorted)
+	index, count := sort.SearchStrings(sorted, local), len(sorted)
+	if index >= count || sorted[index] != local {
+		index, count = 0, 1
+	}
+
+	slice := ""
+	if count > 1 {
+		slice = fmt.Sprintf("%d/%d", index+1, count)
+	}
+	if slice == ns.slice {
+		return nil
+	}
+
+	name := ns.def.Name
+	start, end, err := m.ipam.SetSlice(name, index, count)
+	if err != nil {
+		return fmt.Errorf("splitting IPAM range of %s: %w", name, err)
+	}
+	if ns.subnet6 != nil {
+		if _, _, err := m.ipam.SetSlice(pool6(name), index, count); err != nil {
+			return fmt.Errorf("splitting IPv6 IPAM range of %s: %w", name, err)
+		}
+	}
+	ns.slice = slice
+	m.log.Infow("IPAM range set for overlay network", "network", name, "slice", slice, "start", start, "end", end)
+	return nil
+}
@@ -0,0 +1,253 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/glennswest/mkube/pkg/config"
+)
+
+func TestReconcileMesh(t *testing.T) {
+	drv := &fakeDriver{
+		node:    "rose1",
+		created: make(map[string][2]string),
+		tunnels: make(map[string]TunnelSpec),
+		bridged: make(map[string]string),
+	}
+	mgr, err := NewManager([]config.NetworkDef{
+		{Name: "lab", Bridge: "br-lab", CIDR: "10.60.0.0/24", Gateway: "10.60.0.1",
+			IPAMStart: "10.60.0.10", IPAMEnd: "10.60.0.99"},
+		{Name: "local", Bridge: "br-local", CIDR: "10.70.0.0/24", Gateway: "10.70.0.1"},
+	}, drv, nil, zap.NewNop().Sugar())
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	ctx := context.Background()
+
+	cfg := MeshConfig{
+		LocalNode:    "rose1",
+		LocalAddress: "192.168.1.1",
+		Nodes:        []string{"rose1", "pvex", "stormx"},
+		Peers:        []MeshPeer{{Name: "pvex", Address: "192.168.1.160"}, {Name: "stormx", Address: "192.168.1.170"}},
+		Networks:     []string{"lab"},
+	}
+	if err := mgr.ReconcileMesh(ctx, cfg); err != nil {
+		t.Fatalf("ReconcileMesh: %v", err)
+	}
+
+	// One tunnel per peer, bridged into the network's bridge
+	ids, _ := planMeshIDs(cfg, cfg.Networks)
+	toPvex := ids.overlayName("lab", "pvex")
+	if len(drv.tunnels) != 2 || drv.bridged[toPvex] != "br-lab" {
+		t.Fatalf("tunnels = %v, bridged = %v", drv.tunnels, drv.bridged)
+	}
+	spec := drv.tunnels[toPvex]
+	if spec.Type != "vxlan" || spec.LocalIP != "192.168.1.1" || spec.RemoteIP != "192.168.1.160" {
+		t.Errorf("tunnel to pvex = %+v", spec)
+	}
+	// Both ends of a pair agree on the tunnel ID
+	peerIDs, _ := planMeshIDs(MeshConfig{
+		LocalNode: "pvex",
+		Nodes:     cfg.Nodes,
+		Peers:     []MeshPeer{{Name: "rose1"}, {Name: "stormx"}},
+	}, cfg.Networks)
+	if spec.VNI != peerIDs.vni("lab", "pvex", "rose1") {
+		t.Errorf("VNI %d differs from the peer's view", spec.VNI)
+	}
+
+	// rose1 sorts second of three nodes, so it gets the middle third of .10-.99
+	ip, _, _, err := mgr.AllocateInterface(ctx, "veth_lab_web_0", "web", "lab", "")
+	if err != nil || ip != "10.60.0.40/24" {
+		t.Errorf("allocation on spanning network = %s, %v; want 10.60.0.40/24", ip, err)
+	}
+	if u := mgr.GetIPAMUsage(); u[0].Slice != "2/3" || u[0].Capacity != 30 {
+		t.Errorf("IPAM usage of spanning network = %+v", u[0])
+	}
+	if ip, _, _, _ := mgr.AllocateInterface(ctx, "veth_local_db_0", "db", "local", ""); ip != "10.70.0.2/24" {
+		t.Errorf("non-spanning network allocation = %s, want 10.70.0.2/24", ip)
+	}
+
+	// Reconciling again changes nothing
+	if err := mgr.ReconcileMesh(ctx, cfg); err != nil {
+		t.Fatalf("second ReconcileMesh: %v", err)
+	}
+	if len(mgr.Tunnels()) != 2 {
+		t.Errorf("tunnels after no-op reconcile = %v", mgr.Tunnels())
+	}
+
+	// A peer leaving takes its tunnel down
+	cfg.Peers = cfg.Peers[1:]
+	if err := mgr.ReconcileMesh(ctx, cfg); err != nil {
+		t.Fatalf("ReconcileMesh after peer left: %v", err)
+	}
+	if _, ok := drv.tunnels[toPvex]; ok || len(drv.tunnels) != 1 {
+		t.Errorf("tunnels after pvex left = %v", drv.tunnels)
+	}
+
+	// Stopping the span removes the rest and restores the full range
+	cfg.Networks = nil
+	if err := mgr.ReconcileMesh(ctx, cfg); err != nil {
+		t.Fatalf("ReconcileMesh without spanning networks: %v", err)
+	}
+	if len(drv.tunnels) != 0 || len(mgr.Tunnels()) != 0 {
+		t.Errorf("tunnels left behind: %v", drv.tunnels)
+	}
+	if u := mgr.GetIPAMUsage(); u[0].Slice != "" || u[0].Capacity != 90 {
+		t.Errorf("IPAM usage after the span ended = %+v", u[0])
+	}
+}
+
+func TestReconcileMeshSiteLinks(t *testing.T) {
+	drv := &fakeDriver{
+		node:    "rose1",
+		created: make(map[string][2]string),
+		tunnels: make(map[string]TunnelSpec),
+		bridged: make(map[string]string),
+	}
+	mgr, err := NewManager(nil, drv, nil, zap.NewNop().Sugar())
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	ctx := context.Background()
+
+	localPriv, localPub, err := GenerateWireGuardKey()
+	if err != nil {
+		t.Fatalf("GenerateWireGuardKey: %v", err)
+	}
+	if pub, err := WireGuardPublicKey(localPriv); err != nil || pub != localPub {
+		t.Fatalf("WireGuardPublicKey = %s, %v; want %s", pub, err, localPub)
+	}
+	_, peerPub, _ := GenerateWireGuardKey()
+
+	cfg := MeshConfig{
+		LocalNode:    "rose1",
+		LocalAddress: "192.168.1.1",
+		Nodes:        []string{"rose1", "pvex", "stormx"},
+		Peers: []MeshPeer{
+			{Name: "pvex", Address: "192.168.1.160", PublicKey: peerPub, AllowedIPs: []string{"10.80.0.0/24", "fd00:80::/64"}},
+			{Name: "stormx", Address: "192.168.1.170"}, // no key published yet
+		},
+		WireGuardKey: localPriv,
+	}
+	if err := mgr.ReconcileMesh(ctx, cfg); err != nil {
+		t.Fatalf("ReconcileMesh: %v", err)
+	}
+
+	ids, _ := planMeshIDs(cfg, nil)
+	link := ids.siteLinkName("pvex")
+	spec, ok := drv.tunnels[link]
+	if len(drv.tunnels) != 1 || !ok {
+		t.Fatalf("tunnels = %v, want one site link to pvex", drv.tunnels)
+	}
+	if spec.Type != "wireguard" || spec.PrivateKey != localPriv || spec.PeerPublicKey != peerPub ||
+		spec.RemoteIP != "192.168.1.160" || len(spec.AllowedIPs) != 2 {
+		t.Errorf("site link spec = %+v", spec)
+	}
+	// Both ends of a pair agree on the port
+	peerIDs, _ := planMeshIDs(MeshConfig{
+		LocalNode:    "pvex",
+		Nodes:        cfg.Nodes,
+		Peers:        []MeshPeer{{Name: "rose1"}, {Name: "stormx"}},
+		WireGuardKey: "peer",
+	}, nil)
+	if spec.ListenPort != peerIDs.port("pvex", "rose1") || spec.ListenPort < DefaultWireGuardPort {
+		t.Errorf("listen port %d differs from the peer's view", spec.ListenPort)
+	}
+	if _, ok := drv.bridged[link]; ok {
+		t.Error("site link was bridged")
+	}
+
+	// A rotated peer key rebuilds the link with the new key
+	_, rotated, _ := GenerateWireGuardKey()
+	cfg.Peers[0].PublicKey = rotated
+	if err := mgr.ReconcileMesh(ctx, cfg); err != nil {
+		t.Fatalf("ReconcileMesh after rotation: %v", err)
+	}
+	if got := drv.tunnels[link].PeerPublicKey; got != rotated {
+		t.Errorf("peer key after rotation = %s, want %s", got, rotated)
+	}
+
+	// Disabling WireGuard tears the link down
+	cfg.WireGuardKey = ""
+	if err := mgr.ReconcileMesh(ctx, cfg); err != nil {
+		t.Fatalf("ReconcileMesh without key: %v", err)
+	}
+	if len(drv.tunnels) != 0 || len(mgr.Tunnels()) != 0 {
+		t.Errorf("site links left after disabling WireGuard: %v", drv.tunnels)
+	}
+}
+
+func TestPlanMeshIDsCollisionFree(t *testing.T) {
+	// 40 nodes make 780 pairs in 1000 ports, so plain hashing collides
+	var nodes []string
+	for i := 0; i < 40; i++ {
+		nodes = append(nodes, fmt.Sprintf("node%02d", i))
+	}
+	networks := []string{"lab", "storage"}
+	plan := func(local string) *meshIDs {
+		cfg := MeshConfig{LocalNode: local, Nodes: nodes, WireGuardKey: "key"}
+		for i := len(nodes) - 1; i >= 0; i-- { // any peer order
+			if nodes[i] != local {
+				cfg.Peers = append(cfg.Peers, MeshPeer{Name: nodes[i]})
+			}
+		}
+		ids, err := planMeshIDs(cfg, networks)
+		if err != nil {
+			t.Fatalf("planMeshIDs(%s): %v", local, err)
+		}
+		return ids
+	}
+
+	ids := plan("node00")
+	ports := make(map[int]string)
+	vnis := make(map[int]string)
+	for i, a := range nodes {
+		for _, b := range nodes[i+1:] {
+			port := ids.port(a, b)
+			if other, dup := ports[port]; dup {
+				t.Fatalf("port %d shared by %s/%s and %s", port, a, b, other)
+			}
+			if port < DefaultWireGuardPort || port >= DefaultWireGuardPort+wireGuardPortSpan {
+				t.Fatalf("port %d of %s/%s out of range", port, a, b)
+			}
+			ports[port] = a + "/" + b
+			for _, n := range networks {
+				vni := ids.vni(n, a, b)
+				if other, dup := vnis[vni]; dup || vni < 1 || vni > maxOverlayVNI {
+					t.Fatalf("VNI %d of %s %s/%s invalid or shared with %s", vni, n, a, b, other)
+				}
+				vnis[vni] = n + "/" + a + "/" + b
+			}
+		}
+	}
+
+	// Every node arrives at the same values for its pairs
+	for _, local := range nodes[1:] {
+		other := plan(local)
+		for _, peer := range nodes {
+			if peer == local {
+				continue
+			}
+			if other.port(local, peer) != ids.port(local, peer) || other.vni("lab", peer, local) != ids.vni("lab", local, peer) {
+				t.Fatalf("%s and node00 disagree on the pair %s/%s", local, local, peer)
+			}
+		}
+	}
+
+	// Local interface names are distinct too
+	names := make(map[string]bool)
+	for key, name := range ids.names {
+		if names[name] || len(name) > 15 {
+			t.Fatalf("interface name %q of %s duplicated or too long", name, key)
+		}
+		names[name] = true
+	}
+
+	// Running out of values is an error, not a silent reuse
+	if _, err := probeSlots([]string{"a", "b", "c"}, 2); err == nil {
+		t.Error("probeSlots reused a slot")
+	}
+}
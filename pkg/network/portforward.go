@@ -0,0 +1,131 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// portForwardTag prefixes the comment of every NAT rule mkube owns.
+const portForwardTag = "mkube-pf: "
+
+// PortForward is a destination-NAT rule: traffic for ExternalIP:ExternalPort
+// arriving on Interface is sent to TargetIP:TargetPort.
+type PortForward struct {
+	Owner        string `json:"owner"`               // "namespace/name" of the PortForward resource
+	Protocol     string `json:"protocol"`            // "tcp" or "udp"
+	Interface    string `json:"interface,omitempty"` // in-interface; empty = any
+	ExternalIP   string `json:"externalIP,omitempty"`
+	ExternalPort int    `json:"externalPort"`
+	TargetIP     string `json:"targetIP"`
+	TargetPort   int    `json:"targetPort"`
+
+	ID string `json:"-"` // backend rule ID (RouterOS .id, nftables handle), set by ListPortForwards
+}
+
+// PortForwardComment returns the comment that tags owner's NAT rules.
+func PortForwardComment(owner string) string {
+	return portForwardTag + owner
+}
+
+// PortForwardOwner extracts the owner from a NAT rule comment, or "" when
+// the rule is not mkube's.
+func PortForwardOwner(comment string) string {
+	owner, ok := strings.CutPrefix(comment, portForwardTag)
+	if !ok {
+		return ""
+	}
+	return owner
+}
+
+// PortForwardError is a failure to program one owner's NAT rule. The error
+// SyncPortForwards returns joins one per failed rule, so callers can tell
+// which forwards are affected.
+type PortForwardError struct {
+	Owner string
+	Err   error
+}
+
+func (e *PortForwardError) Error() string { return e.Err.Error() }
+func (e *PortForwardError) Unwrap() error { return e.Err }
+
+// matches reports whether two rules forward the same traffic to the same
+// place, ignoring the backend ID.
+func (pf PortForward) matches(o PortForward) bool {
+	pf.ID, o.ID = "", ""
+	return pf == o
+}
+
+// SyncPortForwards makes the driver's mkube-owned NAT rules exactly want:
+// rules whose target moved are replaced, rules of deleted owners are
+// removed and missing rules are added. Rules without the mkube tag are
+// never touched. Failures of individual rules are *PortForwardError;
+// any other error affects every rule.
+func (m *Manager) SyncPortForwards(ctx context.Context, want []PortForward) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	fw, ok := m.driver.(PortForwarder)
+	if !ok {
+		if len(want) == 0 {
+			return nil
+		}
+		return fmt.Errorf("driver on %s cannot program port forwards: %w", m.driver.NodeName(), ErrNotSupported)
+	}
+
+	have, err := fw.ListPortForwards(ctx)
+	if err != nil {
+		return fmt.Errorf("listing port forwards: %w", err)
+	}
+
+	var errs []error
+	kept := make([]bool, len(want))
+	for _, rule := range have {
+		found := false
+		for i, w := range want {
+			if !kept[i] && rule.matches(w) {
+				kept[i], found = true, true
+				break
+			}
+		}
+		if found {
+			continue
+		}
+		if err := fw.RemovePortForward(ctx, rule); err != nil {
+			errs = append(errs, &PortForwardError{Owner: rule.Owner, Err: fmt.Errorf("removing port forward of %s: %w", rule.Owner, err)})
+			continue
+		}
+		m.log.Infow("port forward removed", "owner", rule.Owner, "port", rule.ExternalPort, "target", rule.TargetIP)
+	}
+
+	add := make([]PortForward, 0, len(want))
+	for i, w := range want {
+		if !kept[i] {
+			add = append(add, w)
+		}
+	}
+	sort.Slice(add, func(i, j int) bool { return add[i].Owner < add[j].Owner })
+	for _, w := range add {
+		if err := fw.AddPortForward(ctx, w); err != nil {
+			errs = append(errs, &PortForwardError{Owner: w.Owner, Err: fmt.Errorf("adding port forward of %s: %w", w.Owner, err)})
+			continue
+		}
+		m.log.Infow("port forward added", "owner", w.Owner, "protocol", w.Protocol,
+			"port", w.ExternalPort, "target", fmt.Sprintf("%s:%d", w.TargetIP, w.TargetPort))
+	}
+	return errors.Join(errs...)
+}
+
+// PortForwards returns the mkube-owned NAT rules the driver currently has.
+func (m *Manager) PortForwards(ctx context.Context) ([]PortForward, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	fw, ok := m.driver.(PortForwarder)
+	if !ok {
+		return nil, ErrNotSupported
+	}
+	return fw.ListPortForwards(ctx)
+}
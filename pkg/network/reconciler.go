@@ -82,6 +82,10 @@ func (m *Manager) reconcile(ctx context.Context) {
 			if ok {
 				if err := m.driver.AttachPort(ctx, ns.def.Bridge, name); err != nil {
 					log.Warnw("failed to re-attach drifted port", "port", name, "bridge", ns.def.Bridge, "error", err)
+				} else if desired.VLANTag > 0 {
+					if err := m.driver.SetPortVLAN(ctx, name, desired.VLANTag, false); err != nil {
+						log.Warnw("failed to re-tag drifted port", "port", name, "vlan", desired.VLANTag, "error", err)
+					}
 				}
 			}
 		}
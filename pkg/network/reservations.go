@@ -0,0 +1,173 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/glennswest/mkube/pkg/config"
+)
+
+// Reservations set aside addresses that no veth on this node backs: VIPs,
+// appliances, addresses promised to a future pod. They live in the IPAM
+// pools' reserved set (so pod allocation and static IPs skip them), are
+// claimed in NATS like any other allocation, and can carry a hostname that
+// gets an A/AAAA record in the network's zone.
+
+// ReserveAddress reserves ip in the named network under key, or the next
+// free address when ip is empty. Calling it again for the same key is
+// idempotent; a changed hostname moves the DNS record. Returns the reserved
+// address.
+func (m *Manager) ReserveAddress(ctx context.Context, key, networkName, ip, hostname string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ns, err := m.resolveNetwork(networkName)
+	if err != nil {
+		return "", err
+	}
+
+	var want net.IP
+	if ip != "" {
+		if want = net.ParseIP(ip); want == nil {
+			return "", fmt.Errorf("invalid IP %q", ip)
+		}
+	}
+	pool := ns.def.Name
+	if want != nil && want.To4() == nil {
+		if ns.subnet6 == nil {
+			return "", fmt.Errorf("network %s has no IPv6 subnet for %s", ns.def.Name, want)
+		}
+		pool = pool6(ns.def.Name)
+	}
+
+	if cur, ok := m.reservations[key]; ok {
+		addr := cur.address()
+		if cur.networkName != ns.def.Name || (want != nil && !want.Equal(addr)) {
+			return "", fmt.Errorf("%s already reserves %s in network %s", key, addr, cur.networkName)
+		}
+		if cur.hostname != hostname {
+			m.deregisterReservation(ctx, cur)
+			cur.hostname = hostname
+			m.registerReservation(ctx, cur)
+		}
+		return addr.String(), nil
+	}
+
+	reserved, err := m.reserveFrom(ctx, ns, pool, key, hostname, want)
+	if err != nil {
+		return "", err
+	}
+	res := &allocation{networkName: ns.def.Name, hostname: hostname}
+	if reserved.To4() == nil {
+		res.ip6 = reserved
+	} else {
+		res.ip = reserved
+	}
+	m.reservations[key] = res
+	m.registerReservation(ctx, res)
+
+	m.log.Infow("address reserved", "key", key, "ip", reserved, "network", ns.def.Name, "hostname", hostname)
+	return reserved.String(), nil
+}
+
+// ReleaseReservation frees the address reserved under key and removes its
+// DNS record. Unknown keys are a no-op.
+func (m *Manager) ReleaseReservation(ctx context.Context, key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	res, ok := m.reservations[key]
+	if !ok {
+		return
+	}
+	m.deregisterReservation(ctx, res)
+
+	pool := res.networkName
+	if res.ip == nil {
+		pool = pool6(res.networkName)
+	}
+	m.ipam.Unreserve(pool, key)
+	m.releaseClaim(ctx, pool, res.address(), key, "reservation released")
+	delete(m.reservations, key)
+	delete(m.conflicts, key)
+
+	m.log.Infow("address reservation released", "key", key, "ip", res.address())
+}
+
+// Reservations returns key -> reserved IP for every reservation.
+func (m *Manager) Reservations() map[string]string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	out := make(map[string]string, len(m.reservations))
+	for key, res := range m.reservations {
+		out[key] = res.address().String()
+	}
+	return out
+}
+
+// reserveFrom reserves want in pool, or probes for a free address the same
+// way allocateFrom does. Must be called with m.mu held.
+func (m *Manager) reserveFrom(ctx context.Context, ns *networkState, pool, key, hostname string, want net.IP) (net.IP, error) {
+	if want != nil {
+		if err := m.checkSlice(ns, pool, want); err != nil {
+			return nil, err
+		}
+		if _, err := m.ipam.Reserve(pool, key, want); err != nil {
+			return nil, err
+		}
+		if err := m.claimAddress(ctx, ns, pool, want, key, hostname, false); err != nil {
+			m.ipam.Unreserve(pool, key)
+			return nil, err
+		}
+		return want, nil
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < maxClaimAttempts; attempt++ {
+		ip, err := m.ipam.Reserve(pool, key, nil)
+		if err != nil {
+			return nil, err
+		}
+		if lastErr = m.claimAddress(ctx, ns, pool, ip, key, hostname, true); lastErr == nil {
+			return ip, nil
+		}
+		m.ipam.Unreserve(pool, key)
+		m.log.Warnw("skipping unavailable IP", "pool", pool, "ip", ip, "key", key, "error", lastErr)
+	}
+	return nil, fmt.Errorf("no usable IP in pool %s after %d attempts: %w", pool, maxClaimAttempts, lastErr)
+}
+
+// registerReservation adds the reservation's DNS record, if it has a
+// hostname. Must be called with m.mu held.
+func (m *Manager) registerReservation(ctx context.Context, res *allocation) {
+	ns, ok := m.networks[res.networkName]
+	if !ok || m.dns == nil || ns.zoneID == "" || res.hostname == "" {
+		return
+	}
+	if err := m.dns.RegisterHost(ctx, ns.def.DNS.Endpoint, ns.zoneID, res.hostname, res.address().String(), ns.def.DNS.TTL.TTL(config.DNSTTLStatic, 60)); err != nil {
+		m.log.Warnw("failed to register DNS", "hostname", res.hostname, "ip", res.address(), "error", err)
+	}
+}
+
+// deregisterReservation removes the reservation's DNS record for its own
+// address only, so a pod sharing the hostname keeps its record. Must be
+// called with m.mu held.
+func (m *Manager) deregisterReservation(ctx context.Context, res *allocation) {
+	ns, ok := m.networks[res.networkName]
+	if !ok || m.dns == nil || ns.zoneID == "" || res.hostname == "" {
+		return
+	}
+	if err := m.dns.DeregisterHostByIP(ctx, ns.def.DNS.Endpoint, ns.zoneID, res.hostname, res.address().String()); err != nil {
+		m.log.Warnw("failed to deregister DNS", "hostname", res.hostname, "ip", res.address(), "error", err)
+	}
+}
+
+// address returns the allocation's primary address.
+func (a *allocation) address() net.IP {
+	if a.ip != nil {
+		return a.ip
+	}
+	return a.ip6
+}
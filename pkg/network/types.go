@@ -1,20 +1,24 @@
 package network
 
+import "strconv"
+
 // LogicalSwitch represents a network segment (maps to a physical bridge on a node).
 type LogicalSwitch struct {
-	Name    string            `json:"name" yaml:"name"`     // e.g. "gt", "g10"
-	Bridge  string            `json:"bridge" yaml:"bridge"` // physical bridge name
-	CIDR    string            `json:"cidr" yaml:"cidr"`
-	Gateway string            `json:"gateway" yaml:"gateway"`
-	VLANs   []int             `json:"vlans,omitempty" yaml:"vlans,omitempty"`
-	Labels  map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	Name     string            `json:"name" yaml:"name"`     // e.g. "gt", "g10"
+	Bridge   string            `json:"bridge" yaml:"bridge"` // physical bridge name
+	CIDR     string            `json:"cidr" yaml:"cidr"`
+	Gateway  string            `json:"gateway" yaml:"gateway"`
+	CIDR6    string            `json:"cidr6,omitempty" yaml:"cidr6,omitempty"` // dual-stack IPv6 subnet
+	Gateway6 string            `json:"gateway6,omitempty" yaml:"gateway6,omitempty"`
+	VLANs    []int             `json:"vlans,omitempty" yaml:"vlans,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
 }
 
 // LogicalPort represents a port on a logical switch (maps to a veth on a node).
 type LogicalPort struct {
 	Name     string `json:"name" yaml:"name"`       // veth name
 	Switch   string `json:"switch" yaml:"switch"`   // parent LogicalSwitch name
-	Address  string `json:"address" yaml:"address"` // assigned IP/mask
+	Address  string `json:"address" yaml:"address"` // assigned IP/mask (comma-separated when dual-stack)
 	Gateway  string `json:"gateway" yaml:"gateway"`
 	Hostname string `json:"hostname" yaml:"hostname"`
 	VLANTag  int    `json:"vlanTag,omitempty" yaml:"vlanTag,omitempty"`
@@ -42,4 +46,18 @@ type Tunnel struct {
 	LocalIP    string `json:"localIP" yaml:"localIP"`
 	RemoteIP   string `json:"remoteIP" yaml:"remoteIP"`
 	VNI        int    `json:"vni,omitempty" yaml:"vni,omitempty"`
+	Network    string `json:"network,omitempty" yaml:"network,omitempty"` // overlay network the tunnel carries
+	Bridge     string `json:"bridge,omitempty" yaml:"bridge,omitempty"`   // bridge the tunnel is a port of
+
+	// WireGuard site links
+	ListenPort int    `json:"listenPort,omitempty" yaml:"listenPort,omitempty"`
+	LocalKey   string `json:"localPublicKey,omitempty" yaml:"localPublicKey,omitempty"`
+	RemoteKey  string `json:"remotePublicKey,omitempty" yaml:"remotePublicKey,omitempty"`
+	AllowedIPs string `json:"allowedIPs,omitempty" yaml:"allowedIPs,omitempty"` // comma-separated CIDRs routed to the peer
+}
+
+// VLANInterfaceName returns the name of the VLAN interface carrying a
+// network's gateway when it shares a VLAN-filtering bridge ("br0.10").
+func VLANInterfaceName(bridge string, vid int) string {
+	return bridge + "." + strconv.Itoa(vid)
 }
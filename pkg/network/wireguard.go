@@ -0,0 +1,37 @@
+package network
+
+import (
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+)
+
+// DefaultWireGuardPort is the base UDP port of WireGuard site links. Each
+// node pair listens on the base plus a per-pair offset (see planMeshIDs),
+// so a node can hold one single-peer interface per site.
+const DefaultWireGuardPort = 51820
+
+// GenerateWireGuardKey returns a new base64 Curve25519 private key and its
+// public key, in the format both wg(8) and RouterOS expect.
+func GenerateWireGuardKey() (privateKey, publicKey string, err error) {
+	key, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", fmt.Errorf("generating WireGuard key: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(key.Bytes()),
+		base64.StdEncoding.EncodeToString(key.PublicKey().Bytes()), nil
+}
+
+// WireGuardPublicKey derives the public key of a base64 private key.
+func WireGuardPublicKey(privateKey string) (string, error) {
+	raw, err := base64.StdEncoding.DecodeString(privateKey)
+	if err != nil {
+		return "", fmt.Errorf("decoding WireGuard private key: %w", err)
+	}
+	key, err := ecdh.X25519().NewPrivateKey(raw)
+	if err != nil {
+		return "", fmt.Errorf("invalid WireGuard private key: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(key.PublicKey().Bytes()), nil
+}
@@ -0,0 +1,442 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/glennswest/mkube/pkg/admission"
+)
+
+// ─── Admission ──────────────────────────────────────────────────────────────
+//
+// Every create, update and delete of an /api/v1 resource passes through
+// admission before it reaches the handler:
+//
+//  1. in-process mutating plugins (admission.plugins, in config order)
+//  2. MutatingWebhookConfigurations, ordered by configuration name
+//  3. in-process validating plugins
+//  4. ValidatingWebhookConfigurations
+//
+// Webhooks are called without holding p.mu so a slow webhook can't stall
+// the API. Mutations are handed to the handler as a rewritten request body.
+
+// admissionRequest identifies the resource a write request targets.
+type admissionRequest struct {
+	attrs   admission.Attributes
+	getPath string // path of the existing object, "" for creates
+}
+
+// parseAdmissionRequest maps a write request to admission attributes. It
+// returns false for requests admission doesn't cover: subresources, actions,
+// collection deletes, unknown resources and the webhook configurations
+// themselves.
+func parseAdmissionRequest(r *http.Request) (*admissionRequest, bool) {
+	ns, resource, name, ok := splitResourcePath(r.URL.Path)
+	if !ok {
+		return nil, false
+	}
+
+	if resource == "mutatingwebhookconfigurations" || resource == "validatingwebhookconfigurations" {
+		return nil, false
+	}
+	kind := ""
+	for _, res := range apiResources {
+		if res.Name == resource {
+			kind = res.Kind
+			break
+		}
+	}
+	if kind == "" {
+		return nil, false
+	}
+
+	req := &admissionRequest{attrs: admission.Attributes{
+		Kind: kind, Resource: resource, Namespace: ns, Name: name,
+	}}
+	switch r.Method {
+	case http.MethodPost:
+		if name != "" {
+			return nil, false // actions such as /baremetalhosts/refresh
+		}
+		req.attrs.Operation = admission.Create
+	case http.MethodPut, http.MethodPatch:
+		req.attrs.Operation = admission.Update
+	case http.MethodDelete:
+		req.attrs.Operation = admission.Delete
+	default:
+		return nil, false
+	}
+	if req.attrs.Operation != admission.Create {
+		if name == "" {
+			return nil, false
+		}
+		req.getPath = r.URL.Path
+	}
+	return req, true
+}
+
+// splitResourcePath splits an /api/v1 resource or collection path into its
+// namespace, resource and name. Subresource and action paths are rejected.
+func splitResourcePath(path string) (ns, resource, name string, ok bool) {
+	rest, ok := strings.CutPrefix(path, "/api/v1/")
+	if !ok {
+		return "", "", "", false
+	}
+	parts := strings.Split(strings.Trim(rest, "/"), "/")
+
+	switch {
+	case parts[0] == "namespaces" && len(parts) <= 2:
+		resource = "namespaces"
+		if len(parts) == 2 {
+			name = parts[1]
+		}
+	case parts[0] == "namespaces" && len(parts) <= 4:
+		ns, resource = parts[1], parts[2]
+		if len(parts) == 4 {
+			name = parts[3]
+		}
+	case parts[0] != "namespaces" && len(parts) <= 2:
+		resource = parts[0]
+		if len(parts) == 2 {
+			name = parts[1]
+		}
+	default:
+		return "", "", "", false
+	}
+	return ns, resource, name, true
+}
+
+// admitRequest runs the admission chain for a write request. It returns the
+// request to serve, with its body replaced if admission mutated the object,
+// or false if the request was denied and the response has been written.
+func (p *MicroKubeProvider) admitRequest(w http.ResponseWriter, r *http.Request, h http.Handler) (*http.Request, bool) {
+	req, ok := parseAdmissionRequest(r)
+	if !ok {
+		return r, true
+	}
+
+	p.mu.RLock()
+	if p.admission.Empty() && len(p.mutatingWebhooks) == 0 && len(p.validatingWebhooks) == 0 {
+		p.mu.RUnlock()
+		return r, true
+	}
+	mutating, validating := p.snapshotWebhooks()
+	var old map[string]interface{}
+	if req.getPath != "" {
+		old = p.internalGetObject(r.Context(), h, req.getPath)
+	}
+	p.mu.RUnlock()
+
+	a := &req.attrs
+	a.OldObject = old
+	if id, ok := r.Context().Value(apiIdentityKey{}).(apiIdentity); ok {
+		a.User = id.Name
+	}
+
+	var body []byte
+	if a.Operation != admission.Delete {
+		var err error
+		if body, err = io.ReadAll(r.Body); err != nil {
+			http.Error(w, fmt.Sprintf("reading body: %v", err), http.StatusBadRequest)
+			return nil, false
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		var obj map[string]interface{}
+		if err := json.Unmarshal(body, &obj); err != nil {
+			// Let the handler report malformed JSON
+			return r, true
+		}
+		if r.Method == http.MethodPatch {
+			if old == nil {
+				return r, true // handler will 404
+			}
+			obj = jsonMergePatch(deepCopyObject(old), obj)
+		}
+		a.Object = obj
+		if a.Name == "" {
+			a.Name, _ = nestedField(obj, "metadata", "name").(string)
+		}
+	}
+
+	// Snapshot to detect mutations
+	before := deepCopyObject(a.Object)
+
+	var warnings []string
+	err := p.runAdmission(r.Context(), a, mutating, validating, &warnings)
+	for _, msg := range warnings {
+		w.Header().Add("Warning", "299 - "+strconv.Quote(msg))
+		p.deps.Logger.Warnw("admission warning", "kind", a.Kind, "namespace", a.Namespace, "name", a.Name, "warning", msg)
+	}
+	if err != nil {
+		p.writeAdmissionError(w, a, err)
+		return nil, false
+	}
+
+	if a.Object == nil || reflect.DeepEqual(before, a.Object) {
+		return r, true
+	}
+
+	// Hand the admitted object to the handler. Patches become a merge patch
+	// from the stored object, since handlers apply patch bodies over it.
+	out := a.Object
+	if r.Method == http.MethodPatch {
+		out = jsonMergeDiff(old, a.Object)
+	}
+	mutated, err := json.Marshal(out)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("encoding admitted object: %v", err), http.StatusInternalServerError)
+		return nil, false
+	}
+	r.Body = io.NopCloser(bytes.NewReader(mutated))
+	r.ContentLength = int64(len(mutated))
+	r.Header.Set("Content-Length", strconv.Itoa(len(mutated)))
+	return r, true
+}
+
+func (p *MicroKubeProvider) runAdmission(ctx context.Context, a *admission.Attributes, mutating, validating []admission.Webhook, warnings *[]string) error {
+	if err := p.admission.Admit(ctx, a); err != nil {
+		return err
+	}
+
+	nsLabels := map[string]string{"kubernetes.io/metadata.name": a.Namespace}
+	for i := range mutating {
+		wh := &mutating[i]
+		if !wh.Matches(a, nsLabels) {
+			continue
+		}
+		warn, err := wh.Invoke(ctx, a, true)
+		*warnings = append(*warnings, warn...)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := p.admission.Validate(ctx, a); err != nil {
+		return err
+	}
+
+	for i := range validating {
+		wh := &validating[i]
+		if !wh.Matches(a, nsLabels) {
+			continue
+		}
+		warn, err := wh.Invoke(ctx, a, false)
+		*warnings = append(*warnings, warn...)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeAdmissionError reports a denied or failed admission and records an
+// event against the object.
+func (p *MicroKubeProvider) writeAdmissionError(w http.ResponseWriter, a *admission.Attributes, err error) {
+	code := http.StatusInternalServerError
+	var denied *admission.DeniedError
+	if errors.As(err, &denied) {
+		switch {
+		case denied.Code >= 400:
+			code = denied.Code
+		case strings.HasPrefix(denied.Source, "plugin "):
+			code = http.StatusForbidden
+		default:
+			code = http.StatusBadRequest
+		}
+	}
+
+	p.deps.Logger.Infow("admission rejected request",
+		"operation", a.Operation, "kind", a.Kind, "namespace", a.Namespace, "name", a.Name,
+		"user", a.User, "error", err)
+	p.recordAdmissionEvent(a, "AdmissionDenied", err.Error())
+
+	http.Error(w, fmt.Sprintf("%s %q: %v", a.Kind, a.Name, err), code)
+}
+
+// recordAdmissionEvent appends an event for the object under admission.
+// Admission runs outside the handler lock, so it takes p.mu itself.
+func (p *MicroKubeProvider) recordAdmissionEvent(a *admission.Attributes, reason, message string) {
+	now := metav1.Now()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.appendEvent(corev1.Event{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Event"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              fmt.Sprintf("%s.%x", a.Name, now.UnixNano()),
+			Namespace:         a.Namespace,
+			CreationTimestamp: now,
+		},
+		InvolvedObject: corev1.ObjectReference{
+			Kind:      a.Kind,
+			Name:      a.Name,
+			Namespace: a.Namespace,
+		},
+		Reason:         reason,
+		Message:        message,
+		Type:           "Warning",
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+		Source:         corev1.EventSource{Component: "mkube-admission", Host: p.nodeName},
+	})
+}
+
+// snapshotWebhooks returns copies of all configured webhooks, each list
+// ordered by configuration name. Caller must hold p.mu.
+func (p *MicroKubeProvider) snapshotWebhooks() (mutating, validating []admission.Webhook) {
+	names := make([]string, 0, len(p.mutatingWebhooks))
+	for name := range p.mutatingWebhooks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		cfg := p.mutatingWebhooks[name].DeepCopy()
+		for _, wh := range cfg.Webhooks {
+			mutating = append(mutating, admission.FromMutating(name, wh))
+		}
+	}
+
+	names = names[:0]
+	for name := range p.validatingWebhooks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		cfg := p.validatingWebhooks[name].DeepCopy()
+		for _, wh := range cfg.Webhooks {
+			validating = append(validating, admission.FromValidating(name, wh))
+		}
+	}
+	return mutating, validating
+}
+
+// internalGetObject fetches the current object at path through the API
+// handler, so admission sees exactly what clients see. Returns nil if the
+// object doesn't exist. Caller must hold p.mu.
+func (p *MicroKubeProvider) internalGetObject(ctx context.Context, h http.Handler, path string) map[string]interface{} {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil
+	}
+	req.Header.Set("Accept", "application/json")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		return nil
+	}
+	var obj map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &obj); err != nil {
+		return nil
+	}
+	return obj
+}
+
+// initAdmission builds the in-process plugin chain from config.
+func (p *MicroKubeProvider) initAdmission() error {
+	chain, err := admission.NewChain(admission.Env{
+		Config: p.deps.Config.Admission,
+		NamespaceNetwork: func(namespace string) string {
+			if p.deps.Namespace == nil {
+				return ""
+			}
+			ns, err := p.deps.Namespace.GetNamespace(namespace)
+			if err != nil || ns == nil {
+				return ""
+			}
+			return ns.Network
+		},
+	})
+	if err != nil {
+		return err
+	}
+	p.admission = chain
+	if len(p.deps.Config.Admission.Plugins) > 0 {
+		p.deps.Logger.Infow("admission plugins enabled", "plugins", p.deps.Config.Admission.Plugins)
+	}
+	return nil
+}
+
+// ─── JSON helpers ───────────────────────────────────────────────────────────
+
+// jsonMergePatch applies an RFC 7386 merge patch to target in place.
+func jsonMergePatch(target, patch map[string]interface{}) map[string]interface{} {
+	if target == nil {
+		target = map[string]interface{}{}
+	}
+	for k, v := range patch {
+		if v == nil {
+			delete(target, k)
+			continue
+		}
+		pm, isMap := v.(map[string]interface{})
+		tm, targetIsMap := target[k].(map[string]interface{})
+		if isMap && targetIsMap {
+			target[k] = jsonMergePatch(tm, pm)
+		} else if isMap {
+			target[k] = jsonMergePatch(nil, pm)
+		} else {
+			target[k] = v
+		}
+	}
+	return target
+}
+
+// jsonMergeDiff returns the merge patch that turns from into to.
+func jsonMergeDiff(from, to map[string]interface{}) map[string]interface{} {
+	diff := map[string]interface{}{}
+	for k, tv := range to {
+		fv, ok := from[k]
+		if ok && reflect.DeepEqual(fv, tv) {
+			continue
+		}
+		fm, fromIsMap := fv.(map[string]interface{})
+		tm, toIsMap := tv.(map[string]interface{})
+		if fromIsMap && toIsMap {
+			diff[k] = jsonMergeDiff(fm, tm)
+		} else {
+			diff[k] = tv
+		}
+	}
+	for k := range from {
+		if _, ok := to[k]; !ok {
+			diff[k] = nil
+		}
+	}
+	return diff
+}
+
+func deepCopyObject(obj map[string]interface{}) map[string]interface{} {
+	if obj == nil {
+		return nil
+	}
+	raw, _ := json.Marshal(obj)
+	var out map[string]interface{}
+	_ = json.Unmarshal(raw, &out)
+	return out
+}
+
+func nestedField(obj map[string]interface{}, path ...string) interface{} {
+	var cur interface{} = obj
+	for _, key := range path {
+		m, ok := cur.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		cur = m[key]
+	}
+	return cur
+}
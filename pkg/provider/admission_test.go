@@ -0,0 +1,132 @@
+package provider
+
+import (
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+
+	"github.com/glennswest/mkube/pkg/admission"
+)
+
+func TestParseAdmissionRequest(t *testing.T) {
+	tests := []struct {
+		method, path string
+		want         string // "op kind ns/name", "" for not admitted
+	}{
+		{http.MethodPost, "/api/v1/namespaces/infra/pods", "CREATE Pod infra/"},
+		{http.MethodPut, "/api/v1/namespaces/infra/pods/web", "UPDATE Pod infra/web"},
+		{http.MethodPatch, "/api/v1/networks/g10", "UPDATE Network /g10"},
+		{http.MethodDelete, "/api/v1/namespaces/dev", "DELETE Namespace /dev"},
+		{http.MethodPost, "/api/v1/namespaces", "CREATE Namespace /"},
+		{http.MethodPost, "/api/v1/namespaces/default/baremetalhosts/server1/refresh", ""},
+		{http.MethodPost, "/api/v1/baremetalhosts/refresh", ""},
+		{http.MethodDelete, "/api/v1/namespaces/infra/pods", ""},
+		{http.MethodPost, "/api/v1/validatingwebhookconfigurations", ""},
+		{http.MethodPost, "/api/v1/consistency/repair", ""},
+	}
+	for _, tt := range tests {
+		req, ok := parseAdmissionRequest(httptest.NewRequest(tt.method, tt.path, nil))
+		got := ""
+		if ok {
+			a := req.attrs
+			got = fmt.Sprintf("%s %s %s/%s", a.Operation, a.Kind, a.Namespace, a.Name)
+		}
+		if got != tt.want {
+			t.Errorf("%s %s: got %q, want %q", tt.method, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestAdmissionPluginsAndWebhooks(t *testing.T) {
+	p, _ := newTestProvider(t)
+	p.deps.Config.Admission.Plugins = []string{admission.PluginRequireBMHOwner}
+	p.deps.Config.Admission.OwnerLabel = "owner"
+	if err := p.initAdmission(); err != nil {
+		t.Fatalf("initAdmission: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	p.RegisterRoutes(mux)
+	h := p.WrapHandler(mux)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rec
+	}
+
+	bmh := `{"metadata":{"name":"server1"},"spec":{"bootMACAddress":"aa:bb:cc:dd:ee:ff"}}`
+	rec := do(http.MethodPost, "/api/v1/namespaces/default/baremetalhosts", bmh)
+	if rec.Code != http.StatusForbidden || !strings.Contains(rec.Body.String(), `"owner" label`) {
+		t.Fatalf("expected plugin denial, got %d (%s)", rec.Code, rec.Body.String())
+	}
+
+	// A mutating webhook that adds the owner label makes the same request pass
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var review admissionv1.AdmissionReview
+		_ = json.NewDecoder(r.Body).Decode(&review)
+		pt := admissionv1.PatchTypeJSONPatch
+		review.Response = &admissionv1.AdmissionResponse{
+			UID:       review.Request.UID,
+			Allowed:   true,
+			PatchType: &pt,
+			Patch:     []byte(`[{"op":"add","path":"/metadata/labels","value":{"owner":"webhook"}}]`),
+		}
+		review.Request = nil
+		_ = json.NewEncoder(w).Encode(review)
+	}))
+	defer srv.Close()
+	ca, _ := json.Marshal(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}))
+
+	cfg := fmt.Sprintf(`{"metadata":{"name":"owner-default"},"webhooks":[{"name":"owner.example.com",
+		"clientConfig":{"url":%q,"caBundle":%s},
+		"rules":[{"operations":["CREATE"],"apiGroups":[""],"apiVersions":["v1"],"resources":["baremetalhosts"]}],
+		"sideEffects":"None","admissionReviewVersions":["v1"]}]}`, srv.URL, ca)
+	if rec := do(http.MethodPost, "/api/v1/mutatingwebhookconfigurations", cfg); rec.Code != http.StatusCreated {
+		t.Fatalf("creating webhook configuration: %d (%s)", rec.Code, rec.Body.String())
+	}
+
+	rec = do(http.MethodPost, "/api/v1/namespaces/default/baremetalhosts", bmh)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected BMH to be admitted, got %d (%s)", rec.Code, rec.Body.String())
+	}
+	stored, ok := p.bareMetalHosts["default/server1"]
+	if !ok || stored.Labels["owner"] != "webhook" {
+		t.Errorf("webhook mutation not stored: %+v", stored)
+	}
+}
+
+func TestWebhookConfigurationValidation(t *testing.T) {
+	p, _ := newTestProvider(t)
+	mux := http.NewServeMux()
+	p.RegisterRoutes(mux)
+
+	body := `{"metadata":{"name":"bad"},"webhooks":[{"name":"plain.example.com",
+		"clientConfig":{"url":"http://plain.example.com/admit"},"timeoutSeconds":5}]}`
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/validatingwebhookconfigurations", strings.NewReader(body)))
+	if rec.Code != http.StatusUnprocessableEntity || !strings.Contains(rec.Body.String(), "https://") {
+		t.Fatalf("expected 422 for http:// webhook, got %d (%s)", rec.Code, rec.Body.String())
+	}
+	if len(p.validatingWebhooks) != 0 {
+		t.Error("invalid configuration was stored")
+	}
+}
+
+func TestJSONMergeDiff(t *testing.T) {
+	from := map[string]interface{}{"a": "1", "b": map[string]interface{}{"c": "2", "d": "3"}, "e": "4"}
+	to := map[string]interface{}{"a": "1", "b": map[string]interface{}{"c": "2", "d": "5"}, "f": "6"}
+
+	patch := jsonMergeDiff(from, to)
+	got, _ := json.Marshal(jsonMergePatch(deepCopyObject(from), patch))
+	want, _ := json.Marshal(to)
+	if string(got) != string(want) {
+		t.Errorf("round trip: got %s, want %s (patch %v)", got, want, patch)
+	}
+}
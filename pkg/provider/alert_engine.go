@@ -0,0 +1,816 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/smtp"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	alertStateInactive = "Inactive"
+	alertStatePending  = "Pending"
+	alertStateFiring   = "Firing"
+
+	// defaultAlertEvalInterval is used when alerting.evalInterval is unset.
+	defaultAlertEvalInterval = 30 * time.Second
+
+	// alertSinkTimeout bounds each webhook/SMTP delivery.
+	alertSinkTimeout = 10 * time.Second
+
+	// alertPeerTimeout bounds the leader's request for a peer's observations.
+	alertPeerTimeout = 5 * time.Second
+)
+
+// alertObservation is one target for which a rule's condition currently
+// holds. Node is set, in a cluster, for targets only one node can see.
+type alertObservation struct {
+	Target  string `json:"target"`
+	Node    string `json:"node,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// alertInstanceKey identifies an alert instance: the same target name on
+// two nodes is two alerts.
+type alertInstanceKey struct {
+	node   string
+	target string
+}
+
+// alertSourceNodeLocal reports whether a rule source observes state only the
+// local node has: its pods, its consistency checks and its jobs. BMHs and
+// DNS servers are cluster-wide and observed by the leader alone.
+func alertSourceNodeLocal(source string) bool {
+	switch source {
+	case "pod", "consistency", "job":
+		return true
+	}
+	return false
+}
+
+// alertNotification is the JSON payload delivered to every sink type.
+type alertNotification struct {
+	Status   string            `json:"status"` // firing, resolved
+	Rule     string            `json:"rule"`
+	Severity string            `json:"severity,omitempty"`
+	Source   string            `json:"source"`
+	Target   string            `json:"target"`
+	Summary  string            `json:"summary,omitempty"`
+	Message  string            `json:"message,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Node     string            `json:"node,omitempty"`
+	StartsAt string            `json:"startsAt"`
+	EndsAt   string            `json:"endsAt,omitempty"`
+}
+
+// alertDelivery pairs a notification with the sinks it must be sent to.
+type alertDelivery struct {
+	sinks []AlertSink
+	note  alertNotification
+}
+
+// RunAlertEngine periodically evaluates all AlertRules, advances their
+// pending/firing state and delivers notifications to the configured sinks.
+func (p *MicroKubeProvider) RunAlertEngine(ctx context.Context) {
+	log := p.deps.Logger.Named("alerts")
+
+	interval := time.Duration(p.deps.Config.Alerting.EvalInterval) * time.Second
+	if interval <= 0 {
+		interval = defaultAlertEvalInterval
+	}
+	log.Infow("alert engine starting", "interval", interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("alert engine stopping")
+			return
+		case <-ticker.C:
+			p.alertTick(ctx, log)
+		}
+	}
+}
+
+// alertTick performs one evaluation cycle. Observations are gathered under
+// the read lock (DNS probes run unlocked), state is advanced under the write
+// lock, and notifications are delivered after the lock is released so a slow
+// sink can never stall the API.
+//
+// In a cluster the alert rule and silence buckets are synced and every node
+// reloads them each cycle, so its API shows the current status. Only the
+// leader evaluates and notifies, so each alert is sent once per cluster: it
+// adds each peer's node-local observations to its own, and a successor
+// picks up the firing state from the synced rules.
+func (p *MicroKubeProvider) alertTick(ctx context.Context, log *zap.SugaredLogger) {
+	if p.clusterMgr != nil {
+		p.reloadAlertsFromStore(ctx)
+		if !p.clusterMgr.IsLeader() {
+			return
+		}
+	}
+
+	p.mu.RLock()
+	rules := make([]*AlertRule, 0, len(p.alertRules))
+	for _, ar := range p.alertRules {
+		if !ar.Spec.Disabled {
+			rules = append(rules, ar.DeepCopy())
+		}
+	}
+	p.mu.RUnlock()
+
+	if len(rules) == 0 {
+		return
+	}
+
+	observed, evalErrs := p.collectAlertObservations(ctx, rules)
+	var unreported map[string]bool
+	if p.clusterMgr != nil {
+		unreported = p.collectPeerAlertObservations(ctx, log, observed)
+	}
+
+	now := time.Now()
+	var deliveries []alertDelivery
+
+	p.mu.Lock()
+	// Inhibition uses the state from the previous cycle so evaluation
+	// order between rules doesn't matter.
+	firing := make(map[string]bool, len(p.alertRules))
+	for name, ar := range p.alertRules {
+		if ar.Status.State == alertStateFiring {
+			firing[name] = true
+		}
+	}
+	silences := make([]*AlertSilence, 0, len(p.alertSilences))
+	for _, s := range p.alertSilences {
+		silences = append(silences, s)
+	}
+
+	for _, snap := range rules {
+		ar, ok := p.alertRules[snap.Name]
+		if !ok || ar.Spec.Disabled {
+			continue // deleted or disabled while we were observing
+		}
+
+		inhibited := false
+		for _, inh := range ar.Spec.InhibitedBy {
+			if firing[inh] {
+				inhibited = true
+				break
+			}
+		}
+
+		prev := ar.Status
+		obs := append(observed[ar.Name], carriedAlertObservations(ar, unreported)...)
+		status, notes := evaluateAlertRule(ar, obs, silences, inhibited, now)
+		status.LastError = evalErrs[ar.Name]
+		ar.Status = status
+
+		for _, n := range notes {
+			if n.Node == "" {
+				n.Node = p.nodeName
+			}
+			deliveries = append(deliveries, alertDelivery{sinks: ar.Spec.Sinks, note: n})
+			if n.Status == "firing" {
+				p.recordAlertEvent(ar, "AlertFiring", fmt.Sprintf("%s: %s", n.Target, n.Message), "Warning")
+			} else {
+				p.recordAlertEvent(ar, "AlertResolved", fmt.Sprintf("%s resolved", n.Target), "Normal")
+			}
+		}
+
+		// Skip the NATS write when only LastEval moved.
+		if prev.State != status.State || prev.LastError != status.LastError ||
+			!reflect.DeepEqual(prev.Alerts, status.Alerts) {
+			p.persistAlertRule(ctx, ar)
+		}
+	}
+	p.mu.Unlock()
+
+	for _, d := range deliveries {
+		p.deliverAlert(ctx, log, d)
+	}
+}
+
+// evaluateAlertRule advances a rule's alert instances given the targets that
+// currently match its condition. It returns the new status and the firing or
+// resolved notifications that should be delivered.
+func evaluateAlertRule(ar *AlertRule, observed []alertObservation, silences []*AlertSilence, inhibited bool, now time.Time) (AlertRuleStatus, []alertNotification) {
+	holdFor, _ := time.ParseDuration(ar.Spec.For)
+	repeat, _ := time.ParseDuration(ar.Spec.RepeatInterval)
+	nowStr := now.UTC().Format(time.RFC3339)
+
+	prev := make(map[alertInstanceKey]AlertInstance, len(ar.Status.Alerts))
+	for _, inst := range ar.Status.Alerts {
+		prev[alertInstanceKey{inst.Node, inst.Target}] = inst
+	}
+
+	status := AlertRuleStatus{State: alertStateInactive, LastEval: nowStr}
+	var notes []alertNotification
+
+	seen := make(map[alertInstanceKey]bool, len(observed))
+	for _, obs := range observed {
+		key := alertInstanceKey{obs.Node, obs.Target}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		inst, ok := prev[key]
+		if !ok {
+			inst = AlertInstance{Target: obs.Target, Node: obs.Node, State: alertStatePending, ActiveSince: nowStr}
+		}
+		delete(prev, key)
+
+		inst.Message = obs.Message
+		inst.Silenced = alertSilenced(silences, ar.Name, obs.Target, now)
+		inst.Inhibited = inhibited
+
+		if inst.State == alertStatePending && now.Sub(parseAlertTime(inst.ActiveSince, now)) >= holdFor {
+			inst.State = alertStateFiring
+			inst.FiredAt = nowStr
+		}
+
+		if inst.State == alertStateFiring && !inst.Silenced && !inst.Inhibited {
+			due := inst.LastNotified == ""
+			if !due && repeat > 0 {
+				due = now.Sub(parseAlertTime(inst.LastNotified, now)) >= repeat
+			}
+			if due {
+				notes = append(notes, newAlertNotification(ar, inst, "firing", ""))
+				inst.LastNotified = nowStr
+			}
+		}
+
+		status.Alerts = append(status.Alerts, inst)
+	}
+
+	// Anything left in prev no longer matches — resolve it. Only targets we
+	// actually told someone about get a resolved notification.
+	for _, inst := range prev {
+		if inst.State == alertStateFiring && inst.LastNotified != "" &&
+			!alertSilenced(silences, ar.Name, inst.Target, now) {
+			notes = append(notes, newAlertNotification(ar, inst, "resolved", nowStr))
+		}
+	}
+
+	sort.Slice(status.Alerts, func(i, j int) bool {
+		a, b := status.Alerts[i], status.Alerts[j]
+		return a.Target < b.Target || a.Target == b.Target && a.Node < b.Node
+	})
+	sort.Slice(notes, func(i, j int) bool {
+		return notes[i].Target < notes[j].Target || notes[i].Target == notes[j].Target && notes[i].Node < notes[j].Node
+	})
+
+	for _, inst := range status.Alerts {
+		if inst.State == alertStateFiring {
+			status.State = alertStateFiring
+			break
+		}
+		status.State = alertStatePending
+	}
+
+	return status, notes
+}
+
+func newAlertNotification(ar *AlertRule, inst AlertInstance, state, endsAt string) alertNotification {
+	return alertNotification{
+		Status:   state,
+		Rule:     ar.Name,
+		Severity: ar.Spec.Severity,
+		Source:   ar.Spec.Source,
+		Target:   inst.Target,
+		Summary:  ar.Spec.Summary,
+		Message:  inst.Message,
+		Labels:   ar.Spec.Labels,
+		Node:     inst.Node,
+		StartsAt: inst.ActiveSince,
+		EndsAt:   endsAt,
+	}
+}
+
+// alertSilenced returns true if any active silence matches the rule and target.
+func alertSilenced(silences []*AlertSilence, rule, target string, now time.Time) bool {
+	for _, s := range silences {
+		if s.activeAt(now) && s.matches(rule, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseAlertTime(s string, fallback time.Time) time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return fallback
+	}
+	return t
+}
+
+// ─── Observation ────────────────────────────────────────────────────────────
+
+// dnsProbeTarget is a managed microdns instance probed on port 53.
+type dnsProbeTarget struct {
+	network string
+	server  string
+	zone    string
+}
+
+// collectAlertObservations evaluates every rule's condition against the
+// current cluster state. The consistency report and DNS probes are computed
+// at most once per cycle and shared by all rules that need them.
+func (p *MicroKubeProvider) collectAlertObservations(ctx context.Context, rules []*AlertRule) (map[string][]alertObservation, map[string]string) {
+	needs := make(map[string]bool)
+	for _, ar := range rules {
+		needs[ar.Spec.Source] = true
+	}
+
+	p.mu.RLock()
+	observed, errs := p.observeAlertRules(ctx, rules)
+	var dnsTargets []dnsProbeTarget
+	if needs["dns"] {
+		for _, netObj := range p.networks {
+			if netObj.Spec.ExternalDNS || netObj.Spec.DNS.Zone == "" || netObj.Spec.DNS.Server == "" {
+				continue
+			}
+			dnsTargets = append(dnsTargets, dnsProbeTarget{
+				network: netObj.Name,
+				server:  netObj.Spec.DNS.Server,
+				zone:    netObj.Spec.DNS.Zone,
+			})
+		}
+	}
+	p.mu.RUnlock()
+
+	if needs["dns"] {
+		dead := make(map[string]bool, len(dnsTargets))
+		for _, t := range dnsTargets {
+			if !probeDNSPort(t.server, t.zone, 3*time.Second) {
+				dead[t.network] = true
+			}
+		}
+		for _, ar := range rules {
+			if ar.Spec.Source != "dns" {
+				continue
+			}
+			for _, t := range dnsTargets {
+				if dead[t.network] && globMatch(ar.Spec.Match.Name, t.network) {
+					observed[ar.Name] = append(observed[ar.Name], alertObservation{
+						Target:  t.network,
+						Message: fmt.Sprintf("DNS server %s not answering for zone %s", t.server, t.zone),
+					})
+				}
+			}
+		}
+	}
+
+	return observed, errs
+}
+
+// observeAlertRules evaluates the conditions of every rule but the DNS
+// ones against the local state. In a cluster, observations of node-local
+// sources carry this node's name. Must be called with p.mu held.
+func (p *MicroKubeProvider) observeAlertRules(ctx context.Context, rules []*AlertRule) (map[string][]alertObservation, map[string]string) {
+	observed := make(map[string][]alertObservation, len(rules))
+	errs := make(map[string]string)
+
+	var categories map[string][]CheckItem
+	for _, ar := range rules {
+		if ar.Spec.Source == "consistency" {
+			report := p.runConsistencyChecks(ctx)
+			categories = consistencyCategories(&report.Checks)
+			break
+		}
+	}
+	for _, ar := range rules {
+		switch ar.Spec.Source {
+		case "consistency":
+			if ar.Spec.Match.Category != "" {
+				if _, ok := categories[ar.Spec.Match.Category]; !ok {
+					errs[ar.Name] = fmt.Sprintf("unknown consistency category %q", ar.Spec.Match.Category)
+					continue
+				}
+			}
+			observed[ar.Name] = matchConsistencyItems(ar.Spec.Match, categories)
+		case "pod":
+			observed[ar.Name] = p.observePods(ctx, ar.Spec.Match)
+		case "bmh":
+			observed[ar.Name] = p.observeBMHs(ar.Spec.Match)
+		case "job":
+			observed[ar.Name] = p.observeJobs(ar.Spec.Match)
+		}
+		if p.clusterMgr != nil && alertSourceNodeLocal(ar.Spec.Source) {
+			for i := range observed[ar.Name] {
+				observed[ar.Name][i].Node = p.nodeName
+			}
+		}
+	}
+	return observed, errs
+}
+
+// collectPeerAlertObservations adds the node-local observations of every
+// healthy peer to observed. It returns the configured peers that did not
+// report, whose alerts are carried over as they are. Must be called
+// without p.mu held.
+func (p *MicroKubeProvider) collectPeerAlertObservations(ctx context.Context, log *zap.SugaredLogger, observed map[string][]alertObservation) map[string]bool {
+	unreported := make(map[string]bool)
+	for _, peer := range p.clusterMgr.Peers() {
+		if !p.clusterMgr.IsPeerHealthy(peer.Name) {
+			unreported[peer.Name] = true
+			continue
+		}
+		remote, err := p.fetchPeerAlertObservations(ctx, peer.Address)
+		if err != nil {
+			log.Warnw("failed to get alert observations from peer", "peer", peer.Name, "error", err)
+			unreported[peer.Name] = true
+			continue
+		}
+		for rule, obs := range remote {
+			for _, o := range obs {
+				o.Node = peer.Name
+				observed[rule] = append(observed[rule], o)
+			}
+		}
+	}
+	return unreported
+}
+
+// fetchPeerAlertObservations asks a peer for its node-local observations.
+func (p *MicroKubeProvider) fetchPeerAlertObservations(ctx context.Context, address string) (map[string][]alertObservation, error) {
+	ctx, cancel := context.WithTimeout(ctx, alertPeerTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, address+"/api/v1/cluster/alert-observations", nil)
+	if err != nil {
+		return nil, err
+	}
+	if token := p.deps.Config.Cluster.Token; token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("peer returned %s", resp.Status)
+	}
+	var observed map[string][]alertObservation
+	if err := json.NewDecoder(resp.Body).Decode(&observed); err != nil {
+		return nil, fmt.Errorf("decoding observations: %w", err)
+	}
+	return observed, nil
+}
+
+// carriedAlertObservations re-observes the alerts of peers that did not
+// report this cycle, so they neither resolve nor restart their hold time
+// until the peer answers again.
+func carriedAlertObservations(ar *AlertRule, unreported map[string]bool) []alertObservation {
+	var out []alertObservation
+	for _, inst := range ar.Status.Alerts {
+		if inst.Node != "" && unreported[inst.Node] {
+			out = append(out, alertObservation{Target: inst.Target, Node: inst.Node, Message: inst.Message})
+		}
+	}
+	return out
+}
+
+// handleAlertObservations returns this node's observations for the enabled
+// rules with a node-local source. The leader calls it on every peer each
+// evaluation cycle; the rules arrive here through cluster sync.
+func (p *MicroKubeProvider) handleAlertObservations(w http.ResponseWriter, r *http.Request) {
+	var rules []*AlertRule
+	for _, ar := range p.alertRules {
+		if !ar.Spec.Disabled && alertSourceNodeLocal(ar.Spec.Source) {
+			rules = append(rules, ar)
+		}
+	}
+	observed, _ := p.observeAlertRules(r.Context(), rules)
+	podWriteJSON(w, http.StatusOK, observed)
+}
+
+// consistencyCategories indexes the report's check categories by their JSON
+// name so rules can reference them the same way the API exposes them.
+func consistencyCategories(checks *ConsistencyChecks) map[string][]CheckItem {
+	out := make(map[string][]CheckItem)
+	v := reflect.ValueOf(checks).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if items, ok := v.Field(i).Interface().([]CheckItem); ok && name != "" {
+			out[name] = items
+		}
+	}
+	return out
+}
+
+func matchConsistencyItems(m AlertMatch, categories map[string][]CheckItem) []alertObservation {
+	statuses := m.Statuses
+	if len(statuses) == 0 {
+		statuses = []string{"fail"}
+	}
+
+	names := make([]string, 0, len(categories))
+	for cat := range categories {
+		if m.Category == "" || m.Category == cat {
+			names = append(names, cat)
+		}
+	}
+	sort.Strings(names)
+
+	var out []alertObservation
+	for _, cat := range names {
+		for _, item := range categories[cat] {
+			if !containsFold(statuses, item.Status) || !globMatch(m.Name, item.Name) {
+				continue
+			}
+			out = append(out, alertObservation{
+				Target:  cat + "/" + item.Name,
+				Message: item.Message,
+			})
+		}
+	}
+	return out
+}
+
+// observePods reports pods whose live phase matches. Must be called with p.mu held.
+func (p *MicroKubeProvider) observePods(ctx context.Context, m AlertMatch) []alertObservation {
+	phases := m.Phases
+	if len(phases) == 0 {
+		phases = []string{string(corev1.PodPending), string(corev1.PodFailed)}
+	}
+
+	var out []alertObservation
+	for key, pod := range p.pods {
+		if m.Namespace != "" && pod.Namespace != m.Namespace {
+			continue
+		}
+		if !globMatch(m.Name, pod.Name) {
+			continue
+		}
+		st, err := p.GetPodStatus(ctx, pod.Namespace, pod.Name)
+		if err != nil {
+			continue
+		}
+		if !containsFold(phases, string(st.Phase)) {
+			continue
+		}
+		msg := fmt.Sprintf("pod is %s", st.Phase)
+		for _, cs := range st.ContainerStatuses {
+			if cs.State.Waiting != nil {
+				msg = fmt.Sprintf("container %s waiting: %s", cs.Name, cs.State.Waiting.Reason)
+				break
+			}
+		}
+		out = append(out, alertObservation{Target: key, Message: msg})
+	}
+	return out
+}
+
+// observeBMHs reports BareMetalHosts whose phase or power state matches.
+// With no condition, hosts reporting an error are matched.
+// Must be called with p.mu held.
+func (p *MicroKubeProvider) observeBMHs(m AlertMatch) []alertObservation {
+	var out []alertObservation
+	for key, bmh := range p.bareMetalHosts {
+		if m.Namespace != "" && bmh.Namespace != m.Namespace {
+			continue
+		}
+		if !globMatch(m.Name, bmh.Name) {
+			continue
+		}
+
+		var msg string
+		switch {
+		case len(m.Phases) == 0 && m.PoweredOn == nil:
+			if bmh.Status.ErrorMessage == "" {
+				continue
+			}
+			msg = bmh.Status.ErrorMessage
+		default:
+			if len(m.Phases) > 0 && !containsFold(m.Phases, bmh.Status.Phase) {
+				continue
+			}
+			if m.PoweredOn != nil && bmh.Status.PoweredOn != *m.PoweredOn {
+				continue
+			}
+			power := "off"
+			if bmh.Status.PoweredOn {
+				power = "on"
+			}
+			msg = fmt.Sprintf("phase %s, power %s", bmh.Status.Phase, power)
+		}
+		out = append(out, alertObservation{Target: key, Message: msg})
+	}
+	return out
+}
+
+// observeJobs reports Jobs in a matching phase (default: Failed, TimedOut).
+// Must be called with p.mu held.
+func (p *MicroKubeProvider) observeJobs(m AlertMatch) []alertObservation {
+	phases := m.Phases
+	if len(phases) == 0 {
+		phases = []string{"Failed", "TimedOut"}
+	}
+
+	var out []alertObservation
+	for key, job := range p.jobs {
+		if m.Namespace != "" && job.Namespace != m.Namespace {
+			continue
+		}
+		if !globMatch(m.Name, job.Name) {
+			continue
+		}
+		if !containsFold(phases, job.Status.Phase) {
+			continue
+		}
+		msg := fmt.Sprintf("job is %s", job.Status.Phase)
+		if job.Spec.Pool != "" {
+			msg += " in pool " + job.Spec.Pool
+		}
+		out = append(out, alertObservation{Target: key, Message: msg})
+	}
+	return out
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// ─── Events ─────────────────────────────────────────────────────────────────
+
+// recordAlertEvent appends an event for an AlertRule to the event ring buffer.
+func (p *MicroKubeProvider) recordAlertEvent(ar *AlertRule, reason, message, eventType string) {
+	now := metav1.Now()
+	p.appendEvent(corev1.Event{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Event"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              fmt.Sprintf("%s.%x", ar.Name, now.UnixNano()),
+			CreationTimestamp: now,
+		},
+		InvolvedObject: corev1.ObjectReference{
+			Kind: "AlertRule",
+			Name: ar.Name,
+		},
+		Reason:         reason,
+		Message:        message,
+		Type:           eventType,
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+		Source:         corev1.EventSource{Component: "mkube-alerts", Host: p.nodeName},
+	})
+}
+
+// ─── Sinks ──────────────────────────────────────────────────────────────────
+
+func (p *MicroKubeProvider) deliverAlert(ctx context.Context, log *zap.SugaredLogger, d alertDelivery) {
+	payload, err := json.Marshal(d.note)
+	if err != nil {
+		return
+	}
+
+	for _, sink := range d.sinks {
+		var err error
+		switch sink.Type {
+		case "webhook":
+			err = sendAlertWebhook(ctx, sink.URL, payload)
+		case "email":
+			err = p.sendAlertEmail(sink.To, d.note)
+		case "nats":
+			subject := sink.Subject
+			if subject == "" {
+				subject = "mkube.alerts." + d.note.Rule
+			}
+			if p.deps.Store == nil {
+				err = fmt.Errorf("NATS store not connected")
+			} else {
+				err = p.deps.Store.Publish(subject, payload)
+			}
+		}
+		if err != nil {
+			log.Warnw("alert delivery failed",
+				"rule", d.note.Rule, "target", d.note.Target, "sink", sink.Type, "error", err)
+		}
+	}
+}
+
+func sendAlertWebhook(ctx context.Context, url string, payload []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, alertSinkTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+func (p *MicroKubeProvider) sendAlertEmail(to []string, n alertNotification) error {
+	cfg := p.deps.Config.Alerting.SMTP
+	if cfg.Host == "" || cfg.From == "" {
+		return fmt.Errorf("alerting.smtp host/from not configured")
+	}
+	port := cfg.Port
+	if port == 0 {
+		port = 25
+	}
+
+	var auth smtp.Auth
+	if cfg.Username != "" {
+		auth = smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)
+	}
+
+	var body bytes.Buffer
+	fmt.Fprintf(&body, "From: %s\r\n", cfg.From)
+	fmt.Fprintf(&body, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&body, "Subject: [%s] %s %s\r\n", strings.ToUpper(n.Status), n.Rule, n.Target)
+	fmt.Fprintf(&body, "Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	fmt.Fprintf(&body, "Rule:     %s\r\n", n.Rule)
+	fmt.Fprintf(&body, "Severity: %s\r\n", n.Severity)
+	fmt.Fprintf(&body, "Target:   %s\r\n", n.Target)
+	fmt.Fprintf(&body, "Node:     %s\r\n", n.Node)
+	fmt.Fprintf(&body, "Since:    %s\r\n", n.StartsAt)
+	if n.EndsAt != "" {
+		fmt.Fprintf(&body, "Resolved: %s\r\n", n.EndsAt)
+	}
+	if n.Summary != "" {
+		fmt.Fprintf(&body, "\r\n%s\r\n", n.Summary)
+	}
+	if n.Message != "" {
+		fmt.Fprintf(&body, "\r\n%s\r\n", n.Message)
+	}
+
+	// smtp.SendMail has no timeout, so drive the client over a deadline-bound conn.
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(cfg.Host, strconv.Itoa(port)), alertSinkTimeout)
+	if err != nil {
+		return err
+	}
+	_ = conn.SetDeadline(time.Now().Add(alertSinkTimeout))
+
+	c, err := smtp.NewClient(conn, cfg.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: cfg.Host}); err != nil {
+			return err
+		}
+	}
+	if auth != nil {
+		if err := c.Auth(auth); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(cfg.From); err != nil {
+		return err
+	}
+	for _, rcpt := range to {
+		if err := c.Rcpt(rcpt); err != nil {
+			return err
+		}
+	}
+	wc, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := wc.Write(body.Bytes()); err != nil {
+		return err
+	}
+	if err := wc.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
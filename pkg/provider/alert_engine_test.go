@@ -0,0 +1,197 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/glennswest/mkube/pkg/cluster"
+	"github.com/glennswest/mkube/pkg/config"
+)
+
+func TestEvaluateAlertRuleLifecycle(t *testing.T) {
+	ar := &AlertRule{
+		ObjectMeta: metav1.ObjectMeta{Name: "dns-down"},
+		Spec:       AlertRuleSpec{Source: "dns", For: "1m"},
+	}
+	obs := []alertObservation{{Target: "g10", Message: "not answering"}}
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// First sighting: pending, no notification
+	status, notes := evaluateAlertRule(ar, obs, nil, false, t0)
+	if status.State != alertStatePending || len(notes) != 0 {
+		t.Fatalf("expected Pending with no notes, got %s / %d notes", status.State, len(notes))
+	}
+	ar.Status = status
+
+	// Hold duration elapsed: firing with one notification
+	status, notes = evaluateAlertRule(ar, obs, nil, false, t0.Add(time.Minute))
+	if status.State != alertStateFiring || len(notes) != 1 || notes[0].Status != "firing" {
+		t.Fatalf("expected Firing with 1 firing note, got %s / %+v", status.State, notes)
+	}
+	ar.Status = status
+
+	// Still firing, no repeat interval: no new notification
+	status, notes = evaluateAlertRule(ar, obs, nil, false, t0.Add(2*time.Minute))
+	if len(notes) != 0 {
+		t.Fatalf("expected no repeat notification, got %d", len(notes))
+	}
+	ar.Status = status
+
+	// Condition cleared: resolved notification and Inactive
+	status, notes = evaluateAlertRule(ar, nil, nil, false, t0.Add(3*time.Minute))
+	if status.State != alertStateInactive || len(notes) != 1 || notes[0].Status != "resolved" {
+		t.Fatalf("expected Inactive with resolved note, got %s / %+v", status.State, notes)
+	}
+}
+
+func TestEvaluateAlertRuleSilencedAndInhibited(t *testing.T) {
+	ar := &AlertRule{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod-pending"},
+		Spec:       AlertRuleSpec{Source: "pod"},
+	}
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	obs := []alertObservation{{Target: "gt/web"}, {Target: "g10/dns"}}
+
+	silence := &AlertSilence{Spec: AlertSilenceSpec{
+		Rule:     "pod-*",
+		Target:   "gt/*",
+		StartsAt: now.Add(-time.Hour).Format(time.RFC3339),
+		EndsAt:   now.Add(time.Hour).Format(time.RFC3339),
+	}}
+
+	status, notes := evaluateAlertRule(ar, obs, []*AlertSilence{silence}, false, now)
+	if status.State != alertStateFiring {
+		t.Fatalf("expected Firing, got %s", status.State)
+	}
+	if len(notes) != 1 || notes[0].Target != "g10/dns" {
+		t.Fatalf("expected only unsilenced target notified, got %+v", notes)
+	}
+
+	ar.Status = AlertRuleStatus{}
+	_, notes = evaluateAlertRule(ar, obs, nil, true, now)
+	if len(notes) != 0 {
+		t.Fatalf("expected inhibited rule to send nothing, got %d", len(notes))
+	}
+}
+
+func TestAlertOnFollowerNode(t *testing.T) {
+	ctx := context.Background()
+	log := zap.NewNop().Sugar()
+
+	var mu sync.Mutex
+	var notes []alertNotification
+	sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var n alertNotification
+		_ = json.NewDecoder(r.Body).Decode(&n)
+		mu.Lock()
+		notes = append(notes, n)
+		mu.Unlock()
+	}))
+	defer sink.Close()
+
+	// The rule, synced to both nodes, matches a pod that runs only on node-b
+	rule := func() *AlertRule {
+		return &AlertRule{
+			ObjectMeta: metav1.ObjectMeta{Name: "web-running"},
+			Spec: AlertRuleSpec{Source: "pod", Match: AlertMatch{Name: "web", Phases: []string{"Running"}},
+				Sinks: []AlertSink{{Type: "webhook", URL: sink.URL}}},
+		}
+	}
+	follower, _ := newTestProvider(t)
+	follower.nodeName = "node-b"
+	follower.clusterMgr = cluster.New("node-b", config.ClusterConfig{Peers: []config.PeerConfig{{Name: "node-a"}}}, nil, "arm64", log)
+	follower.clusterMgr.SetPeerHealthy("node-a", true)
+	follower.alertRules["web-running"] = rule()
+	if err := follower.CreatePod(ctx, testPod("web", "app")); err != nil {
+		t.Fatalf("CreatePod: %v", err)
+	}
+	mux := http.NewServeMux()
+	follower.RegisterRoutes(mux)
+	srv := httptest.NewServer(follower.WrapHandler(mux))
+	defer srv.Close()
+
+	leader, _ := newTestProvider(t)
+	leader.nodeName = "node-a"
+	leader.clusterMgr = cluster.New("node-a", config.ClusterConfig{Peers: []config.PeerConfig{{Name: "node-b", Address: srv.URL}}}, nil, "arm64", log)
+	leader.clusterMgr.SetPeerHealthy("node-b", true)
+	leader.alertRules["web-running"] = rule()
+
+	// The follower leaves evaluation to the leader, which fires for the
+	// follower's pod
+	follower.alertTick(ctx, log)
+	leader.alertTick(ctx, log)
+	mu.Lock()
+	if len(notes) != 1 || notes[0].Status != "firing" || notes[0].Target != "default/web" || notes[0].Node != "node-b" {
+		t.Fatalf("notifications = %+v, want one firing for default/web on node-b", notes)
+	}
+	mu.Unlock()
+
+	// While the follower is unreachable its alert is carried over, neither
+	// resolved nor notified again
+	leader.clusterMgr.SetPeerHealthy("node-b", false)
+	leader.alertTick(ctx, log)
+	if st := leader.alertRules["web-running"].Status; st.State != alertStateFiring || len(st.Alerts) != 1 {
+		t.Errorf("status while follower unreachable = %+v, want the alert still firing", st)
+	}
+
+	// A new leader inherits the synced status and does not notify again
+	leader.clusterMgr.SetPeerHealthy("node-b", true)
+	follower.alertRules["web-running"].Status = leader.alertRules["web-running"].Status
+	follower.clusterMgr.SetPeerHealthy("node-a", false)
+	follower.alertTick(ctx, log)
+	mu.Lock()
+	defer mu.Unlock()
+	if len(notes) != 1 {
+		t.Errorf("notifications after failover = %+v, want no new ones", notes[1:])
+	}
+	if st := follower.alertRules["web-running"].Status; st.State != alertStateFiring {
+		t.Errorf("status after failover = %+v, want firing", st)
+	}
+}
+
+func TestAlertSilenceWindow(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	s := &AlertSilence{Spec: AlertSilenceSpec{
+		StartsAt: now.Add(time.Hour).Format(time.RFC3339),
+		EndsAt:   now.Add(2 * time.Hour).Format(time.RFC3339),
+	}}
+
+	if s.activeAt(now) || s.state(now) != "Pending" {
+		t.Errorf("expected silence to be pending before startsAt")
+	}
+	if !s.activeAt(now.Add(90 * time.Minute)) {
+		t.Errorf("expected silence to be active inside window")
+	}
+	if s.activeAt(now.Add(3*time.Hour)) || s.state(now.Add(3*time.Hour)) != "Expired" {
+		t.Errorf("expected silence to be expired after endsAt")
+	}
+}
+
+func TestValidateAlertRule(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    AlertRuleSpec
+		wantErr bool
+	}{
+		{"valid", AlertRuleSpec{Source: "job", For: "5m", Sinks: []AlertSink{{Type: "nats"}}}, false},
+		{"bad source", AlertRuleSpec{Source: "cpu"}, true},
+		{"bad duration", AlertRuleSpec{Source: "pod", For: "five"}, true},
+		{"webhook without url", AlertRuleSpec{Source: "pod", Sinks: []AlertSink{{Type: "webhook"}}}, true},
+		{"email without to", AlertRuleSpec{Source: "pod", Sinks: []AlertSink{{Type: "email"}}}, true},
+		{"self inhibit", AlertRuleSpec{Source: "pod", InhibitedBy: []string{"r"}}, true},
+	}
+	for _, tt := range tests {
+		ar := &AlertRule{ObjectMeta: metav1.ObjectMeta{Name: "r"}, Spec: tt.spec}
+		if err := validateAlertRule(ar); (err != nil) != tt.wantErr {
+			t.Errorf("%s: err=%v, wantErr=%v", tt.name, err, tt.wantErr)
+		}
+	}
+}
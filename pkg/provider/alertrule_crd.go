@@ -0,0 +1,965 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kruntime "k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/glennswest/mkube/pkg/store"
+)
+
+// ─── Types ──────────────────────────────────────────────────────────────────
+
+// AlertRule is a cluster-scoped CRD that raises an alert when observed
+// cluster state matches a condition for longer than a hold duration.
+type AlertRule struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              AlertRuleSpec   `json:"spec"`
+	Status            AlertRuleStatus `json:"status,omitempty"`
+}
+
+// AlertRuleSpec defines the condition an AlertRule evaluates and where
+// notifications are delivered.
+type AlertRuleSpec struct {
+	Source         string            `json:"source"`                   // consistency, pod, bmh, job, dns
+	Match          AlertMatch        `json:"match,omitempty"`          // condition within the source
+	For            string            `json:"for,omitempty"`            // hold duration before firing, e.g. "5m"
+	Severity       string            `json:"severity,omitempty"`       // critical, warning (default), info
+	Summary        string            `json:"summary,omitempty"`        // human-readable description
+	InhibitedBy    []string          `json:"inhibitedBy,omitempty"`    // suppress notifications while these rules fire
+	RepeatInterval string            `json:"repeatInterval,omitempty"` // re-notify while firing, e.g. "4h"
+	Sinks          []AlertSink       `json:"sinks,omitempty"`
+	Labels         map[string]string `json:"labels,omitempty"` // copied into notifications
+	Disabled       bool              `json:"disabled,omitempty"`
+}
+
+// AlertMatch selects the targets within a source that are considered active.
+// Empty fields match everything; per-source defaults are applied when no
+// condition is given (see alertRuleConditionDefaults).
+type AlertMatch struct {
+	Category  string   `json:"category,omitempty"`  // consistency category, e.g. "dns", "podLiveness"
+	Name      string   `json:"name,omitempty"`      // glob on the target name
+	Namespace string   `json:"namespace,omitempty"` // pod/bmh/job namespace
+	Statuses  []string `json:"statuses,omitempty"`  // consistency item statuses (default: fail)
+	Phases    []string `json:"phases,omitempty"`    // pod/bmh/job phases
+	PoweredOn *bool    `json:"poweredOn,omitempty"` // bmh power state
+}
+
+// AlertSink is a notification destination.
+type AlertSink struct {
+	Type    string   `json:"type"`              // webhook, email, nats
+	URL     string   `json:"url,omitempty"`     // webhook target
+	To      []string `json:"to,omitempty"`      // email recipients
+	Subject string   `json:"subject,omitempty"` // NATS subject (default: mkube.alerts.<rule>)
+}
+
+// AlertRuleStatus reports the observed state of an AlertRule.
+type AlertRuleStatus struct {
+	State     string          `json:"state,omitempty"` // Inactive, Pending, Firing
+	Alerts    []AlertInstance `json:"alerts,omitempty"`
+	LastEval  string          `json:"lastEval,omitempty"`
+	LastError string          `json:"lastError,omitempty"`
+}
+
+// AlertInstance is one target for which a rule's condition currently holds.
+type AlertInstance struct {
+	Target       string `json:"target"`
+	Node         string `json:"node,omitempty"` // node that observed a node-local target; "" for cluster-wide ones
+	State        string `json:"state"` // Pending, Firing
+	Message      string `json:"message,omitempty"`
+	ActiveSince  string `json:"activeSince"`
+	FiredAt      string `json:"firedAt,omitempty"`
+	LastNotified string `json:"lastNotified,omitempty"`
+	Silenced     bool   `json:"silenced,omitempty"`
+	Inhibited    bool   `json:"inhibited,omitempty"`
+}
+
+// AlertRuleList is a list of AlertRule objects.
+type AlertRuleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+	Items           []AlertRule `json:"items"`
+}
+
+// DeepCopy returns a deep copy of the AlertRule.
+func (a *AlertRule) DeepCopy() *AlertRule {
+	out := *a
+	out.ObjectMeta = *a.ObjectMeta.DeepCopy()
+	out.Spec.Match.Statuses = append([]string(nil), a.Spec.Match.Statuses...)
+	out.Spec.Match.Phases = append([]string(nil), a.Spec.Match.Phases...)
+	if a.Spec.Match.PoweredOn != nil {
+		v := *a.Spec.Match.PoweredOn
+		out.Spec.Match.PoweredOn = &v
+	}
+	out.Spec.InhibitedBy = append([]string(nil), a.Spec.InhibitedBy...)
+	if a.Spec.Sinks != nil {
+		out.Spec.Sinks = make([]AlertSink, len(a.Spec.Sinks))
+		for i, s := range a.Spec.Sinks {
+			s.To = append([]string(nil), s.To...)
+			out.Spec.Sinks[i] = s
+		}
+	}
+	if a.Spec.Labels != nil {
+		out.Spec.Labels = make(map[string]string, len(a.Spec.Labels))
+		for k, v := range a.Spec.Labels {
+			out.Spec.Labels[k] = v
+		}
+	}
+	out.Status.Alerts = append([]AlertInstance(nil), a.Status.Alerts...)
+	return &out
+}
+
+// AlertSilence mutes notifications for matching alerts during a time window.
+// Silenced alerts are still evaluated and reported, but no sink is notified.
+type AlertSilence struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              AlertSilenceSpec `json:"spec"`
+}
+
+// AlertSilenceSpec defines which alerts a silence applies to and when.
+type AlertSilenceSpec struct {
+	Rule      string `json:"rule,omitempty"`     // glob on the AlertRule name (empty = all)
+	Target    string `json:"target,omitempty"`   // glob on the alert target (empty = all)
+	StartsAt  string `json:"startsAt,omitempty"` // RFC3339 (default: now)
+	EndsAt    string `json:"endsAt"`             // RFC3339
+	Comment   string `json:"comment,omitempty"`
+	CreatedBy string `json:"createdBy,omitempty"`
+}
+
+// AlertSilenceList is a list of AlertSilence objects.
+type AlertSilenceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+	Items           []AlertSilence `json:"items"`
+}
+
+// DeepCopy returns a deep copy of the AlertSilence.
+func (s *AlertSilence) DeepCopy() *AlertSilence {
+	out := *s
+	out.ObjectMeta = *s.ObjectMeta.DeepCopy()
+	return &out
+}
+
+// activeAt returns true if the silence window covers the given time.
+func (s *AlertSilence) activeAt(now time.Time) bool {
+	if s.Spec.StartsAt != "" {
+		start, err := time.Parse(time.RFC3339, s.Spec.StartsAt)
+		if err != nil || now.Before(start) {
+			return false
+		}
+	}
+	end, err := time.Parse(time.RFC3339, s.Spec.EndsAt)
+	if err != nil {
+		return false
+	}
+	return now.Before(end)
+}
+
+// matches returns true if the silence applies to the given rule and target.
+func (s *AlertSilence) matches(rule, target string) bool {
+	return globMatch(s.Spec.Rule, rule) && globMatch(s.Spec.Target, target)
+}
+
+// state returns Pending, Active or Expired for display.
+func (s *AlertSilence) state(now time.Time) string {
+	if s.activeAt(now) {
+		return "Active"
+	}
+	if s.Spec.StartsAt != "" {
+		if start, err := time.Parse(time.RFC3339, s.Spec.StartsAt); err == nil && now.Before(start) {
+			return "Pending"
+		}
+	}
+	return "Expired"
+}
+
+// globMatch matches name against a shell glob. An empty pattern matches anything.
+func globMatch(pattern, name string) bool {
+	if pattern == "" || pattern == "*" {
+		return true
+	}
+	ok, err := path.Match(pattern, name)
+	return err == nil && ok
+}
+
+// ─── Validation ─────────────────────────────────────────────────────────────
+
+var validAlertSources = map[string]bool{
+	"consistency": true,
+	"pod":         true,
+	"bmh":         true,
+	"job":         true,
+	"dns":         true,
+}
+
+// validateAlertRule checks an AlertRule spec for errors before saving.
+func validateAlertRule(ar *AlertRule) error {
+	if !validAlertSources[ar.Spec.Source] {
+		return fmt.Errorf("spec.source %q must be one of consistency, pod, bmh, job, dns", ar.Spec.Source)
+	}
+	if ar.Spec.For != "" {
+		if _, err := time.ParseDuration(ar.Spec.For); err != nil {
+			return fmt.Errorf("spec.for: %v", err)
+		}
+	}
+	if ar.Spec.RepeatInterval != "" {
+		if _, err := time.ParseDuration(ar.Spec.RepeatInterval); err != nil {
+			return fmt.Errorf("spec.repeatInterval: %v", err)
+		}
+	}
+	if ar.Spec.Match.Name != "" {
+		if _, err := path.Match(ar.Spec.Match.Name, ""); err != nil {
+			return fmt.Errorf("spec.match.name: %v", err)
+		}
+	}
+	for _, inh := range ar.Spec.InhibitedBy {
+		if inh == ar.Name {
+			return fmt.Errorf("spec.inhibitedBy: rule cannot inhibit itself")
+		}
+	}
+	for i, sink := range ar.Spec.Sinks {
+		switch sink.Type {
+		case "webhook":
+			if !strings.HasPrefix(sink.URL, "http://") && !strings.HasPrefix(sink.URL, "https://") {
+				return fmt.Errorf("spec.sinks[%d]: webhook requires an http(s) url", i)
+			}
+		case "email":
+			if len(sink.To) == 0 {
+				return fmt.Errorf("spec.sinks[%d]: email requires at least one recipient in to", i)
+			}
+		case "nats":
+		default:
+			return fmt.Errorf("spec.sinks[%d]: type %q must be webhook, email or nats", i, sink.Type)
+		}
+	}
+	return nil
+}
+
+// ─── Store Operations ────────────────────────────────────────────────────────
+
+func (p *MicroKubeProvider) LoadAlertRulesFromStore(ctx context.Context) {
+	if p.deps.Store == nil || p.deps.Store.AlertRules == nil {
+		return
+	}
+
+	keys, err := p.deps.Store.AlertRules.Keys(ctx, "")
+	if err != nil {
+		p.deps.Logger.Warnw("failed to list alert rules from store", "error", err)
+		return
+	}
+
+	for _, key := range keys {
+		var ar AlertRule
+		if _, err := p.deps.Store.AlertRules.GetJSON(ctx, key, &ar); err != nil {
+			p.deps.Logger.Warnw("failed to read alert rule from store", "key", key, "error", err)
+			continue
+		}
+		p.alertRules[ar.Name] = &ar
+	}
+
+	if len(keys) > 0 {
+		p.deps.Logger.Infow("loaded alert rules from store", "count", len(keys))
+	}
+
+	p.loadAlertSilencesFromStore(ctx)
+}
+
+func (p *MicroKubeProvider) loadAlertSilencesFromStore(ctx context.Context) {
+	if p.deps.Store == nil || p.deps.Store.AlertSilences == nil {
+		return
+	}
+
+	keys, err := p.deps.Store.AlertSilences.Keys(ctx, "")
+	if err != nil {
+		p.deps.Logger.Warnw("failed to list alert silences from store", "error", err)
+		return
+	}
+
+	for _, key := range keys {
+		var s AlertSilence
+		if _, err := p.deps.Store.AlertSilences.GetJSON(ctx, key, &s); err != nil {
+			p.deps.Logger.Warnw("failed to read alert silence from store", "key", key, "error", err)
+			continue
+		}
+		p.alertSilences[s.Name] = &s
+	}
+}
+
+// reloadAlertsFromStore replaces the in-memory alert rules and silences
+// with the store's. The buckets are synced, so in a cluster this picks up
+// rules and silences created on peers and the status the leader wrote.
+// Must be called without p.mu held; the store is read before the write lock
+// is taken.
+func (p *MicroKubeProvider) reloadAlertsFromStore(ctx context.Context) {
+	if p.deps.Store == nil || p.deps.Store.AlertRules == nil || p.deps.Store.AlertSilences == nil {
+		return
+	}
+
+	ruleKeys, err := p.deps.Store.AlertRules.Keys(ctx, "")
+	if err != nil {
+		p.deps.Logger.Warnw("failed to reload alert rules from store", "error", err)
+		return
+	}
+	rules := make(map[string]*AlertRule, len(ruleKeys))
+	for _, key := range ruleKeys {
+		var ar AlertRule
+		if _, err := p.deps.Store.AlertRules.GetJSON(ctx, key, &ar); err == nil {
+			rules[ar.Name] = &ar
+		}
+	}
+
+	silenceKeys, err := p.deps.Store.AlertSilences.Keys(ctx, "")
+	if err != nil {
+		p.deps.Logger.Warnw("failed to reload alert silences from store", "error", err)
+		return
+	}
+	silences := make(map[string]*AlertSilence, len(silenceKeys))
+	for _, key := range silenceKeys {
+		var s AlertSilence
+		if _, err := p.deps.Store.AlertSilences.GetJSON(ctx, key, &s); err == nil {
+			silences[s.Name] = &s
+		}
+	}
+
+	p.mu.Lock()
+	p.alertRules = rules
+	p.alertSilences = silences
+	p.mu.Unlock()
+}
+
+func (p *MicroKubeProvider) persistAlertRule(ctx context.Context, ar *AlertRule) {
+	if p.deps.Store != nil && p.deps.Store.AlertRules != nil {
+		if _, err := p.deps.Store.AlertRules.PutJSON(ctx, ar.Name, ar); err != nil {
+			p.deps.Logger.Warnw("failed to persist AlertRule", "name", ar.Name, "error", err)
+		}
+	}
+}
+
+func (p *MicroKubeProvider) persistAlertSilence(ctx context.Context, s *AlertSilence) {
+	if p.deps.Store != nil && p.deps.Store.AlertSilences != nil {
+		if _, err := p.deps.Store.AlertSilences.PutJSON(ctx, s.Name, s); err != nil {
+			p.deps.Logger.Warnw("failed to persist AlertSilence", "name", s.Name, "error", err)
+		}
+	}
+}
+
+// ─── CRUD Handlers ──────────────────────────────────────────────────────────
+
+func (p *MicroKubeProvider) handleListAlertRules(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("watch") == "true" {
+		p.handleWatchAlertRules(w, r)
+		return
+	}
+
+	items := make([]AlertRule, 0, len(p.alertRules))
+	for _, ar := range p.alertRules {
+		c := ar.DeepCopy()
+		c.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "AlertRule"}
+		items = append(items, *c)
+	}
+
+	if wantsTable(r) {
+		podWriteJSON(w, http.StatusOK, alertRuleListToTable(items))
+		return
+	}
+
+	podWriteJSON(w, http.StatusOK, AlertRuleList{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "AlertRuleList"},
+		Items:    items,
+	})
+}
+
+func (p *MicroKubeProvider) handleGetAlertRule(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+
+	ar, ok := p.alertRules[name]
+	if !ok {
+		http.Error(w, fmt.Sprintf("AlertRule %q not found", name), http.StatusNotFound)
+		return
+	}
+
+	c := ar.DeepCopy()
+	c.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "AlertRule"}
+
+	if wantsTable(r) {
+		podWriteJSON(w, http.StatusOK, alertRuleListToTable([]AlertRule{*c}))
+		return
+	}
+
+	podWriteJSON(w, http.StatusOK, c)
+}
+
+func (p *MicroKubeProvider) handleCreateAlertRule(w http.ResponseWriter, r *http.Request) {
+	var ar AlertRule
+	if err := json.NewDecoder(r.Body).Decode(&ar); err != nil {
+		http.Error(w, fmt.Sprintf("invalid AlertRule JSON: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if ar.Name == "" {
+		http.Error(w, "AlertRule name is required", http.StatusBadRequest)
+		return
+	}
+
+	if _, exists := p.alertRules[ar.Name]; exists {
+		http.Error(w, fmt.Sprintf("AlertRule %q already exists", ar.Name), http.StatusConflict)
+		return
+	}
+
+	if err := validateAlertRule(&ar); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ar.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "AlertRule"}
+	if ar.CreationTimestamp.IsZero() {
+		ar.CreationTimestamp = metav1.Now()
+	}
+	if ar.Spec.Severity == "" {
+		ar.Spec.Severity = "warning"
+	}
+	ar.Status = AlertRuleStatus{State: alertStateInactive}
+
+	p.persistAlertRule(r.Context(), &ar)
+	p.alertRules[ar.Name] = &ar
+
+	podWriteJSON(w, http.StatusCreated, &ar)
+}
+
+func (p *MicroKubeProvider) handleUpdateAlertRule(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+
+	old, ok := p.alertRules[name]
+	if !ok {
+		http.Error(w, fmt.Sprintf("AlertRule %q not found", name), http.StatusNotFound)
+		return
+	}
+
+	var ar AlertRule
+	if err := json.NewDecoder(r.Body).Decode(&ar); err != nil {
+		http.Error(w, fmt.Sprintf("invalid AlertRule JSON: %v", err), http.StatusBadRequest)
+		return
+	}
+	ar.Name = name
+	ar.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "AlertRule"}
+
+	if err := validateAlertRule(&ar); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if ar.CreationTimestamp.IsZero() {
+		ar.CreationTimestamp = old.CreationTimestamp
+	}
+	if ar.Spec.Severity == "" {
+		ar.Spec.Severity = "warning"
+	}
+	// Status is owned by the alert engine
+	ar.Status = old.Status
+
+	p.persistAlertRule(r.Context(), &ar)
+	p.alertRules[name] = &ar
+
+	podWriteJSON(w, http.StatusOK, &ar)
+}
+
+func (p *MicroKubeProvider) handlePatchAlertRule(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+
+	existing, ok := p.alertRules[name]
+	if !ok {
+		http.Error(w, fmt.Sprintf("AlertRule %q not found", name), http.StatusNotFound)
+		return
+	}
+
+	merged := existing.DeepCopy()
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("reading body: %v", err), http.StatusBadRequest)
+		return
+	}
+	if err := json.Unmarshal(body, merged); err != nil {
+		http.Error(w, fmt.Sprintf("invalid patch JSON: %v", err), http.StatusBadRequest)
+		return
+	}
+	merged.Name = name
+	merged.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "AlertRule"}
+	merged.CreationTimestamp = existing.CreationTimestamp
+	merged.Status = existing.Status
+
+	if err := validateAlertRule(merged); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	p.persistAlertRule(r.Context(), merged)
+	p.alertRules[name] = merged
+
+	podWriteJSON(w, http.StatusOK, merged)
+}
+
+func (p *MicroKubeProvider) handleDeleteAlertRule(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+
+	if _, ok := p.alertRules[name]; !ok {
+		http.Error(w, fmt.Sprintf("AlertRule %q not found", name), http.StatusNotFound)
+		return
+	}
+
+	if p.deps.Store != nil && p.deps.Store.AlertRules != nil {
+		if err := p.deps.Store.AlertRules.Delete(r.Context(), name); err != nil {
+			http.Error(w, fmt.Sprintf("deleting AlertRule from store: %v", err), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	delete(p.alertRules, name)
+
+	podWriteJSON(w, http.StatusOK, metav1.Status{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Status"},
+		Status:   "Success",
+		Message:  fmt.Sprintf("AlertRule %q deleted", name),
+	})
+}
+
+// ActiveAlert is a flattened view of one pending or firing alert instance.
+type ActiveAlert struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity,omitempty"`
+	Source   string `json:"source"`
+	Summary  string `json:"summary,omitempty"`
+	AlertInstance
+}
+
+// handleListAlerts returns every pending or firing alert across all rules.
+func (p *MicroKubeProvider) handleListAlerts(w http.ResponseWriter, r *http.Request) {
+	state := r.URL.Query().Get("state")
+
+	alerts := make([]ActiveAlert, 0)
+	for _, ar := range p.alertRules {
+		for _, inst := range ar.Status.Alerts {
+			if state != "" && !strings.EqualFold(state, inst.State) {
+				continue
+			}
+			alerts = append(alerts, ActiveAlert{
+				Rule:          ar.Name,
+				Severity:      ar.Spec.Severity,
+				Source:        ar.Spec.Source,
+				Summary:       ar.Spec.Summary,
+				AlertInstance: inst,
+			})
+		}
+	}
+
+	sort.Slice(alerts, func(i, j int) bool {
+		if alerts[i].Rule != alerts[j].Rule {
+			return alerts[i].Rule < alerts[j].Rule
+		}
+		return alerts[i].Target < alerts[j].Target
+	})
+
+	podWriteJSON(w, http.StatusOK, map[string]interface{}{
+		"items": alerts,
+		"count": len(alerts),
+	})
+}
+
+func (p *MicroKubeProvider) handleListAlertSilences(w http.ResponseWriter, r *http.Request) {
+	items := make([]AlertSilence, 0, len(p.alertSilences))
+	for _, s := range p.alertSilences {
+		c := s.DeepCopy()
+		c.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "AlertSilence"}
+		items = append(items, *c)
+	}
+
+	if wantsTable(r) {
+		podWriteJSON(w, http.StatusOK, alertSilenceListToTable(items))
+		return
+	}
+
+	podWriteJSON(w, http.StatusOK, AlertSilenceList{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "AlertSilenceList"},
+		Items:    items,
+	})
+}
+
+func (p *MicroKubeProvider) handleGetAlertSilence(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+
+	s, ok := p.alertSilences[name]
+	if !ok {
+		http.Error(w, fmt.Sprintf("AlertSilence %q not found", name), http.StatusNotFound)
+		return
+	}
+
+	c := s.DeepCopy()
+	c.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "AlertSilence"}
+	podWriteJSON(w, http.StatusOK, c)
+}
+
+func (p *MicroKubeProvider) handleCreateAlertSilence(w http.ResponseWriter, r *http.Request) {
+	var s AlertSilence
+	if err := json.NewDecoder(r.Body).Decode(&s); err != nil {
+		http.Error(w, fmt.Sprintf("invalid AlertSilence JSON: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	if s.Name == "" {
+		s.Name = fmt.Sprintf("silence-%d", now.Unix())
+	}
+	if _, exists := p.alertSilences[s.Name]; exists {
+		http.Error(w, fmt.Sprintf("AlertSilence %q already exists", s.Name), http.StatusConflict)
+		return
+	}
+
+	if s.Spec.StartsAt == "" {
+		s.Spec.StartsAt = now.UTC().Format(time.RFC3339)
+	}
+	start, err := time.Parse(time.RFC3339, s.Spec.StartsAt)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("spec.startsAt: %v", err), http.StatusBadRequest)
+		return
+	}
+	end, err := time.Parse(time.RFC3339, s.Spec.EndsAt)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("spec.endsAt: %v", err), http.StatusBadRequest)
+		return
+	}
+	if !end.After(start) {
+		http.Error(w, "spec.endsAt must be after spec.startsAt", http.StatusBadRequest)
+		return
+	}
+	for field, pattern := range map[string]string{"rule": s.Spec.Rule, "target": s.Spec.Target} {
+		if _, err := path.Match(pattern, ""); err != nil {
+			http.Error(w, fmt.Sprintf("spec.%s: %v", field, err), http.StatusBadRequest)
+			return
+		}
+	}
+
+	s.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "AlertSilence"}
+	if s.CreationTimestamp.IsZero() {
+		s.CreationTimestamp = metav1.Now()
+	}
+
+	p.persistAlertSilence(r.Context(), &s)
+	p.alertSilences[s.Name] = &s
+
+	podWriteJSON(w, http.StatusCreated, &s)
+}
+
+func (p *MicroKubeProvider) handleDeleteAlertSilence(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+
+	if _, ok := p.alertSilences[name]; !ok {
+		http.Error(w, fmt.Sprintf("AlertSilence %q not found", name), http.StatusNotFound)
+		return
+	}
+
+	if p.deps.Store != nil && p.deps.Store.AlertSilences != nil {
+		if err := p.deps.Store.AlertSilences.Delete(r.Context(), name); err != nil {
+			http.Error(w, fmt.Sprintf("deleting AlertSilence from store: %v", err), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	delete(p.alertSilences, name)
+
+	podWriteJSON(w, http.StatusOK, metav1.Status{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Status"},
+		Status:   "Success",
+		Message:  fmt.Sprintf("AlertSilence %q deleted", name),
+	})
+}
+
+// ─── Watch ──────────────────────────────────────────────────────────────────
+
+func (p *MicroKubeProvider) handleWatchAlertRules(w http.ResponseWriter, r *http.Request) {
+	if p.deps.Store == nil || p.deps.Store.AlertRules == nil {
+		http.Error(w, "watch requires NATS store", http.StatusServiceUnavailable)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Transfer-Encoding", "chunked")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	ctx := r.Context()
+	enc := json.NewEncoder(w)
+
+	p.mu.RLock()
+	snapshot := make([]*AlertRule, 0, len(p.alertRules))
+	for _, ar := range p.alertRules {
+		snapshot = append(snapshot, ar.DeepCopy())
+	}
+	p.mu.RUnlock()
+
+	for _, c := range snapshot {
+		c.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "AlertRule"}
+		if err := enc.Encode(K8sWatchEvent{Type: "ADDED", Object: c}); err != nil {
+			return
+		}
+		flusher.Flush()
+	}
+
+	events, err := p.deps.Store.AlertRules.WatchAll(ctx)
+	if err != nil {
+		return
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case evt, ok := <-events:
+			if !ok {
+				return
+			}
+			var ar AlertRule
+			if evt.Type == store.EventDelete {
+				ar = AlertRule{
+					TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "AlertRule"},
+					ObjectMeta: metav1.ObjectMeta{Name: evt.Key},
+				}
+			} else {
+				if err := json.Unmarshal(evt.Value, &ar); err != nil {
+					continue
+				}
+				ar.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "AlertRule"}
+			}
+			if err := enc.Encode(K8sWatchEvent{Type: string(evt.Type), Object: &ar}); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// ─── Table Format ───────────────────────────────────────────────────────────
+
+func alertRuleListToTable(items []AlertRule) *metav1.Table {
+	table := &metav1.Table{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "meta.k8s.io/v1",
+			Kind:       "Table",
+		},
+		ColumnDefinitions: []metav1.TableColumnDefinition{
+			{Name: "Name", Type: "string", Format: "name"},
+			{Name: "Source", Type: "string"},
+			{Name: "Severity", Type: "string"},
+			{Name: "State", Type: "string"},
+			{Name: "Firing", Type: "integer"},
+			{Name: "Pending", Type: "integer"},
+			{Name: "For", Type: "string"},
+			{Name: "Sinks", Type: "string"},
+			{Name: "Age", Type: "string"},
+		},
+	}
+
+	sort.Slice(items, func(i, j int) bool { return items[i].Name < items[j].Name })
+
+	for i := range items {
+		ar := &items[i]
+
+		age := "<unknown>"
+		if !ar.CreationTimestamp.IsZero() {
+			age = formatAge(time.Since(ar.CreationTimestamp.Time))
+		}
+
+		state := ar.Status.State
+		if ar.Spec.Disabled {
+			state = "Disabled"
+		} else if state == "" {
+			state = alertStateInactive
+		}
+
+		firing, pending := 0, 0
+		for _, inst := range ar.Status.Alerts {
+			switch inst.State {
+			case alertStateFiring:
+				firing++
+			case alertStatePending:
+				pending++
+			}
+		}
+
+		forStr := ar.Spec.For
+		if forStr == "" {
+			forStr = "-"
+		}
+
+		sinks := "-"
+		if len(ar.Spec.Sinks) > 0 {
+			types := make([]string, len(ar.Spec.Sinks))
+			for j, s := range ar.Spec.Sinks {
+				types[j] = s.Type
+			}
+			sinks = strings.Join(types, ",")
+		}
+
+		raw, _ := json.Marshal(map[string]interface{}{
+			"kind":       "PartialObjectMetadata",
+			"apiVersion": "meta.k8s.io/v1",
+			"metadata": map[string]interface{}{
+				"name":              ar.Name,
+				"creationTimestamp": ar.CreationTimestamp.Format(time.RFC3339),
+			},
+		})
+
+		table.Rows = append(table.Rows, metav1.TableRow{
+			Cells: []interface{}{
+				ar.Name,
+				ar.Spec.Source,
+				ar.Spec.Severity,
+				state,
+				firing,
+				pending,
+				forStr,
+				sinks,
+				age,
+			},
+			Object: kruntime.RawExtension{Raw: raw},
+		})
+	}
+
+	return table
+}
+
+func alertSilenceListToTable(items []AlertSilence) *metav1.Table {
+	table := &metav1.Table{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "meta.k8s.io/v1",
+			Kind:       "Table",
+		},
+		ColumnDefinitions: []metav1.TableColumnDefinition{
+			{Name: "Name", Type: "string", Format: "name"},
+			{Name: "Rule", Type: "string"},
+			{Name: "Target", Type: "string"},
+			{Name: "State", Type: "string"},
+			{Name: "Ends", Type: "string"},
+			{Name: "Comment", Type: "string"},
+		},
+	}
+
+	sort.Slice(items, func(i, j int) bool { return items[i].Name < items[j].Name })
+
+	now := time.Now()
+	for i := range items {
+		s := &items[i]
+
+		rule, target := s.Spec.Rule, s.Spec.Target
+		if rule == "" {
+			rule = "*"
+		}
+		if target == "" {
+			target = "*"
+		}
+
+		raw, _ := json.Marshal(map[string]interface{}{
+			"kind":       "PartialObjectMetadata",
+			"apiVersion": "meta.k8s.io/v1",
+			"metadata": map[string]interface{}{
+				"name":              s.Name,
+				"creationTimestamp": s.CreationTimestamp.Format(time.RFC3339),
+			},
+		})
+
+		table.Rows = append(table.Rows, metav1.TableRow{
+			Cells: []interface{}{
+				s.Name,
+				rule,
+				target,
+				s.state(now),
+				s.Spec.EndsAt,
+				s.Spec.Comment,
+			},
+			Object: kruntime.RawExtension{Raw: raw},
+		})
+	}
+
+	return table
+}
+
+// ─── Consistency ────────────────────────────────────────────────────────────
+
+func (p *MicroKubeProvider) checkAlertRuleCRDs(ctx context.Context) []CheckItem {
+	var items []CheckItem
+
+	if p.deps.Store != nil && p.deps.Store.AlertRules != nil {
+		storeKeys, err := p.deps.Store.AlertRules.Keys(ctx, "")
+		if err == nil {
+			storeSet := make(map[string]bool, len(storeKeys))
+			for _, k := range storeKeys {
+				storeSet[k] = true
+			}
+
+			for name := range p.alertRules {
+				if storeSet[name] {
+					items = append(items, CheckItem{
+						Name:    fmt.Sprintf("alertrule/%s", name),
+						Status:  "pass",
+						Message: "AlertRule CRD synced with NATS",
+					})
+				} else {
+					items = append(items, CheckItem{
+						Name:    fmt.Sprintf("alertrule/%s", name),
+						Status:  "fail",
+						Message: "AlertRule CRD in memory but not in NATS store",
+					})
+				}
+				delete(storeSet, name)
+			}
+
+			for name := range storeSet {
+				items = append(items, CheckItem{
+					Name:    fmt.Sprintf("alertrule/%s", name),
+					Status:  "warn",
+					Message: "AlertRule CRD in NATS but not in memory",
+				})
+			}
+		}
+	}
+
+	// Validate InhibitedBy references
+	for name, ar := range p.alertRules {
+		for _, inh := range ar.Spec.InhibitedBy {
+			if _, ok := p.alertRules[inh]; !ok {
+				items = append(items, CheckItem{
+					Name:    fmt.Sprintf("alertrule-ref/%s", name),
+					Status:  "warn",
+					Message: fmt.Sprintf("inhibitedBy references AlertRule %q which does not exist", inh),
+				})
+			}
+		}
+		if ar.Status.LastError != "" {
+			items = append(items, CheckItem{
+				Name:    fmt.Sprintf("alertrule-eval/%s", name),
+				Status:  "warn",
+				Message: ar.Status.LastError,
+			})
+		}
+	}
+
+	return items
+}
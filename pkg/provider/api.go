@@ -44,6 +44,18 @@ func (p *MicroKubeProvider) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("PATCH /api/v1/namespaces/{namespace}/configmaps/{name}", p.handlePatchConfigMap)
 	mux.HandleFunc("DELETE /api/v1/namespaces/{namespace}/configmaps/{name}", p.handleDeleteConfigMap)
 
+	// Secrets
+	mux.HandleFunc("POST /api/v1/namespaces/{namespace}/secrets", p.handleCreateSecret)
+	mux.HandleFunc("GET /api/v1/namespaces/{namespace}/secrets", p.handleListSecrets)
+	mux.HandleFunc("GET /api/v1/namespaces/{namespace}/secrets/{name}", p.handleGetSecret)
+	mux.HandleFunc("PUT /api/v1/namespaces/{namespace}/secrets/{name}", p.handleUpdateSecret)
+	mux.HandleFunc("PATCH /api/v1/namespaces/{namespace}/secrets/{name}", p.handlePatchSecret)
+	mux.HandleFunc("DELETE /api/v1/namespaces/{namespace}/secrets/{name}", p.handleDeleteSecret)
+
+	// WireGuard site links
+	mux.HandleFunc("GET /api/v1/wireguard", p.handleGetWireGuard)
+	mux.HandleFunc("POST /api/v1/wireguard/rotate", p.handleRotateWireGuardKey)
+
 	// Deployments
 	mux.HandleFunc("GET /api/v1/deployments", p.handleListAllDeployments)
 	mux.HandleFunc("GET /api/v1/namespaces/{namespace}/deployments", p.handleListNamespacedDeployments)
@@ -69,6 +81,9 @@ func (p *MicroKubeProvider) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("DELETE /api/v1/networks/{name}", p.handleDeleteNetwork)
 	mux.HandleFunc("GET /api/v1/networks/{name}/config", p.handleGetNetworkConfig)
 	mux.HandleFunc("POST /api/v1/networks/{name}/smoketest", p.handleNetworkSmokeTest)
+	mux.HandleFunc("POST /api/v1/networks/{name}/plan", p.handlePlanNetwork)
+	mux.HandleFunc("POST /api/v1/networks/{name}/apply", p.handleApplyNetwork)
+	mux.HandleFunc("GET /api/v1/networks/{name}/apply", p.handleGetNetworkApply)
 
 	// Registries (cluster-scoped)
 	mux.HandleFunc("GET /api/v1/registries", p.handleListRegistries)
@@ -78,6 +93,7 @@ func (p *MicroKubeProvider) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("PATCH /api/v1/registries/{name}", p.handlePatchRegistry)
 	mux.HandleFunc("DELETE /api/v1/registries/{name}", p.handleDeleteRegistry)
 	mux.HandleFunc("GET /api/v1/registries/{name}/config", p.handleGetRegistryConfig)
+	mux.HandleFunc("GET /api/v1/registries/{name}/catalog", p.handleGetRegistryCatalog)
 
 	// iSCSI CDROMs (cluster-scoped)
 	mux.HandleFunc("GET /api/v1/iscsi-cdroms", p.handleListISCSICdroms)
@@ -129,6 +145,12 @@ func (p *MicroKubeProvider) RegisterRoutes(mux *http.ServeMux) {
 
 	mux.HandleFunc("GET /api/v1/namespaces/{namespace}/dhcpleases", p.handleListDHCPLeases)
 
+	// DHCP device inventory (cluster-scoped, maintained from lease events)
+	mux.HandleFunc("GET /api/v1/dhcpdevices", p.handleListDHCPDevices)
+	mux.HandleFunc("GET /api/v1/dhcpdevices/{name}", p.handleGetDHCPDevice)
+	mux.HandleFunc("DELETE /api/v1/dhcpdevices/{name}", p.handleDeleteDHCPDevice)
+	mux.HandleFunc("POST /api/v1/dhcpdevices/{name}/promote", p.handlePromoteDHCPDevice)
+
 	mux.HandleFunc("GET /api/v1/namespaces/{namespace}/dnsforwarders", p.handleListDNSForwarders)
 	mux.HandleFunc("GET /api/v1/namespaces/{namespace}/dnsforwarders/{name}", p.handleGetDNSForwarder)
 	mux.HandleFunc("POST /api/v1/namespaces/{namespace}/dnsforwarders", p.handleCreateDNSForwarder)
@@ -154,6 +176,24 @@ func (p *MicroKubeProvider) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("PATCH /api/v1/namespaces/{namespace}/hostreservations/{name}", p.handlePatchHostReservation)
 	mux.HandleFunc("DELETE /api/v1/namespaces/{namespace}/hostreservations/{name}", p.handleDeleteHostReservation)
 
+	// IPAddressClaims (namespaced)
+	mux.HandleFunc("GET /api/v1/ipaddressclaims", p.handleListAllIPAddressClaims)
+	mux.HandleFunc("GET /api/v1/namespaces/{namespace}/ipaddressclaims", p.handleListNamespacedIPAddressClaims)
+	mux.HandleFunc("GET /api/v1/namespaces/{namespace}/ipaddressclaims/{name}", p.handleGetIPAddressClaim)
+	mux.HandleFunc("POST /api/v1/namespaces/{namespace}/ipaddressclaims", p.handleCreateIPAddressClaim)
+	mux.HandleFunc("PUT /api/v1/namespaces/{namespace}/ipaddressclaims/{name}", p.handleUpdateIPAddressClaim)
+	mux.HandleFunc("PATCH /api/v1/namespaces/{namespace}/ipaddressclaims/{name}", p.handlePatchIPAddressClaim)
+	mux.HandleFunc("DELETE /api/v1/namespaces/{namespace}/ipaddressclaims/{name}", p.handleDeleteIPAddressClaim)
+
+	// PortForwards (namespaced)
+	mux.HandleFunc("GET /api/v1/portforwards", p.handleListAllPortForwards)
+	mux.HandleFunc("GET /api/v1/namespaces/{namespace}/portforwards", p.handleListNamespacedPortForwards)
+	mux.HandleFunc("GET /api/v1/namespaces/{namespace}/portforwards/{name}", p.handleGetPortForward)
+	mux.HandleFunc("POST /api/v1/namespaces/{namespace}/portforwards", p.handleCreatePortForward)
+	mux.HandleFunc("PUT /api/v1/namespaces/{namespace}/portforwards/{name}", p.handleUpdatePortForward)
+	mux.HandleFunc("PATCH /api/v1/namespaces/{namespace}/portforwards/{name}", p.handlePatchPortForward)
+	mux.HandleFunc("DELETE /api/v1/namespaces/{namespace}/portforwards/{name}", p.handleDeletePortForward)
+
 	// JobRunners (cluster-scoped)
 	mux.HandleFunc("GET /api/v1/jobrunners", p.handleListJobRunners)
 	mux.HandleFunc("GET /api/v1/jobrunners/{name}", p.handleGetJobRunner)
@@ -176,6 +216,34 @@ func (p *MicroKubeProvider) RegisterRoutes(mux *http.ServeMux) {
 	// JobQueue (computed view)
 	mux.HandleFunc("GET /api/v1/jobqueue", p.handleGetJobQueue)
 
+	// AlertRules (cluster-scoped)
+	mux.HandleFunc("GET /api/v1/alertrules", p.handleListAlertRules)
+	mux.HandleFunc("GET /api/v1/alertrules/{name}", p.handleGetAlertRule)
+	mux.HandleFunc("POST /api/v1/alertrules", p.handleCreateAlertRule)
+	mux.HandleFunc("PUT /api/v1/alertrules/{name}", p.handleUpdateAlertRule)
+	mux.HandleFunc("PATCH /api/v1/alertrules/{name}", p.handlePatchAlertRule)
+	mux.HandleFunc("DELETE /api/v1/alertrules/{name}", p.handleDeleteAlertRule)
+	mux.HandleFunc("GET /api/v1/alertsilences", p.handleListAlertSilences)
+	mux.HandleFunc("GET /api/v1/alertsilences/{name}", p.handleGetAlertSilence)
+	mux.HandleFunc("POST /api/v1/alertsilences", p.handleCreateAlertSilence)
+	mux.HandleFunc("DELETE /api/v1/alertsilences/{name}", p.handleDeleteAlertSilence)
+	mux.HandleFunc("GET /api/v1/cluster/alert-observations", p.handleAlertObservations)
+	mux.HandleFunc("GET /api/v1/alerts", p.handleListAlerts)
+
+	// Admission webhook configurations (cluster-scoped)
+	mux.HandleFunc("GET /api/v1/mutatingwebhookconfigurations", p.handleListMutatingWebhookConfigurations)
+	mux.HandleFunc("GET /api/v1/mutatingwebhookconfigurations/{name}", p.handleGetMutatingWebhookConfiguration)
+	mux.HandleFunc("POST /api/v1/mutatingwebhookconfigurations", p.handleCreateMutatingWebhookConfiguration)
+	mux.HandleFunc("PUT /api/v1/mutatingwebhookconfigurations/{name}", p.handleUpdateMutatingWebhookConfiguration)
+	mux.HandleFunc("PATCH /api/v1/mutatingwebhookconfigurations/{name}", p.handlePatchMutatingWebhookConfiguration)
+	mux.HandleFunc("DELETE /api/v1/mutatingwebhookconfigurations/{name}", p.handleDeleteMutatingWebhookConfiguration)
+	mux.HandleFunc("GET /api/v1/validatingwebhookconfigurations", p.handleListValidatingWebhookConfigurations)
+	mux.HandleFunc("GET /api/v1/validatingwebhookconfigurations/{name}", p.handleGetValidatingWebhookConfiguration)
+	mux.HandleFunc("POST /api/v1/validatingwebhookconfigurations", p.handleCreateValidatingWebhookConfiguration)
+	mux.HandleFunc("PUT /api/v1/validatingwebhookconfigurations/{name}", p.handleUpdateValidatingWebhookConfiguration)
+	mux.HandleFunc("PATCH /api/v1/validatingwebhookconfigurations/{name}", p.handlePatchValidatingWebhookConfiguration)
+	mux.HandleFunc("DELETE /api/v1/validatingwebhookconfigurations/{name}", p.handleDeleteValidatingWebhookConfiguration)
+
 	// Agent endpoints (source-IP authenticated)
 	mux.HandleFunc("GET /api/v1/agent/work", p.handleAgentWork)
 	mux.HandleFunc("POST /api/v1/agent/heartbeat", p.handleAgentHeartbeat)
@@ -202,6 +270,8 @@ func (p *MicroKubeProvider) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("GET /api/v1", p.handleAPIResources)
 	mux.HandleFunc("GET /apis", p.handleAPIGroups)
 	mux.HandleFunc("GET /version", p.handleVersion)
+	mux.HandleFunc("GET /openapi/v3", p.handleOpenAPIIndex)
+	mux.HandleFunc("GET /openapi/v3/api/v1", p.handleOpenAPIV1)
 
 	// Export/Import
 	mux.HandleFunc("GET /api/v1/export", p.handleExport)
@@ -224,14 +294,19 @@ func (p *MicroKubeProvider) RegisterRoutes(mux *http.ServeMux) {
 	// Lifecycle stats
 	mux.HandleFunc("GET /api/v1/lifecycle/stats", p.handleLifecycleStats)
 
+	// Auth
+	mux.HandleFunc("GET /api/v1/auth/whoami", p.handleWhoAmI)
+
 	// Health
 	mux.HandleFunc("GET /healthz", p.handleHealthz)
 }
 
 // WrapHandler returns an http.Handler that wraps the given handler with:
 // 1. Panic recovery — catches panics and returns 500 instead of crashing
-// 2. Mutex serialization — prevents concurrent map access crashes
-// Write handlers (POST/PUT/PATCH/DELETE) acquire a write lock.
+// 2. API token authorization and admission (plugins and webhooks) for writes
+// 3. Mutex serialization — prevents concurrent map access crashes
+// Write handlers (POST/PUT/PATCH/DELETE) acquire a write lock; deletes also
+// honour ownerReferences and finalizers (see gc.go).
 // Read handlers (GET/HEAD) acquire a read lock.
 // Watch requests (?watch=true) skip locking — they are long-lived streaming
 // connections and watch handlers use their own polling/snapshot logic.
@@ -250,8 +325,20 @@ func (p *MicroKubeProvider) WrapHandler(h http.Handler) http.Handler {
 			}
 		}()
 
+		r, ok := p.authorizeRequest(w, r)
+		if !ok {
+			return
+		}
+
 		// Watch requests are long-lived streams — skip mutex to avoid blocking writes.
 		isWatch := r.URL.Query().Get("watch") == "true"
+
+		// Admission plugins and webhooks run before the write lock is taken
+		if !isWatch && r.Method != http.MethodGet && r.Method != http.MethodHead {
+			if r, ok = p.admitRequest(w, r, h); !ok {
+				return
+			}
+		}
 		if !isWatch {
 			switch r.Method {
 			case http.MethodGet, http.MethodHead:
@@ -260,6 +347,8 @@ func (p *MicroKubeProvider) WrapHandler(h http.Handler) http.Handler {
 			default:
 				p.mu.Lock()
 				defer p.mu.Unlock()
+				p.serveWrite(w, r, h)
+				return
 			}
 		}
 		h.ServeHTTP(w, r)
@@ -373,6 +462,14 @@ func (p *MicroKubeProvider) handleCreatePod(w http.ResponseWriter, r *http.Reque
 	if pod.CreationTimestamp.IsZero() {
 		pod.CreationTimestamp = metav1.Now()
 	}
+	if _, err := p.podBandwidth(&pod); err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	if _, err := podDNSTTLAnnotation(&pod); err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
 
 	// Stamp node assignment if clustering is enabled
 	if p.clusterMgr != nil {
@@ -479,9 +576,10 @@ func (p *MicroKubeProvider) handleDeletePod(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	// For deployment-owned pods, keep NATS Pods entry so the deployment
-	// reconciler can recreate the pod. Only remove for standalone pods.
-	if isOwnedByDeployment(pod) {
+	// For pods of a live deployment, keep NATS Pods entry so the deployment
+	// reconciler can recreate the pod. Remove it for standalone pods and
+	// for pods whose deployment is gone or terminating.
+	if p.ownedByLiveDeployment(pod) {
 		p.deps.Logger.Infow("pod owned by deployment, will be recreated by reconciler",
 			"pod", ns+"/"+name, "deployment", pod.Annotations[annotationOwnerDeployment])
 	} else if p.deps.Store != nil {
@@ -724,171 +822,228 @@ func (p *MicroKubeProvider) handleAPIResources(w http.ResponseWriter, r *http.Re
 	podWriteJSON(w, http.StatusOK, metav1.APIResourceList{
 		TypeMeta:     metav1.TypeMeta{Kind: "APIResourceList"},
 		GroupVersion: "v1",
-		APIResources: []metav1.APIResource{
-			{
-				Name:       "pods",
-				Namespaced: true,
-				Kind:       "Pod",
-				Verbs:      metav1.Verbs{"get", "list", "create", "update", "patch", "delete"},
-			},
-			{
-				Name:       "pods/log",
-				Namespaced: true,
-				Kind:       "Pod",
-				Verbs:      metav1.Verbs{"get"},
-			},
-			{
-				Name:       "pods/status",
-				Namespaced: true,
-				Kind:       "Pod",
-				Verbs:      metav1.Verbs{"get"},
-			},
-			{
-				Name:       "configmaps",
-				Namespaced: true,
-				Kind:       "ConfigMap",
-				Verbs:      metav1.Verbs{"get", "list", "create", "update", "patch", "delete"},
-			},
-			{
-				Name:       "namespaces",
-				Namespaced: false,
-				Kind:       "Namespace",
-				Verbs:      metav1.Verbs{"get", "list"},
-			},
-			{
-				Name:       "nodes",
-				Namespaced: false,
-				Kind:       "Node",
-				Verbs:      metav1.Verbs{"get", "list"},
-			},
-			{
-				Name:       "events",
-				Namespaced: true,
-				Kind:       "Event",
-				Verbs:      metav1.Verbs{"get", "list"},
-			},
-			{
-				Name:       "services",
-				Namespaced: true,
-				Kind:       "Service",
-				Verbs:      metav1.Verbs{"get", "list"},
-			},
-			{
-				Name:       "baremetalhosts",
-				Namespaced: true,
-				Kind:       "BareMetalHost",
-				ShortNames: []string{"bmh"},
-				Verbs:      metav1.Verbs{"get", "list", "create", "update", "patch", "delete"},
-			},
-			{
-				Name:       "persistentvolumeclaims",
-				Namespaced: true,
-				Kind:       "PersistentVolumeClaim",
-				ShortNames: []string{"pvc"},
-				Verbs:      metav1.Verbs{"get", "list", "create", "update", "delete"},
-			},
-			{
-				Name:       "deployments",
-				Namespaced: true,
-				Kind:       "Deployment",
-				ShortNames: []string{"deploy"},
-				Verbs:      metav1.Verbs{"get", "list", "create", "update", "delete"},
-			},
-			{
-				Name:       "networks",
-				Namespaced: false,
-				Kind:       "Network",
-				ShortNames: []string{"net"},
-				Verbs:      metav1.Verbs{"get", "list", "create", "update", "patch", "delete"},
-			},
-			{
-				Name:       "registries",
-				Namespaced: false,
-				Kind:       "Registry",
-				ShortNames: []string{"reg"},
-				Verbs:      metav1.Verbs{"get", "list", "create", "update", "patch", "delete"},
-			},
-			{
-				Name:       "iscsi-cdroms",
-				Namespaced: false,
-				Kind:       "ISCSICdrom",
-				ShortNames: []string{"icd"},
-				Verbs:      metav1.Verbs{"get", "list", "create", "update", "patch", "delete"},
-			},
-			{
-				Name:       "bootconfigs",
-				Namespaced: false,
-				Kind:       "BootConfig",
-				ShortNames: []string{"bc"},
-				Verbs:      metav1.Verbs{"get", "list", "create", "update", "patch", "delete"},
-			},
-			{
-				Name:       "hostreservations",
-				Namespaced: true,
-				Kind:       "HostReservation",
-				ShortNames: []string{"hres"},
-				Verbs:      metav1.Verbs{"get", "list", "create", "update", "patch", "delete"},
-			},
-			{
-				Name:       "jobrunners",
-				Namespaced: false,
-				Kind:       "JobRunner",
-				ShortNames: []string{"jr"},
-				Verbs:      metav1.Verbs{"get", "list", "create", "update", "patch", "delete"},
-			},
-			{
-				Name:       "jobs",
-				Namespaced: true,
-				Kind:       "Job",
-				ShortNames: []string{"job"},
-				Verbs:      metav1.Verbs{"get", "list", "create", "update", "patch", "delete"},
-			},
-			{
-				Name:       "jobqueue",
-				Namespaced: false,
-				Kind:       "Job",
-				ShortNames: []string{"jq"},
-				Verbs:      metav1.Verbs{"get", "list"},
-			},
-			{
-				Name:       "dnsrecords",
-				Namespaced: true,
-				Kind:       "DNSRecord",
-				ShortNames: []string{"dr"},
-				Verbs:      metav1.Verbs{"get", "list", "create", "update", "delete"},
-			},
-			{
-				Name:       "dhcppools",
-				Namespaced: true,
-				Kind:       "DHCPPool",
-				ShortNames: []string{"dp"},
-				Verbs:      metav1.Verbs{"get", "list", "create", "update", "delete"},
-			},
-			{
-				Name:       "dhcpreservations",
-				Namespaced: true,
-				Kind:       "DHCPReservation",
-				ShortNames: []string{"dhcpr"},
-				Verbs:      metav1.Verbs{"get", "list", "create", "update", "delete"},
-			},
-			{
-				Name:       "dhcpleases",
-				Namespaced: true,
-				Kind:       "DHCPLease",
-				ShortNames: []string{"dl"},
-				Verbs:      metav1.Verbs{"get", "list"},
-			},
-			{
-				Name:       "dnsforwarders",
-				Namespaced: true,
-				Kind:       "DNSForwarder",
-				ShortNames: []string{"df"},
-				Verbs:      metav1.Verbs{"get", "list", "create", "delete"},
-			},
-		},
+		APIResources: apiResources,
 	})
 }
 
+// apiResources lists every resource served under /api/v1. Admission uses it
+// to map request paths to kinds.
+var apiResources = []metav1.APIResource{
+	{
+		Name:       "pods",
+		Namespaced: true,
+		Kind:       "Pod",
+		Verbs:      metav1.Verbs{"get", "list", "create", "update", "patch", "delete"},
+	},
+	{
+		Name:       "pods/log",
+		Namespaced: true,
+		Kind:       "Pod",
+		Verbs:      metav1.Verbs{"get"},
+	},
+	{
+		Name:       "pods/status",
+		Namespaced: true,
+		Kind:       "Pod",
+		Verbs:      metav1.Verbs{"get"},
+	},
+	{
+		Name:       "configmaps",
+		Namespaced: true,
+		Kind:       "ConfigMap",
+		Verbs:      metav1.Verbs{"get", "list", "create", "update", "patch", "delete"},
+	},
+	{
+		Name:       "secrets",
+		Namespaced: true,
+		Kind:       "Secret",
+		Verbs:      metav1.Verbs{"get", "list", "create", "update", "patch", "delete"},
+	},
+	{
+		Name:       "namespaces",
+		Namespaced: false,
+		Kind:       "Namespace",
+		Verbs:      metav1.Verbs{"get", "list"},
+	},
+	{
+		Name:       "nodes",
+		Namespaced: false,
+		Kind:       "Node",
+		Verbs:      metav1.Verbs{"get", "list"},
+	},
+	{
+		Name:       "events",
+		Namespaced: true,
+		Kind:       "Event",
+		Verbs:      metav1.Verbs{"get", "list"},
+	},
+	{
+		Name:       "services",
+		Namespaced: true,
+		Kind:       "Service",
+		Verbs:      metav1.Verbs{"get", "list"},
+	},
+	{
+		Name:       "baremetalhosts",
+		Namespaced: true,
+		Kind:       "BareMetalHost",
+		ShortNames: []string{"bmh"},
+		Verbs:      metav1.Verbs{"get", "list", "create", "update", "patch", "delete"},
+	},
+	{
+		Name:       "persistentvolumeclaims",
+		Namespaced: true,
+		Kind:       "PersistentVolumeClaim",
+		ShortNames: []string{"pvc"},
+		Verbs:      metav1.Verbs{"get", "list", "create", "update", "delete"},
+	},
+	{
+		Name:       "deployments",
+		Namespaced: true,
+		Kind:       "Deployment",
+		ShortNames: []string{"deploy"},
+		Verbs:      metav1.Verbs{"get", "list", "create", "update", "delete"},
+	},
+	{
+		Name:       "networks",
+		Namespaced: false,
+		Kind:       "Network",
+		ShortNames: []string{"net"},
+		Verbs:      metav1.Verbs{"get", "list", "create", "update", "patch", "delete"},
+	},
+	{
+		Name:       "registries",
+		Namespaced: false,
+		Kind:       "Registry",
+		ShortNames: []string{"reg"},
+		Verbs:      metav1.Verbs{"get", "list", "create", "update", "patch", "delete"},
+	},
+	{
+		Name:       "iscsi-cdroms",
+		Namespaced: false,
+		Kind:       "ISCSICdrom",
+		ShortNames: []string{"icd"},
+		Verbs:      metav1.Verbs{"get", "list", "create", "update", "patch", "delete"},
+	},
+	{
+		Name:       "bootconfigs",
+		Namespaced: false,
+		Kind:       "BootConfig",
+		ShortNames: []string{"bc"},
+		Verbs:      metav1.Verbs{"get", "list", "create", "update", "patch", "delete"},
+	},
+	{
+		Name:       "hostreservations",
+		Namespaced: true,
+		Kind:       "HostReservation",
+		ShortNames: []string{"hres"},
+		Verbs:      metav1.Verbs{"get", "list", "create", "update", "patch", "delete"},
+	},
+	{
+		Name:       "ipaddressclaims",
+		Namespaced: true,
+		Kind:       "IPAddressClaim",
+		ShortNames: []string{"ipc"},
+		Verbs:      metav1.Verbs{"get", "list", "create", "update", "patch", "delete"},
+	},
+	{
+		Name:       "portforwards",
+		Namespaced: true,
+		Kind:       "PortForward",
+		ShortNames: []string{"pf"},
+		Verbs:      metav1.Verbs{"get", "list", "create", "update", "patch", "delete"},
+	},
+	{
+		Name:       "jobrunners",
+		Namespaced: false,
+		Kind:       "JobRunner",
+		ShortNames: []string{"jr"},
+		Verbs:      metav1.Verbs{"get", "list", "create", "update", "patch", "delete"},
+	},
+	{
+		Name:       "jobs",
+		Namespaced: true,
+		Kind:       "Job",
+		ShortNames: []string{"job"},
+		Verbs:      metav1.Verbs{"get", "list", "create", "update", "patch", "delete"},
+	},
+	{
+		Name:       "jobqueue",
+		Namespaced: false,
+		Kind:       "Job",
+		ShortNames: []string{"jq"},
+		Verbs:      metav1.Verbs{"get", "list"},
+	},
+	{
+		Name:       "alertrules",
+		Namespaced: false,
+		Kind:       "AlertRule",
+		ShortNames: []string{"ar"},
+		Verbs:      metav1.Verbs{"get", "list", "create", "update", "patch", "delete"},
+	},
+	{
+		Name:       "alertsilences",
+		Namespaced: false,
+		Kind:       "AlertSilence",
+		ShortNames: []string{"silence"},
+		Verbs:      metav1.Verbs{"get", "list", "create", "delete"},
+	},
+	{
+		Name:       "dnsrecords",
+		Namespaced: true,
+		Kind:       "DNSRecord",
+		ShortNames: []string{"dr"},
+		Verbs:      metav1.Verbs{"get", "list", "create", "update", "delete"},
+	},
+	{
+		Name:       "dhcppools",
+		Namespaced: true,
+		Kind:       "DHCPPool",
+		ShortNames: []string{"dp"},
+		Verbs:      metav1.Verbs{"get", "list", "create", "update", "delete"},
+	},
+	{
+		Name:       "dhcpreservations",
+		Namespaced: true,
+		Kind:       "DHCPReservation",
+		ShortNames: []string{"dhcpr"},
+		Verbs:      metav1.Verbs{"get", "list", "create", "update", "delete"},
+	},
+	{
+		Name:       "dhcpdevices",
+		Namespaced: false,
+		Kind:       "DHCPDevice",
+		ShortNames: []string{"dev"},
+		Verbs:      metav1.Verbs{"get", "list", "delete"},
+	},
+	{
+		Name:       "dhcpleases",
+		Namespaced: true,
+		Kind:       "DHCPLease",
+		ShortNames: []string{"dl"},
+		Verbs:      metav1.Verbs{"get", "list"},
+	},
+	{
+		Name:       "dnsforwarders",
+		Namespaced: true,
+		Kind:       "DNSForwarder",
+		ShortNames: []string{"df"},
+		Verbs:      metav1.Verbs{"get", "list", "create", "delete"},
+	},
+	{
+		Name:       "mutatingwebhookconfigurations",
+		Namespaced: false,
+		Kind:       "MutatingWebhookConfiguration",
+		Verbs:      metav1.Verbs{"get", "list", "create", "update", "patch", "delete"},
+	},
+	{
+		Name:       "validatingwebhookconfigurations",
+		Namespaced: false,
+		Kind:       "ValidatingWebhookConfiguration",
+		Verbs:      metav1.Verbs{"get", "list", "create", "update", "patch", "delete"},
+	},
+}
+
 // handleHealthz returns a simple health check response including version and commit
 // so deployers can verify the running binary matches what was built.
 func (p *MicroKubeProvider) handleHealthz(w http.ResponseWriter, r *http.Request) {
@@ -1551,6 +1706,9 @@ func podListToTable(pods []corev1.Pod) *metav1.Table {
 				status = cs.State.Terminated.Reason
 			}
 		}
+		if pod.DeletionTimestamp != nil {
+			status = "Terminating"
+		}
 
 		age := "<unknown>"
 		if !pod.CreationTimestamp.IsZero() {
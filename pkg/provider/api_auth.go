@@ -0,0 +1,191 @@
+package provider
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/glennswest/mkube/pkg/config"
+)
+
+// apiIdentity is the authenticated caller of an API request.
+type apiIdentity struct {
+	Name     string
+	ReadOnly bool
+}
+
+type apiIdentityKey struct{}
+
+// authExemptPaths are reachable without a token. They are called by booting
+// hosts, job agents picking up their job and the registry, none of which
+// carry API credentials, plus the health check and the dashboard's static
+// assets (the dashboard itself authenticates every API call it makes).
+var authExemptPaths = map[string]bool{
+	"/healthz":                     true,
+	"/ui":                          true,
+	"/api/v1/bootconfig":           true,
+	"/api/v1/boot-complete":        true,
+	"/api/v1/registry/push-notify": true,
+	"/api/v1/agent/work":           true,
+}
+
+var authExemptPrefixes = []string{
+	"/ui/",
+}
+
+// clusterSyncPaths are called by cluster peers, which present the shared
+// cluster.token instead of an API token.
+var clusterSyncPaths = map[string]bool{
+	"/api/v1/cluster/sync":               true,
+	"/api/v1/cluster/full-sync":          true,
+	"/api/v1/cluster/alert-observations": true,
+}
+
+// agentHeaderToken carries the token handed to a job agent with its job.
+// The agent presents it as a bearer token on its heartbeat, log and
+// completion calls.
+const agentHeaderToken = "X-Agent-Token"
+
+// agentTokenStore holds the tokens issued to job agents, keyed by job.
+// Tokens live in memory only: after a restart the agent fetches a new one
+// from /api/v1/agent/work.
+type agentTokenStore struct {
+	mu     sync.Mutex
+	tokens map[string]string // job key -> token
+}
+
+func newAgentTokenStore() *agentTokenStore {
+	return &agentTokenStore{tokens: make(map[string]string)}
+}
+
+// issue returns the token of a job, generating one on first use.
+func (s *agentTokenStore) issue(jobKey string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if t, ok := s.tokens[jobKey]; ok {
+		return t
+	}
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	t := hex.EncodeToString(b)
+	s.tokens[jobKey] = t
+	return t
+}
+
+// revoke forgets the token of a finished job.
+func (s *agentTokenStore) revoke(jobKey string) {
+	s.mu.Lock()
+	delete(s.tokens, jobKey)
+	s.mu.Unlock()
+}
+
+// valid reports whether presented is a token issued to a running job.
+func (s *agentTokenStore) valid(presented string) bool {
+	if presented == "" {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, t := range s.tokens {
+		if subtle.ConstantTimeCompare([]byte(t), []byte(presented)) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// authExempt returns true if the request may bypass token authentication.
+func authExempt(r *http.Request) bool {
+	path := r.URL.Path
+	if authExemptPaths[path] {
+		return true
+	}
+	for _, prefix := range authExemptPrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	// Ignition/boot files fetched by hosts during PXE boot
+	if r.Method == http.MethodGet && strings.HasPrefix(path, "/api/v1/bootconfigs/") &&
+		(strings.HasSuffix(path, "/serve") || strings.Contains(path, "/files/")) {
+		return true
+	}
+	return false
+}
+
+// matchAPIToken returns the identity for a bearer token, comparing in
+// constant time so token prefixes can't be probed.
+func matchAPIToken(tokens []config.APIToken, presented string) (apiIdentity, bool) {
+	if presented == "" {
+		return apiIdentity{}, false
+	}
+	for _, t := range tokens {
+		if t.Token == "" {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(t.Token), []byte(presented)) == 1 {
+			return apiIdentity{Name: t.Name, ReadOnly: t.ReadOnly}, true
+		}
+	}
+	return apiIdentity{}, false
+}
+
+func bearerToken(r *http.Request) string {
+	h := r.Header.Get("Authorization")
+	if len(h) > 7 && strings.EqualFold(h[:7], "Bearer ") {
+		return strings.TrimSpace(h[7:])
+	}
+	return ""
+}
+
+// authorizeRequest enforces API token auth when tokens are configured.
+// It returns the request annotated with the caller's identity, or false
+// after writing a 401/403 response.
+func (p *MicroKubeProvider) authorizeRequest(w http.ResponseWriter, r *http.Request) (*http.Request, bool) {
+	tokens := p.deps.Config.API.Tokens
+	if len(tokens) == 0 || authExempt(r) {
+		return r, true
+	}
+
+	presented := bearerToken(r)
+	if clusterSyncPaths[r.URL.Path] {
+		if ct := p.deps.Config.Cluster.Token; ct != "" && subtle.ConstantTimeCompare([]byte(ct), []byte(presented)) == 1 {
+			return r.WithContext(context.WithValue(r.Context(), apiIdentityKey{}, apiIdentity{Name: "cluster"})), true
+		}
+	}
+	if strings.HasPrefix(r.URL.Path, "/api/v1/agent/") && p.agentTokens.valid(presented) {
+		return r.WithContext(context.WithValue(r.Context(), apiIdentityKey{}, apiIdentity{Name: "agent"})), true
+	}
+
+	id, ok := matchAPIToken(tokens, presented)
+	if !ok {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="mkube"`)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return nil, false
+	}
+
+	if id.ReadOnly && r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, fmt.Sprintf("token %q is read-only", id.Name), http.StatusForbidden)
+		return nil, false
+	}
+
+	return r.WithContext(context.WithValue(r.Context(), apiIdentityKey{}, id)), true
+}
+
+// handleWhoAmI reports the caller's identity so the dashboard can decide
+// whether to prompt for a token and whether to show write actions.
+func (p *MicroKubeProvider) handleWhoAmI(w http.ResponseWriter, r *http.Request) {
+	id, _ := r.Context().Value(apiIdentityKey{}).(apiIdentity)
+	podWriteJSON(w, http.StatusOK, map[string]interface{}{
+		"authEnabled": len(p.deps.Config.API.Tokens) > 0,
+		"user":        id.Name,
+		"readOnly":    id.ReadOnly || p.deps.Config.Dashboard.ReadOnly,
+	})
+}
@@ -0,0 +1,119 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/glennswest/mkube/pkg/config"
+)
+
+func TestAPIAuth(t *testing.T) {
+	p, _ := newTestProvider(t)
+	p.deps.Config.API.Tokens = []config.APIToken{
+		{Name: "admin", Token: "rw-secret"},
+		{Name: "viewer", Token: "ro-secret", ReadOnly: true},
+	}
+
+	mux := http.NewServeMux()
+	p.RegisterRoutes(mux)
+	h := p.WrapHandler(mux)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		want   int
+	}{
+		{"no token", http.MethodGet, "/api/v1/pods", "", http.StatusUnauthorized},
+		{"bad token", http.MethodGet, "/api/v1/pods", "nope", http.StatusUnauthorized},
+		{"read-only get", http.MethodGet, "/api/v1/pods", "ro-secret", http.StatusOK},
+		{"read-only write", http.MethodPost, "/api/v1/consistency/repair", "ro-secret", http.StatusForbidden},
+		{"healthz exempt", http.MethodGet, "/healthz", "", http.StatusOK},
+		{"whoami", http.MethodGet, "/api/v1/auth/whoami", "rw-secret", http.StatusOK},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		if tt.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != tt.want {
+			t.Errorf("%s: expected %d, got %d (%s)", tt.name, tt.want, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestAuthExempt(t *testing.T) {
+	tests := []struct {
+		method string
+		path   string
+		want   bool
+	}{
+		{http.MethodGet, "/ui/app.js", true},
+		{http.MethodGet, "/api/v1/agent/work", true},
+		{http.MethodPost, "/api/v1/agent/heartbeat", false},
+		{http.MethodPost, "/api/v1/cluster/sync", false},
+		{http.MethodGet, "/api/v1/bootconfigs/worker/serve", true},
+		{http.MethodPost, "/api/v1/bootconfigs/worker/files/x.ign", false},
+		{http.MethodGet, "/api/v1/bootconfigs", false},
+		{http.MethodGet, "/api/v1/cluster/status", false},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		if got := authExempt(req); got != tt.want {
+			t.Errorf("%s %s: expected %v, got %v", tt.method, tt.path, tt.want, got)
+		}
+	}
+}
+
+func TestPeerAndAgentTokens(t *testing.T) {
+	p, _ := newTestProvider(t)
+	p.deps.Config.API.Tokens = []config.APIToken{{Name: "admin", Token: "rw-secret"}}
+	p.deps.Config.Cluster.Token = "peer-secret"
+
+	mux := http.NewServeMux()
+	p.RegisterRoutes(mux)
+	mux.HandleFunc("POST /api/v1/cluster/sync", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	h := p.WrapHandler(mux)
+	agentToken := p.agentTokens.issue("default/build")
+
+	tests := []struct {
+		name  string
+		path  string
+		token string
+		want  int
+	}{
+		{"sync without token", "/api/v1/cluster/sync", "", http.StatusUnauthorized},
+		{"sync with cluster token", "/api/v1/cluster/sync", "peer-secret", http.StatusOK},
+		{"agent without token", "/api/v1/agent/heartbeat", "", http.StatusUnauthorized},
+		// Past auth, the handler finds no host for the test client's address
+		{"agent with job token", "/api/v1/agent/heartbeat", agentToken, http.StatusNotFound},
+		{"agent token elsewhere", "/api/v1/consistency/repair", agentToken, http.StatusUnauthorized},
+		{"cluster token elsewhere", "/api/v1/consistency/repair", "peer-secret", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, tt.path, nil)
+		if tt.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != tt.want {
+			t.Errorf("%s: expected %d, got %d (%s)", tt.name, tt.want, rec.Code, rec.Body.String())
+		}
+	}
+
+	// A finished job's token is revoked
+	p.releaseJobHost(context.Background(), &Job{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "build"}})
+	if p.agentTokens.valid(agentToken) {
+		t.Error("agent token still valid after the job finished")
+	}
+}
@@ -0,0 +1,133 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/glennswest/mkube/pkg/network"
+)
+
+// Standard Kubernetes bandwidth annotations, as honoured by the CNI
+// bandwidth plugin. Values are quantities in bits per second ("10M").
+// Ingress is traffic toward the pod, egress traffic leaving it.
+const (
+	annotationIngressBandwidth = "kubernetes.io/ingress-bandwidth"
+	annotationEgressBandwidth  = "kubernetes.io/egress-bandwidth"
+
+	// podConditionBandwidth reports a shaped pod's limits and current rates.
+	podConditionBandwidth corev1.PodConditionType = "vkube.io/BandwidthLimited"
+)
+
+// parseBandwidth parses a bandwidth quantity in bits per second.
+func parseBandwidth(s string) (int64, error) {
+	q, err := resource.ParseQuantity(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid bandwidth %q: %w", s, err)
+	}
+	if q.Sign() <= 0 {
+		return 0, fmt.Errorf("invalid bandwidth %q: must be positive", s)
+	}
+	return q.Value(), nil
+}
+
+// podBandwidth returns the shaping for pod: each direction comes from its
+// annotation, falling back to the namespace default in
+// namespace.bandwidth. A zero direction is unlimited.
+func (p *MicroKubeProvider) podBandwidth(pod *corev1.Pod) (network.Bandwidth, error) {
+	def := p.deps.Config.Namespace.Bandwidth[pod.Namespace]
+	var bw network.Bandwidth
+	for _, dir := range []struct {
+		ann, fallback string
+		into          *int64
+	}{
+		{annotationIngressBandwidth, def.Ingress, &bw.Ingress},
+		{annotationEgressBandwidth, def.Egress, &bw.Egress},
+	} {
+		v := pod.Annotations[dir.ann]
+		if v == "" {
+			v = dir.fallback
+		}
+		if v == "" {
+			continue
+		}
+		rate, err := parseBandwidth(v)
+		if err != nil {
+			return network.Bandwidth{}, fmt.Errorf("%s: %w", dir.ann, err)
+		}
+		*dir.into = rate
+	}
+	return bw, nil
+}
+
+// applyPodBandwidth shapes each of pod's veths. It is cheap to repeat: the
+// network manager only reprograms a veth whose limit or address changed.
+// Shaping matches on IPv4 addresses, so IPv6-only veths are skipped.
+func (p *MicroKubeProvider) applyPodBandwidth(ctx context.Context, pod *corev1.Pod, log *zap.SugaredLogger) {
+	bw, err := p.podBandwidth(pod)
+	if err != nil {
+		log.Warnw("ignoring bandwidth limits", "pod", podKey(pod), "error", err)
+		return
+	}
+	for i := range pod.Spec.Containers {
+		veth := vethName(pod, i)
+		if _, _, ok := p.deps.NetworkMgr.GetPortInfo(veth); !ok {
+			continue
+		}
+		if !p.deps.NetworkMgr.PortShapeable(veth) {
+			if !bw.IsZero() {
+				log.Debugw("bandwidth shaping skipped, no IPv4 address", "pod", podKey(pod), "veth", veth)
+			}
+			continue
+		}
+		if err := p.deps.NetworkMgr.SetPortBandwidth(ctx, veth, bw); err != nil {
+			if errors.Is(err, network.ErrNotSupported) {
+				log.Debugw("bandwidth shaping not supported", "pod", podKey(pod), "veth", veth)
+				return
+			}
+			log.Warnw("failed to shape pod bandwidth", "pod", podKey(pod), "veth", veth, "error", err)
+		}
+	}
+}
+
+// syncPodBandwidth re-applies shaping to every tracked pod, picking up
+// annotation and default changes, re-targeted addresses and limits lost
+// while mkube was down, then reads the current rates for pod status.
+func (p *MicroKubeProvider) syncPodBandwidth(ctx context.Context) {
+	log := p.deps.Logger
+	for _, pod := range p.pods {
+		p.applyPodBandwidth(ctx, pod, log)
+	}
+	p.deps.NetworkMgr.RefreshPortRates(ctx)
+}
+
+// podBandwidthCondition describes the shaping of pod's first veth, or
+// returns nil when it is not shaped. Rates are those last read by
+// syncPodBandwidth, so building a pod status never calls the driver.
+func (p *MicroKubeProvider) podBandwidthCondition(pod *corev1.Pod) *corev1.PodCondition {
+	if len(pod.Spec.Containers) == 0 {
+		return nil
+	}
+	limit, rate, ok := p.deps.NetworkMgr.PortBandwidth(vethName(pod, 0))
+	if !ok {
+		return nil
+	}
+	return &corev1.PodCondition{
+		Type:   podConditionBandwidth,
+		Status: corev1.ConditionTrue,
+		Reason: "Shaped",
+		Message: fmt.Sprintf("ingress %s/%s, egress %s/%s (rate/limit, bits/s)",
+			formatBandwidth(rate.Ingress), formatBandwidth(limit.Ingress),
+			formatBandwidth(rate.Egress), formatBandwidth(limit.Egress)),
+	}
+}
+
+// formatBandwidth renders a rate in bits/s as a decimal quantity; 0 as a
+// limit means unlimited.
+func formatBandwidth(v int64) string {
+	return resource.NewQuantity(v, resource.DecimalSI).String()
+}
@@ -0,0 +1,84 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/glennswest/mkube/pkg/config"
+	"github.com/glennswest/mkube/pkg/network"
+)
+
+func TestPodBandwidth(t *testing.T) {
+	p, _ := newTestProvider(t)
+	ctx := context.Background()
+	p.deps.Config.Namespace.Bandwidth = map[string]config.BandwidthConfig{
+		"default": {Ingress: "20M", Egress: "5M"},
+	}
+	drv := &mockNetworkDriver{}
+	netMgr, err := network.NewManager(p.deps.Config.Networks, drv, nil, p.deps.Logger)
+	if err != nil {
+		t.Fatalf("network.NewManager: %v", err)
+	}
+	p.deps.NetworkMgr = netMgr
+
+	pod := testPod("web", "app")
+	pod.Annotations[annotationIngressBandwidth] = "10M"
+	if err := p.CreatePod(ctx, pod); err != nil {
+		t.Fatalf("CreatePod: %v", err)
+	}
+	// The annotation wins for ingress, the namespace default fills egress
+	want := network.Bandwidth{Ingress: 10_000_000, Egress: 5_000_000}
+	if got := drv.shaped[vethName(pod, 0)]; got != want {
+		t.Errorf("shaped %+v, want %+v", got, want)
+	}
+
+	// Pod status reports the rates read by the last shaping pass; the
+	// driver is not asked while building it
+	p.syncPodBandwidth(ctx)
+	reads := drv.rateReads
+	status, err := p.GetPodStatus(ctx, "default", "web")
+	if err != nil {
+		t.Fatalf("GetPodStatus: %v", err)
+	}
+	if drv.rateReads != reads {
+		t.Error("GetPodStatus read rates from the driver")
+	}
+	var cond *corev1.PodCondition
+	for i := range status.Conditions {
+		if status.Conditions[i].Type == podConditionBandwidth {
+			cond = &status.Conditions[i]
+		}
+	}
+	if cond == nil || cond.Message != "ingress 5M/10M, egress 0/5M (rate/limit, bits/s)" {
+		t.Errorf("bandwidth condition = %+v", cond)
+	}
+
+	// Dropping the annotation falls back to the default on the next sync
+	delete(p.pods["default/web"].Annotations, annotationIngressBandwidth)
+	p.syncPodBandwidth(ctx)
+	if got := drv.shaped[vethName(pod, 0)].Ingress; got != 20_000_000 {
+		t.Errorf("ingress after sync = %d, want 20000000", got)
+	}
+
+	if err := p.DeletePod(ctx, pod); err != nil {
+		t.Fatalf("DeletePod: %v", err)
+	}
+	if len(drv.shaped) != 0 {
+		t.Errorf("shaping left after delete: %v", drv.shaped)
+	}
+
+	// Malformed annotations are rejected at create time
+	mux := http.NewServeMux()
+	p.RegisterRoutes(mux)
+	rec := httptest.NewRecorder()
+	p.WrapHandler(mux).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/namespaces/default/pods",
+		strings.NewReader(`{"metadata":{"name":"bad","annotations":{"kubernetes.io/egress-bandwidth":"fast"}},"spec":{"containers":[{"name":"c","image":"x"}]}}`)))
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("malformed annotation: got %d, want 422", rec.Code)
+	}
+}
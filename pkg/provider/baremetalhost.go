@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"regexp"
 	"sort"
@@ -15,6 +14,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	kruntime "k8s.io/apimachinery/pkg/runtime"
 
+	"github.com/glennswest/mkube/pkg/config"
 	"github.com/glennswest/mkube/pkg/store"
 )
 
@@ -117,8 +117,7 @@ func (p *MicroKubeProvider) handleCreateBMH(w http.ResponseWriter, r *http.Reque
 	ns := r.PathValue("namespace")
 
 	var bmh BareMetalHost
-	if err := json.NewDecoder(r.Body).Decode(&bmh); err != nil {
-		http.Error(w, fmt.Sprintf("invalid BareMetalHost JSON: %v", err), http.StatusBadRequest)
+	if !decodeCRDBody(w, r, "BareMetalHost", false, &bmh) {
 		return
 	}
 	bmh.Namespace = ns
@@ -127,6 +126,9 @@ func (p *MicroKubeProvider) handleCreateBMH(w http.ResponseWriter, r *http.Reque
 		return
 	}
 	bmh.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "BareMetalHost"}
+	if !p.admitCRD(w, &bmh, nil) {
+		return
+	}
 	if bmh.CreationTimestamp.IsZero() {
 		bmh.CreationTimestamp = metav1.Now()
 	}
@@ -263,13 +265,15 @@ func (p *MicroKubeProvider) handleUpdateBMH(w http.ResponseWriter, r *http.Reque
 	oldBootConfigRef := existing.Spec.BootConfigRef
 
 	var bmh BareMetalHost
-	if err := json.NewDecoder(r.Body).Decode(&bmh); err != nil {
-		http.Error(w, fmt.Sprintf("invalid BareMetalHost JSON: %v", err), http.StatusBadRequest)
+	if !decodeCRDBody(w, r, "BareMetalHost", false, &bmh) {
 		return
 	}
 	bmh.Namespace = ns
 	bmh.Name = name
 	bmh.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "BareMetalHost"}
+	if !p.admitCRD(w, &bmh, existing) {
+		return
+	}
 	if bmh.CreationTimestamp.IsZero() {
 		bmh.CreationTimestamp = existing.CreationTimestamp
 	}
@@ -319,18 +323,15 @@ func (p *MicroKubeProvider) handlePatchBMH(w http.ResponseWriter, r *http.Reques
 	oldBootConfigRef := existing.Spec.BootConfigRef
 	merged := existing.DeepCopy()
 
-	body, err := io.ReadAll(r.Body)
-	if err != nil {
-		http.Error(w, fmt.Sprintf("reading body: %v", err), http.StatusBadRequest)
-		return
-	}
-	if err := json.Unmarshal(body, merged); err != nil {
-		http.Error(w, fmt.Sprintf("invalid patch JSON: %v", err), http.StatusBadRequest)
+	if !decodeCRDBody(w, r, "BareMetalHost", true, merged) {
 		return
 	}
 	merged.Namespace = ns
 	merged.Name = name
 	merged.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "BareMetalHost"}
+	if !p.admitCRD(w, merged, existing) {
+		return
+	}
 
 	// Preserve existing credentials if not provided in patch
 	if merged.Spec.BMC.Username == "" && existing.Spec.BMC.Username != "" {
@@ -370,21 +371,14 @@ func (p *MicroKubeProvider) handleDeleteBMH(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	// Remove DHCP reservations from referenced Network CRDs (data + IPMI)
-	p.removeBMHFromNetwork(r.Context(), bmh.Spec.BootMACAddress, bmh.Spec.Network)
-	p.removeBMHFromNetwork(r.Context(), bmh.Spec.BMC.MAC, bmh.Spec.BMC.Network)
-
-	// Remove DNS A record for data network
-	if bmh.Spec.Network != "" && bmh.Spec.IP != "" {
-		hostname := firstNonEmpty(bmh.Spec.Hostname, bmh.Name)
-		if err := p.deps.NetworkMgr.DeregisterDNS(r.Context(), bmh.Spec.Network, hostname, bmh.Spec.IP); err != nil {
+	// The mkube.io/bmh-release finalizer has normally released everything
+	// already; this covers deletes that bypass it.
+	if !gcFinalized(r.Context()) {
+		if err := p.releaseBMH(r.Context(), bmh); err != nil {
 			p.deps.Logger.Warnw("BMH DNS deregistration failed", "bmh", bmh.Name, "error", err)
 		}
 	}
 
-	// Remove from BootConfig assignedTo
-	p.removeBootConfigRef(r.Context(), bmh.Name, bmh.Spec.BootConfigRef)
-
 	delete(p.bareMetalHosts, key)
 
 	if p.deps.Store != nil && p.deps.Store.BareMetalHosts != nil {
@@ -401,6 +395,23 @@ func (p *MicroKubeProvider) handleDeleteBMH(w http.ResponseWriter, r *http.Reque
 	})
 }
 
+// releaseBMH frees what a BareMetalHost holds outside mkube: its DHCP
+// reservations (data + IPMI), the DNS A record of its data address and its
+// BootConfig assignment. An error means the DNS record could not be removed.
+func (p *MicroKubeProvider) releaseBMH(ctx context.Context, bmh *BareMetalHost) error {
+	p.removeBMHFromNetwork(ctx, bmh.Spec.BootMACAddress, bmh.Spec.Network)
+	p.removeBMHFromNetwork(ctx, bmh.Spec.BMC.MAC, bmh.Spec.BMC.Network)
+	p.removeBootConfigRef(ctx, bmh.Name, bmh.Spec.BootConfigRef)
+
+	if bmh.Spec.Network != "" && bmh.Spec.IP != "" {
+		hostname := firstNonEmpty(bmh.Spec.Hostname, bmh.Name)
+		if err := p.deps.NetworkMgr.DeregisterDNS(ctx, bmh.Spec.Network, hostname, bmh.Spec.IP); err != nil {
+			return fmt.Errorf("deregistering DNS for %s: %w", hostname, err)
+		}
+	}
+	return nil
+}
+
 // handleRefreshBMH triggers a baremetalservices re-probe by setting an annotation.
 func (p *MicroKubeProvider) handleRefreshBMH(w http.ResponseWriter, r *http.Request) {
 	ns := r.PathValue("namespace")
@@ -509,7 +520,7 @@ func (p *MicroKubeProvider) syncBMHToNetwork(ctx context.Context, bmh *BareMetal
 			if err := p.deps.NetworkMgr.CleanStaleDNS(ctx, bmh.Spec.Network, hostname, bmh.Spec.IP); err != nil {
 				log.Warnw("BMH DNS stale cleanup failed", "bmh", bmh.Name, "network", bmh.Spec.Network, "error", err)
 			}
-			if err := p.deps.NetworkMgr.RegisterDNS(ctx, bmh.Spec.Network, hostname, bmh.Spec.IP); err != nil {
+			if err := p.deps.NetworkMgr.RegisterDNS(ctx, bmh.Spec.Network, hostname, bmh.Spec.IP, p.dnsTTL(config.DNSTTLBMH, bmh.Spec.Network, bmh.Namespace)); err != nil {
 				log.Warnw("BMH DNS registration failed", "bmh", bmh.Name, "network", bmh.Spec.Network, "error", err)
 			} else {
 				log.Infow("registered BMH DNS A record", "bmh", bmh.Name, "hostname", hostname, "ip", bmh.Spec.IP, "network", bmh.Spec.Network)
@@ -560,6 +571,9 @@ func (p *MicroKubeProvider) upsertNetworkReservation(ctx context.Context, networ
 	found := false
 	for i, existing := range net.Spec.DHCP.Reservations {
 		if strings.ToLower(existing.MAC) == normalizedMAC {
+			if res.Options == nil {
+				res.Options = existing.Options // hand-set options survive BMH syncs
+			}
 			net.Spec.DHCP.Reservations[i] = res
 			found = true
 			break
@@ -140,8 +140,7 @@ func (p *MicroKubeProvider) handleGetBootConfig(w http.ResponseWriter, r *http.R
 
 func (p *MicroKubeProvider) handleCreateBootConfig(w http.ResponseWriter, r *http.Request) {
 	var bc BootConfig
-	if err := json.NewDecoder(r.Body).Decode(&bc); err != nil {
-		http.Error(w, fmt.Sprintf("invalid BootConfig JSON: %v", err), http.StatusBadRequest)
+	if !decodeCRDBody(w, r, "BootConfig", false, &bc) {
 		return
 	}
 
@@ -191,8 +190,7 @@ func (p *MicroKubeProvider) handleUpdateBootConfig(w http.ResponseWriter, r *htt
 	}
 
 	var bc BootConfig
-	if err := json.NewDecoder(r.Body).Decode(&bc); err != nil {
-		http.Error(w, fmt.Sprintf("invalid BootConfig JSON: %v", err), http.StatusBadRequest)
+	if !decodeCRDBody(w, r, "BootConfig", false, &bc) {
 		return
 	}
 	bc.Name = name
@@ -229,13 +227,7 @@ func (p *MicroKubeProvider) handlePatchBootConfig(w http.ResponseWriter, r *http
 
 	merged := existing.DeepCopy()
 
-	body, err := io.ReadAll(r.Body)
-	if err != nil {
-		http.Error(w, fmt.Sprintf("reading body: %v", err), http.StatusBadRequest)
-		return
-	}
-	if err := json.Unmarshal(body, merged); err != nil {
-		http.Error(w, fmt.Sprintf("invalid patch JSON: %v", err), http.StatusBadRequest)
+	if !decodeCRDBody(w, r, "BootConfig", true, merged) {
 		return
 	}
 	merged.Name = name
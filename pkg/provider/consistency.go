@@ -5,11 +5,14 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
 
+	"github.com/glennswest/mkube/pkg/dns"
 	"github.com/glennswest/mkube/pkg/network"
 )
 
@@ -42,11 +45,15 @@ type ConsistencyChecks struct {
 	ISCSICdroms      []CheckItem `json:"iscsiCdroms,omitempty"`
 	BootConfigs      []CheckItem `json:"bootConfigs,omitempty"`
 	HostReservations []CheckItem `json:"hostReservations,omitempty"`
+	IPAddressClaims  []CheckItem `json:"ipAddressClaims,omitempty"`
+	PortForwards     []CheckItem `json:"portForwards,omitempty"`
 	JobRunners       []CheckItem `json:"jobRunners,omitempty"`
 	Jobs             []CheckItem `json:"jobs,omitempty"`
 	MicroDNS         []CheckItem `json:"microDNS,omitempty"`
 	SmokeTests       []CheckItem `json:"smokeTests,omitempty"`
 	PodLiveness      []CheckItem `json:"podLiveness,omitempty"`
+	AlertRules       []CheckItem `json:"alertRules,omitempty"`
+	Webhooks         []CheckItem `json:"webhooks,omitempty"`
 }
 
 // CheckItem is a single check result.
@@ -113,11 +120,15 @@ func (p *MicroKubeProvider) runConsistencyChecks(ctx context.Context) Consistenc
 	report.Checks.ISCSICdroms = p.checkISCSICdromCRDs(ctx)
 	report.Checks.BootConfigs = p.checkBootConfigCRDs(ctx)
 	report.Checks.HostReservations = p.checkHostReservationCRDs(ctx)
+	report.Checks.IPAddressClaims = p.checkIPAddressClaimCRDs(ctx)
+	report.Checks.PortForwards = p.checkPortForwardCRDs(ctx)
 	report.Checks.JobRunners = p.checkJobRunnerCRDs(ctx)
 	report.Checks.Jobs = p.checkJobCRDs(ctx)
 	report.Checks.MicroDNS = p.checkMicroDNSServices(ctx)
 	report.Checks.SmokeTests = p.checkSmokeTests()
 	report.Checks.PodLiveness = p.checkPodLiveness(ctx)
+	report.Checks.AlertRules = p.checkAlertRuleCRDs(ctx)
+	report.Checks.Webhooks = p.checkWebhookConfigurations(ctx)
 
 	for _, items := range [][]CheckItem{
 		report.Checks.Containers,
@@ -133,11 +144,15 @@ func (p *MicroKubeProvider) runConsistencyChecks(ctx context.Context) Consistenc
 		report.Checks.ISCSICdroms,
 		report.Checks.BootConfigs,
 		report.Checks.HostReservations,
+		report.Checks.IPAddressClaims,
+		report.Checks.PortForwards,
 		report.Checks.JobRunners,
 		report.Checks.Jobs,
 		report.Checks.MicroDNS,
 		report.Checks.SmokeTests,
 		report.Checks.PodLiveness,
+		report.Checks.AlertRules,
+		report.Checks.Webhooks,
 	} {
 		for _, item := range items {
 			switch item.Status {
@@ -335,6 +350,9 @@ func (p *MicroKubeProvider) checkDNS(ctx context.Context) []CheckItem {
 
 		// Check expected vs actual
 		for hostname, expected := range expectedRecords {
+			if expected.ip == "" {
+				continue // SRV/TXT only, checked below
+			}
 			checkName := fmt.Sprintf("dns/%s/%s", netName, hostname)
 			actuals, exists := actualRecords[hostname]
 			if !exists {
@@ -372,6 +390,18 @@ func (p *MicroKubeProvider) checkDNS(ctx context.Context) []CheckItem {
 			delete(actualRecords, hostname)
 		}
 
+		// Service discovery: SRV records of named ports and TXT metadata
+		items = append(items, checkServiceRecords(netName, netDef.DNS.Zone, records, expectedRecords)...)
+
+		// Reverse lookups: every expected record needs its PTR
+		items = append(items, p.checkReverseDNS(ctx, dnsClient, netName, netDef.DNS.Endpoint, zoneID, netDef.DNS.Zone, expectedRecords)...)
+
+		// Split-horizon views are checked one by one against their spec
+		if n, ok := p.networks[netName]; ok {
+			items = append(items, checkDNSViews(ctx, dnsClient, n, netDef.DNS.Endpoint)...)
+			items = append(items, p.checkNamespaceDNSViews(ctx, dnsClient, n, netDef.DNS.Endpoint)...)
+		}
+
 		// Any remaining actual records are stale — flag for cleanup
 		for hostname, ips := range actualRecords {
 			items = append(items, CheckItem{
@@ -386,12 +416,166 @@ func (p *MicroKubeProvider) checkDNS(ctx context.Context) []CheckItem {
 	return items
 }
 
+// checkReverseDNS verifies the PTR of each expected record in the reverse
+// zones InitDNSZones created, and flags PTRs into the network's zone that
+// no expected record accounts for.
+func (p *MicroKubeProvider) checkReverseDNS(ctx context.Context, client *dns.Client, netName, endpoint, zoneID, zone string, expected map[string]expectedDNS) []CheckItem {
+	if len(client.ReverseZones(endpoint)) == 0 {
+		return []CheckItem{{
+			Name:    fmt.Sprintf("dns-reverse/%s", netName),
+			Status:  "warn",
+			Message: "no reverse zones known for this endpoint (DNS may not be initialized)",
+		}}
+	}
+
+	var items []CheckItem
+	zones := make(map[string][]dns.Record) // reverse zone ID -> records
+	claimed := make(map[string]bool)       // PTR record IDs matched by an expected record
+	for hostname, exp := range expected {
+		ptr, ok, err := client.ReverseRecord(ctx, endpoint, zoneID, hostname, exp.ip)
+		if err != nil || !ok {
+			continue // address outside the network's reverse zones
+		}
+		records, seen := zones[ptr.Zone.ID]
+		if !seen {
+			records, err = client.ListRecords(ctx, endpoint, ptr.Zone.ID)
+			if err != nil {
+				items = append(items, CheckItem{
+					Name:    fmt.Sprintf("dns-reverse/%s/%s", netName, ptr.Zone.Name),
+					Status:  "fail",
+					Message: fmt.Sprintf("reverse zone unreachable: %v", err),
+				})
+			}
+			zones[ptr.Zone.ID] = records
+		}
+
+		found := false
+		for _, r := range records {
+			if r.Type == "PTR" && r.Name == ptr.Name && dns.SameName(r.Data.Data, ptr.Target) {
+				found, claimed[r.ID] = true, true
+			}
+		}
+		item := CheckItem{
+			Name:    fmt.Sprintf("dns-ptr/%s/%s", netName, hostname),
+			Status:  "pass",
+			Message: "PTR record correct",
+			Details: fmt.Sprintf("%s.%s -> %s", ptr.Name, ptr.Zone.Name, ptr.Target),
+		}
+		if !found {
+			item.Status, item.Message = "fail", "PTR record missing"
+			item.Details = fmt.Sprintf("expected %s.%s PTR %s", ptr.Name, ptr.Zone.Name, ptr.Target)
+		}
+		items = append(items, item)
+	}
+
+	// PTRs into this zone that no expected record accounts for
+	suffix := "." + strings.ToLower(zone)
+	for _, records := range zones {
+		for _, r := range records {
+			target := strings.ToLower(strings.TrimSuffix(r.Data.Data, "."))
+			if r.Type != "PTR" || claimed[r.ID] || !strings.HasSuffix(target, suffix) {
+				continue
+			}
+			items = append(items, CheckItem{
+				Name:    fmt.Sprintf("dns-ptr/%s/%s", netName, r.Name),
+				Status:  "warn",
+				Message: "stale PTR record",
+				Details: fmt.Sprintf("%s -> %s", r.Name, r.Data.Data),
+			})
+		}
+	}
+	return items
+}
+
+// checkServiceRecords compares the SRV and TXT records mkube publishes
+// with the zone. SRVs pointing into the network's zone under a name nothing
+// expects are flagged stale.
+func checkServiceRecords(netName, zone string, records []dns.Record, expected map[string]expectedDNS) []CheckItem {
+	actualSRV := make(map[string][]dns.SRVData)
+	actualTXT := make(map[string][]string)
+	for _, r := range records {
+		if srv, ok := r.Data.SRV(); ok {
+			actualSRV[r.Name] = append(actualSRV[r.Name], srv)
+		} else if r.Type == "TXT" {
+			actualTXT[r.Name] = append(actualTXT[r.Name], r.Data.Data)
+		}
+	}
+
+	var items []CheckItem
+	for name, exp := range expected {
+		if len(exp.srv) > 0 {
+			item := CheckItem{
+				Name:    fmt.Sprintf("dns-srv/%s/%s", netName, name),
+				Status:  "pass",
+				Message: "SRV records correct",
+				Details: fmt.Sprintf("%d targets", len(exp.srv)),
+			}
+			var missing []string
+			for _, want := range exp.srv {
+				found := false
+				for _, have := range actualSRV[name] {
+					if have.Port == want.Port && have.Priority == want.Priority &&
+						have.Weight == want.Weight && dns.SameName(have.Target, want.Target) {
+						found = true
+						break
+					}
+				}
+				if !found {
+					missing = append(missing, fmt.Sprintf("%s:%d", want.Target, want.Port))
+				}
+			}
+			if len(missing) > 0 {
+				item.Status, item.Message = "fail", "SRV records missing"
+				item.Details = fmt.Sprintf("missing=%v", missing)
+			} else if extra := len(actualSRV[name]) - len(exp.srv); extra > 0 {
+				item.Status, item.Message = "warn", "stale SRV records"
+				item.Details = fmt.Sprintf("%d targets not backed by a running container", extra)
+			}
+			items = append(items, item)
+			delete(actualSRV, name)
+		}
+		if len(exp.txt) > 0 {
+			item := CheckItem{
+				Name:    fmt.Sprintf("dns-txt/%s/%s", netName, name),
+				Status:  "pass",
+				Message: "TXT records correct",
+			}
+			for _, want := range exp.txt {
+				if !containsStr(actualTXT[name], want) {
+					item.Status, item.Message = "fail", "TXT record missing"
+					item.Details = fmt.Sprintf("expected %q", want)
+					break
+				}
+			}
+			items = append(items, item)
+		}
+	}
+
+	suffix := "." + strings.ToLower(zone) + "."
+	for name, srvs := range actualSRV {
+		for _, srv := range srvs {
+			if strings.HasSuffix(strings.ToLower(srv.Target), suffix) {
+				items = append(items, CheckItem{
+					Name:    fmt.Sprintf("dns-srv/%s/%s", netName, name),
+					Status:  "warn",
+					Message: "stale SRV record",
+					Details: fmt.Sprintf("%s:%d", srv.Target, srv.Port),
+				})
+			}
+		}
+	}
+	return items
+}
+
 type expectedDNS struct {
-	ip string
+	ip  string        // A record; empty for names carrying only SRV/TXT
+	srv []dns.SRVData // SRV records, one per replica container
+	txt []string      // TXT records from vkube.io/txt
 }
 
 // buildExpectedDNSRecords constructs the set of DNS hostnames and IPs expected
-// from the boot manifest for a given network.
+// from the boot manifest for a given network, plus the SRV records of named
+// container ports and the TXT records of vkube.io/txt.
 func (p *MicroKubeProvider) buildExpectedDNSRecords(pods []*corev1.Pod, networkName string) map[string]expectedDNS {
 	expected := make(map[string]expectedDNS)
 
@@ -406,6 +590,15 @@ func (p *MicroKubeProvider) buildExpectedDNSRecords(pods []*corev1.Pod, networkN
 		}
 	}
 
+	// IPAddressClaim hostnames
+	for _, c := range p.ipAddressClaims {
+		if c.Spec.Network == networkName && c.Spec.Hostname != "" && c.Status.Phase == "Bound" {
+			expected[c.Spec.Hostname] = expectedDNS{ip: c.Status.IP}
+		}
+	}
+
+	zone := p.podNetworkZone(networkName)
+
 	for _, pod := range pods {
 		podNetwork := pod.Annotations[annotationNetwork]
 		if podNetwork == "" {
@@ -421,12 +614,14 @@ func (p *MicroKubeProvider) buildExpectedDNSRecords(pods []*corev1.Pod, networkN
 
 		staticIP := pod.Annotations[annotationStaticIP]
 
+		running := false
 		for i, c := range pod.Spec.Containers {
 			veth := vethName(pod, i)
 			ip, _, ok := p.deps.NetworkMgr.GetPortInfo(veth)
 			if !ok {
 				continue
 			}
+			running = true
 			if staticIP != "" {
 				ip = staticIP
 			}
@@ -434,6 +629,15 @@ func (p *MicroKubeProvider) buildExpectedDNSRecords(pods []*corev1.Pod, networkN
 			// Container hostname: container.pod
 			containerHostname := c.Name + "." + pod.Name
 			expected[containerHostname] = expectedDNS{ip: ip}
+
+			// SRV records for named ports: _port._proto.service -> container
+			if zone != "" {
+				for _, srv := range containerSRVs(pod, c, srvTarget(pod, c, zone)) {
+					e := expected[srv.Name]
+					e.srv = append(e.srv, srv.SRVData)
+					expected[srv.Name] = e
+				}
+			}
 		}
 
 		// Pod-level alias: podName -> first container's IP
@@ -469,6 +673,13 @@ func (p *MicroKubeProvider) buildExpectedDNSRecords(pods []*corev1.Pod, networkN
 				}
 			}
 		}
+
+		// TXT metadata on the pod name
+		if texts := parseTXT(pod.Annotations[annotationTXT]); running && len(texts) > 0 {
+			e := expected[pod.Name]
+			e.txt = texts
+			expected[pod.Name] = e
+		}
 	}
 
 	return expected
@@ -599,6 +810,32 @@ func (p *MicroKubeProvider) checkIPAM(ctx context.Context) []CheckItem {
 		})
 	}
 
+	// Veths sitting on an address reserved by an IPAddressClaim
+	reservedIPs := make(map[string]string)
+	for key, ip := range p.deps.NetworkMgr.Reservations() {
+		reservedIPs[ip] = key
+	}
+	for veth, ipamIP := range ipamAllocs {
+		if holder, ok := reservedIPs[ipamIP]; ok {
+			items = append(items, CheckItem{
+				Name:    fmt.Sprintf("ipam-reserved/%s", veth),
+				Status:  "fail",
+				Message: "veth uses an address reserved by an IPAddressClaim",
+				Details: fmt.Sprintf("ip=%s reservation=%s", ipamIP, holder),
+			})
+		}
+	}
+
+	// Allocations whose address is claimed by another owner in NATS
+	for veth, conflict := range p.deps.NetworkMgr.ClaimConflicts() {
+		items = append(items, CheckItem{
+			Name:    fmt.Sprintf("ipam-claim/%s", veth),
+			Status:  "fail",
+			Message: "IP is claimed by another allocation in the cluster",
+			Details: conflict,
+		})
+	}
+
 	// Verify static IP annotations match actual allocations
 	manifestPath := p.deps.Config.Lifecycle.BootManifestPath
 	if manifestPath != "" {
@@ -1132,12 +1369,27 @@ func (p *MicroKubeProvider) cleanStaleDNSRecords(ctx context.Context) (int, erro
 		}
 
 		for _, r := range records {
+			if srv, ok := r.Data.SRV(); ok {
+				// SRV of an expected service whose target no longer runs
+				exp, isExpected := expected[r.Name]
+				if !isExpected || len(exp.srv) == 0 || slices.Contains(exp.srv, srv) {
+					continue
+				}
+				p.deps.Logger.Infow("deleting stale SRV record",
+					"network", netName, "name", r.Name, "target", srv.Target, "port", srv.Port, "id", r.ID)
+				if err := dnsClient.DeleteRecord(ctx, netDef.DNS.Endpoint, zoneID, r.ID); err != nil {
+					p.deps.Logger.Warnw("failed to delete stale SRV record", "name", r.Name, "error", err)
+				} else {
+					cleaned++
+				}
+				continue
+			}
 			if r.Type != "A" {
 				continue
 			}
 
 			exp, isExpected := expected[r.Name]
-			if !isExpected {
+			if !isExpected || exp.ip == "" {
 				// Hostname not in mkube's expected set — leave it alone.
 				// User-created records (via REST API or mk apply) are not
 				// managed by mkube and must not be deleted.
@@ -1856,7 +2108,7 @@ func (p *MicroKubeProvider) checkPodLiveness(ctx context.Context) []CheckItem {
 			allReachable := true
 			var portResults []string
 			for _, port := range tcpPorts {
-				addr := fmt.Sprintf("%s:%d", podIP, port)
+				addr := net.JoinHostPort(podIP, strconv.Itoa(int(port)))
 				conn, err := net.DialTimeout("tcp", addr, 3*time.Second)
 				if err != nil {
 					allReachable = false
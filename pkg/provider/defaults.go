@@ -76,6 +76,8 @@ registry:
 			}
 		}
 
+		recursorTTL, dhcpTTL := dnsTTLTOML(net.DNS.TTL)
+
 		var dhcpSection string
 		if hasDHCP {
 			reverseZone := ""
@@ -97,8 +99,8 @@ enabled = true
 forward_zone = %q
 reverse_zone_v4 = %q
 reverse_zone_v6 = ""
-default_ttl = 300
-`, net.DNS.Server, net.DNS.Zone, reverseZone)
+default_ttl = %d
+`, net.DNS.Server, net.DNS.Zone, reverseZone, dhcpTTL)
 		}
 
 		// NATS messaging section for DHCP event pipeline
@@ -131,7 +133,7 @@ zones = ["%s"]
 [dns.recursor]
 enabled = true
 listen = "0.0.0.0:53"
-
+%s
 [dns.recursor.forward_zones]
 
 [api.rest]
@@ -144,7 +146,7 @@ path = "/data/microdns.redb"
 [logging]
 level = "info"
 format = "text"
-%s%s`, net.Name, dnsMode, net.DNS.Zone, dhcpSection, messagingSection)
+%s%s`, net.Name, dnsMode, net.DNS.Zone, recursorTTL, dhcpSection, messagingSection)
 
 		cms = append(cms, &corev1.ConfigMap{
 			TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
@@ -223,28 +223,13 @@ func (p *MicroKubeProvider) handleDeleteDeployment(w http.ResponseWriter, r *htt
 	name := r.PathValue("name")
 	key := ns + "/" + name
 
-	deploy, ok := p.deployments[key]
-	if !ok {
+	if _, ok := p.deployments[key]; !ok {
 		http.Error(w, fmt.Sprintf("Deployment %s not found", key), http.StatusNotFound)
 		return
 	}
 
-	// Delete all owned pods
-	ownedPods := p.deploymentPods(deploy)
-	for _, pod := range ownedPods {
-		podKey := pod.Namespace + "/" + pod.Name
-		p.deps.Logger.Infow("deleting deployment-owned pod", "deployment", key, "pod", podKey)
-		if err := p.DeletePod(r.Context(), pod); err != nil {
-			p.deps.Logger.Warnw("failed to delete deployment-owned pod", "pod", podKey, "error", err)
-		}
-		// Remove from NATS Pods bucket
-		if p.deps.Store != nil {
-			storeKey := pod.Namespace + "." + pod.Name
-			if err := p.deps.Store.Pods.Delete(r.Context(), storeKey); err != nil {
-				p.deps.Logger.Warnw("failed to delete pod from store", "key", storeKey, "error", err)
-			}
-		}
-	}
+	// Owned pods are removed by the garbage collector according to the
+	// request's propagation policy (see gc.go)
 
 	// Remove deployment from NATS
 	if p.deps.Store != nil && p.deps.Store.Deployments != nil {
@@ -255,12 +240,12 @@ func (p *MicroKubeProvider) handleDeleteDeployment(w http.ResponseWriter, r *htt
 	}
 
 	delete(p.deployments, key)
-	p.deps.Logger.Infow("deployment deleted", "deployment", key, "podsRemoved", len(ownedPods))
+	p.deps.Logger.Infow("deployment deleted", "deployment", key)
 
 	podWriteJSON(w, http.StatusOK, metav1.Status{
 		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Status"},
 		Status:   "Success",
-		Message:  fmt.Sprintf("deployment %q deleted (%d pods removed)", name, len(ownedPods)),
+		Message:  fmt.Sprintf("deployment %q deleted", name),
 	})
 }
 
@@ -293,6 +278,9 @@ func (p *MicroKubeProvider) reconcileDeployments(ctx context.Context) {
 	}
 
 	for _, deploy := range p.deployments {
+		if deploy.DeletionTimestamp != nil {
+			continue // terminating: the garbage collector is removing its pods
+		}
 		p.reconcileOneDeployment(ctx, deploy)
 	}
 }
@@ -411,6 +399,9 @@ func (p *MicroKubeProvider) rollingUpdateDeployment(ctx context.Context, deploy
 	deployKey := deploy.Namespace + "/" + deploy.Name
 
 	ownedPods := p.deploymentPods(deploy)
+	// Keep every replica's records at the rollout TTL until the last one
+	// is through, so clients move off replaced addresses quickly.
+	defer p.lowerPodDNSTTL(ctx, ownedPods)()
 	for i, pod := range ownedPods {
 		podKey := pod.Namespace + "/" + pod.Name
 		log.Infow("rolling update pod",
@@ -470,7 +461,16 @@ func (p *MicroKubeProvider) podFromDeployment(deploy *Deployment, podName string
 		pod.Labels[k] = v
 	}
 
-	// Set owner annotation
+	// Set owner reference (and the owner annotation older code matches on)
+	controller := true
+	pod.OwnerReferences = []metav1.OwnerReference{{
+		APIVersion:         "v1",
+		Kind:               "Deployment",
+		Name:               deploy.Name,
+		UID:                deploy.UID,
+		Controller:         &controller,
+		BlockOwnerDeletion: &controller,
+	}}
 	pod.Annotations[annotationOwnerDeployment] = deploy.Name
 
 	// Remove static-ip annotation for multi-replica deployments —
@@ -500,12 +500,15 @@ func (p *MicroKubeProvider) deploymentPods(deploy *Deployment) []*corev1.Pod {
 	return pods
 }
 
-// isOwnedByDeployment checks if a pod is owned by a deployment.
-func isOwnedByDeployment(pod *corev1.Pod) bool {
-	if pod.Annotations == nil {
+// ownedByLiveDeployment checks if a pod is owned by a deployment that
+// exists and is not being deleted.
+func (p *MicroKubeProvider) ownedByLiveDeployment(pod *corev1.Pod) bool {
+	name := pod.Annotations[annotationOwnerDeployment]
+	if name == "" {
 		return false
 	}
-	return pod.Annotations[annotationOwnerDeployment] != ""
+	deploy, ok := p.deployments[pod.Namespace+"/"+name]
+	return ok && deploy.DeletionTimestamp == nil
 }
 
 // enrichDeploymentStatus updates the deployment status with live pod counts.
@@ -0,0 +1,485 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kruntime "k8s.io/apimachinery/pkg/runtime"
+)
+
+// ─── Types ──────────────────────────────────────────────────────────────────
+
+// DHCPDevice is the inventory entry of one MAC address seen by DHCP on any
+// managed network. Entries are created and updated by mkube from lease
+// events and polls; the API only reads, forgets or promotes them. The
+// name is the MAC with dashes (aa-bb-cc-dd-ee-ff).
+type DHCPDevice struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	Status            DHCPDeviceStatus `json:"status"`
+}
+
+// DHCPDeviceStatus is what DHCP has seen of a device.
+type DHCPDeviceStatus struct {
+	MAC       string               `json:"mac"`
+	Vendor    string               `json:"vendor,omitempty"`    // from the OUI
+	Network   string               `json:"network"`             // network of the latest lease
+	FirstSeen string               `json:"firstSeen"`           // RFC3339
+	LastSeen  string               `json:"lastSeen"`            // RFC3339
+	IPs       []DHCPDeviceSighting `json:"ips,omitempty"`       // oldest first
+	Hostnames []DHCPDeviceSighting `json:"hostnames,omitempty"` // oldest first
+	KnownAs   string               `json:"knownAs,omitempty"`   // owning BareMetalHost or reservation, filled in on read
+}
+
+// DHCPDeviceSighting is one address or hostname a device used, and when.
+type DHCPDeviceSighting struct {
+	Value     string `json:"value"`
+	Network   string `json:"network,omitempty"`
+	FirstSeen string `json:"firstSeen"`
+	LastSeen  string `json:"lastSeen"`
+}
+
+// DHCPDeviceList is a list of DHCPDevice objects.
+type DHCPDeviceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+	Items           []DHCPDevice `json:"items"`
+}
+
+// DHCPDevicePromotion is the body of POST /api/v1/dhcpdevices/{name}/promote.
+// Kind is BareMetalHost or DHCPReservation; empty fields default to what
+// DHCP last saw of the device.
+type DHCPDevicePromotion struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name,omitempty"`      // BareMetalHost name; default: hostname, else the device name
+	Namespace string `json:"namespace,omitempty"` // BareMetalHost namespace; default: "default"
+	Network   string `json:"network,omitempty"`
+	IP        string `json:"ip,omitempty"`
+	Hostname  string `json:"hostname,omitempty"`
+}
+
+// DeepCopy returns a deep copy of the DHCPDevice.
+func (d *DHCPDevice) DeepCopy() *DHCPDevice {
+	out := *d
+	out.ObjectMeta = *d.ObjectMeta.DeepCopy()
+	out.Status.IPs = append([]DHCPDeviceSighting(nil), d.Status.IPs...)
+	out.Status.Hostnames = append([]DHCPDeviceSighting(nil), d.Status.Hostnames...)
+	return &out
+}
+
+// maxDHCPDeviceHistory bounds the IPs and hostnames kept per device; the
+// least recently seen are dropped first.
+const maxDHCPDeviceHistory = 16
+
+// ─── Inventory ──────────────────────────────────────────────────────────────
+
+// dhcpInventory holds the devices by name. Lease events arrive on NATS
+// callbacks outside the provider lock, so it carries its own.
+type dhcpInventory struct {
+	mu      sync.Mutex
+	devices map[string]*DHCPDevice // dashed MAC -> device
+}
+
+func newDHCPInventory() *dhcpInventory {
+	return &dhcpInventory{devices: make(map[string]*DHCPDevice)}
+}
+
+// observe records a lease of mac and returns a copy of the updated device.
+func (inv *dhcpInventory) observe(network, ip, mac, hostname string, now time.Time) *DHCPDevice {
+	hw, err := net.ParseMAC(mac)
+	if err != nil {
+		return nil
+	}
+	mac = strings.ToLower(hw.String())
+	name := macToDashes(mac)
+	ts := now.UTC().Format(time.RFC3339)
+
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	d, ok := inv.devices[name]
+	if !ok {
+		d = &DHCPDevice{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "DHCPDevice"},
+			ObjectMeta: metav1.ObjectMeta{Name: name, CreationTimestamp: metav1.NewTime(now)},
+			Status: DHCPDeviceStatus{
+				MAC:       mac,
+				Vendor:    macVendor(mac),
+				FirstSeen: ts,
+			},
+		}
+		inv.devices[name] = d
+	}
+	d.Status.Network = network
+	d.Status.LastSeen = ts
+	if ip != "" {
+		d.Status.IPs = sight(d.Status.IPs, ip, network, ts)
+	}
+	if hostname != "" {
+		d.Status.Hostnames = sight(d.Status.Hostnames, hostname, network, ts)
+	}
+	return d.DeepCopy()
+}
+
+// sight marks value as seen at ts, moving it to the end of the history.
+func sight(history []DHCPDeviceSighting, value, network, ts string) []DHCPDeviceSighting {
+	entry := DHCPDeviceSighting{Value: value, Network: network, FirstSeen: ts}
+	for i, s := range history {
+		if s.Value == value && s.Network == network {
+			entry.FirstSeen = s.FirstSeen
+			history = append(history[:i], history[i+1:]...)
+			break
+		}
+	}
+	entry.LastSeen = ts
+	history = append(history, entry)
+	if len(history) > maxDHCPDeviceHistory {
+		history = history[len(history)-maxDHCPDeviceHistory:]
+	}
+	return history
+}
+
+func (inv *dhcpInventory) get(name string) (*DHCPDevice, bool) {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	d, ok := inv.devices[name]
+	if !ok {
+		return nil, false
+	}
+	return d.DeepCopy(), true
+}
+
+func (inv *dhcpInventory) list() []*DHCPDevice {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	out := make([]*DHCPDevice, 0, len(inv.devices))
+	for _, d := range inv.devices {
+		out = append(out, d.DeepCopy())
+	}
+	return out
+}
+
+func (inv *dhcpInventory) put(d *DHCPDevice) {
+	inv.mu.Lock()
+	inv.devices[d.Name] = d
+	inv.mu.Unlock()
+}
+
+func (inv *dhcpInventory) remove(name string) bool {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	_, ok := inv.devices[name]
+	delete(inv.devices, name)
+	return ok
+}
+
+// recordDHCPDevice adds a lease to the device inventory. Called for every
+// lease event and poll result once the network is resolved.
+func (p *MicroKubeProvider) recordDHCPDevice(network, ip, mac, hostname string) {
+	d := p.devices().observe(network, ip, mac, hostname, time.Now())
+	if d == nil {
+		return
+	}
+	if p.deps.Store != nil && p.deps.Store.DHCPDevices != nil {
+		if _, err := p.deps.Store.DHCPDevices.PutJSON(context.Background(), d.Name, d); err != nil {
+			p.deps.Logger.Warnw("failed to persist DHCP device", "mac", d.Status.MAC, "error", err)
+		}
+	}
+}
+
+// dhcpDeviceOwner names what already claims a MAC: a BareMetalHost (boot
+// or BMC MAC) or a reservation on a Network; "" for unknown devices.
+func (p *MicroKubeProvider) dhcpDeviceOwner(mac string) string {
+	for key, bmh := range p.bareMetalHosts {
+		if strings.EqualFold(bmh.Spec.BootMACAddress, mac) || strings.EqualFold(bmh.Spec.BMC.MAC, mac) {
+			return "BareMetalHost " + key
+		}
+	}
+	for _, n := range p.networks {
+		for _, r := range n.Spec.DHCP.Reservations {
+			if strings.EqualFold(r.MAC, mac) {
+				return "DHCPReservation " + n.Name + "/" + firstNonEmpty(r.Hostname, r.IP)
+			}
+		}
+	}
+	return ""
+}
+
+// lastSeen returns the most recently seen value of a history, or "".
+func lastSeen(history []DHCPDeviceSighting) string {
+	if len(history) == 0 {
+		return ""
+	}
+	return history[len(history)-1].Value
+}
+
+// ─── Store Operations ────────────────────────────────────────────────────────
+
+// devices returns the device inventory, reading it from NATS on first use
+// once the store is attached, whether at boot or by a deferred SetStore.
+func (p *MicroKubeProvider) devices() *dhcpInventory {
+	if p.deps.Store != nil && p.deps.Store.DHCPDevices != nil {
+		p.dhcpDevicesLoad.Do(func() { p.loadDHCPDevicesFromStore(context.Background()) })
+	}
+	return p.dhcpDevices
+}
+
+// loadDHCPDevicesFromStore reads the device inventory from NATS. The
+// DHCPDEVICES bucket is not synced to peers: each node keeps the devices
+// its own lease events and polls have seen.
+func (p *MicroKubeProvider) loadDHCPDevicesFromStore(ctx context.Context) {
+
+	keys, err := p.deps.Store.DHCPDevices.Keys(ctx, "")
+	if err != nil {
+		p.deps.Logger.Warnw("failed to list DHCP devices from store", "error", err)
+		return
+	}
+
+	for _, key := range keys {
+		var d DHCPDevice
+		if _, err := p.deps.Store.DHCPDevices.GetJSON(ctx, key, &d); err != nil {
+			p.deps.Logger.Warnw("failed to read DHCP device from store", "key", key, "error", err)
+			continue
+		}
+		p.dhcpDevices.put(&d)
+	}
+
+	if len(keys) > 0 {
+		p.deps.Logger.Infow("loaded DHCP devices from store", "count", len(keys))
+	}
+}
+
+// ─── Handlers ───────────────────────────────────────────────────────────────
+
+func (p *MicroKubeProvider) handleListDHCPDevices(w http.ResponseWriter, r *http.Request) {
+	network := r.URL.Query().Get("network")
+	unknown := r.URL.Query().Get("unknown") == "true"
+
+	items := make([]DHCPDevice, 0)
+	for _, d := range p.devices().list() {
+		if network != "" && d.Status.Network != network {
+			continue
+		}
+		d.Status.KnownAs = p.dhcpDeviceOwner(d.Status.MAC)
+		if unknown && d.Status.KnownAs != "" {
+			continue
+		}
+		d.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "DHCPDevice"}
+		items = append(items, *d)
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Name < items[j].Name })
+
+	if wantsTable(r) {
+		podWriteJSON(w, http.StatusOK, dhcpDeviceListToTable(items))
+		return
+	}
+
+	podWriteJSON(w, http.StatusOK, DHCPDeviceList{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "DHCPDeviceList"},
+		Items:    items,
+	})
+}
+
+func (p *MicroKubeProvider) handleGetDHCPDevice(w http.ResponseWriter, r *http.Request) {
+	name := macToDashes(r.PathValue("name"))
+
+	d, ok := p.devices().get(name)
+	if !ok {
+		http.Error(w, fmt.Sprintf("DHCPDevice %q not found", name), http.StatusNotFound)
+		return
+	}
+	d.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "DHCPDevice"}
+	d.Status.KnownAs = p.dhcpDeviceOwner(d.Status.MAC)
+
+	if wantsTable(r) {
+		podWriteJSON(w, http.StatusOK, dhcpDeviceListToTable([]DHCPDevice{*d}))
+		return
+	}
+
+	podWriteJSON(w, http.StatusOK, d)
+}
+
+// handleDeleteDHCPDevice forgets a device; it reappears on its next lease.
+func (p *MicroKubeProvider) handleDeleteDHCPDevice(w http.ResponseWriter, r *http.Request) {
+	name := macToDashes(r.PathValue("name"))
+
+	if !p.devices().remove(name) {
+		http.Error(w, fmt.Sprintf("DHCPDevice %q not found", name), http.StatusNotFound)
+		return
+	}
+	if p.deps.Store != nil && p.deps.Store.DHCPDevices != nil {
+		if err := p.deps.Store.DHCPDevices.Delete(r.Context(), name); err != nil {
+			http.Error(w, fmt.Sprintf("deleting DHCPDevice from store: %v", err), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	podWriteJSON(w, http.StatusOK, metav1.Status{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Status"},
+		Status:   "Success",
+		Message:  fmt.Sprintf("DHCPDevice %q deleted", name),
+	})
+}
+
+// handlePromoteDHCPDevice turns an unknown device into a BareMetalHost or a
+// DHCP reservation on its network, defaulting IP and hostname to what the
+// device last used.
+func (p *MicroKubeProvider) handlePromoteDHCPDevice(w http.ResponseWriter, r *http.Request) {
+	name := macToDashes(r.PathValue("name"))
+
+	d, ok := p.devices().get(name)
+	if !ok {
+		http.Error(w, fmt.Sprintf("DHCPDevice %q not found", name), http.StatusNotFound)
+		return
+	}
+	if owner := p.dhcpDeviceOwner(d.Status.MAC); owner != "" {
+		http.Error(w, fmt.Sprintf("DHCPDevice %q is already known as %s", name, owner), http.StatusConflict)
+		return
+	}
+
+	var req DHCPDevicePromotion
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("invalid JSON: %v", err), http.StatusBadRequest)
+		return
+	}
+	req.Network = firstNonEmpty(req.Network, d.Status.Network)
+	req.IP = firstNonEmpty(req.IP, lastSeen(d.Status.IPs))
+	req.Hostname = firstNonEmpty(req.Hostname, lastSeen(d.Status.Hostnames))
+
+	n, ok := p.networks[req.Network]
+	if !ok {
+		http.Error(w, fmt.Sprintf("network %q not found", req.Network), http.StatusBadRequest)
+		return
+	}
+	if req.IP != "" {
+		_, subnet, err := net.ParseCIDR(n.Spec.CIDR)
+		if ip := net.ParseIP(req.IP); ip == nil || (err == nil && !subnet.Contains(ip)) {
+			http.Error(w, fmt.Sprintf("IP %q is not an address in network %s (%s)", req.IP, n.Name, n.Spec.CIDR), http.StatusBadRequest)
+			return
+		}
+	}
+
+	switch req.Kind {
+	case "BareMetalHost":
+		p.promoteDHCPDeviceToBMH(w, r, d, req)
+	case "DHCPReservation":
+		if req.IP == "" {
+			http.Error(w, "a reservation needs an IP and the device has never held one", http.StatusBadRequest)
+			return
+		}
+		res := NetworkDHCPReservation{MAC: d.Status.MAC, IP: req.IP, Hostname: req.Hostname}
+		p.upsertNetworkReservation(r.Context(), n.Name, res, "dhcpdevice/"+name)
+		p.rebuildDHCPIndex()
+		p.recordNetworkEvent(n.Name, "DHCPDevicePromoted",
+			fmt.Sprintf("device %s (%s) reserved at %s", d.Status.MAC, firstNonEmpty(d.Status.Vendor, "unknown vendor"), req.IP), "Normal")
+		podWriteJSON(w, http.StatusCreated, res)
+	default:
+		http.Error(w, fmt.Sprintf("kind must be BareMetalHost or DHCPReservation, got %q", req.Kind), http.StatusBadRequest)
+	}
+}
+
+func (p *MicroKubeProvider) promoteDHCPDeviceToBMH(w http.ResponseWriter, r *http.Request, d *DHCPDevice, req DHCPDevicePromotion) {
+	bmh := BareMetalHost{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "BareMetalHost"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              firstNonEmpty(req.Name, req.Hostname, d.Name),
+			Namespace:         firstNonEmpty(req.Namespace, "default"),
+			CreationTimestamp: metav1.Now(),
+		},
+		Spec: BMHSpec{
+			BootMACAddress: d.Status.MAC,
+			Network:        req.Network,
+			IP:             req.IP,
+			Hostname:       req.Hostname,
+		},
+		Status: BMHStatus{Phase: "Registering"},
+	}
+	key := bmh.Namespace + "/" + bmh.Name
+	if _, exists := p.bareMetalHosts[key]; exists {
+		http.Error(w, fmt.Sprintf("BareMetalHost %s already exists", key), http.StatusConflict)
+		return
+	}
+	if !p.admitCRD(w, &bmh, nil) {
+		return
+	}
+
+	if p.deps.Store != nil && p.deps.Store.BareMetalHosts != nil {
+		if _, err := p.deps.Store.BareMetalHosts.PutJSON(r.Context(), bmh.Namespace+"."+bmh.Name, &bmh); err != nil {
+			http.Error(w, fmt.Sprintf("persisting BMH: %v", err), http.StatusInternalServerError)
+			return
+		}
+	}
+	p.bareMetalHosts[key] = &bmh
+	p.syncBMHToNetwork(r.Context(), &bmh, "", "", "", "")
+	p.rebuildDHCPIndex()
+	p.recordNetworkEvent(req.Network, "DHCPDevicePromoted",
+		fmt.Sprintf("device %s (%s) registered as BareMetalHost %s", d.Status.MAC, firstNonEmpty(d.Status.Vendor, "unknown vendor"), key), "Normal")
+
+	podWriteJSON(w, http.StatusCreated, &bmh)
+}
+
+// ─── Table Format ───────────────────────────────────────────────────────────
+
+func dhcpDeviceListToTable(items []DHCPDevice) *metav1.Table {
+	table := &metav1.Table{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "meta.k8s.io/v1",
+			Kind:       "Table",
+		},
+		ColumnDefinitions: []metav1.TableColumnDefinition{
+			{Name: "Name", Type: "string", Format: "name"},
+			{Name: "Network", Type: "string"},
+			{Name: "IP", Type: "string"},
+			{Name: "Hostname", Type: "string"},
+			{Name: "Vendor", Type: "string"},
+			{Name: "Known As", Type: "string"},
+			{Name: "Last Seen", Type: "string"},
+			{Name: "Age", Type: "string"},
+		},
+	}
+
+	for i := range items {
+		d := &items[i]
+
+		age := "<unknown>"
+		if !d.CreationTimestamp.IsZero() {
+			age = formatAge(time.Since(d.CreationTimestamp.Time))
+		}
+		seen := "-"
+		if t, err := time.Parse(time.RFC3339, d.Status.LastSeen); err == nil {
+			seen = formatAge(time.Since(t))
+		}
+
+		raw, _ := json.Marshal(map[string]interface{}{
+			"kind":       "PartialObjectMetadata",
+			"apiVersion": "meta.k8s.io/v1",
+			"metadata": map[string]interface{}{
+				"name":              d.Name,
+				"creationTimestamp": d.CreationTimestamp.Format(time.RFC3339),
+			},
+		})
+
+		table.Rows = append(table.Rows, metav1.TableRow{
+			Cells: []interface{}{
+				d.Name,
+				d.Status.Network,
+				firstNonEmpty(lastSeen(d.Status.IPs), "-"),
+				firstNonEmpty(lastSeen(d.Status.Hostnames), "-"),
+				firstNonEmpty(d.Status.Vendor, "-"),
+				firstNonEmpty(d.Status.KnownAs, "-"),
+				seen,
+				age,
+			},
+			Object: kruntime.RawExtension{Raw: raw},
+		})
+	}
+
+	return table
+}
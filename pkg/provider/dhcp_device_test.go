@@ -0,0 +1,130 @@
+package provider
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestMACVendor(t *testing.T) {
+	for mac, want := range map[string]string{
+		"0C:C4:7A:01:02:03": "Super Micro Computer",
+		"b8-27-eb-aa-bb-cc": "Raspberry Pi",
+		"4c:5e:0c:00:00:01": "MikroTik",
+		"da:a1:19:00:00:01": "locally administered",
+		"00:00:5e:00:53:01": "",
+		"not-a-mac":         "",
+	} {
+		if got := macVendor(mac); got != want {
+			t.Errorf("macVendor(%q) = %q, want %q", mac, got, want)
+		}
+	}
+}
+
+func TestDHCPInventoryHistory(t *testing.T) {
+	inv := newDHCPInventory()
+	t0 := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	inv.observe("g10", "192.168.10.50", "AC:1F:6B:00:00:01", "", t0)
+	inv.observe("g10", "192.168.10.51", "ac:1f:6b:00:00:01", "node-a", t0.Add(time.Hour))
+	d := inv.observe("g10", "192.168.10.50", "ac-1f-6b-00-00-01", "node-a", t0.Add(2*time.Hour))
+
+	if d.Name != "ac-1f-6b-00-00-01" || d.Status.MAC != "ac:1f:6b:00:00:01" || d.Status.Vendor != "Super Micro Computer" {
+		t.Fatalf("device = %+v", d)
+	}
+	if d.Status.FirstSeen != "2026-10-01T12:00:00Z" || d.Status.LastSeen != "2026-10-01T14:00:00Z" {
+		t.Errorf("seen = %s .. %s", d.Status.FirstSeen, d.Status.LastSeen)
+	}
+	// .50 came back: moved to the end, keeping its first sighting
+	if len(d.Status.IPs) != 2 || d.Status.IPs[1].Value != "192.168.10.50" || d.Status.IPs[1].FirstSeen != "2026-10-01T12:00:00Z" {
+		t.Errorf("IP history = %+v", d.Status.IPs)
+	}
+	if len(d.Status.Hostnames) != 1 || d.Status.Hostnames[0].FirstSeen != "2026-10-01T13:00:00Z" {
+		t.Errorf("hostname history = %+v", d.Status.Hostnames)
+	}
+
+	for i := 0; i < 2*maxDHCPDeviceHistory; i++ {
+		d = inv.observe("g10", "10.0.0."+string(rune('a'+i)), "ac:1f:6b:00:00:01", "", t0)
+	}
+	if len(d.Status.IPs) != maxDHCPDeviceHistory {
+		t.Errorf("history not bounded: %d entries", len(d.Status.IPs))
+	}
+	if inv.observe("g10", "10.0.0.1", "bogus", "", t0) != nil {
+		t.Error("invalid MAC recorded")
+	}
+}
+
+func TestDHCPDevicePromote(t *testing.T) {
+	p, _ := newTestProvider(t)
+	p.networks["g10"] = &Network{
+		ObjectMeta: metav1.ObjectMeta{Name: "g10"},
+		Spec: NetworkSpec{
+			CIDR: "192.168.10.0/24",
+			DHCP: NetworkDHCPSpec{Enabled: true, Reservations: []NetworkDHCPReservation{
+				{MAC: "aa:bb:cc:00:00:09", IP: "192.168.10.9", Hostname: "known"},
+			}},
+		},
+	}
+	p.rebuildDHCPIndex()
+
+	// Lease events feed the inventory
+	p.publishDHCPEvent("LeaseCreated", "", "192.168.10.60", "0c:c4:7a:00:00:01", "mystery")
+	p.publishDHCPEvent("LeaseCreated", "", "192.168.10.61", "b8:27:eb:00:00:02", "")
+	p.publishDHCPEvent("LeaseCreated", "", "192.168.10.9", "aa:bb:cc:00:00:09", "")
+
+	mux := http.NewServeMux()
+	p.RegisterRoutes(mux)
+	handler := p.WrapHandler(mux)
+	do := func(method, url, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(method, url, strings.NewReader(body)))
+		return rec
+	}
+
+	rec := do(http.MethodGet, "/api/v1/dhcpdevices?unknown=true", "")
+	var list DHCPDeviceList
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("list: %d %s", rec.Code, rec.Body)
+	}
+	if len(list.Items) != 2 || list.Items[0].Name != "0c-c4-7a-00-00-01" || list.Items[0].Status.Network != "g10" {
+		t.Fatalf("unknown devices = %+v", list.Items)
+	}
+
+	if rec := do(http.MethodPost, "/api/v1/dhcpdevices/aa-bb-cc-00-00-09/promote", `{"kind":"DHCPReservation"}`); rec.Code != http.StatusConflict {
+		t.Errorf("promoting a known device: got %d, want 409", rec.Code)
+	}
+	if rec := do(http.MethodPost, "/api/v1/dhcpdevices/0c-c4-7a-00-00-01/promote", `{"kind":"DHCPReservation","ip":"10.1.1.1"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("IP outside the network: got %d, want 400", rec.Code)
+	}
+
+	// Reservation defaults to the last IP and hostname seen
+	if rec := do(http.MethodPost, "/api/v1/dhcpdevices/0c-c4-7a-00-00-01/promote", `{"kind":"DHCPReservation"}`); rec.Code != http.StatusCreated {
+		t.Fatalf("promote to reservation: %d %s", rec.Code, rec.Body)
+	}
+	res := p.networks["g10"].Spec.DHCP.Reservations
+	if len(res) != 2 || res[1].MAC != "0c:c4:7a:00:00:01" || res[1].IP != "192.168.10.60" || res[1].Hostname != "mystery" {
+		t.Errorf("reservations = %+v", res)
+	}
+
+	if rec := do(http.MethodPost, "/api/v1/dhcpdevices/b8:27:eb:00:00:02/promote", `{"kind":"BareMetalHost","name":"pi1"}`); rec.Code != http.StatusCreated {
+		t.Fatalf("promote to BMH: %d %s", rec.Code, rec.Body)
+	}
+	bmh, ok := p.bareMetalHosts["default/pi1"]
+	if !ok || bmh.Spec.BootMACAddress != "b8:27:eb:00:00:02" || bmh.Spec.Network != "g10" || bmh.Spec.IP != "192.168.10.61" {
+		t.Fatalf("BMH = %+v", bmh)
+	}
+
+	rec = do(http.MethodGet, "/api/v1/dhcpdevices/b8-27-eb-00-00-02", "")
+	var d DHCPDevice
+	if err := json.Unmarshal(rec.Body.Bytes(), &d); err != nil || d.Status.KnownAs != "BareMetalHost default/pi1" {
+		t.Errorf("promoted device: %s", rec.Body)
+	}
+	if rec := do(http.MethodGet, "/api/v1/dhcpdevices?unknown=true", ""); strings.Contains(rec.Body.String(), `"name":`) {
+		t.Errorf("promoted devices still listed as unknown: %s", rec.Body)
+	}
+}
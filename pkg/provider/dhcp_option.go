@@ -0,0 +1,205 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/glennswest/mkube/pkg/dns"
+)
+
+// ─── DHCP Options ───────────────────────────────────────────────────────────
+
+// validateDHCPOptions checks that each option encodes and that no code is
+// set twice at the same level.
+func validateDHCPOptions(field string, opts []DHCPOptionSpec) []string {
+	var errs []string
+	seen := make(map[int]int)
+	for i, o := range opts {
+		ofield := fmt.Sprintf("%s[%d]", field, i)
+		if j, ok := seen[o.Code]; ok {
+			errs = append(errs, fmt.Sprintf("%s.code: option %d is already set by %s[%d]", ofield, o.Code, field, j))
+			continue
+		}
+		seen[o.Code] = i
+		if _, err := dns.EncodeDHCPOption(o.toDNS()); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", ofield, err))
+		}
+	}
+	return errs
+}
+
+// validateDHCPVendorClasses checks vendor class matches and their options.
+func validateDHCPVendorClasses(field string, classes []DHCPVendorClassSpec) []string {
+	var errs []string
+	seen := make(map[string]int)
+	for i, vc := range classes {
+		cfield := fmt.Sprintf("%s[%d]", field, i)
+		match := strings.TrimSpace(vc.Match)
+		if match == "" {
+			errs = append(errs, cfield+".match: a vendor class identifier prefix (option 60) is required")
+		} else if j, ok := seen[match]; ok {
+			errs = append(errs, fmt.Sprintf("%s.match: %q is already used by %s[%d]", cfield, match, field, j))
+		} else {
+			seen[match] = i
+		}
+		if len(vc.Options) == 0 {
+			errs = append(errs, cfield+".options: at least one option is required")
+		}
+		errs = append(errs, validateDHCPOptions(cfield+".options", vc.Options)...)
+	}
+	return errs
+}
+
+// validateNetworkDHCPOptions checks the options of a network, its vendor
+// classes and its reservations.
+func validateNetworkDHCPOptions(n *Network) []string {
+	errs := validateDHCPOptions("spec.dhcp.options", n.Spec.DHCP.Options)
+	errs = append(errs, validateDHCPVendorClasses("spec.dhcp.vendorClasses", n.Spec.DHCP.VendorClasses)...)
+	for i, r := range n.Spec.DHCP.Reservations {
+		errs = append(errs, validateDHCPOptions(fmt.Sprintf("spec.dhcp.reservations[%d].options", i), r.Options)...)
+	}
+	return errs
+}
+
+func (o DHCPOptionSpec) toDNS() dns.DHCPOption {
+	return dns.DHCPOption{Code: o.Code, Type: o.Type, Value: o.Value}
+}
+
+// dhcpOptionsToDNS validates options and encodes them for microdns.
+func dhcpOptionsToDNS(field string, opts []DHCPOptionSpec) ([]dns.DHCPOption, error) {
+	if errs := validateDHCPOptions(field, opts); len(errs) > 0 {
+		return nil, errors.New(strings.Join(errs, "; "))
+	}
+	in := make([]dns.DHCPOption, 0, len(opts))
+	for _, o := range opts {
+		in = append(in, o.toDNS())
+	}
+	return dns.EncodeDHCPOptions(in)
+}
+
+// dhcpPoolOptionsToDNS validates and encodes pool-level options and vendor
+// classes for microdns.
+func dhcpPoolOptionsToDNS(field string, opts []DHCPOptionSpec, classes []DHCPVendorClassSpec) ([]dns.DHCPOption, []dns.DHCPVendorClass, error) {
+	if errs := validateDHCPVendorClasses(field+".vendorClasses", classes); len(errs) > 0 {
+		return nil, nil, errors.New(strings.Join(errs, "; "))
+	}
+	out, err := dhcpOptionsToDNS(field+".options", opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	var outClasses []dns.DHCPVendorClass
+	for i, vc := range classes {
+		vcOpts, err := dhcpOptionsToDNS(fmt.Sprintf("%s.vendorClasses[%d].options", field, i), vc.Options)
+		if err != nil {
+			return nil, nil, err
+		}
+		outClasses = append(outClasses, dns.DHCPVendorClass{Match: strings.TrimSpace(vc.Match), Options: vcOpts})
+	}
+	return out, outClasses, nil
+}
+
+// storedDHCPOptions encodes the options of a stored network. They were
+// validated on save, so an error means a stale object; push nothing rather
+// than a partial set.
+func storedDHCPOptions(opts []DHCPOptionSpec) []dns.DHCPOption {
+	out, err := dhcpOptionsToDNS("options", opts)
+	if err != nil {
+		return nil
+	}
+	return out
+}
+
+func dhcpOptionsFromDNS(opts []dns.DHCPOption) []DHCPOptionSpec {
+	var out []DHCPOptionSpec
+	for _, o := range opts {
+		out = append(out, DHCPOptionSpec{Code: o.Code, Type: o.Type, Value: o.Value})
+	}
+	return out
+}
+
+func dhcpVendorClassesFromDNS(classes []dns.DHCPVendorClass) []DHCPVendorClassSpec {
+	var out []DHCPVendorClassSpec
+	for _, vc := range classes {
+		out = append(out, DHCPVendorClassSpec{Match: vc.Match, Options: dhcpOptionsFromDNS(vc.Options)})
+	}
+	return out
+}
+
+// dhcpOptionsKey is a comparable form of a pool's options: codes and wire
+// data, in order.
+func dhcpOptionsKey(opts []dns.DHCPOption, classes []dns.DHCPVendorClass) string {
+	var b strings.Builder
+	for _, o := range opts {
+		fmt.Fprintf(&b, "%d=%s;", o.Code, o.Data)
+	}
+	for _, vc := range classes {
+		fmt.Fprintf(&b, "[%s]", vc.Match)
+		for _, o := range vc.Options {
+			fmt.Fprintf(&b, "%d=%s;", o.Code, o.Data)
+		}
+	}
+	return b.String()
+}
+
+// syncDHCPPoolOptions brings the options of an existing microdns pool in
+// line with the source network, leaving the rest of the pool untouched.
+func (p *MicroKubeProvider) syncDHCPPoolOptions(ctx context.Context, client *dns.Client, endpoint string, pool dns.DHCPPool, source *Network) error {
+	opts, classes, err := dhcpPoolOptionsToDNS("spec.dhcp", source.Spec.DHCP.Options, source.Spec.DHCP.VendorClasses)
+	if err != nil {
+		return err
+	}
+	if dhcpOptionsKey(pool.Options, pool.VendorClasses) == dhcpOptionsKey(opts, classes) {
+		return nil
+	}
+	pool.Options, pool.VendorClasses = opts, classes
+	if _, err := client.UpdateDHCPPool(ctx, endpoint, pool.ID, pool); err != nil {
+		return err
+	}
+	p.deps.Logger.Infow("DHCP pool options updated", "network", source.Name, "pool", pool.ID,
+		"options", len(opts), "vendorClasses", len(classes))
+	return nil
+}
+
+// syncNetworkDHCPPoolOptions finds the network's pool on its microdns
+// instance and syncs its options.
+func (p *MicroKubeProvider) syncNetworkDHCPPoolOptions(ctx context.Context, client *dns.Client, endpoint string, net *Network) error {
+	if !net.Spec.DHCP.Enabled {
+		return nil
+	}
+	pools, err := client.ListDHCPPools(ctx, endpoint)
+	if err != nil {
+		return err
+	}
+	for _, pool := range pools {
+		if pool.Subnet == net.Spec.CIDR {
+			return p.syncDHCPPoolOptions(ctx, client, endpoint, pool, net)
+		}
+	}
+	return nil
+}
+
+func equalDHCPOptions(a, b []DHCPOptionSpec) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func equalDHCPVendorClasses(a, b []DHCPVendorClassSpec) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Match != b[i].Match || !equalDHCPOptions(a[i].Options, b[i].Options) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,113 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/glennswest/mkube/pkg/dns"
+)
+
+// poolServer is an in-memory microdns DHCP pool API.
+type poolServer struct {
+	mu    sync.Mutex
+	pools []dns.DHCPPool
+	puts  int
+}
+
+func (s *poolServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := strings.TrimPrefix(r.URL.Path, "/api/v1/dhcp/pools")
+	switch {
+	case id == "" && r.Method == http.MethodGet:
+		_ = json.NewEncoder(w).Encode(s.pools)
+	case id == "" && r.Method == http.MethodPost:
+		var pool dns.DHCPPool
+		_ = json.NewDecoder(r.Body).Decode(&pool)
+		pool.ID = pool.Name
+		s.pools = append(s.pools, pool)
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(pool)
+	case r.Method == http.MethodPut:
+		var pool dns.DHCPPool
+		_ = json.NewDecoder(r.Body).Decode(&pool)
+		for i := range s.pools {
+			if "/"+s.pools[i].ID == id {
+				s.pools[i] = pool
+				s.puts++
+				_ = json.NewEncoder(w).Encode(pool)
+				return
+			}
+		}
+		http.NotFound(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestSeedDHCPPoolOptions(t *testing.T) {
+	p, _ := newTestProvider(t)
+	ps := &poolServer{}
+	srv := httptest.NewServer(ps)
+	t.Cleanup(srv.Close)
+	client := dns.NewClient(p.deps.Logger)
+	ctx := context.Background()
+
+	n := &Network{
+		ObjectMeta: metav1.ObjectMeta{Name: "g10"},
+		Spec: NetworkSpec{
+			CIDR:    "192.168.10.0/24",
+			Gateway: "192.168.10.1",
+			DNS:     NetworkDNSSpec{Zone: "g10.lo", Server: "192.168.10.199"},
+			DHCP: NetworkDHCPSpec{Enabled: true, RangeStart: "192.168.10.100", RangeEnd: "192.168.10.200",
+				Options: []DHCPOptionSpec{{Code: 42, Value: "192.168.10.1"}},
+				VendorClasses: []DHCPVendorClassSpec{{Match: "PXEClient",
+					Options: []DHCPOptionSpec{{Code: 66, Value: "192.168.10.1"}, {Code: 67, Value: "ipxe.efi"}}}},
+			},
+		},
+	}
+
+	p.seedDHCPPool(ctx, client, srv.URL, n, n)
+	if len(ps.pools) != 1 {
+		t.Fatalf("pools = %+v", ps.pools)
+	}
+	pool := ps.pools[0]
+	if len(pool.Options) != 1 || pool.Options[0].Data != "c0a80a01" {
+		t.Errorf("pool options = %+v", pool.Options)
+	}
+	if len(pool.VendorClasses) != 1 || pool.VendorClasses[0].Match != "PXEClient" || len(pool.VendorClasses[0].Options) != 2 {
+		t.Errorf("vendor classes = %+v", pool.VendorClasses)
+	}
+
+	// Unchanged options: no update
+	p.seedDHCPPool(ctx, client, srv.URL, n, n)
+	if ps.puts != 0 {
+		t.Errorf("unchanged options pushed %d updates", ps.puts)
+	}
+
+	// Changed options update the existing pool in place
+	n.Spec.DHCP.Options = []DHCPOptionSpec{{Code: 121, Value: "10.0.0.0/8 via 192.168.10.254"}}
+	n.Spec.DHCP.VendorClasses = nil
+	p.seedDHCPPool(ctx, client, srv.URL, n, n)
+	if ps.puts != 1 || len(ps.pools) != 1 {
+		t.Fatalf("puts = %d, pools = %d", ps.puts, len(ps.pools))
+	}
+	pool = ps.pools[0]
+	if len(pool.Options) != 1 || pool.Options[0].Code != 121 || pool.Options[0].Data != "080ac0a80afe" ||
+		len(pool.VendorClasses) != 0 || pool.RangeStart != "192.168.10.100" {
+		t.Errorf("updated pool = %+v", pool)
+	}
+
+	res := networkReservationToDNS(NetworkDHCPReservation{MAC: "aa:bb:cc:00:00:01", IP: "192.168.10.5",
+		Options: []DHCPOptionSpec{{Code: 43, Value: "01:04:c0:a8:0a:01"}}})
+	if len(res.Options) != 1 || res.Options[0].Type != "hex" || res.Options[0].Data != "0104c0a80a01" {
+		t.Errorf("reservation options = %+v", res.Options)
+	}
+}
@@ -0,0 +1,88 @@
+package provider
+
+import (
+	"net"
+	"strings"
+)
+
+// ouiVendors maps the IEEE OUI (first three octets, lower case, colon
+// separated) to a vendor name for hardware commonly seen on the lab
+// networks: server boards, NICs, BMCs, switches, SBCs and hypervisors.
+// Unknown prefixes report no vendor.
+var ouiVendors = map[string]string{
+	// Server boards and BMCs
+	"00:25:90": "Super Micro Computer",
+	"0c:c4:7a": "Super Micro Computer",
+	"3c:ec:ef": "Super Micro Computer",
+	"ac:1f:6b": "Super Micro Computer",
+	"00:14:22": "Dell",
+	"14:18:77": "Dell",
+	"18:03:73": "Dell",
+	"24:6e:96": "Dell",
+	"b8:ac:6f": "Dell",
+	"d4:ae:52": "Dell",
+	"f8:bc:12": "Dell",
+
+	// NICs
+	"00:1b:21": "Intel",
+	"00:1e:67": "Intel",
+	"00:15:17": "Intel",
+	"3c:fd:fe": "Intel",
+	"a0:36:9f": "Intel",
+	"00:02:c9": "Mellanox",
+	"24:8a:07": "Mellanox",
+	"7c:fe:90": "Mellanox",
+	"00:07:43": "Chelsio",
+	"00:10:18": "Broadcom",
+	"00:e0:4c": "Realtek",
+
+	// Network gear
+	"4c:5e:0c": "MikroTik",
+	"64:d1:54": "MikroTik",
+	"6c:3b:6b": "MikroTik",
+	"b8:69:f4": "MikroTik",
+	"cc:2d:e0": "MikroTik",
+	"d4:ca:6d": "MikroTik",
+	"e4:8d:8c": "MikroTik",
+	"04:18:d6": "Ubiquiti",
+	"24:a4:3c": "Ubiquiti",
+	"78:8a:20": "Ubiquiti",
+	"80:2a:a8": "Ubiquiti",
+	"f0:9f:c2": "Ubiquiti",
+	"00:00:0c": "Cisco",
+	"50:c7:bf": "TP-Link",
+	"00:11:32": "Synology",
+
+	// Single-board computers and IoT
+	"b8:27:eb": "Raspberry Pi",
+	"dc:a6:32": "Raspberry Pi",
+	"e4:5f:01": "Raspberry Pi",
+	"24:0a:c4": "Espressif",
+	"30:ae:a4": "Espressif",
+	"a4:cf:12": "Espressif",
+
+	// Hypervisors
+	"00:0c:29": "VMware",
+	"00:50:56": "VMware",
+	"08:00:27": "VirtualBox",
+	"00:16:3e": "Xen",
+	"52:54:00": "QEMU/KVM",
+}
+
+// macVendor returns the vendor of a MAC address from its OUI. Locally
+// administered addresses (randomized phone MACs, most virtual NICs) carry
+// no OUI and are reported as such.
+func macVendor(mac string) string {
+	hw, err := net.ParseMAC(mac)
+	if err != nil || len(hw) < 3 {
+		return ""
+	}
+	oui := strings.ToLower(hw[:3].String())
+	if v, ok := ouiVendors[oui]; ok {
+		return v
+	}
+	if hw[0]&0x02 != 0 {
+		return "locally administered"
+	}
+	return ""
+}
@@ -16,10 +16,11 @@ import (
 
 // dhcpLease is the JSON structure from microdns DHCP lease API.
 type dhcpLease struct {
-	IP      string `json:"ip"`
-	IPAddr  string `json:"ip_addr"`  // microdns field name
-	MAC     string `json:"mac"`
-	MACAddr string `json:"mac_addr"` // microdns field name
+	IP       string `json:"ip"`
+	IPAddr   string `json:"ip_addr"`  // microdns field name
+	MAC      string `json:"mac"`
+	MACAddr  string `json:"mac_addr"` // microdns field name
+	Hostname string `json:"hostname"`
 }
 
 func (l dhcpLease) getIP() string {
@@ -286,8 +287,9 @@ func (p *MicroKubeProvider) startDHCPSubscription(ctx context.Context) {
 	log.Info("DHCP NATS subscription started on microdns.*.leases")
 }
 
-// publishDHCPEvent resolves the network and publishes a typed event on
-// mkube.dhcp.{network}.lease for the bmh-operator (and any other consumer).
+// publishDHCPEvent resolves the network, records the lease in the device
+// inventory and publishes a typed event on mkube.dhcp.{network}.lease for
+// the bmh-operator (and any other consumer).
 func (p *MicroKubeProvider) publishDHCPEvent(eventType, network, ip, mac, hostname string) {
 	log := p.deps.Logger.Named("dhcp-watcher")
 
@@ -315,6 +317,8 @@ func (p *MicroKubeProvider) publishDHCPEvent(eventType, network, ip, mac, hostna
 		return
 	}
 
+	p.recordDHCPDevice(network, ip, mac, hostname)
+
 	// Look up network type
 	networkType := ""
 	if net, ok := p.networks[network]; ok {
@@ -416,6 +420,6 @@ func (p *MicroKubeProvider) pollDHCPLeases(ctx context.Context, log *zap.Sugared
 	resp.Body.Close()
 
 	for _, lease := range leases {
-		p.publishDHCPEvent("LeaseCreated", networkName, lease.getIP(), lease.getMAC(), "")
+		p.publishDHCPEvent("LeaseCreated", networkName, lease.getIP(), lease.getMAC(), lease.Hostname)
 	}
 }
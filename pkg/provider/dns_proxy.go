@@ -30,6 +30,7 @@ type DNSRecordSpec struct {
 	Data     interface{} `json:"data"`
 	TTL      int         `json:"ttl"`
 	Enabled  *bool       `json:"enabled,omitempty"`
+	View     string      `json:"view,omitempty"` // split-horizon view holding the record
 }
 
 type DNSRecordList struct {
@@ -59,6 +60,9 @@ type DHCPPoolSpec struct {
 	BootFileEFI   string   `json:"bootFileEfi,omitempty"`
 	IPXEBootURL   string   `json:"ipxeBootUrl,omitempty"`
 	RootPath      string   `json:"rootPath,omitempty"`
+
+	Options       []DHCPOptionSpec      `json:"options,omitempty"`
+	VendorClasses []DHCPVendorClassSpec `json:"vendorClasses,omitempty"`
 }
 
 type DHCPPoolList struct {
@@ -83,6 +87,8 @@ type DHCPReservationSpec struct {
 	BootFileEFI string `json:"bootFileEfi,omitempty"`
 	IPXEBootURL string `json:"ipxeBootUrl,omitempty"`
 	RootPath    string `json:"rootPath,omitempty"`
+
+	Options []DHCPOptionSpec `json:"options,omitempty"`
 }
 
 type DHCPReservationList struct {
@@ -205,7 +211,7 @@ func (p *MicroKubeProvider) handleListDNSRecords(w http.ResponseWriter, r *http.
 	if !ok {
 		return
 	}
-	zoneID, ok := p.resolveZoneID(w, r, endpoint, net)
+	zoneID, ok := p.resolveRecordZoneID(w, r, endpoint, net)
 	if !ok {
 		return
 	}
@@ -216,9 +222,16 @@ func (p *MicroKubeProvider) handleListDNSRecords(w http.ResponseWriter, r *http.
 		return
 	}
 
+	if r.URL.Query().Get("format") == formatZoneFile {
+		writeZoneFile(w, net.Spec.DNS.Zone, records)
+		return
+	}
+
 	items := make([]DNSRecord, 0, len(records))
 	for _, rec := range records {
-		items = append(items, fullRecordToResource(rec, ns))
+		res := fullRecordToResource(rec, ns)
+		res.Spec.View = r.URL.Query().Get("view")
+		items = append(items, res)
 	}
 
 	if wantsTable(r) {
@@ -239,7 +252,7 @@ func (p *MicroKubeProvider) handleGetDNSRecord(w http.ResponseWriter, r *http.Re
 	if !ok {
 		return
 	}
-	zoneID, ok := p.resolveZoneID(w, r, endpoint, net)
+	zoneID, ok := p.resolveRecordZoneID(w, r, endpoint, net)
 	if !ok {
 		return
 	}
@@ -251,6 +264,7 @@ func (p *MicroKubeProvider) handleGetDNSRecord(w http.ResponseWriter, r *http.Re
 	}
 
 	res := fullRecordToResource(*rec, ns)
+	res.Spec.View = r.URL.Query().Get("view")
 	if wantsTable(r) {
 		podWriteJSON(w, http.StatusOK, dnsRecordListToTable([]DNSRecord{res}))
 		return
@@ -259,6 +273,9 @@ func (p *MicroKubeProvider) handleGetDNSRecord(w http.ResponseWriter, r *http.Re
 }
 
 func (p *MicroKubeProvider) handleCreateDNSRecord(w http.ResponseWriter, r *http.Request) {
+	if rejectViewWrite(w, r) {
+		return
+	}
 	ns := r.PathValue("namespace")
 	endpoint, net, ok := p.resolveDNSEndpoint(w, ns)
 	if !ok {
@@ -269,6 +286,11 @@ func (p *MicroKubeProvider) handleCreateDNSRecord(w http.ResponseWriter, r *http
 		return
 	}
 
+	if r.URL.Query().Get("format") == formatZoneFile {
+		p.importZoneFile(w, r, endpoint, zoneID, net)
+		return
+	}
+
 	var res DNSRecord
 	if err := json.NewDecoder(r.Body).Decode(&res); err != nil {
 		http.Error(w, fmt.Sprintf("invalid JSON: %v", err), http.StatusBadRequest)
@@ -298,6 +320,9 @@ func (p *MicroKubeProvider) handleCreateDNSRecord(w http.ResponseWriter, r *http
 }
 
 func (p *MicroKubeProvider) handleUpdateDNSRecord(w http.ResponseWriter, r *http.Request) {
+	if rejectViewWrite(w, r) {
+		return
+	}
 	ns := r.PathValue("namespace")
 	name := r.PathValue("name") // record UUID
 	endpoint, net, ok := p.resolveDNSEndpoint(w, ns)
@@ -334,6 +359,9 @@ func (p *MicroKubeProvider) handleUpdateDNSRecord(w http.ResponseWriter, r *http
 }
 
 func (p *MicroKubeProvider) handlePatchDNSRecord(w http.ResponseWriter, r *http.Request) {
+	if rejectViewWrite(w, r) {
+		return
+	}
 	ns := r.PathValue("namespace")
 	name := r.PathValue("name") // record UUID
 	endpoint, net, ok := p.resolveDNSEndpoint(w, ns)
@@ -378,6 +406,9 @@ func (p *MicroKubeProvider) handlePatchDNSRecord(w http.ResponseWriter, r *http.
 }
 
 func (p *MicroKubeProvider) handleDeleteDNSRecord(w http.ResponseWriter, r *http.Request) {
+	if rejectViewWrite(w, r) {
+		return
+	}
 	ns := r.PathValue("namespace")
 	name := r.PathValue("name") // record UUID
 	endpoint, net, ok := p.resolveDNSEndpoint(w, ns)
@@ -499,6 +530,12 @@ func (p *MicroKubeProvider) handleCreateDHCPPool(w http.ResponseWriter, r *http.
 		IPXEBootURL:   res.Spec.IPXEBootURL,
 		RootPath:      res.Spec.RootPath,
 	}
+	opts, classes, err := dhcpPoolOptionsToDNS("spec", res.Spec.Options, res.Spec.VendorClasses)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	pool.Options, pool.VendorClasses = opts, classes
 
 	created, err := p.deps.NetworkMgr.DNSClient().CreateDHCPPool(r.Context(), endpoint, pool)
 	if err != nil {
@@ -539,6 +576,12 @@ func (p *MicroKubeProvider) handleUpdateDHCPPool(w http.ResponseWriter, r *http.
 		IPXEBootURL:   res.Spec.IPXEBootURL,
 		RootPath:      res.Spec.RootPath,
 	}
+	opts, classes, err := dhcpPoolOptionsToDNS("spec", res.Spec.Options, res.Spec.VendorClasses)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	pool.Options, pool.VendorClasses = opts, classes
 
 	updated, err := p.deps.NetworkMgr.DNSClient().UpdateDHCPPool(r.Context(), endpoint, name, pool)
 	if err != nil {
@@ -586,6 +629,12 @@ func (p *MicroKubeProvider) handlePatchDHCPPool(w http.ResponseWriter, r *http.R
 		IPXEBootURL:   merged.Spec.IPXEBootURL,
 		RootPath:      merged.Spec.RootPath,
 	}
+	opts, classes, err := dhcpPoolOptionsToDNS("spec", merged.Spec.Options, merged.Spec.VendorClasses)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	pool.Options, pool.VendorClasses = opts, classes
 
 	updated, err := p.deps.NetworkMgr.DNSClient().UpdateDHCPPool(r.Context(), endpoint, name, pool)
 	if err != nil {
@@ -638,6 +687,8 @@ func dhcpPoolToResource(pool dns.DHCPPool, ns string) DHCPPoolResource {
 			BootFileEFI:   pool.BootFileEFI,
 			IPXEBootURL:   pool.IPXEBootURL,
 			RootPath:      pool.RootPath,
+			Options:       dhcpOptionsFromDNS(pool.Options),
+			VendorClasses: dhcpVendorClassesFromDNS(pool.VendorClasses),
 		},
 	}
 }
@@ -719,6 +770,12 @@ func (p *MicroKubeProvider) handleCreateDHCPReservation(w http.ResponseWriter, r
 		IPXEBootURL: res.Spec.IPXEBootURL,
 		RootPath:    res.Spec.RootPath,
 	}
+	opts, err := dhcpOptionsToDNS("spec.options", res.Spec.Options)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	reservation.Options = opts
 
 	if err := p.deps.NetworkMgr.DNSClient().UpsertDHCPReservation(r.Context(), endpoint, reservation); err != nil {
 		http.Error(w, fmt.Sprintf("creating DHCP reservation: %v", err), http.StatusBadGateway)
@@ -752,6 +809,12 @@ func (p *MicroKubeProvider) handleUpdateDHCPReservation(w http.ResponseWriter, r
 		IPXEBootURL: res.Spec.IPXEBootURL,
 		RootPath:    res.Spec.RootPath,
 	}
+	opts, err := dhcpOptionsToDNS("spec.options", res.Spec.Options)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	reservation.Options = opts
 
 	if err := p.deps.NetworkMgr.DNSClient().UpsertDHCPReservation(r.Context(), endpoint, reservation); err != nil {
 		http.Error(w, fmt.Sprintf("updating DHCP reservation: %v", err), http.StatusBadGateway)
@@ -794,6 +857,12 @@ func (p *MicroKubeProvider) handlePatchDHCPReservation(w http.ResponseWriter, r
 		IPXEBootURL: merged.Spec.IPXEBootURL,
 		RootPath:    merged.Spec.RootPath,
 	}
+	opts, err := dhcpOptionsToDNS("spec.options", merged.Spec.Options)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	reservation.Options = opts
 	if err := p.deps.NetworkMgr.DNSClient().UpsertDHCPReservation(r.Context(), endpoint, reservation); err != nil {
 		http.Error(w, fmt.Sprintf("patching DHCP reservation: %v", err), http.StatusBadGateway)
 		return
@@ -840,6 +909,7 @@ func dhcpReservationToResource(res dns.DHCPReservation, ns string) DHCPReservati
 			BootFileEFI: res.BootFileEFI,
 			IPXEBootURL: res.IPXEBootURL,
 			RootPath:    res.RootPath,
+			Options:     dhcpOptionsFromDNS(res.Options),
 		},
 	}
 }
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/glennswest/mkube/pkg/config"
 	"github.com/glennswest/mkube/pkg/dns"
 )
 
@@ -67,6 +68,11 @@ func (p *MicroKubeProvider) seedDNSConfig(ctx context.Context, net *Network) {
 	// 5. Create DNS forward zones for all peer networks
 	p.seedDNSForwarders(ctx, dnsClient, endpoint, net)
 
+	// 6. Push split-horizon view overrides into their view zones
+	if err := p.syncDNSViews(ctx, dnsClient, endpoint, net); err != nil {
+		log.Warnw("failed to sync DNS views", "endpoint", endpoint, "error", err)
+	}
+
 	log.Infow("DNS config seeded successfully", "endpoint", endpoint)
 
 	// Run smoke test after seed to verify end-to-end functionality
@@ -87,6 +93,9 @@ func (p *MicroKubeProvider) seedDHCPPool(ctx context.Context, client *dns.Client
 	for _, pool := range existing {
 		if pool.Subnet == source.Spec.CIDR {
 			log.Debugw("DHCP pool already exists", "subnet", source.Spec.CIDR, "endpoint", endpoint)
+			if err := p.syncDHCPPoolOptions(ctx, client, endpoint, pool, source); err != nil {
+				log.Warnw("failed to sync DHCP pool options", "network", source.Name, "endpoint", endpoint, "error", err)
+			}
 			return
 		}
 	}
@@ -109,6 +118,10 @@ func (p *MicroKubeProvider) seedDHCPPool(ctx context.Context, client *dns.Client
 		BootFile:      source.Spec.DHCP.BootFile,
 		BootFileEFI:   source.Spec.DHCP.BootFileEFI,
 	}
+	pool.Options, pool.VendorClasses, err = dhcpPoolOptionsToDNS("spec.dhcp", source.Spec.DHCP.Options, source.Spec.DHCP.VendorClasses)
+	if err != nil {
+		log.Warnw("invalid DHCP pool options, creating pool without them", "network", source.Name, "error", err)
+	}
 
 	// For data networks: set default iSCSI root_path to baremetalservices.
 	// All PXE clients on data networks boot baremetalservices by default
@@ -166,6 +179,7 @@ func networkReservationToDNS(r NetworkDHCPReservation) dns.DHCPReservation {
 		BootFile:    r.BootFile,
 		BootFileEFI: r.BootFileEFI,
 		RootPath:    r.RootPath,
+		Options:     storedDHCPOptions(r.Options),
 	}
 }
 
@@ -180,12 +194,13 @@ func (p *MicroKubeProvider) seedReservationDNSRecords(ctx context.Context, clien
 		hostname string
 		ip       string
 		zone     string
+		ttl      int
 	}
 	var entries []resEntry
 
 	for _, r := range net.Spec.DHCP.Reservations {
 		if r.Hostname != "" && r.IP != "" {
-			entries = append(entries, resEntry{r.Hostname, r.IP, net.Spec.DNS.Zone})
+			entries = append(entries, resEntry{r.Hostname, r.IP, net.Spec.DNS.Zone, net.Spec.DNS.TTL.config().TTL(config.DNSTTLStatic, 300)})
 		}
 	}
 
@@ -195,7 +210,7 @@ func (p *MicroKubeProvider) seedReservationDNSRecords(ctx context.Context, clien
 		}
 		for _, r := range peer.Spec.DHCP.Reservations {
 			if r.Hostname != "" && r.IP != "" {
-				entries = append(entries, resEntry{r.Hostname, r.IP, peer.Spec.DNS.Zone})
+				entries = append(entries, resEntry{r.Hostname, r.IP, peer.Spec.DNS.Zone, peer.Spec.DNS.TTL.config().TTL(config.DNSTTLStatic, 300)})
 			}
 		}
 	}
@@ -225,7 +240,7 @@ func (p *MicroKubeProvider) seedReservationDNSRecords(ctx context.Context, clien
 			continue
 		}
 		// RegisterHost is idempotent — no-op if record already exists
-		if err := client.RegisterHost(ctx, endpoint, zoneID, e.hostname, e.ip, 300); err != nil {
+		if err := client.RegisterHost(ctx, endpoint, zoneID, e.hostname, e.ip, e.ttl); err != nil {
 			log.Warnw("failed to create DNS A record from reservation",
 				"hostname", e.hostname, "ip", e.ip, "zone", e.zone, "error", err)
 		}
@@ -0,0 +1,167 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/glennswest/mkube/pkg/dns"
+)
+
+// ─── SRV and TXT Records ────────────────────────────────────────────────────
+
+const (
+	// annotationSRVPriority and annotationSRVWeight set the priority and
+	// weight of the SRV records published for a pod's named ports.
+	annotationSRVPriority = "vkube.io/srv-priority"
+	annotationSRVWeight   = "vkube.io/srv-weight"
+
+	// annotationTXT publishes TXT records on the pod name. Format:
+	// "key=value,key2=value2"; each entry becomes one TXT record.
+	annotationTXT = "vkube.io/txt"
+
+	defaultSRVPriority = 0
+	defaultSRVWeight   = 100
+)
+
+// podServiceName is the service a pod's SRV records are published under:
+// the owning Deployment (the repo's notion of a Service), or the pod itself.
+func podServiceName(pod *corev1.Pod) string {
+	if name := pod.Annotations[annotationOwnerDeployment]; name != "" {
+		return name
+	}
+	return pod.Name
+}
+
+// srvAnnotation reads an SRV priority or weight annotation, falling back
+// to def when it is unset or not a 16-bit number.
+func srvAnnotation(pod *corev1.Pod, ann string, def int) int {
+	v, err := strconv.Atoi(pod.Annotations[ann])
+	if err != nil || v < 0 || v > 65535 {
+		return def
+	}
+	return v
+}
+
+// containerSRVs returns the SRV records for one container's named ports:
+// "_<port>._<proto>.<service>" pointing at target. Unnamed ports are
+// skipped — SRV owner names come from the port name.
+func containerSRVs(pod *corev1.Pod, c corev1.Container, target string) []dns.SRV {
+	service := podServiceName(pod)
+	priority := srvAnnotation(pod, annotationSRVPriority, defaultSRVPriority)
+	weight := srvAnnotation(pod, annotationSRVWeight, defaultSRVWeight)
+
+	var out []dns.SRV
+	for _, port := range c.Ports {
+		if port.Name == "" || port.ContainerPort == 0 {
+			continue
+		}
+		proto := strings.ToLower(string(port.Protocol))
+		if proto == "" {
+			proto = "tcp"
+		}
+		out = append(out, dns.SRV{
+			Name: fmt.Sprintf("_%s._%s.%s", port.Name, proto, service),
+			SRVData: dns.SRVData{
+				Priority: priority,
+				Weight:   weight,
+				Port:     int(port.ContainerPort),
+				Target:   target,
+			},
+		})
+	}
+	return out
+}
+
+// parseTXT parses the vkube.io/txt annotation into sorted TXT strings.
+func parseTXT(annotation string) []string {
+	var out []string
+	for _, part := range strings.Split(annotation, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// podNetworkZone returns the DNS zone of the pod's network ("" = the
+// default network), or "" when it has none.
+func (p *MicroKubeProvider) podNetworkZone(networkName string) string {
+	if networkName == "" {
+		nets := p.deps.NetworkMgr.Networks()
+		if len(nets) == 0 {
+			return ""
+		}
+		networkName = nets[0]
+	}
+	def, ok := p.deps.NetworkMgr.NetworkDef(networkName)
+	if !ok {
+		return ""
+	}
+	return def.DNS.Zone
+}
+
+// srvTarget is the fully qualified container hostname SRV records point
+// at. It lives in the network zone, so namespace-zone SRVs use it too.
+func srvTarget(pod *corev1.Pod, c corev1.Container, zone string) string {
+	return c.Name + "." + pod.Name + "." + zone + "."
+}
+
+// syncPodServiceRecords publishes SRV records for each running container's
+// named ports and the pod's TXT records, in the network zone and the
+// namespace zone. With remove set it withdraws them instead. Each replica
+// owns only the SRVs pointing at its own containers, so scaling a
+// Deployment adds and removes targets without disturbing the others.
+func (p *MicroKubeProvider) syncPodServiceRecords(ctx context.Context, pod *corev1.Pod, networkName, namespaceName string, containerIPs map[string]string, remove bool, log *zap.SugaredLogger) {
+	zone := p.podNetworkZone(networkName)
+	if zone == "" || len(containerIPs) == 0 {
+		return
+	}
+
+	var nsEndpoint, nsZoneID string
+	if namespaceName != "" && p.deps.Namespace != nil {
+		ep, zid, err := p.deps.Namespace.ResolveNamespace(namespaceName)
+		if err == nil {
+			nsEndpoint, nsZoneID = ep, zid
+		}
+	}
+	dnsClient := p.deps.NetworkMgr.DNSClient()
+
+	for _, c := range pod.Spec.Containers {
+		if _, ok := containerIPs[c.Name]; !ok {
+			continue
+		}
+		target := srvTarget(pod, c, zone)
+		var srvs []dns.SRV
+		if !remove {
+			srvs = containerSRVs(pod, c, target)
+		}
+		if err := p.deps.NetworkMgr.SyncDNSServices(ctx, networkName, target, srvs); err != nil {
+			log.Warnw("failed to sync SRV records", "target", target, "error", err)
+		}
+		if nsZoneID != "" && dnsClient != nil {
+			if err := dnsClient.SyncSRV(ctx, nsEndpoint, nsZoneID, target, srvs, 60); err != nil {
+				log.Warnw("failed to sync SRV records in namespace zone", "target", target, "error", err)
+			}
+		}
+	}
+
+	var texts []string
+	if !remove {
+		texts = parseTXT(pod.Annotations[annotationTXT])
+	}
+	if err := p.deps.NetworkMgr.SyncDNSText(ctx, networkName, pod.Name, texts); err != nil {
+		log.Warnw("failed to sync TXT records", "pod", podKey(pod), "error", err)
+	}
+	if nsZoneID != "" && dnsClient != nil {
+		if err := dnsClient.SyncTXT(ctx, nsEndpoint, nsZoneID, pod.Name, texts, 60); err != nil {
+			log.Warnw("failed to sync TXT records in namespace zone", "pod", podKey(pod), "error", err)
+		}
+	}
+}
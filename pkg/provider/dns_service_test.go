@@ -0,0 +1,253 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/glennswest/mkube/pkg/config"
+	"github.com/glennswest/mkube/pkg/dns"
+	"github.com/glennswest/mkube/pkg/network"
+)
+
+// zoneServer is an in-memory microdns zone/record API that keeps record
+// data as raw JSON, like microdns does for SRV.
+type zoneServer struct {
+	mu      sync.Mutex
+	zones   []dns.Zone
+	records map[string][]map[string]any // zone ID -> records
+	nextID  int
+}
+
+func (z *zoneServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/") // api v1 zones [id records [rid]]
+	switch {
+	case len(parts) == 3 && r.Method == http.MethodGet:
+		_ = json.NewEncoder(w).Encode(z.zones)
+	case len(parts) == 3 && r.Method == http.MethodPost:
+		var zone dns.Zone
+		_ = json.NewDecoder(r.Body).Decode(&zone)
+		z.nextID++
+		zone.ID = fmt.Sprintf("z%d", z.nextID)
+		z.zones = append(z.zones, zone)
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(zone)
+	case len(parts) == 4 && r.Method == http.MethodDelete:
+		for i, zone := range z.zones {
+			if zone.ID == parts[3] {
+				z.zones = append(z.zones[:i], z.zones[i+1:]...)
+				delete(z.records, zone.ID)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+		}
+		http.NotFound(w, r)
+	case len(parts) == 5 && r.Method == http.MethodGet:
+		_ = json.NewEncoder(w).Encode(z.records[parts[3]])
+	case len(parts) == 5 && r.Method == http.MethodPost:
+		var rec map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&rec)
+		z.nextID++
+		rec["id"] = fmt.Sprintf("r%d", z.nextID)
+		rec["type"] = rec["data"].(map[string]any)["type"]
+		if _, ok := rec["enabled"]; !ok {
+			rec["enabled"] = true
+		}
+		z.records[parts[3]] = append(z.records[parts[3]], rec)
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(rec)
+	case len(parts) == 6 && r.Method == http.MethodPut:
+		var patch map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&patch)
+		for _, rec := range z.records[parts[3]] {
+			if rec["id"] == parts[5] {
+				for k, v := range patch {
+					rec[k] = v
+				}
+				_ = json.NewEncoder(w).Encode(rec)
+				return
+			}
+		}
+		http.NotFound(w, r)
+	case len(parts) == 6 && r.Method == http.MethodDelete:
+		recs := z.records[parts[3]]
+		for i, rec := range recs {
+			if rec["id"] == parts[5] {
+				z.records[parts[3]] = append(recs[:i], recs[i+1:]...)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+		}
+		http.NotFound(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// list renders the zone's records of one type as "name data" lines.
+func (z *zoneServer) list(rtype string) []string {
+	return z.listZone("z1", rtype)
+}
+
+// listZone is list for any zone ID; an empty rtype lists every type.
+func (z *zoneServer) listZone(zoneID, rtype string) []string {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	var out []string
+	for _, rec := range z.records[zoneID] {
+		if rtype != "" && rec["type"] != rtype {
+			continue
+		}
+		data := rec["data"].(map[string]any)["data"]
+		if srv, ok := data.(map[string]any); ok {
+			data = fmt.Sprintf("%v %v %v %v", srv["priority"], srv["weight"], srv["port"], srv["target"])
+		}
+		out = append(out, fmt.Sprintf("%s %v", rec["name"], data))
+	}
+	sort.Strings(out)
+	return out
+}
+
+// newZoneServer points the test provider's first network at an in-memory
+// microdns serving zone gt.lo (ID z1).
+func newZoneServer(t *testing.T, p *MicroKubeProvider) (*zoneServer, string) {
+	t.Helper()
+	zs := &zoneServer{records: map[string][]map[string]any{}}
+	srv := httptest.NewServer(zs)
+	t.Cleanup(srv.Close)
+
+	p.deps.Config.Networks[0].DNS = config.DNSConfig{Endpoint: srv.URL, Zone: "gt.lo"}
+	netMgr, err := network.NewManager(p.deps.Config.Networks, &mockNetworkDriver{}, dns.NewClient(p.deps.Logger), p.deps.Logger)
+	if err != nil {
+		t.Fatalf("network.NewManager: %v", err)
+	}
+	netMgr.InitDNSZones(context.Background())
+	p.deps.NetworkMgr = netMgr
+	return zs, srv.URL
+}
+
+func TestPodServiceRecords(t *testing.T) {
+	p, _ := newTestProvider(t)
+	ctx := context.Background()
+
+	zs, endpoint := newZoneServer(t, p)
+	netMgr := p.deps.NetworkMgr
+
+	replica := func(name string) *corev1.Pod {
+		pod := testPod(name, "web", "sidecar")
+		pod.Annotations[annotationOwnerDeployment] = "app"
+		pod.Annotations[annotationSRVWeight] = "50"
+		pod.Annotations[annotationTXT] = "version=2, path=/api"
+		pod.Spec.Containers[0].Ports = []corev1.ContainerPort{
+			{Name: "http", ContainerPort: 8080},
+			{Name: "metrics", ContainerPort: 9100, Protocol: corev1.ProtocolUDP},
+			{ContainerPort: 22}, // unnamed: no SRV
+		}
+		return pod
+	}
+	pods := []*corev1.Pod{replica("app-0"), replica("app-1")}
+	for _, pod := range pods {
+		if err := p.CreatePod(ctx, pod); err != nil {
+			t.Fatalf("CreatePod %s: %v", pod.Name, err)
+		}
+	}
+
+	want := []string{
+		"_http._tcp.app 0 50 8080 web.app-0.gt.lo.",
+		"_http._tcp.app 0 50 8080 web.app-1.gt.lo.",
+		"_metrics._udp.app 0 50 9100 web.app-0.gt.lo.",
+		"_metrics._udp.app 0 50 9100 web.app-1.gt.lo.",
+	}
+	if got := zs.list("SRV"); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("SRV records:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if got := zs.list("TXT"); strings.Join(got, ",") != "app-0 path=/api,app-0 version=2,app-1 path=/api,app-1 version=2" {
+		t.Errorf("TXT records = %v", got)
+	}
+
+	// Consistency sees every SRV/TXT record it expects
+	records, err := netMgr.DNSClient().ListRecords(ctx, endpoint, "z1")
+	if err != nil {
+		t.Fatalf("ListRecords with SRV data: %v", err)
+	}
+	expected := p.buildExpectedDNSRecords(pods, "containers")
+	if got := len(expected["_http._tcp.app"].srv); got != 2 {
+		t.Errorf("expected %d http SRV targets, want 2", got)
+	}
+	for _, item := range checkServiceRecords("containers", "gt.lo", records, expected) {
+		if item.Status != "pass" {
+			t.Errorf("%s: %s %s (%s)", item.Name, item.Status, item.Message, item.Details)
+		}
+	}
+
+	// Scaling down removes only that replica's records
+	if err := p.DeletePod(ctx, pods[1]); err != nil {
+		t.Fatalf("DeletePod: %v", err)
+	}
+	if got := zs.list("SRV"); len(got) != 2 || !strings.HasSuffix(got[0], "web.app-0.gt.lo.") || !strings.HasSuffix(got[1], "web.app-0.gt.lo.") {
+		t.Errorf("SRV records after scale-down = %v", got)
+	}
+	if got := zs.list("TXT"); len(got) != 2 || !strings.HasPrefix(got[0], "app-0 ") {
+		t.Errorf("TXT records after scale-down = %v", got)
+	}
+
+	// A record left behind by a crashed replica is flagged
+	expected = p.buildExpectedDNSRecords(pods[:1], "containers")
+	if _, err := netMgr.DNSClient().CreateFullRecord(ctx, endpoint, "z1", map[string]any{
+		"name": "_http._tcp.app", "ttl": 60,
+		"data": dns.FullRecordData{Type: "SRV", Data: dns.SRVData{Weight: 50, Port: 8080, Target: "web.app-7.gt.lo."}},
+	}); err != nil {
+		t.Fatalf("CreateFullRecord: %v", err)
+	}
+	records, _ = netMgr.DNSClient().ListRecords(ctx, endpoint, "z1")
+	stale := false
+	for _, item := range checkServiceRecords("containers", "gt.lo", records, expected) {
+		if item.Name == "dns-srv/containers/_http._tcp.app" && item.Status == "warn" {
+			stale = true
+		}
+	}
+	if !stale {
+		t.Error("stale SRV target not reported")
+	}
+}
+
+func TestPodTXTLeavesForeignRecords(t *testing.T) {
+	p, _ := newTestProvider(t)
+	ctx := context.Background()
+
+	zs, endpoint := newZoneServer(t, p)
+	pod := testPod("app-0", "web")
+	pod.Annotations[annotationTXT] = "version=2"
+	if err := p.CreatePod(ctx, pod); err != nil {
+		t.Fatalf("CreatePod: %v", err)
+	}
+
+	// An ACME client's RFC 2136 challenge at the same name
+	if _, err := p.deps.NetworkMgr.DNSClient().CreateFullRecord(ctx, endpoint, "z1", map[string]any{
+		"name": "app-0", "ttl": 60,
+		"data": dns.RecordData{Type: "TXT", Data: "acme-token"},
+	}); err != nil {
+		t.Fatalf("CreateFullRecord: %v", err)
+	}
+	p.syncPodServiceRecords(ctx, pod, "", "", map[string]string{"web": "172.20.0.2"}, false, p.deps.Logger)
+	if got := zs.list("TXT"); strings.Join(got, ",") != "app-0 acme-token,app-0 version=2" {
+		t.Errorf("TXT records after resync = %v", got)
+	}
+
+	if err := p.DeletePod(ctx, pod); err != nil {
+		t.Fatalf("DeletePod: %v", err)
+	}
+	if got := zs.list("TXT"); strings.Join(got, ",") != "app-0 acme-token" {
+		t.Errorf("TXT records after delete = %v", got)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/binary"
 	"fmt"
 	"net"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -106,7 +107,16 @@ func (p *MicroKubeProvider) runDNSSmokeTest(ctx context.Context, net *Network) {
 	fqdn := canaryName + "." + zone
 	resolved := probeDNSRecord(net.Spec.DNS.Server, fqdn, canaryIP, 5*time.Second)
 
-	// 5. Cleanup — always delete the canary record
+	// 5. Mesh check — the canary must resolve through every peer microdns
+	var unreachable []string
+	if resolved {
+		p.mu.RLock()
+		peers := p.dnsMeshPeers(net)
+		p.mu.RUnlock()
+		unreachable = probeDNSMesh(ctx, dnsClient, peers, fqdn, canaryIP)
+	}
+
+	// 6. Cleanup — always delete the canary record
 	cleanupCanary(ctx, dnsClient, endpoint, zoneID, canaryName)
 
 	if !resolved {
@@ -115,13 +125,19 @@ func (p *MicroKubeProvider) runDNSSmokeTest(ctx context.Context, net *Network) {
 		storeSmokeResult(net.Name, false, msg)
 		return
 	}
+	if len(unreachable) > 0 {
+		msg := fmt.Sprintf("canary %s did not resolve through %s", fqdn, strings.Join(unreachable, ", "))
+		log.Warnw("smoketest failed", "step", "dns-mesh", "fqdn", fqdn, "peers", unreachable)
+		storeSmokeResult(net.Name, false, msg)
+		return
+	}
 
 	log.Infow("smoketest passed", "zone", zone)
 	storeSmokeResult(net.Name, true, "all checks passed")
 }
 
 // runSmokeTestSync runs a smoke test synchronously and returns the result.
-// Used by the on-demand API endpoint.
+// Used by the on-demand API endpoint; the caller holds p.mu.
 func (p *MicroKubeProvider) runSmokeTestSync(ctx context.Context, net *Network) SmokeTestResult {
 	log := p.deps.Logger.With("network", net.Name, "func", "smokeTestSync")
 
@@ -167,6 +183,11 @@ func (p *MicroKubeProvider) runSmokeTestSync(ctx context.Context, net *Network)
 
 	fqdn := canaryName + "." + zone
 	resolved := probeDNSRecord(net.Spec.DNS.Server, fqdn, canaryIP, 5*time.Second)
+	var unreachable []string
+	if resolved {
+		// the API handler holds p.mu
+		unreachable = probeDNSMesh(ctx, dnsClient, p.dnsMeshPeers(net), fqdn, canaryIP)
+	}
 	cleanupCanary(ctx, dnsClient, endpoint, zoneID, canaryName)
 
 	if !resolved {
@@ -174,6 +195,11 @@ func (p *MicroKubeProvider) runSmokeTestSync(ctx context.Context, net *Network)
 		smokeTestResults.Store(net.Name, result)
 		return result
 	}
+	if len(unreachable) > 0 {
+		result := SmokeTestResult{Pass: false, Message: fmt.Sprintf("canary %s did not resolve through %s", fqdn, strings.Join(unreachable, ", ")), Timestamp: time.Now(), Network: net.Name}
+		smokeTestResults.Store(net.Name, result)
+		return result
+	}
 
 	log.Infow("on-demand smoketest passed", "zone", zone)
 	result := SmokeTestResult{Pass: true, Message: "all checks passed", Timestamp: time.Now(), Network: net.Name}
@@ -181,6 +207,42 @@ func (p *MicroKubeProvider) runSmokeTestSync(ctx context.Context, net *Network)
 	return result
 }
 
+// dnsMeshPeer is another managed microdns the smoke test resolves through.
+type dnsMeshPeer struct {
+	network, server, endpoint string
+}
+
+// dnsMeshPeers returns the managed microdns instances of the other
+// networks, sorted by network. Caller holds p.mu.
+func (p *MicroKubeProvider) dnsMeshPeers(net *Network) []dnsMeshPeer {
+	var peers []dnsMeshPeer
+	for _, n := range p.networks {
+		if n.Name == net.Name || n.Spec.ExternalDNS || n.Spec.DNS.Zone == "" || n.Spec.DNS.Server == "" {
+			continue
+		}
+		peers = append(peers, dnsMeshPeer{n.Name, n.Spec.DNS.Server, p.networkDNSEndpoint(n)})
+	}
+	sort.Slice(peers, func(i, j int) bool { return peers[i].network < peers[j].network })
+	return peers
+}
+
+// probeDNSMesh queries every peer for the canary, which only resolves there
+// through the forwarding mesh. Peers whose REST API is down are skipped;
+// their own smoke test reports them. Returns the peers that did not
+// resolve it, as "network (server)".
+func probeDNSMesh(ctx context.Context, dnsClient *dns.Client, peers []dnsMeshPeer, fqdn, expectedIP string) []string {
+	var failed []string
+	for _, peer := range peers {
+		if err := dnsClient.HealthCheck(ctx, peer.endpoint); err != nil {
+			continue
+		}
+		if !probeDNSRecord(peer.server, fqdn, expectedIP, 5*time.Second) {
+			failed = append(failed, fmt.Sprintf("%s (%s)", peer.network, peer.server))
+		}
+	}
+	return failed
+}
+
 // cleanupCanary removes the _smoketest canary record from the zone.
 func cleanupCanary(ctx context.Context, dnsClient *dns.Client, endpoint, zoneID, canaryName string) {
 	records, err := dnsClient.ListRecords(ctx, endpoint, zoneID)
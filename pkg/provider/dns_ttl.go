@@ -0,0 +1,216 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/glennswest/mkube/pkg/config"
+)
+
+// DNS record TTLs come, most specific first, from a pod's vkube.io/dns-ttl
+// annotation, the namespace policy in namespace.dnsTTL and the network's
+// spec.dns.ttl, each falling back from the record kind to its default.
+// While a pod rolls (rolling update, blue-green update, migration) its
+// records carry the rollout TTL so clients drop the old address quickly;
+// the usual TTL is restored once it settles.
+const (
+	annotationDNSTTL = "vkube.io/dns-ttl"
+
+	// annotationDNSTTLLoweredUntil keeps a migrated pod's records at the
+	// rollout TTL on its new node until the given RFC 3339 time.
+	annotationDNSTTLLoweredUntil = "vkube.io/dns-ttl-lowered-until"
+
+	maxDNSTTL           = 604800 // one week
+	dnsTTLMigrateWindow = 5 * time.Minute
+
+	// maxDNSTTLDrain caps how long a rollout waits for answers cached with
+	// the previous TTL to expire before it touches the first pod.
+	maxDNSTTLDrain = 30 * time.Second
+)
+
+// builtinDNSTTL is the TTL of each kind of record when no policy sets one.
+var builtinDNSTTL = map[string]int{
+	config.DNSTTLPods:    60,
+	config.DNSTTLAliases: 60,
+	config.DNSTTLBMH:     60,
+	config.DNSTTLStatic:  300,
+	config.DNSTTLRollout: 5,
+}
+
+// parseDNSTTL parses a TTL in seconds.
+func parseDNSTTL(s string) (int, error) {
+	ttl, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || ttl <= 0 || ttl > maxDNSTTL {
+		return 0, fmt.Errorf("invalid DNS TTL %q: must be 1-%d seconds", s, maxDNSTTL)
+	}
+	return ttl, nil
+}
+
+// podDNSTTLAnnotation returns the TTL set by pod's vkube.io/dns-ttl
+// annotation, 0 when it has none.
+func podDNSTTLAnnotation(pod *corev1.Pod) (int, error) {
+	v, ok := pod.Annotations[annotationDNSTTL]
+	if !ok {
+		return 0, nil
+	}
+	ttl, err := parseDNSTTL(v)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", annotationDNSTTL, err)
+	}
+	return ttl, nil
+}
+
+// dnsTTL returns the TTL of a kind of record registered in networkName for
+// a Kubernetes namespace ("" for records no namespace owns).
+func (p *MicroKubeProvider) dnsTTL(kind, networkName, namespace string) int {
+	if ttl := p.deps.Config.Namespace.DNSTTL[namespace].For(kind); ttl > 0 {
+		return ttl
+	}
+	if p.deps.NetworkMgr != nil {
+		if ttl := p.deps.NetworkMgr.DNSTTL(networkName).For(kind); ttl > 0 {
+			return ttl
+		}
+	}
+	return builtinDNSTTL[kind]
+}
+
+// podDNSTTL returns the TTL of pod's records of kind (pods or aliases): its
+// annotation or policy TTL, lowered to the rollout TTL while the pod rolls.
+func (p *MicroKubeProvider) podDNSTTL(pod *corev1.Pod, kind string) int {
+	networkName := pod.Annotations[annotationNetwork]
+	ttl, err := podDNSTTLAnnotation(pod)
+	if err != nil || ttl == 0 {
+		ttl = p.dnsTTL(kind, networkName, pod.Namespace)
+	}
+	if p.podDNSLowered(pod) {
+		if rollout := p.dnsTTL(config.DNSTTLRollout, networkName, pod.Namespace); rollout < ttl {
+			ttl = rollout
+		}
+	}
+	return ttl
+}
+
+// podDNSLowered reports whether pod's records carry the rollout TTL.
+func (p *MicroKubeProvider) podDNSLowered(pod *corev1.Pod) bool {
+	p.dnsTTLMu.Lock()
+	lowered := p.dnsTTLLowered[podKey(pod)]
+	p.dnsTTLMu.Unlock()
+	if lowered {
+		return true
+	}
+	if v := pod.Annotations[annotationDNSTTLLoweredUntil]; v != "" {
+		until, err := time.Parse(time.RFC3339, v)
+		return err == nil && time.Now().Before(until)
+	}
+	return false
+}
+
+// lowerPodDNSTTL re-registers the records of the pods not already rolling
+// with the rollout TTL and returns a func restoring their usual TTL. Only
+// answers given from now on carry the lower TTL, so it then waits out the
+// previous TTL (capped at maxDNSTTLDrain) before returning; callers lower
+// before touching the first pod.
+func (p *MicroKubeProvider) lowerPodDNSTTL(ctx context.Context, pods []*corev1.Pod) (restore func()) {
+	var keys []string
+	drain := 0
+	for _, pod := range pods {
+		key := podKey(pod)
+		tracked, ok := p.pods[key]
+		if !ok {
+			tracked = pod
+		}
+		// The TTL clients may have cached, read before the flag flips
+		previous := max(p.podDNSTTL(tracked, config.DNSTTLPods), p.podDNSTTL(tracked, config.DNSTTLAliases))
+
+		p.dnsTTLMu.Lock()
+		lowered := p.dnsTTLLowered[key]
+		p.dnsTTLLowered[key] = true
+		p.dnsTTLMu.Unlock()
+		if lowered {
+			continue
+		}
+		keys = append(keys, key)
+		drain = max(drain, previous)
+		if ok {
+			p.registerPodDNS(ctx, tracked)
+		}
+	}
+	if len(keys) > 0 {
+		wait := min(time.Duration(drain)*time.Second, maxDNSTTLDrain)
+		p.deps.Logger.Infow("DNS TTL lowered for rollout", "pods", keys, "drain", wait)
+		p.dnsTTLDrain(ctx, wait)
+	}
+	return func() {
+		for _, key := range keys {
+			p.dnsTTLMu.Lock()
+			delete(p.dnsTTLLowered, key)
+			p.dnsTTLMu.Unlock()
+			if tracked, ok := p.pods[key]; ok {
+				p.registerPodDNS(ctx, tracked)
+			}
+		}
+		if len(keys) > 0 {
+			p.deps.Logger.Infow("DNS TTL restored after rollout", "pods", keys)
+		}
+	}
+}
+
+// sleepCtx waits for d or until ctx is done.
+func sleepCtx(ctx context.Context, d time.Duration) {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+	case <-t.C:
+	}
+}
+
+// dnsTTLTOML returns the recursor settings of a network's TTL policy and
+// the TTL microdns gives names it registers from DHCP leases.
+func dnsTTLTOML(policy config.DNSTTLConfig) (recursor string, dhcpTTL int) {
+	if policy.Negative > 0 {
+		recursor = fmt.Sprintf("negative_ttl = %d\n", policy.Negative)
+	}
+	dhcpTTL = 300
+	if policy.Default > 0 {
+		dhcpTTL = policy.Default
+	}
+	return recursor, dhcpTTL
+}
+
+// config converts the policy to its config form; a nil policy sets nothing.
+func (t *DNSTTLPolicy) config() config.DNSTTLConfig {
+	if t == nil {
+		return config.DNSTTLConfig{}
+	}
+	return config.DNSTTLConfig{
+		Default:  t.Default,
+		Pods:     t.Pods,
+		Aliases:  t.Aliases,
+		BMH:      t.BMH,
+		Static:   t.Static,
+		Rollout:  t.Rollout,
+		Negative: t.Negative,
+	}
+}
+
+// dnsTTLPolicy converts a config policy to its CRD form, nil when empty.
+func dnsTTLPolicy(c config.DNSTTLConfig) *DNSTTLPolicy {
+	if c == (config.DNSTTLConfig{}) {
+		return nil
+	}
+	return &DNSTTLPolicy{
+		Default:  c.Default,
+		Pods:     c.Pods,
+		Aliases:  c.Aliases,
+		BMH:      c.BMH,
+		Static:   c.Static,
+		Rollout:  c.Rollout,
+		Negative: c.Negative,
+	}
+}
@@ -0,0 +1,155 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/glennswest/mkube/pkg/config"
+)
+
+// ttlOf returns the TTL of name's A record in zone z1, -1 if there is none.
+func (z *zoneServer) ttlOf(name string) int {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	for _, rec := range z.records["z1"] {
+		if rec["name"] == name && rec["type"] == "A" {
+			ttl, _ := rec["ttl"].(float64)
+			return int(ttl)
+		}
+	}
+	return -1
+}
+
+func TestPodDNSTTL(t *testing.T) {
+	p, _ := newTestProvider(t)
+	ctx := context.Background()
+
+	zs, _ := newZoneServer(t, p)
+	p.deps.NetworkMgr.SetDNSTTL("containers", config.DNSTTLConfig{Default: 120, Rollout: 10})
+	p.deps.Config.Namespace.DNSTTL = map[string]config.DNSTTLConfig{
+		"default": {Aliases: 45},
+	}
+
+	web := testPod("web", "app")
+	job := testPod("job", "run")
+	job.Annotations[annotationDNSTTL] = "15"
+	for _, pod := range []*corev1.Pod{web, job} {
+		if err := p.CreatePod(ctx, pod); err != nil {
+			t.Fatalf("CreatePod %s: %v", pod.Name, err)
+		}
+	}
+	p.reregisterPodDNS(ctx)
+
+	// Network default for container records, namespace policy for aliases,
+	// the annotation over both
+	for name, want := range map[string]int{"app.web": 120, "web": 45, "run.job": 15, "job": 15} {
+		if got := zs.ttlOf(name); got != want {
+			t.Errorf("TTL of %s = %d, want %d", name, got, want)
+		}
+	}
+
+	// A rollout lowers the pod's records, waits out the previous TTL
+	// (capped) and restores them afterwards
+	var drained []time.Duration
+	p.dnsTTLDrain = func(_ context.Context, d time.Duration) { drained = append(drained, d) }
+	restore := p.lowerPodDNSTTL(ctx, []*corev1.Pod{web})
+	if got, want := zs.ttlOf("app.web")+zs.ttlOf("web"), 20; got != want {
+		t.Errorf("TTLs during rollout sum to %d, want %d", got, want)
+	}
+	p.lowerPodDNSTTL(ctx, []*corev1.Pod{web})() // already lowered: a nested restore is a no-op
+	if got := zs.ttlOf("web"); got != 10 {
+		t.Errorf("TTL of web after nested restore = %d, want 10", got)
+	}
+	if len(drained) != 1 || drained[0] != maxDNSTTLDrain {
+		t.Errorf("drained %v, want one wait of %v", drained, maxDNSTTLDrain)
+	}
+	restore()
+	if got := zs.ttlOf("app.web"); got != 120 {
+		t.Errorf("TTL of app.web after rollout = %d, want 120", got)
+	}
+
+	// A migrated pod stays lowered until its window closes
+	web.Annotations[annotationDNSTTLLoweredUntil] = time.Now().Add(time.Minute).UTC().Format(time.RFC3339)
+	if got := p.podDNSTTL(web, config.DNSTTLPods); got != 10 {
+		t.Errorf("TTL while migrating = %d, want 10", got)
+	}
+	web.Annotations[annotationDNSTTLLoweredUntil] = time.Now().Add(-time.Minute).UTC().Format(time.RFC3339)
+	if got := p.podDNSTTL(web, config.DNSTTLPods); got != 120 {
+		t.Errorf("TTL after migration window = %d, want 120", got)
+	}
+
+	// Records no policy covers keep their built-in TTL
+	p.deps.NetworkMgr.SetDNSTTL("containers", config.DNSTTLConfig{})
+	if got := p.dnsTTL(config.DNSTTLStatic, "containers", ""); got != 300 {
+		t.Errorf("static TTL = %d, want 300", got)
+	}
+
+	// Malformed annotations are rejected at create time
+	mux := http.NewServeMux()
+	p.RegisterRoutes(mux)
+	rec := httptest.NewRecorder()
+	p.WrapHandler(mux).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/namespaces/default/pods",
+		strings.NewReader(`{"metadata":{"name":"bad","annotations":{"vkube.io/dns-ttl":"0"}},"spec":{"containers":[{"name":"c","image":"x"}]}}`)))
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("malformed annotation: got %d, want 422", rec.Code)
+	}
+}
+
+func TestUpdatePodDrainsDNSTTLFirst(t *testing.T) {
+	p, rt := newTestProvider(t)
+	ctx := context.Background()
+
+	zs, _ := newZoneServer(t, p)
+	p.deps.NetworkMgr.SetDNSTTL("containers", config.DNSTTLConfig{Default: 20, Rollout: 5})
+
+	pod := testPod("web", "app")
+	if err := p.CreatePod(ctx, pod); err != nil {
+		t.Fatalf("CreatePod: %v", err)
+	}
+	p.reregisterPodDNS(ctx)
+	name := sanitizeName(pod, "app")
+
+	// The wait must come after the records are lowered and before the
+	// update touches any container
+	var drained []time.Duration
+	p.dnsTTLDrain = func(_ context.Context, d time.Duration) {
+		drained = append(drained, d)
+		if got := zs.ttlOf("app.web"); got != 5 {
+			t.Errorf("TTL of app.web while draining = %d, want 5", got)
+		}
+		rt.mu.Lock()
+		defer rt.mu.Unlock()
+		if len(rt.containers) != 1 || rt.containers[name] == nil || rt.containers[name].Image != "/dev/null" {
+			t.Errorf("containers touched before the drain: %v", rt.containers)
+		}
+	}
+
+	updated := pod.DeepCopy()
+	updated.Annotations["vkube.io/file"] = "/tmp/new-image.tar"
+	if err := p.UpdatePod(ctx, updated); err != nil {
+		t.Fatalf("UpdatePod: %v", err)
+	}
+	if len(drained) != 1 || drained[0] != 20*time.Second {
+		t.Errorf("drained %v, want one wait of 20s", drained)
+	}
+	if got := zs.ttlOf("app.web"); got != 20 {
+		t.Errorf("TTL of app.web after update = %d, want 20", got)
+	}
+}
+
+func TestDNSTTLTOML(t *testing.T) {
+	recursor, dhcpTTL := dnsTTLTOML(config.DNSTTLConfig{})
+	if recursor != "" || dhcpTTL != 300 {
+		t.Errorf("empty policy: %q, %d", recursor, dhcpTTL)
+	}
+	recursor, dhcpTTL = dnsTTLTOML(config.DNSTTLConfig{Default: 30, Negative: 5})
+	if recursor != "negative_ttl = 5\n" || dhcpTTL != 30 {
+		t.Errorf("policy: %q, %d", recursor, dhcpTTL)
+	}
+}
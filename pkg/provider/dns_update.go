@@ -0,0 +1,278 @@
+package provider
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/glennswest/mkube/pkg/config"
+	"github.com/glennswest/mkube/pkg/dns"
+)
+
+// ─── Dynamic DNS Updates (RFC 2136) ─────────────────────────────────────────
+
+// maxUpdateEventChanges bounds the changes listed in one DNSUpdate event.
+const maxUpdateEventChanges = 5
+
+// rejectLogInterval bounds how often updates refused before authentication
+// are logged.
+const rejectLogInterval = time.Minute
+
+// dnsUpdatePolicy is what updates signed with one TSIG key may change.
+type dnsUpdatePolicy struct {
+	zones map[string]bool
+	types map[string]bool // empty allows every type
+}
+
+// dnsUpdateHandler applies authenticated dynamic updates to the microdns
+// instance of the network owning the zone, through the same record
+// operations the REST proxy uses.
+type dnsUpdateHandler struct {
+	p        *MicroKubeProvider
+	policies map[string]dnsUpdatePolicy // key name -> policy
+
+	mu sync.Mutex // one update at a time, so prerequisites hold while applying
+
+	rejectMu         sync.Mutex
+	rejectLogged     time.Time // last unauthenticated rejection logged
+	rejectSuppressed int       // unauthenticated rejections not logged since
+}
+
+// newDNSUpdateHandler decodes the configured keys. Keys with a bad secret
+// or an unsupported algorithm are skipped with a warning.
+func newDNSUpdateHandler(p *MicroKubeProvider, cfg config.DNSUpdateConfig) ([]dns.TSIGKey, *dnsUpdateHandler) {
+	h := &dnsUpdateHandler{p: p, policies: make(map[string]dnsUpdatePolicy)}
+	var keys []dns.TSIGKey
+	for _, k := range cfg.Keys {
+		name := strings.ToLower(strings.TrimSuffix(k.Name, "."))
+		alg, ok := dns.TSIGAlgorithm(k.Algorithm)
+		if !ok {
+			p.deps.Logger.Warnw("skipping TSIG key with unsupported algorithm", "key", name, "algorithm", k.Algorithm)
+			continue
+		}
+		secret, err := base64.StdEncoding.DecodeString(k.Secret)
+		if err != nil || name == "" || len(secret) == 0 {
+			p.deps.Logger.Warnw("skipping TSIG key without a valid name and base64 secret", "key", name)
+			continue
+		}
+		pol := dnsUpdatePolicy{zones: make(map[string]bool), types: make(map[string]bool)}
+		for _, z := range k.Zones {
+			pol.zones[strings.ToLower(strings.TrimSuffix(z, "."))] = true
+		}
+		for _, t := range k.Types {
+			pol.types[strings.ToUpper(t)] = true
+		}
+		h.policies[name] = pol
+		keys = append(keys, dns.TSIGKey{Name: name, Algorithm: alg, Secret: secret})
+	}
+	return keys, h
+}
+
+// StartDNSUpdateServer starts the RFC 2136 listener when keys are
+// configured, so hosts outside mkube (laptops, VMs on other hypervisors,
+// ACME clients publishing TXT challenges) can register names with nsupdate.
+func (p *MicroKubeProvider) StartDNSUpdateServer() {
+	cfg := p.deps.Config.DNSUpdate
+	if len(cfg.Keys) == 0 {
+		return
+	}
+	keys, h := newDNSUpdateHandler(p, cfg)
+	if len(keys) == 0 {
+		return
+	}
+	srv := dns.NewUpdateServer(cfg.Listen, keys, h, p.deps.Logger)
+	if err := srv.Start(); err != nil {
+		p.deps.Logger.Warnw("DNS update server not started", "error", err)
+		return
+	}
+	p.dnsUpdates = srv
+}
+
+// deniedType returns the first update record type the policy does not
+// allow; deleting every RRset of a name needs an unrestricted key.
+func (pol dnsUpdatePolicy) deniedType(m *dns.UpdateMessage) string {
+	if len(pol.types) == 0 {
+		return ""
+	}
+	for _, u := range m.Updates {
+		if !pol.types[u.Type] {
+			return u.Type
+		}
+	}
+	return ""
+}
+
+// zoneNetwork returns the network serving zone and its microdns endpoint.
+// Caller holds p.mu.
+func (p *MicroKubeProvider) zoneNetwork(zone string) (*Network, string) {
+	names := make([]string, 0, len(p.networks))
+	for name := range p.networks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		n := p.networks[name]
+		if strings.EqualFold(n.Spec.DNS.Zone, zone) {
+			return n, p.networkDNSEndpoint(n)
+		}
+	}
+	return nil, ""
+}
+
+func (h *dnsUpdateHandler) ApplyUpdate(ctx context.Context, req *dns.UpdateRequest) dns.RCode {
+	p, m := h.p, req.Msg
+	pol := h.policies[req.Key]
+	if !pol.zones[m.Zone] {
+		h.refuse(req, fmt.Sprintf("key %q may not update zone %s", req.Key, m.Zone))
+		return dns.RCodeRefused
+	}
+	if t := pol.deniedType(m); t != "" {
+		h.refuse(req, fmt.Sprintf("key %q may not change %s records", req.Key, t))
+		return dns.RCodeRefused
+	}
+
+	p.mu.Lock()
+	net, endpoint := p.zoneNetwork(m.Zone)
+	p.mu.Unlock()
+	if net == nil || endpoint == "" || p.deps.NetworkMgr == nil {
+		h.refuse(req, fmt.Sprintf("zone %s is not served by a managed network", m.Zone))
+		return dns.RCodeNotAuth
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	client := p.deps.NetworkMgr.DNSClient()
+	zoneID, err := client.EnsureZone(ctx, endpoint, m.Zone)
+	if err != nil {
+		h.failed(req, net.Name, fmt.Sprintf("resolving zone: %v", err))
+		return dns.RCodeServFail
+	}
+	have, err := client.ListFullRecords(ctx, endpoint, zoneID)
+	if err != nil {
+		h.failed(req, net.Name, fmt.Sprintf("listing records: %v", err))
+		return dns.RCodeServFail
+	}
+
+	diff, rcode := dns.PlanUpdate(m.Zone, have, m)
+	if rcode != dns.RCodeSuccess {
+		p.mu.Lock()
+		p.recordNetworkEvent(net.Name, "DNSUpdateRejected", fmt.Sprintf("Dynamic update of %s by key %q from %s: %s",
+			m.Zone, req.Key, req.Client, dns.RCodeName(rcode)), corev1.EventTypeNormal)
+		p.mu.Unlock()
+		return rcode
+	}
+
+	var changes, errs []string
+	addr := false
+	note := func(prefix string, r dns.FullRecord) {
+		changes = append(changes, fmt.Sprintf("%s%s %s %s", prefix, ownerOrApex(r.Name), r.Type, r.Data.Text()))
+		addr = addr || r.Type == "A" || r.Type == "AAAA"
+	}
+	for _, r := range diff.Delete {
+		if err := client.DeleteRecord(ctx, endpoint, zoneID, r.ID); err != nil {
+			errs = append(errs, fmt.Sprintf("deleting %s %s: %v", r.Name, r.Type, err))
+			continue
+		}
+		note("-", r)
+	}
+	for _, u := range diff.Update {
+		if _, err := client.UpdateFullRecord(ctx, endpoint, zoneID, u.Old.ID, map[string]interface{}{
+			"ttl":  u.New.TTL,
+			"data": u.New.Data,
+		}); err != nil {
+			errs = append(errs, fmt.Sprintf("updating %s %s: %v", u.Old.Name, u.Old.Type, err))
+			continue
+		}
+		note("~", u.New)
+	}
+	for _, r := range diff.Create {
+		if _, err := client.CreateFullRecord(ctx, endpoint, zoneID, map[string]interface{}{
+			"name":    r.Name,
+			"ttl":     r.TTL,
+			"data":    r.Data,
+			"enabled": true,
+		}); err != nil {
+			errs = append(errs, fmt.Sprintf("creating %s %s: %v", r.Name, r.Type, err))
+			continue
+		}
+		note("+", r)
+	}
+	if addr {
+		if err := client.SyncReverseRecords(ctx, endpoint, zoneID); err != nil {
+			errs = append(errs, fmt.Sprintf("syncing PTR records: %v", err))
+		}
+	}
+
+	summary := strings.Join(changes, ", ")
+	if len(changes) > maxUpdateEventChanges {
+		summary = strings.Join(changes[:maxUpdateEventChanges], ", ") + fmt.Sprintf(" and %d more", len(changes)-maxUpdateEventChanges)
+	}
+	if summary == "" {
+		summary = "no changes"
+	}
+	msg := fmt.Sprintf("Dynamic update of %s by key %q from %s: %s", m.Zone, req.Key, req.Client, summary)
+	p.deps.Logger.Infow("DNS dynamic update applied", "zone", m.Zone, "key", req.Key, "client", req.Client,
+		"created", len(diff.Create), "updated", len(diff.Update), "deleted", len(diff.Delete), "errors", len(errs))
+	if len(errs) > 0 {
+		h.failed(req, net.Name, msg+"; failed: "+strings.Join(errs, "; "))
+		return dns.RCodeServFail
+	}
+	p.mu.Lock()
+	p.recordNetworkEvent(net.Name, "DNSUpdate", msg, corev1.EventTypeNormal)
+	p.mu.Unlock()
+	return dns.RCodeSuccess
+}
+
+// RejectUpdate hears about updates the server refused before they were
+// authenticated: unsigned, signed with an unknown key or with a bad
+// signature. Anyone who can reach the port can send those, so they are
+// only logged, at most once per rejectLogInterval with a count of the ones
+// suppressed since, and never take the provider lock or record events.
+func (h *dnsUpdateHandler) RejectUpdate(req *dns.UpdateRequest, reason string) {
+	h.rejectMu.Lock()
+	now := time.Now()
+	if now.Sub(h.rejectLogged) < rejectLogInterval {
+		h.rejectSuppressed++
+		h.rejectMu.Unlock()
+		return
+	}
+	suppressed := h.rejectSuppressed
+	h.rejectLogged, h.rejectSuppressed = now, 0
+	h.rejectMu.Unlock()
+	h.p.deps.Logger.Warnw("DNS dynamic update rejected", "zone", req.Msg.Zone, "key", req.Key, "client", req.Client,
+		"reason", reason, "suppressed", suppressed)
+}
+
+// refuse logs an authenticated update its key may not make and records it
+// on the zone's network, when there is one.
+func (h *dnsUpdateHandler) refuse(req *dns.UpdateRequest, reason string) {
+	p := h.p
+	p.deps.Logger.Warnw("DNS dynamic update refused", "zone", req.Msg.Zone, "key", req.Key, "client", req.Client, "reason", reason)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if net, _ := p.zoneNetwork(req.Msg.Zone); net != nil {
+		p.recordNetworkEvent(net.Name, "DNSUpdateRejected", fmt.Sprintf("Dynamic update of %s from %s rejected: %s",
+			req.Msg.Zone, req.Client, reason), corev1.EventTypeWarning)
+	}
+}
+
+func (h *dnsUpdateHandler) failed(req *dns.UpdateRequest, network, message string) {
+	h.p.deps.Logger.Warnw("DNS dynamic update failed", "zone", req.Msg.Zone, "key", req.Key, "error", message)
+	h.p.mu.Lock()
+	defer h.p.mu.Unlock()
+	h.p.recordNetworkEvent(network, "DNSUpdateFailed", message, corev1.EventTypeWarning)
+}
+
+func ownerOrApex(name string) string {
+	if name == "" {
+		return "@"
+	}
+	return name
+}
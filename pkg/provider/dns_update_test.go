@@ -0,0 +1,109 @@
+package provider
+
+import (
+	"encoding/base64"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/glennswest/mkube/pkg/config"
+	"github.com/glennswest/mkube/pkg/dns"
+)
+
+func TestDNSUpdate(t *testing.T) {
+	p, _ := newTestProvider(t)
+	zs, endpoint := newZoneServer(t, p)
+	p.networks["containers"] = &Network{
+		ObjectMeta: metav1.ObjectMeta{Name: "containers"},
+		Spec:       NetworkSpec{CIDR: "172.20.0.0/24", DNS: NetworkDNSSpec{Zone: "gt.lo", Endpoint: endpoint}},
+	}
+
+	secret := []byte("0123456789abcdef0123456789abcdef")
+	keys, h := newDNSUpdateHandler(p, config.DNSUpdateConfig{Keys: []config.TSIGKeyConfig{
+		{Name: "hosts", Secret: base64.StdEncoding.EncodeToString(secret), Zones: []string{"gt.lo."}},
+		{Name: "acme", Secret: base64.StdEncoding.EncodeToString(secret), Zones: []string{"gt.lo"}, Types: []string{"TXT"}},
+		{Name: "broken", Secret: "not base64!", Zones: []string{"gt.lo"}},
+	}})
+	if len(keys) != 2 {
+		t.Fatalf("keys = %+v", keys)
+	}
+	srv := dns.NewUpdateServer("127.0.0.1:0", keys, h, p.deps.Logger)
+
+	send := func(key string, updates ...dnsmessage.Resource) dnsmessage.RCode {
+		t.Helper()
+		m := dnsmessage.Message{
+			Header:      dnsmessage.Header{ID: 7, OpCode: 5},
+			Questions:   []dnsmessage.Question{{Name: dnsmessage.MustNewName("gt.lo."), Type: dnsmessage.TypeSOA, Class: dnsmessage.ClassINET}},
+			Authorities: updates,
+		}
+		msg, err := m.Pack()
+		if err != nil {
+			t.Fatalf("Pack: %v", err)
+		}
+		msg = dns.SignTSIG(msg, dns.TSIGKey{Name: key, Secret: secret}, time.Now())
+		var parser dnsmessage.Parser
+		hdr, err := parser.Start(srv.Handle(msg, "192.0.2.10:40000"))
+		if err != nil {
+			t.Fatalf("response: %v", err)
+		}
+		return hdr.RCode
+	}
+	header := func(name string, class dnsmessage.Class, ttl uint32) dnsmessage.ResourceHeader {
+		return dnsmessage.ResourceHeader{Name: dnsmessage.MustNewName(name), Class: class, TTL: ttl}
+	}
+	var a dnsmessage.AResource
+	copy(a.A[:], net.IPv4(172, 20, 0, 77).To4())
+	host := dnsmessage.Resource{Header: header("laptop.gt.lo.", dnsmessage.ClassINET, 120), Body: &a}
+	txt := dnsmessage.Resource{Header: header("_acme-challenge.gt.lo.", dnsmessage.ClassINET, 60), Body: &dnsmessage.TXTResource{TXT: []string{"tok"}}}
+
+	if rc := send("hosts", host); rc != dns.RCodeSuccess {
+		t.Fatalf("add host: %s", dns.RCodeName(rc))
+	}
+	if got := strings.Join(zs.list("A"), ","); !strings.Contains(got, "laptop 172.20.0.77") {
+		t.Errorf("A records = %v", got)
+	}
+
+	// The ACME key may only touch TXT records
+	if rc := send("acme", host); rc != dns.RCodeRefused {
+		t.Errorf("acme key adding A: %s, want REFUSED", dns.RCodeName(rc))
+	}
+	if rc := send("acme", txt); rc != dns.RCodeSuccess {
+		t.Fatalf("acme TXT: %s", dns.RCodeName(rc))
+	}
+	if got := zs.list("TXT"); len(got) != 1 || got[0] != "_acme-challenge tok" {
+		t.Errorf("TXT records = %v", got)
+	}
+
+	// Deleting the host's RRset
+	del := dnsmessage.Resource{Header: header("laptop.gt.lo.", dnsmessage.ClassANY, 0), Body: &dnsmessage.UnknownResource{Type: dnsmessage.TypeA}}
+	if rc := send("hosts", del); rc != dns.RCodeSuccess {
+		t.Fatalf("delete host: %s", dns.RCodeName(rc))
+	}
+	if got := strings.Join(zs.list("A"), ","); strings.Contains(got, "laptop") {
+		t.Errorf("A records after delete = %v", got)
+	}
+
+	// Updates refused before authentication are logged, rate-limited, and
+	// never recorded as events
+	for i := 0; i < 3; i++ {
+		req := &dns.UpdateRequest{Msg: &dns.UpdateMessage{Zone: "gt.lo"}, Client: "198.51.100.9:53"}
+		h.RejectUpdate(req, "update is not signed")
+	}
+	if h.rejectSuppressed != 2 {
+		t.Errorf("suppressed rejections = %d, want 2", h.rejectSuppressed)
+	}
+
+	var reasons []string
+	for _, e := range p.events {
+		if e.InvolvedObject.Name == "containers" {
+			reasons = append(reasons, e.Reason)
+		}
+	}
+	if strings.Join(reasons, ",") != "DNSUpdate,DNSUpdateRejected,DNSUpdate,DNSUpdate" {
+		t.Errorf("events = %v", reasons)
+	}
+}
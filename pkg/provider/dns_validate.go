@@ -168,6 +168,9 @@ func (p *MicroKubeProvider) runDNSValidation(ctx context.Context) DNSValidationR
 		}
 		expectedRecords := p.buildExpectedDNSRecords(pods, netName)
 		for hostname, expected := range expectedRecords {
+			if expected.ip == "" {
+				continue // SRV/TXT only; checkDNS covers those
+			}
 			checkName := fmt.Sprintf("zone/%s/pod/%s", netName, hostname)
 			ips, exists := actualRecords[hostname]
 			if !exists {
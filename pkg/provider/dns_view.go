@@ -0,0 +1,494 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/glennswest/mkube/pkg/config"
+	"github.com/glennswest/mkube/pkg/dns"
+)
+
+// ─── Split-Horizon DNS Views ────────────────────────────────────────────────
+
+// defaultViewTTL applies to view records without a TTL.
+const defaultViewTTL = 300
+
+var dnsViewNameRe = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?$`)
+
+// namespaceDNSViews are the views of a namespace zone served by its
+// network's microdns, from namespace.dnsViews.
+type namespaceDNSViews struct {
+	Namespace string
+	Zone      string
+	Views     []DNSView
+}
+
+// namespaceDNSViews returns every namespace on net with a zone of its own,
+// sorted by name, with its configured views. Namespaces without views are
+// included so that syncDNSViews removes the view zones they used to have.
+func (p *MicroKubeProvider) namespaceDNSViews(net *Network) []namespaceDNSViews {
+	if p.deps.Namespace == nil {
+		return nil
+	}
+	var out []namespaceDNSViews
+	for _, ns := range p.deps.Namespace.ListNamespaces() {
+		if ns.Network != net.Name || ns.Zone == "" || strings.EqualFold(ns.Zone, net.Spec.DNS.Zone) {
+			continue
+		}
+		out = append(out, namespaceDNSViews{
+			Namespace: ns.Name,
+			Zone:      ns.Zone,
+			Views:     dnsViewsFromConfig(p.deps.Config.Namespace.DNSViews[ns.Name]),
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Namespace < out[j].Namespace })
+	return out
+}
+
+// dnsViewsFromConfig converts namespace.dnsViews entries to DNSViews.
+func dnsViewsFromConfig(defs []config.DNSViewConfig) []DNSView {
+	views := make([]DNSView, 0, len(defs))
+	for _, d := range defs {
+		v := DNSView{Name: d.Name, Clients: append([]string(nil), d.Clients...)}
+		for _, r := range d.Records {
+			v.Records = append(v.Records, DNSViewRecord{Name: r.Name, Type: r.Type, Data: r.Data, TTL: r.TTL})
+		}
+		views = append(views, v)
+	}
+	return views
+}
+
+// dnsViewsTOML renders the views of a network's microdns as
+// [[dns.auth.views]] tables: the network's own, then those of its
+// namespace zones under names the network does not use. microdns matches a
+// query's source address against them in order; the overrides themselves
+// are pushed by syncDNSViews.
+func (p *MicroKubeProvider) dnsViewsTOML(net *Network) string {
+	var b strings.Builder
+	seen := make(map[string]bool)
+	render := func(views []DNSView) {
+		for _, v := range views {
+			if seen[v.Name] {
+				continue
+			}
+			seen[v.Name] = true
+			clients := make([]string, len(v.Clients))
+			for i, c := range v.Clients {
+				clients[i] = strconv.Quote(c)
+			}
+			fmt.Fprintf(&b, "\n[[dns.auth.views]]\nname = %q\nmatch_clients = [%s]\n", v.Name, strings.Join(clients, ", "))
+		}
+	}
+	render(net.Spec.DNS.Views)
+	for _, nv := range p.namespaceDNSViews(net) {
+		render(nv.Views)
+	}
+	return b.String()
+}
+
+// dnsRecords returns the view's overrides as microdns records.
+func (v DNSView) dnsRecords() []dns.Record {
+	out := make([]dns.Record, 0, len(v.Records))
+	for _, r := range v.Records {
+		rtype := strings.ToUpper(r.Type)
+		if rtype == "" {
+			rtype = "A"
+		}
+		ttl := r.TTL
+		if ttl == 0 {
+			ttl = defaultViewTTL
+		}
+		out = append(out, dns.Record{
+			Name: r.Name,
+			Type: rtype,
+			TTL:  ttl,
+			Data: dns.RecordData{Type: rtype, Data: r.Data},
+		})
+	}
+	return out
+}
+
+// dnsView returns the named view of a network.
+func (n *Network) dnsView(name string) (DNSView, bool) {
+	for _, v := range n.Spec.DNS.Views {
+		if v.Name == name {
+			return v, true
+		}
+	}
+	return DNSView{}, false
+}
+
+// syncDNSViews makes the network's microdns hold one view zone per
+// spec.dns.views entry with exactly its overrides, and deletes view zones
+// of views no longer in the spec. The views of the network's namespace
+// zones are synced the same way; a namespace whose views are invalid, or
+// whose zone another microdns serves, is skipped.
+func (p *MicroKubeProvider) syncDNSViews(ctx context.Context, client *dns.Client, endpoint string, net *Network) error {
+	errs := []error{p.syncViewZones(ctx, client, endpoint, net.Spec.DNS.Zone, net.Spec.DNS.Views)}
+
+	nsViews := p.namespaceDNSViews(net)
+	for _, nv := range nsViews {
+		if len(nv.Views) > 0 {
+			if verrs := namespaceViewErrors(net, nsViews, nv); len(verrs) > 0 {
+				errs = append(errs, fmt.Errorf("namespace %s: %s", nv.Namespace, strings.Join(verrs, "; ")))
+				continue
+			}
+			if !p.namespaceZoneServedBy(nv, endpoint) {
+				errs = append(errs, fmt.Errorf("namespace %s: zone %s is not served by the microdns of network %s", nv.Namespace, nv.Zone, net.Name))
+				continue
+			}
+		}
+		if err := p.syncViewZones(ctx, client, endpoint, nv.Zone, nv.Views); err != nil {
+			errs = append(errs, fmt.Errorf("namespace %s: %w", nv.Namespace, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// namespaceZoneServedBy reports whether the namespace zone of nv is on the
+// microdns at endpoint rather than a dedicated instance.
+func (p *MicroKubeProvider) namespaceZoneServedBy(nv namespaceDNSViews, endpoint string) bool {
+	ep, _, err := p.deps.Namespace.ResolveNamespace(nv.Namespace)
+	return err == nil && strings.TrimSuffix(ep, "/") == strings.TrimSuffix(endpoint, "/")
+}
+
+// syncViewZones makes endpoint hold exactly views as view zones of zone.
+func (p *MicroKubeProvider) syncViewZones(ctx context.Context, client *dns.Client, endpoint, zone string, views []DNSView) error {
+	stale, err := client.ViewZones(ctx, endpoint, zone)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, v := range views {
+		delete(stale, v.Name)
+		zoneID, err := client.EnsureViewZone(ctx, endpoint, zone, v.Name)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("view %s: %w", v.Name, err))
+			continue
+		}
+		created, deleted, err := client.SyncViewRecords(ctx, endpoint, zoneID, v.dnsRecords())
+		if err != nil {
+			errs = append(errs, fmt.Errorf("view %s: %w", v.Name, err))
+			continue
+		}
+		if created+deleted > 0 {
+			p.deps.Logger.Infow("DNS view synced", "zone", zone, "view", v.Name,
+				"created", created, "deleted", deleted)
+		}
+	}
+
+	for view, zoneID := range stale {
+		if err := client.DeleteZone(ctx, endpoint, zoneID); err != nil {
+			errs = append(errs, fmt.Errorf("removing view %s: %w", view, err))
+			continue
+		}
+		p.deps.Logger.Infow("DNS view removed", "zone", zone, "view", view)
+	}
+	return errors.Join(errs...)
+}
+
+// resolveViewZoneID resolves the view zone behind ?view= on the record API.
+// The view must be one of the network's spec.dns.views.
+func (p *MicroKubeProvider) resolveViewZoneID(w http.ResponseWriter, r *http.Request, endpoint string, net *Network, view string) (string, bool) {
+	if _, ok := net.dnsView(view); !ok {
+		http.Error(w, fmt.Sprintf("network %q has no DNS view %q", net.Name, view), http.StatusNotFound)
+		return "", false
+	}
+	zoneID, err := p.deps.NetworkMgr.DNSClient().EnsureViewZone(r.Context(), endpoint, net.Spec.DNS.Zone, view)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("resolving view %q of zone %q: %v", view, net.Spec.DNS.Zone, err), http.StatusBadGateway)
+		return "", false
+	}
+	return zoneID, true
+}
+
+// resolveRecordZoneID resolves the zone the record API reads: the view
+// zone for ?view=, the network's zone otherwise.
+func (p *MicroKubeProvider) resolveRecordZoneID(w http.ResponseWriter, r *http.Request, endpoint string, net *Network) (string, bool) {
+	if view := r.URL.Query().Get("view"); view != "" {
+		return p.resolveViewZoneID(w, r, endpoint, net, view)
+	}
+	return p.resolveZoneID(w, r, endpoint, net)
+}
+
+// rejectViewWrite refuses record writes addressed to a view: view zones
+// are rebuilt from spec.dns.views, so a direct write would not stick.
+func rejectViewWrite(w http.ResponseWriter, r *http.Request) bool {
+	if r.URL.Query().Get("view") == "" {
+		return false
+	}
+	http.Error(w, "DNS view records are managed through spec.dns.views of the Network", http.StatusBadRequest)
+	return true
+}
+
+// validateDNSViews checks view names, client CIDRs and override records.
+func validateDNSViews(n *Network) []string {
+	views := n.Spec.DNS.Views
+	if len(views) == 0 {
+		return nil
+	}
+	if n.Spec.DNS.Zone == "" {
+		return []string{"spec.dns.views: views require spec.dns.zone"}
+	}
+	return validateViews("spec.dns.views", views)
+}
+
+// namespaceViewErrors validates the views of nv as validateDNSViews does
+// for a Network. A view name the network, or a namespace sorted before nv,
+// also uses must match the same clients: microdns matches clients to view
+// names once per instance.
+func namespaceViewErrors(net *Network, all []namespaceDNSViews, nv namespaceDNSViews) []string {
+	field := "namespace.dnsViews." + nv.Namespace
+	errs := validateViews(field, nv.Views)
+
+	owner := make(map[string]string)   // view name -> first user
+	clients := make(map[string]string) // view name -> its clients
+	claim := func(who string, views []DNSView) {
+		for _, v := range views {
+			if _, ok := owner[v.Name]; !ok {
+				owner[v.Name], clients[v.Name] = who, strings.Join(v.Clients, ",")
+			}
+		}
+	}
+	claim(fmt.Sprintf("network %q", net.Name), net.Spec.DNS.Views)
+	for _, other := range all {
+		if other.Namespace == nv.Namespace {
+			break
+		}
+		claim(fmt.Sprintf("namespace %q", other.Namespace), other.Views)
+	}
+	for i, v := range nv.Views {
+		if c, ok := clients[v.Name]; ok && c != strings.Join(v.Clients, ",") {
+			errs = append(errs, fmt.Sprintf("%s[%d].clients: %s has a view %q with other clients", field, i, owner[v.Name], v.Name))
+		}
+	}
+	return errs
+}
+
+// validateNetworkViewClients checks that the network's views match the same
+// clients as views of the same name in its namespace zones.
+func (p *MicroKubeProvider) validateNetworkViewClients(n *Network) []string {
+	var errs []string
+	nsViews := p.namespaceDNSViews(n)
+	for i, v := range n.Spec.DNS.Views {
+		for _, nv := range nsViews {
+			for _, w := range nv.Views {
+				if w.Name == v.Name && strings.Join(w.Clients, ",") != strings.Join(v.Clients, ",") {
+					errs = append(errs, fmt.Sprintf("spec.dns.views[%d].clients: namespace %q has a view %q with other clients", i, nv.Namespace, v.Name))
+				}
+			}
+		}
+	}
+	return errs
+}
+
+// validateViews checks the views listed at field.
+func validateViews(field string, views []DNSView) []string {
+	var errs []string
+	seen := make(map[string]bool)
+	for i, v := range views {
+		field := fmt.Sprintf("%s[%d]", field, i)
+		switch {
+		case !dnsViewNameRe.MatchString(v.Name):
+			errs = append(errs, fmt.Sprintf("%s.name: %q is not a valid view name", field, v.Name))
+		case seen[v.Name]:
+			errs = append(errs, fmt.Sprintf("%s.name: duplicate view %q", field, v.Name))
+		}
+		seen[v.Name] = true
+
+		if len(v.Clients) == 0 {
+			errs = append(errs, fmt.Sprintf("%s.clients: at least one source CIDR is required", field))
+		}
+		for j, c := range v.Clients {
+			if _, _, err := net.ParseCIDR(c); err != nil {
+				errs = append(errs, fmt.Sprintf("%s.clients[%d]: %q is not a valid CIDR", field, j, c))
+			}
+		}
+
+		types := make(map[string]map[string]bool) // name -> record types
+		for j, rec := range v.dnsRecords() {
+			rfield := fmt.Sprintf("%s.records[%d]", field, j)
+			name := strings.ToLower(rec.Name)
+			if name == "" {
+				errs = append(errs, rfield+".name: required")
+				continue
+			}
+			if e := validateViewRecordData(rec); e != "" {
+				errs = append(errs, rfield+".data: "+e)
+			}
+			if types[name] == nil {
+				types[name] = make(map[string]bool)
+			}
+			types[name][rec.Type] = true
+			if types[name]["CNAME"] && len(types[name]) > 1 {
+				errs = append(errs, fmt.Sprintf("%s: %s has a CNAME and other records", rfield, rec.Name))
+			}
+		}
+	}
+	return errs
+}
+
+// validateViewRecordData checks a view record's data against its type.
+func validateViewRecordData(rec dns.Record) string {
+	data := rec.Data.Data
+	switch rec.Type {
+	case "A":
+		if ip := net.ParseIP(data); ip == nil || ip.To4() == nil {
+			return fmt.Sprintf("%q is not an IPv4 address", data)
+		}
+	case "AAAA":
+		if ip := net.ParseIP(data); ip == nil || ip.To4() != nil {
+			return fmt.Sprintf("%q is not an IPv6 address", data)
+		}
+	case "CNAME":
+		if data == "" || strings.ContainsAny(data, " \t") {
+			return fmt.Sprintf("%q is not a host name", data)
+		}
+	case "TXT":
+		if data == "" {
+			return "text is required"
+		}
+	default:
+		return fmt.Sprintf("type %q is not supported in views (A, AAAA, CNAME, TXT)", rec.Type)
+	}
+	return ""
+}
+
+// checkDNSViews validates each view of a network on its own: the view
+// zone exists and holds exactly the spec's overrides. View zones of views
+// no longer in the spec are flagged stale.
+func checkDNSViews(ctx context.Context, client *dns.Client, n *Network, endpoint string) []CheckItem {
+	return checkViewZones(ctx, client, endpoint, n.Spec.DNS.Zone, "dns-view/"+n.Name, n.Spec.DNS.Views)
+}
+
+// checkNamespaceDNSViews validates the views of each namespace zone on the
+// network's microdns like checkDNSViews, under dns-view/<network>/<zone>.
+// A namespace with invalid views fails as a whole.
+func (p *MicroKubeProvider) checkNamespaceDNSViews(ctx context.Context, client *dns.Client, n *Network, endpoint string) []CheckItem {
+	var items []CheckItem
+	nsViews := p.namespaceDNSViews(n)
+	for _, nv := range nsViews {
+		prefix := fmt.Sprintf("dns-view/%s/%s", n.Name, nv.Zone)
+		if errs := namespaceViewErrors(n, nsViews, nv); len(errs) > 0 {
+			items = append(items, CheckItem{
+				Name:    prefix,
+				Status:  "fail",
+				Message: "invalid namespace views",
+				Details: strings.Join(errs, "; "),
+			})
+			continue
+		}
+		if len(nv.Views) > 0 && !p.namespaceZoneServedBy(nv, endpoint) {
+			items = append(items, CheckItem{
+				Name:    prefix,
+				Status:  "fail",
+				Message: "namespace zone not on the network's microdns",
+				Details: fmt.Sprintf("namespace %s has views but its zone is served elsewhere", nv.Namespace),
+			})
+			continue
+		}
+		items = append(items, checkViewZones(ctx, client, endpoint, nv.Zone, prefix, nv.Views)...)
+	}
+	return items
+}
+
+// checkViewZones checks the view zones of zone against views, naming items
+// <prefix>/<view>/<record>.
+func checkViewZones(ctx context.Context, client *dns.Client, endpoint, zone, prefix string, views []DNSView) []CheckItem {
+	zones, err := client.ViewZones(ctx, endpoint, zone)
+	if err != nil {
+		if len(views) == 0 {
+			return nil
+		}
+		return []CheckItem{{
+			Name:    prefix,
+			Status:  "fail",
+			Message: fmt.Sprintf("listing view zones: %v", err),
+		}}
+	}
+
+	var items []CheckItem
+	for _, v := range views {
+		viewName := fmt.Sprintf("%s/%s", prefix, v.Name)
+		zoneID, ok := zones[v.Name]
+		delete(zones, v.Name)
+		if !ok {
+			items = append(items, CheckItem{
+				Name:    viewName,
+				Status:  "fail",
+				Message: "view zone missing",
+				Details: fmt.Sprintf("clients=%s", strings.Join(v.Clients, ",")),
+			})
+			continue
+		}
+		records, err := client.ListRecords(ctx, endpoint, zoneID)
+		if err != nil {
+			items = append(items, CheckItem{
+				Name:    viewName,
+				Status:  "fail",
+				Message: fmt.Sprintf("view zone unreachable: %v", err),
+			})
+			continue
+		}
+
+		claimed := make(map[string]bool)
+		for _, want := range v.dnsRecords() {
+			item := CheckItem{
+				Name:    fmt.Sprintf("%s/%s", viewName, want.Name),
+				Status:  "pass",
+				Message: "view record correct",
+				Details: fmt.Sprintf("%s %s", want.Type, want.Data.Data),
+			}
+			var others []string
+			found := false
+			for _, r := range records {
+				if !claimed[r.ID] && dns.SameRecord(r, want) {
+					found, claimed[r.ID] = true, true
+					break
+				}
+				if strings.EqualFold(r.Name, want.Name) && r.Type == want.Type {
+					others = append(others, fmt.Sprintf("%d %s", r.TTL, r.Data.Data))
+				}
+			}
+			if !found {
+				item.Status, item.Message = "fail", "view record missing"
+				item.Details = fmt.Sprintf("expected %s %d %s", want.Type, want.TTL, want.Data.Data)
+				if len(others) > 0 {
+					item.Message = "view record differs"
+					item.Details += fmt.Sprintf(", actual %v", others)
+				}
+			}
+			items = append(items, item)
+		}
+
+		for _, r := range records {
+			if claimed[r.ID] {
+				continue
+			}
+			items = append(items, CheckItem{
+				Name:    fmt.Sprintf("%s/%s", viewName, r.Name),
+				Status:  "warn",
+				Message: "view record not in spec",
+				Details: fmt.Sprintf("%s %s", r.Type, r.Data.Data),
+			})
+		}
+	}
+
+	for view := range zones {
+		items = append(items, CheckItem{
+			Name:    fmt.Sprintf("%s/%s", prefix, view),
+			Status:  "warn",
+			Message: "stale view zone",
+			Details: "view is no longer configured",
+		})
+	}
+	return items
+}
@@ -0,0 +1,245 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/glennswest/mkube/pkg/config"
+	"github.com/glennswest/mkube/pkg/namespace"
+)
+
+func TestDNSViews(t *testing.T) {
+	p, _ := newTestProvider(t)
+	ctx := context.Background()
+	zs, endpoint := newZoneServer(t, p)
+	client := p.deps.NetworkMgr.DNSClient()
+
+	net := &Network{
+		ObjectMeta: metav1.ObjectMeta{Name: "gw"},
+		Spec: NetworkSpec{
+			CIDR: "192.168.1.0/24",
+			DNS: NetworkDNSSpec{Endpoint: endpoint, Zone: "gt.lo", Server: "192.168.1.199", Views: []DNSView{
+				{Name: "lan", Clients: []string{"192.168.1.0/24", "10.0.0.0/8"}, Records: []DNSViewRecord{
+					{Name: "web", Data: "192.168.1.50"},
+					{Name: "www", Type: "CNAME", Data: "web.gt.lo.", TTL: 60},
+				}},
+				{Name: "vpn", Clients: []string{"172.30.0.0/16"}, Records: []DNSViewRecord{
+					{Name: "web", Data: "172.30.0.5"},
+				}},
+			}},
+		},
+	}
+	p.networks[net.Name] = net
+
+	toml := p.generateMinimalTOML(net)
+	for _, want := range []string{
+		"[[dns.auth.views]]\nname = \"lan\"\nmatch_clients = [\"192.168.1.0/24\", \"10.0.0.0/8\"]",
+		"[[dns.auth.views]]\nname = \"vpn\"\nmatch_clients = [\"172.30.0.0/16\"]",
+	} {
+		if !strings.Contains(toml, want) {
+			t.Errorf("TOML lacks %q:\n%s", want, toml)
+		}
+	}
+
+	// A view zone left over from a removed view goes away on sync
+	if _, err := client.EnsureViewZone(ctx, endpoint, "gt.lo", "old"); err != nil {
+		t.Fatalf("EnsureViewZone: %v", err)
+	}
+	if err := p.syncDNSViews(ctx, client, endpoint, net); err != nil {
+		t.Fatalf("syncDNSViews: %v", err)
+	}
+	views, err := client.ViewZones(ctx, endpoint, "gt.lo")
+	if err != nil || len(views) != 2 || views["lan"] == "" || views["vpn"] == "" {
+		t.Fatalf("view zones = %v, %v", views, err)
+	}
+	if got := zs.listZone(views["lan"], ""); strings.Join(got, ",") != "web 192.168.1.50,www web.gt.lo." {
+		t.Errorf("lan view records = %v", got)
+	}
+	// The zone itself keeps its own answers
+	for _, line := range zs.list("A") {
+		if strings.HasPrefix(line, "web ") {
+			t.Errorf("view record leaked into the zone: %s", line)
+		}
+	}
+
+	for _, item := range checkDNSViews(ctx, client, net, endpoint) {
+		if item.Status != "pass" {
+			t.Errorf("%s: %s %s (%s)", item.Name, item.Status, item.Message, item.Details)
+		}
+	}
+
+	// Each view is checked on its own
+	net.Spec.DNS.Views[1].Records[0].Data = "172.30.0.6"
+	status := map[string]string{}
+	for _, item := range checkDNSViews(ctx, client, net, endpoint) {
+		status[item.Name] += item.Status + " " + item.Message + ";"
+	}
+	if status["dns-view/gw/vpn/web"] != "fail view record differs;warn view record not in spec;" ||
+		status["dns-view/gw/lan/web"] != "pass view record correct;" {
+		t.Errorf("check after edit = %v", status)
+	}
+	if err := p.syncDNSViews(ctx, client, endpoint, net); err != nil {
+		t.Fatalf("re-sync: %v", err)
+	}
+	if got := zs.listZone(views["vpn"], ""); strings.Join(got, ",") != "web 172.30.0.6" {
+		t.Errorf("vpn view after re-sync = %v", got)
+	}
+
+	// The record API reads views and refuses writes to them
+	mux := http.NewServeMux()
+	p.RegisterRoutes(mux)
+	handler := p.WrapHandler(mux)
+	do := func(method, url, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(method, url, strings.NewReader(body)))
+		return rec
+	}
+	rec := do(http.MethodGet, "/api/v1/namespaces/gw/dnsrecords?view=lan", "")
+	var list DNSRecordList
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("list view records: %d %s", rec.Code, rec.Body)
+	}
+	if len(list.Items) != 2 || list.Items[0].Spec.View != "lan" {
+		t.Errorf("view records = %+v", list.Items)
+	}
+	if rec := do(http.MethodGet, "/api/v1/namespaces/gw/dnsrecords?view=nope", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown view: got %d, want 404", rec.Code)
+	}
+	if rec := do(http.MethodPost, "/api/v1/namespaces/gw/dnsrecords?view=lan", `{"spec":{"hostname":"x","type":"A","data":"10.0.0.1"}}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("write to view: got %d, want 400", rec.Code)
+	}
+}
+
+func TestValidateDNSViews(t *testing.T) {
+	net := &Network{Spec: NetworkSpec{DNS: NetworkDNSSpec{Zone: "gt.lo", Views: []DNSView{
+		{Name: "lan", Clients: []string{"192.168.1.0/24"}, Records: []DNSViewRecord{
+			{Name: "web", Data: "192.168.1.50"},
+			{Name: "v6", Type: "AAAA", Data: "fd00::5"},
+		}},
+	}}}}
+	if errs := validateDNSViews(net); len(errs) != 0 {
+		t.Fatalf("valid views rejected: %v", errs)
+	}
+
+	net.Spec.DNS.Views = append(net.Spec.DNS.Views, DNSView{
+		Name:    "lan",
+		Clients: []string{"192.168.1.1"},
+		Records: []DNSViewRecord{
+			{Name: "web", Data: "fd00::5"},
+			{Name: "alias", Type: "CNAME", Data: "web"},
+			{Name: "alias", Type: "TXT", Data: "x"},
+			{Name: "mx", Type: "MX", Data: "10 mail"},
+		},
+	})
+	errs := strings.Join(validateDNSViews(net), "\n")
+	for _, want := range []string{
+		`spec.dns.views[1].name: duplicate view "lan"`,
+		`spec.dns.views[1].clients[0]: "192.168.1.1" is not a valid CIDR`,
+		`spec.dns.views[1].records[0].data: "fd00::5" is not an IPv4 address`,
+		`spec.dns.views[1].records[2]: alias has a CNAME and other records`,
+		`spec.dns.views[1].records[3].data: type "MX" is not supported`,
+	} {
+		if !strings.Contains(errs, want) {
+			t.Errorf("errors lack %q:\n%s", want, errs)
+		}
+	}
+}
+
+// viewZoneResolver serves every namespace zone from one microdns.
+type viewZoneResolver struct{ endpoint string }
+
+func (r viewZoneResolver) GetZoneEndpoint(zone string) (string, string, error) {
+	return r.endpoint, "zone-" + zone, nil
+}
+func (r viewZoneResolver) EnsureZone(context.Context, string, string, bool) error { return nil }
+
+func TestNamespaceDNSViews(t *testing.T) {
+	p, _ := newTestProvider(t)
+	ctx := context.Background()
+	zs, endpoint := newZoneServer(t, p)
+	client := p.deps.NetworkMgr.DNSClient()
+
+	nsMgr := namespace.NewManager(config.NamespaceConfig{StatePath: t.TempDir() + "/ns.yaml"}, config.DZOConfig{},
+		[]config.NetworkDef{{Name: "gw", DNS: config.DNSConfig{Zone: "gt.lo"}}}, viewZoneResolver{endpoint}, zap.NewNop().Sugar())
+	if err := nsMgr.Bootstrap(ctx); err != nil {
+		t.Fatalf("Bootstrap: %v", err)
+	}
+	if _, err := nsMgr.CreateNamespace(ctx, "kube", "", "gw", namespace.ModeNested, false); err != nil {
+		t.Fatalf("CreateNamespace: %v", err)
+	}
+	p.deps.Namespace = nsMgr
+	p.deps.Config.Namespace.DNSViews = map[string][]config.DNSViewConfig{
+		"kube": {
+			{Name: "lan", Clients: []string{"192.168.1.0/24"}, Records: []config.DNSViewRecordConfig{{Name: "api", Data: "192.168.1.60"}}},
+			{Name: "lab", Clients: []string{"10.9.0.0/16"}, Records: []config.DNSViewRecordConfig{{Name: "api", Type: "CNAME", Data: "api.lab.lo."}}},
+		},
+	}
+
+	net := &Network{
+		ObjectMeta: metav1.ObjectMeta{Name: "gw"},
+		Spec: NetworkSpec{
+			CIDR: "192.168.1.0/24",
+			DNS: NetworkDNSSpec{Endpoint: endpoint, Zone: "gt.lo", Server: "192.168.1.199", Views: []DNSView{
+				{Name: "lan", Clients: []string{"192.168.1.0/24"}, Records: []DNSViewRecord{{Name: "web", Data: "192.168.1.50"}}},
+			}},
+		},
+	}
+	p.networks[net.Name] = net
+
+	// The network and its namespace share the lan view; lab is the namespace's
+	toml := p.generateMinimalTOML(net)
+	if n := strings.Count(toml, "[[dns.auth.views]]"); n != 2 ||
+		!strings.Contains(toml, "name = \"lab\"\nmatch_clients = [\"10.9.0.0/16\"]") {
+		t.Errorf("TOML has %d views:\n%s", n, toml)
+	}
+
+	if err := p.syncDNSViews(ctx, client, endpoint, net); err != nil {
+		t.Fatalf("syncDNSViews: %v", err)
+	}
+	views, err := client.ViewZones(ctx, endpoint, "kube.gt.lo")
+	if err != nil || len(views) != 2 {
+		t.Fatalf("namespace view zones = %v, %v", views, err)
+	}
+	if got := zs.listZone(views["lan"], ""); strings.Join(got, ",") != "api 192.168.1.60" {
+		t.Errorf("namespace lan view records = %v", got)
+	}
+	if got := zs.listZone(views["lab"], ""); strings.Join(got, ",") != "api api.lab.lo." {
+		t.Errorf("namespace lab view records = %v", got)
+	}
+
+	for _, item := range p.checkNamespaceDNSViews(ctx, client, net, endpoint) {
+		if item.Status != "pass" {
+			t.Errorf("%s: %s %s (%s)", item.Name, item.Status, item.Message, item.Details)
+		}
+	}
+
+	// A view name shared with the network must match the same clients
+	p.deps.Config.Namespace.DNSViews["kube"][0].Clients = []string{"192.168.0.0/16"}
+	items := p.checkNamespaceDNSViews(ctx, client, net, endpoint)
+	if len(items) != 1 || items[0].Name != "dns-view/gw/kube.gt.lo" || items[0].Status != "fail" ||
+		!strings.Contains(items[0].Details, `namespace.dnsViews.kube[0].clients: network "gw" has a view "lan" with other clients`) {
+		t.Errorf("check with clashing clients = %+v", items)
+	}
+	if errs := strings.Join(p.validateNetworkSemantics(net), "\n"); !strings.Contains(errs, `spec.dns.views[0].clients: namespace "kube" has a view "lan" with other clients`) {
+		t.Errorf("network validation missed the clash: %s", errs)
+	}
+	if err := p.syncDNSViews(ctx, client, endpoint, net); err == nil {
+		t.Error("syncDNSViews synced invalid namespace views")
+	}
+
+	// Views dropped from the config are removed from the namespace zone
+	delete(p.deps.Config.Namespace.DNSViews, "kube")
+	if err := p.syncDNSViews(ctx, client, endpoint, net); err != nil {
+		t.Fatalf("syncDNSViews after removal: %v", err)
+	}
+	if views, err := client.ViewZones(ctx, endpoint, "kube.gt.lo"); err != nil || len(views) != 0 {
+		t.Errorf("namespace view zones after removal = %v, %v", views, err)
+	}
+}
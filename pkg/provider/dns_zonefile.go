@@ -0,0 +1,157 @@
+package provider
+
+import (
+	"fmt"
+	"net/http"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/glennswest/mkube/pkg/dns"
+)
+
+// ─── Zone File Import / Export ──────────────────────────────────────────────
+
+// formatZoneFile selects master-file format on the dnsrecords collection:
+// GET exports the zone, POST imports one.
+const formatZoneFile = "zonefile"
+
+// DNSZoneImport reports what a zone-file import changed, or would change
+// under ?dryRun=All.
+type DNSZoneImport struct {
+	metav1.TypeMeta `json:",inline"`
+	Zone            string            `json:"zone"`
+	DryRun          bool              `json:"dryRun,omitempty"`
+	Created         []DNSRecordChange `json:"created"`
+	Updated         []DNSRecordChange `json:"updated"`
+	Deleted         []DNSRecordChange `json:"deleted"`
+	Unchanged       int               `json:"unchanged"`
+	Errors          []string          `json:"errors,omitempty"`
+}
+
+// DNSRecordChange is one record of an import report. Data is in
+// master-file presentation format; Old is the replaced "ttl data" of an
+// update.
+type DNSRecordChange struct {
+	ID       string `json:"id,omitempty"`
+	Hostname string `json:"hostname"`
+	Type     string `json:"type"`
+	TTL      int    `json:"ttl"`
+	Data     string `json:"data"`
+	Old      string `json:"old,omitempty"`
+}
+
+func recordChange(rec dns.FullRecord) DNSRecordChange {
+	return DNSRecordChange{ID: rec.ID, Hostname: rec.Name, Type: rec.Type, TTL: rec.TTL, Data: rec.Data.Text()}
+}
+
+// writeZoneFile responds with records as a master file for zone.
+func writeZoneFile(w http.ResponseWriter, zone string, records []dns.FullRecord) {
+	w.Header().Set("Content-Type", "text/dns; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", zone+".zone"))
+	w.WriteHeader(http.StatusOK)
+	_ = dns.WriteZoneFile(w, zone, records)
+}
+
+// importZoneFile diffs the master file in the request body against the
+// zone and applies the difference: deletes first (so a name can change
+// type), then updates, then creates. Failed operations are reported and
+// the rest still applied.
+func (p *MicroKubeProvider) importZoneFile(w http.ResponseWriter, r *http.Request, endpoint, zoneID string, net *Network) {
+	dryRun, ok := dryRunRequested(w, r)
+	if !ok {
+		return
+	}
+	zone := net.Spec.DNS.Zone
+	want, err := dns.ParseZoneFile(r.Body, zone)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid zone file: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	client := p.deps.NetworkMgr.DNSClient()
+	have, err := client.ListFullRecords(r.Context(), endpoint, zoneID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("listing DNS records: %v", err), http.StatusBadGateway)
+		return
+	}
+	diff := dns.DiffZone(zone, have, want)
+
+	report := DNSZoneImport{
+		TypeMeta:  metav1.TypeMeta{APIVersion: "v1", Kind: "DNSZoneImport"},
+		Zone:      zone,
+		DryRun:    dryRun,
+		Created:   []DNSRecordChange{},
+		Updated:   []DNSRecordChange{},
+		Deleted:   []DNSRecordChange{},
+		Unchanged: diff.Unchanged,
+	}
+	fail := func(format string, args ...interface{}) {
+		report.Errors = append(report.Errors, fmt.Sprintf(format, args...))
+	}
+
+	for _, rec := range diff.Delete {
+		if !dryRun {
+			if err := client.DeleteRecord(r.Context(), endpoint, zoneID, rec.ID); err != nil {
+				fail("deleting %s %s: %v", rec.Name, rec.Type, err)
+				continue
+			}
+		}
+		report.Deleted = append(report.Deleted, recordChange(rec))
+	}
+	for _, u := range diff.Update {
+		change := recordChange(u.New)
+		change.ID = u.Old.ID
+		change.Old = fmt.Sprintf("%d %s", u.Old.TTL, u.Old.Data.Text())
+		if !dryRun {
+			payload := map[string]interface{}{
+				"ttl":  u.New.TTL,
+				"data": u.New.Data,
+			}
+			if _, err := client.UpdateFullRecord(r.Context(), endpoint, zoneID, u.Old.ID, payload); err != nil {
+				fail("updating %s %s: %v", u.Old.Name, u.Old.Type, err)
+				continue
+			}
+		}
+		report.Updated = append(report.Updated, change)
+	}
+	for _, rec := range diff.Create {
+		change := recordChange(rec)
+		if !dryRun {
+			created, err := client.CreateFullRecord(r.Context(), endpoint, zoneID, map[string]interface{}{
+				"name":    rec.Name,
+				"ttl":     rec.TTL,
+				"data":    rec.Data,
+				"enabled": true,
+			})
+			if err != nil {
+				fail("creating %s %s: %v", rec.Name, rec.Type, err)
+				continue
+			}
+			change.ID = created.ID
+		}
+		report.Created = append(report.Created, change)
+	}
+
+	if !dryRun {
+		// Imported address records get their PTRs like registered ones
+		if err := client.SyncReverseRecords(r.Context(), endpoint, zoneID); err != nil {
+			fail("syncing PTR records: %v", err)
+		}
+		msg := fmt.Sprintf("Zone file imported into %s: %d created, %d updated, %d deleted, %d unchanged",
+			zone, len(report.Created), len(report.Updated), len(report.Deleted), report.Unchanged)
+		eventType := "Normal"
+		if len(report.Errors) > 0 {
+			msg += fmt.Sprintf(", %d failed", len(report.Errors))
+			eventType = "Warning"
+		}
+		p.recordNetworkEvent(net.Name, "ZoneImported", msg, eventType)
+		p.deps.Logger.Infow("DNS zone file imported", "zone", zone, "created", len(report.Created),
+			"updated", len(report.Updated), "deleted", len(report.Deleted), "errors", len(report.Errors))
+	}
+
+	code := http.StatusOK
+	if len(report.Errors) > 0 {
+		code = http.StatusBadGateway
+	}
+	podWriteJSON(w, code, report)
+}
@@ -0,0 +1,89 @@
+package provider
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDNSZoneFileImportExport(t *testing.T) {
+	p, _ := newTestProvider(t)
+	zs, endpoint := newZoneServer(t, p)
+	p.networks["containers"] = &Network{
+		ObjectMeta: metav1.ObjectMeta{Name: "containers"},
+		Spec:       NetworkSpec{DNS: NetworkDNSSpec{Endpoint: endpoint, Zone: "gt.lo"}},
+	}
+	mux := http.NewServeMux()
+	p.RegisterRoutes(mux)
+	handler := p.WrapHandler(mux)
+	do := func(method, url, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(method, url, strings.NewReader(body)))
+		return rec
+	}
+	const records = "/api/v1/namespaces/containers/dnsrecords"
+
+	// rose1 is the gateway record InitDNSZones registered
+	zone := `$ORIGIN gt.lo.
+rose1 300 IN A  172.20.0.1
+web   60 IN A   192.168.200.5
+www   60 IN CNAME web
+_http._tcp.web 60 IN SRV 0 100 8080 web.gt.lo.
+`
+	// Dry run reports the creates without touching the zone
+	rec := do(http.MethodPost, records+"?format=zonefile&dryRun=All", zone)
+	var report DNSZoneImport
+	if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("dry-run import: %d %s", rec.Code, rec.Body)
+	}
+	if !report.DryRun || len(report.Created) != 3 || report.Unchanged != 1 || len(zs.list("A")) != 1 {
+		t.Fatalf("dry run = %+v, zone A records %v", report, zs.list("A"))
+	}
+
+	if rec := do(http.MethodPost, records+"?format=zonefile", zone); rec.Code != http.StatusOK {
+		t.Fatalf("import: %d %s", rec.Code, rec.Body)
+	}
+	if got := zs.list("SRV"); len(got) != 1 || got[0] != "_http._tcp.web 0 100 8080 web.gt.lo." {
+		t.Errorf("SRV after import = %v", got)
+	}
+
+	// Re-importing an edited file updates, creates and deletes
+	edited := `$ORIGIN gt.lo.
+rose1 300 IN A  172.20.0.1
+web   300 IN A   192.168.200.5
+db    60  IN A   192.168.200.7
+_http._tcp.web 60 IN SRV 0 100 8080 web.gt.lo.
+`
+	rec = do(http.MethodPost, records+"?format=zonefile", edited)
+	report = DNSZoneImport{}
+	_ = json.Unmarshal(rec.Body.Bytes(), &report)
+	if rec.Code != http.StatusOK || len(report.Created) != 1 || len(report.Updated) != 1 ||
+		len(report.Deleted) != 1 || report.Unchanged != 2 {
+		t.Fatalf("edited import: %d %+v", rec.Code, report)
+	}
+	if u := report.Updated[0]; u.Hostname != "web" || u.TTL != 300 || u.Old != "60 192.168.200.5" {
+		t.Errorf("update = %+v", u)
+	}
+	if d := report.Deleted[0]; d.Hostname != "www" || d.Type != "CNAME" {
+		t.Errorf("delete = %+v", d)
+	}
+
+	// Export writes the zone back out
+	rec = do(http.MethodGet, records+"?format=zonefile", "")
+	if rec.Code != http.StatusOK || !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/dns") {
+		t.Fatalf("export: %d %s", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	for _, line := range []string{"$ORIGIN gt.lo.", "db ", "IN A     192.168.200.7", "IN SRV   0 100 8080 web.gt.lo."} {
+		if !strings.Contains(rec.Body.String(), line) {
+			t.Errorf("export lacks %q:\n%s", line, rec.Body)
+		}
+	}
+
+	if rec := do(http.MethodPost, records+"?format=zonefile", "web IN A not-an-ip"); rec.Code != http.StatusBadRequest {
+		t.Errorf("bad zone file: got %d, want 400", rec.Code)
+	}
+}
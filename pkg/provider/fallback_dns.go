@@ -0,0 +1,234 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/glennswest/mkube/pkg/dns"
+)
+
+// ─── Fallback DNS ───────────────────────────────────────────────────────────
+
+// fallbackDNS is the authoritative responder mkube runs for its managed
+// zones while a microdns instance fails the port 53 probe. It serves from
+// what mkube already knows, so the registry and the other infrastructure
+// names keep resolving until microdns is repaired.
+type fallbackDNS struct {
+	responder *dns.Responder
+	resolver  *net.Resolver // mkube's own lookups, redirected by dial
+
+	mu   sync.Mutex
+	down map[string]string // network -> DNS server IP of a microdns not answering
+}
+
+func newFallbackDNS(responder *dns.Responder) *fallbackDNS {
+	f := &fallbackDNS{responder: responder, down: make(map[string]string)}
+	f.resolver = &net.Resolver{PreferGo: true, Dial: f.dial}
+	return f
+}
+
+// StartFallbackDNS prepares the fallback responder; checkInfraHealth runs
+// it while any managed microdns is down. mkube's own lookups (local
+// registry pulls and overlay peer names) use a dedicated resolver that
+// sends queries addressed to a dead microdns to the responder instead, so
+// image pulls from the registry work while microdns itself is being pulled
+// and restarted. The process-wide net.DefaultResolver is left alone, and
+// LAN clients asking microdns directly are not covered.
+func (p *MicroKubeProvider) StartFallbackDNS() {
+	cfg := p.deps.Config.FallbackDNS
+	if !cfg.Enabled {
+		return
+	}
+	p.fallbackDNS = newFallbackDNS(dns.NewResponder(cfg.Listen, p.deps.Logger))
+	if p.deps.StorageMgr != nil {
+		p.deps.StorageMgr.SetResolver(p.fallbackDNS.resolver)
+	}
+}
+
+// resolver returns the resolver for mkube's own lookups: the fallback DNS
+// one when it is enabled, else the system resolver.
+func (p *MicroKubeProvider) resolver() *net.Resolver {
+	if p.fallbackDNS != nil {
+		return p.fallbackDNS.resolver
+	}
+	return net.DefaultResolver
+}
+
+// dial redirects UDP DNS queries for a down microdns to the responder.
+func (f *fallbackDNS) dial(ctx context.Context, network, address string) (net.Conn, error) {
+	if host, _, err := net.SplitHostPort(address); err == nil && strings.HasPrefix(network, "udp") && f.serving(host) {
+		if local := f.responder.LocalAddr(); local != "" {
+			address = local
+		}
+	}
+	var d net.Dialer
+	return d.DialContext(ctx, network, address)
+}
+
+// serving reports whether the responder stands in for the DNS server ip.
+func (f *fallbackDNS) serving(ip string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, server := range f.down {
+		if server == ip {
+			return true
+		}
+	}
+	return false
+}
+
+// updateFallbackDNS takes the port 53 probe result of each managed network
+// and runs the responder while any of them is down, refreshing its records
+// on every call. It steps down once every microdns answers again.
+func (p *MicroKubeProvider) updateFallbackDNS(alive map[string]bool) {
+	f := p.fallbackDNS
+	if f == nil {
+		return
+	}
+
+	names := make([]string, 0, len(alive))
+	for name := range alive {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	f.mu.Lock()
+	for name := range f.down {
+		if _, managed := alive[name]; !managed {
+			delete(f.down, name) // network removed or no longer managed
+		}
+	}
+	var failed, recovered []string
+	for _, name := range names {
+		_, wasDown := f.down[name]
+		switch {
+		case !alive[name] && !wasDown:
+			f.down[name] = p.networks[name].Spec.DNS.Server
+			failed = append(failed, name)
+		case alive[name] && wasDown:
+			delete(f.down, name)
+			recovered = append(recovered, name)
+		}
+	}
+	anyDown := len(f.down) > 0
+	f.mu.Unlock()
+
+	for _, name := range failed {
+		n := p.networks[name]
+		p.recordNetworkEvent(name, "FallbackDNSActive",
+			fmt.Sprintf("microdns on %s not answering on port 53, mkube is serving %s", n.Spec.DNS.Server, n.Spec.DNS.Zone),
+			"Warning")
+	}
+	for _, name := range recovered {
+		p.recordNetworkEvent(name, "FallbackDNSStepDown",
+			"microdns answering on port 53 again, mkube stopped serving its zone", "Normal")
+	}
+
+	if !anyDown {
+		if f.responder.Running() {
+			if err := f.responder.Stop(); err != nil {
+				p.deps.Logger.Warnw("stopping fallback DNS responder", "error", err)
+			}
+		}
+		return
+	}
+	f.responder.SetZones(p.fallbackDNSZones())
+	if err := f.responder.Start(); err != nil {
+		p.deps.Logger.Errorw("fallback DNS responder failed to start", "error", err)
+	}
+}
+
+// fallbackDNSZones collects the records mkube knows for every managed zone:
+// pods (containers, aliases, SRV, TXT), BMHs, address claims, static
+// records, reservation hostnames and the gateway and DNS server names, plus
+// the container names of nested namespace zones.
+func (p *MicroKubeProvider) fallbackDNSZones() map[string][]dns.Record {
+	pods := make([]*corev1.Pod, 0, len(p.pods))
+	for _, pod := range p.pods {
+		pods = append(pods, pod)
+	}
+
+	zones := make(map[string][]dns.Record)
+	for _, n := range p.networks {
+		zone := n.Spec.DNS.Zone
+		if zone == "" || n.Spec.ExternalDNS {
+			continue
+		}
+		expected := p.buildExpectedDNSRecords(pods, n.Name)
+		for _, rec := range n.Spec.StaticRecords {
+			expected[rec.Name] = expectedDNS{ip: rec.IP}
+		}
+		for _, res := range n.Spec.DHCP.Reservations {
+			if res.Hostname != "" && res.IP != "" {
+				expected[res.Hostname] = expectedDNS{ip: res.IP}
+			}
+		}
+		if n.Spec.Gateway != "" {
+			expected["rose1"] = expectedDNS{ip: n.Spec.Gateway}
+		}
+		if n.Spec.DNS.Server != "" {
+			expected["dns"] = expectedDNS{ip: n.Spec.DNS.Server}
+		}
+
+		var records []dns.Record
+		for name, e := range expected {
+			if e.ip != "" {
+				records = append(records, fallbackRecord(name, hostRecordType(e.ip), e.ip))
+			}
+			for _, srv := range e.srv {
+				data, _ := json.Marshal(srv)
+				records = append(records, fallbackRecord(name, "SRV", string(data)))
+			}
+			for _, txt := range e.txt {
+				records = append(records, fallbackRecord(name, "TXT", txt))
+			}
+		}
+		zones[zone] = append(zones[zone], records...)
+	}
+
+	if p.deps.Namespace == nil {
+		return zones
+	}
+	for _, ns := range p.deps.Namespace.ListNamespaces() {
+		if ns.Zone == "" || zones[ns.Zone] != nil {
+			continue
+		}
+		var records []dns.Record
+		for _, pod := range pods {
+			if pod.Annotations[annotationNamespace] != ns.Name {
+				continue
+			}
+			for i, c := range pod.Spec.Containers {
+				ip, _, ok := p.deps.NetworkMgr.GetPortInfo(vethName(pod, i))
+				if !ok {
+					continue
+				}
+				if static := pod.Annotations[annotationStaticIP]; static != "" {
+					ip = static
+				}
+				records = append(records, fallbackRecord(c.Name+"."+pod.Name, hostRecordType(ip), ip))
+			}
+		}
+		zones[ns.Zone] = records
+	}
+	return zones
+}
+
+func fallbackRecord(name, rtype, data string) dns.Record {
+	return dns.Record{Name: name, Type: rtype, TTL: 60, Data: dns.RecordData{Type: rtype, Data: data}}
+}
+
+// hostRecordType returns AAAA for an IPv6 address and A otherwise.
+func hostRecordType(ip string) string {
+	if parsed := net.ParseIP(ip); parsed != nil && parsed.To4() == nil {
+		return "AAAA"
+	}
+	return "A"
+}
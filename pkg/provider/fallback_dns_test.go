@@ -0,0 +1,94 @@
+package provider
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/glennswest/mkube/pkg/config"
+	"github.com/glennswest/mkube/pkg/dns"
+)
+
+func TestFallbackDNS(t *testing.T) {
+	p, _ := newTestProvider(t)
+	p.networks["gw"] = &Network{
+		ObjectMeta: metav1.ObjectMeta{Name: "gw"},
+		Spec: NetworkSpec{
+			CIDR:          "192.168.1.0/24",
+			Gateway:       "192.168.1.1",
+			DNS:           NetworkDNSSpec{Zone: "gt.lo", Server: "192.168.1.199"},
+			StaticRecords: []StaticDNSRecord{{Name: "registry", IP: "192.168.1.3"}},
+			DHCP: NetworkDHCPSpec{Reservations: []NetworkDHCPReservation{
+				{MAC: "aa:bb:cc:dd:ee:01", IP: "192.168.1.40", Hostname: "server1-bmc"},
+			}},
+		},
+	}
+	f := newFallbackDNS(dns.NewResponder("127.0.0.1:0", p.deps.Logger))
+	p.fallbackDNS = f
+
+	zones := p.fallbackDNSZones()
+	have := make(map[string]string)
+	for _, r := range zones["gt.lo"] {
+		have[r.Name] = r.Data.Data
+	}
+	for name, ip := range map[string]string{"registry": "192.168.1.3", "server1-bmc": "192.168.1.40", "rose1": "192.168.1.1", "dns": "192.168.1.199"} {
+		if have[name] != ip {
+			t.Errorf("fallback zone: %s = %q, want %q", name, have[name], ip)
+		}
+	}
+
+	// microdns down: the responder starts and mkube's lookups follow it
+	p.updateFallbackDNS(map[string]bool{"gw": false})
+	if !f.responder.Running() {
+		t.Fatal("responder not started while microdns is down")
+	}
+	conn, err := f.dial(context.Background(), "udp", "192.168.1.199:53")
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	if got := conn.RemoteAddr().String(); got != f.responder.LocalAddr() {
+		t.Errorf("lookup dialed %s, want the responder at %s", got, f.responder.LocalAddr())
+	}
+	conn.Close()
+
+	// Still down: no repeated events
+	p.updateFallbackDNS(map[string]bool{"gw": false})
+
+	// microdns back: the responder steps down
+	p.updateFallbackDNS(map[string]bool{"gw": true})
+	if f.responder.Running() {
+		t.Fatal("responder still running after microdns recovered")
+	}
+	if f.serving("192.168.1.199") {
+		t.Error("lookups still redirected after recovery")
+	}
+
+	var reasons []string
+	for _, e := range p.events {
+		if e.InvolvedObject.Name == "gw" {
+			reasons = append(reasons, e.Reason)
+		}
+	}
+	if len(reasons) != 2 || reasons[0] != "FallbackDNSActive" || reasons[1] != "FallbackDNSStepDown" {
+		t.Errorf("events = %v", reasons)
+	}
+}
+
+func TestStartFallbackDNSKeepsSystemResolver(t *testing.T) {
+	p, _ := newTestProvider(t)
+	if p.resolver() != net.DefaultResolver {
+		t.Error("mkube lookups not on the system resolver with fallback DNS disabled")
+	}
+
+	system := net.DefaultResolver
+	p.deps.Config.FallbackDNS = config.FallbackDNSConfig{Enabled: true, Listen: "127.0.0.1:0"}
+	p.StartFallbackDNS()
+	if net.DefaultResolver != system {
+		t.Error("StartFallbackDNS replaced net.DefaultResolver")
+	}
+	if p.fallbackDNS == nil || p.resolver() != p.fallbackDNS.resolver {
+		t.Error("mkube lookups do not use the fallback DNS resolver")
+	}
+}
@@ -0,0 +1,860 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/glennswest/mkube/pkg/store"
+)
+
+// ─── Garbage Collection ─────────────────────────────────────────────────────
+//
+// Deletion follows the Kubernetes model for every kind listed in gcKinds:
+//
+//   - metadata.ownerReferences tie a dependent to its owners. Deleting an
+//     owner cascades to its dependents according to the propagation policy
+//     (?propagationPolicy= or DeleteOptions in the request body):
+//     Background (default) deletes the owner, then its dependents;
+//     Foreground keeps the owner Terminating until its dependents are gone;
+//     Orphan strips the owner reference and leaves dependents in place.
+//   - metadata.finalizers hold an object in a Terminating state (with
+//     metadata.deletionTimestamp set) until each finalizer is removed.
+//     Built-in finalizers are attached when deletion starts and released by
+//     the garbage collector; any other finalizer is removed by its own
+//     controller with an update or patch.
+//
+// A DELETE that can complete immediately runs the kind's delete handler as
+// before. Otherwise it answers 202 Accepted with the Terminating object and
+// the garbage collector finishes the delete once the finalizers are gone.
+
+const (
+	finalizerForeground           = "foregroundDeletion"
+	finalizerNetworkProtection    = "mkube.io/network-protection"
+	finalizerPVCProtection        = "kubernetes.io/pvc-protection"
+	finalizerBootConfigProtection = "mkube.io/bootconfig-protection"
+	finalizerISCSITarget          = "mkube.io/iscsi-target"
+	finalizerBMHRelease           = "mkube.io/bmh-release"
+
+	// annotationDeletionQuery keeps the query of the original DELETE (for
+	// example ?deleteISO=true or ?purge=true) so it can be replayed when
+	// the finalizers have cleared.
+	annotationDeletionQuery = "mkube.io/deletion-query"
+
+	gcInterval = 10 * time.Second
+)
+
+// gcFinalizedKey marks a delete handler context as coming from the garbage
+// collector, after the object's finalizers have released its resources.
+type gcFinalizedKey struct{}
+
+// gcFinalized reports whether ctx belongs to a delete issued by the garbage
+// collector. Delete handlers skip cleanup already done by a finalizer.
+func gcFinalized(ctx context.Context) bool {
+	v, _ := ctx.Value(gcFinalizedKey{}).(bool)
+	return v
+}
+
+// gcKind describes how the garbage collector reaches objects of one kind.
+type gcKind struct {
+	kind       string
+	resource   string
+	namespaced bool
+	get        func(p *MicroKubeProvider, key string) metav1.Object
+	list       func(p *MicroKubeProvider) []metav1.Object
+	bucket     func(s *store.Store) *store.Bucket
+	del        func(p *MicroKubeProvider, w http.ResponseWriter, r *http.Request)
+
+	// finalizers are attached when deletion starts.
+	finalizers []string
+}
+
+func gcMapKind[T metav1.Object](kind, resource string, namespaced bool,
+	objects func(p *MicroKubeProvider) map[string]T,
+	bucket func(s *store.Store) *store.Bucket,
+	del func(p *MicroKubeProvider, w http.ResponseWriter, r *http.Request),
+	finalizers ...string) gcKind {
+	return gcKind{
+		kind:       kind,
+		resource:   resource,
+		namespaced: namespaced,
+		get: func(p *MicroKubeProvider, key string) metav1.Object {
+			if obj, ok := objects(p)[key]; ok {
+				return obj
+			}
+			return nil
+		},
+		list: func(p *MicroKubeProvider) []metav1.Object {
+			m := objects(p)
+			keys := sortedKeys(m)
+			out := make([]metav1.Object, 0, len(keys))
+			for _, k := range keys {
+				out = append(out, m[k])
+			}
+			return out
+		},
+		bucket:     bucket,
+		del:        del,
+		finalizers: finalizers,
+	}
+}
+
+var gcKinds = []gcKind{
+	gcMapKind("Pod", "pods", true,
+		func(p *MicroKubeProvider) map[string]*corev1.Pod { return p.pods },
+		func(s *store.Store) *store.Bucket { return s.Pods },
+		(*MicroKubeProvider).handleDeletePod),
+	gcMapKind("ConfigMap", "configmaps", true,
+		func(p *MicroKubeProvider) map[string]*corev1.ConfigMap { return p.configMaps },
+		func(s *store.Store) *store.Bucket { return s.ConfigMaps },
+		(*MicroKubeProvider).handleDeleteConfigMap),
+	gcMapKind("Secret", "secrets", true,
+		func(p *MicroKubeProvider) map[string]*corev1.Secret { return p.secrets },
+		func(s *store.Store) *store.Bucket { return s.Secrets },
+		(*MicroKubeProvider).handleDeleteSecret),
+	gcMapKind("Deployment", "deployments", true,
+		func(p *MicroKubeProvider) map[string]*Deployment { return p.deployments },
+		func(s *store.Store) *store.Bucket { return s.Deployments },
+		(*MicroKubeProvider).handleDeleteDeployment),
+	gcMapKind("PersistentVolumeClaim", "persistentvolumeclaims", true,
+		func(p *MicroKubeProvider) map[string]*corev1.PersistentVolumeClaim { return p.pvcs },
+		func(s *store.Store) *store.Bucket { return s.PersistentVolumeClaims },
+		(*MicroKubeProvider).handleDeletePVC, finalizerPVCProtection),
+	gcMapKind("BareMetalHost", "baremetalhosts", true,
+		func(p *MicroKubeProvider) map[string]*BareMetalHost { return p.bareMetalHosts },
+		func(s *store.Store) *store.Bucket { return s.BareMetalHosts },
+		(*MicroKubeProvider).handleDeleteBMH, finalizerBMHRelease),
+	gcMapKind("HostReservation", "hostreservations", true,
+		func(p *MicroKubeProvider) map[string]*HostReservation { return p.hostReservations },
+		func(s *store.Store) *store.Bucket { return s.HostReservations },
+		(*MicroKubeProvider).handleDeleteHostReservation),
+	gcMapKind("IPAddressClaim", "ipaddressclaims", true,
+		func(p *MicroKubeProvider) map[string]*IPAddressClaim { return p.ipAddressClaims },
+		func(s *store.Store) *store.Bucket { return s.IPAddressClaims },
+		(*MicroKubeProvider).handleDeleteIPAddressClaim),
+	gcMapKind("PortForward", "portforwards", true,
+		func(p *MicroKubeProvider) map[string]*PortForward { return p.portForwards },
+		func(s *store.Store) *store.Bucket { return s.PortForwards },
+		(*MicroKubeProvider).handleDeletePortForward),
+	gcMapKind("Job", "jobs", true,
+		func(p *MicroKubeProvider) map[string]*Job { return p.jobs },
+		func(s *store.Store) *store.Bucket { return s.Jobs },
+		(*MicroKubeProvider).handleDeleteJob),
+	gcMapKind("Network", "networks", false,
+		func(p *MicroKubeProvider) map[string]*Network { return p.networks },
+		func(s *store.Store) *store.Bucket { return s.Networks },
+		(*MicroKubeProvider).handleDeleteNetwork, finalizerNetworkProtection),
+	gcMapKind("Registry", "registries", false,
+		func(p *MicroKubeProvider) map[string]*Registry { return p.registries },
+		func(s *store.Store) *store.Bucket { return s.Registries },
+		(*MicroKubeProvider).handleDeleteRegistry),
+	gcMapKind("ISCSICdrom", "iscsi-cdroms", false,
+		func(p *MicroKubeProvider) map[string]*ISCSICdrom { return p.iscsiCdroms },
+		func(s *store.Store) *store.Bucket { return s.ISCSICdroms },
+		(*MicroKubeProvider).handleDeleteISCSICdrom, finalizerISCSITarget),
+	gcMapKind("BootConfig", "bootconfigs", false,
+		func(p *MicroKubeProvider) map[string]*BootConfig { return p.bootConfigs },
+		func(s *store.Store) *store.Bucket { return s.BootConfigs },
+		(*MicroKubeProvider).handleDeleteBootConfig, finalizerBootConfigProtection),
+	gcMapKind("JobRunner", "jobrunners", false,
+		func(p *MicroKubeProvider) map[string]*JobRunner { return p.jobRunners },
+		func(s *store.Store) *store.Bucket { return s.JobRunners },
+		(*MicroKubeProvider).handleDeleteJobRunner),
+	gcMapKind("AlertRule", "alertrules", false,
+		func(p *MicroKubeProvider) map[string]*AlertRule { return p.alertRules },
+		func(s *store.Store) *store.Bucket { return s.AlertRules },
+		(*MicroKubeProvider).handleDeleteAlertRule),
+	gcMapKind("AlertSilence", "alertsilences", false,
+		func(p *MicroKubeProvider) map[string]*AlertSilence { return p.alertSilences },
+		func(s *store.Store) *store.Bucket { return s.AlertSilences },
+		(*MicroKubeProvider).handleDeleteAlertSilence),
+	gcMapKind("MutatingWebhookConfiguration", "mutatingwebhookconfigurations", false,
+		func(p *MicroKubeProvider) map[string]*admissionregv1.MutatingWebhookConfiguration {
+			return p.mutatingWebhooks
+		},
+		func(s *store.Store) *store.Bucket { return s.MutatingWebhooks },
+		(*MicroKubeProvider).handleDeleteMutatingWebhookConfiguration),
+	gcMapKind("ValidatingWebhookConfiguration", "validatingwebhookconfigurations", false,
+		func(p *MicroKubeProvider) map[string]*admissionregv1.ValidatingWebhookConfiguration {
+			return p.validatingWebhooks
+		},
+		func(s *store.Store) *store.Bucket { return s.ValidatingWebhooks },
+		(*MicroKubeProvider).handleDeleteValidatingWebhookConfiguration),
+}
+
+// gcKindFor returns the garbage collector descriptor for an object kind.
+func gcKindFor(kind string) (*gcKind, bool) {
+	for i := range gcKinds {
+		if gcKinds[i].kind == kind {
+			return &gcKinds[i], true
+		}
+	}
+	return nil, false
+}
+
+// gcKindForResource returns the descriptor for a REST resource name.
+func gcKindForResource(resource string) (*gcKind, bool) {
+	for i := range gcKinds {
+		if gcKinds[i].resource == resource {
+			return &gcKinds[i], true
+		}
+	}
+	return nil, false
+}
+
+// mapKey returns the in-memory map key of obj.
+func (gk *gcKind) mapKey(obj metav1.Object) string {
+	if gk.namespaced {
+		return obj.GetNamespace() + "/" + obj.GetName()
+	}
+	return obj.GetName()
+}
+
+// storeKey returns the NATS key of obj.
+func (gk *gcKind) storeKey(obj metav1.Object) string {
+	if gk.namespaced {
+		return obj.GetNamespace() + "." + obj.GetName()
+	}
+	return obj.GetName()
+}
+
+// path returns the REST path of obj.
+func (gk *gcKind) path(obj metav1.Object) string {
+	if gk.namespaced {
+		return "/api/v1/namespaces/" + obj.GetNamespace() + "/" + gk.resource + "/" + obj.GetName()
+	}
+	return "/api/v1/" + gk.resource + "/" + obj.GetName()
+}
+
+// gcObject is an object together with its kind descriptor.
+type gcObject struct {
+	gk  *gcKind
+	obj metav1.Object
+}
+
+func (o gcObject) String() string {
+	return o.gk.kind + " " + o.gk.mapKey(o.obj)
+}
+
+// ─── Owner References ───────────────────────────────────────────────────────
+
+// ownerRefsOf returns the owner references of obj. Pods created before
+// ownerReferences existed carry only the deployment owner annotation, which
+// is treated as an implicit reference.
+func ownerRefsOf(obj metav1.Object) []metav1.OwnerReference {
+	refs := obj.GetOwnerReferences()
+	if pod, ok := obj.(*corev1.Pod); ok && pod.Annotations[annotationOwnerDeployment] != "" {
+		name := pod.Annotations[annotationOwnerDeployment]
+		for _, ref := range refs {
+			if ref.Kind == "Deployment" && ref.Name == name {
+				return refs
+			}
+		}
+		refs = append(slices.Clone(refs), metav1.OwnerReference{APIVersion: "v1", Kind: "Deployment", Name: name})
+	}
+	return refs
+}
+
+// refersTo reports whether ref, held by a dependent in namespace ns, points
+// at owner.
+func refersTo(ref metav1.OwnerReference, ns string, gk *gcKind, owner metav1.Object) bool {
+	if ref.Kind != gk.kind || ref.Name != owner.GetName() {
+		return false
+	}
+	if gk.namespaced && ns != owner.GetNamespace() {
+		return false
+	}
+	return ref.UID == "" || owner.GetUID() == "" || ref.UID == owner.GetUID()
+}
+
+// ownerExists reports whether the owner ref points at, as seen from a
+// dependent in namespace ns, still exists. References to kinds mkube does
+// not manage are assumed to exist.
+func (p *MicroKubeProvider) ownerExists(ns string, ref metav1.OwnerReference) bool {
+	gk, ok := gcKindFor(ref.Kind)
+	if !ok {
+		return true
+	}
+	key := ref.Name
+	if gk.namespaced {
+		key = ns + "/" + ref.Name
+	}
+	owner := gk.get(p, key)
+	return owner != nil && refersTo(ref, ns, gk, owner)
+}
+
+// dependentsOf returns every object with an owner reference to owner.
+func (p *MicroKubeProvider) dependentsOf(gk *gcKind, owner metav1.Object) []gcObject {
+	var deps []gcObject
+	for i := range gcKinds {
+		dk := &gcKinds[i]
+		if gk.namespaced && !dk.namespaced {
+			continue // cluster-scoped objects can't be owned by namespaced ones
+		}
+		for _, obj := range dk.list(p) {
+			for _, ref := range ownerRefsOf(obj) {
+				if refersTo(ref, obj.GetNamespace(), gk, owner) {
+					deps = append(deps, gcObject{dk, obj})
+					break
+				}
+			}
+		}
+	}
+	return deps
+}
+
+// removeOwnerRef strips the references to owner from dep and persists it.
+func (p *MicroKubeProvider) removeOwnerRef(ctx context.Context, dep gcObject, gk *gcKind, owner metav1.Object) {
+	var kept []metav1.OwnerReference
+	for _, ref := range dep.obj.GetOwnerReferences() {
+		if !refersTo(ref, dep.obj.GetNamespace(), gk, owner) {
+			kept = append(kept, ref)
+		}
+	}
+	dep.obj.SetOwnerReferences(kept)
+	if pod, ok := dep.obj.(*corev1.Pod); ok && gk.kind == "Deployment" &&
+		pod.Annotations[annotationOwnerDeployment] == owner.GetName() {
+		delete(pod.Annotations, annotationOwnerDeployment)
+	}
+	p.gcPersist(ctx, dep.gk, dep.obj)
+}
+
+// ─── Deletion ───────────────────────────────────────────────────────────────
+
+// deletePropagation reads the propagation policy of a DELETE request from
+// the query string or a DeleteOptions body.
+func deletePropagation(r *http.Request) (metav1.DeletionPropagation, error) {
+	policy := metav1.DeletionPropagation(r.URL.Query().Get("propagationPolicy"))
+	if policy == "" && r.Body != nil {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			return "", fmt.Errorf("reading body: %w", err)
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		if len(bytes.TrimSpace(body)) > 0 {
+			var opts metav1.DeleteOptions
+			if err := json.Unmarshal(body, &opts); err != nil {
+				return "", fmt.Errorf("invalid DeleteOptions: %w", err)
+			}
+			switch {
+			case opts.PropagationPolicy != nil:
+				policy = *opts.PropagationPolicy
+			case opts.OrphanDependents != nil && *opts.OrphanDependents:
+				policy = metav1.DeletePropagationOrphan
+			}
+		}
+	}
+	switch policy {
+	case "":
+		return metav1.DeletePropagationBackground, nil
+	case metav1.DeletePropagationBackground, metav1.DeletePropagationForeground, metav1.DeletePropagationOrphan:
+		return policy, nil
+	}
+	return "", fmt.Errorf("invalid propagationPolicy %q: must be Background, Foreground or Orphan", policy)
+}
+
+// gcTarget returns the object a write request addresses, if the garbage
+// collector manages its kind.
+func gcTarget(r *http.Request) (*gcKind, string, bool) {
+	ns, resource, name, ok := splitResourcePath(r.URL.Path)
+	if !ok || name == "" {
+		return nil, "", false
+	}
+	gk, ok := gcKindForResource(resource)
+	if !ok || gk.namespaced != (ns != "") {
+		return nil, "", false
+	}
+	if gk.namespaced {
+		return gk, ns + "/" + name, true
+	}
+	return gk, name, true
+}
+
+// serveWrite serves a write request under p.mu, applying ownerReference and
+// finalizer semantics to deletes and keeping updates from clearing the
+// deletionTimestamp of a Terminating object.
+func (p *MicroKubeProvider) serveWrite(w http.ResponseWriter, r *http.Request, h http.Handler) {
+	gk, key, ok := gcTarget(r)
+	if !ok {
+		h.ServeHTTP(w, r)
+		return
+	}
+	switch r.Method {
+	case http.MethodDelete:
+		p.serveDelete(w, r, h, gk, key)
+	case http.MethodPut, http.MethodPatch:
+		var deletedAt *metav1.Time
+		if obj := gk.get(p, key); obj != nil {
+			deletedAt = obj.GetDeletionTimestamp()
+		}
+		h.ServeHTTP(w, r)
+		if deletedAt == nil {
+			return
+		}
+		// Updates may remove finalizers but never cancel a deletion
+		if obj := gk.get(p, key); obj != nil {
+			if obj.GetDeletionTimestamp() == nil {
+				obj.SetDeletionTimestamp(deletedAt)
+				p.gcPersist(r.Context(), gk, obj)
+			}
+			p.kickGC()
+		}
+	default:
+		h.ServeHTTP(w, r)
+	}
+}
+
+// serveDelete handles DELETE for an object the garbage collector manages.
+func (p *MicroKubeProvider) serveDelete(w http.ResponseWriter, r *http.Request, h http.Handler, gk *gcKind, key string) {
+	obj := gk.get(p, key)
+	if obj == nil {
+		h.ServeHTTP(w, r) // the handler reports 404 or cleans up store-only leftovers
+		return
+	}
+	policy, err := deletePropagation(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	deps := p.dependentsOf(gk, obj)
+	if p.beginDeletion(r.Context(), gk, obj, policy, r.URL.Query(), deps) {
+		setObjectKind(obj, gk.kind)
+		podWriteJSON(w, http.StatusAccepted, obj)
+		return
+	}
+
+	// Built-in finalizers have already released the object's resources
+	r = r.WithContext(context.WithValue(r.Context(), gcFinalizedKey{}, true))
+	sw := &gcStatusWriter{ResponseWriter: w, code: http.StatusOK}
+	h.ServeHTTP(sw, r)
+	if sw.code < 300 && policy != metav1.DeletePropagationOrphan {
+		p.releaseDependents(r.Context(), gk, obj, deps)
+	}
+}
+
+// beginDeletion starts deleting obj. Orphaned dependents are released
+// immediately. If finalizers remain, obj is marked Terminating, persisted
+// and true is returned; false means the caller deletes obj right away.
+// Caller must hold p.mu.
+func (p *MicroKubeProvider) beginDeletion(ctx context.Context, gk *gcKind, obj metav1.Object,
+	policy metav1.DeletionPropagation, query url.Values, deps []gcObject) bool {
+	if obj.GetDeletionTimestamp() != nil {
+		return true
+	}
+
+	finalizers := obj.GetFinalizers()
+	for _, f := range gk.finalizers {
+		if !slices.Contains(finalizers, f) {
+			finalizers = append(finalizers, f)
+		}
+	}
+	switch policy {
+	case metav1.DeletePropagationOrphan:
+		for _, dep := range deps {
+			p.removeOwnerRef(ctx, dep, gk, obj)
+		}
+	case metav1.DeletePropagationForeground:
+		if len(deps) > 0 && !slices.Contains(finalizers, finalizerForeground) {
+			finalizers = append(finalizers, finalizerForeground)
+		}
+	}
+
+	// Built-in finalizers whose resources are already free don't need to
+	// hold the object.
+	var pending []string
+	for _, f := range finalizers {
+		if slices.Contains(gk.finalizers, f) && p.runFinalizer(ctx, f, obj) == nil {
+			continue
+		}
+		pending = append(pending, f)
+	}
+	if len(pending) == 0 {
+		return false
+	}
+
+	now := metav1.Now()
+	zero := int64(0)
+	obj.SetFinalizers(pending)
+	obj.SetDeletionTimestamp(&now)
+	obj.SetDeletionGracePeriodSeconds(&zero)
+	q := url.Values{}
+	for k, v := range query {
+		if k != "propagationPolicy" {
+			q[k] = v
+		}
+	}
+	if len(q) > 0 {
+		ann := obj.GetAnnotations()
+		if ann == nil {
+			ann = make(map[string]string)
+		}
+		ann[annotationDeletionQuery] = q.Encode()
+		obj.SetAnnotations(ann)
+	}
+	p.gcPersist(ctx, gk, obj)
+
+	p.deps.Logger.Infow("object terminating", "kind", gk.kind, "object", gk.mapKey(obj), "finalizers", pending)
+	p.recordGCEvent(gk, obj, "Terminating",
+		fmt.Sprintf("waiting for finalizers: %s", strings.Join(pending, ", ")), "Normal")
+	p.kickGC()
+	return true
+}
+
+// deleteObject deletes obj with the given propagation policy, either at
+// once or by marking it Terminating. Caller must hold p.mu.
+func (p *MicroKubeProvider) deleteObject(ctx context.Context, gk *gcKind, obj metav1.Object, policy metav1.DeletionPropagation) error {
+	deps := p.dependentsOf(gk, obj)
+	if p.beginDeletion(ctx, gk, obj, policy, nil, deps) {
+		return nil
+	}
+	if err := p.finalDelete(ctx, gk, obj); err != nil {
+		return err
+	}
+	if policy != metav1.DeletePropagationOrphan {
+		p.releaseDependents(ctx, gk, obj, deps)
+	}
+	return nil
+}
+
+// finalDelete removes obj through its kind's delete handler, replaying the
+// query of the original request. Caller must hold p.mu.
+func (p *MicroKubeProvider) finalDelete(ctx context.Context, gk *gcKind, obj metav1.Object) error {
+	target := gk.path(obj)
+	if q := obj.GetAnnotations()[annotationDeletionQuery]; q != "" {
+		target += "?" + q
+	}
+	req := httptest.NewRequest(http.MethodDelete, target, nil).WithContext(context.WithValue(ctx, gcFinalizedKey{}, true))
+	req.SetPathValue("namespace", obj.GetNamespace())
+	req.SetPathValue("name", obj.GetName())
+
+	rec := httptest.NewRecorder()
+	gk.del(p, rec, req)
+	if rec.Code >= 300 && rec.Code != http.StatusNotFound {
+		return fmt.Errorf("%s", strings.TrimSpace(rec.Body.String()))
+	}
+	p.deps.Logger.Infow("object deleted", "kind", gk.kind, "object", gk.mapKey(obj))
+	return nil
+}
+
+// releaseDependents runs after owner has been deleted. Dependents left
+// without a live owner are deleted in the background; dependents with
+// other owners only lose the reference. Caller must hold p.mu.
+func (p *MicroKubeProvider) releaseDependents(ctx context.Context, gk *gcKind, owner metav1.Object, deps []gcObject) {
+	for _, dep := range deps {
+		if dep.gk.get(p, dep.gk.mapKey(dep.obj)) == nil || dep.obj.GetDeletionTimestamp() != nil {
+			continue
+		}
+		if p.hasOtherOwner(dep, gk, owner) {
+			p.removeOwnerRef(ctx, dep, gk, owner)
+			continue
+		}
+		p.deps.Logger.Infow("deleting dependent", "owner", gk.kind+" "+gk.mapKey(owner), "dependent", dep.String())
+		if err := p.deleteObject(ctx, dep.gk, dep.obj, metav1.DeletePropagationBackground); err != nil {
+			p.deps.Logger.Warnw("failed to delete dependent", "dependent", dep.String(), "error", err)
+		}
+	}
+}
+
+// hasOtherOwner reports whether dep has a live owner other than owner.
+func (p *MicroKubeProvider) hasOtherOwner(dep gcObject, gk *gcKind, owner metav1.Object) bool {
+	ns := dep.obj.GetNamespace()
+	for _, ref := range ownerRefsOf(dep.obj) {
+		if !refersTo(ref, ns, gk, owner) && p.ownerExists(ns, ref) {
+			return true
+		}
+	}
+	return false
+}
+
+// ─── Finalizers ─────────────────────────────────────────────────────────────
+
+// runFinalizer releases what a built-in finalizer protects. A nil error
+// means the finalizer can be removed; otherwise the error says what the
+// object is waiting for.
+func (p *MicroKubeProvider) runFinalizer(ctx context.Context, finalizer string, obj metav1.Object) error {
+	switch finalizer {
+	case finalizerNetworkProtection:
+		for _, key := range sortedKeys(p.pods) {
+			pod := p.pods[key]
+			if pod.Annotations[annotationNetwork] == obj.GetName() && !p.isManagedDNSPod(pod) {
+				return fmt.Errorf("network in use by pod %s", key)
+			}
+		}
+		return nil
+
+	case finalizerPVCProtection:
+		for _, key := range sortedKeys(p.pods) {
+			pod := p.pods[key]
+			if pod.Namespace != obj.GetNamespace() {
+				continue
+			}
+			for _, v := range pod.Spec.Volumes {
+				if v.PersistentVolumeClaim != nil && v.PersistentVolumeClaim.ClaimName == obj.GetName() {
+					return fmt.Errorf("PVC in use by pod %s", key)
+				}
+			}
+		}
+		return nil
+
+	case finalizerBootConfigProtection:
+		if bc, ok := obj.(*BootConfig); ok && len(bc.Status.AssignedTo) > 0 {
+			return fmt.Errorf("referenced by BareMetalHost(s) %s", strings.Join(bc.Status.AssignedTo, ", "))
+		}
+		return nil
+
+	case finalizerISCSITarget:
+		cdrom, ok := obj.(*ISCSICdrom)
+		if !ok {
+			return nil
+		}
+		if n := len(cdrom.Status.Subscribers); n > 0 {
+			return fmt.Errorf("%d active subscriber(s)", n)
+		}
+		p.removeISCSITarget(ctx, cdrom)
+		return nil
+
+	case finalizerBMHRelease:
+		bmh, ok := obj.(*BareMetalHost)
+		if !ok {
+			return nil
+		}
+		return p.releaseBMH(ctx, bmh)
+	}
+	return fmt.Errorf("unknown finalizer %q", finalizer)
+}
+
+// isManagedDNSPod reports whether pod is the microdns pod mkube deploys for
+// a managed network. It goes away with the network and never blocks it.
+func (p *MicroKubeProvider) isManagedDNSPod(pod *corev1.Pod) bool {
+	net, ok := p.networks[pod.Namespace]
+	return ok && net.Spec.Managed && pod.Name == "dns"
+}
+
+// finalizeObject advances a Terminating object: it releases built-in
+// finalizers, drives foreground deletion of dependents and deletes the
+// object once no finalizers remain. Caller must hold p.mu.
+func (p *MicroKubeProvider) finalizeObject(ctx context.Context, gk *gcKind, obj metav1.Object) {
+	var remaining, waiting []string
+	for _, f := range obj.GetFinalizers() {
+		switch {
+		case f == finalizerForeground:
+			left := 0
+			for _, dep := range p.dependentsOf(gk, obj) {
+				if dep.obj.GetDeletionTimestamp() == nil {
+					if p.hasOtherOwner(dep, gk, obj) {
+						p.removeOwnerRef(ctx, dep, gk, obj)
+						continue
+					}
+					if err := p.deleteObject(ctx, dep.gk, dep.obj, metav1.DeletePropagationForeground); err != nil {
+						p.deps.Logger.Warnw("failed to delete dependent", "dependent", dep.String(), "error", err)
+					}
+				}
+				if dep.gk.get(p, dep.gk.mapKey(dep.obj)) != nil {
+					left++
+				}
+			}
+			if left > 0 {
+				remaining = append(remaining, f)
+				waiting = append(waiting, fmt.Sprintf("%s: %d dependent(s) remaining", f, left))
+			}
+		case slices.Contains(gk.finalizers, f):
+			if err := p.runFinalizer(ctx, f, obj); err != nil {
+				remaining = append(remaining, f)
+				waiting = append(waiting, fmt.Sprintf("%s: %v", f, err))
+			}
+		default:
+			// Removed by the controller that added it
+			remaining = append(remaining, f)
+			waiting = append(waiting, f)
+		}
+	}
+
+	pendingKey := gk.kind + "/" + gk.mapKey(obj)
+	if len(remaining) > 0 {
+		if len(remaining) != len(obj.GetFinalizers()) {
+			obj.SetFinalizers(remaining)
+			p.gcPersist(ctx, gk, obj)
+		}
+		msg := "waiting for " + strings.Join(waiting, "; ")
+		if p.gcPending[pendingKey] != msg {
+			p.gcPending[pendingKey] = msg
+			p.recordGCEvent(gk, obj, "FinalizerPending", msg, "Normal")
+		}
+		return
+	}
+
+	obj.SetFinalizers(nil)
+	deps := p.dependentsOf(gk, obj)
+	if err := p.finalDelete(ctx, gk, obj); err != nil {
+		msg := "delete failed: " + err.Error()
+		if p.gcPending[pendingKey] != msg {
+			p.gcPending[pendingKey] = msg
+			p.deps.Logger.Warnw("finalized delete failed", "kind", gk.kind, "object", gk.mapKey(obj), "error", err)
+			p.recordGCEvent(gk, obj, "DeleteFailed", msg, "Warning")
+		}
+		return
+	}
+	delete(p.gcPending, pendingKey)
+	p.releaseDependents(ctx, gk, obj, deps)
+}
+
+// ─── Collector ──────────────────────────────────────────────────────────────
+
+// RunGarbageCollector periodically finishes the deletion of Terminating
+// objects and removes dependents whose owners are gone.
+func (p *MicroKubeProvider) RunGarbageCollector(ctx context.Context) {
+	log := p.deps.Logger.Named("gc")
+	log.Infow("garbage collector starting", "interval", gcInterval)
+
+	ticker := time.NewTicker(gcInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("garbage collector stopping")
+			return
+		case <-ticker.C:
+		case <-p.gcKick:
+		}
+		p.mu.Lock()
+		p.collectGarbage(ctx)
+		p.mu.Unlock()
+	}
+}
+
+// kickGC asks the garbage collector to run a pass soon.
+func (p *MicroKubeProvider) kickGC() {
+	select {
+	case p.gcKick <- struct{}{}:
+	default:
+	}
+}
+
+// collectGarbage runs one garbage collection pass. Caller must hold p.mu.
+func (p *MicroKubeProvider) collectGarbage(ctx context.Context) {
+	for i := range gcKinds {
+		gk := &gcKinds[i]
+		for _, obj := range gk.list(p) {
+			if obj.GetDeletionTimestamp() != nil && gk.get(p, gk.mapKey(obj)) != nil {
+				p.finalizeObject(ctx, gk, obj)
+			}
+		}
+	}
+
+	// Only sweep orphans once the store has been read, so owners that
+	// haven't loaded yet aren't mistaken for deleted ones.
+	if p.deps.Store != nil && !p.deps.Store.Connected() {
+		return
+	}
+	for i := range gcKinds {
+		gk := &gcKinds[i]
+		for _, obj := range gk.list(p) {
+			refs := obj.GetOwnerReferences()
+			if len(refs) == 0 || obj.GetDeletionTimestamp() != nil || gk.get(p, gk.mapKey(obj)) == nil {
+				continue
+			}
+			var live []metav1.OwnerReference
+			for _, ref := range refs {
+				if p.ownerExists(obj.GetNamespace(), ref) {
+					live = append(live, ref)
+				}
+			}
+			switch {
+			case len(live) == 0:
+				dep := gcObject{gk, obj}
+				p.deps.Logger.Infow("deleting orphaned dependent", "dependent", dep.String())
+				if err := p.deleteObject(ctx, gk, obj, metav1.DeletePropagationBackground); err != nil {
+					p.deps.Logger.Warnw("failed to delete orphaned dependent", "dependent", dep.String(), "error", err)
+				}
+			case len(live) < len(refs):
+				obj.SetOwnerReferences(live)
+				p.gcPersist(ctx, gk, obj)
+			}
+		}
+	}
+}
+
+// gcPersist writes obj back to its NATS bucket.
+func (p *MicroKubeProvider) gcPersist(ctx context.Context, gk *gcKind, obj metav1.Object) {
+	if p.deps.Store == nil {
+		return
+	}
+	bucket := gk.bucket(p.deps.Store)
+	if bucket == nil {
+		return
+	}
+	if _, err := bucket.PutJSON(ctx, gk.storeKey(obj), obj); err != nil {
+		p.deps.Logger.Warnw("failed to persist object", "kind", gk.kind, "key", gk.storeKey(obj), "error", err)
+	}
+}
+
+// setObjectKind fills in apiVersion and kind if obj doesn't carry them.
+func setObjectKind(obj metav1.Object, kind string) {
+	o, ok := obj.(interface{ GetObjectKind() schema.ObjectKind })
+	if !ok || o.GetObjectKind().GroupVersionKind().Kind != "" {
+		return
+	}
+	o.GetObjectKind().SetGroupVersionKind(schema.GroupVersionKind{Version: "v1", Kind: kind})
+}
+
+// sortedKeys returns the keys of m in order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// gcStatusWriter records the status code written by a delete handler.
+type gcStatusWriter struct {
+	http.ResponseWriter
+	code int
+}
+
+func (w *gcStatusWriter) WriteHeader(code int) {
+	w.code = code
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// recordGCEvent appends an event about a deletion to the event ring buffer.
+func (p *MicroKubeProvider) recordGCEvent(gk *gcKind, obj metav1.Object, reason, message, eventType string) {
+	now := metav1.Now()
+	p.appendEvent(corev1.Event{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Event"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              fmt.Sprintf("%s.%x", obj.GetName(), now.UnixNano()),
+			Namespace:         obj.GetNamespace(),
+			CreationTimestamp: now,
+		},
+		InvolvedObject: corev1.ObjectReference{
+			Kind:      gk.kind,
+			Name:      obj.GetName(),
+			Namespace: obj.GetNamespace(),
+		},
+		Reason:         reason,
+		Message:        message,
+		Type:           eventType,
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+		Source:         corev1.EventSource{Component: "mkube-gc", Host: p.nodeName},
+	})
+}
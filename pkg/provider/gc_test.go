@@ -0,0 +1,194 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// newGCTestProvider returns a provider with a deployment "default/web" that
+// owns pods web-0 and web-1, and a handler serving the full API.
+func newGCTestProvider(t *testing.T) (*MicroKubeProvider, func(method, path, body string) *httptest.ResponseRecorder) {
+	t.Helper()
+	p, _ := newTestProvider(t)
+	ctx := context.Background()
+
+	deploy := &Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"}}
+	p.deployments["default/web"] = deploy
+	for _, name := range []string{"web-0", "web-1"} {
+		pod := p.podFromDeployment(deploy, name)
+		pod.Spec.Containers = []corev1.Container{{Name: "app", Image: "app:1"}}
+		pod.Annotations["vkube.io/file"] = "/dev/null"
+		if err := p.CreatePod(ctx, pod); err != nil {
+			t.Fatalf("CreatePod: %v", err)
+		}
+	}
+
+	mux := http.NewServeMux()
+	p.RegisterRoutes(mux)
+	h := p.WrapHandler(mux)
+	return p, func(method, path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rec
+	}
+}
+
+func TestDeletePropagation(t *testing.T) {
+	tests := []struct {
+		query, body string
+		want        metav1.DeletionPropagation
+		wantErr     bool
+	}{
+		{"", "", metav1.DeletePropagationBackground, false},
+		{"?propagationPolicy=Foreground", "", metav1.DeletePropagationForeground, false},
+		{"", `{"kind":"DeleteOptions","propagationPolicy":"Orphan"}`, metav1.DeletePropagationOrphan, false},
+		{"", `{"orphanDependents":true}`, metav1.DeletePropagationOrphan, false},
+		{"?propagationPolicy=Sometimes", "", "", true},
+		{"", `{not json`, "", true},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodDelete, "/api/v1/networks/g10"+tt.query, strings.NewReader(tt.body))
+		got, err := deletePropagation(r)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("%q %q: got %q, %v; want %q (err %v)", tt.query, tt.body, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestDeleteDeploymentBackground(t *testing.T) {
+	p, do := newGCTestProvider(t)
+
+	// A pod with a second, live owner only loses the reference
+	p.configMaps["default/settings"] = &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "settings", Namespace: "default"}}
+	shared := p.pods["default/web-1"]
+	shared.OwnerReferences = append(shared.OwnerReferences, metav1.OwnerReference{Kind: "ConfigMap", Name: "settings"})
+
+	if rec := do(http.MethodDelete, "/api/v1/namespaces/default/deployments/web", ""); rec.Code != http.StatusOK {
+		t.Fatalf("delete: %d (%s)", rec.Code, rec.Body.String())
+	}
+	if _, ok := p.deployments["default/web"]; ok {
+		t.Error("deployment still present")
+	}
+	if _, ok := p.pods["default/web-0"]; ok {
+		t.Error("owned pod web-0 not deleted")
+	}
+	pod, ok := p.pods["default/web-1"]
+	if !ok {
+		t.Fatal("pod with a second owner was deleted")
+	}
+	if refs := pod.OwnerReferences; len(refs) != 1 || refs[0].Kind != "ConfigMap" {
+		t.Errorf("expected only the ConfigMap reference to remain, got %+v", refs)
+	}
+}
+
+func TestDeleteDeploymentOrphan(t *testing.T) {
+	p, do := newGCTestProvider(t)
+
+	if rec := do(http.MethodDelete, "/api/v1/namespaces/default/deployments/web?propagationPolicy=Orphan", ""); rec.Code != http.StatusOK {
+		t.Fatalf("delete: %d (%s)", rec.Code, rec.Body.String())
+	}
+	for _, name := range []string{"web-0", "web-1"} {
+		pod, ok := p.pods["default/"+name]
+		if !ok {
+			t.Fatalf("orphaned pod %s deleted", name)
+		}
+		if len(ownerRefsOf(pod)) != 0 {
+			t.Errorf("pod %s still owned: %+v", name, ownerRefsOf(pod))
+		}
+	}
+}
+
+func TestDeleteDeploymentForeground(t *testing.T) {
+	p, do := newGCTestProvider(t)
+
+	rec := do(http.MethodDelete, "/api/v1/namespaces/default/deployments/web", `{"propagationPolicy":"Foreground"}`)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d (%s)", rec.Code, rec.Body.String())
+	}
+	deploy := p.deployments["default/web"]
+	if deploy == nil || deploy.DeletionTimestamp == nil || deploy.Finalizers[0] != finalizerForeground {
+		t.Fatalf("deployment not terminating: %+v", deploy)
+	}
+	if len(p.pods) != 2 {
+		t.Fatalf("pods deleted before the collector ran: %d left", len(p.pods))
+	}
+
+	p.collectGarbage(context.Background())
+	if len(p.pods) != 0 {
+		t.Errorf("%d pods left after foreground deletion", len(p.pods))
+	}
+	if _, ok := p.deployments["default/web"]; ok {
+		t.Error("deployment not deleted once its pods were gone")
+	}
+}
+
+func TestFinalizerHoldsObject(t *testing.T) {
+	p, do := newGCTestProvider(t)
+	cm := `{"metadata":{"name":"app","namespace":"default","finalizers":["example.com/cleanup"]},"data":{"k":"v"}}`
+	if rec := do(http.MethodPost, "/api/v1/namespaces/default/configmaps", cm); rec.Code != http.StatusCreated {
+		t.Fatalf("create: %d (%s)", rec.Code, rec.Body.String())
+	}
+
+	if rec := do(http.MethodDelete, "/api/v1/namespaces/default/configmaps/app", ""); rec.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d (%s)", rec.Code, rec.Body.String())
+	}
+	p.collectGarbage(context.Background())
+	if obj, ok := p.configMaps["default/app"]; !ok || obj.DeletionTimestamp == nil {
+		t.Fatal("ConfigMap with a pending finalizer should stay Terminating")
+	}
+
+	// An update can't cancel the deletion, but removing the finalizer ends it
+	update := `{"metadata":{"name":"app","namespace":"default"},"data":{"k":"v2"}}`
+	if rec := do(http.MethodPut, "/api/v1/namespaces/default/configmaps/app", update); rec.Code != http.StatusOK {
+		t.Fatalf("update: %d (%s)", rec.Code, rec.Body.String())
+	}
+	if obj := p.configMaps["default/app"]; obj == nil || obj.DeletionTimestamp == nil {
+		t.Fatal("update cleared the deletionTimestamp")
+	}
+	p.collectGarbage(context.Background())
+	if _, ok := p.configMaps["default/app"]; ok {
+		t.Error("ConfigMap not deleted after its finalizer was removed")
+	}
+}
+
+func TestNetworkProtection(t *testing.T) {
+	p, do := newGCTestProvider(t)
+	p.networks["g10"] = &Network{ObjectMeta: metav1.ObjectMeta{Name: "g10"}}
+	pod := p.pods["default/web-0"]
+	pod.Annotations[annotationNetwork] = "g10"
+
+	rec := do(http.MethodDelete, "/api/v1/networks/g10", "")
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d (%s)", rec.Code, rec.Body.String())
+	}
+	p.collectGarbage(context.Background())
+	if net, ok := p.networks["g10"]; !ok || net.Finalizers[0] != finalizerNetworkProtection {
+		t.Fatal("network in use should stay Terminating")
+	}
+	if !strings.Contains(p.gcPending["Network/g10"], "default/web-0") {
+		t.Errorf("pending reason should name the pod, got %q", p.gcPending["Network/g10"])
+	}
+
+	delete(pod.Annotations, annotationNetwork)
+	p.collectGarbage(context.Background())
+	if _, ok := p.networks["g10"]; ok {
+		t.Error("network not deleted once unused")
+	}
+}
+
+func TestCollectOrphanedDependents(t *testing.T) {
+	p, _ := newGCTestProvider(t)
+
+	// Deployment vanished without a cascade (e.g. removed from the store)
+	delete(p.deployments, "default/web")
+	p.collectGarbage(context.Background())
+	if len(p.pods) != 0 {
+		t.Errorf("%d pods of a missing deployment left", len(p.pods))
+	}
+}
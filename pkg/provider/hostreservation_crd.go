@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"sort"
 	"time"
@@ -172,8 +171,7 @@ func (p *MicroKubeProvider) handleCreateHostReservation(w http.ResponseWriter, r
 	ns := r.PathValue("namespace")
 
 	var hr HostReservation
-	if err := json.NewDecoder(r.Body).Decode(&hr); err != nil {
-		http.Error(w, fmt.Sprintf("invalid HostReservation JSON: %v", err), http.StatusBadRequest)
+	if !decodeCRDBody(w, r, "HostReservation", false, &hr) {
 		return
 	}
 
@@ -190,31 +188,19 @@ func (p *MicroKubeProvider) handleCreateHostReservation(w http.ResponseWriter, r
 		return
 	}
 
-	// Validate BMHRef
-	if hr.Spec.BMHRef == "" {
-		http.Error(w, "spec.bmhRef is required", http.StatusBadRequest)
-		return
-	}
-
-	if hr.Spec.Pool == "" {
-		http.Error(w, "spec.pool is required", http.StatusBadRequest)
+	hr.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "HostReservation"}
+	if !p.admitCRD(w, &hr, nil) {
 		return
 	}
 
-	// Check BMH exists
-	bmhFound := false
+	// Resolve BMH network/IP into status
 	for _, bmh := range p.bareMetalHosts {
 		if bmh.Name == hr.Spec.BMHRef {
-			bmhFound = true
 			hr.Status.BMHNetwork = bmh.Spec.Network
 			hr.Status.BMHIP = bmh.Spec.IP
 			break
 		}
 	}
-	if !bmhFound {
-		http.Error(w, fmt.Sprintf("BareMetalHost %q not found", hr.Spec.BMHRef), http.StatusBadRequest)
-		return
-	}
 
 	// Check no other reservation references same BMH
 	for _, existing := range p.hostReservations {
@@ -225,7 +211,6 @@ func (p *MicroKubeProvider) handleCreateHostReservation(w http.ResponseWriter, r
 		}
 	}
 
-	hr.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "HostReservation"}
 	if hr.CreationTimestamp.IsZero() {
 		hr.CreationTimestamp = metav1.Now()
 	}
@@ -254,13 +239,15 @@ func (p *MicroKubeProvider) handleUpdateHostReservation(w http.ResponseWriter, r
 	}
 
 	var hr HostReservation
-	if err := json.NewDecoder(r.Body).Decode(&hr); err != nil {
-		http.Error(w, fmt.Sprintf("invalid HostReservation JSON: %v", err), http.StatusBadRequest)
+	if !decodeCRDBody(w, r, "HostReservation", false, &hr) {
 		return
 	}
 	hr.Name = name
 	hr.Namespace = ns
 	hr.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "HostReservation"}
+	if !p.admitCRD(w, &hr, old) {
+		return
+	}
 
 	if hr.CreationTimestamp.IsZero() {
 		hr.CreationTimestamp = old.CreationTimestamp
@@ -288,19 +275,16 @@ func (p *MicroKubeProvider) handlePatchHostReservation(w http.ResponseWriter, r
 
 	merged := existing.DeepCopy()
 
-	body, err := io.ReadAll(r.Body)
-	if err != nil {
-		http.Error(w, fmt.Sprintf("reading body: %v", err), http.StatusBadRequest)
-		return
-	}
-	if err := json.Unmarshal(body, merged); err != nil {
-		http.Error(w, fmt.Sprintf("invalid patch JSON: %v", err), http.StatusBadRequest)
+	if !decodeCRDBody(w, r, "HostReservation", true, merged) {
 		return
 	}
 	merged.Name = name
 	merged.Namespace = ns
 	merged.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "HostReservation"}
 	merged.CreationTimestamp = existing.CreationTimestamp
+	if !p.admitCRD(w, merged, existing) {
+		return
+	}
 
 	p.persistHostReservation(r.Context(), merged)
 	p.hostReservations[key] = merged
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -169,13 +170,17 @@ const dnsHealthFailureThreshold = 3
 // checkInfraHealth is the polling fallback called from the reconcile loop.
 // It checks microdns REST API and port 53 for each managed network. If both
 // are dead, it triggers immediate repair (restart or recreate) instead of
-// waiting for the next consistency check cycle.
+// waiting for the next consistency check cycle. While port 53 of any
+// microdns stays dead, mkube's fallback responder answers for the zones.
 func (p *MicroKubeProvider) checkInfraHealth(ctx context.Context) {
 	dnsClient := p.deps.NetworkMgr.DNSClient()
 	if dnsClient == nil {
 		return
 	}
 
+	dnsAlive := make(map[string]bool) // network -> port 53 answering
+	defer func() { p.updateFallbackDNS(dnsAlive) }()
+
 	for _, netObj := range p.networks {
 		if netObj.Spec.ExternalDNS || netObj.Spec.DNS.Zone == "" || netObj.Spec.DNS.Server == "" {
 			continue
@@ -188,6 +193,7 @@ func (p *MicroKubeProvider) checkInfraHealth(ctx context.Context) {
 
 		// REST API healthy → microdns is alive, reset failure counter
 		if err := dnsClient.HealthCheck(ctx, endpoint); err == nil {
+			dnsAlive[netObj.Name] = probeDNSPort(netObj.Spec.DNS.Server, netObj.Spec.DNS.Zone, 3*time.Second)
 			infraMu.Lock()
 			delete(dnsHealthFailures, netObj.Name)
 			infraMu.Unlock()
@@ -198,6 +204,7 @@ func (p *MicroKubeProvider) checkInfraHealth(ctx context.Context) {
 		if probeDNSPort(netObj.Spec.DNS.Server, netObj.Spec.DNS.Zone, 3*time.Second) {
 			// Port 53 is alive but REST API is down — unusual but not critical.
 			// DNS resolution still works; DHCP management is impaired.
+			dnsAlive[netObj.Name] = true
 			p.deps.Logger.Warnw("microdns REST API down but port 53 alive",
 				"network", netObj.Name, "endpoint", endpoint)
 			continue
@@ -205,6 +212,7 @@ func (p *MicroKubeProvider) checkInfraHealth(ctx context.Context) {
 
 		// Both REST API and port 53 are dead — track consecutive failures
 		// and trigger immediate repair.
+		dnsAlive[netObj.Name] = false
 		infraMu.Lock()
 		dnsHealthFailures[netObj.Name]++
 		failures := dnsHealthFailures[netObj.Name]
@@ -290,7 +298,7 @@ func (p *MicroKubeProvider) checkPodPortHealth(ctx context.Context) {
 			// Probe each declared TCP port
 			anyReachable := false
 			for _, port := range tcpPorts {
-				addr := fmt.Sprintf("%s:%d", podIP, port)
+				addr := net.JoinHostPort(podIP, strconv.Itoa(int(port)))
 				conn, err := net.DialTimeout("tcp", addr, 3*time.Second)
 				if err == nil {
 					conn.Close()
@@ -6,6 +6,7 @@ import (
 	"io"
 	"sync"
 	"testing"
+	"time"
 
 	"go.uber.org/zap"
 	corev1 "k8s.io/api/core/v1"
@@ -111,7 +112,14 @@ func (m *mockRuntime) Close() error
 
 // ─── Mock Network Driver ─────────────────────────────────────────────────────
 
-type mockNetworkDriver struct{}
+type mockNetworkDriver struct {
+	forwards  []network.PortForward // dst-nat rules, IDs "1", "2", ...
+	nextID    int
+	failAdd   map[string]bool              // owners whose AddPortForward fails
+	onList    func()                       // called by ListPortForwards, e.g. to block it
+	shaped    map[string]network.Bandwidth // port -> limit from SetPortBandwidth
+	rateReads int                          // PortRates calls
+}
 
 func (d *mockNetworkDriver) CreateBridge(context.Context, string, network.BridgeOpts) error {
 	return nil
@@ -135,6 +143,45 @@ func (d *mockNetworkDriver) NodeName() string                           { return
 func (d *mockNetworkDriver) Capabilities() network.DriverCapabilities {
 	return network.DriverCapabilities{}
 }
+func (d *mockNetworkDriver) ListPortForwards(context.Context) ([]network.PortForward, error) {
+	if d.onList != nil {
+		d.onList()
+	}
+	return append([]network.PortForward(nil), d.forwards...), nil
+}
+func (d *mockNetworkDriver) AddPortForward(_ context.Context, pf network.PortForward) error {
+	if d.failAdd[pf.Owner] {
+		return fmt.Errorf("port %d in use", pf.ExternalPort)
+	}
+	d.nextID++
+	pf.ID = fmt.Sprint(d.nextID)
+	d.forwards = append(d.forwards, pf)
+	return nil
+}
+func (d *mockNetworkDriver) RemovePortForward(_ context.Context, pf network.PortForward) error {
+	for i, f := range d.forwards {
+		if f.ID == pf.ID {
+			d.forwards = append(d.forwards[:i], d.forwards[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("rule %s not found", pf.ID)
+}
+func (d *mockNetworkDriver) SetPortBandwidth(_ context.Context, port, _ string, limit network.Bandwidth) error {
+	if d.shaped == nil {
+		d.shaped = make(map[string]network.Bandwidth)
+	}
+	d.shaped[port] = limit
+	return nil
+}
+func (d *mockNetworkDriver) ClearPortBandwidth(_ context.Context, port string) error {
+	delete(d.shaped, port)
+	return nil
+}
+func (d *mockNetworkDriver) PortRates(_ context.Context, port string) (network.Bandwidth, error) {
+	d.rateReads++
+	return network.Bandwidth{Ingress: d.shaped[port].Ingress / 2}, nil
+}
 
 // ─── Test Helper ─────────────────────────────────────────────────────────────
 
@@ -177,6 +224,7 @@ func newTestProvider(t *testing.T) (*MicroKubeProvider, *mockRuntime) {
 	if err != nil {
 		t.Fatalf("NewMicroKubeProvider: %v", err)
 	}
+	p.dnsTTLDrain = func(context.Context, time.Duration) {} // rollouts don't wait out DNS TTLs in tests
 
 	return p, rt
 }
@@ -0,0 +1,593 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kruntime "k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/glennswest/mkube/pkg/store"
+)
+
+// ─── Types ──────────────────────────────────────────────────────────────────
+
+// IPAddressClaim is a namespaced CRD that reserves an address from a
+// Network for something other than a running pod (VIP, appliance, an
+// address promised to a future pod). Claims are kept in the node's own
+// IPADDRESSCLAIMS bucket, which is not synced to peers: a claim reserves
+// its address on the node it was created on, and on a network spanning
+// nodes only addresses in that node's IPAM slice can be claimed.
+type IPAddressClaim struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              IPAddressClaimSpec   `json:"spec"`
+	Status            IPAddressClaimStatus `json:"status,omitempty"`
+}
+
+// IPAddressClaimSpec defines the desired state of an IPAddressClaim.
+type IPAddressClaimSpec struct {
+	Network  string `json:"network"`            // Network name (must exist)
+	IP       string `json:"ip,omitempty"`       // specific address; empty = next free in the IPAM range
+	Hostname string `json:"hostname,omitempty"` // registered as an A/AAAA record in the network's zone
+	Purpose  string `json:"purpose,omitempty"`  // human-readable
+}
+
+// IPAddressClaimStatus reports the observed state of an IPAddressClaim.
+type IPAddressClaimStatus struct {
+	Phase   string `json:"phase"`             // Bound, Failed
+	IP      string `json:"ip,omitempty"`      // reserved address
+	BoundAt string `json:"boundAt,omitempty"` // RFC3339
+	Message string `json:"message,omitempty"` // why binding failed
+}
+
+// IPAddressClaimList is a list of IPAddressClaim objects.
+type IPAddressClaimList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+	Items           []IPAddressClaim `json:"items"`
+}
+
+// DeepCopy returns a deep copy of the IPAddressClaim.
+func (c *IPAddressClaim) DeepCopy() *IPAddressClaim {
+	out := *c
+	out.ObjectMeta = *c.ObjectMeta.DeepCopy()
+	return &out
+}
+
+// reservationKey is the owner recorded in IPAM and NATS for the claim.
+func (c *IPAddressClaim) reservationKey() string {
+	return "ipaddressclaim/" + c.Namespace + "/" + c.Name
+}
+
+// ─── Binding ────────────────────────────────────────────────────────────────
+
+// bindIPAddressClaim reserves the claim's address in the network manager
+// and records the outcome in status. A claim that was bound before keeps
+// its address across restarts even when spec.ip is empty.
+func (p *MicroKubeProvider) bindIPAddressClaim(ctx context.Context, c *IPAddressClaim) error {
+	if p.deps.NetworkMgr == nil {
+		return fmt.Errorf("network manager not available")
+	}
+	want := c.Spec.IP
+	if want == "" {
+		want = c.Status.IP
+	}
+	ip, err := p.deps.NetworkMgr.ReserveAddress(ctx, c.reservationKey(), c.Spec.Network, want, c.Spec.Hostname)
+	if err != nil {
+		c.Status.Phase = "Failed"
+		c.Status.Message = err.Error()
+		return err
+	}
+	if c.Status.Phase != "Bound" || c.Status.IP != ip {
+		c.Status.BoundAt = time.Now().UTC().Format(time.RFC3339)
+	}
+	c.Status.Phase = "Bound"
+	c.Status.IP = ip
+	c.Status.Message = ""
+	return nil
+}
+
+// ─── Store Operations ────────────────────────────────────────────────────────
+
+// LoadIPAddressClaimsFromStore reads claims from NATS and re-reserves their
+// addresses. Must run after networks are loaded.
+func (p *MicroKubeProvider) LoadIPAddressClaimsFromStore(ctx context.Context) {
+	if p.deps.Store == nil || p.deps.Store.IPAddressClaims == nil {
+		return
+	}
+
+	keys, err := p.deps.Store.IPAddressClaims.Keys(ctx, "")
+	if err != nil {
+		p.deps.Logger.Warnw("failed to list IP address claims from store", "error", err)
+		return
+	}
+
+	for _, key := range keys {
+		var c IPAddressClaim
+		if _, err := p.deps.Store.IPAddressClaims.GetJSON(ctx, key, &c); err != nil {
+			p.deps.Logger.Warnw("failed to read IP address claim from store", "key", key, "error", err)
+			continue
+		}
+		prev := c.Status
+		if err := p.bindIPAddressClaim(ctx, &c); err != nil {
+			p.deps.Logger.Warnw("failed to re-bind IP address claim", "claim", c.Namespace+"/"+c.Name, "error", err)
+		}
+		if c.Status != prev {
+			p.persistIPAddressClaim(ctx, &c)
+		}
+		p.ipAddressClaims[c.Namespace+"/"+c.Name] = &c
+	}
+
+	if len(keys) > 0 {
+		p.deps.Logger.Infow("loaded IP address claims from store", "count", len(keys))
+	}
+}
+
+func (p *MicroKubeProvider) persistIPAddressClaim(ctx context.Context, c *IPAddressClaim) {
+	if p.deps.Store != nil && p.deps.Store.IPAddressClaims != nil {
+		key := c.Namespace + "." + c.Name
+		if _, err := p.deps.Store.IPAddressClaims.PutJSON(ctx, key, c); err != nil {
+			p.deps.Logger.Warnw("failed to persist IPAddressClaim", "name", c.Name, "error", err)
+		}
+	}
+}
+
+// ─── CRUD Handlers ──────────────────────────────────────────────────────────
+
+func (p *MicroKubeProvider) handleListAllIPAddressClaims(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("watch") == "true" {
+		p.handleWatchIPAddressClaims(w, r)
+		return
+	}
+
+	items := make([]IPAddressClaim, 0, len(p.ipAddressClaims))
+	for _, c := range p.ipAddressClaims {
+		cp := c.DeepCopy()
+		cp.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "IPAddressClaim"}
+		items = append(items, *cp)
+	}
+
+	if wantsTable(r) {
+		podWriteJSON(w, http.StatusOK, ipAddressClaimListToTable(items))
+		return
+	}
+
+	podWriteJSON(w, http.StatusOK, IPAddressClaimList{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "IPAddressClaimList"},
+		Items:    items,
+	})
+}
+
+func (p *MicroKubeProvider) handleListNamespacedIPAddressClaims(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("watch") == "true" {
+		p.handleWatchIPAddressClaims(w, r)
+		return
+	}
+
+	ns := r.PathValue("namespace")
+	items := make([]IPAddressClaim, 0)
+	for _, c := range p.ipAddressClaims {
+		if c.Namespace == ns {
+			cp := c.DeepCopy()
+			cp.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "IPAddressClaim"}
+			items = append(items, *cp)
+		}
+	}
+
+	if wantsTable(r) {
+		podWriteJSON(w, http.StatusOK, ipAddressClaimListToTable(items))
+		return
+	}
+
+	podWriteJSON(w, http.StatusOK, IPAddressClaimList{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "IPAddressClaimList"},
+		Items:    items,
+	})
+}
+
+func (p *MicroKubeProvider) handleGetIPAddressClaim(w http.ResponseWriter, r *http.Request) {
+	ns := r.PathValue("namespace")
+	name := r.PathValue("name")
+	key := ns + "/" + name
+
+	c, ok := p.ipAddressClaims[key]
+	if !ok {
+		http.Error(w, fmt.Sprintf("IPAddressClaim %q not found", name), http.StatusNotFound)
+		return
+	}
+
+	cp := c.DeepCopy()
+	cp.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "IPAddressClaim"}
+
+	if wantsTable(r) {
+		podWriteJSON(w, http.StatusOK, ipAddressClaimListToTable([]IPAddressClaim{*cp}))
+		return
+	}
+
+	podWriteJSON(w, http.StatusOK, cp)
+}
+
+func (p *MicroKubeProvider) handleCreateIPAddressClaim(w http.ResponseWriter, r *http.Request) {
+	ns := r.PathValue("namespace")
+
+	var c IPAddressClaim
+	if !decodeCRDBody(w, r, "IPAddressClaim", false, &c) {
+		return
+	}
+
+	if c.Name == "" {
+		http.Error(w, "IPAddressClaim name is required", http.StatusBadRequest)
+		return
+	}
+
+	c.Namespace = ns
+	key := ns + "/" + c.Name
+
+	if _, exists := p.ipAddressClaims[key]; exists {
+		http.Error(w, fmt.Sprintf("IPAddressClaim %q already exists", c.Name), http.StatusConflict)
+		return
+	}
+
+	c.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "IPAddressClaim"}
+	if !p.admitCRD(w, &c, nil) {
+		return
+	}
+
+	c.Status = IPAddressClaimStatus{}
+	if err := p.bindIPAddressClaim(r.Context(), &c); err != nil {
+		http.Error(w, fmt.Sprintf("reserving address for IPAddressClaim %q: %v", c.Name, err), http.StatusConflict)
+		return
+	}
+
+	if c.CreationTimestamp.IsZero() {
+		c.CreationTimestamp = metav1.Now()
+	}
+
+	p.persistIPAddressClaim(r.Context(), &c)
+	p.ipAddressClaims[key] = &c
+
+	podWriteJSON(w, http.StatusCreated, &c)
+}
+
+func (p *MicroKubeProvider) handleUpdateIPAddressClaim(w http.ResponseWriter, r *http.Request) {
+	ns := r.PathValue("namespace")
+	name := r.PathValue("name")
+	key := ns + "/" + name
+
+	old, ok := p.ipAddressClaims[key]
+	if !ok {
+		http.Error(w, fmt.Sprintf("IPAddressClaim %q not found", name), http.StatusNotFound)
+		return
+	}
+
+	var c IPAddressClaim
+	if !decodeCRDBody(w, r, "IPAddressClaim", false, &c) {
+		return
+	}
+	c.Name = name
+	c.Namespace = ns
+	c.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "IPAddressClaim"}
+	if !p.admitCRD(w, &c, old) {
+		return
+	}
+
+	if c.CreationTimestamp.IsZero() {
+		c.CreationTimestamp = old.CreationTimestamp
+	}
+	c.Status = old.Status
+	if !p.rebindIPAddressClaim(r.Context(), w, &c, old) {
+		return
+	}
+
+	p.persistIPAddressClaim(r.Context(), &c)
+	p.ipAddressClaims[key] = &c
+
+	podWriteJSON(w, http.StatusOK, &c)
+}
+
+func (p *MicroKubeProvider) handlePatchIPAddressClaim(w http.ResponseWriter, r *http.Request) {
+	ns := r.PathValue("namespace")
+	name := r.PathValue("name")
+	key := ns + "/" + name
+
+	existing, ok := p.ipAddressClaims[key]
+	if !ok {
+		http.Error(w, fmt.Sprintf("IPAddressClaim %q not found", name), http.StatusNotFound)
+		return
+	}
+
+	merged := existing.DeepCopy()
+
+	if !decodeCRDBody(w, r, "IPAddressClaim", true, merged) {
+		return
+	}
+	merged.Name = name
+	merged.Namespace = ns
+	merged.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "IPAddressClaim"}
+	merged.CreationTimestamp = existing.CreationTimestamp
+	merged.Status = existing.Status
+	if !p.admitCRD(w, merged, existing) {
+		return
+	}
+	if !p.rebindIPAddressClaim(r.Context(), w, merged, existing) {
+		return
+	}
+
+	p.persistIPAddressClaim(r.Context(), merged)
+	p.ipAddressClaims[key] = merged
+
+	podWriteJSON(w, http.StatusOK, merged)
+}
+
+// rebindIPAddressClaim applies an update to the reservation: a hostname
+// change moves the DNS record, and a Failed claim gets another attempt. On
+// failure a 409 is written and false is returned.
+func (p *MicroKubeProvider) rebindIPAddressClaim(ctx context.Context, w http.ResponseWriter, c, old *IPAddressClaim) bool {
+	if old.Status.Phase != "Bound" && c.Spec.Network != old.Spec.Network {
+		// Never bound, so there's nothing to keep; start over in the new network
+		c.Status.IP = ""
+	}
+	if err := p.bindIPAddressClaim(ctx, c); err != nil {
+		http.Error(w, fmt.Sprintf("reserving address for IPAddressClaim %q: %v", c.Name, err), http.StatusConflict)
+		return false
+	}
+	return true
+}
+
+func (p *MicroKubeProvider) handleDeleteIPAddressClaim(w http.ResponseWriter, r *http.Request) {
+	ns := r.PathValue("namespace")
+	name := r.PathValue("name")
+	key := ns + "/" + name
+
+	c, ok := p.ipAddressClaims[key]
+	if !ok {
+		http.Error(w, fmt.Sprintf("IPAddressClaim %q not found", name), http.StatusNotFound)
+		return
+	}
+
+	if p.deps.Store != nil && p.deps.Store.IPAddressClaims != nil {
+		storeKey := ns + "." + name
+		if err := p.deps.Store.IPAddressClaims.Delete(r.Context(), storeKey); err != nil {
+			http.Error(w, fmt.Sprintf("deleting IPAddressClaim from store: %v", err), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if p.deps.NetworkMgr != nil {
+		p.deps.NetworkMgr.ReleaseReservation(r.Context(), c.reservationKey())
+	}
+	delete(p.ipAddressClaims, key)
+
+	podWriteJSON(w, http.StatusOK, metav1.Status{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Status"},
+		Status:   "Success",
+		Message:  fmt.Sprintf("IPAddressClaim %q deleted", name),
+	})
+}
+
+// ─── Watch ──────────────────────────────────────────────────────────────────
+
+func (p *MicroKubeProvider) handleWatchIPAddressClaims(w http.ResponseWriter, r *http.Request) {
+	if p.deps.Store == nil || p.deps.Store.IPAddressClaims == nil {
+		http.Error(w, "watch requires NATS store", http.StatusServiceUnavailable)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Transfer-Encoding", "chunked")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	ctx := r.Context()
+	enc := json.NewEncoder(w)
+
+	p.mu.RLock()
+	snapshot := make([]*IPAddressClaim, 0, len(p.ipAddressClaims))
+	for _, c := range p.ipAddressClaims {
+		snapshot = append(snapshot, c.DeepCopy())
+	}
+	p.mu.RUnlock()
+
+	for _, c := range snapshot {
+		c.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "IPAddressClaim"}
+		if err := enc.Encode(K8sWatchEvent{Type: "ADDED", Object: c}); err != nil {
+			return
+		}
+		flusher.Flush()
+	}
+
+	events, err := p.deps.Store.IPAddressClaims.WatchAll(ctx)
+	if err != nil {
+		return
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case evt, ok := <-events:
+			if !ok {
+				return
+			}
+			var c IPAddressClaim
+			if evt.Type == store.EventDelete {
+				c = IPAddressClaim{
+					TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "IPAddressClaim"},
+					ObjectMeta: metav1.ObjectMeta{Name: evt.Key},
+				}
+			} else {
+				if err := json.Unmarshal(evt.Value, &c); err != nil {
+					continue
+				}
+				c.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "IPAddressClaim"}
+			}
+			if err := enc.Encode(K8sWatchEvent{Type: string(evt.Type), Object: &c}); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// ─── Table Format ───────────────────────────────────────────────────────────
+
+func ipAddressClaimListToTable(items []IPAddressClaim) *metav1.Table {
+	table := &metav1.Table{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "meta.k8s.io/v1",
+			Kind:       "Table",
+		},
+		ColumnDefinitions: []metav1.TableColumnDefinition{
+			{Name: "Name", Type: "string", Format: "name"},
+			{Name: "Network", Type: "string"},
+			{Name: "IP", Type: "string"},
+			{Name: "Hostname", Type: "string"},
+			{Name: "Status", Type: "string"},
+			{Name: "Purpose", Type: "string"},
+			{Name: "Age", Type: "string"},
+		},
+	}
+
+	sort.Slice(items, func(i, j int) bool { return items[i].Name < items[j].Name })
+
+	for i := range items {
+		c := &items[i]
+
+		age := "<unknown>"
+		if !c.CreationTimestamp.IsZero() {
+			age = formatAge(time.Since(c.CreationTimestamp.Time))
+		}
+
+		ip := c.Status.IP
+		if ip == "" {
+			ip = "-"
+		}
+		hostname := c.Spec.Hostname
+		if hostname == "" {
+			hostname = "-"
+		}
+
+		raw, _ := json.Marshal(map[string]interface{}{
+			"kind":       "PartialObjectMetadata",
+			"apiVersion": "meta.k8s.io/v1",
+			"metadata": map[string]interface{}{
+				"name":              c.Name,
+				"namespace":         c.Namespace,
+				"creationTimestamp": c.CreationTimestamp.Format(time.RFC3339),
+			},
+		})
+
+		table.Rows = append(table.Rows, metav1.TableRow{
+			Cells: []interface{}{
+				c.Name,
+				c.Spec.Network,
+				ip,
+				hostname,
+				c.Status.Phase,
+				c.Spec.Purpose,
+				age,
+			},
+			Object: kruntime.RawExtension{Raw: raw},
+		})
+	}
+
+	return table
+}
+
+// ─── Consistency ────────────────────────────────────────────────────────────
+
+func (p *MicroKubeProvider) checkIPAddressClaimCRDs(ctx context.Context) []CheckItem {
+	var items []CheckItem
+
+	if p.deps.Store != nil && p.deps.Store.IPAddressClaims != nil {
+		storeKeys, err := p.deps.Store.IPAddressClaims.Keys(ctx, "")
+		if err == nil {
+			storeSet := make(map[string]bool, len(storeKeys))
+			for _, k := range storeKeys {
+				storeSet[k] = true
+			}
+
+			for key, c := range p.ipAddressClaims {
+				storeKey := c.Namespace + "." + c.Name
+				if storeSet[storeKey] {
+					items = append(items, CheckItem{
+						Name:    fmt.Sprintf("ipaddressclaim/%s", key),
+						Status:  "pass",
+						Message: "IPAddressClaim CRD synced with NATS",
+					})
+				} else {
+					items = append(items, CheckItem{
+						Name:    fmt.Sprintf("ipaddressclaim/%s", key),
+						Status:  "fail",
+						Message: "IPAddressClaim CRD in memory but not in NATS store",
+					})
+				}
+				delete(storeSet, storeKey)
+			}
+
+			for storeKey := range storeSet {
+				items = append(items, CheckItem{
+					Name:    fmt.Sprintf("ipaddressclaim/%s", storeKey),
+					Status:  "warn",
+					Message: "IPAddressClaim CRD in NATS but not in memory",
+				})
+			}
+		}
+	}
+
+	// Verify each claim holds its reservation
+	if p.deps.NetworkMgr == nil {
+		return items
+	}
+	reserved := p.deps.NetworkMgr.Reservations()
+	for key, c := range p.ipAddressClaims {
+		checkName := fmt.Sprintf("ipaddressclaim-ip/%s", key)
+		if c.Status.Phase != "Bound" {
+			items = append(items, CheckItem{
+				Name:    checkName,
+				Status:  "fail",
+				Message: "IPAddressClaim is not bound",
+				Details: c.Status.Message,
+			})
+			continue
+		}
+		switch ip, ok := reserved[c.reservationKey()]; {
+		case !ok:
+			items = append(items, CheckItem{
+				Name:    checkName,
+				Status:  "fail",
+				Message: "IPAddressClaim is bound but its address is not reserved in IPAM",
+				Details: fmt.Sprintf("ip=%s", c.Status.IP),
+			})
+		case ip != c.Status.IP:
+			items = append(items, CheckItem{
+				Name:    checkName,
+				Status:  "fail",
+				Message: "IP mismatch between IPAddressClaim and IPAM reservation",
+				Details: fmt.Sprintf("claim=%s ipam=%s", c.Status.IP, ip),
+			})
+		default:
+			items = append(items, CheckItem{
+				Name:    checkName,
+				Status:  "pass",
+				Message: "IPAddressClaim reservation matches IPAM",
+				Details: fmt.Sprintf("ip=%s", ip),
+			})
+		}
+	}
+
+	return items
+}
@@ -0,0 +1,107 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestIPAddressClaimLifecycle(t *testing.T) {
+	p, _ := newTestProvider(t)
+	ctx := context.Background()
+	p.networks["containers"] = &Network{
+		ObjectMeta: metav1.ObjectMeta{Name: "containers"},
+		Spec:       NetworkSpec{CIDR: "172.20.0.0/24", Gateway: "172.20.0.1"},
+	}
+
+	mux := http.NewServeMux()
+	p.RegisterRoutes(mux)
+	h := p.WrapHandler(mux)
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rec
+	}
+	const base = "/api/v1/namespaces/default/ipaddressclaims"
+
+	// Specific address
+	rec := do(http.MethodPost, base, `{"metadata":{"name":"vip"},"spec":{"network":"containers","ip":"172.20.0.50","hostname":"vip"}}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create vip: %d %s", rec.Code, rec.Body)
+	}
+	var vip IPAddressClaim
+	_ = json.Unmarshal(rec.Body.Bytes(), &vip)
+	if vip.Status.Phase != "Bound" || vip.Status.IP != "172.20.0.50" {
+		t.Fatalf("vip status = %+v", vip.Status)
+	}
+
+	// Next free address
+	rec = do(http.MethodPost, base, `{"metadata":{"name":"appliance"},"spec":{"network":"containers"}}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create appliance: %d %s", rec.Code, rec.Body)
+	}
+	var appliance IPAddressClaim
+	_ = json.Unmarshal(rec.Body.Bytes(), &appliance)
+	if appliance.Status.IP != "172.20.0.2" {
+		t.Errorf("appliance got %s, want 172.20.0.2", appliance.Status.IP)
+	}
+
+	// Rejected: taken address, unknown network, moving a bound claim
+	if rec := do(http.MethodPost, base, `{"metadata":{"name":"dup"},"spec":{"network":"containers","ip":"172.20.0.50"}}`); rec.Code != http.StatusConflict {
+		t.Errorf("duplicate address: got %d, want 409", rec.Code)
+	}
+	if rec := do(http.MethodPost, base, `{"metadata":{"name":"lost"},"spec":{"network":"nowhere"}}`); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("unknown network: got %d, want 422", rec.Code)
+	}
+	if rec := do(http.MethodPatch, base+"/vip", `{"spec":{"ip":"172.20.0.51"}}`); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("changing a bound address: got %d, want 422", rec.Code)
+	}
+	if rec := do(http.MethodPut, base+"/vip", `{"metadata":{"name":"vip"},"spec":{"network":"containers","ip":"172.20.0.51"}}`); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("replacing a bound address: got %d, want 422", rec.Code)
+	}
+	p.networks["other"] = &Network{
+		ObjectMeta: metav1.ObjectMeta{Name: "other"},
+		Spec:       NetworkSpec{CIDR: "172.21.0.0/24", Gateway: "172.21.0.1"},
+	}
+	if rec := do(http.MethodPatch, base+"/vip", `{"spec":{"network":"other","ip":""}}`); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("moving a bound claim: got %d, want 422", rec.Code)
+	}
+	// Spelling the bound address differently is not a change
+	bound6 := &IPAddressClaim{Spec: IPAddressClaimSpec{Network: "v6net"}, Status: IPAddressClaimStatus{Phase: "Bound", IP: "fd00::50"}}
+	if errs := p.validateIPAddressClaimSemantics(&IPAddressClaim{Spec: IPAddressClaimSpec{Network: "v6net", IP: "fd00:0:0::0050"}}, bound6); len(errs) > 0 {
+		t.Errorf("same IPv6 address rejected: %v", errs)
+	}
+	if rec := do(http.MethodPatch, base+"/vip", `{"spec":{"purpose":"keepalived"}}`); rec.Code != http.StatusOK {
+		t.Errorf("patching purpose: %d %s", rec.Code, rec.Body)
+	}
+
+	// Pod allocation respects both reservations
+	if _, _, _, err := p.deps.NetworkMgr.AllocateInterface(ctx, "veth_default_web_0", "web", "containers", "172.20.0.50"); err == nil {
+		t.Error("static IP allocation took a reserved address")
+	}
+	ip, _, _, err := p.deps.NetworkMgr.AllocateInterface(ctx, "veth_default_web_0", "web", "containers", "")
+	if err != nil || ip != "172.20.0.3/24" {
+		t.Errorf("dynamic allocation: got %s, %v; want 172.20.0.3/24", ip, err)
+	}
+
+	for _, item := range p.checkIPAddressClaimCRDs(ctx) {
+		if item.Status != "pass" {
+			t.Errorf("consistency: %s %s: %s", item.Name, item.Status, item.Message)
+		}
+	}
+
+	if rec := do(http.MethodDelete, base+"/vip", ""); rec.Code != http.StatusOK {
+		t.Fatalf("delete vip: %d %s", rec.Code, rec.Body)
+	}
+	if _, ok := p.deps.NetworkMgr.Reservations()["ipaddressclaim/default/vip"]; ok {
+		t.Error("reservation survived claim deletion")
+	}
+	if _, _, _, err := p.deps.NetworkMgr.AllocateInterface(ctx, "veth_default_db_0", "db", "containers", "172.20.0.50"); err != nil {
+		t.Errorf("static IP after claim deletion: %v", err)
+	}
+}
@@ -155,8 +155,7 @@ func (p *MicroKubeProvider) handleGetISCSICdrom(w http.ResponseWriter, r *http.R
 
 func (p *MicroKubeProvider) handleCreateISCSICdrom(w http.ResponseWriter, r *http.Request) {
 	var cdrom ISCSICdrom
-	if err := json.NewDecoder(r.Body).Decode(&cdrom); err != nil {
-		http.Error(w, fmt.Sprintf("invalid ISCSICdrom JSON: %v", err), http.StatusBadRequest)
+	if !decodeCRDBody(w, r, "ISCSICdrom", false, &cdrom) {
 		return
 	}
 
@@ -171,6 +170,9 @@ func (p *MicroKubeProvider) handleCreateISCSICdrom(w http.ResponseWriter, r *htt
 	}
 
 	cdrom.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "ISCSICdrom"}
+	if !p.admitCRD(w, &cdrom, nil) {
+		return
+	}
 	if cdrom.CreationTimestamp.IsZero() {
 		cdrom.CreationTimestamp = metav1.Now()
 	}
@@ -225,12 +227,14 @@ func (p *MicroKubeProvider) handleUpdateISCSICdrom(w http.ResponseWriter, r *htt
 	}
 
 	var cdrom ISCSICdrom
-	if err := json.NewDecoder(r.Body).Decode(&cdrom); err != nil {
-		http.Error(w, fmt.Sprintf("invalid ISCSICdrom JSON: %v", err), http.StatusBadRequest)
+	if !decodeCRDBody(w, r, "ISCSICdrom", false, &cdrom) {
 		return
 	}
 	cdrom.Name = name
 	cdrom.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "ISCSICdrom"}
+	if !p.admitCRD(w, &cdrom, old) {
+		return
+	}
 
 	if cdrom.CreationTimestamp.IsZero() {
 		cdrom.CreationTimestamp = old.CreationTimestamp
@@ -283,18 +287,15 @@ func (p *MicroKubeProvider) handlePatchISCSICdrom(w http.ResponseWriter, r *http
 
 	merged := existing.DeepCopy()
 
-	body, err := io.ReadAll(r.Body)
-	if err != nil {
-		http.Error(w, fmt.Sprintf("reading body: %v", err), http.StatusBadRequest)
-		return
-	}
-	if err := json.Unmarshal(body, merged); err != nil {
-		http.Error(w, fmt.Sprintf("invalid patch JSON: %v", err), http.StatusBadRequest)
+	if !decodeCRDBody(w, r, "ISCSICdrom", true, merged) {
 		return
 	}
 	merged.Name = name
 	merged.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "ISCSICdrom"}
 	merged.CreationTimestamp = existing.CreationTimestamp
+	if !p.admitCRD(w, merged, existing) {
+		return
+	}
 
 	if p.deps.Store != nil && p.deps.Store.ISCSICdroms != nil {
 		if _, err := p.deps.Store.ISCSICdroms.PutJSON(r.Context(), name, merged); err != nil {
@@ -324,8 +325,11 @@ func (p *MicroKubeProvider) handleDeleteISCSICdrom(w http.ResponseWriter, r *htt
 		return
 	}
 
-	// Remove iSCSI target from RouterOS
-	p.removeISCSITarget(r.Context(), cdrom)
+	// Remove iSCSI target from RouterOS, unless the mkube.io/iscsi-target
+	// finalizer already did
+	if !gcFinalized(r.Context()) {
+		p.removeISCSITarget(r.Context(), cdrom)
+	}
 
 	// Optionally delete ISO file
 	if r.URL.Query().Get("deleteISO") == "true" {
@@ -213,8 +213,7 @@ func (p *MicroKubeProvider) handleCreateJob(w http.ResponseWriter, r *http.Reque
 	ns := r.PathValue("namespace")
 
 	var job Job
-	if err := json.NewDecoder(r.Body).Decode(&job); err != nil {
-		http.Error(w, fmt.Sprintf("invalid Job JSON: %v", err), http.StatusBadRequest)
+	if !decodeCRDBody(w, r, "Job", false, &job) {
 		return
 	}
 
@@ -231,29 +230,10 @@ func (p *MicroKubeProvider) handleCreateJob(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	if job.Spec.Pool == "" {
-		http.Error(w, "spec.pool is required", http.StatusBadRequest)
-		return
-	}
-	if job.Spec.Script == "" {
-		http.Error(w, "spec.script is required", http.StatusBadRequest)
-		return
-	}
-
-	// Verify a runner exists for this pool
-	runnerFound := false
-	for _, jr := range p.jobRunners {
-		if jr.Spec.Pool == job.Spec.Pool {
-			runnerFound = true
-			break
-		}
-	}
-	if !runnerFound {
-		http.Error(w, fmt.Sprintf("no JobRunner found for pool %q", job.Spec.Pool), http.StatusBadRequest)
+	job.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "Job"}
+	if !p.admitCRD(w, &job, nil) {
 		return
 	}
-
-	job.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "Job"}
 	if job.CreationTimestamp.IsZero() {
 		job.CreationTimestamp = metav1.Now()
 	}
@@ -279,13 +259,15 @@ func (p *MicroKubeProvider) handleUpdateJob(w http.ResponseWriter, r *http.Reque
 	}
 
 	var job Job
-	if err := json.NewDecoder(r.Body).Decode(&job); err != nil {
-		http.Error(w, fmt.Sprintf("invalid Job JSON: %v", err), http.StatusBadRequest)
+	if !decodeCRDBody(w, r, "Job", false, &job) {
 		return
 	}
 	job.Name = name
 	job.Namespace = ns
 	job.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "Job"}
+	if !p.admitCRD(w, &job, old) {
+		return
+	}
 
 	if job.CreationTimestamp.IsZero() {
 		job.CreationTimestamp = old.CreationTimestamp
@@ -313,19 +295,16 @@ func (p *MicroKubeProvider) handlePatchJob(w http.ResponseWriter, r *http.Reques
 
 	merged := existing.DeepCopy()
 
-	body, err := io.ReadAll(r.Body)
-	if err != nil {
-		http.Error(w, fmt.Sprintf("reading body: %v", err), http.StatusBadRequest)
-		return
-	}
-	if err := json.Unmarshal(body, merged); err != nil {
-		http.Error(w, fmt.Sprintf("invalid patch JSON: %v", err), http.StatusBadRequest)
+	if !decodeCRDBody(w, r, "Job", true, merged) {
 		return
 	}
 	merged.Name = name
 	merged.Namespace = ns
 	merged.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "Job"}
 	merged.CreationTimestamp = existing.CreationTimestamp
+	if !p.admitCRD(w, merged, existing) {
+		return
+	}
 
 	p.persistJob(r.Context(), merged)
 	p.jobs[key] = merged
@@ -406,8 +385,10 @@ func (p *MicroKubeProvider) handleCancelJob(w http.ResponseWriter, r *http.Reque
 	podWriteJSON(w, http.StatusOK, job)
 }
 
-// releaseJobHost clears activeJob from the host reservation.
+// releaseJobHost clears activeJob from the host reservation and revokes the
+// job's agent token.
 func (p *MicroKubeProvider) releaseJobHost(ctx context.Context, job *Job) {
+	p.agentTokens.revoke(jobKey(job))
 	if job.Status.BMHRef == "" {
 		return
 	}
@@ -503,7 +484,9 @@ func jobQueueToTable(items []Job) *metav1.Table {
 
 // ─── Agent Endpoints ────────────────────────────────────────────────────────
 
-// handleAgentWork returns the assigned job for the calling agent (source IP lookup).
+// handleAgentWork returns the assigned job for the calling agent (source IP
+// lookup), with the token the agent presents on its other calls in the
+// X-Agent-Token header.
 func (p *MicroKubeProvider) handleAgentWork(w http.ResponseWriter, r *http.Request) {
 	sourceIP, _, err := net.SplitHostPort(r.RemoteAddr)
 	if err != nil {
@@ -540,6 +523,7 @@ func (p *MicroKubeProvider) handleAgentWork(w http.ResponseWriter, r *http.Reque
 				c.Status = job.Status
 			}
 
+			w.Header().Set(agentHeaderToken, p.agentTokens.issue(jobKey(job)))
 			podWriteJSON(w, http.StatusOK, c)
 			return
 		}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"sort"
 	"strings"
@@ -262,8 +261,7 @@ func (p *MicroKubeProvider) handleGetJobRunner(w http.ResponseWriter, r *http.Re
 
 func (p *MicroKubeProvider) handleCreateJobRunner(w http.ResponseWriter, r *http.Request) {
 	var jr JobRunner
-	if err := json.NewDecoder(r.Body).Decode(&jr); err != nil {
-		http.Error(w, fmt.Sprintf("invalid JobRunner JSON: %v", err), http.StatusBadRequest)
+	if !decodeCRDBody(w, r, "JobRunner", false, &jr) {
 		return
 	}
 
@@ -277,22 +275,10 @@ func (p *MicroKubeProvider) handleCreateJobRunner(w http.ResponseWriter, r *http
 		return
 	}
 
-	if jr.Spec.Pool == "" {
-		http.Error(w, "spec.pool is required", http.StatusBadRequest)
-		return
-	}
-	if jr.Spec.BootConfigRef == "" {
-		http.Error(w, "spec.bootConfigRef is required", http.StatusBadRequest)
-		return
-	}
-
-	// Validate BootConfigRef
-	if _, ok := p.bootConfigs[jr.Spec.BootConfigRef]; !ok {
-		http.Error(w, fmt.Sprintf("BootConfig %q not found", jr.Spec.BootConfigRef), http.StatusBadRequest)
+	jr.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "JobRunner"}
+	if !p.admitCRD(w, &jr, nil) {
 		return
 	}
-
-	jr.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "JobRunner"}
 	if jr.CreationTimestamp.IsZero() {
 		jr.CreationTimestamp = metav1.Now()
 	}
@@ -319,12 +305,14 @@ func (p *MicroKubeProvider) handleUpdateJobRunner(w http.ResponseWriter, r *http
 	}
 
 	var jr JobRunner
-	if err := json.NewDecoder(r.Body).Decode(&jr); err != nil {
-		http.Error(w, fmt.Sprintf("invalid JobRunner JSON: %v", err), http.StatusBadRequest)
+	if !decodeCRDBody(w, r, "JobRunner", false, &jr) {
 		return
 	}
 	jr.Name = name
 	jr.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "JobRunner"}
+	if !p.admitCRD(w, &jr, old) {
+		return
+	}
 
 	if jr.CreationTimestamp.IsZero() {
 		jr.CreationTimestamp = old.CreationTimestamp
@@ -350,18 +338,15 @@ func (p *MicroKubeProvider) handlePatchJobRunner(w http.ResponseWriter, r *http.
 
 	merged := existing.DeepCopy()
 
-	body, err := io.ReadAll(r.Body)
-	if err != nil {
-		http.Error(w, fmt.Sprintf("reading body: %v", err), http.StatusBadRequest)
-		return
-	}
-	if err := json.Unmarshal(body, merged); err != nil {
-		http.Error(w, fmt.Sprintf("invalid patch JSON: %v", err), http.StatusBadRequest)
+	if !decodeCRDBody(w, r, "JobRunner", true, merged) {
 		return
 	}
 	merged.Name = name
 	merged.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "JobRunner"}
 	merged.CreationTimestamp = existing.CreationTimestamp
+	if !p.admitCRD(w, merged, existing) {
+		return
+	}
 
 	p.persistJobRunner(r.Context(), merged)
 	p.jobRunners[name] = merged
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"time"
 )
 
 // migrateRequest is the JSON body for POST /api/v1/namespaces/{namespace}/pods/{name}/migrate.
@@ -70,6 +71,14 @@ func (p *MicroKubeProvider) handleMigratePod(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	// Drop the pod's records to the rollout TTL, here and on the target
+	// node until the window closes, so clients follow the move quickly
+	if pod.Annotations == nil {
+		pod.Annotations = make(map[string]string)
+	}
+	pod.Annotations[annotationDNSTTLLoweredUntil] = time.Now().Add(dnsTTLMigrateWindow).UTC().Format(time.RFC3339)
+	p.registerPodDNS(ctx, pod)
+
 	// Delete pod locally (stops containers, releases veths, cleans up DNS)
 	if err := p.DeletePod(ctx, pod); err != nil {
 		http.Error(w, fmt.Sprintf("failed to delete pod locally: %v", err), http.StatusInternalServerError)
@@ -77,9 +86,6 @@ func (p *MicroKubeProvider) handleMigratePod(w http.ResponseWriter, r *http.Requ
 	}
 
 	// Update the node annotation in NATS so the target node picks it up
-	if pod.Annotations == nil {
-		pod.Annotations = make(map[string]string)
-	}
 	pod.Annotations[annotationNode] = req.TargetNode
 
 	if p.deps.Store != nil {
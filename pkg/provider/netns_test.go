@@ -0,0 +1,93 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/glennswest/mkube/pkg/network"
+	"github.com/glennswest/mkube/pkg/runtime"
+)
+
+// netnsRuntime is a mockRuntime that prepares a named namespace per
+// container and records the namespace each container was created in.
+type netnsRuntime struct {
+	*mockRuntime
+	prepared []string
+	specNS   map[string]string
+}
+
+func (r *netnsRuntime) PrepareNetNS(_ context.Context, name string) (string, error) {
+	r.prepared = append(r.prepared, name)
+	return "/var/run/netns/" + name, nil
+}
+
+func (r *netnsRuntime) CreateContainer(ctx context.Context, spec runtime.ContainerSpec) error {
+	r.specNS[spec.Name] = spec.NetNS
+	return r.mockRuntime.CreateContainer(ctx, spec)
+}
+
+// netnsDriver is a mockNetworkDriver that records the namespace each port
+// had when CreatePort ran.
+type netnsDriver struct {
+	*mockNetworkDriver
+	netns   map[string]string
+	created map[string]string
+}
+
+func (d *netnsDriver) SetPortNamespace(port, netns string) error {
+	d.netns[port] = netns
+	return nil
+}
+
+func (d *netnsDriver) CreatePort(_ context.Context, name, _, _ string) error {
+	d.created[name] = d.netns[name]
+	return nil
+}
+
+func TestCreatePodPortNamespace(t *testing.T) {
+	p, rt := newTestProvider(t)
+	ctx := context.Background()
+	nsrt := &netnsRuntime{mockRuntime: rt, specNS: map[string]string{}}
+	p.deps.Runtime = nsrt
+	drv := &netnsDriver{mockNetworkDriver: &mockNetworkDriver{}, netns: map[string]string{}, created: map[string]string{}}
+	netMgr, err := network.NewManager(p.deps.Config.Networks, drv, nil, p.deps.Logger)
+	if err != nil {
+		t.Fatalf("network.NewManager: %v", err)
+	}
+	p.deps.NetworkMgr = netMgr
+
+	pod := testPod("web", "app")
+	if err := p.CreatePod(ctx, pod); err != nil {
+		t.Fatalf("CreatePod: %v", err)
+	}
+	name := sanitizeName(pod, "app")
+	if len(nsrt.prepared) != 1 || nsrt.prepared[0] != name {
+		t.Errorf("prepared namespaces = %v, want [%s]", nsrt.prepared, name)
+	}
+	veth := vethName(pod, 0)
+	if got, want := drv.created[veth], "/var/run/netns/"+name; got != want {
+		t.Errorf("port %s created in namespace %q, want %q", veth, got, want)
+	}
+	// The workload runs in the namespace its port was moved into
+	if got := nsrt.specNS[name]; got != drv.created[veth] {
+		t.Errorf("container %s created in namespace %q, want %q", name, got, drv.created[veth])
+	}
+
+	// Without a namespace-aware runtime the veth stays on the host
+	p2, _ := newTestProvider(t)
+	drv2 := &netnsDriver{mockNetworkDriver: &mockNetworkDriver{}, netns: map[string]string{}, created: map[string]string{}}
+	if p2.deps.NetworkMgr, err = network.NewManager(p2.deps.Config.Networks, drv2, nil, p2.deps.Logger); err != nil {
+		t.Fatalf("network.NewManager: %v", err)
+	}
+	if err := p2.CreatePod(ctx, testPod("db", "app")); err != nil {
+		t.Fatalf("CreatePod: %v", err)
+	}
+	for port, ns := range drv2.created {
+		if ns != "" {
+			t.Errorf("port %s got namespace %q without a namespace-aware runtime", port, ns)
+		}
+	}
+	if len(drv2.created) == 0 {
+		t.Error("no port created")
+	}
+}
@@ -4,7 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
+	"net"
 	"net/http"
 	"strings"
 	"time"
@@ -48,12 +48,17 @@ type NetworkSpec struct {
 	Bridge        string            `json:"bridge,omitempty"`        // RouterOS bridge name
 	CIDR          string            `json:"cidr"`                    // e.g. "192.168.10.0/24"
 	Gateway       string            `json:"gateway"`                 // router IP on this network
-	VLAN          int               `json:"vlan,omitempty"`
+	CIDRs         []string          `json:"cidrs,omitempty"`         // dual-stack: one CIDR per address family, including cidr
+	Gateway6      string            `json:"gateway6,omitempty"`      // IPv6 router address on dual-stack networks
+	VLAN          int               `json:"vlan,omitempty"`          // 802.1Q VLAN ID; lets several networks share one bridge
+	Uplinks       []string          `json:"uplinks,omitempty"`       // trunk ports carrying the VLAN tagged (VLAN networks only)
 	Router        RouterRef         `json:"router,omitempty"`
 	DNS           NetworkDNSSpec    `json:"dns,omitempty"`
 	DHCP          NetworkDHCPSpec   `json:"dhcp,omitempty"`
 	IPAM          NetworkIPAMSpec   `json:"ipam,omitempty"`
 	ExternalDNS   bool              `json:"externalDNS,omitempty"`   // DNS not managed by mkube
+	SpanNodes     bool              `json:"spanNodes,omitempty"`     // stretched across cluster nodes by the overlay mesh
+	Masquerade    bool              `json:"masquerade,omitempty"`    // NAT outbound traffic to the host address (Linux driver)
 	Managed       bool              `json:"managed,omitempty"`       // part 2: auto-deploy microdns
 	Provisioned   bool              `json:"provisioned,omitempty"`   // infrastructure created by provider
 	StaticRecords []StaticDNSRecord `json:"staticRecords,omitempty"`
@@ -67,9 +72,45 @@ type RouterRef struct {
 
 // NetworkDNSSpec defines DNS settings for a network.
 type NetworkDNSSpec struct {
-	Endpoint string `json:"endpoint,omitempty"` // microdns REST URL
-	Zone     string `json:"zone"`               // e.g. "g10.lo"
-	Server   string `json:"server"`             // DNS server IP
+	Endpoint string        `json:"endpoint,omitempty"` // microdns REST URL
+	Zone     string        `json:"zone"`               // e.g. "g10.lo"
+	Server   string        `json:"server"`             // DNS server IP
+	Views    []DNSView     `json:"views,omitempty"`    // split-horizon answer overrides
+	TTL      *DNSTTLPolicy `json:"ttl,omitempty"`      // record TTL policy for the zone
+}
+
+// DNSTTLPolicy sets the TTL, in seconds, of the records mkube registers in
+// the network's zone. A zero field falls back to Default, then to the
+// built-in TTL of the kind; Rollout applies to pods and aliases while a
+// rolling update or migration runs. Namespace policies in namespace.dnsTTL
+// take precedence.
+type DNSTTLPolicy struct {
+	Default  int `json:"default,omitempty"`
+	Pods     int `json:"pods,omitempty"`
+	Aliases  int `json:"aliases,omitempty"`
+	BMH      int `json:"bmh,omitempty"`
+	Static   int `json:"static,omitempty"`
+	Rollout  int `json:"rollout,omitempty"`
+	Negative int `json:"negative,omitempty"` // negative-cache TTL of the network's microdns
+}
+
+// DNSView is a split-horizon view of the network's zone. Queries from a
+// source address inside one of Clients are answered from Records first;
+// names and types the view does not override resolve from the zone as
+// usual. The first matching view wins. Namespace zones on the same
+// microdns take their views from namespace.dnsViews in the config.
+type DNSView struct {
+	Name    string          `json:"name"`              // e.g. "lan"
+	Clients []string        `json:"clients"`           // source CIDRs matched to this view
+	Records []DNSViewRecord `json:"records,omitempty"` // answers replacing the zone's
+}
+
+// DNSViewRecord is one answer override of a DNSView.
+type DNSViewRecord struct {
+	Name string `json:"name"`           // relative to the zone; "@" for the apex
+	Type string `json:"type,omitempty"` // A (default), AAAA, CNAME or TXT
+	Data string `json:"data"`           // address, target name or text
+	TTL  int    `json:"ttl,omitempty"`  // defaults to 300
 }
 
 // NetworkDHCPSpec defines DHCP settings for a network.
@@ -83,6 +124,25 @@ type NetworkDHCPSpec struct {
 	BootFileEFI   string                  `json:"bootFileEfi,omitempty"`
 	ServerNetwork string                  `json:"serverNetwork,omitempty"` // DHCP relay target
 	Reservations  []NetworkDHCPReservation `json:"reservations,omitempty"`
+
+	Options       []DHCPOptionSpec      `json:"options,omitempty"`       // extra options for every client
+	VendorClasses []DHCPVendorClassSpec `json:"vendorClasses,omitempty"` // extra options by option 60 prefix
+}
+
+// DHCPOptionSpec is an arbitrary DHCP option. Type selects how Value is
+// encoded (ip, ips, string, uint8, uint16, uint32, bool, hex, routes) and
+// defaults by code for well-known options such as 42, 43, 66, 67 and 121.
+type DHCPOptionSpec struct {
+	Code  int    `json:"code"`
+	Type  string `json:"type,omitempty"`
+	Value string `json:"value"`
+}
+
+// DHCPVendorClassSpec applies options to clients whose vendor class
+// identifier (option 60) starts with Match.
+type DHCPVendorClassSpec struct {
+	Match   string           `json:"match"`
+	Options []DHCPOptionSpec `json:"options"`
 }
 
 // NetworkDHCPReservation is a static DHCP lease for a known MAC address.
@@ -97,12 +157,17 @@ type NetworkDHCPReservation struct {
 	BootFile    string   `json:"bootFile,omitempty"`     // per-host PXE boot file (BIOS)
 	BootFileEFI string   `json:"bootFileEfi,omitempty"` // per-host PXE boot file (UEFI)
 	RootPath    string   `json:"rootPath,omitempty"`     // iSCSI root path (option 17)
+
+	Options []DHCPOptionSpec `json:"options,omitempty"` // per-host extra options, override the network's
 }
 
 // NetworkIPAMSpec defines IPAM allocation range for a network.
 type NetworkIPAMSpec struct {
 	Start string `json:"start,omitempty"` // first container IPAM IP
 	End   string `json:"end,omitempty"`   // last container IPAM IP
+
+	Start6 string `json:"start6,omitempty"` // first IPv6 IPAM address (dual-stack)
+	End6   string `json:"end6,omitempty"`   // last IPv6 IPAM address (dual-stack)
 }
 
 // StaticDNSRecord is an infrastructure DNS record.
@@ -113,9 +178,10 @@ type StaticDNSRecord struct {
 
 // NetworkStatus reports the observed state of a Network.
 type NetworkStatus struct {
-	Phase    string `json:"phase"`              // Active, Degraded, Error
-	DNSAlive bool   `json:"dnsAlive,omitempty"`
-	PodCount int    `json:"podCount,omitempty"`
+	Phase    string       `json:"phase"`              // Active, Degraded, Error
+	DNSAlive bool         `json:"dnsAlive,omitempty"`
+	PodCount int          `json:"podCount,omitempty"`
+	Plan     *NetworkPlan `json:"plan,omitempty"` // only in ?dryRun=All responses
 }
 
 // NetworkList is a list of Network objects.
@@ -129,8 +195,30 @@ type NetworkList struct {
 func (n *Network) DeepCopy() *Network {
 	out := *n
 	out.ObjectMeta = *n.ObjectMeta.DeepCopy()
+	out.Spec.CIDRs = append([]string(nil), n.Spec.CIDRs...)
+	out.Spec.Uplinks = append([]string(nil), n.Spec.Uplinks...)
 	out.Spec.StaticRecords = append([]StaticDNSRecord(nil), n.Spec.StaticRecords...)
-	out.Spec.DHCP.Reservations = append([]NetworkDHCPReservation(nil), n.Spec.DHCP.Reservations...)
+	out.Spec.DNS.Views = nil
+	for _, v := range n.Spec.DNS.Views {
+		v.Clients = append([]string(nil), v.Clients...)
+		v.Records = append([]DNSViewRecord(nil), v.Records...)
+		out.Spec.DNS.Views = append(out.Spec.DNS.Views, v)
+	}
+	if n.Spec.DNS.TTL != nil {
+		ttl := *n.Spec.DNS.TTL
+		out.Spec.DNS.TTL = &ttl
+	}
+	out.Spec.DHCP.Reservations = nil
+	for _, r := range n.Spec.DHCP.Reservations {
+		r.Options = append([]DHCPOptionSpec(nil), r.Options...)
+		out.Spec.DHCP.Reservations = append(out.Spec.DHCP.Reservations, r)
+	}
+	out.Spec.DHCP.Options = append([]DHCPOptionSpec(nil), n.Spec.DHCP.Options...)
+	out.Spec.DHCP.VendorClasses = nil
+	for _, vc := range n.Spec.DHCP.VendorClasses {
+		vc.Options = append([]DHCPOptionSpec(nil), vc.Options...)
+		out.Spec.DHCP.VendorClasses = append(out.Spec.DHCP.VendorClasses, vc)
+	}
 	return &out
 }
 
@@ -162,6 +250,7 @@ func (p *MicroKubeProvider) LoadNetworksFromStore(ctx context.Context) {
 			if err := p.deps.NetworkMgr.RegisterNetwork(networkToNetworkDef(&net)); err != nil {
 				p.deps.Logger.Warnw("failed to register network with IPAM on load", "network", net.Name, "error", err)
 			}
+			p.deps.NetworkMgr.SetDNSTTL(net.Name, net.Spec.DNS.TTL.config())
 		}
 	}
 
@@ -308,6 +397,11 @@ func networkDefToNetwork(nd config.NetworkDef) Network {
 		})
 	}
 
+	var cidrs []string
+	if nd.CIDR6 != "" {
+		cidrs = []string{nd.CIDR, nd.CIDR6}
+	}
+
 	return Network{
 		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Network"},
 		ObjectMeta: metav1.ObjectMeta{
@@ -317,13 +411,16 @@ func networkDefToNetwork(nd config.NetworkDef) Network {
 		Spec: NetworkSpec{
 			Type:    netType,
 			Bridge:  nd.Bridge,
-			CIDR:    nd.CIDR,
-			Gateway: nd.Gateway,
-			VLAN:    nd.VLAN,
+			CIDR:     nd.CIDR,
+			Gateway:  nd.Gateway,
+			CIDRs:    cidrs,
+			Gateway6: nd.Gateway6,
+			VLAN:     nd.VLAN,
 			DNS: NetworkDNSSpec{
 				Endpoint: nd.DNS.Endpoint,
 				Zone:     nd.DNS.Zone,
 				Server:   nd.DNS.Server,
+				TTL:      dnsTTLPolicy(nd.DNS.TTL),
 			},
 			DHCP: NetworkDHCPSpec{
 				Enabled:       nd.DNS.DHCP.Enabled,
@@ -337,8 +434,10 @@ func networkDefToNetwork(nd config.NetworkDef) Network {
 				Reservations:  reservations,
 			},
 			IPAM: NetworkIPAMSpec{
-				Start: nd.IPAMStart,
-				End:   nd.IPAMEnd,
+				Start:  nd.IPAMStart,
+				End:    nd.IPAMEnd,
+				Start6: nd.IPAMStart6,
+				End6:   nd.IPAMEnd6,
 			},
 			ExternalDNS:   nd.ExternalDNS,
 			Managed:       !nd.ExternalDNS && nd.DNS.Zone != "" && nd.DNS.Server != "",
@@ -363,14 +462,35 @@ func networkToNetworkDef(n *Network) config.NetworkDef {
 		IPAMStart:   n.Spec.IPAM.Start,
 		IPAMEnd:     n.Spec.IPAM.End,
 		ExternalDNS: n.Spec.ExternalDNS,
+		SpanNodes:   n.Spec.SpanNodes,
+		Masquerade:  n.Spec.Masquerade,
 		DNS: config.DNSConfig{
 			Endpoint: n.Spec.DNS.Endpoint,
 			Zone:     n.Spec.DNS.Zone,
 			Server:   n.Spec.DNS.Server,
+			TTL:      n.Spec.DNS.TTL.config(),
 		},
+		CIDR6:      n.Spec.secondaryCIDR6(),
+		Gateway6:   n.Spec.Gateway6,
+		IPAMStart6: n.Spec.IPAM.Start6,
+		IPAMEnd6:   n.Spec.IPAM.End6,
 	}
 }
 
+// secondaryCIDR6 returns the IPv6 entry of a dual-stack network's cidrs
+// list, or "" when the network is single-stack or cidr itself is IPv6.
+func (s NetworkSpec) secondaryCIDR6() string {
+	for _, c := range s.CIDRs {
+		if c == s.CIDR {
+			continue
+		}
+		if ip, _, err := net.ParseCIDR(c); err == nil && ip.To4() == nil {
+			return c
+		}
+	}
+	return ""
+}
+
 // ─── CRUD Handlers ──────────────────────────────────────────────────────────
 
 func (p *MicroKubeProvider) handleListNetworks(w http.ResponseWriter, r *http.Request) {
@@ -420,9 +540,12 @@ func (p *MicroKubeProvider) handleGetNetwork(w http.ResponseWriter, r *http.Requ
 }
 
 func (p *MicroKubeProvider) handleCreateNetwork(w http.ResponseWriter, r *http.Request) {
+	dryRun, ok := dryRunRequested(w, r)
+	if !ok {
+		return
+	}
 	var net Network
-	if err := json.NewDecoder(r.Body).Decode(&net); err != nil {
-		http.Error(w, fmt.Sprintf("invalid Network JSON: %v", err), http.StatusBadRequest)
+	if !decodeCRDBody(w, r, "Network", false, &net) {
 		return
 	}
 
@@ -430,23 +553,26 @@ func (p *MicroKubeProvider) handleCreateNetwork(w http.ResponseWriter, r *http.R
 		http.Error(w, "network name is required", http.StatusBadRequest)
 		return
 	}
-	if net.Spec.CIDR == "" {
-		http.Error(w, "network CIDR is required", http.StatusBadRequest)
-		return
-	}
-
 	if _, exists := p.networks[net.Name]; exists {
 		http.Error(w, fmt.Sprintf("network %q already exists", net.Name), http.StatusConflict)
 		return
 	}
 
 	net.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "Network"}
+	if !p.admitCRD(w, &net, nil) {
+		return
+	}
 	if net.CreationTimestamp.IsZero() {
 		net.CreationTimestamp = metav1.Now()
 	}
 	if net.Status.Phase == "" {
 		net.Status.Phase = "Active"
 	}
+	if dryRun {
+		net.Spec.Bridge = networkBridge(&net)
+		p.writeNetworkDryRun(w, r, nil, &net, http.StatusCreated)
+		return
+	}
 
 	// Persist to NATS
 	if p.deps.Store != nil && p.deps.Store.Networks != nil {
@@ -496,19 +622,33 @@ func (p *MicroKubeProvider) handleUpdateNetwork(w http.ResponseWriter, r *http.R
 		return
 	}
 	wasManaged := old.Spec.Managed
+	dryRun, ok := dryRunRequested(w, r)
+	if !ok {
+		return
+	}
 
 	var net Network
-	if err := json.NewDecoder(r.Body).Decode(&net); err != nil {
-		http.Error(w, fmt.Sprintf("invalid Network JSON: %v", err), http.StatusBadRequest)
+	if !decodeCRDBody(w, r, "Network", false, &net) {
 		return
 	}
 	net.Name = name
 	net.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "Network"}
+	if !p.admitCRD(w, &net, old) {
+		return
+	}
 
-	// Preserve creation timestamp from existing
+	// Preserve creation timestamp and provisioning state from existing
 	if net.CreationTimestamp.IsZero() {
 		net.CreationTimestamp = old.CreationTimestamp
 	}
+	net.Spec.Provisioned = net.Spec.Provisioned || old.Spec.Provisioned
+	if net.Spec.Bridge == "" {
+		net.Spec.Bridge = old.Spec.Bridge
+	}
+	if dryRun {
+		p.writeNetworkDryRun(w, r, old, &net, http.StatusOK)
+		return
+	}
 
 	if p.deps.Store != nil && p.deps.Store.Networks != nil {
 		if _, err := p.deps.Store.Networks.PutJSON(r.Context(), name, &net); err != nil {
@@ -518,6 +658,10 @@ func (p *MicroKubeProvider) handleUpdateNetwork(w http.ResponseWriter, r *http.R
 	}
 
 	p.networks[name] = &net
+	p.deps.NetworkMgr.SetDNSTTL(name, net.Spec.DNS.TTL.config())
+
+	// Move bridge, VLAN, gateway IP and DHCP relay along with the spec
+	p.convergeNetwork(r.Context(), old, &net)
 
 	// Handle managed DNS transitions
 	p.handleManagedDNSTransition(r.Context(), wasManaged, &net)
@@ -537,22 +681,27 @@ func (p *MicroKubeProvider) handlePatchNetwork(w http.ResponseWriter, r *http.Re
 		return
 	}
 	wasManaged := existing.Spec.Managed
+	dryRun, ok := dryRunRequested(w, r)
+	if !ok {
+		return
+	}
 
 	// Start from existing, overlay the patch
 	merged := existing.DeepCopy()
 
-	body, err := io.ReadAll(r.Body)
-	if err != nil {
-		http.Error(w, fmt.Sprintf("reading body: %v", err), http.StatusBadRequest)
-		return
-	}
-	if err := json.Unmarshal(body, merged); err != nil {
-		http.Error(w, fmt.Sprintf("invalid patch JSON: %v", err), http.StatusBadRequest)
+	if !decodeCRDBody(w, r, "Network", true, merged) {
 		return
 	}
 	merged.Name = name
 	merged.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "Network"}
 	merged.CreationTimestamp = existing.CreationTimestamp
+	if !p.admitCRD(w, merged, existing) {
+		return
+	}
+	if dryRun {
+		p.writeNetworkDryRun(w, r, existing, merged, http.StatusOK)
+		return
+	}
 
 	if p.deps.Store != nil && p.deps.Store.Networks != nil {
 		if _, err := p.deps.Store.Networks.PutJSON(r.Context(), name, merged); err != nil {
@@ -562,6 +711,10 @@ func (p *MicroKubeProvider) handlePatchNetwork(w http.ResponseWriter, r *http.Re
 	}
 
 	p.networks[name] = merged
+	p.deps.NetworkMgr.SetDNSTTL(name, merged.Spec.DNS.TTL.config())
+
+	// Move bridge, VLAN, gateway IP and DHCP relay along with the spec
+	p.convergeNetwork(r.Context(), existing, merged)
 
 	// Handle managed DNS transitions
 	p.handleManagedDNSTransition(r.Context(), wasManaged, merged)
@@ -581,7 +734,17 @@ func (p *MicroKubeProvider) handleDeleteNetwork(w http.ResponseWriter, r *http.R
 		return
 	}
 
-	// Teardown auto-deployed DNS pod before the pod-reference check
+	// Check if any pod other than the managed DNS pod still references
+	// this network, before anything is torn down
+	for _, pod := range p.pods {
+		if pod.Annotations[annotationNetwork] == name && !p.isManagedDNSPod(pod) {
+			http.Error(w, fmt.Sprintf("cannot delete network %q: pod %s/%s references it — delete or move the pod first",
+				name, pod.Namespace, pod.Name), http.StatusConflict)
+			return
+		}
+	}
+
+	// Teardown auto-deployed DNS pod
 	if net.Spec.Managed {
 		if err := p.teardownManagedDNS(r.Context(), name); err != nil {
 			p.deps.Logger.Warnw("teardown managed DNS failed", "network", name, "error", err)
@@ -593,15 +756,6 @@ func (p *MicroKubeProvider) handleDeleteNetwork(w http.ResponseWriter, r *http.R
 		p.deprovisionNetwork(r.Context(), net)
 	}
 
-	// Check if any (non-managed) pod still references this network
-	for _, pod := range p.pods {
-		if pod.Annotations[annotationNetwork] == name {
-			http.Error(w, fmt.Sprintf("cannot delete network %q: pod %s/%s references it",
-				name, pod.Namespace, pod.Name), http.StatusConflict)
-			return
-		}
-	}
-
 	if p.deps.Store != nil && p.deps.Store.Networks != nil {
 		if err := p.deps.Store.Networks.Delete(r.Context(), name); err != nil {
 			http.Error(w, fmt.Sprintf("deleting network from store: %v", err), http.StatusInternalServerError)
@@ -653,6 +807,8 @@ func (p *MicroKubeProvider) generateMinimalTOML(net *Network) string {
 		dnsMode = "gateway"
 	}
 
+	recursorTTL, dhcpTTL := dnsTTLTOML(net.Spec.DNS.TTL.config())
+
 	// DHCP enabled section: just the interface/server_ip/listen_ports
 	// so microdns knows to start the DHCP listener. Pools and reservations
 	// come via REST API.
@@ -688,8 +844,8 @@ enabled = true
 forward_zone = %q
 reverse_zone_v4 = %q
 reverse_zone_v6 = ""
-default_ttl = 300
-`, net.Spec.DNS.Server, net.Spec.DNS.Zone, reverseZone)
+default_ttl = %d
+`, net.Spec.DNS.Server, net.Spec.DNS.Zone, reverseZone, dhcpTTL)
 	}
 
 	// Build NATS messaging section
@@ -712,11 +868,11 @@ mode = "%s"
 enabled = true
 listen = "0.0.0.0:15353"
 zones = ["%s"]
-
+%s
 [dns.recursor]
 enabled = true
 listen = "0.0.0.0:53"
-
+%s
 [dns.recursor.forward_zones]
 
 [api.rest]
@@ -729,7 +885,7 @@ path = "/data/microdns.redb"
 [logging]
 level = "info"
 format = "text"
-%s%s`, net.Name, dnsMode, net.Spec.DNS.Zone, dhcpSection, messagingSection)
+%s%s`, net.Name, dnsMode, net.Spec.DNS.Zone, p.dnsViewsTOML(net), recursorTTL, dhcpSection, messagingSection)
 }
 
 // ─── Status Enrichment ──────────────────────────────────────────────────────
@@ -1155,6 +1311,14 @@ func (p *MicroKubeProvider) handleManagedDNSTransition(ctx context.Context, wasM
 							"network", net.Name, "mac", r.MAC, "error", err)
 					}
 				}
+				if err := p.syncNetworkDHCPPoolOptions(ctx, dnsClient, endpoint, net); err != nil {
+					p.deps.Logger.Warnw("failed to sync DHCP pool options on network update",
+						"network", net.Name, "error", err)
+				}
+				if err := p.syncDNSViews(ctx, dnsClient, endpoint, net); err != nil {
+					p.deps.Logger.Warnw("failed to sync DNS views on network update",
+						"network", net.Name, "error", err)
+				}
 			}
 		}
 
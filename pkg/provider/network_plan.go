@@ -0,0 +1,621 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/glennswest/mkube/pkg/routeros"
+)
+
+// ─── Types ──────────────────────────────────────────────────────────────────
+
+// NetworkOp is one change converging a Network would make. Router
+// operations carry the RouterOS console command that reverts them; DNS and
+// DHCP operations describe what the managed DNS deployment and the DNS
+// seeder push to microdns.
+type NetworkOp struct {
+	Action   string `json:"action"`   // create, update, delete
+	Resource string `json:"resource"` // bridge, bridge-port, bridge-vlan, vlan-interface, ip-address, dhcp-relay, dns-pod, dns-config, dns-zone, dns-record, dhcp-pool, dhcp-reservation
+	Name     string `json:"name"`
+	Detail   string `json:"detail,omitempty"`
+	Revert   string `json:"revert,omitempty"`
+
+	apply    func(context.Context) error // nil for operations carried out elsewhere
+	undo     func(context.Context) error
+	optional bool // a failure is logged instead of rolling back
+}
+
+// NetworkPlan is the ordered list of operations that converges a Network,
+// computed without changing anything.
+type NetworkPlan struct {
+	Network    string      `json:"network"`
+	Operations []NetworkOp `json:"operations"`
+	Warnings   []string    `json:"warnings,omitempty"`
+}
+
+// NetworkApply tracks a change applied with a confirm timeout. Until the
+// window ends the router holds a scheduler entry that reverts the router
+// operations on its own; mkube removes it to commit the change once it has
+// reached the router throughout the window. The Network itself is only
+// stored then: until the change is committed, mkube keeps serving the old
+// spec, so an mkube restart mid-window leaves the old spec stored and the
+// router's scheduler reverting to it.
+type NetworkApply struct {
+	Network   string       `json:"network"`
+	Phase     string       `json:"phase"` // Pending, Reverting, Committed, Reverted
+	Plan      *NetworkPlan `json:"plan"`
+	StartedAt metav1.Time  `json:"startedAt"`
+	Deadline  metav1.Time  `json:"deadline"`
+	Message   string       `json:"message,omitempty"`
+
+	net     *Network    // spec to commit once confirmed
+	applied []NetworkOp // router operations that ran, for reverting from mkube
+}
+
+// Confirm timeout tuning; variables so tests can shorten them.
+var (
+	// networkApplyProbeInterval is how often router reachability is checked
+	// during a confirm window.
+	networkApplyProbeInterval = 5 * time.Second
+	// networkApplyMaxFailures consecutive failed probes count as lost
+	// reachability.
+	networkApplyMaxFailures = 3
+	// networkApplyGrace is how long after the confirm window the router's
+	// revert scheduler fires, leaving mkube time to remove it.
+	networkApplyGrace = 30 * time.Second
+)
+
+const (
+	defaultConfirmTimeout = 60 * time.Second
+	maxConfirmTimeout     = 30 * time.Minute
+)
+
+// revertSchedulerName is the RouterOS scheduler entry that reverts a
+// pending change to network.
+func revertSchedulerName(network string) string {
+	return "mkube-revert-" + network
+}
+
+// ─── Planning ───────────────────────────────────────────────────────────────
+
+// planNetworkChange computes what changing old into net does: router
+// operations where mkube provisions the network (a create that is not
+// already provisioned, or an update of a provisioned network's
+// infrastructure, see convergeNetwork), followed by managed DNS and DHCP
+// seeding. old is nil for a create; passing the current network as both
+// reports drift. Must be called with p.mu held.
+func (p *MicroKubeProvider) planNetworkChange(ctx context.Context, old, net *Network) (*NetworkPlan, error) {
+	plan := &NetworkPlan{Network: net.Name, Operations: []NetworkOp{}}
+
+	provisions := (old == nil && !net.Spec.Provisioned) ||
+		(old != nil && old.Spec.Provisioned && (old == net || networkInfraChanged(old, net)))
+	if ros := p.getRouterOSClient(); ros != nil && provisions {
+		ops, warnings, err := p.planProvision(ctx, ros, net, old)
+		if err != nil {
+			return nil, err
+		}
+		plan.Operations = append(plan.Operations, ops...)
+		plan.Warnings = append(plan.Warnings, warnings...)
+	}
+
+	plan.Operations = append(plan.Operations, p.planNetworkDNS(old, net)...)
+	return plan, nil
+}
+
+// planNetworkDNS lists the managed DNS and DHCP changes of turning old
+// (nil on create) into net.
+func (p *MicroKubeProvider) planNetworkDNS(old, net *Network) []NetworkOp {
+	var ops []NetworkOp
+	hasDNS := net.Spec.DNS.Zone != "" && net.Spec.DNS.Server != ""
+	wasManaged := old != nil && old.Spec.Managed
+
+	switch {
+	case net.Spec.Managed && hasDNS && !wasManaged:
+		ops = append(ops,
+			NetworkOp{Action: "create", Resource: "dns-config", Name: net.Name + "/dns-config"},
+			NetworkOp{Action: "create", Resource: "dns-pod", Name: net.Name + "/dns",
+				Detail: fmt.Sprintf("microdns at %s", net.Spec.DNS.Server)})
+	case wasManaged && !net.Spec.Managed:
+		ops = append(ops,
+			NetworkOp{Action: "delete", Resource: "dns-pod", Name: net.Name + "/dns"},
+			NetworkOp{Action: "delete", Resource: "dns-config", Name: net.Name + "/dns-config"})
+	case wasManaged && hasDNS:
+		if cm, ok := p.configMaps[net.Name+"/dns-config"]; ok && cm.Data["microdns.toml"] != p.generateMinimalTOML(net) {
+			ops = append(ops, NetworkOp{Action: "update", Resource: "dns-config", Name: net.Name + "/dns-config",
+				Detail: "microdns.toml changed"})
+		}
+	}
+	if !hasDNS || net.Spec.ExternalDNS {
+		return ops
+	}
+
+	if old == nil || old.Spec.DNS.Zone != net.Spec.DNS.Zone {
+		ops = append(ops, NetworkOp{Action: "create", Resource: "dns-zone", Name: net.Spec.DNS.Zone,
+			Detail: "on " + net.Spec.DNS.Server})
+	}
+	oldRecords := map[string]string{}
+	if old != nil {
+		for _, r := range old.Spec.StaticRecords {
+			oldRecords[r.Name] = r.IP
+		}
+	}
+	for _, r := range net.Spec.StaticRecords {
+		if ip, ok := oldRecords[r.Name]; !ok || ip != r.IP {
+			ops = append(ops, NetworkOp{Action: createOrUpdate(ok), Resource: "dns-record",
+				Name: r.Name + "." + net.Spec.DNS.Zone, Detail: "A " + r.IP})
+		}
+	}
+
+	if !net.Spec.DHCP.Enabled {
+		return ops
+	}
+	d := net.Spec.DHCP
+	if old == nil || !old.Spec.DHCP.Enabled || old.Spec.DHCP.RangeStart != d.RangeStart || old.Spec.DHCP.RangeEnd != d.RangeEnd ||
+		old.Spec.DHCP.LeaseTime != d.LeaseTime || old.Spec.Gateway != net.Spec.Gateway ||
+		!equalDHCPOptions(old.Spec.DHCP.Options, d.Options) || !equalDHCPVendorClasses(old.Spec.DHCP.VendorClasses, d.VendorClasses) {
+		ops = append(ops, NetworkOp{Action: createOrUpdate(old != nil && old.Spec.DHCP.Enabled), Resource: "dhcp-pool",
+			Name: net.Name, Detail: fmt.Sprintf("%s-%s via %s", d.RangeStart, d.RangeEnd, net.Spec.Gateway)})
+	}
+	oldRes := map[string]NetworkDHCPReservation{}
+	if old != nil {
+		for _, r := range old.Spec.DHCP.Reservations {
+			oldRes[strings.ToLower(r.MAC)] = r
+		}
+	}
+	for _, r := range d.Reservations {
+		prev, ok := oldRes[strings.ToLower(r.MAC)]
+		if ok && prev.IP == r.IP && prev.Hostname == r.Hostname && prev.BootFile == r.BootFile &&
+			prev.BootFileEFI == r.BootFileEFI && prev.NextServer == r.NextServer && equalDHCPOptions(prev.Options, r.Options) {
+			continue
+		}
+		detail := r.IP
+		if r.Hostname != "" {
+			detail += " (" + r.Hostname + ")"
+		}
+		ops = append(ops, NetworkOp{Action: createOrUpdate(ok), Resource: "dhcp-reservation", Name: r.MAC, Detail: detail})
+	}
+	return ops
+}
+
+func createOrUpdate(exists bool) string {
+	if exists {
+		return "update"
+	}
+	return "create"
+}
+
+// ─── Execution ──────────────────────────────────────────────────────────────
+
+// runNetworkOps runs the router operations of ops in order and returns the
+// ones that ran. A failed operation undoes everything run before it, in
+// reverse, and fails the whole run, unless it is optional, in which case it
+// is logged and skipped.
+func (p *MicroKubeProvider) runNetworkOps(ctx context.Context, log *zap.SugaredLogger, ops []NetworkOp) ([]NetworkOp, error) {
+	var applied []NetworkOp
+	for _, op := range ops {
+		if op.apply == nil {
+			continue
+		}
+		if err := op.apply(ctx); err != nil {
+			if op.optional {
+				log.Warnw("network operation failed", "action", op.Action, "resource", op.Resource, "name", op.Name, "error", err)
+				continue
+			}
+			undoNetworkOps(ctx, log, applied)
+			return nil, fmt.Errorf("%s %s %s: %w", op.Action, op.Resource, op.Name, err)
+		}
+		log.Infow("network operation applied", "action", op.Action, "resource", op.Resource, "name", op.Name, "detail", op.Detail)
+		applied = append(applied, op)
+	}
+	return applied, nil
+}
+
+// undoNetworkOps reverts applied operations in reverse order.
+func undoNetworkOps(ctx context.Context, log *zap.SugaredLogger, applied []NetworkOp) {
+	for i := len(applied) - 1; i >= 0; i-- {
+		op := applied[i]
+		if op.undo == nil {
+			continue
+		}
+		if err := op.undo(ctx); err != nil {
+			log.Warnw("network rollback step failed", "action", op.Action, "resource", op.Resource, "name", op.Name, "error", err)
+		}
+	}
+	if len(applied) > 0 {
+		log.Infow("network operations rolled back", "operations", len(applied))
+	}
+}
+
+// revertScript returns the RouterOS script that undoes ops and then removes
+// its own scheduler entry.
+func revertScript(network string, ops []NetworkOp) string {
+	var lines []string
+	for i := len(ops) - 1; i >= 0; i-- {
+		if ops[i].apply != nil && ops[i].Revert != "" {
+			lines = append(lines, ":do {"+ops[i].Revert+"} on-error={}")
+		}
+	}
+	lines = append(lines,
+		":log warning "+rosString("mkube: reverted unconfirmed change to network "+network),
+		"/system/scheduler/remove [find name="+rosString(revertSchedulerName(network))+"]")
+	return strings.Join(lines, "\n")
+}
+
+// rosString quotes s for a RouterOS script. Go's %q is not enough: RouterOS
+// expands $variables inside double quotes, so a name such as "$x" would be
+// evaluated when the script runs. Backslashes, quotes, dollar signs and
+// question marks are escaped, and control characters are written as \XX.
+func rosString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\' || c == '"' || c == '$' || c == '?':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c < 0x20 || c == 0x7f:
+			fmt.Fprintf(&b, "\\%02X", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// routerOSDuration formats d as a RouterOS hh:mm:ss interval.
+func routerOSDuration(d time.Duration) string {
+	s := int(d.Round(time.Second).Seconds())
+	return fmt.Sprintf("%02d:%02d:%02d", s/3600, s/60%60, s%60)
+}
+
+// ─── Dry Run ────────────────────────────────────────────────────────────────
+
+// dryRunRequested reports whether r asks for a server-side dry run. Like
+// the Kubernetes API, "All" is the only accepted value; anything else is
+// rejected with 400 and ok is false.
+func dryRunRequested(w http.ResponseWriter, r *http.Request) (dryRun, ok bool) {
+	switch v := r.URL.Query().Get("dryRun"); v {
+	case "":
+		return false, true
+	case "All":
+		return true, true
+	default:
+		http.Error(w, fmt.Sprintf("invalid dryRun %q: the only supported value is All", v), http.StatusBadRequest)
+		return false, false
+	}
+}
+
+// writeNetworkDryRun plans the change from old to net and responds with
+// net as it would be stored, carrying the plan in status.plan.
+func (p *MicroKubeProvider) writeNetworkDryRun(w http.ResponseWriter, r *http.Request, old, net *Network, code int) {
+	plan, err := p.planNetworkChange(r.Context(), old, net)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("planning network change: %v", err), http.StatusBadGateway)
+		return
+	}
+	out := net.DeepCopy()
+	out.Status.Plan = plan
+	podWriteJSON(w, code, out)
+}
+
+// ─── Handlers ───────────────────────────────────────────────────────────────
+
+// handlePlanNetwork returns the operations that converge a network. With a
+// Network in the body it plans that change (a create if the network does
+// not exist); with an empty body it plans the current spec against the
+// router, reporting drift.
+func (p *MicroKubeProvider) handlePlanNetwork(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	old, exists := p.networks[name]
+
+	net, ok := p.proposedNetwork(w, r, name, old)
+	if !ok {
+		return
+	}
+	if net == nil {
+		if !exists {
+			http.Error(w, fmt.Sprintf("network %q not found", name), http.StatusNotFound)
+			return
+		}
+		net = old
+	}
+	plan, err := p.planNetworkChange(r.Context(), old, net)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("planning network change: %v", err), http.StatusBadGateway)
+		return
+	}
+	podWriteJSON(w, http.StatusOK, plan)
+}
+
+// proposedNetwork decodes and admits the Network in r's body, filled in as
+// create or update would. It returns nil with ok true for an empty body.
+func (p *MicroKubeProvider) proposedNetwork(w http.ResponseWriter, r *http.Request, name string, old *Network) (*Network, bool) {
+	if r.ContentLength == 0 {
+		return nil, true
+	}
+	var net Network
+	if !decodeCRDBody(w, r, "Network", false, &net) {
+		return nil, false
+	}
+	net.Name = name
+	net.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "Network"}
+	if !p.admitCRD(w, &net, old) {
+		return nil, false
+	}
+	if old != nil {
+		net.CreationTimestamp = old.CreationTimestamp
+		net.Spec.Provisioned = net.Spec.Provisioned || old.Spec.Provisioned
+		if net.Spec.Bridge == "" {
+			net.Spec.Bridge = old.Spec.Bridge
+		}
+	} else if net.CreationTimestamp.IsZero() {
+		net.CreationTimestamp = metav1.Now()
+	}
+	if net.Spec.Bridge == "" {
+		net.Spec.Bridge = networkBridge(&net)
+	}
+	if net.Status.Phase == "" {
+		net.Status.Phase = "Active"
+	}
+	return &net, true
+}
+
+// handleApplyNetwork applies a Network change with a confirm timeout
+// (?confirmTimeout=90s, default 60s). Before touching the router it adds a
+// scheduler entry there that reverts the router operations once the window
+// and a grace period have passed. mkube keeps probing the router during the
+// window and commits the change by removing the entry and storing the
+// Network; if it loses the router, the change is reverted — by mkube if the
+// router comes back before the entry fires, otherwise by the router itself
+// — and the Network is never stored.
+func (p *MicroKubeProvider) handleApplyNetwork(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	ros := p.getRouterOSClient()
+	if ros == nil {
+		http.Error(w, "applying with a confirm timeout needs the RouterOS backend", http.StatusConflict)
+		return
+	}
+	if a, ok := p.networkApplies[name]; ok && (a.Phase == "Pending" || a.Phase == "Reverting") {
+		http.Error(w, fmt.Sprintf("network %q has a change awaiting confirmation until %s", name, a.Deadline.Format(time.RFC3339)), http.StatusConflict)
+		return
+	}
+	window := defaultConfirmTimeout
+	if v := r.URL.Query().Get("confirmTimeout"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 || d > maxConfirmTimeout {
+			http.Error(w, fmt.Sprintf("invalid confirmTimeout %q: want a duration up to %s", v, maxConfirmTimeout), http.StatusBadRequest)
+			return
+		}
+		window = d
+	}
+
+	old := p.networks[name]
+	net, ok := p.proposedNetwork(w, r, name, old)
+	if !ok {
+		return
+	}
+	if net == nil {
+		http.Error(w, "a Network body is required", http.StatusBadRequest)
+		return
+	}
+	ctx := r.Context()
+	log := p.deps.Logger.With("network", name)
+
+	plan, err := p.planNetworkChange(ctx, old, net)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("planning network change: %v", err), http.StatusBadGateway)
+		return
+	}
+
+	// The dead man's switch goes in before the first change
+	if err := ros.AddScheduler(ctx, routeros.Scheduler{
+		Name:     revertSchedulerName(name),
+		Interval: routerOSDuration(window + networkApplyGrace),
+		OnEvent:  revertScript(name, plan.Operations),
+		Comment:  "mkube: reverts network " + name + " unless confirmed",
+	}); err != nil {
+		http.Error(w, fmt.Sprintf("installing revert scheduler: %v", err), http.StatusBadGateway)
+		return
+	}
+
+	applied, err := p.runNetworkOps(ctx, log, plan.Operations)
+	if err != nil {
+		_ = ros.RemoveScheduler(ctx, revertSchedulerName(name))
+		http.Error(w, fmt.Sprintf("applying network change: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if old == nil {
+		net.Spec.Provisioned = true
+	}
+
+	now := time.Now()
+	a := &NetworkApply{
+		Network:   name,
+		Phase:     "Pending",
+		Plan:      plan,
+		StartedAt: metav1.NewTime(now),
+		Deadline:  metav1.NewTime(now.Add(window)),
+		net:       net,
+		applied:   applied,
+	}
+	p.networkApplies[name] = a
+	p.recordNetworkEvent(name, "ChangeApplied",
+		fmt.Sprintf("applied %d operations, confirming within %s", len(applied), window), corev1.EventTypeNormal)
+	go p.watchNetworkApply(a, ros, window)
+
+	podWriteJSON(w, http.StatusAccepted, a)
+}
+
+// handleGetNetworkApply returns the latest change applied with a confirm
+// timeout.
+func (p *MicroKubeProvider) handleGetNetworkApply(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	a, ok := p.networkApplies[name]
+	if !ok {
+		http.Error(w, fmt.Sprintf("no change applied to network %q with a confirm timeout", name), http.StatusNotFound)
+		return
+	}
+	podWriteJSON(w, http.StatusOK, a)
+}
+
+// commitNetwork stores net in place of old (nil on create) and carries out
+// its IPAM, managed DNS and DHCP side of the change. Router operations are
+// the caller's. Must be called with p.mu held for writing.
+func (p *MicroKubeProvider) commitNetwork(ctx context.Context, old, net *Network) error {
+	if p.deps.Store != nil && p.deps.Store.Networks != nil {
+		if _, err := p.deps.Store.Networks.PutJSON(ctx, net.Name, net); err != nil {
+			return fmt.Errorf("persisting network: %w", err)
+		}
+	}
+	p.networks[net.Name] = net
+	if old == nil {
+		if err := p.deps.NetworkMgr.RegisterNetwork(networkToNetworkDef(net)); err != nil {
+			p.deps.Logger.Warnw("failed to register network with IPAM", "network", net.Name, "error", err)
+		}
+		if net.Spec.Managed && net.Spec.DNS.Zone != "" && net.Spec.DNS.Server != "" {
+			if err := p.deployManagedDNS(ctx, net); err != nil {
+				p.deps.Logger.Warnw("auto-deploy DNS failed", "network", net.Name, "error", err)
+			}
+		}
+	} else {
+		p.handleManagedDNSTransition(ctx, old.Spec.Managed, net)
+	}
+	p.rebuildDHCPIndex()
+	return nil
+}
+
+// ─── Confirm Watchdog ───────────────────────────────────────────────────────
+
+// watchNetworkApply probes the router through a's confirm window. If every
+// probe answers, the revert scheduler is removed and the change committed.
+// Once networkApplyMaxFailures probes in a row fail, the change is
+// reverted: from mkube as soon as the router answers again, or by the
+// router's scheduler when the grace period ends.
+func (p *MicroKubeProvider) watchNetworkApply(a *NetworkApply, ros *routeros.Client, window time.Duration) {
+	ctx := context.Background()
+	log := p.deps.Logger.With("network", a.Network)
+	ticker := time.NewTicker(networkApplyProbeInterval)
+	defer ticker.Stop()
+	confirmAt := time.After(window)
+	routerRevertsAt := time.After(window + networkApplyGrace)
+
+	reachable := func() bool {
+		probeCtx, cancel := context.WithTimeout(ctx, networkApplyProbeInterval)
+		defer cancel()
+		_, err := ros.GetSystemResource(probeCtx)
+		return err == nil
+	}
+
+	failures := 0
+	lost := false
+	for {
+		select {
+		case <-ticker.C:
+			ok := reachable()
+			switch {
+			case !lost && ok:
+				failures = 0
+			case !lost:
+				failures++
+				if failures >= networkApplyMaxFailures {
+					lost = true
+					log.Warnw("lost the router during confirm window, reverting", "failures", failures)
+					p.setNetworkApplyPhase(a, "Reverting", "router unreachable during the confirm window")
+				}
+			case ok:
+				// Back before the router's own revert fires: revert from here
+				_ = ros.RemoveScheduler(ctx, revertSchedulerName(a.Network))
+				undoNetworkOps(ctx, log, a.applied)
+				p.revertNetworkApply(a, "router unreachable during the confirm window; reverted by mkube")
+				return
+			}
+
+		case <-confirmAt:
+			if lost {
+				continue
+			}
+			if err := ros.RemoveScheduler(ctx, revertSchedulerName(a.Network)); err != nil {
+				// The entry may still fire; treat the change as lost
+				log.Warnw("could not remove revert scheduler, the router will revert", "error", err)
+				lost = true
+				p.setNetworkApplyPhase(a, "Reverting", fmt.Sprintf("could not confirm with the router: %v", err))
+				continue
+			}
+			p.commitNetworkApply(ctx, a)
+			return
+
+		case <-routerRevertsAt:
+			p.revertNetworkApply(a, "router unreachable during the confirm window; reverted by the router's scheduler")
+			return
+		}
+	}
+}
+
+// setNetworkApplyPhase updates a's phase under the provider lock.
+func (p *MicroKubeProvider) setNetworkApplyPhase(a *NetworkApply, phase, message string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	a.Phase, a.Message = phase, message
+}
+
+// commitNetworkApply stores a's Network once the router has confirmed the
+// change. If that fails, the router operations are undone from mkube, as
+// the router no longer reverts them.
+func (p *MicroKubeProvider) commitNetworkApply(ctx context.Context, a *NetworkApply) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.commitNetwork(ctx, p.networks[a.Network], a.net); err != nil {
+		undoNetworkOps(ctx, p.deps.Logger.With("network", a.Network), a.applied)
+		a.Phase, a.Message = "Reverted", fmt.Sprintf("confirmed, but storing the network failed: %v", err)
+		p.recordNetworkEvent(a.Network, "ChangeReverted", a.Message, corev1.EventTypeWarning)
+		p.deps.Logger.Warnw("network change reverted", "network", a.Network, "reason", a.Message)
+		return
+	}
+	a.Phase, a.Message = "Committed", "router reachable throughout the confirm window"
+	p.recordNetworkEvent(a.Network, "ChangeCommitted", a.Message, corev1.EventTypeNormal)
+	p.deps.Logger.Infow("network change committed", "network", a.Network)
+}
+
+// revertNetworkApply marks a reverted once its router operations have been
+// undone. The Network was never stored, so mkube still has the old spec.
+func (p *MicroKubeProvider) revertNetworkApply(a *NetworkApply, message string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	a.Phase, a.Message = "Reverted", message
+	p.recordNetworkEvent(a.Network, "ChangeReverted", message, corev1.EventTypeWarning)
+	p.deps.Logger.Warnw("network change reverted", "network", a.Network, "reason", message)
+}
+
+// recordNetworkEvent appends an event for a Network to the event ring
+// buffer. Must be called with p.mu held for writing.
+func (p *MicroKubeProvider) recordNetworkEvent(name, reason, message, eventType string) {
+	now := metav1.Now()
+	p.appendEvent(corev1.Event{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Event"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              fmt.Sprintf("%s.%x", name, now.UnixNano()),
+			CreationTimestamp: now,
+		},
+		InvolvedObject: corev1.ObjectReference{
+			Kind: "Network",
+			Name: name,
+		},
+		Reason:         reason,
+		Message:        message,
+		Type:           eventType,
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+		Source:         corev1.EventSource{Component: "mkube", Host: p.nodeName},
+	})
+}
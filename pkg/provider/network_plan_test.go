@@ -0,0 +1,250 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/glennswest/mkube/pkg/config"
+	"github.com/glennswest/mkube/pkg/dns"
+	"github.com/glennswest/mkube/pkg/network"
+	"github.com/glennswest/mkube/pkg/routeros"
+	"github.com/glennswest/mkube/pkg/runtime"
+)
+
+// fakeRouter is an in-memory RouterOS REST API: GET lists a menu, POST
+// .../add, .../set and .../remove edit it by .id.
+type fakeRouter struct {
+	mu     sync.Mutex
+	menus  map[string][]map[string]string
+	writes []string // "add /ip/address 10.60.0.1/24", ...
+	nextID int
+	down   bool
+}
+
+func (f *fakeRouter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	if f.down {
+		http.Error(w, "unreachable", http.StatusServiceUnavailable)
+		return
+	}
+	if r.Method == http.MethodGet {
+		if r.URL.Path == "/system/resource" {
+			_, _ = w.Write([]byte(`{"version":"7.16"}`))
+			return
+		}
+		list := f.menus[r.URL.Path]
+		if list == nil {
+			list = []map[string]string{}
+		}
+		_ = json.NewEncoder(w).Encode(list)
+		return
+	}
+
+	var body map[string]string
+	_ = json.NewDecoder(r.Body).Decode(&body)
+	i := strings.LastIndex(r.URL.Path, "/")
+	menu, verb := r.URL.Path[:i], r.URL.Path[i+1:]
+	switch verb {
+	case "add":
+		f.nextID++
+		body[".id"] = fmt.Sprintf("*%d", f.nextID)
+		f.menus[menu] = append(f.menus[menu], body)
+		f.writes = append(f.writes, fmt.Sprintf("add %s %s", menu, firstOf(body, "name", "address", "interface", "vlan-ids")))
+	case "set", "remove":
+		for j, item := range f.menus[menu] {
+			if item[".id"] != body[".id"] {
+				continue
+			}
+			if verb == "remove" {
+				f.menus[menu] = append(f.menus[menu][:j], f.menus[menu][j+1:]...)
+			} else {
+				for k, v := range body {
+					item[k] = v
+				}
+			}
+			f.writes = append(f.writes, fmt.Sprintf("%s %s %s", verb, menu, firstOf(item, "name", "address", "interface", "vlan-ids")))
+			break
+		}
+	}
+	_, _ = w.Write([]byte("{}"))
+}
+
+func firstOf(m map[string]string, keys ...string) string {
+	for _, k := range keys {
+		if m[k] != "" {
+			return m[k]
+		}
+	}
+	return ""
+}
+
+func (f *fakeRouter) count(menu string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.menus[menu])
+}
+
+func TestNetworkPlanAndApply(t *testing.T) {
+	probe, grace := networkApplyProbeInterval, networkApplyGrace
+	networkApplyProbeInterval, networkApplyGrace = 10*time.Millisecond, 150*time.Millisecond
+	t.Cleanup(func() { networkApplyProbeInterval, networkApplyGrace = probe, grace })
+
+	router := &fakeRouter{menus: map[string][]map[string]string{
+		"/interface/bridge": {{".id": "*a", "name": "trunk"}},
+	}}
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+	client, _ := routeros.NewClient(config.RouterOSConfig{RESTURL: srv.URL})
+
+	p, _ := newTestProvider(t)
+	p.deps.Runtime = runtime.NewRouterOSRuntime(client)
+	netMgr, err := network.NewManager(p.deps.Config.Networks, &mockNetworkDriver{}, dns.NewClient(p.deps.Logger), p.deps.Logger)
+	if err != nil {
+		t.Fatalf("network.NewManager: %v", err)
+	}
+	p.deps.NetworkMgr = netMgr
+	mux := http.NewServeMux()
+	p.RegisterRoutes(mux)
+	h := p.WrapHandler(mux)
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rec
+	}
+	lab := `{"metadata":{"name":"lab"},"spec":{"type":"data","bridge":"trunk","cidr":"10.60.0.0/24","gateway":"10.60.0.1",` +
+		`"vlan":60,"uplinks":["ether2"],"dns":{"zone":"lab.lo","server":"10.60.0.2","endpoint":"` + srv.URL + `"},` +
+		`"dhcp":{"enabled":true,"rangeStart":"10.60.0.100","rangeEnd":"10.60.0.200"}}}`
+
+	// A dry-run create plans every step and touches nothing
+	if rec := do(http.MethodPost, "/api/v1/networks?dryRun=Some", lab); rec.Code != http.StatusBadRequest {
+		t.Errorf("dryRun=Some: got %d, want 400", rec.Code)
+	}
+	rec := do(http.MethodPost, "/api/v1/networks?dryRun=All", lab)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("dry-run create: %d %s", rec.Code, rec.Body)
+	}
+	var dry Network
+	_ = json.Unmarshal(rec.Body.Bytes(), &dry)
+	var got []string
+	for _, op := range dry.Status.Plan.Operations {
+		got = append(got, op.Action+" "+op.Resource+" "+op.Name)
+	}
+	want := []string{
+		"update bridge trunk", "create bridge-port ether2", "create bridge-vlan trunk vlan 60",
+		"create vlan-interface trunk.60", "create ip-address 10.60.0.1/24", "create dhcp-relay relay-lab",
+		"create dns-zone lab.lo", "create dhcp-pool lab",
+	}
+	if strings.Join(got, "; ") != strings.Join(want, "; ") {
+		t.Errorf("plan =\n  %s\nwant\n  %s", strings.Join(got, "\n  "), strings.Join(want, "\n  "))
+	}
+	if _, ok := p.networks["lab"]; ok || len(router.writes) != 0 {
+		t.Fatalf("dry run changed state: network stored %v, router writes %v", ok, router.writes)
+	}
+
+	// Apply with a confirm timeout; the router stays reachable, so it commits
+	rec = do(http.MethodPost, "/api/v1/networks/lab/apply?confirmTimeout=100ms", lab)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("apply: %d %s", rec.Code, rec.Body)
+	}
+	if router.count("/system/scheduler") != 1 || router.count("/interface/vlan") != 1 || router.count("/ip/address") != 1 {
+		t.Fatalf("router after apply: %v", router.writes)
+	}
+	router.mu.Lock()
+	script := router.menus["/system/scheduler"][0]["on-event"]
+	router.mu.Unlock()
+	if !strings.Contains(script, `/interface/vlan/remove [find name="trunk.60"]`) ||
+		strings.Index(script, "dhcp-relay/remove") > strings.Index(script, "bridge/set") {
+		t.Errorf("revert script does not undo in reverse order:\n%s", script)
+	}
+	// Nothing is stored until the change is confirmed, so a restart in the
+	// window leaves the old spec for the router's revert to match
+	p.mu.RLock()
+	_, stored := p.networks["lab"]
+	p.mu.RUnlock()
+	if stored {
+		t.Error("network stored before the change was confirmed")
+	}
+	if rec := do(http.MethodPost, "/api/v1/networks/lab/apply", lab); rec.Code != http.StatusConflict {
+		t.Errorf("second apply while pending: got %d, want 409", rec.Code)
+	}
+	waitPhase(t, p, "lab", "Committed")
+	if router.count("/system/scheduler") != 0 || !p.networks["lab"].Spec.Provisioned {
+		t.Errorf("commit left scheduler %d, provisioned %v", router.count("/system/scheduler"), p.networks["lab"].Spec.Provisioned)
+	}
+
+	// The plan endpoint reports no drift for the converged network
+	rec = do(http.MethodPost, "/api/v1/networks/lab/plan", "")
+	var plan NetworkPlan
+	_ = json.Unmarshal(rec.Body.Bytes(), &plan)
+	if rec.Code != http.StatusOK || len(plan.Operations) != 0 {
+		t.Errorf("drift plan: %d %+v", rec.Code, plan.Operations)
+	}
+
+	// Moving the gateway while the router drops off: reverted, old spec back
+	moved := strings.Replace(lab, `"gateway":"10.60.0.1"`, `"gateway":"10.60.0.254"`, 1)
+	rec = do(http.MethodPost, "/api/v1/networks/lab/apply?confirmTimeout=100ms", moved)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("apply move: %d %s", rec.Code, rec.Body)
+	}
+	if !strings.Contains(rec.Body.String(), `"name":"10.60.0.254/24"`) || !strings.Contains(rec.Body.String(), `"action":"delete","resource":"ip-address","name":"10.60.0.1/24"`) {
+		t.Errorf("move plan: %s", rec.Body)
+	}
+	router.mu.Lock()
+	router.down = true
+	router.mu.Unlock()
+	waitPhase(t, p, "lab", "Reverted")
+	p.mu.RLock()
+	gw := p.networks["lab"].Spec.Gateway
+	p.mu.RUnlock()
+	if gw != "10.60.0.1" {
+		t.Errorf("gateway after revert = %s, want 10.60.0.1", gw)
+	}
+}
+
+func waitPhase(t *testing.T, p *MicroKubeProvider, network, phase string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		p.mu.RLock()
+		got := p.networkApplies[network].Phase
+		p.mu.RUnlock()
+		if got == phase {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("network %s change never reached phase %s", network, phase)
+}
+
+func TestRevertScriptQuoting(t *testing.T) {
+	for in, want := range map[string]string{
+		"trunk.60":         `"trunk.60"`,
+		`a"b`:              `"a\"b"`,
+		`$(evil)`:          `"\$(evil)"`,
+		`c:\x?`:            `"c:\\x\?"`,
+		"line\n/sys reset": `"line\0A/sys reset"`,
+	} {
+		if got := rosString(in); got != want {
+			t.Errorf("rosString(%q) = %s, want %s", in, got, want)
+		}
+	}
+
+	script := revertScript("lab$x", []NetworkOp{{
+		Revert: "/interface/bridge/remove [find name=" + rosString("br$x") + "]",
+		apply:  func(context.Context) error { return nil },
+	}})
+	for _, line := range strings.Split(script, "\n") {
+		if strings.Contains(strings.ReplaceAll(line, `\$`, ""), "$") {
+			t.Errorf("unescaped $ in revert script line %s", line)
+		}
+	}
+}
@@ -3,8 +3,10 @@ package provider
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
 
+	"github.com/glennswest/mkube/pkg/network"
 	"github.com/glennswest/mkube/pkg/routeros"
 	"github.com/glennswest/mkube/pkg/runtime"
 )
@@ -21,6 +23,15 @@ func (p *MicroKubeProvider) getRouterOSClient() *routeros.Client {
 // provisionNetwork creates the physical infrastructure for a new network:
 // bridge, gateway IP, and DHCP relay. Only runs on RouterOS backend.
 // After provisioning, it deploys managed DNS if configured.
+//
+// A network with spec.vlan set can share its bridge with other VLAN
+// networks: the bridge gets VLAN filtering, a VLAN table entry tagged on
+// the bridge itself and on spec.uplinks, and a VLAN interface that carries
+// the gateway IP and DHCP relay instead of the bridge. If any VLAN step
+// fails, everything created by this call is rolled back.
+//
+// The work is planned by planProvision first, so a dry run reports exactly
+// the operations this performs.
 func (p *MicroKubeProvider) provisionNetwork(ctx context.Context, net *Network) {
 	log := p.deps.Logger.With("network", net.Name)
 	rosClient := p.getRouterOSClient()
@@ -29,96 +40,395 @@ func (p *MicroKubeProvider) provisionNetwork(ctx context.Context, net *Network)
 		return
 	}
 
-	bridge := net.Spec.Bridge
-	if bridge == "" {
-		bridge = "bridge-" + net.Name
-		net.Spec.Bridge = bridge
+	if net.Spec.Bridge == "" {
+		net.Spec.Bridge = networkBridge(net)
 	}
 
-	// Check if bridge already exists
-	bridges, err := rosClient.ListBridges(ctx)
+	ops, _, err := p.planProvision(ctx, rosClient, net, nil)
+	if err != nil {
+		log.Warnw("failed to plan network provisioning", "error", err)
+		return
+	}
+	if _, err := p.runNetworkOps(ctx, log, ops); err != nil {
+		log.Warnw("network provisioning failed", "bridge", net.Spec.Bridge, "error", err)
+		return
+	}
+
+	// Mark as provisioned
+	net.Spec.Provisioned = true
+	if p.deps.Store != nil && p.deps.Store.Networks != nil {
+		if _, err := p.deps.Store.Networks.PutJSON(ctx, net.Name, net); err != nil {
+			log.Warnw("failed to persist provisioned state", "error", err)
+		}
+	}
+
+	log.Infow("network provisioned", "bridge", net.Spec.Bridge, "vlan", net.Spec.VLAN, "provisioned", true)
+}
+
+// convergeNetwork moves a provisioned network's router configuration from
+// old to net when an update changed its bridge, VLAN, uplinks, gateway or
+// DHCP relay. Only runs on RouterOS backend.
+func (p *MicroKubeProvider) convergeNetwork(ctx context.Context, old, net *Network) {
+	rosClient := p.getRouterOSClient()
+	if rosClient == nil || !old.Spec.Provisioned || !networkInfraChanged(old, net) {
+		return
+	}
+	log := p.deps.Logger.With("network", net.Name)
+	ops, warnings, err := p.planProvision(ctx, rosClient, net, old)
 	if err != nil {
-		log.Warnw("failed to list bridges for provisioning", "error", err)
+		log.Warnw("failed to plan network update", "error", err)
 		return
 	}
-	bridgeExists := false
+	for _, w := range warnings {
+		log.Infow("network update", "note", w)
+	}
+	if _, err := p.runNetworkOps(ctx, log, ops); err != nil {
+		log.Warnw("network update failed", "error", err)
+	}
+}
+
+// networkInfraChanged reports whether going from old to net changes what
+// provisioning creates on the router.
+func networkInfraChanged(old, net *Network) bool {
+	return networkBridge(old) != networkBridge(net) ||
+		old.Spec.VLAN != net.Spec.VLAN ||
+		strings.Join(old.Spec.Uplinks, ",") != strings.Join(net.Spec.Uplinks, ",") ||
+		networkGatewayAddress(old) != networkGatewayAddress(net) ||
+		networkWantsRelay(old) != networkWantsRelay(net) ||
+		old.Spec.DNS.Server != net.Spec.DNS.Server
+}
+
+// networkBridge returns the bridge net lives on, defaulting to
+// bridge-<name>.
+func networkBridge(net *Network) string {
+	if net.Spec.Bridge != "" {
+		return net.Spec.Bridge
+	}
+	return "bridge-" + net.Name
+}
+
+// networkL3Interface returns the interface carrying net's gateway IP and
+// DHCP relay: the VLAN interface on VLAN networks, else the bridge.
+func networkL3Interface(net *Network) string {
+	if net.Spec.VLAN > 0 {
+		return network.VLANInterfaceName(networkBridge(net), net.Spec.VLAN)
+	}
+	return networkBridge(net)
+}
+
+// networkGatewayAddress returns net's gateway in address/prefix form, or ""
+// when it has none.
+func networkGatewayAddress(net *Network) string {
+	if net.Spec.Gateway == "" || net.Spec.CIDR == "" {
+		return ""
+	}
+	return net.Spec.Gateway + "/" + cidrMask(net.Spec.CIDR)
+}
+
+// networkWantsRelay reports whether net needs a DHCP relay to its DNS
+// server.
+func networkWantsRelay(net *Network) bool {
+	return net.Spec.DHCP.Enabled && net.Spec.DNS.Server != "" && net.Spec.Gateway != ""
+}
+
+// planProvision reads the router and returns the operations that converge
+// it to net: bridge, VLAN, gateway IP and DHCP relay, in the order they
+// must run. With old set (an update of a provisioned network) it also
+// removes the gateway IP, relay and VLAN interface old had where net no
+// longer wants them. Nothing is changed on the router.
+func (p *MicroKubeProvider) planProvision(ctx context.Context, ros *routeros.Client, net, old *Network) ([]NetworkOp, []string, error) {
+	var ops []NetworkOp
+	var warnings []string
+	bridge := networkBridge(net)
+	l3Iface := networkL3Interface(net)
+
+	// 1. Bridge
+	bridges, err := ros.ListBridges(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("listing bridges: %w", err)
+	}
+	bridgeExists, filtering := false, false
 	for _, b := range bridges {
 		if b.Name == bridge {
-			bridgeExists = true
+			bridgeExists, filtering = true, b.VLANFiltering == "true" || b.VLANFiltering == "yes"
 			break
 		}
 	}
-
 	if !bridgeExists {
-		// 1. Create bridge
-		if err := rosClient.CreateBridge(ctx, bridge); err != nil {
-			log.Warnw("failed to create bridge", "bridge", bridge, "error", err)
-			return
-		}
-		log.Infow("created bridge", "bridge", bridge)
+		ops = append(ops, NetworkOp{
+			Action: "create", Resource: "bridge", Name: bridge,
+			Revert: "/interface/bridge/remove [find name="+rosString(bridge)+"]",
+			apply:  func(ctx context.Context) error { return ros.CreateBridge(ctx, bridge) },
+			undo:   func(ctx context.Context) error { return ros.DeleteBridge(ctx, bridge) },
+		})
 	}
 
-	// 2. Assign gateway IP (if not already assigned)
-	if net.Spec.Gateway != "" && net.Spec.CIDR != "" {
-		gatewayAddr := net.Spec.Gateway + "/" + cidrMask(net.Spec.CIDR)
-		addrs, err := rosClient.ListIPAddresses(ctx)
+	// 2. VLAN: filtering bridge, tagged uplinks, VLAN interface for L3
+	if net.Spec.VLAN > 0 {
+		vlanOps, err := p.planVLAN(ctx, ros, net, bridge, l3Iface, filtering)
 		if err != nil {
-			log.Warnw("failed to list IPs for provisioning", "error", err)
-		} else {
-			alreadyAssigned := false
-			for _, a := range addrs {
-				if a.Interface == bridge && a.Address == gatewayAddr {
-					alreadyAssigned = true
-					break
-				}
+			return nil, nil, err
+		}
+		ops = append(ops, vlanOps...)
+	}
+
+	// 3. Gateway IP, added before the old one goes so the router keeps an
+	// address on the network throughout
+	addrs, err := ros.ListIPAddresses(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("listing IP addresses: %w", err)
+	}
+	hasAddr := func(addr, iface string) bool {
+		for _, a := range addrs {
+			if a.Interface == iface && a.Address == addr {
+				return true
 			}
-			if !alreadyAssigned {
-				if err := rosClient.AddIPAddress(ctx, gatewayAddr, bridge); err != nil {
-					log.Warnw("failed to assign gateway IP", "address", gatewayAddr, "bridge", bridge, "error", err)
-				} else {
-					log.Infow("assigned gateway IP", "address", gatewayAddr, "bridge", bridge)
-				}
+		}
+		return false
+	}
+	gatewayAddr := networkGatewayAddress(net)
+	if gatewayAddr != "" && !hasAddr(gatewayAddr, l3Iface) {
+		ops = append(ops, NetworkOp{
+			Action: "create", Resource: "ip-address", Name: gatewayAddr, Detail: "on " + l3Iface,
+			Revert:   fmt.Sprintf("/ip/address/remove [find address=%s interface=%s]", rosString(gatewayAddr), rosString(l3Iface)),
+			optional: true,
+			apply:    func(ctx context.Context) error { return ros.AddIPAddress(ctx, gatewayAddr, l3Iface) },
+			undo:     func(ctx context.Context) error { return removeIPAddress(ctx, ros, gatewayAddr, l3Iface) },
+		})
+	}
+	if old != nil {
+		oldAddr, oldIface := networkGatewayAddress(old), networkL3Interface(old)
+		if oldAddr != "" && (oldAddr != gatewayAddr || oldIface != l3Iface) && hasAddr(oldAddr, oldIface) {
+			ops = append(ops, NetworkOp{
+				Action: "delete", Resource: "ip-address", Name: oldAddr, Detail: "from " + oldIface,
+				Revert: fmt.Sprintf("/ip/address/add address=%s interface=%s", rosString(oldAddr), rosString(oldIface)),
+				apply:  func(ctx context.Context) error { return removeIPAddress(ctx, ros, oldAddr, oldIface) },
+				undo:   func(ctx context.Context) error { return ros.AddIPAddress(ctx, oldAddr, oldIface) },
+			})
+		}
+	}
+
+	// 4. DHCP relay (if DHCP enabled and DNS server is on this network)
+	relays, err := ros.ListDHCPRelays(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("listing DHCP relays: %w", err)
+	}
+	relayName := "relay-" + net.Name
+	var current *routeros.DHCPRelay
+	for i, r := range relays {
+		if r.Interface == l3Iface || r.Name == relayName {
+			current = &relays[i]
+			break
+		}
+	}
+	stale := old != nil && current != nil && current.Name == relayName &&
+		(!networkWantsRelay(net) || current.Interface != l3Iface || current.DHCPServer != net.Spec.DNS.Server)
+	if stale {
+		r := *current
+		ops = append(ops, NetworkOp{
+			Action: "delete", Resource: "dhcp-relay", Name: r.Name, Detail: "on " + r.Interface,
+			Revert: fmt.Sprintf("/ip/dhcp-relay/add name=%s interface=%s dhcp-server=%s local-address=%s",
+				rosString(r.Name), rosString(r.Interface), rosString(r.DHCPServer), rosString(r.LocalAddress)),
+			apply: func(ctx context.Context) error { return ros.RemoveDHCPRelay(ctx, r.ID) },
+			undo: func(ctx context.Context) error {
+				return ros.AddDHCPRelay(ctx, r.Name, r.Interface, r.DHCPServer, r.LocalAddress)
+			},
+		})
+	}
+	if networkWantsRelay(net) && (current == nil || stale) {
+		server, local := net.Spec.DNS.Server, net.Spec.Gateway
+		ops = append(ops, NetworkOp{
+			Action: "create", Resource: "dhcp-relay", Name: relayName,
+			Detail:   fmt.Sprintf("on %s to %s", l3Iface, server),
+			Revert:   "/ip/dhcp-relay/remove [find name="+rosString(relayName)+"]",
+			optional: true,
+			apply:    func(ctx context.Context) error { return ros.AddDHCPRelay(ctx, relayName, l3Iface, server, local) },
+			undo:     func(ctx context.Context) error { return ros.RemoveDHCPRelayByInterface(ctx, l3Iface) },
+		})
+	}
+
+	// 5. What an update leaves behind
+	if old != nil {
+		oldBridge := networkBridge(old)
+		if vid := old.Spec.VLAN; vid > 0 && (vid != net.Spec.VLAN || oldBridge != bridge) {
+			oldIface := networkL3Interface(old)
+			ops = append(ops, NetworkOp{
+				Action: "delete", Resource: "vlan-interface", Name: oldIface,
+				Revert: fmt.Sprintf("/interface/vlan/add name=%s interface=%s vlan-id=%d", rosString(oldIface), rosString(oldBridge), vid),
+				apply:  func(ctx context.Context) error { return ros.DeleteVLANInterface(ctx, oldIface) },
+				undo: func(ctx context.Context) error {
+					_, err := ros.CreateVLANInterface(ctx, oldIface, oldBridge, vid)
+					return err
+				},
+			})
+			warnings = append(warnings, fmt.Sprintf("VLAN %d stays in the VLAN table of %s with its uplinks", vid, oldBridge))
+		}
+		if oldBridge != bridge && len(p.bridgeSharers(oldBridge, net.Name)) == 0 {
+			warnings = append(warnings, fmt.Sprintf("bridge %s is no longer used by any network and is left in place", oldBridge))
+		}
+	}
+
+	return ops, warnings, nil
+}
+
+// planVLAN plans VLAN filtering for bridge unless it is already on,
+// attaching the network's
+// uplinks, adding the VLAN to the bridge's VLAN table tagged on the bridge
+// (so the router sees it) and on every uplink, and creating the VLAN
+// interface.
+func (p *MicroKubeProvider) planVLAN(ctx context.Context, ros *routeros.Client, net *Network, bridge, vlanIface string, filtering bool) ([]NetworkOp, error) {
+	var ops []NetworkOp
+	vid := net.Spec.VLAN
+
+	if !filtering {
+		ops = append(ops, NetworkOp{
+			Action: "update", Resource: "bridge", Name: bridge, Detail: "vlan-filtering=yes",
+			Revert: fmt.Sprintf("/interface/bridge/set [find name=%s] vlan-filtering=no", rosString(bridge)),
+			apply:  func(ctx context.Context) error { return ros.SetBridgeVLANFiltering(ctx, bridge, true) },
+			undo:   func(ctx context.Context) error { return ros.SetBridgeVLANFiltering(ctx, bridge, false) },
+		})
+	}
+
+	ports, err := ros.ListBridgePorts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing bridge ports: %w", err)
+	}
+	for _, uplink := range net.Spec.Uplinks {
+		attached := false
+		for _, port := range ports {
+			if port.Bridge == bridge && port.Interface == uplink {
+				attached = true
+				break
 			}
 		}
+		if attached {
+			continue
+		}
+		ops = append(ops, NetworkOp{
+			Action: "create", Resource: "bridge-port", Name: uplink, Detail: "on " + bridge,
+			Revert: fmt.Sprintf("/interface/bridge/port/remove [find bridge=%s interface=%s]", rosString(bridge), rosString(uplink)),
+			apply:  func(ctx context.Context) error { return ros.AddBridgePort(ctx, bridge, uplink) },
+			undo:   func(ctx context.Context) error { return ros.RemoveBridgePort(ctx, bridge, uplink) },
+		})
 	}
 
-	// 3. Create DHCP relay (if DHCP enabled and DNS server is on this network)
-	if net.Spec.DHCP.Enabled && net.Spec.DNS.Server != "" && net.Spec.Gateway != "" {
-		relayName := "relay-" + net.Name
-		relays, err := rosClient.ListDHCPRelays(ctx)
-		if err != nil {
-			log.Warnw("failed to list DHCP relays", "error", err)
-		} else {
-			relayExists := false
-			for _, r := range relays {
-				if r.Interface == bridge || r.Name == relayName {
-					relayExists = true
-					break
-				}
+	tagged := append([]string{bridge}, net.Spec.Uplinks...)
+	entries, err := ros.ListBridgeVLANs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing bridge VLANs: %w", err)
+	}
+	var entry *routeros.BridgeVLAN
+	for i, e := range entries {
+		if e.Bridge == bridge && e.VLANIDs == strconv.Itoa(vid) {
+			entry = &entries[i]
+			break
+		}
+	}
+	name := fmt.Sprintf("%s vlan %d", bridge, vid)
+	ensure := func(ctx context.Context) error {
+		_, err := ros.EnsureBridgeVLAN(ctx, bridge, vid, tagged)
+		return err
+	}
+	switch {
+	case entry == nil:
+		ops = append(ops, NetworkOp{
+			Action: "create", Resource: "bridge-vlan", Name: name, Detail: "tagged " + strings.Join(tagged, ","),
+			Revert: fmt.Sprintf("/interface/bridge/vlan/remove [find bridge=%s vlan-ids=%d]", rosString(bridge), vid),
+			apply:  ensure,
+			undo:   func(ctx context.Context) error { return ros.RemoveBridgeVLAN(ctx, bridge, vid) },
+		})
+	default:
+		var missing []string
+		for _, t := range tagged {
+			if !containsString(strings.Split(entry.Tagged, ","), t) {
+				missing = append(missing, t)
 			}
-			if !relayExists {
-				if err := rosClient.AddDHCPRelay(ctx, relayName, bridge, net.Spec.DNS.Server, net.Spec.Gateway); err != nil {
-					log.Warnw("failed to create DHCP relay", "relay", relayName, "error", err)
-				} else {
-					log.Infow("created DHCP relay", "relay", relayName, "interface", bridge, "server", net.Spec.DNS.Server)
+		}
+		if len(missing) == 0 {
+			break
+		}
+		was := entry.Tagged
+		ops = append(ops, NetworkOp{
+			Action: "update", Resource: "bridge-vlan", Name: name, Detail: "tag " + strings.Join(missing, ","),
+			Revert: fmt.Sprintf("/interface/bridge/vlan/set [find bridge=%s vlan-ids=%d] tagged=%s", rosString(bridge), vid, rosString(was)),
+			apply:  ensure,
+			undo: func(ctx context.Context) error {
+				for _, m := range missing {
+					if err := ros.RemoveBridgeVLANMember(ctx, bridge, vid, m); err != nil {
+						return err
+					}
 				}
-			}
+				return nil
+			},
+		})
+	}
+
+	ifaces, err := ros.ListVLANInterfaces(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing VLAN interfaces: %w", err)
+	}
+	for _, v := range ifaces {
+		if v.Name != vlanIface {
+			continue
+		}
+		if v.Interface == bridge && v.VLANID == strconv.Itoa(vid) {
+			return ops, nil
 		}
+		return nil, fmt.Errorf("VLAN interface %q exists on %s with vlan-id %s", vlanIface, v.Interface, v.VLANID)
 	}
+	ops = append(ops, NetworkOp{
+		Action: "create", Resource: "vlan-interface", Name: vlanIface, Detail: fmt.Sprintf("vlan %d on %s", vid, bridge),
+		Revert: "/interface/vlan/remove [find name="+rosString(vlanIface)+"]",
+		apply: func(ctx context.Context) error {
+			_, err := ros.CreateVLANInterface(ctx, vlanIface, bridge, vid)
+			return err
+		},
+		undo: func(ctx context.Context) error { return ros.DeleteVLANInterface(ctx, vlanIface) },
+	})
+	return ops, nil
+}
 
-	// 4. Mark as provisioned
-	net.Spec.Provisioned = true
-	if p.deps.Store != nil && p.deps.Store.Networks != nil {
-		if _, err := p.deps.Store.Networks.PutJSON(ctx, net.Name, net); err != nil {
-			log.Warnw("failed to persist provisioned state", "error", err)
+// removeIPAddress removes addr from iface. A missing address is not an
+// error.
+func removeIPAddress(ctx context.Context, ros *routeros.Client, addr, iface string) error {
+	addrs, err := ros.ListIPAddresses(ctx)
+	if err != nil {
+		return err
+	}
+	for _, a := range addrs {
+		if a.Interface == iface && a.Address == addr {
+			return ros.RemoveIPAddress(ctx, a.ID)
+		}
+	}
+	return nil
+}
+
+// bridgeHasVLANNetworks reports whether another VLAN network already uses
+// bridge (so VLAN filtering is on and the bridge must stay).
+func (p *MicroKubeProvider) bridgeHasVLANNetworks(bridge, except string) bool {
+	for _, other := range p.bridgeSharers(bridge, except) {
+		if other.Spec.VLAN > 0 {
+			return true
 		}
 	}
+	return false
+}
 
-	log.Infow("network provisioned", "bridge", bridge, "provisioned", true)
+// bridgeSharers returns the other networks on bridge.
+func (p *MicroKubeProvider) bridgeSharers(bridge, except string) []*Network {
+	var out []*Network
+	for name, other := range p.networks {
+		if name != except && other.Spec.Bridge == bridge {
+			out = append(out, other)
+		}
+	}
+	return out
 }
 
 // deprovisionNetwork tears down the physical infrastructure for a network:
-// DHCP relay, bridge IP, and bridge. Only runs on RouterOS backend.
+// DHCP relay, gateway IP, the VLAN interface and bridge VLAN entry of a
+// VLAN network, uplinks no other network needs, and the bridge once no
+// other network shares it. Only runs on RouterOS backend.
 func (p *MicroKubeProvider) deprovisionNetwork(ctx context.Context, net *Network) {
 	log := p.deps.Logger.With("network", net.Name)
 	rosClient := p.getRouterOSClient()
@@ -134,18 +444,53 @@ func (p *MicroKubeProvider) deprovisionNetwork(ctx context.Context, net *Network
 	if bridge == "" {
 		return
 	}
+	l3Iface := bridge
+	if net.Spec.VLAN > 0 {
+		l3Iface = network.VLANInterfaceName(bridge, net.Spec.VLAN)
+	}
 
 	// 1. Remove DHCP relay
-	if err := rosClient.RemoveDHCPRelayByInterface(ctx, bridge); err != nil {
-		log.Warnw("failed to remove DHCP relay", "bridge", bridge, "error", err)
+	if err := rosClient.RemoveDHCPRelayByInterface(ctx, l3Iface); err != nil {
+		log.Warnw("failed to remove DHCP relay", "interface", l3Iface, "error", err)
 	}
 
-	// 2. Remove bridge IP
-	if err := rosClient.RemoveIPAddressByInterface(ctx, bridge); err != nil {
-		log.Warnw("failed to remove bridge IP", "bridge", bridge, "error", err)
+	// 2. Remove gateway IP
+	if err := rosClient.RemoveIPAddressByInterface(ctx, l3Iface); err != nil {
+		log.Warnw("failed to remove gateway IP", "interface", l3Iface, "error", err)
 	}
 
-	// 3. Remove bridge
+	sharers := p.bridgeSharers(bridge, net.Name)
+
+	// 3. Remove VLAN interface, bridge VLAN entry and unshared uplinks
+	if vid := net.Spec.VLAN; vid > 0 {
+		if err := rosClient.DeleteVLANInterface(ctx, l3Iface); err != nil {
+			log.Warnw("failed to remove VLAN interface", "interface", l3Iface, "error", err)
+		}
+		if err := rosClient.RemoveBridgeVLAN(ctx, bridge, vid); err != nil {
+			log.Warnw("failed to remove bridge VLAN", "bridge", bridge, "vlan", vid, "error", err)
+		}
+		if len(sharers) > 0 {
+			for _, uplink := range net.Spec.Uplinks {
+				if uplinkShared(sharers, uplink) {
+					continue
+				}
+				if err := rosClient.RemoveBridgePort(ctx, bridge, uplink); err != nil {
+					log.Warnw("failed to remove uplink", "bridge", bridge, "uplink", uplink, "error", err)
+				}
+			}
+			if !p.bridgeHasVLANNetworks(bridge, net.Name) {
+				if err := rosClient.SetBridgeVLANFiltering(ctx, bridge, false); err != nil {
+					log.Warnw("failed to disable VLAN filtering", "bridge", bridge, "error", err)
+				}
+			}
+		}
+	}
+
+	// 4. Remove bridge, unless other networks still share it
+	if len(sharers) > 0 {
+		log.Infow("network deprovisioned, bridge kept for other networks", "bridge", bridge, "networks", len(sharers))
+		return
+	}
 	if err := rosClient.DeleteBridge(ctx, bridge); err != nil {
 		log.Warnw("failed to remove bridge", "bridge", bridge, "error", err)
 	}
@@ -153,6 +498,16 @@ func (p *MicroKubeProvider) deprovisionNetwork(ctx context.Context, net *Network
 	log.Infow("network deprovisioned", "bridge", bridge)
 }
 
+// uplinkShared reports whether any of the networks trunks uplink.
+func uplinkShared(networks []*Network, uplink string) bool {
+	for _, n := range networks {
+		if containsString(n.Spec.Uplinks, uplink) {
+			return true
+		}
+	}
+	return false
+}
+
 // cidrMask extracts the mask length from a CIDR string (e.g. "192.168.12.0/24" → "24").
 func cidrMask(cidr string) string {
 	for i := len(cidr) - 1; i >= 0; i-- {
@@ -0,0 +1,482 @@
+package provider
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// ─── Types ──────────────────────────────────────────────────────────────────
+
+// openAPISchema is the subset of the OpenAPI v3 schema object that mkube
+// generates for the kinds it serves.
+type openAPISchema struct {
+	Type                 string                    `json:"type,omitempty"`
+	Format               string                    `json:"format,omitempty"`
+	Description          string                    `json:"description,omitempty"`
+	Properties           map[string]*openAPISchema `json:"properties,omitempty"`
+	AdditionalProperties *openAPISchema            `json:"additionalProperties,omitempty"`
+	Items                *openAPISchema            `json:"items,omitempty"`
+	Required             []string                  `json:"required,omitempty"`
+	Enum                 []string                  `json:"enum,omitempty"`
+	Pattern              string                    `json:"pattern,omitempty"`
+	Minimum              *int64                    `json:"minimum,omitempty"`
+	Maximum              *int64                    `json:"maximum,omitempty"`
+	IntOrString          bool                      `json:"x-kubernetes-int-or-string,omitempty"`
+	PreserveUnknown      bool                      `json:"x-kubernetes-preserve-unknown-fields,omitempty"`
+	GVK                  []map[string]string       `json:"x-kubernetes-group-version-kind,omitempty"`
+}
+
+// schemaRule adds constraints the Go types can't express. Paths are
+// dot-separated JSON field names; "[]" steps into array items.
+type schemaRule struct {
+	Required bool
+	Enum     []string
+	Format   string // "ip", "cidr", "mac", "date-time"
+	Pattern  string
+	Min, Max *int64
+}
+
+func intPtr(v int64) *int64 { return &v }
+
+// crdSchemaKind describes one custom kind published on /openapi/v3.
+type crdSchemaKind struct {
+	Kind        string
+	Type        reflect.Type
+	Description string
+	Rules       map[string]schemaRule
+}
+
+var crdSchemaKinds = []crdSchemaKind{
+	{
+		Kind:        "BareMetalHost",
+		Type:        reflect.TypeOf(BareMetalHost{}),
+		Description: "BareMetalHost represents a physical server managed through IPMI.",
+		Rules: map[string]schemaRule{
+			"spec.bootMACAddress": {Format: "mac"},
+			"spec.ip":             {Format: "ip"},
+			"spec.nextServer":     {Format: "ip"},
+			"spec.bmc.address":    {Format: "ip"},
+			"spec.bmc.mac":        {Format: "mac"},
+		},
+	},
+	{
+		Kind:        "Network",
+		Type:        reflect.TypeOf(Network{}),
+		Description: "Network is a layer-2/3 network with IPAM, DHCP and DNS settings.",
+		Rules: map[string]schemaRule{
+			"spec":                             {Required: true},
+			"spec.type":                        {Enum: []string{"data", "ipmi", "management", "boot", "storage", "user", "external"}},
+			"spec.cidr":                        {Required: true, Format: "cidr"},
+			"spec.gateway":                     {Format: "ip"},
+			"spec.cidrs[]":                     {Format: "cidr"},
+			"spec.gateway6":                    {Format: "ip"},
+			"spec.vlan":                        {Min: intPtr(0), Max: intPtr(4094)},
+			"spec.router.ip":                   {Format: "ip"},
+			"spec.dns.server":                  {Format: "ip"},
+			"spec.dns.views[].name":            {Required: true},
+			"spec.dns.views[].clients":         {Required: true},
+			"spec.dns.views[].clients[]":       {Format: "cidr"},
+			"spec.dns.views[].records[].name":  {Required: true},
+			"spec.dns.views[].records[].type":  {Enum: []string{"A", "AAAA", "CNAME", "TXT"}},
+			"spec.dns.views[].records[].data":  {Required: true},
+			"spec.dns.views[].records[].ttl":   {Min: intPtr(0)},
+			"spec.dns.ttl.default":             {Min: intPtr(0), Max: intPtr(maxDNSTTL)},
+			"spec.dns.ttl.pods":                {Min: intPtr(0), Max: intPtr(maxDNSTTL)},
+			"spec.dns.ttl.aliases":             {Min: intPtr(0), Max: intPtr(maxDNSTTL)},
+			"spec.dns.ttl.bmh":                 {Min: intPtr(0), Max: intPtr(maxDNSTTL)},
+			"spec.dns.ttl.static":              {Min: intPtr(0), Max: intPtr(maxDNSTTL)},
+			"spec.dns.ttl.rollout":             {Min: intPtr(0), Max: intPtr(maxDNSTTL)},
+			"spec.dns.ttl.negative":            {Min: intPtr(0), Max: intPtr(maxDNSTTL)},
+			"spec.dhcp.rangeStart":             {Format: "ip"},
+			"spec.dhcp.rangeEnd":               {Format: "ip"},
+			"spec.dhcp.nextServer":             {Format: "ip"},
+			"spec.dhcp.leaseTime":              {Min: intPtr(0)},
+			"spec.dhcp.reservations[].mac":     {Required: true, Format: "mac"},
+			"spec.dhcp.reservations[].ip":      {Required: true, Format: "ip"},
+			"spec.dhcp.reservations[].gateway": {Format: "ip"},
+			"spec.ipam.start":                  {Format: "ip"},
+			"spec.ipam.end":                    {Format: "ip"},
+			"spec.ipam.start6":                 {Format: "ip"},
+			"spec.ipam.end6":                   {Format: "ip"},
+			"spec.staticRecords[].name":        {Required: true},
+			"spec.staticRecords[].ip":          {Required: true, Format: "ip"},
+		},
+	},
+	{
+		Kind:        "Job",
+		Type:        reflect.TypeOf(Job{}),
+		Description: "Job is a unit of work executed on a bare metal host from a JobRunner pool.",
+		Rules: map[string]schemaRule{
+			"spec":                  {Required: true},
+			"spec.pool":             {Required: true},
+			"spec.script":           {Required: true},
+			"spec.timeout":          {Min: intPtr(0)},
+			"spec.artifacts[].path": {Required: true},
+			"spec.artifacts[].name": {Required: true},
+		},
+	},
+	{
+		Kind:        "JobRunner",
+		Type:        reflect.TypeOf(JobRunner{}),
+		Description: "JobRunner defines how hosts in a job pool are booted, shared and reclaimed.",
+		Rules: map[string]schemaRule{
+			"spec":                 {Required: true},
+			"spec.pool":            {Required: true},
+			"spec.bootConfigRef":   {Required: true},
+			"spec.reclaimPolicy":   {Enum: []string{"PowerOff", "Retain"}},
+			"spec.idleTimeout":     {Min: intPtr(0)},
+			"spec.maxConcurrent":   {Min: intPtr(0)},
+			"spec.schedule.days":   {Required: true},
+			"spec.schedule.days[]": {Enum: []string{"Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"}},
+			"spec.schedule.start":  {Required: true, Pattern: `^([01][0-9]|2[0-3]):[0-5][0-9]$`},
+			"spec.schedule.end":    {Required: true, Pattern: `^([01][0-9]|2[0-3]):[0-5][0-9]$`},
+		},
+	},
+	{
+		Kind:        "HostReservation",
+		Type:        reflect.TypeOf(HostReservation{}),
+		Description: "HostReservation claims a BareMetalHost for a job pool.",
+		Rules: map[string]schemaRule{
+			"spec":           {Required: true},
+			"spec.bmhRef":    {Required: true},
+			"spec.pool":      {Required: true},
+			"spec.expiresAt": {Format: "date-time"},
+		},
+	},
+	{
+		Kind:        "IPAddressClaim",
+		Type:        reflect.TypeOf(IPAddressClaim{}),
+		Description: "IPAddressClaim reserves an address from a Network outside of pods.",
+		Rules: map[string]schemaRule{
+			"spec":          {Required: true},
+			"spec.network":  {Required: true},
+			"spec.ip":       {Format: "ip"},
+			"spec.hostname": {Pattern: `^[a-z0-9]([-a-z0-9.]*[a-z0-9])?$`},
+		},
+	},
+	{
+		Kind:        "PortForward",
+		Type:        reflect.TypeOf(PortForward{}),
+		Description: "PortForward exposes a pod port through destination NAT on an external interface or address.",
+		Rules: map[string]schemaRule{
+			"spec":              {Required: true},
+			"spec.externalPort": {Required: true, Min: intPtr(1), Max: intPtr(65535)},
+			"spec.externalIP":   {Format: "ip"},
+			"spec.protocol":     {Enum: []string{"tcp", "udp"}},
+			"spec.target":       {Required: true},
+			"spec.target.port":  {Required: true, Min: intPtr(1), Max: intPtr(65535)},
+		},
+	},
+	{
+		Kind:        "BootConfig",
+		Type:        reflect.TypeOf(BootConfig{}),
+		Description: "BootConfig is a boot configuration template served to hosts during PXE boot.",
+		Rules: map[string]schemaRule{
+			"spec.format": {Enum: []string{"ignition", "cloud-init", "kickstart", "custom"}},
+		},
+	},
+	{
+		Kind:        "ISCSICdrom",
+		Type:        reflect.TypeOf(ISCSICdrom{}),
+		Description: "ISCSICdrom is an ISO image shared read-only over iSCSI.",
+		Rules:       map[string]schemaRule{},
+	},
+	{
+		Kind:        "Registry",
+		Type:        reflect.TypeOf(Registry{}),
+		Description: "Registry is an OCI registry instance with optional pull-through and image watching.",
+		Rules: map[string]schemaRule{
+			"spec":                  {Required: true},
+			"spec.network":          {Required: true},
+			"spec.staticIP":         {Required: true, Format: "ip"},
+			"spec.watchPollSeconds": {Min: intPtr(0)},
+		},
+	},
+	{
+		Kind:        "Deployment",
+		Type:        reflect.TypeOf(Deployment{}),
+		Description: "Deployment keeps a number of pod replicas running and rolls them on change.",
+	},
+	{
+		Kind:        "AlertRule",
+		Type:        reflect.TypeOf(AlertRule{}),
+		Description: "AlertRule raises an alert when its condition holds and notifies its receivers.",
+	},
+	{
+		Kind:        "AlertSilence",
+		Type:        reflect.TypeOf(AlertSilence{}),
+		Description: "AlertSilence mutes matching alerts until it expires.",
+	},
+	{
+		Kind:        "DHCPDevice",
+		Type:        reflect.TypeOf(DHCPDevice{}),
+		Description: "DHCPDevice is a MAC address seen by DHCP, with its vendor and lease history.",
+	},
+	{
+		Kind:        "DNSRecord",
+		Type:        reflect.TypeOf(DNSRecord{}),
+		Description: "DNSRecord is a record in a network's microdns zone.",
+	},
+	{
+		Kind:        "DHCPPool",
+		Type:        reflect.TypeOf(DHCPPoolResource{}),
+		Description: "DHCPPool is a microdns DHCP address pool.",
+	},
+	{
+		Kind:        "DHCPReservation",
+		Type:        reflect.TypeOf(DHCPReservationResource{}),
+		Description: "DHCPReservation is a fixed microdns DHCP lease for a MAC address.",
+	},
+	{
+		Kind:        "DHCPLease",
+		Type:        reflect.TypeOf(DHCPLeaseResource{}),
+		Description: "DHCPLease is an active microdns DHCP lease.",
+	},
+	{
+		Kind:        "DNSForwarder",
+		Type:        reflect.TypeOf(DNSForwarderResource{}),
+		Description: "DNSForwarder sends queries for a zone to other servers.",
+	},
+	{
+		Kind:        "MutatingWebhookConfiguration",
+		Type:        reflect.TypeOf(admissionregv1.MutatingWebhookConfiguration{}),
+		Description: "MutatingWebhookConfiguration lists HTTPS webhooks that may modify objects before they are stored.",
+		Rules:       webhookSchemaRules,
+	},
+	{
+		Kind:        "ValidatingWebhookConfiguration",
+		Type:        reflect.TypeOf(admissionregv1.ValidatingWebhookConfiguration{}),
+		Description: "ValidatingWebhookConfiguration lists HTTPS webhooks that may reject writes.",
+		Rules:       webhookSchemaRules,
+	},
+}
+
+// coreSchemaKinds are the Kubernetes core/v1 kinds served under /api/v1.
+// Their schemas are published so clients can explain and validate them;
+// the API server itself decodes them with the Kubernetes types.
+var coreSchemaKinds = []crdSchemaKind{
+	{Kind: "Pod", Type: reflect.TypeOf(corev1.Pod{}),
+		Description: "Pod is a group of containers run together on one node."},
+	{Kind: "ConfigMap", Type: reflect.TypeOf(corev1.ConfigMap{}),
+		Description: "ConfigMap holds configuration data for pods to consume."},
+	{Kind: "Secret", Type: reflect.TypeOf(corev1.Secret{}),
+		Description: "Secret holds sensitive data such as passwords, tokens or keys."},
+	{Kind: "Namespace", Type: reflect.TypeOf(corev1.Namespace{}),
+		Description: "Namespace provides a scope for names."},
+	{Kind: "Node", Type: reflect.TypeOf(corev1.Node{}),
+		Description: "Node is a device running mkube."},
+	{Kind: "Event", Type: reflect.TypeOf(corev1.Event{}),
+		Description: "Event is a report of something that happened to an object."},
+	{Kind: "Service", Type: reflect.TypeOf(corev1.Service{}),
+		Description: "Service names a set of pods reachable through DNS."},
+	{Kind: "PersistentVolumeClaim", Type: reflect.TypeOf(corev1.PersistentVolumeClaim{}),
+		Description: "PersistentVolumeClaim requests a volume for a pod."},
+}
+
+// webhookSchemaRules are shared by both webhook configuration kinds.
+var webhookSchemaRules = map[string]schemaRule{
+	"webhooks[].name":           {Required: true},
+	"webhooks[].clientConfig":   {Required: true},
+	"webhooks[].failurePolicy":  {Enum: []string{"Fail", "Ignore"}},
+	"webhooks[].timeoutSeconds": {Min: intPtr(1), Max: intPtr(30)},
+}
+
+// ─── Schema Generation ──────────────────────────────────────────────────────
+
+var (
+	timeType        = reflect.TypeOf(metav1.Time{})
+	microTimeType   = reflect.TypeOf(metav1.MicroTime{})
+	stdTimeType     = reflect.TypeOf(time.Time{})
+	durationType    = reflect.TypeOf(metav1.Duration{})
+	intOrStringType = reflect.TypeOf(intstr.IntOrString{})
+	quantityType    = reflect.TypeOf(resource.Quantity{})
+	marshalerType   = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// schemaForType builds an OpenAPI schema from a Go type's JSON encoding.
+func schemaForType(t reflect.Type) *openAPISchema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t {
+	case timeType, microTimeType, stdTimeType:
+		return &openAPISchema{Type: "string", Format: "date-time"}
+	case durationType:
+		return &openAPISchema{Type: "string"}
+	case intOrStringType, quantityType:
+		return &openAPISchema{IntOrString: true}
+	}
+	// Anything else with custom JSON encoding (e.g. metav1.FieldsV1) is opaque
+	if t.Kind() == reflect.Struct && (t.Implements(marshalerType) || reflect.PointerTo(t).Implements(marshalerType)) {
+		return &openAPISchema{Type: "object", PreserveUnknown: true}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &openAPISchema{Type: "string"}
+	case reflect.Bool:
+		return &openAPISchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &openAPISchema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &openAPISchema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &openAPISchema{Type: "number", Format: "double"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &openAPISchema{Type: "string", Format: "byte"}
+		}
+		return &openAPISchema{Type: "array", Items: schemaForType(t.Elem())}
+	case reflect.Map:
+		return &openAPISchema{Type: "object", AdditionalProperties: schemaForType(t.Elem())}
+	case reflect.Struct:
+		s := &openAPISchema{Type: "object", Properties: map[string]*openAPISchema{}}
+		addStructProperties(s, t)
+		return s
+	}
+	return &openAPISchema{PreserveUnknown: true}
+}
+
+func addStructProperties(s *openAPISchema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		// Embedded structs without a name (or with ",inline") flatten into the parent
+		if name == "" && (f.Anonymous || strings.Contains(opts, "inline")) {
+			ft := f.Type
+			for ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				addStructProperties(s, ft)
+				continue
+			}
+		}
+		if name == "" {
+			name = f.Name
+		}
+		s.Properties[name] = schemaForType(f.Type)
+	}
+}
+
+// applySchemaRules attaches the per-kind constraints to the generated schema.
+func applySchemaRules(root *openAPISchema, rules map[string]schemaRule) {
+	for path, rule := range rules {
+		parent, leaf := root, root
+		for _, part := range strings.Split(path, ".") {
+			name := strings.TrimSuffix(part, "[]")
+			parent = leaf
+			leaf = parent.Properties[name]
+			if leaf == nil {
+				panic("openapi: schema rule for unknown field " + path)
+			}
+			if strings.HasSuffix(part, "[]") {
+				parent = leaf
+				leaf = leaf.Items
+			}
+		}
+		if rule.Required && !strings.HasSuffix(path, "[]") {
+			parent.Required = append(parent.Required, path[strings.LastIndex(path, ".")+1:])
+		}
+		if rule.Enum != nil {
+			leaf.Enum = rule.Enum
+		}
+		if rule.Format != "" {
+			leaf.Format = rule.Format
+		}
+		if rule.Pattern != "" {
+			leaf.Pattern = rule.Pattern
+		}
+		leaf.Minimum, leaf.Maximum = rule.Min, rule.Max
+	}
+}
+
+// buildSchemas generates the schema of each kind, keyed by kind.
+func buildSchemas(kinds []crdSchemaKind) map[string]*openAPISchema {
+	out := make(map[string]*openAPISchema, len(kinds))
+	for _, k := range kinds {
+		s := schemaForType(k.Type)
+		s.Description = k.Description
+		s.GVK = []map[string]string{{"group": "", "version": "v1", "kind": k.Kind}}
+		applySchemaRules(s, k.Rules)
+		out[k.Kind] = s
+	}
+	return out
+}
+
+// crdSchemas returns the schema for every published mkube kind, keyed by
+// kind. The types are static, so the result is computed once.
+var crdSchemas = sync.OnceValue(func() map[string]*openAPISchema {
+	return buildSchemas(crdSchemaKinds)
+})
+
+// openAPIDocument renders the /openapi/v3/api/v1 document and its hash.
+var openAPIDocument = sync.OnceValues(func() ([]byte, string) {
+	schemas := make(map[string]*openAPISchema)
+	for kind, s := range crdSchemas() {
+		schemas["mkube.v1."+kind] = s
+	}
+	for kind, s := range buildSchemas(coreSchemaKinds) {
+		schemas["io.k8s.api.core.v1."+kind] = s
+	}
+	doc := map[string]interface{}{
+		"openapi": "3.0.0",
+		"info":    map[string]string{"title": "mkube", "version": "v1"},
+		"paths":   map[string]interface{}{},
+		"components": map[string]interface{}{
+			"schemas": schemas,
+		},
+	}
+	data, _ := json.Marshal(doc)
+	sum := sha256.Sum256(data)
+	return data, strings.ToUpper(hex.EncodeToString(sum[:16]))
+})
+
+// ─── Handlers ───────────────────────────────────────────────────────────────
+
+// handleOpenAPIIndex serves the OpenAPI v3 discovery document used by
+// kubectl/oc to locate per-group-version specs.
+func (p *MicroKubeProvider) handleOpenAPIIndex(w http.ResponseWriter, r *http.Request) {
+	_, hash := openAPIDocument()
+	podWriteJSON(w, http.StatusOK, map[string]interface{}{
+		"paths": map[string]interface{}{
+			"api/v1": map[string]string{
+				"serverRelativeURL": "/openapi/v3/api/v1?hash=" + hash,
+			},
+		},
+	})
+}
+
+// handleOpenAPIV1 serves the OpenAPI v3 spec for the core v1 group, which
+// carries the Kubernetes core kinds mkube serves and all mkube custom kinds.
+func (p *MicroKubeProvider) handleOpenAPIV1(w http.ResponseWriter, r *http.Request) {
+	data, hash := openAPIDocument()
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Etag", `"`+hash+`"`)
+	if r.URL.Query().Get("hash") == hash {
+		w.Header().Set("Cache-Control", "public, immutable")
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
@@ -0,0 +1,150 @@
+package provider
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/glennswest/mkube/pkg/network"
+)
+
+// overlayInterval is how often the overlay mesh is reconciled; it matches
+// the cluster manager's peer health checks, so a peer that goes down loses
+// its tunnels within one more interval.
+const overlayInterval = 15 * time.Second
+
+// RunOverlayMesh keeps full-mesh tunnels between this node and every
+// healthy peer for each Network with spec.spanNodes (or spanNodes in the
+// config file), plus WireGuard site links to every peer when
+// cluster.wireguard is enabled. Does nothing when clustering is disabled.
+func (p *MicroKubeProvider) RunOverlayMesh(ctx context.Context) {
+	if p.clusterMgr == nil || p.deps.NetworkMgr == nil {
+		return
+	}
+	log := p.deps.Logger.Named("overlay")
+	log.Infow("overlay mesh controller starting", "interval", overlayInterval,
+		"tunnelAddress", p.deps.Config.Cluster.TunnelAddress)
+
+	ticker := time.NewTicker(overlayInterval)
+	defer ticker.Stop()
+
+	for {
+		p.overlayTick(ctx, log)
+		select {
+		case <-ctx.Done():
+			log.Info("overlay mesh controller stopping")
+			return
+		case <-ticker.C:
+		case <-p.overlayKick:
+		}
+	}
+}
+
+// overlayTick reconciles the mesh once against the current peer health.
+func (p *MicroKubeProvider) overlayTick(ctx context.Context, log *zap.SugaredLogger) {
+	cfg := p.overlayConfig(ctx)
+	if len(cfg.Networks) > 0 && cfg.LocalAddress == "" {
+		log.Warnw("spanning networks configured but cluster.tunnelAddress is not set; overlay disabled",
+			"networks", cfg.Networks)
+		cfg.Networks = nil
+	}
+	if err := p.deps.NetworkMgr.ReconcileMesh(ctx, cfg); err != nil {
+		log.Warnw("overlay mesh reconcile incomplete", "error", err)
+	}
+}
+
+// overlayConfig builds the mesh view: spanning networks from the config
+// file and Network CRDs, all cluster nodes for the IPAM split, and the
+// underlay addresses of the peers that are currently healthy. With
+// WireGuard enabled it also carries this node's private key and each
+// peer's public key and network CIDRs. Networks are copied under the read
+// lock; store reads, key generation and name lookups happen after it is
+// released so API handlers are not held up.
+func (p *MicroKubeProvider) overlayConfig(ctx context.Context) network.MeshConfig {
+	cfg := network.MeshConfig{
+		LocalNode:    p.clusterMgr.NodeName(),
+		LocalAddress: p.deps.Config.Cluster.TunnelAddress,
+		Nodes:        p.clusterMgr.AllNodes(),
+	}
+	wg := p.deps.Config.Cluster.WireGuard
+
+	span := make(map[string]bool)
+	for _, def := range p.deps.Config.Networks {
+		if def.SpanNodes {
+			span[def.Name] = true
+		}
+	}
+	peers := p.clusterMgr.Peers()
+	allowedIPs := make(map[string][]string, len(peers))
+	p.mu.RLock()
+	for name, n := range p.networks {
+		if n.Spec.SpanNodes {
+			span[name] = true
+		}
+	}
+	if wg.Enabled {
+		for _, peer := range peers {
+			allowedIPs[peer.Name] = p.peerAllowedIPs(peer.Networks)
+		}
+	}
+	p.mu.RUnlock()
+
+	for name := range span {
+		cfg.Networks = append(cfg.Networks, name)
+	}
+	sort.Strings(cfg.Networks)
+
+	if wg.Enabled {
+		key, err := p.ensureWireGuardKey(ctx)
+		if err != nil {
+			p.deps.Logger.Warnw("WireGuard key unavailable; site links disabled", "error", err)
+			wg.Enabled = false
+		}
+		cfg.WireGuardKey, cfg.WireGuardPort = key, wg.ListenPort
+	}
+
+	for _, peer := range peers {
+		if !p.clusterMgr.IsPeerHealthy(peer.Name) {
+			continue
+		}
+		addr := peer.TunnelAddress
+		if addr == "" {
+			addr = peerHost(ctx, p.resolver(), peer.Address)
+		}
+		if addr == "" {
+			p.deps.Logger.Warnw("no underlay address for overlay peer", "peer", peer.Name, "address", peer.Address)
+			continue
+		}
+		mp := network.MeshPeer{Name: peer.Name, Address: addr}
+		if wg.Enabled {
+			if cm := p.loadWireGuardKeyConfigMap(ctx, peer.Name); cm != nil {
+				mp.PublicKey = cm.Data["publicKey"]
+			}
+			mp.AllowedIPs = allowedIPs[peer.Name]
+		}
+		cfg.Peers = append(cfg.Peers, mp)
+	}
+	return cfg
+}
+
+// peerHost extracts the IP from a peer's HTTP address
+// ("http://192.168.1.160:8082" -> "192.168.1.160"). Hostnames are resolved.
+func peerHost(ctx context.Context, r *net.Resolver, address string) string {
+	u, err := url.Parse(address)
+	if err != nil || u.Hostname() == "" {
+		return ""
+	}
+	host := u.Hostname()
+	if net.ParseIP(host) != nil {
+		return host
+	}
+	addrs, err := r.LookupHost(ctx, host)
+	if err != nil || len(addrs) == 0 {
+		return ""
+	}
+	return addrs[0]
+}
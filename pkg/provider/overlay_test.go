@@ -0,0 +1,105 @@
+package provider
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/glennswest/mkube/pkg/cluster"
+	"github.com/glennswest/mkube/pkg/config"
+	"github.com/glennswest/mkube/pkg/network"
+)
+
+func TestOverlayConfigDoesNotBlockReaders(t *testing.T) {
+	p, _ := newTestProvider(t)
+	ctx := context.Background()
+	p.deps.Config.Cluster = config.ClusterConfig{
+		Enabled:       true,
+		TunnelAddress: "10.0.0.1",
+		Peers:         []config.PeerConfig{{Name: "node-b", Address: "http://10.0.0.2:8082"}},
+		WireGuard:     config.WireGuardConfig{Enabled: true},
+	}
+	p.clusterMgr = cluster.New("node-a", p.deps.Config.Cluster, nil, "arm64", zap.NewNop().Sugar())
+
+	// The first pass generates the keypair
+	if cfg := p.overlayConfig(ctx); cfg.WireGuardKey == "" {
+		t.Fatal("no WireGuard key generated")
+	}
+
+	// Later passes only take the read lock, so an API reader holding it
+	// doesn't stall the overlay loop (or the other way round)
+	p.mu.RLock()
+	done := make(chan struct{})
+	go func() {
+		p.overlayConfig(ctx)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Error("overlayConfig blocked behind a reader")
+	}
+	p.mu.RUnlock()
+	<-done
+}
+
+func TestWireGuardPrivateKeyStaysLocal(t *testing.T) {
+	p, _ := newTestProvider(t)
+	ctx := context.Background()
+	p.deps.Config.Cluster = config.ClusterConfig{
+		Enabled:   true,
+		Peers:     []config.PeerConfig{{Name: "node-b", Address: "http://10.0.0.2:8082"}},
+		WireGuard: config.WireGuardConfig{Enabled: true},
+	}
+	p.clusterMgr = cluster.New("node-a", p.deps.Config.Cluster, nil, "arm64", zap.NewNop().Sugar())
+
+	// A peer's keypair Secret synced here by an older release
+	p.secrets["kube-system/wireguard-node-b"] = &corev1.Secret{
+		Data: map[string][]byte{"privateKey": []byte("peer-private")},
+	}
+
+	priv, err := p.ensureWireGuardKey(ctx)
+	if err != nil {
+		t.Fatalf("ensureWireGuardKey: %v", err)
+	}
+	s := p.secrets["kube-system/wireguard-node-a"]
+	if s == nil || string(s.Data["privateKey"]) != priv || len(s.Data) != 1 {
+		t.Fatalf("key Secret = %+v, want only privateKey", s)
+	}
+	if _, ok := p.secrets["kube-system/wireguard-node-b"]; ok {
+		t.Error("peer's WireGuard key Secret not deleted")
+	}
+
+	// Only the public key is published
+	pub, _ := network.WireGuardPublicKey(priv)
+	cm := p.configMaps["kube-system/wireguard-node-a"]
+	if cm == nil || cm.Data["publicKey"] != pub || len(cm.Data) != 1 {
+		t.Fatalf("published ConfigMap = %+v, want only publicKey %s", cm, pub)
+	}
+
+	// A restart reads the same key back from the Secret
+	restarted, _ := newTestProvider(t)
+	restarted.deps.Config.Cluster = p.deps.Config.Cluster
+	restarted.clusterMgr = p.clusterMgr
+	restarted.secrets["kube-system/wireguard-node-a"] = s
+	if again, _ := restarted.ensureWireGuardKey(ctx); again != priv {
+		t.Error("private key not reloaded from the Secret")
+	}
+
+	// Rotation replaces both the Secret and the published key
+	p.mu.Lock()
+	rotated, err := p.rotateWireGuardKey(ctx)
+	p.mu.Unlock()
+	if err != nil {
+		t.Fatalf("rotateWireGuardKey: %v", err)
+	}
+	if rotated.Data["publicKey"] == pub {
+		t.Error("rotation kept the old public key")
+	}
+	if string(p.secrets["kube-system/wireguard-node-a"].Data["privateKey"]) == priv {
+		t.Error("rotation kept the old private key")
+	}
+}
@@ -0,0 +1,762 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kruntime "k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/glennswest/mkube/pkg/network"
+	"github.com/glennswest/mkube/pkg/store"
+)
+
+// ─── Types ──────────────────────────────────────────────────────────────────
+
+// PortForward is a namespaced CRD that exposes a pod port on an external
+// interface or address through destination NAT (RouterOS /ip/firewall/nat,
+// nftables on Linux). The rule follows the target when its IP changes.
+type PortForward struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              PortForwardSpec   `json:"spec"`
+	Status            PortForwardStatus `json:"status,omitempty"`
+}
+
+// PortForwardSpec defines the desired state of a PortForward.
+type PortForwardSpec struct {
+	Interface    string            `json:"interface,omitempty"`  // external in-interface, e.g. "ether1"; empty = any
+	ExternalIP   string            `json:"externalIP,omitempty"` // external IPv4 address; empty = any
+	ExternalPort int               `json:"externalPort"`
+	Protocol     string            `json:"protocol,omitempty"` // tcp (default) or udp
+	Target       PortForwardTarget `json:"target"`
+}
+
+// PortForwardTarget names what traffic is forwarded to. Exactly one of Pod
+// and Service is set; a Service is the Deployment of that name, and traffic
+// goes to its first running replica.
+type PortForwardTarget struct {
+	Pod     string `json:"pod,omitempty"`
+	Service string `json:"service,omitempty"`
+	Port    int    `json:"port"`
+}
+
+// PortForwardStatus reports the observed state of a PortForward.
+type PortForwardStatus struct {
+	Phase     string `json:"phase"`               // Active, Pending (target has no IP), Failed
+	TargetPod string `json:"targetPod,omitempty"` // pod the rule points at
+	TargetIP  string `json:"targetIP,omitempty"`
+	UpdatedAt string `json:"updatedAt,omitempty"` // RFC3339, last time the target changed
+	Message   string `json:"message,omitempty"`
+}
+
+// PortForwardList is a list of PortForward objects.
+type PortForwardList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+	Items           []PortForward `json:"items"`
+}
+
+// DeepCopy returns a deep copy of the PortForward.
+func (f *PortForward) DeepCopy() *PortForward {
+	out := *f
+	out.ObjectMeta = *f.ObjectMeta.DeepCopy()
+	return &out
+}
+
+// defaultPortForward fills in spec defaults.
+func defaultPortForward(f *PortForward) {
+	if f.Spec.Protocol == "" {
+		f.Spec.Protocol = "tcp"
+	}
+}
+
+// ─── Reconcile ──────────────────────────────────────────────────────────────
+
+// portForwardInterval is how often NAT rules are checked against their
+// targets' current IPs.
+const portForwardInterval = 15 * time.Second
+
+// RunPortForwards keeps the driver's NAT rules in line with the PortForward
+// objects: rules follow their target's IP and are removed when the object
+// is deleted. Changes through the API are applied at once.
+func (p *MicroKubeProvider) RunPortForwards(ctx context.Context) {
+	if p.deps.NetworkMgr == nil {
+		return
+	}
+	log := p.deps.Logger.Named("portforward")
+	log.Infow("port forward controller starting", "interval", portForwardInterval)
+
+	ticker := time.NewTicker(portForwardInterval)
+	defer ticker.Stop()
+
+	for {
+		p.reconcilePortForwardsAsync(ctx)
+		select {
+		case <-ctx.Done():
+			log.Info("port forward controller stopping")
+			return
+		case <-ticker.C:
+		case <-p.portForwardKick:
+		}
+	}
+}
+
+// kickPortForwards asks the port forward controller to reconcile soon.
+func (p *MicroKubeProvider) kickPortForwards() {
+	select {
+	case p.portForwardKick <- struct{}{}:
+	default:
+	}
+}
+
+// resolvePortForwardTarget returns the pod a PortForward points at and its
+// IPv4 address, or "" if the target has none yet. Must be called with p.mu
+// held.
+func (p *MicroKubeProvider) resolvePortForwardTarget(f *PortForward) (pod, ip string) {
+	if f.Spec.Target.Pod != "" {
+		if pod, ok := p.pods[f.Namespace+"/"+f.Spec.Target.Pod]; ok {
+			return pod.Name, pod.Status.PodIP
+		}
+		return "", ""
+	}
+	deploy, ok := p.deployments[f.Namespace+"/"+f.Spec.Target.Service]
+	if !ok {
+		return "", ""
+	}
+	for _, pod := range p.deploymentPods(deploy) {
+		if pod.Status.Phase == corev1.PodRunning && pod.Status.PodIP != "" {
+			return pod.Name, pod.Status.PodIP
+		}
+	}
+	return "", ""
+}
+
+// reconcilePortForwards resolves every PortForward's target, pushes the
+// resulting rule set to the driver and records the outcome in status.
+// Used by the API handlers, which want the outcome in their response.
+// Must be called with p.mu held for writing.
+func (p *MicroKubeProvider) reconcilePortForwards(ctx context.Context) {
+	if p.deps.NetworkMgr == nil {
+		return
+	}
+	want, resolved := p.planPortForwards()
+	p.portForwardSyncMu.Lock()
+	err := p.deps.NetworkMgr.SyncPortForwards(ctx, want)
+	p.portForwardSyncMu.Unlock()
+	p.portForwardGen++
+	p.applyPortForwardSync(ctx, resolved, err)
+}
+
+// reconcilePortForwardsAsync is reconcilePortForwards for the controller
+// loop: targets are resolved under p.mu, but the driver I/O runs without
+// it, so API requests aren't held up by RouterOS or nftables. Taking
+// portForwardSyncMu before releasing p.mu keeps syncs in the order their
+// rule sets were planned, and a handler reconciling in between makes this
+// pass's statuses stale, so they are dropped. Must be called without p.mu
+// held.
+func (p *MicroKubeProvider) reconcilePortForwardsAsync(ctx context.Context) {
+	p.mu.Lock()
+	want, resolved := p.planPortForwards()
+	gen := p.portForwardGen
+	p.portForwardSyncMu.Lock()
+	p.mu.Unlock()
+
+	err := p.deps.NetworkMgr.SyncPortForwards(ctx, want)
+	p.portForwardSyncMu.Unlock()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.portForwardGen != gen {
+		return
+	}
+	p.applyPortForwardSync(ctx, resolved, err)
+}
+
+// planPortForwards resolves every PortForward's target into the NAT rules
+// to program and the status each forward gets if they apply cleanly. Must
+// be called with p.mu held.
+func (p *MicroKubeProvider) planPortForwards() ([]network.PortForward, map[string]PortForwardStatus) {
+	var want []network.PortForward
+	resolved := make(map[string]PortForwardStatus, len(p.portForwards))
+	for _, key := range sortedKeys(p.portForwards) {
+		f := p.portForwards[key]
+		st := PortForwardStatus{Phase: "Pending", UpdatedAt: f.Status.UpdatedAt}
+		st.TargetPod, st.TargetIP = p.resolvePortForwardTarget(f)
+		if st.TargetIP == "" {
+			st.Message = "target has no IP address"
+		} else {
+			st.Phase = "Active"
+			want = append(want, network.PortForward{
+				Owner:        key,
+				Protocol:     f.Spec.Protocol,
+				Interface:    f.Spec.Interface,
+				ExternalIP:   f.Spec.ExternalIP,
+				ExternalPort: f.Spec.ExternalPort,
+				TargetIP:     st.TargetIP,
+				TargetPort:   f.Spec.Target.Port,
+			})
+		}
+		if st.TargetIP != f.Status.TargetIP {
+			st.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+		}
+		resolved[key] = st
+	}
+	return want, resolved
+}
+
+// applyPortForwardSync records the outcome of a driver sync in the
+// forwards' status. A rule that failed marks only its own forward Failed;
+// an error not tied to one rule (listing failed, no driver support) marks
+// every active forward. Forwards deleted since planning are skipped. Must
+// be called with p.mu held for writing.
+func (p *MicroKubeProvider) applyPortForwardSync(ctx context.Context, resolved map[string]PortForwardStatus, err error) {
+	if err != nil {
+		p.deps.Logger.Warnw("port forward sync incomplete", "error", err)
+		failed, general := portForwardFailures(err)
+		for key, st := range resolved {
+			if st.Phase != "Active" {
+				continue
+			}
+			if msg, ok := failed[key]; ok {
+				st.Phase, st.Message = "Failed", msg
+			} else if general != nil {
+				st.Phase, st.Message = "Failed", general.Error()
+			}
+			resolved[key] = st
+		}
+	}
+
+	for key, st := range resolved {
+		f, ok := p.portForwards[key]
+		if !ok || f.Status == st {
+			continue
+		}
+		if f.Status.TargetIP != st.TargetIP {
+			p.deps.Logger.Infow("port forward target changed", "portforward", key,
+				"from", f.Status.TargetIP, "to", st.TargetIP, "pod", st.TargetPod)
+		}
+		f.Status = st
+		p.persistPortForward(ctx, f)
+	}
+}
+
+// portForwardFailures splits a SyncPortForwards error into the messages of
+// individual failed rules, keyed by owner, and the rest.
+func portForwardFailures(err error) (map[string]string, error) {
+	errs := []error{err}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		errs = joined.Unwrap()
+	}
+	failed := make(map[string]string)
+	var rest []error
+	for _, e := range errs {
+		var pfErr *network.PortForwardError
+		if errors.As(e, &pfErr) {
+			if failed[pfErr.Owner] != "" {
+				failed[pfErr.Owner] += "; "
+			}
+			failed[pfErr.Owner] += pfErr.Error()
+			continue
+		}
+		rest = append(rest, e)
+	}
+	return failed, errors.Join(rest...)
+}
+
+// ─── Store Operations ────────────────────────────────────────────────────────
+
+// LoadPortForwardsFromStore reads PortForwards from NATS. Their rules are
+// reconciled by RunPortForwards.
+func (p *MicroKubeProvider) LoadPortForwardsFromStore(ctx context.Context) {
+	if p.deps.Store == nil || p.deps.Store.PortForwards == nil {
+		return
+	}
+
+	keys, err := p.deps.Store.PortForwards.Keys(ctx, "")
+	if err != nil {
+		p.deps.Logger.Warnw("failed to list port forwards from store", "error", err)
+		return
+	}
+
+	for _, key := range keys {
+		var f PortForward
+		if _, err := p.deps.Store.PortForwards.GetJSON(ctx, key, &f); err != nil {
+			p.deps.Logger.Warnw("failed to read port forward from store", "key", key, "error", err)
+			continue
+		}
+		p.portForwards[f.Namespace+"/"+f.Name] = &f
+	}
+
+	if len(keys) > 0 {
+		p.deps.Logger.Infow("loaded port forwards from store", "count", len(keys))
+	}
+}
+
+func (p *MicroKubeProvider) persistPortForward(ctx context.Context, f *PortForward) {
+	if p.deps.Store != nil && p.deps.Store.PortForwards != nil {
+		key := f.Namespace + "." + f.Name
+		if _, err := p.deps.Store.PortForwards.PutJSON(ctx, key, f); err != nil {
+			p.deps.Logger.Warnw("failed to persist PortForward", "name", f.Name, "error", err)
+		}
+	}
+}
+
+// ─── CRUD Handlers ──────────────────────────────────────────────────────────
+
+func (p *MicroKubeProvider) handleListAllPortForwards(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("watch") == "true" {
+		p.handleWatchPortForwards(w, r)
+		return
+	}
+
+	items := make([]PortForward, 0, len(p.portForwards))
+	for _, f := range p.portForwards {
+		cp := f.DeepCopy()
+		cp.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "PortForward"}
+		items = append(items, *cp)
+	}
+
+	if wantsTable(r) {
+		podWriteJSON(w, http.StatusOK, portForwardListToTable(items))
+		return
+	}
+
+	podWriteJSON(w, http.StatusOK, PortForwardList{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "PortForwardList"},
+		Items:    items,
+	})
+}
+
+func (p *MicroKubeProvider) handleListNamespacedPortForwards(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("watch") == "true" {
+		p.handleWatchPortForwards(w, r)
+		return
+	}
+
+	ns := r.PathValue("namespace")
+	items := make([]PortForward, 0)
+	for _, f := range p.portForwards {
+		if f.Namespace == ns {
+			cp := f.DeepCopy()
+			cp.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "PortForward"}
+			items = append(items, *cp)
+		}
+	}
+
+	if wantsTable(r) {
+		podWriteJSON(w, http.StatusOK, portForwardListToTable(items))
+		return
+	}
+
+	podWriteJSON(w, http.StatusOK, PortForwardList{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "PortForwardList"},
+		Items:    items,
+	})
+}
+
+func (p *MicroKubeProvider) handleGetPortForward(w http.ResponseWriter, r *http.Request) {
+	ns := r.PathValue("namespace")
+	name := r.PathValue("name")
+	key := ns + "/" + name
+
+	f, ok := p.portForwards[key]
+	if !ok {
+		http.Error(w, fmt.Sprintf("PortForward %q not found", name), http.StatusNotFound)
+		return
+	}
+
+	cp := f.DeepCopy()
+	cp.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "PortForward"}
+
+	if wantsTable(r) {
+		podWriteJSON(w, http.StatusOK, portForwardListToTable([]PortForward{*cp}))
+		return
+	}
+
+	podWriteJSON(w, http.StatusOK, cp)
+}
+
+func (p *MicroKubeProvider) handleCreatePortForward(w http.ResponseWriter, r *http.Request) {
+	ns := r.PathValue("namespace")
+
+	var f PortForward
+	if !decodeCRDBody(w, r, "PortForward", false, &f) {
+		return
+	}
+
+	if f.Name == "" {
+		http.Error(w, "PortForward name is required", http.StatusBadRequest)
+		return
+	}
+
+	f.Namespace = ns
+	key := ns + "/" + f.Name
+
+	if _, exists := p.portForwards[key]; exists {
+		http.Error(w, fmt.Sprintf("PortForward %q already exists", f.Name), http.StatusConflict)
+		return
+	}
+
+	f.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "PortForward"}
+	defaultPortForward(&f)
+	if !p.admitCRD(w, &f, nil) {
+		return
+	}
+
+	if f.CreationTimestamp.IsZero() {
+		f.CreationTimestamp = metav1.Now()
+	}
+	f.Status = PortForwardStatus{Phase: "Pending"}
+
+	p.portForwards[key] = &f
+	p.reconcilePortForwards(r.Context())
+	p.persistPortForward(r.Context(), &f)
+
+	podWriteJSON(w, http.StatusCreated, &f)
+}
+
+func (p *MicroKubeProvider) handleUpdatePortForward(w http.ResponseWriter, r *http.Request) {
+	ns := r.PathValue("namespace")
+	name := r.PathValue("name")
+	key := ns + "/" + name
+
+	old, ok := p.portForwards[key]
+	if !ok {
+		http.Error(w, fmt.Sprintf("PortForward %q not found", name), http.StatusNotFound)
+		return
+	}
+
+	var f PortForward
+	if !decodeCRDBody(w, r, "PortForward", false, &f) {
+		return
+	}
+	f.Name = name
+	f.Namespace = ns
+	f.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "PortForward"}
+	defaultPortForward(&f)
+	if !p.admitCRD(w, &f, old) {
+		return
+	}
+
+	if f.CreationTimestamp.IsZero() {
+		f.CreationTimestamp = old.CreationTimestamp
+	}
+	f.Status = old.Status
+
+	p.portForwards[key] = &f
+	p.reconcilePortForwards(r.Context())
+	p.persistPortForward(r.Context(), &f)
+
+	podWriteJSON(w, http.StatusOK, &f)
+}
+
+func (p *MicroKubeProvider) handlePatchPortForward(w http.ResponseWriter, r *http.Request) {
+	ns := r.PathValue("namespace")
+	name := r.PathValue("name")
+	key := ns + "/" + name
+
+	existing, ok := p.portForwards[key]
+	if !ok {
+		http.Error(w, fmt.Sprintf("PortForward %q not found", name), http.StatusNotFound)
+		return
+	}
+
+	merged := existing.DeepCopy()
+
+	if !decodeCRDBody(w, r, "PortForward", true, merged) {
+		return
+	}
+	merged.Name = name
+	merged.Namespace = ns
+	merged.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "PortForward"}
+	merged.CreationTimestamp = existing.CreationTimestamp
+	merged.Status = existing.Status
+	defaultPortForward(merged)
+	if !p.admitCRD(w, merged, existing) {
+		return
+	}
+
+	p.portForwards[key] = merged
+	p.reconcilePortForwards(r.Context())
+	p.persistPortForward(r.Context(), merged)
+
+	podWriteJSON(w, http.StatusOK, merged)
+}
+
+func (p *MicroKubeProvider) handleDeletePortForward(w http.ResponseWriter, r *http.Request) {
+	ns := r.PathValue("namespace")
+	name := r.PathValue("name")
+	key := ns + "/" + name
+
+	if _, ok := p.portForwards[key]; !ok {
+		http.Error(w, fmt.Sprintf("PortForward %q not found", name), http.StatusNotFound)
+		return
+	}
+
+	if p.deps.Store != nil && p.deps.Store.PortForwards != nil {
+		storeKey := ns + "." + name
+		if err := p.deps.Store.PortForwards.Delete(r.Context(), storeKey); err != nil {
+			http.Error(w, fmt.Sprintf("deleting PortForward from store: %v", err), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	delete(p.portForwards, key)
+	p.reconcilePortForwards(r.Context())
+
+	podWriteJSON(w, http.StatusOK, metav1.Status{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Status"},
+		Status:   "Success",
+		Message:  fmt.Sprintf("PortForward %q deleted", name),
+	})
+}
+
+// ─── Watch ──────────────────────────────────────────────────────────────────
+
+func (p *MicroKubeProvider) handleWatchPortForwards(w http.ResponseWriter, r *http.Request) {
+	if p.deps.Store == nil || p.deps.Store.PortForwards == nil {
+		http.Error(w, "watch requires NATS store", http.StatusServiceUnavailable)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Transfer-Encoding", "chunked")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	ctx := r.Context()
+	enc := json.NewEncoder(w)
+
+	p.mu.RLock()
+	snapshot := make([]*PortForward, 0, len(p.portForwards))
+	for _, c := range p.portForwards {
+		snapshot = append(snapshot, c.DeepCopy())
+	}
+	p.mu.RUnlock()
+
+	for _, c := range snapshot {
+		c.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "PortForward"}
+		if err := enc.Encode(K8sWatchEvent{Type: "ADDED", Object: c}); err != nil {
+			return
+		}
+		flusher.Flush()
+	}
+
+	events, err := p.deps.Store.PortForwards.WatchAll(ctx)
+	if err != nil {
+		return
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case evt, ok := <-events:
+			if !ok {
+				return
+			}
+			var c PortForward
+			if evt.Type == store.EventDelete {
+				c = PortForward{
+					TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "PortForward"},
+					ObjectMeta: metav1.ObjectMeta{Name: evt.Key},
+				}
+			} else {
+				if err := json.Unmarshal(evt.Value, &c); err != nil {
+					continue
+				}
+				c.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "PortForward"}
+			}
+			if err := enc.Encode(K8sWatchEvent{Type: string(evt.Type), Object: &c}); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// ─── Table Format ───────────────────────────────────────────────────────────
+
+func portForwardListToTable(items []PortForward) *metav1.Table {
+	table := &metav1.Table{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "meta.k8s.io/v1",
+			Kind:       "Table",
+		},
+		ColumnDefinitions: []metav1.TableColumnDefinition{
+			{Name: "Name", Type: "string", Format: "name"},
+			{Name: "Protocol", Type: "string"},
+			{Name: "External", Type: "string"},
+			{Name: "Target", Type: "string"},
+			{Name: "Target-IP", Type: "string"},
+			{Name: "Status", Type: "string"},
+			{Name: "Age", Type: "string"},
+		},
+	}
+
+	sort.Slice(items, func(i, j int) bool { return items[i].Name < items[j].Name })
+
+	for i := range items {
+		f := &items[i]
+
+		age := "<unknown>"
+		if !f.CreationTimestamp.IsZero() {
+			age = formatAge(time.Since(f.CreationTimestamp.Time))
+		}
+
+		external := f.Spec.ExternalIP
+		if external == "" {
+			external = "*"
+		}
+		external += ":" + strconv.Itoa(f.Spec.ExternalPort)
+		if f.Spec.Interface != "" {
+			external = f.Spec.Interface + "/" + external
+		}
+		target := "pod/" + f.Spec.Target.Pod
+		if f.Spec.Target.Service != "" {
+			target = "svc/" + f.Spec.Target.Service
+		}
+		target += ":" + strconv.Itoa(f.Spec.Target.Port)
+		targetIP := f.Status.TargetIP
+		if targetIP == "" {
+			targetIP = "-"
+		}
+
+		raw, _ := json.Marshal(map[string]interface{}{
+			"kind":       "PartialObjectMetadata",
+			"apiVersion": "meta.k8s.io/v1",
+			"metadata": map[string]interface{}{
+				"name":              f.Name,
+				"namespace":         f.Namespace,
+				"creationTimestamp": f.CreationTimestamp.Format(time.RFC3339),
+			},
+		})
+
+		table.Rows = append(table.Rows, metav1.TableRow{
+			Cells: []interface{}{
+				f.Name,
+				f.Spec.Protocol,
+				external,
+				target,
+				targetIP,
+				f.Status.Phase,
+				age,
+			},
+			Object: kruntime.RawExtension{Raw: raw},
+		})
+	}
+
+	return table
+}
+
+// ─── Consistency ────────────────────────────────────────────────────────────
+
+func (p *MicroKubeProvider) checkPortForwardCRDs(ctx context.Context) []CheckItem {
+	var items []CheckItem
+
+	if p.deps.Store != nil && p.deps.Store.PortForwards != nil {
+		storeKeys, err := p.deps.Store.PortForwards.Keys(ctx, "")
+		if err == nil {
+			storeSet := make(map[string]bool, len(storeKeys))
+			for _, k := range storeKeys {
+				storeSet[k] = true
+			}
+
+			for key, f := range p.portForwards {
+				storeKey := f.Namespace + "." + f.Name
+				if storeSet[storeKey] {
+					items = append(items, CheckItem{
+						Name:    fmt.Sprintf("portforward/%s", key),
+						Status:  "pass",
+						Message: "PortForward CRD synced with NATS",
+					})
+				} else {
+					items = append(items, CheckItem{
+						Name:    fmt.Sprintf("portforward/%s", key),
+						Status:  "fail",
+						Message: "PortForward CRD in memory but not in NATS store",
+					})
+				}
+				delete(storeSet, storeKey)
+			}
+
+			for storeKey := range storeSet {
+				items = append(items, CheckItem{
+					Name:    fmt.Sprintf("portforward/%s", storeKey),
+					Status:  "warn",
+					Message: "PortForward CRD in NATS but not in memory",
+				})
+			}
+		}
+	}
+
+	// Verify each active forward has its NAT rule, pointing at the target
+	if p.deps.NetworkMgr == nil || len(p.portForwards) == 0 {
+		return items
+	}
+	rules, err := p.deps.NetworkMgr.PortForwards(ctx)
+	if err != nil {
+		return append(items, CheckItem{
+			Name:    "portforward-rules",
+			Status:  "warn",
+			Message: "cannot list NAT rules",
+			Details: err.Error(),
+		})
+	}
+	byOwner := make(map[string]network.PortForward, len(rules))
+	for _, rule := range rules {
+		byOwner[rule.Owner] = rule
+	}
+	for key, f := range p.portForwards {
+		checkName := fmt.Sprintf("portforward-rule/%s", key)
+		_, ip := p.resolvePortForwardTarget(f)
+		rule, ok := byOwner[key]
+		switch {
+		case ip == "":
+			items = append(items, CheckItem{
+				Name:    checkName,
+				Status:  "warn",
+				Message: "PortForward target has no IP address",
+			})
+		case !ok:
+			items = append(items, CheckItem{
+				Name:    checkName,
+				Status:  "fail",
+				Message: "PortForward has no NAT rule",
+				Details: f.Status.Message,
+			})
+		case rule.TargetIP != ip || rule.TargetPort != f.Spec.Target.Port:
+			items = append(items, CheckItem{
+				Name:    checkName,
+				Status:  "fail",
+				Message: "NAT rule points at a stale target",
+				Details: fmt.Sprintf("rule=%s:%d target=%s:%d", rule.TargetIP, rule.TargetPort, ip, f.Spec.Target.Port),
+			})
+		default:
+			items = append(items, CheckItem{
+				Name:    checkName,
+				Status:  "pass",
+				Message: "NAT rule matches PortForward target",
+				Details: fmt.Sprintf("%s:%d -> %s:%d", rule.ExternalIP, rule.ExternalPort, ip, rule.TargetPort),
+			})
+		}
+	}
+
+	return items
+}
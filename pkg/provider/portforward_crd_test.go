@@ -0,0 +1,154 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/glennswest/mkube/pkg/network"
+)
+
+func TestPortForwardLifecycle(t *testing.T) {
+	p, _ := newTestProvider(t)
+	ctx := context.Background()
+	web := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning, PodIP: "172.20.0.5"},
+	}
+	p.pods["default/web"] = web
+
+	mux := http.NewServeMux()
+	p.RegisterRoutes(mux)
+	h := p.WrapHandler(mux)
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rec
+	}
+	rules := func() map[string]string {
+		list, err := p.deps.NetworkMgr.PortForwards(ctx)
+		if err != nil {
+			t.Fatalf("PortForwards: %v", err)
+		}
+		out := make(map[string]string, len(list))
+		for _, r := range list {
+			out[r.Owner] = r.TargetIP
+		}
+		return out
+	}
+	const base = "/api/v1/namespaces/default/portforwards"
+
+	rec := do(http.MethodPost, base, `{"metadata":{"name":"http"},"spec":{"interface":"ether1","externalPort":8080,"target":{"pod":"web","port":80}}}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: %d %s", rec.Code, rec.Body)
+	}
+	var f PortForward
+	_ = json.Unmarshal(rec.Body.Bytes(), &f)
+	if f.Spec.Protocol != "tcp" || f.Status.Phase != "Active" || f.Status.TargetIP != "172.20.0.5" {
+		t.Fatalf("created forward = %+v", f)
+	}
+	if got := rules(); got["default/http"] != "172.20.0.5" {
+		t.Fatalf("NAT rules = %v", got)
+	}
+
+	// Rejected: same external port, no target, both targets
+	if rec := do(http.MethodPost, base, `{"metadata":{"name":"dup"},"spec":{"externalPort":8080,"target":{"pod":"web","port":81}}}`); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("duplicate external port: got %d, want 422", rec.Code)
+	}
+	if rec := do(http.MethodPost, base, `{"metadata":{"name":"both"},"spec":{"externalPort":9090,"target":{"pod":"web","service":"web","port":80}}}`); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("pod and service target: got %d, want 422", rec.Code)
+	}
+
+	// Target not running yet: the forward waits without a rule
+	if rec := do(http.MethodPost, base, `{"metadata":{"name":"dns"},"spec":{"externalPort":53,"protocol":"udp","target":{"service":"dns","port":53}}}`); rec.Code != http.StatusCreated {
+		t.Fatalf("create dns: %d %s", rec.Code, rec.Body)
+	}
+	if ph := p.portForwards["default/dns"].Status.Phase; ph != "Pending" {
+		t.Errorf("forward to missing service is %s, want Pending", ph)
+	}
+
+	// The rule follows the pod to its new address
+	p.mu.Lock()
+	web.Status.PodIP = "172.20.0.9"
+	p.reconcilePortForwards(ctx)
+	p.mu.Unlock()
+	if got := rules(); len(got) != 1 || got["default/http"] != "172.20.0.9" {
+		t.Errorf("NAT rules after IP change = %v", got)
+	}
+	for _, item := range p.checkPortForwardCRDs(ctx) {
+		if item.Name == "portforward-rule/default/http" && item.Status != "pass" {
+			t.Errorf("consistency: %s %s: %s", item.Name, item.Status, item.Message)
+		}
+	}
+
+	if rec := do(http.MethodDelete, base+"/http", ""); rec.Code != http.StatusOK {
+		t.Fatalf("delete: %d %s", rec.Code, rec.Body)
+	}
+	if got := rules(); len(got) != 0 {
+		t.Errorf("NAT rules after delete = %v", got)
+	}
+}
+
+func TestPortForwardControllerLocking(t *testing.T) {
+	p, _ := newTestProvider(t)
+	ctx := context.Background()
+	drv := &mockNetworkDriver{failAdd: map[string]bool{"default/ssh": true}}
+	netMgr, err := network.NewManager(p.deps.Config.Networks, drv, nil, p.deps.Logger)
+	if err != nil {
+		t.Fatalf("network.NewManager: %v", err)
+	}
+	p.deps.NetworkMgr = netMgr
+	p.pods["default/web"] = &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning, PodIP: "172.20.0.5"},
+	}
+	for name, port := range map[string]int{"http": 8080, "ssh": 2222} {
+		p.portForwards["default/"+name] = &PortForward{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       PortForwardSpec{Protocol: "tcp", ExternalPort: port, Target: PortForwardTarget{Pod: "web", Port: port}},
+		}
+	}
+
+	// The driver I/O runs without p.mu: an API reader gets in while the
+	// driver is blocked
+	entered, release := make(chan struct{}), make(chan struct{})
+	drv.onList = func() {
+		close(entered)
+		<-release
+	}
+	done := make(chan struct{})
+	go func() {
+		p.reconcilePortForwardsAsync(ctx)
+		close(done)
+	}()
+	<-entered
+	locked := make(chan struct{})
+	go func() {
+		p.mu.RLock()
+		p.mu.RUnlock()
+		close(locked)
+	}()
+	select {
+	case <-locked:
+	case <-time.After(5 * time.Second):
+		t.Error("provider lock held through the driver sync")
+	}
+	close(release)
+	<-done
+	drv.onList = nil
+
+	// Only the forward whose rule failed is marked Failed
+	if st := p.portForwards["default/ssh"].Status; st.Phase != "Failed" || !strings.Contains(st.Message, "in use") {
+		t.Errorf("failing forward status = %+v", st)
+	}
+	if st := p.portForwards["default/http"].Status; st.Phase != "Active" || st.Message != "" {
+		t.Errorf("healthy forward status = %+v, want Active", st)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net"
@@ -17,6 +18,7 @@ import (
 	"time"
 
 	"go.uber.org/zap"
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -25,8 +27,10 @@ import (
 	restclient "k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 
+	"github.com/glennswest/mkube/pkg/admission"
 	"github.com/glennswest/mkube/pkg/cluster"
 	"github.com/glennswest/mkube/pkg/config"
+	"github.com/glennswest/mkube/pkg/dns"
 	"github.com/glennswest/mkube/pkg/lifecycle"
 	"github.com/glennswest/mkube/pkg/namespace"
 	"github.com/glennswest/mkube/pkg/network"
@@ -90,6 +94,7 @@ type MicroKubeProvider struct {
 	startTime       time.Time
 	pods            map[string]*corev1.Pod       // namespace/name -> pod
 	configMaps      map[string]*corev1.ConfigMap // namespace/name -> configmap
+	secrets         map[string]*corev1.Secret    // namespace/name -> secret
 	bareMetalHosts  map[string]*BareMetalHost               // namespace/name -> BMH
 	deployments     map[string]*Deployment                  // namespace/name -> deployment
 	pvcs            map[string]*corev1.PersistentVolumeClaim // namespace/name -> PVC
@@ -98,18 +103,41 @@ type MicroKubeProvider struct {
 	iscsiCdroms     map[string]*ISCSICdrom                  // name -> ISCSICdrom (cluster-scoped)
 	bootConfigs      map[string]*BootConfig                  // name -> BootConfig (cluster-scoped)
 	hostReservations map[string]*HostReservation             // namespace/name -> HostReservation
+	ipAddressClaims  map[string]*IPAddressClaim              // namespace/name -> IPAddressClaim
+	portForwards     map[string]*PortForward                 // namespace/name -> PortForward
+	networkApplies   map[string]*NetworkApply                // network name -> latest change applied with a confirm timeout
 	jobRunners       map[string]*JobRunner                   // name -> JobRunner (cluster-scoped)
 	jobs             map[string]*Job                         // namespace/name -> Job
+	alertRules       map[string]*AlertRule                   // name -> AlertRule (cluster-scoped)
+	alertSilences    map[string]*AlertSilence                // name -> AlertSilence
+	mutatingWebhooks   map[string]*admissionregv1.MutatingWebhookConfiguration   // name -> config (cluster-scoped)
+	validatingWebhooks map[string]*admissionregv1.ValidatingWebhookConfiguration // name -> config (cluster-scoped)
+	admission          *admission.Chain                                          // enabled in-process admission plugins
 	jobLogBuf        *jobLogStore                            // in-memory job log buffers
+	agentTokens      *agentTokenStore                        // tokens handed to job agents (own lock)
 	dhcpIndex       *dhcpNetworkIndex            // precomputed DHCP reservation/subnet lookup
+	dhcpDevices     *dhcpInventory               // every MAC seen by DHCP (own lock); use devices()
+	dhcpDevicesLoad sync.Once                    // reads the inventory from NATS once the store is attached
 	events          []corev1.Event               // recent events (ring buffer, max 256)
 	notifyPodStatus func(*corev1.Pod)            // callback for pod status updates
 	pushNotify      chan registry.PushEvent       // internal channel for API push notifications
 	redeploying        map[string]bool              // pod keys currently being redeployed (skip in reconciler)
+	dnsTTLMu           sync.Mutex                   // guards dnsTTLLowered
+	dnsTTLLowered      map[string]bool              // pod keys whose DNS records carry the rollout TTL
+	dnsTTLDrain        func(context.Context, time.Duration) // waits for answers cached with the previous TTL to expire
 	createFailures     map[string]int               // pod key -> consecutive CreatePod failures
 	networkFailures    map[string]int               // pod key -> consecutive network health failures
+	gcKick             chan struct{}                // wakes the garbage collector early
+	overlayKick        chan struct{}                // wakes the overlay mesh controller early
+	portForwardKick    chan struct{}                // wakes the port forward controller early
+	portForwardSyncMu  sync.Mutex                   // orders NAT rule syncs; taken with p.mu held, kept after releasing it
+	portForwardGen     uint64                       // bumped by every synchronous port forward reconcile
+	gcPending          map[string]string            // kind/key -> last reported finalizer wait (dedups events)
 	consistencyRunning atomic.Bool                  // guards CheckConsistencyAsync against goroutine leaks
 	clusterMgr         *cluster.Manager             // nil if clustering is disabled
+	fallbackDNS        *fallbackDNS                 // nil until StartFallbackDNS, or if disabled
+	wgPeerSecretsPurge sync.Once                    // deletes peers' keypair Secrets synced by older releases
+	dnsUpdates         *dns.UpdateServer            // RFC 2136 listener; nil without TSIG keys
 }
 
 // SetStore sets the NATS store on the provider (used for deferred NATS connection).
@@ -125,12 +153,17 @@ func (p *MicroKubeProvider) SetStore(s *store.Store) {
 	p.LoadRegistriesFromStore(context.Background())
 	p.MigrateRegistryConfig(context.Background())
 	p.LoadConfigMapsFromStore(context.Background())
+	p.LoadSecretsFromStore(context.Background())
 	p.ReconcileNetworkConfigMaps(context.Background())
 	p.LoadISCSICdromsFromStore(context.Background())
 	p.LoadBootConfigsFromStore(context.Background())
 	p.LoadHostReservationsFromStore(context.Background())
+	p.LoadIPAddressClaimsFromStore(context.Background())
+	p.LoadPortForwardsFromStore(context.Background())
 	p.LoadJobRunnersFromStore(context.Background())
 	p.LoadJobsFromStore(context.Background())
+	p.LoadAlertRulesFromStore(context.Background())
+	p.LoadWebhookConfigurationsFromStore(context.Background())
 	p.startDHCPSubscription(context.Background())
 }
 
@@ -160,6 +193,7 @@ func NewMicroKubeProvider(deps Deps) (*MicroKubeProvider, error) {
 		startTime:  time.Now(),
 		pods:            make(map[string]*corev1.Pod),
 		configMaps:      make(map[string]*corev1.ConfigMap),
+		secrets:         make(map[string]*corev1.Secret),
 		bareMetalHosts:  make(map[string]*BareMetalHost),
 		deployments:     make(map[string]*Deployment),
 		pvcs:            make(map[string]*corev1.PersistentVolumeClaim),
@@ -168,14 +202,29 @@ func NewMicroKubeProvider(deps Deps) (*MicroKubeProvider, error) {
 		iscsiCdroms:     make(map[string]*ISCSICdrom),
 		bootConfigs:      make(map[string]*BootConfig),
 		hostReservations: make(map[string]*HostReservation),
+		ipAddressClaims:  make(map[string]*IPAddressClaim),
+		portForwards:     make(map[string]*PortForward),
+		networkApplies:   make(map[string]*NetworkApply),
 		jobRunners:       make(map[string]*JobRunner),
 		jobs:             make(map[string]*Job),
+		alertRules:       make(map[string]*AlertRule),
+		alertSilences:    make(map[string]*AlertSilence),
+		mutatingWebhooks:   make(map[string]*admissionregv1.MutatingWebhookConfiguration),
+		validatingWebhooks: make(map[string]*admissionregv1.ValidatingWebhookConfiguration),
 		jobLogBuf:        newJobLogStore(),
+		agentTokens:      newAgentTokenStore(),
 		dhcpIndex:       buildDHCPIndex(deps.Config.Networks),
+		dhcpDevices:     newDHCPInventory(),
 		pushNotify:      make(chan registry.PushEvent, 16),
 		redeploying:     make(map[string]bool),
+		dnsTTLLowered:   make(map[string]bool),
+		dnsTTLDrain:     sleepCtx,
 		createFailures:  make(map[string]int),
 		networkFailures: make(map[string]int),
+		gcKick:          make(chan struct{}, 1),
+		overlayKick:     make(chan struct{}, 1),
+		portForwardKick: make(chan struct{}, 1),
+		gcPending:       make(map[string]string),
 	}
 
 	// Load built-in default ConfigMaps derived from mkube config
@@ -183,6 +232,10 @@ func NewMicroKubeProvider(deps Deps) (*MicroKubeProvider, error) {
 		p.configMaps[cm.Namespace+"/"+cm.Name] = cm
 	}
 
+	if err := p.initAdmission(); err != nil {
+		return nil, fmt.Errorf("admission: %w", err)
+	}
+
 	// Register lifecycle failed callback so containers that exceed max
 	// restarts trigger a full pod recreate (fresh veth allocation).
 	if deps.LifecycleMgr != nil {
@@ -215,7 +268,7 @@ func (p *MicroKubeProvider) CreatePod(ctx context.Context, pod *corev1.Pod) erro
 	containerIPs := make(map[string]string) // container name → bare IP
 
 	// Device passthrough: allocate devices if annotations are present (StormBase only)
-	if sb, ok := p.deps.Runtime.(*stormbase.Client); ok {
+	if sb, ok := p.stormbaseClient(); ok {
 		if deviceClass := pod.Annotations[annotationDeviceClass]; deviceClass != "" {
 			count := uint32(1)
 			if countStr := pod.Annotations[annotationDeviceCount]; countStr != "" {
@@ -278,6 +331,10 @@ func (p *MicroKubeProvider) CreatePod(ctx context.Context, pod *corev1.Pod) erro
 		vethName := vethName(pod, i)
 		containerHostname := container.Name + "." + pod.Name
 		staticIP := pod.Annotations[annotationStaticIP]
+		netns, err := p.preparePortNamespace(ctx, name, vethName)
+		if err != nil {
+			return err
+		}
 		ip, gw, dnsServer, err := p.deps.NetworkMgr.AllocateInterface(ctx, vethName, containerHostname, networkName, staticIP)
 		if err != nil {
 			// If veth/IP exists from a previous failed attempt, clean up and retry.
@@ -290,6 +347,9 @@ func (p *MicroKubeProvider) CreatePod(ctx context.Context, pod *corev1.Pod) erro
 					log.Warnw("release failed, force-releasing veth", "veth", vethName, "error", releaseErr)
 					p.forceReleaseVeth(ctx, vethName)
 				}
+				if netns, err = p.preparePortNamespace(ctx, name, vethName); err != nil {
+					return err
+				}
 				ip, gw, dnsServer, err = p.deps.NetworkMgr.AllocateInterface(ctx, vethName, containerHostname, networkName, staticIP)
 			}
 			if err != nil {
@@ -310,7 +370,7 @@ func (p *MicroKubeProvider) CreatePod(ctx context.Context, pod *corev1.Pod) erro
 				dnsClient := p.deps.NetworkMgr.DNSClient()
 				if dnsClient != nil {
 					_ = dnsClient.CleanStaleRecords(ctx, endpoint, zoneID, containerHostname, bareIP)
-					if regErr := dnsClient.RegisterHost(ctx, endpoint, zoneID, containerHostname, bareIP, 60); regErr != nil {
+					if regErr := dnsClient.RegisterHost(ctx, endpoint, zoneID, containerHostname, bareIP, p.podDNSTTL(pod, config.DNSTTLPods)); regErr != nil {
 						log.Warnw("failed to register container in namespace zone", "namespace", namespaceName, "error", regErr)
 					}
 				}
@@ -399,6 +459,7 @@ func (p *MicroKubeProvider) CreatePod(ctx context.Context, pod *corev1.Pod) erro
 			DNS:         dnsServer,
 			Logging:     "true",
 			StartOnBoot: startOnBoot,
+			NetNS:       netns,
 		}
 
 		// Set root user for containers that need privileged port binding
@@ -476,8 +537,10 @@ func (p *MicroKubeProvider) CreatePod(ctx context.Context, pod *corev1.Pod) erro
 	}
 
 	// 9. Register DNS aliases (pod-level default + custom aliases from annotation)
+	// and the SRV/TXT records for named ports and vkube.io/txt
 	tracker.start(PhaseDNSRegister)
 	p.registerPodAliases(ctx, pod, networkName, namespaceName, containerIPs, log)
+	p.syncPodServiceRecords(ctx, pod, networkName, namespaceName, containerIPs, false, log)
 
 	tracker.done()
 
@@ -485,6 +548,9 @@ func (p *MicroKubeProvider) CreatePod(ctx context.Context, pod *corev1.Pod) erro
 	tracker.start(PhasePodReady)
 	p.pushLogMappings(ctx, pod, log)
 
+	// 11. Apply bandwidth limits now that every veth has its address
+	p.applyPodBandwidth(ctx, pod, log)
+
 	// Track the pod
 	p.pods[podKey(pod)] = pod.DeepCopy()
 
@@ -552,6 +618,37 @@ func (p *MicroKubeProvider) stopAndRemoveContainer(ctx context.Context, name, id
 	}
 }
 
+// stormbaseClient returns the stormd client behind the runtime, looking
+// through wrappers such as runtime.NamedNetNS.
+func (p *MicroKubeProvider) stormbaseClient() (*stormbase.Client, bool) {
+	rt := p.deps.Runtime
+	if w, ok := rt.(interface{ Unwrap() runtime.ContainerRuntime }); ok {
+		rt = w.Unwrap()
+	}
+	sb, ok := rt.(*stormbase.Client)
+	return sb, ok
+}
+
+// preparePortNamespace has the runtime create the network namespace the
+// container will run in and tells the network manager to put the container
+// end of veth there; call it before AllocateInterface. It returns the path
+// for the container's ContainerSpec.NetNS, "" for runtimes without
+// namespaces. Drivers that cannot move ports leave the veth on the host.
+func (p *MicroKubeProvider) preparePortNamespace(ctx context.Context, container, veth string) (string, error) {
+	nsr, ok := p.deps.Runtime.(runtime.NetNSProvider)
+	if !ok {
+		return "", nil
+	}
+	path, err := nsr.PrepareNetNS(ctx, container)
+	if err != nil {
+		return "", fmt.Errorf("preparing network namespace for %s: %w", container, err)
+	}
+	if err := p.deps.NetworkMgr.SetPortNamespace(veth, path); err != nil && !errors.Is(err, network.ErrNotSupported) {
+		return "", fmt.Errorf("setting network namespace of %s: %w", veth, err)
+	}
+	return path, nil
+}
+
 // forceReleaseVeth finds the RouterOS container holding a veth interface,
 // stops and removes it, then releases the veth. Used during CreatePod to
 // recover when an orphaned container blocks veth allocation.
@@ -589,6 +686,9 @@ func (p *MicroKubeProvider) UpdatePod(ctx context.Context, pod *corev1.Pod) erro
 	log := p.deps.Logger.With("pod", podKey(pod))
 	log.Infow("updating pod (blue-green)")
 
+	restoreTTL := p.lowerPodDNSTTL(ctx, []*corev1.Pod{pod})
+	defer restoreTTL()
+
 	if err := p.blueGreenUpdate(ctx, pod); err != nil {
 		log.Errorw("blue-green update failed, falling back to destructive update", "error", err)
 		p.cleanupStaging(ctx, pod)
@@ -713,6 +813,7 @@ func (p *MicroKubeProvider) blueGreenUpdate(ctx context.Context, pod *corev1.Pod
 
 	// ── Phase C: Register + track ───────────────────────────────────────
 	p.registerPodAliases(ctx, pod, networkName, namespaceName, newContainerIPs, log)
+	p.syncPodServiceRecords(ctx, pod, networkName, namespaceName, newContainerIPs, false, log)
 	p.pushLogMappings(ctx, pod, log)
 	p.pods[key] = pod.DeepCopy()
 
@@ -746,6 +847,10 @@ func (p *MicroKubeProvider) stagingExtractAndVerify(
 	}
 
 	// Allocate staging veth with dynamic IP (empty hostname = no DNS registration)
+	netns, err := p.preparePortNamespace(ctx, stg.stgName, stg.stgVeth)
+	if err != nil {
+		return err
+	}
 	_, _, dnsServer, err := p.deps.NetworkMgr.AllocateInterface(ctx, stg.stgVeth, "", networkName, "")
 	if err != nil {
 		return fmt.Errorf("allocating staging veth %s: %w", stg.stgVeth, err)
@@ -771,6 +876,7 @@ func (p *MicroKubeProvider) stagingExtractAndVerify(
 		DNS:         dnsServer,
 		Logging:     "true",
 		StartOnBoot: "false",
+		NetNS:       netns,
 	}
 	if p.networkHasDHCP(networkName) {
 		spec.User = "0:0"
@@ -845,6 +951,10 @@ func (p *MicroKubeProvider) cutoverContainer(
 
 	// Allocate production veth with SAME production IP (static)
 	containerHostname := stg.container.Name + "." + pod.Name
+	netns, err := p.preparePortNamespace(ctx, stg.prodName, stg.prodVeth)
+	if err != nil {
+		return "", err
+	}
 	ip, _, dnsServer, err := p.deps.NetworkMgr.AllocateInterface(
 		ctx, stg.prodVeth, containerHostname, networkName, stg.prodIP)
 	if err != nil {
@@ -858,7 +968,7 @@ func (p *MicroKubeProvider) cutoverContainer(
 		if endpoint, zoneID, nsErr := p.deps.Namespace.ResolveNamespace(namespaceName); nsErr == nil {
 			if dnsClient := p.deps.NetworkMgr.DNSClient(); dnsClient != nil {
 				_ = dnsClient.CleanStaleRecords(ctx, endpoint, zoneID, containerHostname, bareIP)
-				_ = dnsClient.RegisterHost(ctx, endpoint, zoneID, containerHostname, bareIP, 60)
+				_ = dnsClient.RegisterHost(ctx, endpoint, zoneID, containerHostname, bareIP, p.podDNSTTL(pod, config.DNSTTLPods))
 			}
 			p.deps.Namespace.AddContainerToNamespace(namespaceName, stg.prodName)
 		}
@@ -889,6 +999,7 @@ func (p *MicroKubeProvider) cutoverContainer(
 		DNS:         dnsServer,
 		Logging:     "true",
 		StartOnBoot: startOnBoot,
+		NetNS:       netns,
 	}
 	if p.networkHasDHCP(networkName) {
 		spec.User = "0:0"
@@ -1089,7 +1200,7 @@ func (p *MicroKubeProvider) DeletePod(ctx context.Context, pod *corev1.Pod) erro
 	namespaceName := pod.Annotations[annotationNamespace]
 
 	// Release device allocation if present (StormBase only)
-	if sb, ok := p.deps.Runtime.(*stormbase.Client); ok {
+	if sb, ok := p.stormbaseClient(); ok {
 		if allocID := pod.Annotations[annotationDeviceAllocation]; allocID != "" {
 			log.Infow("releasing device allocation", "allocation", allocID)
 			if err := sb.ReleaseDevices(ctx, allocID); err != nil {
@@ -1114,8 +1225,9 @@ func (p *MicroKubeProvider) DeletePod(ctx context.Context, pod *corev1.Pod) erro
 		}
 	}
 
-	// Deregister DNS aliases before releasing interfaces
+	// Deregister DNS aliases and SRV/TXT records before releasing interfaces
 	p.deregisterPodAliases(ctx, pod, networkName, namespaceName, containerIPs, log)
+	p.syncPodServiceRecords(ctx, pod, networkName, namespaceName, containerIPs, true, log)
 
 	// Progressive backoff durations for container removal retries.
 	backoffs := []time.Duration{
@@ -1300,12 +1412,15 @@ func (p *MicroKubeProvider) GetPodStatus(ctx context.Context, namespace, name st
 		phase = corev1.PodPending
 	}
 
-	// Look up pod IP from first container's veth
+	// Look up pod IPs from first container's veth (two on dual-stack networks)
 	var podIP string
+	var podIPs []corev1.PodIP
 	if len(pod.Spec.Containers) > 0 {
-		vn := vethName(pod, 0)
-		if ip, _, ok := p.deps.NetworkMgr.GetPortInfo(vn); ok {
-			podIP = ip
+		for _, ip := range p.deps.NetworkMgr.GetPortIPs(vethName(pod, 0)) {
+			podIPs = append(podIPs, corev1.PodIP{IP: ip})
+		}
+		if len(podIPs) > 0 {
+			podIP = podIPs[0].IP
 		}
 	}
 
@@ -1326,8 +1441,11 @@ func (p *MicroKubeProvider) GetPodStatus(ctx context.Context, namespace, name st
 			},
 		},
 	}
-	if podIP != "" {
-		status.PodIPs = []corev1.PodIP{{IP: podIP}}
+	if len(podIPs) > 0 {
+		status.PodIPs = podIPs
+	}
+	if cond := p.podBandwidthCondition(pod); cond != nil {
+		status.Conditions = append(status.Conditions, *cond)
 	}
 	return status, nil
 }
@@ -1883,6 +2001,10 @@ func (p *MicroKubeProvider) reconcile(ctx context.Context) error {
 		}
 	}
 
+	// 4c. Re-apply bandwidth shaping (limits are lost on restart and must
+	// follow address changes)
+	p.syncPodBandwidth(ctx)
+
 	// 5. Sync ConfigMap data to disk and recreate pods whose ConfigMaps changed
 	stepStart = time.Now()
 	p.syncConfigMapsToDisk(ctx)
@@ -2217,7 +2339,7 @@ func (p *MicroKubeProvider) runNodeHeartbeat(ctx context.Context, clientset kube
 			}
 
 			// Check if node is cordoned (stormbase backend only)
-			if sb, ok := p.deps.Runtime.(*stormbase.Client); ok {
+			if sb, ok := p.stormbaseClient(); ok {
 				cordoned, reason := sb.IsNodeCordoned(ctx)
 				node.Spec.Unschedulable = cordoned
 
@@ -2642,6 +2764,7 @@ func (p *MicroKubeProvider) registerPodAliases(ctx context.Context, pod *corev1.
 	}
 
 	dnsClient := p.deps.NetworkMgr.DNSClient()
+	ttl := p.podDNSTTL(pod, config.DNSTTLAliases)
 
 	for _, a := range aliases {
 		ip, ok := containerIPs[a.containerName]
@@ -2656,7 +2779,7 @@ func (p *MicroKubeProvider) registerPodAliases(ctx context.Context, pod *corev1.
 		}
 
 		// Register in network zone
-		if regErr := p.deps.NetworkMgr.RegisterDNS(ctx, networkName, a.hostname, ip); regErr != nil {
+		if regErr := p.deps.NetworkMgr.RegisterDNS(ctx, networkName, a.hostname, ip, ttl); regErr != nil {
 			log.Warnw("failed to register DNS alias", "alias", a.hostname, "ip", ip, "error", regErr)
 		} else {
 			log.Infow("DNS alias registered", "alias", a.hostname, "container", a.containerName, "ip", ip)
@@ -2665,7 +2788,7 @@ func (p *MicroKubeProvider) registerPodAliases(ctx context.Context, pod *corev1.
 		// Register in namespace zone (clean stale + register)
 		if nsZoneID != "" && dnsClient != nil {
 			_ = dnsClient.CleanStaleRecords(ctx, nsEndpoint, nsZoneID, a.hostname, ip)
-			if regErr := dnsClient.RegisterHost(ctx, nsEndpoint, nsZoneID, a.hostname, ip, 60); regErr != nil {
+			if regErr := dnsClient.RegisterHost(ctx, nsEndpoint, nsZoneID, a.hostname, ip, ttl); regErr != nil {
 				log.Warnw("failed to register DNS alias in namespace zone", "alias", a.hostname, "error", regErr)
 			}
 		}
@@ -2674,8 +2797,8 @@ func (p *MicroKubeProvider) registerPodAliases(ctx context.Context, pod *corev1.
 
 // reregisterPodDNS re-registers DNS records for all tracked pods.
 // This ensures pod DNS records survive DNS container restarts that wipe the zone.
-// Registers both container-level records (container.pod → IP) and pod-level
-// aliases (podName → IP).
+// Registers container-level records (container.pod → IP), pod-level
+// aliases (podName → IP) and the pod's SRV/TXT records.
 func (p *MicroKubeProvider) reregisterPodDNS(ctx context.Context) {
 	// Enable batch mode on the DNS client to cache record lists per zone.
 	// Without this, every RegisterDNS and CleanStaleDNS call fetches the
@@ -2686,32 +2809,40 @@ func (p *MicroKubeProvider) reregisterPodDNS(ctx context.Context) {
 	}
 
 	for _, pod := range p.pods {
-		networkName := pod.Annotations[annotationNetwork]
-		namespaceName := pod.Namespace
+		p.registerPodDNS(ctx, pod)
+	}
+}
 
-		// Rebuild containerIPs from the network manager's allocation records
-		containerIPs := make(map[string]string)
-		for i, c := range pod.Spec.Containers {
-			veth := vethName(pod, i)
-			if ip, _, ok := p.deps.NetworkMgr.GetPortInfo(veth); ok {
-				containerIPs[c.Name] = ip
-
-				// Register the container-level DNS record (container.pod → IP).
-				// This is normally done by AllocateInterface during CreatePod,
-				// but pods tracked via the "already exists" path never called it.
-				containerHostname := c.Name + "." + pod.Name
-				if err := p.deps.NetworkMgr.RegisterDNS(ctx, networkName, containerHostname, ip); err != nil {
-					p.deps.Logger.Warnw("failed to re-register container DNS",
-						"hostname", containerHostname, "ip", ip, "error", err)
-				}
-				// Clean stale IPs for this container hostname
-				_ = p.deps.NetworkMgr.CleanStaleDNS(ctx, networkName, containerHostname, ip)
+// registerPodDNS registers pod's container-level records, aliases and
+// SRV/TXT records with the TTLs its policy currently calls for.
+func (p *MicroKubeProvider) registerPodDNS(ctx context.Context, pod *corev1.Pod) {
+	networkName := pod.Annotations[annotationNetwork]
+	namespaceName := pod.Namespace
+	ttl := p.podDNSTTL(pod, config.DNSTTLPods)
+
+	// Rebuild containerIPs from the network manager's allocation records
+	containerIPs := make(map[string]string)
+	for i, c := range pod.Spec.Containers {
+		veth := vethName(pod, i)
+		if ip, _, ok := p.deps.NetworkMgr.GetPortInfo(veth); ok {
+			containerIPs[c.Name] = ip
+
+			// Register the container-level DNS record (container.pod → IP).
+			// This is normally done by AllocateInterface during CreatePod,
+			// but pods tracked via the "already exists" path never called it.
+			containerHostname := c.Name + "." + pod.Name
+			if err := p.deps.NetworkMgr.RegisterDNS(ctx, networkName, containerHostname, ip, ttl); err != nil {
+				p.deps.Logger.Warnw("failed to re-register container DNS",
+					"hostname", containerHostname, "ip", ip, "error", err)
 			}
+			// Clean stale IPs for this container hostname
+			_ = p.deps.NetworkMgr.CleanStaleDNS(ctx, networkName, containerHostname, ip)
 		}
+	}
 
-		if len(containerIPs) > 0 {
-			p.registerPodAliases(ctx, pod, networkName, namespaceName, containerIPs, p.deps.Logger)
-		}
+	if len(containerIPs) > 0 {
+		p.registerPodAliases(ctx, pod, networkName, namespaceName, containerIPs, p.deps.Logger)
+		p.syncPodServiceRecords(ctx, pod, networkName, namespaceName, containerIPs, false, p.deps.Logger)
 	}
 }
 
@@ -3025,6 +3156,11 @@ func (p *MicroKubeProvider) recordEvent(pod *corev1.Pod, reason, message, eventT
 		Count:          1,
 		Source:         corev1.EventSource{Component: "mkube", Host: p.nodeName},
 	}
+	p.appendEvent(evt)
+}
+
+// appendEvent adds an event to the ring buffer, dropping the oldest when full.
+func (p *MicroKubeProvider) appendEvent(evt corev1.Event) {
 	p.events = append(p.events, evt)
 	if len(p.events) > maxEvents {
 		p.events = p.events[len(p.events)-maxEvents:]
@@ -2,9 +2,9 @@ package provider
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net"
 	"net/http"
 	"time"
@@ -217,8 +217,7 @@ func (p *MicroKubeProvider) handleGetRegistry(w http.ResponseWriter, r *http.Req
 
 func (p *MicroKubeProvider) handleCreateRegistry(w http.ResponseWriter, r *http.Request) {
 	var reg Registry
-	if err := json.NewDecoder(r.Body).Decode(&reg); err != nil {
-		http.Error(w, fmt.Sprintf("invalid Registry JSON: %v", err), http.StatusBadRequest)
+	if !decodeCRDBody(w, r, "Registry", false, &reg) {
 		return
 	}
 
@@ -226,27 +225,15 @@ func (p *MicroKubeProvider) handleCreateRegistry(w http.ResponseWriter, r *http.
 		http.Error(w, "registry name is required", http.StatusBadRequest)
 		return
 	}
-	if reg.Spec.Network == "" {
-		http.Error(w, "registry network is required", http.StatusBadRequest)
-		return
-	}
-	if reg.Spec.StaticIP == "" {
-		http.Error(w, "registry staticIP is required", http.StatusBadRequest)
-		return
-	}
-
-	// Validate network exists
-	if _, exists := p.networks[reg.Spec.Network]; !exists {
-		http.Error(w, fmt.Sprintf("network %q not found", reg.Spec.Network), http.StatusBadRequest)
-		return
-	}
-
 	if _, exists := p.registries[reg.Name]; exists {
 		http.Error(w, fmt.Sprintf("registry %q already exists", reg.Name), http.StatusConflict)
 		return
 	}
 
 	reg.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "Registry"}
+	if !p.admitCRD(w, &reg, nil) {
+		return
+	}
 	if reg.CreationTimestamp.IsZero() {
 		reg.CreationTimestamp = metav1.Now()
 	}
@@ -300,12 +287,14 @@ func (p *MicroKubeProvider) handleUpdateRegistry(w http.ResponseWriter, r *http.
 	wasManaged := old.Spec.Managed
 
 	var reg Registry
-	if err := json.NewDecoder(r.Body).Decode(&reg); err != nil {
-		http.Error(w, fmt.Sprintf("invalid Registry JSON: %v", err), http.StatusBadRequest)
+	if !decodeCRDBody(w, r, "Registry", false, &reg) {
 		return
 	}
 	reg.Name = name
 	reg.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "Registry"}
+	if !p.admitCRD(w, &reg, old) {
+		return
+	}
 
 	if reg.CreationTimestamp.IsZero() {
 		reg.CreationTimestamp = old.CreationTimestamp
@@ -337,18 +326,15 @@ func (p *MicroKubeProvider) handlePatchRegistry(w http.ResponseWriter, r *http.R
 
 	merged := existing.DeepCopy()
 
-	body, err := io.ReadAll(r.Body)
-	if err != nil {
-		http.Error(w, fmt.Sprintf("reading body: %v", err), http.StatusBadRequest)
-		return
-	}
-	if err := json.Unmarshal(body, merged); err != nil {
-		http.Error(w, fmt.Sprintf("invalid patch JSON: %v", err), http.StatusBadRequest)
+	if !decodeCRDBody(w, r, "Registry", true, merged) {
 		return
 	}
 	merged.Name = name
 	merged.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "Registry"}
 	merged.CreationTimestamp = existing.CreationTimestamp
+	if !p.admitCRD(w, merged, existing) {
+		return
+	}
 
 	if p.deps.Store != nil && p.deps.Store.Registries != nil {
 		if _, err := p.deps.Store.Registries.PutJSON(r.Context(), name, merged); err != nil {
@@ -415,6 +401,63 @@ func (p *MicroKubeProvider) handleGetRegistryConfig(w http.ResponseWriter, r *ht
 	_, _ = w.Write([]byte(cfgYAML))
 }
 
+// handleGetRegistryCatalog proxies the registry's /v2/_catalog so API clients
+// (and the dashboard) can list repositories without reaching port 5000 directly.
+func (p *MicroKubeProvider) handleGetRegistryCatalog(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+
+	reg, ok := p.registries[name]
+	if !ok {
+		http.Error(w, fmt.Sprintf("registry %q not found", name), http.StatusNotFound)
+		return
+	}
+	if reg.Spec.StaticIP == "" {
+		http.Error(w, fmt.Sprintf("registry %q has no staticIP", name), http.StatusBadRequest)
+		return
+	}
+
+	scheme := "http"
+	client := &http.Client{Timeout: 5 * time.Second}
+	if reg.Spec.TLSCertFile != "" {
+		scheme = "https"
+		client.Transport = &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, //nolint:gosec // internal registry CA
+		}
+	}
+
+	url := fmt.Sprintf("%s://%s/v2/_catalog", scheme, net.JoinHostPort(reg.Spec.StaticIP, registryPort(reg)))
+	resp, err := client.Get(url)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("fetching catalog: %v", err), http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+
+	var catalog struct {
+		Repositories []string `json:"repositories"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&catalog); err != nil {
+		http.Error(w, fmt.Sprintf("decoding catalog: %v", err), http.StatusBadGateway)
+		return
+	}
+	if catalog.Repositories == nil {
+		catalog.Repositories = []string{}
+	}
+
+	podWriteJSON(w, http.StatusOK, map[string]interface{}{
+		"registry":     name,
+		"repositories": catalog.Repositories,
+	})
+}
+
+// registryPort returns the port a Registry listens on (default 5000).
+func registryPort(reg *Registry) string {
+	if len(reg.Spec.ListenAddr) > 1 && reg.Spec.ListenAddr[0] == ':' {
+		return reg.Spec.ListenAddr[1:]
+	}
+	return "5000"
+}
+
 // generateRegistryConfigYAML produces a YAML config matching config.RegistryConfig format.
 func (p *MicroKubeProvider) generateRegistryConfigYAML(reg *Registry) string {
 	listenAddr := reg.Spec.ListenAddr
@@ -485,14 +528,7 @@ func (p *MicroKubeProvider) enrichRegistryStatus(ctx context.Context, reg *Regis
 
 	// Liveness: HTTP GET /v2/ on registry port
 	if reg.Spec.StaticIP != "" {
-		port := "5000"
-		if reg.Spec.ListenAddr != "" && reg.Spec.ListenAddr != ":5000" {
-			// Extract port from listenAddr like ":5000"
-			if len(reg.Spec.ListenAddr) > 1 && reg.Spec.ListenAddr[0] == ':' {
-				port = reg.Spec.ListenAddr[1:]
-			}
-		}
-		reg.Status.Alive = probeHTTP(reg.Spec.StaticIP, port, "/v2/", 3*time.Second)
+		reg.Status.Alive = probeHTTP(reg.Spec.StaticIP, registryPort(reg), "/v2/", 3*time.Second)
 	}
 }
 
@@ -893,10 +929,7 @@ func (p *MicroKubeProvider) checkRegistryCRDs(ctx context.Context) []CheckItem {
 		if reg.Spec.StaticIP == "" {
 			continue
 		}
-		port := "5000"
-		if reg.Spec.ListenAddr != "" && len(reg.Spec.ListenAddr) > 1 && reg.Spec.ListenAddr[0] == ':' {
-			port = reg.Spec.ListenAddr[1:]
-		}
+		port := registryPort(reg)
 		alive := probeHTTP(reg.Spec.StaticIP, port, "/v2/", 3*time.Second)
 		if alive {
 			items = append(items, CheckItem{
@@ -0,0 +1,200 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// normalizeSecret applies the Kubernetes write semantics for Secrets:
+// stringData is merged into data (and not stored), and the type defaults
+// to Opaque.
+func normalizeSecret(s *corev1.Secret, ns, name string) {
+	s.Namespace = ns
+	s.Name = name
+	s.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "Secret"}
+	if s.Type == "" {
+		s.Type = corev1.SecretTypeOpaque
+	}
+	if len(s.StringData) > 0 {
+		if s.Data == nil {
+			s.Data = make(map[string][]byte, len(s.StringData))
+		}
+		for k, v := range s.StringData {
+			s.Data[k] = []byte(v)
+		}
+		s.StringData = nil
+	}
+}
+
+// putSecret persists a Secret to the NATS store and the in-memory map.
+func (p *MicroKubeProvider) putSecret(ctx context.Context, s *corev1.Secret) error {
+	if p.deps.Store != nil && p.deps.Store.Secrets != nil {
+		if _, err := p.deps.Store.Secrets.PutJSON(ctx, s.Namespace+"."+s.Name, s); err != nil {
+			return fmt.Errorf("persisting secret: %w", err)
+		}
+	}
+	p.secrets[s.Namespace+"/"+s.Name] = s
+	return nil
+}
+
+// handleCreateSecret creates or replaces a Secret.
+func (p *MicroKubeProvider) handleCreateSecret(w http.ResponseWriter, r *http.Request) {
+	ns := r.PathValue("namespace")
+
+	var s corev1.Secret
+	if err := json.NewDecoder(r.Body).Decode(&s); err != nil {
+		http.Error(w, fmt.Sprintf("invalid secret JSON: %v", err), http.StatusBadRequest)
+		return
+	}
+	if s.Name == "" {
+		http.Error(w, "secret name is required", http.StatusBadRequest)
+		return
+	}
+	normalizeSecret(&s, ns, s.Name)
+
+	if err := p.putSecret(r.Context(), &s); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	podWriteJSON(w, http.StatusCreated, &s)
+}
+
+// handleGetSecret returns a Secret by name.
+func (p *MicroKubeProvider) handleGetSecret(w http.ResponseWriter, r *http.Request) {
+	key := r.PathValue("namespace") + "/" + r.PathValue("name")
+
+	s, ok := p.secrets[key]
+	if !ok {
+		http.Error(w, fmt.Sprintf("secret %s not found", key), http.StatusNotFound)
+		return
+	}
+	podWriteJSON(w, http.StatusOK, s)
+}
+
+// handleListSecrets returns all Secrets in a namespace.
+func (p *MicroKubeProvider) handleListSecrets(w http.ResponseWriter, r *http.Request) {
+	ns := r.PathValue("namespace")
+
+	items := make([]corev1.Secret, 0)
+	for _, key := range sortedKeys(p.secrets) {
+		if s := p.secrets[key]; s.Namespace == ns {
+			items = append(items, *s.DeepCopy())
+		}
+	}
+
+	podWriteJSON(w, http.StatusOK, corev1.SecretList{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "SecretList"},
+		Items:    items,
+	})
+}
+
+// handleUpdateSecret replaces a Secret (PUT).
+func (p *MicroKubeProvider) handleUpdateSecret(w http.ResponseWriter, r *http.Request) {
+	ns := r.PathValue("namespace")
+	name := r.PathValue("name")
+	key := ns + "/" + name
+
+	if _, ok := p.secrets[key]; !ok {
+		http.Error(w, fmt.Sprintf("secret %s not found", key), http.StatusNotFound)
+		return
+	}
+
+	var s corev1.Secret
+	if err := json.NewDecoder(r.Body).Decode(&s); err != nil {
+		http.Error(w, fmt.Sprintf("invalid secret JSON: %v", err), http.StatusBadRequest)
+		return
+	}
+	normalizeSecret(&s, ns, name)
+
+	if err := p.putSecret(r.Context(), &s); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	podWriteJSON(w, http.StatusOK, &s)
+}
+
+// handlePatchSecret applies a merge patch to a Secret.
+func (p *MicroKubeProvider) handlePatchSecret(w http.ResponseWriter, r *http.Request) {
+	ns := r.PathValue("namespace")
+	name := r.PathValue("name")
+	key := ns + "/" + name
+
+	existing, ok := p.secrets[key]
+	if !ok {
+		http.Error(w, fmt.Sprintf("secret %s not found", key), http.StatusNotFound)
+		return
+	}
+
+	merged := existing.DeepCopy()
+	if err := json.NewDecoder(r.Body).Decode(merged); err != nil {
+		http.Error(w, fmt.Sprintf("invalid patch JSON: %v", err), http.StatusBadRequest)
+		return
+	}
+	normalizeSecret(merged, ns, name)
+
+	if err := p.putSecret(r.Context(), merged); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	podWriteJSON(w, http.StatusOK, merged)
+}
+
+// handleDeleteSecret removes a Secret.
+func (p *MicroKubeProvider) handleDeleteSecret(w http.ResponseWriter, r *http.Request) {
+	ns := r.PathValue("namespace")
+	name := r.PathValue("name")
+	key := ns + "/" + name
+
+	if _, ok := p.secrets[key]; !ok {
+		http.Error(w, fmt.Sprintf("secret %s not found", key), http.StatusNotFound)
+		return
+	}
+
+	delete(p.secrets, key)
+
+	if p.deps.Store != nil && p.deps.Store.Secrets != nil {
+		storeKey := ns + "." + name
+		if err := p.deps.Store.Secrets.Delete(r.Context(), storeKey); err != nil {
+			p.deps.Logger.Warnw("failed to delete secret from store", "key", storeKey, "error", err)
+		}
+	}
+
+	podWriteJSON(w, http.StatusOK, metav1.Status{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Status"},
+		Status:   "Success",
+		Message:  fmt.Sprintf("secret %q deleted", name),
+	})
+}
+
+// LoadSecretsFromStore loads Secret objects from the NATS SECRETS bucket.
+func (p *MicroKubeProvider) LoadSecretsFromStore(ctx context.Context) {
+	if p.deps.Store == nil || p.deps.Store.Secrets == nil {
+		return
+	}
+
+	keys, err := p.deps.Store.Secrets.Keys(ctx, "")
+	if err != nil {
+		p.deps.Logger.Warnw("failed to list secrets from store", "error", err)
+		return
+	}
+
+	loaded := 0
+	for _, key := range keys {
+		var s corev1.Secret
+		if _, err := p.deps.Store.Secrets.GetJSON(ctx, key, &s); err != nil {
+			p.deps.Logger.Warnw("failed to read secret from store", "key", key, "error", err)
+			continue
+		}
+		p.secrets[s.Namespace+"/"+s.Name] = &s
+		loaded++
+	}
+
+	if loaded > 0 {
+		p.deps.Logger.Infow("loaded secrets from store", "count", loaded)
+	}
+}
@@ -0,0 +1,58 @@
+package provider
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestSecretLifecycle(t *testing.T) {
+	p, _ := newTestProvider(t)
+
+	mux := http.NewServeMux()
+	p.RegisterRoutes(mux)
+	h := p.WrapHandler(mux)
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rec
+	}
+	const base = "/api/v1/namespaces/default/secrets"
+
+	// stringData is folded into data and the type defaults to Opaque
+	rec := do(http.MethodPost, base, `{"metadata":{"name":"db"},"stringData":{"password":"hunter2"}}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: %d %s", rec.Code, rec.Body)
+	}
+	var s corev1.Secret
+	_ = json.Unmarshal(rec.Body.Bytes(), &s)
+	if string(s.Data["password"]) != "hunter2" || s.StringData != nil || s.Type != corev1.SecretTypeOpaque {
+		t.Fatalf("created secret = %+v", s)
+	}
+
+	rec = do(http.MethodPatch, base+"/db", `{"stringData":{"user":"app"}}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("patch: %d %s", rec.Code, rec.Body)
+	}
+	_ = json.Unmarshal(rec.Body.Bytes(), &s)
+	if string(s.Data["user"]) != "app" || string(s.Data["password"]) != "hunter2" {
+		t.Errorf("patched data = %v", s.Data)
+	}
+
+	var list corev1.SecretList
+	_ = json.Unmarshal(do(http.MethodGet, base, "").Body.Bytes(), &list)
+	if len(list.Items) != 1 {
+		t.Errorf("list returned %d secrets, want 1", len(list.Items))
+	}
+
+	if rec := do(http.MethodDelete, base+"/db", ""); rec.Code != http.StatusOK {
+		t.Fatalf("delete: %d %s", rec.Code, rec.Body)
+	}
+	if rec := do(http.MethodGet, base+"/db", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("get after delete: %d, want 404", rec.Code)
+	}
+}
@@ -0,0 +1,677 @@
+package provider
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ─── Schema Validation ──────────────────────────────────────────────────────
+
+// decodeCRDBody reads a create, update or patch body for kind, decodes it
+// into obj and validates it against the kind's OpenAPI schema. Patches skip
+// required-field checks since they only carry changed fields. On failure the
+// error response has been written and false is returned.
+func decodeCRDBody(w http.ResponseWriter, r *http.Request, kind string, patch bool, obj interface{}) bool {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("reading body: %v", err), http.StatusBadRequest)
+		return false
+	}
+	if err := json.Unmarshal(body, obj); err != nil {
+		http.Error(w, fmt.Sprintf("invalid %s JSON: %v", kind, err), http.StatusBadRequest)
+		return false
+	}
+	if errs := validateCRDSchema(kind, body, patch); len(errs) > 0 {
+		name := ""
+		if o, ok := obj.(metav1.Object); ok {
+			name = o.GetName()
+		}
+		writeInvalid(w, kind, name, errs)
+		return false
+	}
+	return true
+}
+
+// writeInvalid writes a 422 listing every validation error for an object.
+func writeInvalid(w http.ResponseWriter, kind, name string, errs []string) {
+	http.Error(w, fmt.Sprintf("%s %q is invalid: %s", kind, name, strings.Join(errs, "; ")),
+		http.StatusUnprocessableEntity)
+}
+
+// validateCRDSchema checks a JSON document against the published schema for
+// kind. Status is server-managed and not validated.
+func validateCRDSchema(kind string, body []byte, patch bool) []string {
+	schema := crdSchemas()[kind]
+	if schema == nil {
+		return nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var doc map[string]interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return []string{err.Error()}
+	}
+
+	var errs []string
+	if k, ok := doc["kind"].(string); ok && k != "" && k != kind {
+		errs = append(errs, fmt.Sprintf("kind: expected %q, got %q", kind, k))
+	}
+	delete(doc, "status")
+	validateSchemaValue(schema, doc, "", patch, &errs)
+	return errs
+}
+
+func joinFieldPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// validateSchemaValue appends an error for every way v violates s.
+func validateSchemaValue(s *openAPISchema, v interface{}, path string, patch bool, errs *[]string) {
+	if v == nil || s == nil {
+		return
+	}
+	fail := func(format string, args ...interface{}) {
+		*errs = append(*errs, path+": "+fmt.Sprintf(format, args...))
+	}
+
+	if s.IntOrString {
+		if _, ok := v.(string); ok {
+			return
+		}
+		if n, ok := v.(json.Number); ok {
+			if _, err := n.Int64(); err == nil {
+				return
+			}
+		}
+		fail("must be an integer or string")
+		return
+	}
+
+	switch s.Type {
+	case "string":
+		str, ok := v.(string)
+		if !ok {
+			fail("must be a string")
+			return
+		}
+		// Empty strings mean "unset" throughout the mkube types
+		if str == "" {
+			return
+		}
+		if len(s.Enum) > 0 && !containsString(s.Enum, str) {
+			fail("unsupported value %q: must be one of %s", str, strings.Join(s.Enum, ", "))
+		}
+		if s.Pattern != "" {
+			if ok, _ := regexp.MatchString(s.Pattern, str); !ok {
+				fail("%q does not match %s", str, s.Pattern)
+			}
+		}
+		if err := checkStringFormat(s.Format, str); err != "" {
+			fail("%q is not a valid %s", str, err)
+		}
+
+	case "integer":
+		n, ok := v.(json.Number)
+		if !ok {
+			fail("must be an integer")
+			return
+		}
+		i, err := n.Int64()
+		if err != nil {
+			fail("must be an integer")
+			return
+		}
+		if s.Minimum != nil && i < *s.Minimum {
+			fail("must be greater than or equal to %d", *s.Minimum)
+		}
+		if s.Maximum != nil && i > *s.Maximum {
+			fail("must be less than or equal to %d", *s.Maximum)
+		}
+
+	case "number":
+		if _, ok := v.(json.Number); !ok {
+			fail("must be a number")
+		}
+
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			fail("must be a boolean")
+		}
+
+	case "array":
+		items, ok := v.([]interface{})
+		if !ok {
+			fail("must be an array")
+			return
+		}
+		for i, item := range items {
+			validateSchemaValue(s.Items, item, fmt.Sprintf("%s[%d]", path, i), patch, errs)
+		}
+
+	case "object":
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			fail("must be an object")
+			return
+		}
+		if s.PreserveUnknown && s.Properties == nil {
+			return
+		}
+		if !patch {
+			for _, req := range s.Required {
+				if val, ok := obj[req]; !ok || val == nil || val == "" {
+					*errs = append(*errs, joinFieldPath(path, req)+": Required value")
+				}
+			}
+		}
+		keys := make([]string, 0, len(obj))
+		for k := range obj {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			// Strategic merge patch directives ($patch, $setElementOrder/...)
+			if patch && strings.HasPrefix(k, "$") {
+				continue
+			}
+			if prop, ok := s.Properties[k]; ok {
+				validateSchemaValue(prop, obj[k], joinFieldPath(path, k), patch, errs)
+			} else if s.AdditionalProperties != nil {
+				validateSchemaValue(s.AdditionalProperties, obj[k], joinFieldPath(path, k), patch, errs)
+			} else {
+				*errs = append(*errs, joinFieldPath(path, k)+": unknown field")
+			}
+		}
+	}
+}
+
+// checkStringFormat returns the format name if s doesn't satisfy it.
+func checkStringFormat(format, s string) string {
+	switch format {
+	case "ip":
+		if net.ParseIP(s) == nil {
+			return "IP address"
+		}
+	case "cidr":
+		if _, _, err := net.ParseCIDR(s); err != nil {
+			return "CIDR"
+		}
+	case "mac":
+		if _, err := net.ParseMAC(s); err != nil {
+			return "MAC address"
+		}
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339, s); err != nil {
+			return "RFC3339 timestamp"
+		}
+	}
+	return ""
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// ─── Semantic Validation ────────────────────────────────────────────────────
+
+// admitCRD runs cross-object checks (CIDR overlap, ranges inside the network,
+// referenced objects exist) on the object about to be stored. old is the
+// current object on update/patch and nil on create; references that haven't
+// changed since old are not re-checked, so deleting a referenced object
+// doesn't block unrelated edits. On failure a 422 is written and false is
+// returned.
+func (p *MicroKubeProvider) admitCRD(w http.ResponseWriter, obj, old interface{}) bool {
+	var kind, name string
+	var errs []string
+
+	switch o := obj.(type) {
+	case *Network:
+		kind, name = "Network", o.Name
+		errs = p.validateNetworkSemantics(o)
+	case *BareMetalHost:
+		prev, _ := old.(*BareMetalHost)
+		kind, name = "BareMetalHost", o.Name
+		errs = p.validateBMHSemantics(o, prev)
+	case *Job:
+		prev, _ := old.(*Job)
+		kind, name = "Job", o.Name
+		errs = p.validateJobSemantics(o, prev)
+	case *JobRunner:
+		prev, _ := old.(*JobRunner)
+		kind, name = "JobRunner", o.Name
+		errs = p.validateJobRunnerSemantics(o, prev)
+	case *HostReservation:
+		prev, _ := old.(*HostReservation)
+		kind, name = "HostReservation", o.Name
+		errs = p.validateHostReservationSemantics(o, prev)
+	case *IPAddressClaim:
+		prev, _ := old.(*IPAddressClaim)
+		kind, name = "IPAddressClaim", o.Name
+		errs = p.validateIPAddressClaimSemantics(o, prev)
+	case *PortForward:
+		prev, _ := old.(*PortForward)
+		kind, name = "PortForward", o.Name
+		errs = p.validatePortForwardSemantics(o, prev)
+	case *ISCSICdrom:
+		prev, _ := old.(*ISCSICdrom)
+		kind, name = "ISCSICdrom", o.Name
+		errs = p.validateISCSICdromSemantics(o, prev)
+	case *Registry:
+		prev, _ := old.(*Registry)
+		kind, name = "Registry", o.Name
+		errs = p.validateRegistrySemantics(o, prev)
+	}
+
+	if len(errs) > 0 {
+		writeInvalid(w, kind, name, errs)
+		return false
+	}
+	return true
+}
+
+// ipInNetwork checks that ip is a valid address inside cidr.
+func ipInNetwork(field, ip string, cidr *net.IPNet) string {
+	if ip == "" {
+		return ""
+	}
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return fmt.Sprintf("%s: %q is not a valid IP address", field, ip)
+	}
+	if !cidr.Contains(parsed) {
+		return fmt.Sprintf("%s: %s is outside network CIDR %s", field, ip, cidr)
+	}
+	return ""
+}
+
+// rangeInNetwork checks that start..end is an ordered range inside cidr.
+func rangeInNetwork(startField, endField, start, end string, cidr *net.IPNet) []string {
+	var errs []string
+	for _, e := range []string{
+		ipInNetwork(startField, start, cidr),
+		ipInNetwork(endField, end, cidr),
+	} {
+		if e != "" {
+			errs = append(errs, e)
+		}
+	}
+	if len(errs) == 0 && start != "" && end != "" &&
+		bytes.Compare(net.ParseIP(start).To16(), net.ParseIP(end).To16()) > 0 {
+		errs = append(errs, fmt.Sprintf("%s: %s is after %s %s", startField, start, endField, end))
+	}
+	return errs
+}
+
+// networkCIDR returns the parsed CIDR of a known network, or nil.
+func (p *MicroKubeProvider) networkCIDR(name string) *net.IPNet {
+	n, ok := p.networks[name]
+	if !ok {
+		return nil
+	}
+	_, cidr, err := net.ParseCIDR(n.Spec.CIDR)
+	if err != nil {
+		return nil
+	}
+	return cidr
+}
+
+// routerOSNameRe restricts the bridge and interface names a Network hands
+// to the router, where they also end up in scheduled revert scripts.
+var routerOSNameRe = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+// validateRouterOSNames checks the network name (which names its default
+// bridge and DHCP relay), spec.bridge and spec.uplinks against
+// routerOSNameRe.
+func validateRouterOSNames(n *Network) []string {
+	var errs []string
+	check := func(field, name string) {
+		if !routerOSNameRe.MatchString(name) {
+			errs = append(errs, fmt.Sprintf("%s: %q may only contain letters, digits, '.', '_' and '-'", field, name))
+		}
+	}
+	check("metadata.name", n.Name)
+	if n.Spec.Bridge != "" {
+		check("spec.bridge", n.Spec.Bridge)
+	}
+	for i, uplink := range n.Spec.Uplinks {
+		check(fmt.Sprintf("spec.uplinks[%d]", i), uplink)
+	}
+	return errs
+}
+
+func (p *MicroKubeProvider) validateNetworkSemantics(n *Network) []string {
+	_, cidr, err := net.ParseCIDR(n.Spec.CIDR)
+	if err != nil {
+		return []string{fmt.Sprintf("spec.cidr: %q is not a valid CIDR", n.Spec.CIDR)}
+	}
+
+	var errs []string
+	add := func(e string) {
+		if e != "" {
+			errs = append(errs, e)
+		}
+	}
+
+	add(ipInNetwork("spec.gateway", n.Spec.Gateway, cidr))
+	errs = append(errs, rangeInNetwork("spec.ipam.start", "spec.ipam.end",
+		n.Spec.IPAM.Start, n.Spec.IPAM.End, cidr)...)
+	errs = append(errs, rangeInNetwork("spec.dhcp.rangeStart", "spec.dhcp.rangeEnd",
+		n.Spec.DHCP.RangeStart, n.Spec.DHCP.RangeEnd, cidr)...)
+	for i, res := range n.Spec.DHCP.Reservations {
+		add(ipInNetwork(fmt.Sprintf("spec.dhcp.reservations[%d].ip", i), res.IP, cidr))
+	}
+
+	errs = append(errs, validateRouterOSNames(n)...)
+	cidr6 := validateDualStack(n, cidr, add)
+	errs = append(errs, validateDNSViews(n)...)
+	errs = append(errs, p.validateNetworkViewClients(n)...)
+	errs = append(errs, validateNetworkDHCPOptions(n)...)
+
+	if sn := n.Spec.DHCP.ServerNetwork; sn != "" && sn != n.Name {
+		if _, ok := p.networks[sn]; !ok {
+			add(fmt.Sprintf("spec.dhcp.serverNetwork: network %q not found", sn))
+		}
+	}
+
+	if len(n.Spec.Uplinks) > 0 && n.Spec.VLAN == 0 {
+		add("spec.uplinks: only a network with spec.vlan carries tagged uplinks")
+	}
+
+	names := make([]string, 0, len(p.networks))
+	for name := range p.networks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if name == n.Name {
+			continue
+		}
+		add(validateSharedBridge(n, p.networks[name]))
+		_, other, err := net.ParseCIDR(p.networks[name].Spec.CIDR)
+		if err != nil {
+			continue
+		}
+		if other.Contains(cidr.IP) || cidr.Contains(other.IP) {
+			add(fmt.Sprintf("spec.cidr: %s overlaps network %q (%s)", cidr, name, other))
+		}
+		if cidr6 == nil {
+			continue
+		}
+		if _, other6, err := net.ParseCIDR(p.networks[name].Spec.secondaryCIDR6()); err == nil &&
+			(other6.Contains(cidr6.IP) || cidr6.Contains(other6.IP)) {
+			add(fmt.Sprintf("spec.cidrs: %s overlaps network %q (%s)", cidr6, name, other6))
+		}
+	}
+	return errs
+}
+
+// validateSharedBridge checks that two networks on the same bridge keep
+// their traffic apart: once either uses a VLAN, both must, with different
+// IDs. Networks without a VLAN on a shared bridge are left alone.
+func validateSharedBridge(n, other *Network) string {
+	if n.Spec.Bridge == "" || n.Spec.Bridge != other.Spec.Bridge {
+		return ""
+	}
+	switch {
+	case n.Spec.VLAN == 0 && other.Spec.VLAN == 0:
+		return ""
+	case n.Spec.VLAN == 0:
+		return fmt.Sprintf("spec.vlan: bridge %s is shared with VLAN network %q, so this network needs a VLAN too", n.Spec.Bridge, other.Name)
+	case other.Spec.VLAN == 0:
+		return fmt.Sprintf("spec.bridge: %s is used by network %q without a VLAN", n.Spec.Bridge, other.Name)
+	case n.Spec.VLAN == other.Spec.VLAN:
+		return fmt.Sprintf("spec.vlan: VLAN %d is already used on bridge %s by network %q", n.Spec.VLAN, n.Spec.Bridge, other.Name)
+	}
+	return ""
+}
+
+// validateDualStack checks spec.cidrs and the IPv6 gateway and IPAM range
+// of a dual-stack network, returning the parsed IPv6 subnet if there is one.
+// cidrs must include cidr, hold at most one CIDR per address family, and
+// only a network whose primary cidr is IPv4 can carry a second family: an
+// IPv4 entry next to an IPv6 cidr is rejected rather than ignored.
+func validateDualStack(n *Network, cidr *net.IPNet, add func(string)) *net.IPNet {
+	if len(n.Spec.CIDRs) == 0 {
+		if n.Spec.Gateway6 != "" || n.Spec.IPAM.Start6 != "" || n.Spec.IPAM.End6 != "" {
+			add("spec.gateway6/spec.ipam.start6/spec.ipam.end6 require an IPv6 entry in spec.cidrs")
+		}
+		return nil
+	}
+
+	var cidr6 *net.IPNet
+	families := make(map[bool]string)
+	found := false
+	for i, c := range n.Spec.CIDRs {
+		ip, parsed, err := net.ParseCIDR(c)
+		if err != nil {
+			add(fmt.Sprintf("spec.cidrs[%d]: %q is not a valid CIDR", i, c))
+			continue
+		}
+		v6 := ip.To4() == nil
+		if prev, dup := families[v6]; dup {
+			add(fmt.Sprintf("spec.cidrs[%d]: %s is the same address family as %s", i, c, prev))
+			continue
+		}
+		families[v6] = c
+		if c == n.Spec.CIDR {
+			found = true
+		} else if v6 {
+			cidr6 = parsed
+		}
+	}
+	if !found {
+		add(fmt.Sprintf("spec.cidrs: must include spec.cidr %s", n.Spec.CIDR))
+	}
+	if _, v4 := families[false]; v4 && cidr != nil && cidr.IP.To4() == nil {
+		add("spec.cidr: must be the IPv4 CIDR of a dual-stack network")
+		return nil
+	}
+	if cidr6 == nil {
+		if n.Spec.Gateway6 != "" || n.Spec.IPAM.Start6 != "" || n.Spec.IPAM.End6 != "" {
+			add("spec.gateway6/spec.ipam.start6/spec.ipam.end6 require an IPv6 entry in spec.cidrs")
+		}
+		return nil
+	}
+
+	add(ipInNetwork("spec.gateway6", n.Spec.Gateway6, cidr6))
+	for _, e := range rangeInNetwork("spec.ipam.start6", "spec.ipam.end6",
+		n.Spec.IPAM.Start6, n.Spec.IPAM.End6, cidr6) {
+		add(e)
+	}
+	return cidr6
+}
+
+func (p *MicroKubeProvider) validateBMHSemantics(b, old *BareMetalHost) []string {
+	var errs []string
+	if ref := b.Spec.BootConfigRef; ref != "" && (old == nil || old.Spec.BootConfigRef != ref) {
+		if _, ok := p.bootConfigs[ref]; !ok {
+			errs = append(errs, fmt.Sprintf("spec.bootConfigRef: BootConfig %q not found", ref))
+		}
+	}
+	if nw := b.Spec.Network; nw != "" {
+		if cidr := p.networkCIDR(nw); cidr != nil {
+			if e := ipInNetwork("spec.ip", b.Spec.IP, cidr); e != "" {
+				errs = append(errs, e)
+			}
+		} else if old == nil || old.Spec.Network != nw {
+			errs = append(errs, fmt.Sprintf("spec.network: network %q not found", nw))
+		}
+	}
+	if nw := b.Spec.BMC.Network; nw != "" {
+		if cidr := p.networkCIDR(nw); cidr != nil {
+			if e := ipInNetwork("spec.bmc.address", b.Spec.BMC.Address, cidr); e != "" {
+				errs = append(errs, e)
+			}
+		} else if old == nil || old.Spec.BMC.Network != nw {
+			errs = append(errs, fmt.Sprintf("spec.bmc.network: network %q not found", nw))
+		}
+	}
+	return errs
+}
+
+func (p *MicroKubeProvider) validateJobSemantics(j, old *Job) []string {
+	if j.Spec.Pool == "" || (old != nil && old.Spec.Pool == j.Spec.Pool) {
+		return nil
+	}
+	for _, jr := range p.jobRunners {
+		if jr.Spec.Pool == j.Spec.Pool {
+			return nil
+		}
+	}
+	return []string{fmt.Sprintf("spec.pool: no JobRunner found for pool %q", j.Spec.Pool)}
+}
+
+func (p *MicroKubeProvider) validateJobRunnerSemantics(jr, old *JobRunner) []string {
+	var errs []string
+	if ref := jr.Spec.BootConfigRef; ref != "" && (old == nil || old.Spec.BootConfigRef != ref) {
+		if _, ok := p.bootConfigs[ref]; !ok {
+			errs = append(errs, fmt.Sprintf("spec.bootConfigRef: BootConfig %q not found", ref))
+		}
+	}
+	if s := jr.Spec.Schedule; s != nil && s.Timezone != "" {
+		if _, err := time.LoadLocation(s.Timezone); err != nil {
+			errs = append(errs, fmt.Sprintf("spec.schedule.timezone: unknown timezone %q", s.Timezone))
+		}
+	}
+	return errs
+}
+
+func (p *MicroKubeProvider) validateHostReservationSemantics(hr, old *HostReservation) []string {
+	if hr.Spec.BMHRef == "" || (old != nil && old.Spec.BMHRef == hr.Spec.BMHRef) {
+		return nil
+	}
+	for _, bmh := range p.bareMetalHosts {
+		if bmh.Name == hr.Spec.BMHRef {
+			return nil
+		}
+	}
+	return []string{fmt.Sprintf("spec.bmhRef: BareMetalHost %q not found", hr.Spec.BMHRef)}
+}
+
+func (p *MicroKubeProvider) validateIPAddressClaimSemantics(c, old *IPAddressClaim) []string {
+	var errs []string
+	if old != nil && old.Status.Phase == "Bound" {
+		// The address is handed out; moving it would break whoever uses it
+		if c.Spec.Network != old.Spec.Network {
+			errs = append(errs, "spec.network: cannot change once the claim is bound")
+		}
+		if c.Spec.IP != "" && !net.ParseIP(c.Spec.IP).Equal(net.ParseIP(old.Status.IP)) {
+			errs = append(errs, fmt.Sprintf("spec.ip: claim is bound to %s; delete and recreate it to change the address", old.Status.IP))
+		}
+		if len(errs) > 0 {
+			return errs
+		}
+	}
+
+	n, ok := p.networks[c.Spec.Network]
+	if !ok {
+		if old == nil || old.Spec.Network != c.Spec.Network {
+			return []string{fmt.Sprintf("spec.network: network %q not found", c.Spec.Network)}
+		}
+		return nil
+	}
+	if c.Spec.IP == "" {
+		return nil
+	}
+	ip := net.ParseIP(c.Spec.IP)
+	if ip == nil {
+		return []string{fmt.Sprintf("spec.ip: %q is not a valid IP address", c.Spec.IP)}
+	}
+	cidr := n.Spec.CIDR
+	if ip.To4() == nil {
+		if cidr = n.Spec.secondaryCIDR6(); cidr == "" {
+			return []string{fmt.Sprintf("spec.ip: network %q has no IPv6 CIDR", c.Spec.Network)}
+		}
+	}
+	if _, subnet, err := net.ParseCIDR(cidr); err == nil {
+		if e := ipInNetwork("spec.ip", c.Spec.IP, subnet); e != "" {
+			errs = append(errs, e)
+		}
+	}
+	return errs
+}
+
+func (p *MicroKubeProvider) validatePortForwardSemantics(f, old *PortForward) []string {
+	var errs []string
+	t := f.Spec.Target
+	if (t.Pod == "") == (t.Service == "") {
+		errs = append(errs, "spec.target: exactly one of pod and service must be set")
+	}
+	if ip := net.ParseIP(f.Spec.ExternalIP); f.Spec.ExternalIP != "" && (ip == nil || ip.To4() == nil) {
+		errs = append(errs, fmt.Sprintf("spec.externalIP: %q is not an IPv4 address", f.Spec.ExternalIP))
+	}
+
+	// Two forwards of the same external port would shadow each other
+	for _, other := range p.portForwards {
+		if other.Namespace == f.Namespace && other.Name == f.Name {
+			continue
+		}
+		o := other.Spec
+		if o.Protocol != f.Spec.Protocol || o.ExternalPort != f.Spec.ExternalPort {
+			continue
+		}
+		if (o.ExternalIP == "" || f.Spec.ExternalIP == "" || o.ExternalIP == f.Spec.ExternalIP) &&
+			(o.Interface == "" || f.Spec.Interface == "" || o.Interface == f.Spec.Interface) {
+			errs = append(errs, fmt.Sprintf("spec.externalPort: %s/%d is already forwarded by PortForward %s/%s",
+				f.Spec.Protocol, f.Spec.ExternalPort, other.Namespace, other.Name))
+		}
+	}
+	return errs
+}
+
+func (p *MicroKubeProvider) validateISCSICdromSemantics(c, old *ISCSICdrom) []string {
+	var errs []string
+	for i, bc := range c.Spec.BootConfigs {
+		if old != nil && containsString(old.Spec.BootConfigs, bc) {
+			continue
+		}
+		if _, ok := p.bootConfigs[bc]; !ok {
+			errs = append(errs, fmt.Sprintf("spec.bootConfigs[%d]: BootConfig %q not found", i, bc))
+		}
+	}
+	if base := c.Spec.DerivedFrom; base != "" && (old == nil || old.Spec.DerivedFrom != base) {
+		if base == c.Name {
+			errs = append(errs, "spec.derivedFrom: cannot reference itself")
+		} else if _, ok := p.iscsiCdroms[base]; !ok {
+			errs = append(errs, fmt.Sprintf("spec.derivedFrom: iSCSI CDROM %q not found", base))
+		}
+	}
+	return errs
+}
+
+func (p *MicroKubeProvider) validateRegistrySemantics(reg, old *Registry) []string {
+	nw := reg.Spec.Network
+	if nw == "" {
+		return nil
+	}
+	cidr := p.networkCIDR(nw)
+	if cidr == nil {
+		if old == nil || old.Spec.Network != nw {
+			return []string{fmt.Sprintf("spec.network: network %q not found", nw)}
+		}
+		return nil
+	}
+	if e := ipInNetwork("spec.staticIP", reg.Spec.StaticIP, cidr); e != "" {
+		return []string{e}
+	}
+	return nil
+}
@@ -0,0 +1,252 @@
+package provider
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestOpenAPIDocument(t *testing.T) {
+	p, _ := newTestProvider(t)
+	mux := http.NewServeMux()
+	p.RegisterRoutes(mux)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/openapi/v3", nil))
+	var index struct {
+		Paths map[string]struct {
+			ServerRelativeURL string `json:"serverRelativeURL"`
+		} `json:"paths"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &index); err != nil {
+		t.Fatalf("decoding index: %v", err)
+	}
+	url := index.Paths["api/v1"].ServerRelativeURL
+	if !strings.HasPrefix(url, "/openapi/v3/api/v1?hash=") {
+		t.Fatalf("unexpected serverRelativeURL %q", url)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, url, nil))
+	var doc struct {
+		Components struct {
+			Schemas map[string]*openAPISchema `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("decoding spec: %v", err)
+	}
+	for _, kind := range []string{"BareMetalHost", "Network", "Job", "JobRunner",
+		"HostReservation", "IPAddressClaim", "PortForward", "BootConfig", "ISCSICdrom", "Registry"} {
+		s := doc.Components.Schemas["mkube.v1."+kind]
+		if s == nil {
+			t.Errorf("missing schema for %s", kind)
+			continue
+		}
+		if len(s.GVK) != 1 || s.GVK[0]["kind"] != kind {
+			t.Errorf("%s: bad group-version-kind %v", kind, s.GVK)
+		}
+	}
+
+	for _, kind := range []string{"Pod", "ConfigMap", "Secret", "Namespace", "Node", "Event", "Service", "PersistentVolumeClaim"} {
+		s := doc.Components.Schemas["io.k8s.api.core.v1."+kind]
+		if s == nil {
+			t.Errorf("missing schema for core kind %s", kind)
+			continue
+		}
+		if len(s.GVK) != 1 || s.GVK[0]["kind"] != kind {
+			t.Errorf("%s: bad group-version-kind %v", kind, s.GVK)
+		}
+	}
+	// Every kind served under /api/v1 has a schema
+	for _, res := range apiResources {
+		if doc.Components.Schemas["mkube.v1."+res.Kind] == nil && doc.Components.Schemas["io.k8s.api.core.v1."+res.Kind] == nil {
+			t.Errorf("no schema for %s (%s)", res.Kind, res.Name)
+		}
+	}
+	if containers := doc.Components.Schemas["io.k8s.api.core.v1.Pod"].Properties["spec"].Properties["containers"]; containers == nil || containers.Items == nil {
+		t.Error("expected Pod spec.containers to be an array of containers")
+	}
+
+	spec := doc.Components.Schemas["mkube.v1.Network"].Properties["spec"]
+	if spec.Properties["cidr"].Format != "cidr" {
+		t.Errorf("expected spec.cidr format cidr, got %q", spec.Properties["cidr"].Format)
+	}
+	if !containsString(spec.Required, "cidr") {
+		t.Errorf("expected spec.cidr to be required, got %v", spec.Required)
+	}
+}
+
+func TestValidateCRDSchema(t *testing.T) {
+	tests := []struct {
+		name  string
+		kind  string
+		body  string
+		patch bool
+		want  string // substring of the first error; "" means valid
+	}{
+		{"valid network", "Network",
+			`{"metadata":{"name":"g10"},"spec":{"type":"data","cidr":"192.168.10.0/24","vlan":10}}`, false, ""},
+		{"missing cidr", "Network",
+			`{"metadata":{"name":"g10"},"spec":{"type":"data"}}`, false, "spec.cidr: Required value"},
+		{"bad cidr", "Network",
+			`{"metadata":{"name":"g10"},"spec":{"cidr":"192.168.10.0/33"}}`, false, "not a valid CIDR"},
+		{"bad enum", "Network",
+			`{"metadata":{"name":"g10"},"spec":{"cidr":"10.0.0.0/8","type":"wan"}}`, false, "unsupported value"},
+		{"vlan out of range", "Network",
+			`{"metadata":{"name":"g10"},"spec":{"cidr":"10.0.0.0/8","vlan":5000}}`, false, "less than or equal to 4094"},
+		{"misspelled field", "BareMetalHost",
+			`{"metadata":{"name":"s1"},"spec":{"bootConfgRef":"coreos"}}`, false, "spec.bootConfgRef: unknown field"},
+		{"wrong type", "Job",
+			`{"metadata":{"name":"j1"},"spec":{"pool":"p","script":"true","priority":"high"}}`, false, "spec.priority: must be an integer"},
+		{"status ignored", "Job",
+			`{"metadata":{"name":"j1"},"spec":{"pool":"p","script":"true"},"status":{"bogus":1}}`, false, ""},
+		{"patch skips required", "Registry",
+			`{"spec":{"pullThrough":true}}`, true, ""},
+		{"patch directives", "Network",
+			`{"spec":{"$setElementOrder/staticRecords":[{"name":"a"}],"staticRecords":[{"name":"a","ip":"10.0.0.5"}]}}`, true, ""},
+		{"kind mismatch", "Network",
+			`{"kind":"Registry","metadata":{"name":"g10"},"spec":{"cidr":"10.0.0.0/8"}}`, false, "kind: expected"},
+		{"schedule pattern", "JobRunner",
+			`{"metadata":{"name":"r"},"spec":{"pool":"p","bootConfigRef":"b","schedule":{"days":["Mon"],"start":"9am","end":"17:00"}}}`, false, "spec.schedule.start"},
+	}
+	for _, tt := range tests {
+		errs := validateCRDSchema(tt.kind, []byte(tt.body), tt.patch)
+		if tt.want == "" {
+			if len(errs) != 0 {
+				t.Errorf("%s: expected valid, got %v", tt.name, errs)
+			}
+			continue
+		}
+		if len(errs) == 0 || !strings.Contains(errs[0], tt.want) {
+			t.Errorf("%s: expected error containing %q, got %v", tt.name, tt.want, errs)
+		}
+	}
+}
+
+func TestValidateNetworkSemantics(t *testing.T) {
+	p, _ := newTestProvider(t)
+	p.networks["gt"] = &Network{
+		ObjectMeta: metav1.ObjectMeta{Name: "gt"},
+		Spec:       NetworkSpec{CIDR: "192.168.200.0/24"},
+	}
+	p.networks["lab"] = &Network{
+		ObjectMeta: metav1.ObjectMeta{Name: "lab"},
+		Spec:       NetworkSpec{CIDR: "10.60.0.0/24", Bridge: "trunk", VLAN: 60},
+	}
+	p.networks["g50"] = &Network{
+		ObjectMeta: metav1.ObjectMeta{Name: "g50"},
+		Spec:       NetworkSpec{CIDR: "10.50.0.0/24", CIDRs: []string{"10.50.0.0/24", "fd00:50::/64"}},
+	}
+
+	tests := []struct {
+		name string
+		spec NetworkSpec
+		want string
+	}{
+		{"valid", NetworkSpec{CIDR: "192.168.10.0/24", Gateway: "192.168.10.1",
+			IPAM: NetworkIPAMSpec{Start: "192.168.10.100", End: "192.168.10.200"}}, ""},
+		{"overlap", NetworkSpec{CIDR: "192.168.0.0/16"}, `overlaps network "gt"`},
+		{"gateway outside", NetworkSpec{CIDR: "192.168.10.0/24", Gateway: "192.168.11.1"}, "spec.gateway"},
+		{"ipam outside", NetworkSpec{CIDR: "192.168.10.0/24",
+			IPAM: NetworkIPAMSpec{Start: "192.168.10.100", End: "192.168.11.200"}}, "spec.ipam.end"},
+		{"ipam reversed", NetworkSpec{CIDR: "192.168.10.0/24",
+			IPAM: NetworkIPAMSpec{Start: "192.168.10.200", End: "192.168.10.100"}}, "is after"},
+		{"missing relay network", NetworkSpec{CIDR: "192.168.10.0/24",
+			DHCP: NetworkDHCPSpec{ServerNetwork: "nope"}}, `network "nope" not found`},
+		{"valid dual-stack", NetworkSpec{CIDR: "192.168.10.0/24",
+			CIDRs: []string{"192.168.10.0/24", "fd00:10::/64"}, Gateway6: "fd00:10::1",
+			IPAM: NetworkIPAMSpec{Start6: "fd00:10::100", End6: "fd00:10::200"}}, ""},
+		{"cidrs without cidr", NetworkSpec{CIDR: "192.168.10.0/24",
+			CIDRs: []string{"fd00:10::/64"}}, "must include spec.cidr"},
+		{"ipv6 cidr with ipv4 secondary", NetworkSpec{CIDR: "fd00:10::/64",
+			CIDRs: []string{"fd00:10::/64", "192.168.10.0/24"}}, "must be the IPv4 CIDR"},
+		{"two ipv6 cidrs", NetworkSpec{CIDR: "192.168.10.0/24",
+			CIDRs: []string{"192.168.10.0/24", "fd00:10::/64", "fd00:11::/64"}}, "same address family"},
+		{"gateway6 outside", NetworkSpec{CIDR: "192.168.10.0/24",
+			CIDRs: []string{"192.168.10.0/24", "fd00:10::/64"}, Gateway6: "fd00:11::1"}, "spec.gateway6"},
+		{"gateway6 single-stack", NetworkSpec{CIDR: "192.168.10.0/24", Gateway6: "fd00:10::1"},
+			"require an IPv6 entry"},
+		{"ipv6 overlap", NetworkSpec{CIDR: "192.168.10.0/24",
+			CIDRs: []string{"192.168.10.0/24", "fd00:50::/48"}}, `overlaps network "g50"`},
+		{"vlan on shared bridge", NetworkSpec{CIDR: "192.168.10.0/24", Bridge: "trunk", VLAN: 10,
+			Uplinks: []string{"ether2"}}, ""},
+		{"untagged on vlan bridge", NetworkSpec{CIDR: "192.168.10.0/24", Bridge: "trunk"},
+			"needs a VLAN too"},
+		{"duplicate vlan on bridge", NetworkSpec{CIDR: "192.168.10.0/24", Bridge: "trunk", VLAN: 60},
+			`VLAN 60 is already used on bridge trunk by network "lab"`},
+		{"uplinks without vlan", NetworkSpec{CIDR: "192.168.10.0/24", Uplinks: []string{"ether2"}},
+			"spec.uplinks"},
+		{"script in bridge name", NetworkSpec{CIDR: "192.168.10.0/24", Bridge: `br"$x`},
+			"spec.bridge"},
+		{"space in uplink", NetworkSpec{CIDR: "192.168.10.0/24", Bridge: "br-10", VLAN: 10,
+			Uplinks: []string{"ether2", "sfp plus1"}}, "spec.uplinks[1]"},
+		{"dhcp options", NetworkSpec{CIDR: "192.168.10.0/24", DHCP: NetworkDHCPSpec{
+			Options:       []DHCPOptionSpec{{Code: 42, Value: "192.168.10.1"}, {Code: 121, Value: "10.0.0.0/8 via 192.168.10.254"}},
+			VendorClasses: []DHCPVendorClassSpec{{Match: "PXEClient", Options: []DHCPOptionSpec{{Code: 67, Value: "ipxe.efi"}}}},
+			Reservations: []NetworkDHCPReservation{{MAC: "aa:bb:cc:00:00:01", IP: "192.168.10.5",
+				Options: []DHCPOptionSpec{{Code: 43, Value: "01:02"}}}},
+		}}, ""},
+		{"managed dhcp option", NetworkSpec{CIDR: "192.168.10.0/24", DHCP: NetworkDHCPSpec{
+			Options: []DHCPOptionSpec{{Code: 6, Value: "1.1.1.1"}}}}, "spec.dhcp.options[0]: option 6"},
+		{"duplicate dhcp option", NetworkSpec{CIDR: "192.168.10.0/24", DHCP: NetworkDHCPSpec{
+			Options: []DHCPOptionSpec{{Code: 42, Value: "192.168.10.1"}, {Code: 42, Value: "192.168.10.2"}}}},
+			"already set by spec.dhcp.options[0]"},
+		{"vendor class without match", NetworkSpec{CIDR: "192.168.10.0/24", DHCP: NetworkDHCPSpec{
+			VendorClasses: []DHCPVendorClassSpec{{Options: []DHCPOptionSpec{{Code: 66, Value: "tftp"}}}}}},
+			"spec.dhcp.vendorClasses[0].match"},
+		{"bad reservation option", NetworkSpec{CIDR: "192.168.10.0/24", DHCP: NetworkDHCPSpec{
+			Reservations: []NetworkDHCPReservation{{MAC: "aa:bb:cc:00:00:01", IP: "192.168.10.5",
+				Options: []DHCPOptionSpec{{Code: 42, Value: "ntp.gt.lo"}}}}}},
+			"spec.dhcp.reservations[0].options[0]"},
+	}
+	for _, tt := range tests {
+		n := &Network{ObjectMeta: metav1.ObjectMeta{Name: "g10"}, Spec: tt.spec}
+		errs := p.validateNetworkSemantics(n)
+		if tt.want == "" {
+			if len(errs) != 0 {
+				t.Errorf("%s: expected valid, got %v", tt.name, errs)
+			}
+			continue
+		}
+		if len(errs) == 0 || !strings.Contains(strings.Join(errs, "; "), tt.want) {
+			t.Errorf("%s: expected error containing %q, got %v", tt.name, tt.want, errs)
+		}
+	}
+
+	// The network name names its default bridge and relay
+	bad := &Network{ObjectMeta: metav1.ObjectMeta{Name: "g$10"}, Spec: NetworkSpec{CIDR: "192.168.10.0/24"}}
+	if errs := p.validateNetworkSemantics(bad); !strings.Contains(strings.Join(errs, "; "), "metadata.name") {
+		t.Errorf("name with $ accepted: %v", errs)
+	}
+
+	// Updating a network must not report overlap with itself
+	if errs := p.validateNetworkSemantics(p.networks["gt"]); len(errs) != 0 {
+		t.Errorf("self-overlap reported: %v", errs)
+	}
+}
+
+func TestCreateBMHRejectsMissingBootConfig(t *testing.T) {
+	p, _ := newTestProvider(t)
+	mux := http.NewServeMux()
+	p.RegisterRoutes(mux)
+
+	body := `{"metadata":{"name":"server1"},"spec":{"bootMACAddress":"aa:bb:cc:dd:ee:ff","bootConfigRef":"missing"}}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/namespaces/default/baremetalhosts", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d (%s)", rec.Code, rec.Body.String())
+	}
+	if !strings.Contains(rec.Body.String(), `BootConfig "missing" not found`) {
+		t.Errorf("unexpected error body: %s", rec.Body.String())
+	}
+	if _, ok := p.bareMetalHosts["default/server1"]; ok {
+		t.Error("rejected BMH was stored")
+	}
+}
@@ -0,0 +1,520 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kruntime "k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/glennswest/mkube/pkg/admission"
+)
+
+// Mutating- and ValidatingWebhookConfigurations are cluster-scoped and use
+// the upstream admissionregistration/v1 types unchanged, so manifests written
+// for Kubernetes apply as-is. Only clientConfig.url is supported.
+
+// ─── Validation ─────────────────────────────────────────────────────────────
+
+func checkMutatingWebhookConfiguration(cfg *admissionregv1.MutatingWebhookConfiguration) []string {
+	var errs []string
+	seen := make(map[string]bool, len(cfg.Webhooks))
+	for _, w := range cfg.Webhooks {
+		wh := admission.FromMutating(cfg.Name, w)
+		errs = append(errs, checkWebhook(&wh, seen)...)
+	}
+	return errs
+}
+
+func checkValidatingWebhookConfiguration(cfg *admissionregv1.ValidatingWebhookConfiguration) []string {
+	var errs []string
+	seen := make(map[string]bool, len(cfg.Webhooks))
+	for _, w := range cfg.Webhooks {
+		wh := admission.FromValidating(cfg.Name, w)
+		errs = append(errs, checkWebhook(&wh, seen)...)
+	}
+	return errs
+}
+
+func checkWebhook(wh *admission.Webhook, seen map[string]bool) []string {
+	errs := wh.Check()
+	if wh.Name != "" {
+		if seen[wh.Name] {
+			errs = append(errs, fmt.Sprintf("webhook %q: duplicate name", wh.Name))
+		}
+		seen[wh.Name] = true
+	}
+	return errs
+}
+
+// ─── Store Operations ────────────────────────────────────────────────────────
+
+func (p *MicroKubeProvider) LoadWebhookConfigurationsFromStore(ctx context.Context) {
+	if p.deps.Store == nil {
+		return
+	}
+
+	if b := p.deps.Store.MutatingWebhooks; b != nil {
+		keys, err := b.Keys(ctx, "")
+		if err != nil {
+			p.deps.Logger.Warnw("failed to list mutating webhook configurations from store", "error", err)
+		}
+		for _, key := range keys {
+			var cfg admissionregv1.MutatingWebhookConfiguration
+			if _, err := b.GetJSON(ctx, key, &cfg); err != nil {
+				p.deps.Logger.Warnw("failed to read mutating webhook configuration from store", "key", key, "error", err)
+				continue
+			}
+			p.mutatingWebhooks[cfg.Name] = &cfg
+		}
+	}
+
+	if b := p.deps.Store.ValidatingWebhooks; b != nil {
+		keys, err := b.Keys(ctx, "")
+		if err != nil {
+			p.deps.Logger.Warnw("failed to list validating webhook configurations from store", "error", err)
+		}
+		for _, key := range keys {
+			var cfg admissionregv1.ValidatingWebhookConfiguration
+			if _, err := b.GetJSON(ctx, key, &cfg); err != nil {
+				p.deps.Logger.Warnw("failed to read validating webhook configuration from store", "key", key, "error", err)
+				continue
+			}
+			p.validatingWebhooks[cfg.Name] = &cfg
+		}
+	}
+
+	if n := len(p.mutatingWebhooks) + len(p.validatingWebhooks); n > 0 {
+		p.deps.Logger.Infow("loaded webhook configurations from store",
+			"mutating", len(p.mutatingWebhooks), "validating", len(p.validatingWebhooks))
+	}
+}
+
+func (p *MicroKubeProvider) persistMutatingWebhookConfiguration(ctx context.Context, cfg *admissionregv1.MutatingWebhookConfiguration) {
+	if p.deps.Store != nil && p.deps.Store.MutatingWebhooks != nil {
+		if _, err := p.deps.Store.MutatingWebhooks.PutJSON(ctx, cfg.Name, cfg); err != nil {
+			p.deps.Logger.Warnw("failed to persist MutatingWebhookConfiguration", "name", cfg.Name, "error", err)
+		}
+	}
+}
+
+func (p *MicroKubeProvider) persistValidatingWebhookConfiguration(ctx context.Context, cfg *admissionregv1.ValidatingWebhookConfiguration) {
+	if p.deps.Store != nil && p.deps.Store.ValidatingWebhooks != nil {
+		if _, err := p.deps.Store.ValidatingWebhooks.PutJSON(ctx, cfg.Name, cfg); err != nil {
+			p.deps.Logger.Warnw("failed to persist ValidatingWebhookConfiguration", "name", cfg.Name, "error", err)
+		}
+	}
+}
+
+// ─── MutatingWebhookConfiguration Handlers ─────────────────────────────────
+
+func (p *MicroKubeProvider) handleListMutatingWebhookConfigurations(w http.ResponseWriter, r *http.Request) {
+	items := make([]admissionregv1.MutatingWebhookConfiguration, 0, len(p.mutatingWebhooks))
+	for _, cfg := range p.mutatingWebhooks {
+		c := cfg.DeepCopy()
+		c.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "MutatingWebhookConfiguration"}
+		items = append(items, *c)
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Name < items[j].Name })
+
+	if wantsTable(r) {
+		rows := make([]webhookConfigRow, len(items))
+		for i := range items {
+			rows[i] = webhookConfigRow{meta: items[i].ObjectMeta, webhooks: len(items[i].Webhooks)}
+		}
+		podWriteJSON(w, http.StatusOK, webhookConfigListToTable(rows))
+		return
+	}
+
+	podWriteJSON(w, http.StatusOK, admissionregv1.MutatingWebhookConfigurationList{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "MutatingWebhookConfigurationList"},
+		Items:    items,
+	})
+}
+
+func (p *MicroKubeProvider) handleGetMutatingWebhookConfiguration(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+
+	cfg, ok := p.mutatingWebhooks[name]
+	if !ok {
+		http.Error(w, fmt.Sprintf("MutatingWebhookConfiguration %q not found", name), http.StatusNotFound)
+		return
+	}
+
+	c := cfg.DeepCopy()
+	c.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "MutatingWebhookConfiguration"}
+
+	if wantsTable(r) {
+		podWriteJSON(w, http.StatusOK, webhookConfigListToTable([]webhookConfigRow{{meta: c.ObjectMeta, webhooks: len(c.Webhooks)}}))
+		return
+	}
+
+	podWriteJSON(w, http.StatusOK, c)
+}
+
+func (p *MicroKubeProvider) handleCreateMutatingWebhookConfiguration(w http.ResponseWriter, r *http.Request) {
+	var cfg admissionregv1.MutatingWebhookConfiguration
+	if !decodeCRDBody(w, r, "MutatingWebhookConfiguration", false, &cfg) {
+		return
+	}
+
+	if cfg.Name == "" {
+		http.Error(w, "MutatingWebhookConfiguration name is required", http.StatusBadRequest)
+		return
+	}
+	if _, exists := p.mutatingWebhooks[cfg.Name]; exists {
+		http.Error(w, fmt.Sprintf("MutatingWebhookConfiguration %q already exists", cfg.Name), http.StatusConflict)
+		return
+	}
+	if errs := checkMutatingWebhookConfiguration(&cfg); len(errs) > 0 {
+		writeInvalid(w, "MutatingWebhookConfiguration", cfg.Name, errs)
+		return
+	}
+
+	cfg.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "MutatingWebhookConfiguration"}
+	if cfg.CreationTimestamp.IsZero() {
+		cfg.CreationTimestamp = metav1.Now()
+	}
+
+	p.persistMutatingWebhookConfiguration(r.Context(), &cfg)
+	p.mutatingWebhooks[cfg.Name] = &cfg
+
+	podWriteJSON(w, http.StatusCreated, &cfg)
+}
+
+func (p *MicroKubeProvider) handleUpdateMutatingWebhookConfiguration(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+
+	old, ok := p.mutatingWebhooks[name]
+	if !ok {
+		http.Error(w, fmt.Sprintf("MutatingWebhookConfiguration %q not found", name), http.StatusNotFound)
+		return
+	}
+
+	var cfg admissionregv1.MutatingWebhookConfiguration
+	if !decodeCRDBody(w, r, "MutatingWebhookConfiguration", false, &cfg) {
+		return
+	}
+	cfg.Name = name
+	cfg.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "MutatingWebhookConfiguration"}
+	cfg.CreationTimestamp = old.CreationTimestamp
+
+	if errs := checkMutatingWebhookConfiguration(&cfg); len(errs) > 0 {
+		writeInvalid(w, "MutatingWebhookConfiguration", name, errs)
+		return
+	}
+
+	p.persistMutatingWebhookConfiguration(r.Context(), &cfg)
+	p.mutatingWebhooks[name] = &cfg
+
+	podWriteJSON(w, http.StatusOK, &cfg)
+}
+
+func (p *MicroKubeProvider) handlePatchMutatingWebhookConfiguration(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+
+	existing, ok := p.mutatingWebhooks[name]
+	if !ok {
+		http.Error(w, fmt.Sprintf("MutatingWebhookConfiguration %q not found", name), http.StatusNotFound)
+		return
+	}
+
+	merged := existing.DeepCopy()
+	if !decodeCRDBody(w, r, "MutatingWebhookConfiguration", true, merged) {
+		return
+	}
+	merged.Name = name
+	merged.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "MutatingWebhookConfiguration"}
+	merged.CreationTimestamp = existing.CreationTimestamp
+
+	if errs := checkMutatingWebhookConfiguration(merged); len(errs) > 0 {
+		writeInvalid(w, "MutatingWebhookConfiguration", name, errs)
+		return
+	}
+
+	p.persistMutatingWebhookConfiguration(r.Context(), merged)
+	p.mutatingWebhooks[name] = merged
+
+	podWriteJSON(w, http.StatusOK, merged)
+}
+
+func (p *MicroKubeProvider) handleDeleteMutatingWebhookConfiguration(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+
+	if _, ok := p.mutatingWebhooks[name]; !ok {
+		http.Error(w, fmt.Sprintf("MutatingWebhookConfiguration %q not found", name), http.StatusNotFound)
+		return
+	}
+
+	if p.deps.Store != nil && p.deps.Store.MutatingWebhooks != nil {
+		if err := p.deps.Store.MutatingWebhooks.Delete(r.Context(), name); err != nil {
+			http.Error(w, fmt.Sprintf("deleting MutatingWebhookConfiguration from store: %v", err), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	delete(p.mutatingWebhooks, name)
+
+	podWriteJSON(w, http.StatusOK, metav1.Status{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Status"},
+		Status:   "Success",
+		Message:  fmt.Sprintf("MutatingWebhookConfiguration %q deleted", name),
+	})
+}
+
+// ─── ValidatingWebhookConfiguration Handlers ───────────────────────────────
+
+func (p *MicroKubeProvider) handleListValidatingWebhookConfigurations(w http.ResponseWriter, r *http.Request) {
+	items := make([]admissionregv1.ValidatingWebhookConfiguration, 0, len(p.validatingWebhooks))
+	for _, cfg := range p.validatingWebhooks {
+		c := cfg.DeepCopy()
+		c.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "ValidatingWebhookConfiguration"}
+		items = append(items, *c)
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Name < items[j].Name })
+
+	if wantsTable(r) {
+		rows := make([]webhookConfigRow, len(items))
+		for i := range items {
+			rows[i] = webhookConfigRow{meta: items[i].ObjectMeta, webhooks: len(items[i].Webhooks)}
+		}
+		podWriteJSON(w, http.StatusOK, webhookConfigListToTable(rows))
+		return
+	}
+
+	podWriteJSON(w, http.StatusOK, admissionregv1.ValidatingWebhookConfigurationList{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "ValidatingWebhookConfigurationList"},
+		Items:    items,
+	})
+}
+
+func (p *MicroKubeProvider) handleGetValidatingWebhookConfiguration(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+
+	cfg, ok := p.validatingWebhooks[name]
+	if !ok {
+		http.Error(w, fmt.Sprintf("ValidatingWebhookConfiguration %q not found", name), http.StatusNotFound)
+		return
+	}
+
+	c := cfg.DeepCopy()
+	c.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "ValidatingWebhookConfiguration"}
+
+	if wantsTable(r) {
+		podWriteJSON(w, http.StatusOK, webhookConfigListToTable([]webhookConfigRow{{meta: c.ObjectMeta, webhooks: len(c.Webhooks)}}))
+		return
+	}
+
+	podWriteJSON(w, http.StatusOK, c)
+}
+
+func (p *MicroKubeProvider) handleCreateValidatingWebhookConfiguration(w http.ResponseWriter, r *http.Request) {
+	var cfg admissionregv1.ValidatingWebhookConfiguration
+	if !decodeCRDBody(w, r, "ValidatingWebhookConfiguration", false, &cfg) {
+		return
+	}
+
+	if cfg.Name == "" {
+		http.Error(w, "ValidatingWebhookConfiguration name is required", http.StatusBadRequest)
+		return
+	}
+	if _, exists := p.validatingWebhooks[cfg.Name]; exists {
+		http.Error(w, fmt.Sprintf("ValidatingWebhookConfiguration %q already exists", cfg.Name), http.StatusConflict)
+		return
+	}
+	if errs := checkValidatingWebhookConfiguration(&cfg); len(errs) > 0 {
+		writeInvalid(w, "ValidatingWebhookConfiguration", cfg.Name, errs)
+		return
+	}
+
+	cfg.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "ValidatingWebhookConfiguration"}
+	if cfg.CreationTimestamp.IsZero() {
+		cfg.CreationTimestamp = metav1.Now()
+	}
+
+	p.persistValidatingWebhookConfiguration(r.Context(), &cfg)
+	p.validatingWebhooks[cfg.Name] = &cfg
+
+	podWriteJSON(w, http.StatusCreated, &cfg)
+}
+
+func (p *MicroKubeProvider) handleUpdateValidatingWebhookConfiguration(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+
+	old, ok := p.validatingWebhooks[name]
+	if !ok {
+		http.Error(w, fmt.Sprintf("ValidatingWebhookConfiguration %q not found", name), http.StatusNotFound)
+		return
+	}
+
+	var cfg admissionregv1.ValidatingWebhookConfiguration
+	if !decodeCRDBody(w, r, "ValidatingWebhookConfiguration", false, &cfg) {
+		return
+	}
+	cfg.Name = name
+	cfg.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "ValidatingWebhookConfiguration"}
+	cfg.CreationTimestamp = old.CreationTimestamp
+
+	if errs := checkValidatingWebhookConfiguration(&cfg); len(errs) > 0 {
+		writeInvalid(w, "ValidatingWebhookConfiguration", name, errs)
+		return
+	}
+
+	p.persistValidatingWebhookConfiguration(r.Context(), &cfg)
+	p.validatingWebhooks[name] = &cfg
+
+	podWriteJSON(w, http.StatusOK, &cfg)
+}
+
+func (p *MicroKubeProvider) handlePatchValidatingWebhookConfiguration(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+
+	existing, ok := p.validatingWebhooks[name]
+	if !ok {
+		http.Error(w, fmt.Sprintf("ValidatingWebhookConfiguration %q not found", name), http.StatusNotFound)
+		return
+	}
+
+	merged := existing.DeepCopy()
+	if !decodeCRDBody(w, r, "ValidatingWebhookConfiguration", true, merged) {
+		return
+	}
+	merged.Name = name
+	merged.TypeMeta = metav1.TypeMeta{APIVersion: "v1", Kind: "ValidatingWebhookConfiguration"}
+	merged.CreationTimestamp = existing.CreationTimestamp
+
+	if errs := checkValidatingWebhookConfiguration(merged); len(errs) > 0 {
+		writeInvalid(w, "ValidatingWebhookConfiguration", name, errs)
+		return
+	}
+
+	p.persistValidatingWebhookConfiguration(r.Context(), merged)
+	p.validatingWebhooks[name] = merged
+
+	podWriteJSON(w, http.StatusOK, merged)
+}
+
+func (p *MicroKubeProvider) handleDeleteValidatingWebhookConfiguration(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+
+	if _, ok := p.validatingWebhooks[name]; !ok {
+		http.Error(w, fmt.Sprintf("ValidatingWebhookConfiguration %q not found", name), http.StatusNotFound)
+		return
+	}
+
+	if p.deps.Store != nil && p.deps.Store.ValidatingWebhooks != nil {
+		if err := p.deps.Store.ValidatingWebhooks.Delete(r.Context(), name); err != nil {
+			http.Error(w, fmt.Sprintf("deleting ValidatingWebhookConfiguration from store: %v", err), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	delete(p.validatingWebhooks, name)
+
+	podWriteJSON(w, http.StatusOK, metav1.Status{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Status"},
+		Status:   "Success",
+		Message:  fmt.Sprintf("ValidatingWebhookConfiguration %q deleted", name),
+	})
+}
+
+// ─── Table Format ───────────────────────────────────────────────────────────
+
+type webhookConfigRow struct {
+	meta     metav1.ObjectMeta
+	webhooks int
+}
+
+func webhookConfigListToTable(items []webhookConfigRow) *metav1.Table {
+	table := &metav1.Table{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "meta.k8s.io/v1",
+			Kind:       "Table",
+		},
+		ColumnDefinitions: []metav1.TableColumnDefinition{
+			{Name: "Name", Type: "string", Format: "name"},
+			{Name: "Webhooks", Type: "integer"},
+			{Name: "Age", Type: "string"},
+		},
+	}
+
+	for _, item := range items {
+		age := "<unknown>"
+		if !item.meta.CreationTimestamp.IsZero() {
+			age = formatAge(time.Since(item.meta.CreationTimestamp.Time))
+		}
+
+		raw, _ := json.Marshal(map[string]interface{}{
+			"kind":       "PartialObjectMetadata",
+			"apiVersion": "meta.k8s.io/v1",
+			"metadata": map[string]interface{}{
+				"name":              item.meta.Name,
+				"creationTimestamp": item.meta.CreationTimestamp.Format(time.RFC3339),
+			},
+		})
+
+		table.Rows = append(table.Rows, metav1.TableRow{
+			Cells:  []interface{}{item.meta.Name, item.webhooks, age},
+			Object: kruntime.RawExtension{Raw: raw},
+		})
+	}
+
+	return table
+}
+
+// ─── Consistency ────────────────────────────────────────────────────────────
+
+func (p *MicroKubeProvider) checkWebhookConfigurations(ctx context.Context) []CheckItem {
+	var items []CheckItem
+	if p.deps.Store == nil {
+		return items
+	}
+
+	check := func(prefix, kind string, keys []string, names []string) {
+		storeSet := make(map[string]bool, len(keys))
+		for _, k := range keys {
+			storeSet[k] = true
+		}
+		for _, name := range names {
+			if storeSet[name] {
+				items = append(items, CheckItem{
+					Name:    fmt.Sprintf("%s/%s", prefix, name),
+					Status:  "pass",
+					Message: kind + " synced with NATS",
+				})
+			} else {
+				items = append(items, CheckItem{
+					Name:    fmt.Sprintf("%s/%s", prefix, name),
+					Status:  "warn",
+					Message: kind + " in memory but not in NATS store",
+				})
+			}
+		}
+	}
+
+	if b := p.deps.Store.MutatingWebhooks; b != nil {
+		if keys, err := b.Keys(ctx, ""); err == nil {
+			names := make([]string, 0, len(p.mutatingWebhooks))
+			for name := range p.mutatingWebhooks {
+				names = append(names, name)
+			}
+			check("mutatingwebhookconfiguration", "MutatingWebhookConfiguration", keys, names)
+		}
+	}
+	if b := p.deps.Store.ValidatingWebhooks; b != nil {
+		if keys, err := b.Keys(ctx, ""); err == nil {
+			names := make([]string, 0, len(p.validatingWebhooks))
+			for name := range p.validatingWebhooks {
+				names = append(names, name)
+			}
+			check("validatingwebhookconfiguration", "ValidatingWebhookConfiguration", keys, names)
+		}
+	}
+
+	return items
+}
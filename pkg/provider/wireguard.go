@@ -0,0 +1,272 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/glennswest/mkube/pkg/network"
+)
+
+// Each node's WireGuard private key lives in the Secret
+// kube-system/wireguard-<node>. The SECRETS bucket is not synced, so the
+// Secret stays on its node. Only the public key is published, in the
+// ConfigMap of the same name; the CONFIGMAPS bucket is synced across the
+// cluster, so peers read each other's public keys from their own store.
+const (
+	wireGuardNamespace    = "kube-system"
+	wireGuardRotatedAtAnn = "vkube.io/wireguard-rotated-at"
+)
+
+// wireGuardObjectName returns the name of node's private key Secret and
+// public key ConfigMap.
+func wireGuardObjectName(node string) string {
+	return "wireguard-" + node
+}
+
+// wireGuardKeyConfigMap returns the ConfigMap publishing node's public key.
+// Peer ConfigMaps arrive through cluster sync, which writes only the store,
+// so the store is read first and the in-memory map is the fallback. Must be
+// called with p.mu held.
+func (p *MicroKubeProvider) wireGuardKeyConfigMap(ctx context.Context, node string) *corev1.ConfigMap {
+	if cm := p.storedWireGuardKeyConfigMap(ctx, node); cm != nil {
+		return cm
+	}
+	return p.configMaps[wireGuardNamespace+"/"+wireGuardObjectName(node)]
+}
+
+// loadWireGuardKeyConfigMap is wireGuardKeyConfigMap for callers not
+// holding p.mu: the store is safe to read on its own, and the read lock is
+// taken only for the in-memory fallback.
+func (p *MicroKubeProvider) loadWireGuardKeyConfigMap(ctx context.Context, node string) *corev1.ConfigMap {
+	if cm := p.storedWireGuardKeyConfigMap(ctx, node); cm != nil {
+		return cm
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.configMaps[wireGuardNamespace+"/"+wireGuardObjectName(node)]
+}
+
+func (p *MicroKubeProvider) storedWireGuardKeyConfigMap(ctx context.Context, node string) *corev1.ConfigMap {
+	if p.deps.Store == nil || p.deps.Store.ConfigMaps == nil {
+		return nil
+	}
+	var cm corev1.ConfigMap
+	if _, err := p.deps.Store.ConfigMaps.GetJSON(ctx, wireGuardNamespace+"."+wireGuardObjectName(node), &cm); err != nil {
+		return nil
+	}
+	return &cm
+}
+
+// wireGuardPrivateKey returns this node's private key from its Secret, ""
+// if there is none yet. Must be called with p.mu held.
+func (p *MicroKubeProvider) wireGuardPrivateKey() string {
+	if s := p.secrets[wireGuardNamespace+"/"+wireGuardObjectName(p.clusterMgr.NodeName())]; s != nil {
+		return string(s.Data["privateKey"])
+	}
+	return ""
+}
+
+// loadWireGuardPrivateKey is wireGuardPrivateKey for callers not holding
+// p.mu.
+func (p *MicroKubeProvider) loadWireGuardPrivateKey() string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.wireGuardPrivateKey()
+}
+
+// ensureWireGuardKey returns this node's private key, generating a keypair
+// on first use, and makes sure its public key is published. Must be called
+// without p.mu held; the write lock is taken only when the Secret or the
+// published key has to change.
+func (p *MicroKubeProvider) ensureWireGuardKey(ctx context.Context) (string, error) {
+	p.wgPeerSecretsPurge.Do(func() { p.purgePeerWireGuardSecrets(ctx) })
+
+	node := p.clusterMgr.NodeName()
+	priv := p.loadWireGuardPrivateKey()
+	if priv != "" {
+		pub, err := network.WireGuardPublicKey(priv)
+		if err != nil {
+			return "", err
+		}
+		if cm := p.loadWireGuardKeyConfigMap(ctx, node); cm != nil && cm.Data["publicKey"] == pub {
+			return priv, nil
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if priv = p.wireGuardPrivateKey(); priv == "" {
+		cm, err := p.rotateWireGuardKey(ctx)
+		if err != nil {
+			return "", err
+		}
+		p.deps.Logger.Infow("WireGuard keypair generated", "node", node, "publicKey", cm.Data["publicKey"])
+		return p.wireGuardPrivateKey(), nil
+	}
+	pub, err := network.WireGuardPublicKey(priv)
+	if err != nil {
+		return "", err
+	}
+	if cm := p.wireGuardKeyConfigMap(ctx, node); cm == nil || cm.Data["publicKey"] != pub {
+		if err := p.publishWireGuardKey(ctx, pub); err != nil {
+			return "", err
+		}
+	}
+	return priv, nil
+}
+
+// rotateWireGuardKey replaces this node's keypair. Peers pick up the new
+// public key on their next overlay pass and rebuild their site link; until
+// then the link to them is down. Must be called with p.mu held for writing.
+func (p *MicroKubeProvider) rotateWireGuardKey(ctx context.Context) (*corev1.ConfigMap, error) {
+	priv, pub, err := network.GenerateWireGuardKey()
+	if err != nil {
+		return nil, err
+	}
+	s := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.Now()},
+		Data:       map[string][]byte{"privateKey": []byte(priv)},
+	}
+	normalizeSecret(s, wireGuardNamespace, wireGuardObjectName(p.clusterMgr.NodeName()))
+	if err := p.putSecret(ctx, s); err != nil {
+		return nil, fmt.Errorf("storing WireGuard private key: %w", err)
+	}
+	if err := p.publishWireGuardKey(ctx, pub); err != nil {
+		return nil, err
+	}
+	return p.configMaps[wireGuardNamespace+"/"+wireGuardObjectName(p.clusterMgr.NodeName())], nil
+}
+
+// publishWireGuardKey writes this node's public key ConfigMap. Must be
+// called with p.mu held for writing.
+func (p *MicroKubeProvider) publishWireGuardKey(ctx context.Context, pub string) error {
+	name := wireGuardObjectName(p.clusterMgr.NodeName())
+	cm := &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         wireGuardNamespace,
+			CreationTimestamp: metav1.Now(),
+			Annotations:       map[string]string{wireGuardRotatedAtAnn: time.Now().UTC().Format(time.RFC3339)},
+		},
+		Data: map[string]string{"publicKey": pub},
+	}
+	if p.deps.Store != nil && p.deps.Store.ConfigMaps != nil {
+		if _, err := p.deps.Store.ConfigMaps.PutJSON(ctx, wireGuardNamespace+"."+name, cm); err != nil {
+			return fmt.Errorf("publishing WireGuard public key: %w", err)
+		}
+	}
+	p.configMaps[wireGuardNamespace+"/"+name] = cm
+	p.deps.Logger.Infow("WireGuard public key published", "node", p.clusterMgr.NodeName(), "publicKey", pub)
+	return nil
+}
+
+// purgePeerWireGuardSecrets deletes the keypair Secrets of cluster peers.
+// Older releases synced the SECRETS bucket, leaving a copy of every peer's
+// private key on each node. Takes p.mu for writing.
+func (p *MicroKubeProvider) purgePeerWireGuardSecrets(ctx context.Context) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, peer := range p.deps.Config.Cluster.Peers {
+		if peer.Name == p.clusterMgr.NodeName() {
+			continue
+		}
+		name := wireGuardObjectName(peer.Name)
+		if _, ok := p.secrets[wireGuardNamespace+"/"+name]; !ok {
+			continue
+		}
+		if p.deps.Store != nil && p.deps.Store.Secrets != nil {
+			if err := p.deps.Store.Secrets.Delete(ctx, wireGuardNamespace+"."+name); err != nil {
+				p.deps.Logger.Warnw("failed to delete peer WireGuard key Secret", "secret", name, "error", err)
+				continue
+			}
+		}
+		delete(p.secrets, wireGuardNamespace+"/"+name)
+		p.deps.Logger.Infow("deleted synced copy of a peer's WireGuard private key", "secret", name)
+	}
+}
+
+// peerAllowedIPs returns the CIDRs of the networks behind peer, from
+// Network CRDs and the config file. Must be called with p.mu held.
+func (p *MicroKubeProvider) peerAllowedIPs(networks []string) []string {
+	var cidrs []string
+	for _, name := range networks {
+		if n, ok := p.networks[name]; ok {
+			cidrs = append(cidrs, n.Spec.CIDR)
+			if c6 := n.Spec.secondaryCIDR6(); c6 != "" {
+				cidrs = append(cidrs, c6)
+			}
+			continue
+		}
+		for _, def := range p.deps.Config.Networks {
+			if def.Name == name {
+				cidrs = append(cidrs, def.CIDR)
+				if def.CIDR6 != "" {
+					cidrs = append(cidrs, def.CIDR6)
+				}
+			}
+		}
+	}
+	return cidrs
+}
+
+// kickOverlay asks the overlay mesh controller to run a pass soon.
+func (p *MicroKubeProvider) kickOverlay() {
+	select {
+	case p.overlayKick <- struct{}{}:
+	default:
+	}
+}
+
+// handleGetWireGuard returns this node's public key and its site links.
+func (p *MicroKubeProvider) handleGetWireGuard(w http.ResponseWriter, r *http.Request) {
+	if p.clusterMgr == nil {
+		http.Error(w, "clustering is not enabled", http.StatusNotFound)
+		return
+	}
+	resp := map[string]interface{}{
+		"node":    p.clusterMgr.NodeName(),
+		"enabled": p.deps.Config.Cluster.WireGuard.Enabled,
+	}
+	if cm := p.wireGuardKeyConfigMap(r.Context(), p.clusterMgr.NodeName()); cm != nil {
+		resp["publicKey"] = cm.Data["publicKey"]
+		resp["rotatedAt"] = cm.Annotations[wireGuardRotatedAtAnn]
+	}
+	links := []network.Tunnel{}
+	if p.deps.NetworkMgr != nil {
+		for _, t := range p.deps.NetworkMgr.Tunnels() {
+			if t.Type == "wireguard" {
+				links = append(links, t)
+			}
+		}
+	}
+	sort.Slice(links, func(i, j int) bool { return links[i].RemoteNode < links[j].RemoteNode })
+	resp["links"] = links
+	podWriteJSON(w, http.StatusOK, resp)
+}
+
+// handleRotateWireGuardKey generates a new keypair for this node and
+// rebuilds its site links.
+func (p *MicroKubeProvider) handleRotateWireGuardKey(w http.ResponseWriter, r *http.Request) {
+	if p.clusterMgr == nil || !p.deps.Config.Cluster.WireGuard.Enabled {
+		http.Error(w, "WireGuard site links are not enabled (cluster.wireguard.enabled)", http.StatusConflict)
+		return
+	}
+	cm, err := p.rotateWireGuardKey(r.Context())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("rotating WireGuard key: %v", err), http.StatusInternalServerError)
+		return
+	}
+	p.kickOverlay()
+	podWriteJSON(w, http.StatusOK, map[string]string{
+		"node":      p.clusterMgr.NodeName(),
+		"publicKey": cm.Data["publicKey"],
+		"rotatedAt": cm.Annotations[wireGuardRotatedAtAnn],
+	})
+}
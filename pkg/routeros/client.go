@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -75,11 +76,12 @@ type ContainerSpec struct {
 
 // NetworkInterface represents a veth interface for containers.
 type NetworkInterface struct {
-	ID      string `json:".id"`
-	Name    string `json:"name"`
-	Address string `json:"address"`
-	Gateway string `json:"gateway"`
-	Bridge  string `json:"bridge"`
+	ID       string `json:".id"`
+	Name     string `json:"name"`
+	Address  string `json:"address"` // comma-separated when dual-stack
+	Gateway  string `json:"gateway"`
+	Gateway6 string `json:"gateway6,omitempty"`
+	Bridge   string `json:"bridge"`
 }
 
 // NewClient creates a new RouterOS API client.
@@ -197,29 +199,34 @@ func (c *Client) RemoveMountsByList(ctx context.Context, listName string) error
 // CreateVeth creates a virtual ethernet interface for a container.
 // Idempotent: if the veth already exists with matching config, returns nil.
 // If it exists with different address/gateway, updates it in place.
+//
+// Dual-stack veths pass comma-separated lists: address "a/24,b/64" is sent
+// as-is, and gateway "gw4,gw6" is split into gateway and gateway6.
 func (c *Client) CreateVeth(ctx context.Context, name, address, gateway string) error {
+	gateway, gateway6, _ := strings.Cut(gateway, ",")
 	veths, err := c.ListVeths(ctx)
 	if err != nil {
 		return fmt.Errorf("listing veths for idempotent create %q: %w", name, err)
 	}
+	params := map[string]string{
+		"address": address,
+		"gateway": gateway,
+	}
+	if gateway6 != "" {
+		params["gateway6"] = gateway6
+	}
 	for _, v := range veths {
 		if v.Name == name {
-			if v.Address == address && v.Gateway == gateway {
+			if v.Address == address && v.Gateway == gateway && v.Gateway6 == gateway6 {
 				return nil // already exists with correct config
 			}
 			// exists with different config — update in place
-			return c.restPOST(ctx, "/interface/veth/set", map[string]string{
-				".id":     v.ID,
-				"address": address,
-				"gateway": gateway,
-			}, nil)
+			params[".id"] = v.ID
+			return c.restPOST(ctx, "/interface/veth/set", params, nil)
 		}
 	}
-	return c.restPOST(ctx, "/interface/veth/add", map[string]string{
-		"name":    name,
-		"address": address,
-		"gateway": gateway,
-	}, nil)
+	params["name"] = name
+	return c.restPOST(ctx, "/interface/veth/add", params, nil)
 }
 
 // RemoveVeth removes a virtual ethernet interface by name.
@@ -451,8 +458,9 @@ func (c *Client) ListBridgePorts(ctx context.Context) ([]BridgePort, error) {
 
 // Bridge represents a bridge interface.
 type Bridge struct {
-	ID   string `json:".id"`
-	Name string `json:"name"`
+	ID            string `json:".id"`
+	Name          string `json:"name"`
+	VLANFiltering string `json:"vlan-filtering,omitempty"` // "true" or "false"
 }
 
 // ListBridges returns all bridge interfaces.
@@ -571,13 +579,264 @@ func (c *Client) CreateEoIPTunnel(ctx context.Context, name, localIP, remoteIP s
 	}, nil)
 }
 
-// DeleteEoIPTunnel removes an EoIP tunnel interface by name.
+// EoIPTunnel represents a RouterOS EoIP tunnel interface.
+type EoIPTunnel struct {
+	ID            string `json:".id"`
+	Name          string `json:"name"`
+	LocalAddress  string `json:"local-address"`
+	RemoteAddress string `json:"remote-address"`
+	TunnelID      string `json:"tunnel-id"`
+}
+
+// ListEoIPTunnels returns all EoIP tunnel interfaces.
+func (c *Client) ListEoIPTunnels(ctx context.Context) ([]EoIPTunnel, error) {
+	var tunnels []EoIPTunnel
+	err := c.restGET(ctx, "/interface/eoip", &tunnels)
+	return tunnels, err
+}
+
+// DeleteEoIPTunnel removes an EoIP tunnel interface by name, along with any
+// bridge ports it holds. A missing tunnel is not an error.
 func (c *Client) DeleteEoIPTunnel(ctx context.Context, name string) error {
-	return c.restPOST(ctx, "/interface/eoip/remove", map[string]string{
-		"name": name,
+	tunnels, err := c.ListEoIPTunnels(ctx)
+	if err != nil {
+		return fmt.Errorf("listing EoIP tunnels to find %q: %w", name, err)
+	}
+	for _, t := range tunnels {
+		if t.Name != name {
+			continue
+		}
+		ports, err := c.ListBridgePorts(ctx)
+		if err != nil {
+			return err
+		}
+		for _, p := range ports {
+			if p.Interface == name {
+				if err := c.restPOST(ctx, "/interface/bridge/port/remove", map[string]string{".id": p.ID}, nil); err != nil {
+					return err
+				}
+			}
+		}
+		return c.restPOST(ctx, "/interface/eoip/remove", map[string]string{".id": t.ID}, nil)
+	}
+	return nil // already gone
+}
+
+// ─── WireGuard Operations ────────────────────────────────────────────────────
+
+// WireGuardInterface represents a RouterOS WireGuard interface.
+type WireGuardInterface struct {
+	ID         string `json:".id"`
+	Name       string `json:"name"`
+	ListenPort string `json:"listen-port"`
+	PublicKey  string `json:"public-key"`
+}
+
+// WireGuardPeer represents a peer of a RouterOS WireGuard interface.
+type WireGuardPeer struct {
+	ID             string `json:".id"`
+	Interface      string `json:"interface"`
+	PublicKey      string `json:"public-key"`
+	EndpointAddr   string `json:"endpoint-address"`
+	EndpointPort   string `json:"endpoint-port"`
+	AllowedAddress string `json:"allowed-address"` // comma-separated CIDRs
+}
+
+// ListWireGuardInterfaces returns all WireGuard interfaces.
+func (c *Client) ListWireGuardInterfaces(ctx context.Context) ([]WireGuardInterface, error) {
+	var ifaces []WireGuardInterface
+	err := c.restGET(ctx, "/interface/wireguard", &ifaces)
+	return ifaces, err
+}
+
+// ListWireGuardPeers returns the peers of all WireGuard interfaces.
+func (c *Client) ListWireGuardPeers(ctx context.Context) ([]WireGuardPeer, error) {
+	var peers []WireGuardPeer
+	err := c.restGET(ctx, "/interface/wireguard/peers", &peers)
+	return peers, err
+}
+
+// CreateWireGuardInterface creates a WireGuard interface with the given
+// base64 private key, listening on port.
+func (c *Client) CreateWireGuardInterface(ctx context.Context, name, privateKey string, port int) error {
+	return c.restPOST(ctx, "/interface/wireguard/add", map[string]string{
+		"name":        name,
+		"private-key": privateKey,
+		"listen-port": strconv.Itoa(port),
+	}, nil)
+}
+
+// AddWireGuardPeer adds a peer to a WireGuard interface. The peer is
+// reached at endpoint:port and traffic for allowed is routed to it.
+func (c *Client) AddWireGuardPeer(ctx context.Context, iface, publicKey, endpoint string, port int, allowed []string) error {
+	return c.restPOST(ctx, "/interface/wireguard/peers/add", map[string]string{
+		"interface":            iface,
+		"public-key":           publicKey,
+		"endpoint-address":     endpoint,
+		"endpoint-port":        strconv.Itoa(port),
+		"allowed-address":      strings.Join(allowed, ","),
+		"persistent-keepalive": "25s",
 	}, nil)
 }
 
+// DeleteWireGuardInterface removes a WireGuard interface by name, with its
+// peers and the routes through it. A missing interface is not an error.
+func (c *Client) DeleteWireGuardInterface(ctx context.Context, name string) error {
+	ifaces, err := c.ListWireGuardInterfaces(ctx)
+	if err != nil {
+		return fmt.Errorf("listing WireGuard interfaces to find %q: %w", name, err)
+	}
+	for _, wg := range ifaces {
+		if wg.Name != name {
+			continue
+		}
+		peers, err := c.ListWireGuardPeers(ctx)
+		if err != nil {
+			return err
+		}
+		for _, p := range peers {
+			if p.Interface == name {
+				if err := c.restPOST(ctx, "/interface/wireguard/peers/remove", map[string]string{".id": p.ID}, nil); err != nil {
+					return err
+				}
+			}
+		}
+		if err := c.RemoveRoutesByGateway(ctx, name); err != nil {
+			return err
+		}
+		return c.restPOST(ctx, "/interface/wireguard/remove", map[string]string{".id": wg.ID}, nil)
+	}
+	return nil // already gone
+}
+
+// ─── Firewall NAT Operations ─────────────────────────────────────────────────
+
+// NATRule represents a RouterOS /ip/firewall/nat rule. Empty fields are
+// left unset when adding.
+type NATRule struct {
+	ID          string `json:".id,omitempty"`
+	Chain       string `json:"chain"`
+	Action      string `json:"action"`
+	Protocol    string `json:"protocol,omitempty"`
+	InInterface string `json:"in-interface,omitempty"`
+	DstAddress  string `json:"dst-address,omitempty"`
+	DstPort     string `json:"dst-port,omitempty"`
+	ToAddresses string `json:"to-addresses,omitempty"`
+	ToPorts     string `json:"to-ports,omitempty"`
+	Comment     string `json:"comment,omitempty"`
+	Disabled    string `json:"disabled,omitempty"`
+}
+
+// ListNATRules returns all IPv4 NAT rules.
+func (c *Client) ListNATRules(ctx context.Context) ([]NATRule, error) {
+	var rules []NATRule
+	err := c.restGET(ctx, "/ip/firewall/nat", &rules)
+	return rules, err
+}
+
+// AddNATRule appends a NAT rule. rule.ID is ignored.
+func (c *Client) AddNATRule(ctx context.Context, rule NATRule) error {
+	rule.ID = ""
+	return c.restPOST(ctx, "/ip/firewall/nat/add", rule, nil)
+}
+
+// RemoveNATRule removes a NAT rule by .id.
+func (c *Client) RemoveNATRule(ctx context.Context, id string) error {
+	return c.restPOST(ctx, "/ip/firewall/nat/remove", map[string]string{".id": id}, nil)
+}
+
+// ─── Simple Queue Operations ─────────────────────────────────────────────────
+
+// SimpleQueue represents a RouterOS /queue/simple entry. Rates are
+// "upload/download" pairs in bits per second, where upload is traffic sent
+// by the target and download is traffic sent to it.
+type SimpleQueue struct {
+	ID       string `json:".id,omitempty"`
+	Name     string `json:"name"`
+	Target   string `json:"target"`
+	MaxLimit string `json:"max-limit,omitempty"`
+	Rate     string `json:"rate,omitempty"`
+	Comment  string `json:"comment,omitempty"`
+	Disabled string `json:"disabled,omitempty"`
+}
+
+// ListSimpleQueues returns all simple queues with their current rates.
+func (c *Client) ListSimpleQueues(ctx context.Context) ([]SimpleQueue, error) {
+	var queues []SimpleQueue
+	err := c.restGET(ctx, "/queue/simple", &queues)
+	return queues, err
+}
+
+// AddSimpleQueue adds a simple queue. q.ID and q.Rate are ignored.
+func (c *Client) AddSimpleQueue(ctx context.Context, q SimpleQueue) error {
+	q.ID, q.Rate = "", ""
+	return c.restPOST(ctx, "/queue/simple/add", q, nil)
+}
+
+// SetSimpleQueue updates the target and max-limit of the queue with .id id.
+func (c *Client) SetSimpleQueue(ctx context.Context, id, target, maxLimit string) error {
+	return c.restPOST(ctx, "/queue/simple/set", map[string]string{
+		".id":       id,
+		"target":    target,
+		"max-limit": maxLimit,
+	}, nil)
+}
+
+// RemoveSimpleQueue removes a simple queue by .id.
+func (c *Client) RemoveSimpleQueue(ctx context.Context, id string) error {
+	return c.restPOST(ctx, "/queue/simple/remove", map[string]string{".id": id}, nil)
+}
+
+// ─── Route Operations ────────────────────────────────────────────────────────
+
+// Route represents a RouterOS IP route.
+type Route struct {
+	ID         string `json:".id"`
+	DstAddress string `json:"dst-address"`
+	Gateway    string `json:"gateway"`
+	Comment    string `json:"comment,omitempty"`
+}
+
+// ListRoutes returns the static and dynamic IPv4 routes.
+func (c *Client) ListRoutes(ctx context.Context) ([]Route, error) {
+	var routes []Route
+	err := c.restGET(ctx, "/ip/route", &routes)
+	return routes, err
+}
+
+// AddRoute adds a static route to dst via gateway (an address or an
+// interface name).
+func (c *Client) AddRoute(ctx context.Context, dst, gateway, comment string) error {
+	path := "/ip/route/add"
+	if strings.Contains(dst, ":") {
+		path = "/ipv6/route/add"
+	}
+	return c.restPOST(ctx, path, map[string]string{
+		"dst-address": dst,
+		"gateway":     gateway,
+		"comment":     comment,
+	}, nil)
+}
+
+// RemoveRoutesByGateway removes the static IPv4 and IPv6 routes whose
+// gateway is gateway.
+func (c *Client) RemoveRoutesByGateway(ctx context.Context, gateway string) error {
+	for _, family := range []string{"/ip/route", "/ipv6/route"} {
+		var routes []Route
+		if err := c.restGET(ctx, family, &routes); err != nil {
+			return err
+		}
+		for _, r := range routes {
+			if r.Gateway == gateway {
+				if err := c.restPOST(ctx, family+"/remove", map[string]string{".id": r.ID}, nil); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
 // ─── Bridge VLAN Operations ──────────────────────────────────────────────────
 
 // AddBridgeVLAN adds a VLAN entry on a bridge port.
@@ -615,6 +874,249 @@ func (c *Client) RemoveBridgeVLAN(ctx context.Context, bridge string, vid int) e
 	return fmt.Errorf("VLAN %d not found on bridge %s", vid, bridge)
 }
 
+// BridgeVLAN is an entry in a bridge's VLAN table.
+type BridgeVLAN struct {
+	ID       string `json:".id"`
+	Bridge   string `json:"bridge"`
+	VLANIDs  string `json:"vlan-ids"`
+	Tagged   string `json:"tagged"`   // comma-separated member interfaces
+	Untagged string `json:"untagged"` // comma-separated member interfaces
+}
+
+// ListBridgeVLANs returns all bridge VLAN table entries.
+func (c *Client) ListBridgeVLANs(ctx context.Context) ([]BridgeVLAN, error) {
+	var entries []BridgeVLAN
+	err := c.restGET(ctx, "/interface/bridge/vlan", &entries)
+	return entries, err
+}
+
+// EnsureBridgeVLAN makes sure the bridge's VLAN table has an entry for vid
+// with at least the given tagged members, adding the entry or merging the
+// members into an existing one. Reports whether the entry was created.
+func (c *Client) EnsureBridgeVLAN(ctx context.Context, bridge string, vid int, tagged []string) (bool, error) {
+	entries, err := c.ListBridgeVLANs(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, e := range entries {
+		if e.Bridge != bridge || e.VLANIDs != strconv.Itoa(vid) {
+			continue
+		}
+		members := splitMembers(e.Tagged)
+		changed := false
+		for _, t := range tagged {
+			if !containsMember(members, t) {
+				members = append(members, t)
+				changed = true
+			}
+		}
+		if !changed {
+			return false, nil
+		}
+		return false, c.restPOST(ctx, "/interface/bridge/vlan/set", map[string]string{
+			".id":    e.ID,
+			"tagged": strings.Join(members, ","),
+		}, nil)
+	}
+	return true, c.restPOST(ctx, "/interface/bridge/vlan/add", map[string]string{
+		"bridge":   bridge,
+		"vlan-ids": strconv.Itoa(vid),
+		"tagged":   strings.Join(tagged, ","),
+	}, nil)
+}
+
+// RemoveBridgeVLANMember drops iface from the tagged and untagged members
+// of the bridge VLAN entry for vid. A missing entry is not an error.
+func (c *Client) RemoveBridgeVLANMember(ctx context.Context, bridge string, vid int, iface string) error {
+	entries, err := c.ListBridgeVLANs(ctx)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if e.Bridge != bridge || e.VLANIDs != strconv.Itoa(vid) {
+			continue
+		}
+		tagged, untagged := splitMembers(e.Tagged), splitMembers(e.Untagged)
+		if !containsMember(tagged, iface) && !containsMember(untagged, iface) {
+			return nil
+		}
+		return c.restPOST(ctx, "/interface/bridge/vlan/set", map[string]string{
+			".id":      e.ID,
+			"tagged":   strings.Join(removeMember(tagged, iface), ","),
+			"untagged": strings.Join(removeMember(untagged, iface), ","),
+		}, nil)
+	}
+	return nil
+}
+
+// SetBridgeVLANFiltering turns VLAN filtering on or off for a bridge.
+func (c *Client) SetBridgeVLANFiltering(ctx context.Context, bridge string, enabled bool) error {
+	bridges, err := c.ListBridges(ctx)
+	if err != nil {
+		return fmt.Errorf("listing bridges to find %q: %w", bridge, err)
+	}
+	for _, b := range bridges {
+		if b.Name == bridge {
+			return c.restPOST(ctx, "/interface/bridge/set", map[string]string{
+				".id":            b.ID,
+				"vlan-filtering": yesNo(enabled),
+			}, nil)
+		}
+	}
+	return fmt.Errorf("bridge %q not found", bridge)
+}
+
+// SetBridgePortPVID sets the port VLAN ID of an interface's bridge port.
+// A PVID above 1 makes it an access port that only admits untagged frames;
+// 1 restores the default.
+func (c *Client) SetBridgePortPVID(ctx context.Context, iface string, pvid int) error {
+	ports, err := c.ListBridgePorts(ctx)
+	if err != nil {
+		return fmt.Errorf("listing bridge ports to find %q: %w", iface, err)
+	}
+	frameTypes := "admit-all"
+	if pvid > 1 {
+		frameTypes = "admit-only-untagged-and-priority-tagged"
+	}
+	for _, p := range ports {
+		if p.Interface == iface {
+			return c.restPOST(ctx, "/interface/bridge/port/set", map[string]string{
+				".id":         p.ID,
+				"pvid":        strconv.Itoa(pvid),
+				"frame-types": frameTypes,
+			}, nil)
+		}
+	}
+	return fmt.Errorf("%q is not a bridge port", iface)
+}
+
+// SetBridgePortHorizon sets the split-horizon group of an interface's
+// port on bridge. The bridge never forwards between ports with the same
+// horizon value; 0 clears it.
+func (c *Client) SetBridgePortHorizon(ctx context.Context, bridge, iface string, horizon int) error {
+	ports, err := c.ListBridgePorts(ctx)
+	if err != nil {
+		return fmt.Errorf("listing bridge ports to find %q: %w", iface, err)
+	}
+	value := "none"
+	if horizon > 0 {
+		value = strconv.Itoa(horizon)
+	}
+	for _, p := range ports {
+		if p.Bridge == bridge && p.Interface == iface {
+			return c.restPOST(ctx, "/interface/bridge/port/set", map[string]string{
+				".id":     p.ID,
+				"horizon": value,
+			}, nil)
+		}
+	}
+	return fmt.Errorf("%q is not a port of bridge %s", iface, bridge)
+}
+
+// RemoveBridgePort removes an interface from a bridge. Not being a port of
+// that bridge is not an error.
+func (c *Client) RemoveBridgePort(ctx context.Context, bridge, iface string) error {
+	ports, err := c.ListBridgePorts(ctx)
+	if err != nil {
+		return err
+	}
+	for _, p := range ports {
+		if p.Bridge == bridge && p.Interface == iface {
+			return c.restPOST(ctx, "/interface/bridge/port/remove", map[string]string{".id": p.ID}, nil)
+		}
+	}
+	return nil
+}
+
+// VLANInterface is an 802.1Q sub-interface.
+type VLANInterface struct {
+	ID        string `json:".id"`
+	Name      string `json:"name"`
+	Interface string `json:"interface"` // parent interface (trunk or bridge)
+	VLANID    string `json:"vlan-id"`
+}
+
+// ListVLANInterfaces returns all VLAN interfaces.
+func (c *Client) ListVLANInterfaces(ctx context.Context) ([]VLANInterface, error) {
+	var vlans []VLANInterface
+	err := c.restGET(ctx, "/interface/vlan", &vlans)
+	return vlans, err
+}
+
+// CreateVLANInterface adds a VLAN interface for vid on parent. Reports
+// whether it was created; an existing interface with the same name, parent
+// and VLAN ID is left alone.
+func (c *Client) CreateVLANInterface(ctx context.Context, name, parent string, vid int) (bool, error) {
+	vlans, err := c.ListVLANInterfaces(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, v := range vlans {
+		if v.Name != name {
+			continue
+		}
+		if v.Interface == parent && v.VLANID == strconv.Itoa(vid) {
+			return false, nil
+		}
+		return false, fmt.Errorf("VLAN interface %q exists on %s with vlan-id %s", name, v.Interface, v.VLANID)
+	}
+	return true, c.restPOST(ctx, "/interface/vlan/add", map[string]string{
+		"name":      name,
+		"interface": parent,
+		"vlan-id":   strconv.Itoa(vid),
+	}, nil)
+}
+
+// DeleteVLANInterface removes a VLAN interface by name.
+func (c *Client) DeleteVLANInterface(ctx context.Context, name string) error {
+	vlans, err := c.ListVLANInterfaces(ctx)
+	if err != nil {
+		return err
+	}
+	for _, v := range vlans {
+		if v.Name == name {
+			return c.restPOST(ctx, "/interface/vlan/remove", map[string]string{".id": v.ID}, nil)
+		}
+	}
+	return nil // already gone
+}
+
+func splitMembers(s string) []string {
+	var out []string
+	for _, m := range strings.Split(s, ",") {
+		if m = strings.TrimSpace(m); m != "" {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+func containsMember(members []string, iface string) bool {
+	for _, m := range members {
+		if m == iface {
+			return true
+		}
+	}
+	return false
+}
+
+func removeMember(members []string, iface string) []string {
+	out := members[:0]
+	for _, m := range members {
+		if m != iface {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
 // ─── IP Address Operations ──────────────────────────────────────────────────
 
 // IPAddress represents an IP address assignment on an interface.
@@ -657,6 +1159,76 @@ func (c *Client) GetSystemResource(ctx context.Context) (*SystemResource, error)
 	return &resource, nil
 }
 
+// Scheduler is a /system/scheduler entry. Without a start time, RouterOS
+// first runs OnEvent one Interval after the entry is added.
+type Scheduler struct {
+	ID       string `json:".id,omitempty"`
+	Name     string `json:"name"`
+	Interval string `json:"interval,omitempty"`
+	OnEvent  string `json:"on-event"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+// ListSchedulers returns all scheduler entries.
+func (c *Client) ListSchedulers(ctx context.Context) ([]Scheduler, error) {
+	var entries []Scheduler
+	err := c.restGET(ctx, "/system/scheduler", &entries)
+	return entries, err
+}
+
+// AddScheduler adds a scheduler entry. s.ID is ignored.
+func (c *Client) AddScheduler(ctx context.Context, s Scheduler) error {
+	s.ID = ""
+	return c.restPOST(ctx, "/system/scheduler/add", s, nil)
+}
+
+// RemoveScheduler removes the scheduler entry called name. A missing entry
+// is not an error.
+func (c *Client) RemoveScheduler(ctx context.Context, name string) error {
+	entries, err := c.ListSchedulers(ctx)
+	if err != nil {
+		return err
+	}
+	for _, s := range entries {
+		if s.Name == name {
+			return c.restPOST(ctx, "/system/scheduler/remove", map[string]string{".id": s.ID}, nil)
+		}
+	}
+	return nil
+}
+
+// PingResult is one line of /ping output.
+type PingResult struct {
+	Host     string `json:"host"`
+	Sent     string `json:"sent"`
+	Received string `json:"received"`
+}
+
+// Ping sends count echo requests to address and returns how many were
+// answered. With iface set and arp true it uses ARP ping on that interface,
+// which also finds hosts that drop ICMP.
+func (c *Client) Ping(ctx context.Context, address, iface string, count int, arp bool) (int, error) {
+	params := map[string]string{
+		"address": address,
+		"count":   strconv.Itoa(count),
+	}
+	if iface != "" {
+		params["interface"] = iface
+		if arp {
+			params["arp-ping"] = "yes"
+		}
+	}
+	var results []PingResult
+	if err := c.restPOST(ctx, "/ping", params, &results); err != nil {
+		return 0, fmt.Errorf("ping %s: %w", address, err)
+	}
+	if len(results) == 0 {
+		return 0, nil
+	}
+	received, _ := strconv.Atoi(results[len(results)-1].Received)
+	return received, nil
+}
+
 // ─── Log Operations ─────────────────────────────────────────────────────────
 
 // LogEntry represents a RouterOS log entry.
@@ -239,6 +239,36 @@ func TestCreateVethUpdate(t *testing.T) {
 	}
 }
 
+func TestCreateVethDualStack(t *testing.T) {
+	var body map[string]string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/interface/veth":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte("[]"))
+		case "/interface/veth/add":
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			w.WriteHeader(http.StatusOK)
+		default:
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+	})
+
+	client, server := newTestClient(t, handler)
+	defer server.Close()
+
+	err := client.CreateVeth(context.Background(), "veth0", "172.20.0.5/16,fd00::5/64", "172.20.0.1,fd00::1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if body["address"] != "172.20.0.5/16,fd00::5/64" {
+		t.Errorf("expected both addresses, got %q", body["address"])
+	}
+	if body["gateway"] != "172.20.0.1" || body["gateway6"] != "fd00::1" {
+		t.Errorf("expected gateway 172.20.0.1 and gateway6 fd00::1, got %q and %q", body["gateway"], body["gateway6"])
+	}
+}
+
 func TestListVeths(t *testing.T) {
 	veths := []NetworkInterface{
 		{ID: "*1", Name: "veth0", Address: "172.20.0.5/16"},
@@ -286,3 +316,195 @@ func TestClose(t *testing.T) {
 		t.Errorf("unexpected close error: %v", err)
 	}
 }
+
+func TestBridgeVLANAndVLANInterface(t *testing.T) {
+	var posts []map[string]string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/interface/bridge/vlan":
+			_ = json.NewEncoder(w).Encode([]BridgeVLAN{
+				{ID: "*1", Bridge: "trunk", VLANIDs: "60", Tagged: "trunk,ether2"},
+			})
+		case r.Method == http.MethodGet && r.URL.Path == "/interface/vlan":
+			_ = json.NewEncoder(w).Encode([]VLANInterface{
+				{ID: "*5", Name: "trunk.60", Interface: "trunk", VLANID: "60"},
+			})
+		case r.Method == http.MethodPost:
+			var body map[string]string
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			body["path"] = r.URL.Path
+			posts = append(posts, body)
+			_, _ = w.Write([]byte("{}"))
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	})
+
+	client, server := newTestClient(t, handler)
+	defer server.Close()
+	ctx := context.Background()
+
+	// Existing entry: members are merged, not duplicated
+	created, err := client.EnsureBridgeVLAN(ctx, "trunk", 60, []string{"trunk", "ether3"})
+	if err != nil || created {
+		t.Fatalf("EnsureBridgeVLAN existing: created=%v err=%v", created, err)
+	}
+	if len(posts) != 1 || posts[0]["path"] != "/interface/bridge/vlan/set" || posts[0]["tagged"] != "trunk,ether2,ether3" {
+		t.Errorf("merge posted %v", posts)
+	}
+
+	// New VLAN ID: a fresh entry is added
+	posts = nil
+	created, err = client.EnsureBridgeVLAN(ctx, "trunk", 70, []string{"trunk", "ether2"})
+	if err != nil || !created {
+		t.Fatalf("EnsureBridgeVLAN new: created=%v err=%v", created, err)
+	}
+	if len(posts) != 1 || posts[0]["path"] != "/interface/bridge/vlan/add" || posts[0]["vlan-ids"] != "70" {
+		t.Errorf("add posted %v", posts)
+	}
+
+	// VLAN interface: idempotent when it matches, an error when it doesn't
+	posts = nil
+	if created, err := client.CreateVLANInterface(ctx, "trunk.60", "trunk", 60); err != nil || created || len(posts) != 0 {
+		t.Errorf("CreateVLANInterface existing: created=%v err=%v posts=%v", created, err, posts)
+	}
+	if _, err := client.CreateVLANInterface(ctx, "trunk.60", "trunk", 61); err == nil {
+		t.Error("expected error for VLAN interface with a different vlan-id")
+	}
+	if created, err := client.CreateVLANInterface(ctx, "trunk.70", "trunk", 70); err != nil || !created {
+		t.Errorf("CreateVLANInterface new: created=%v err=%v", created, err)
+	}
+}
+
+func TestNATRules(t *testing.T) {
+	var posts []map[string]string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/ip/firewall/nat":
+			_ = json.NewEncoder(w).Encode([]NATRule{
+				{ID: "*1", Chain: "srcnat", Action: "masquerade", Comment: "defconf"},
+				{ID: "*2", Chain: "dstnat", Action: "dst-nat", Protocol: "tcp", DstPort: "8080",
+					ToAddresses: "172.20.0.5", ToPorts: "80", Comment: "mkube-pf: default/http"},
+			})
+		case r.Method == http.MethodPost:
+			var body map[string]string
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			body["path"] = r.URL.Path
+			posts = append(posts, body)
+			_, _ = w.Write([]byte("{}"))
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	})
+
+	client, server := newTestClient(t, handler)
+	defer server.Close()
+	ctx := context.Background()
+
+	rules, err := client.ListNATRules(ctx)
+	if err != nil || len(rules) != 2 || rules[1].ToAddresses != "172.20.0.5" {
+		t.Fatalf("ListNATRules = %+v, %v", rules, err)
+	}
+
+	err = client.AddNATRule(ctx, NATRule{ID: "*9", Chain: "dstnat", Action: "dst-nat", Protocol: "udp",
+		DstPort: "53", ToAddresses: "172.20.0.2", ToPorts: "53", Comment: "mkube-pf: dns/dns"})
+	if err != nil {
+		t.Fatalf("AddNATRule: %v", err)
+	}
+	add := posts[0]
+	if add["path"] != "/ip/firewall/nat/add" || add["to-addresses"] != "172.20.0.2" || add[".id"] != "" {
+		t.Errorf("add posted %v", add)
+	}
+	if _, ok := add["in-interface"]; ok {
+		t.Errorf("empty in-interface was sent: %v", add)
+	}
+
+	if err := client.RemoveNATRule(ctx, "*2"); err != nil || posts[1]["path"] != "/ip/firewall/nat/remove" || posts[1][".id"] != "*2" {
+		t.Errorf("RemoveNATRule posted %v, %v", posts[1:], err)
+	}
+}
+
+func TestSimpleQueues(t *testing.T) {
+	var posts []map[string]string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/queue/simple":
+			_ = json.NewEncoder(w).Encode([]SimpleQueue{
+				{ID: "*A", Name: "mkube-bw-veth-web-0", Target: "172.20.0.5/32", MaxLimit: "5000000/10000000", Rate: "1200/48000"},
+			})
+		case r.Method == http.MethodPost:
+			var body map[string]string
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			body["path"] = r.URL.Path
+			posts = append(posts, body)
+			_, _ = w.Write([]byte("{}"))
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	})
+
+	client, server := newTestClient(t, handler)
+	defer server.Close()
+	ctx := context.Background()
+
+	queues, err := client.ListSimpleQueues(ctx)
+	if err != nil || len(queues) != 1 || queues[0].Rate != "1200/48000" {
+		t.Fatalf("ListSimpleQueues = %+v, %v", queues, err)
+	}
+
+	err = client.AddSimpleQueue(ctx, SimpleQueue{ID: "*9", Name: "mkube-bw-veth-db-0", Target: "172.20.0.6/32", MaxLimit: "0/1000000", Rate: "1/1"})
+	if err != nil {
+		t.Fatalf("AddSimpleQueue: %v", err)
+	}
+	if add := posts[0]; add["path"] != "/queue/simple/add" || add["max-limit"] != "0/1000000" || add[".id"] != "" || add["rate"] != "" {
+		t.Errorf("add posted %v", add)
+	}
+
+	if err := client.SetSimpleQueue(ctx, "*A", "172.20.0.7/32", "1000/2000"); err != nil ||
+		posts[1]["path"] != "/queue/simple/set" || posts[1]["target"] != "172.20.0.7/32" {
+		t.Errorf("SetSimpleQueue posted %v, %v", posts[1:], err)
+	}
+	if err := client.RemoveSimpleQueue(ctx, "*A"); err != nil || posts[2]["path"] != "/queue/simple/remove" || posts[2][".id"] != "*A" {
+		t.Errorf("RemoveSimpleQueue posted %v, %v", posts[2:], err)
+	}
+}
+
+func TestSchedulers(t *testing.T) {
+	var posts []map[string]string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/system/scheduler":
+			_ = json.NewEncoder(w).Encode([]Scheduler{{ID: "*3", Name: "mkube-revert-lab", Interval: "00:01:30", OnEvent: ":log info x"}})
+		case r.Method == http.MethodPost:
+			var body map[string]string
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			body["path"] = r.URL.Path
+			posts = append(posts, body)
+			_, _ = w.Write([]byte("{}"))
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	})
+
+	client, server := newTestClient(t, handler)
+	defer server.Close()
+	ctx := context.Background()
+
+	if err := client.AddScheduler(ctx, Scheduler{ID: "*9", Name: "mkube-revert-dmz", Interval: "00:02:00", OnEvent: "/ip/address/remove [find]"}); err != nil {
+		t.Fatalf("AddScheduler: %v", err)
+	}
+	if add := posts[0]; add["path"] != "/system/scheduler/add" || add["on-event"] != "/ip/address/remove [find]" || add[".id"] != "" {
+		t.Errorf("add posted %v", add)
+	}
+
+	if err := client.RemoveScheduler(ctx, "mkube-revert-lab"); err != nil || len(posts) != 2 || posts[1][".id"] != "*3" {
+		t.Errorf("RemoveScheduler posted %v, %v", posts[1:], err)
+	}
+	if err := client.RemoveScheduler(ctx, "missing"); err != nil || len(posts) != 2 {
+		t.Errorf("removing a missing scheduler posted %v, %v", posts[2:], err)
+	}
+}
@@ -0,0 +1,87 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// NetNSProvider is implemented by runtimes that run each container in a
+// network namespace that exists before the container is created. The
+// provider passes the path to the network manager so the driver moves the
+// container end of the veth into it, and to the runtime in
+// ContainerSpec.NetNS so the workload starts there.
+type NetNSProvider interface {
+	PrepareNetNS(ctx context.Context, name string) (string, error)
+}
+
+// NamedNetNS wraps a runtime so every container gets the named network
+// namespace /var/run/netns/<container name>: PrepareNetNS creates it with
+// "ip netns add" and RemoveContainer deletes it. The wrapped runtime gets
+// the path in ContainerSpec.NetNS; stormd receives it as the netns field of
+// WorkloadCreateRequest.
+type NamedNetNS struct {
+	ContainerRuntime
+
+	dir string
+	ip  func(ctx context.Context, args ...string) error
+}
+
+// NewNamedNetNS wraps rt with per-container named network namespaces.
+func NewNamedNetNS(rt ContainerRuntime) *NamedNetNS {
+	return &NamedNetNS{ContainerRuntime: rt, dir: "/var/run/netns", ip: runIP}
+}
+
+// Unwrap returns the wrapped runtime.
+func (r *NamedNetNS) Unwrap() ContainerRuntime {
+	return r.ContainerRuntime
+}
+
+// PrepareNetNS creates name's namespace unless it already exists (left by
+// an earlier attempt) and returns its path.
+func (r *NamedNetNS) PrepareNetNS(ctx context.Context, name string) (string, error) {
+	path := filepath.Join(r.dir, name)
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+	if err := r.ip(ctx, "netns", "add", name); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// RemoveContainer removes the container, then its namespace. Namespaces
+// are named after the container, while id may be the runtime's own ID, so
+// the name is looked up first. A namespace that is already gone is not an
+// error.
+func (r *NamedNetNS) RemoveContainer(ctx context.Context, id string) error {
+	name := id
+	if cts, err := r.ContainerRuntime.ListContainers(ctx); err == nil {
+		for _, ct := range cts {
+			if ct.ID == id {
+				name = ct.Name
+				break
+			}
+		}
+	}
+	if err := r.ContainerRuntime.RemoveContainer(ctx, id); err != nil {
+		return err
+	}
+	if _, err := os.Stat(filepath.Join(r.dir, name)); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return r.ip(ctx, "netns", "delete", name)
+}
+
+// runIP runs ip(8) with args.
+func runIP(ctx context.Context, args ...string) error {
+	out, err := exec.CommandContext(ctx, "ip", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("ip %s: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
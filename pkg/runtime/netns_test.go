@@ -0,0 +1,67 @@
+package runtime
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// idRuntime is a ContainerRuntime whose container IDs differ from their
+// names, like stormd's.
+type idRuntime struct {
+	ContainerRuntime
+	containers []Container
+}
+
+func (r *idRuntime) ListContainers(context.Context) ([]Container, error) {
+	return r.containers, nil
+}
+
+func (r *idRuntime) RemoveContainer(_ context.Context, id string) error {
+	for i, ct := range r.containers {
+		if ct.ID == id {
+			r.containers = append(r.containers[:i], r.containers[i+1:]...)
+			return nil
+		}
+	}
+	return ErrNotFound
+}
+
+func TestNamedNetNSRemoveByName(t *testing.T) {
+	dir := t.TempDir()
+	var calls [][]string
+	r := &NamedNetNS{
+		ContainerRuntime: &idRuntime{containers: []Container{{ID: "c0ffee", Name: "default_web_app"}}},
+		dir:              dir,
+		ip: func(_ context.Context, args ...string) error {
+			calls = append(calls, args)
+			switch args[1] {
+			case "add":
+				return os.WriteFile(filepath.Join(dir, args[2]), nil, 0o644)
+			case "delete":
+				return os.Remove(filepath.Join(dir, args[2]))
+			}
+			return nil
+		},
+	}
+	ctx := context.Background()
+
+	path, err := r.PrepareNetNS(ctx, "default_web_app")
+	if err != nil || path != filepath.Join(dir, "default_web_app") {
+		t.Fatalf("PrepareNetNS = %q, %v", path, err)
+	}
+	if _, err := r.PrepareNetNS(ctx, "default_web_app"); err != nil {
+		t.Fatalf("PrepareNetNS again: %v", err)
+	}
+
+	// Removal by the runtime's ID deletes the namespace named after the container
+	if err := r.RemoveContainer(ctx, "c0ffee"); err != nil {
+		t.Fatalf("RemoveContainer: %v", err)
+	}
+	want := [][]string{{"netns", "add", "default_web_app"}, {"netns", "delete", "default_web_app"}}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("ip calls = %v, want %v", calls, want)
+	}
+}
@@ -93,6 +93,7 @@ type ContainerSpec struct {
 	Logging     string
 	StartOnBoot string
 	User        string // container user, e.g. "0:0" for root (needed for privileged ports)
+	NetNS       string // network namespace path to run in, from NetNSProvider.PrepareNetNS
 
 	// StormBase-specific fields
 	RestartPolicy string
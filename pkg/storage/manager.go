@@ -7,12 +7,14 @@ import (
 	"crypto/x509"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/go-containerregistry/pkg/authn"
@@ -40,6 +42,8 @@ type Manager struct {
 	images            map[string]*CachedImage // image ref -> cache entry
 	volumes           map[string]*ProvisionedVolume
 	registryTransport http.RoundTripper // TLS transport for local registry
+
+	resolver atomic.Pointer[net.Resolver] // name lookups for local registry pulls; nil = system resolver
 }
 
 // CachedImage tracks a cached OCI image tarball on the RouterOS filesystem.
@@ -89,10 +93,25 @@ func NewManager(cfg config.StorageConfig, registryCfg config.RegistryConfig, ros
 
 	// Load registry CA cert for TLS verification if configured
 	m.registryTransport = loadRegistryTransport(m.registryCfg.TLSCACertFile, "/etc/mkube/registry-ca.crt", log)
+	if t, ok := m.registryTransport.(*http.Transport); ok {
+		t.DialContext = m.dialRegistry
+	}
 
 	return m, nil
 }
 
+// SetResolver makes local registry pulls resolve names with r instead of
+// the system resolver. mkube's fallback DNS uses it so the registry still
+// resolves while its microdns is down.
+func (m *Manager) SetResolver(r *net.Resolver) {
+	m.resolver.Store(r)
+}
+
+func (m *Manager) dialRegistry(ctx context.Context, network, address string) (net.Conn, error) {
+	d := net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second, Resolver: m.resolver.Load()}
+	return d.DialContext(ctx, network, address)
+}
+
 // loadRegistryTransport returns an http.RoundTripper configured to trust
 // the registry's CA certificate. Falls back to skip-verify if no CA cert
 // is found (backward compatibility).
@@ -264,6 +264,89 @@ func (s *Store) ExportYAML(ctx context.Context) ([]byte, error) {
 		}
 	}
 
+	// Export IPAddressClaims
+	if s.IPAddressClaims != nil {
+		ipcKeys, err := s.IPAddressClaims.Keys(ctx, "")
+		if err != nil {
+			return nil, fmt.Errorf("listing IP address claims: %w", err)
+		}
+		for _, key := range ipcKeys {
+			raw, _, err := s.IPAddressClaims.Get(ctx, key)
+			if err != nil {
+				continue
+			}
+			var doc map[string]interface{}
+			if err := json.Unmarshal(raw, &doc); err != nil {
+				continue
+			}
+			doc["apiVersion"] = "v1"
+			doc["kind"] = "IPAddressClaim"
+			delete(doc, "status")
+			data, err := json.MarshalIndent(doc, "", "  ")
+			if err != nil {
+				continue
+			}
+			buf.WriteString("---\n")
+			buf.Write(data)
+			buf.WriteString("\n")
+		}
+	}
+
+	// Export PortForwards
+	if s.PortForwards != nil {
+		pfKeys, err := s.PortForwards.Keys(ctx, "")
+		if err != nil {
+			return nil, fmt.Errorf("listing port forwards: %w", err)
+		}
+		for _, key := range pfKeys {
+			raw, _, err := s.PortForwards.Get(ctx, key)
+			if err != nil {
+				continue
+			}
+			var doc map[string]interface{}
+			if err := json.Unmarshal(raw, &doc); err != nil {
+				continue
+			}
+			doc["apiVersion"] = "v1"
+			doc["kind"] = "PortForward"
+			delete(doc, "status")
+			data, err := json.MarshalIndent(doc, "", "  ")
+			if err != nil {
+				continue
+			}
+			buf.WriteString("---\n")
+			buf.Write(data)
+			buf.WriteString("\n")
+		}
+	}
+
+	// Export Secrets
+	if s.Secrets != nil {
+		secretKeys, err := s.Secrets.Keys(ctx, "")
+		if err != nil {
+			return nil, fmt.Errorf("listing secrets: %w", err)
+		}
+		for _, key := range secretKeys {
+			raw, _, err := s.Secrets.Get(ctx, key)
+			if err != nil {
+				continue
+			}
+			var doc map[string]interface{}
+			if err := json.Unmarshal(raw, &doc); err != nil {
+				continue
+			}
+			doc["apiVersion"] = "v1"
+			doc["kind"] = "Secret"
+			data, err := json.MarshalIndent(doc, "", "  ")
+			if err != nil {
+				continue
+			}
+			buf.WriteString("---\n")
+			buf.Write(data)
+			buf.WriteString("\n")
+		}
+	}
+
 	// Export JobRunners
 	if s.JobRunners != nil {
 		jrKeys, err := s.JobRunners.Keys(ctx, "")
@@ -320,6 +403,88 @@ func (s *Store) ExportYAML(ctx context.Context) ([]byte, error) {
 		}
 	}
 
+	// Export AlertRules
+	if s.AlertRules != nil {
+		arKeys, err := s.AlertRules.Keys(ctx, "")
+		if err != nil {
+			return nil, fmt.Errorf("listing alert rules: %w", err)
+		}
+		for _, key := range arKeys {
+			raw, _, err := s.AlertRules.Get(ctx, key)
+			if err != nil {
+				continue
+			}
+			var doc map[string]interface{}
+			if err := json.Unmarshal(raw, &doc); err != nil {
+				continue
+			}
+			doc["apiVersion"] = "v1"
+			doc["kind"] = "AlertRule"
+			delete(doc, "status")
+			data, err := json.MarshalIndent(doc, "", "  ")
+			if err != nil {
+				continue
+			}
+			buf.WriteString("---\n")
+			buf.Write(data)
+			buf.WriteString("\n")
+		}
+	}
+
+	// Export MutatingWebhookConfigurations
+	if s.MutatingWebhooks != nil {
+		whKeys, err := s.MutatingWebhooks.Keys(ctx, "")
+		if err != nil {
+			return nil, fmt.Errorf("listing mutating webhook configurations: %w", err)
+		}
+		for _, key := range whKeys {
+			raw, _, err := s.MutatingWebhooks.Get(ctx, key)
+			if err != nil {
+				continue
+			}
+			var doc map[string]interface{}
+			if err := json.Unmarshal(raw, &doc); err != nil {
+				continue
+			}
+			doc["apiVersion"] = "v1"
+			doc["kind"] = "MutatingWebhookConfiguration"
+			data, err := json.MarshalIndent(doc, "", "  ")
+			if err != nil {
+				continue
+			}
+			buf.WriteString("---\n")
+			buf.Write(data)
+			buf.WriteString("\n")
+		}
+	}
+
+	// Export ValidatingWebhookConfigurations
+	if s.ValidatingWebhooks != nil {
+		whKeys, err := s.ValidatingWebhooks.Keys(ctx, "")
+		if err != nil {
+			return nil, fmt.Errorf("listing validating webhook configurations: %w", err)
+		}
+		for _, key := range whKeys {
+			raw, _, err := s.ValidatingWebhooks.Get(ctx, key)
+			if err != nil {
+				continue
+			}
+			var doc map[string]interface{}
+			if err := json.Unmarshal(raw, &doc); err != nil {
+				continue
+			}
+			doc["apiVersion"] = "v1"
+			doc["kind"] = "ValidatingWebhookConfiguration"
+			data, err := json.MarshalIndent(doc, "", "  ")
+			if err != nil {
+				continue
+			}
+			buf.WriteString("---\n")
+			buf.Write(data)
+			buf.WriteString("\n")
+		}
+	}
+
 	return buf.Bytes(), nil
 }
 
@@ -428,6 +593,60 @@ func (s *Store) ImportYAML(ctx context.Context, data []byte) (int, int, error) {
 		}
 	}
 
+	// Import IPAddressClaims
+	if s.IPAddressClaims != nil {
+		ipcs, err := parseGenericDocs(data, "IPAddressClaim")
+		if err == nil {
+			for _, doc := range ipcs {
+				meta := doc["metadata"]
+				if m, ok := meta.(map[string]interface{}); ok {
+					ns, _ := m["namespace"].(string)
+					name, _ := m["name"].(string)
+					if ns != "" && name != "" {
+						raw, _ := json.Marshal(doc)
+						_, _ = s.IPAddressClaims.Put(ctx, ns+"."+name, raw)
+					}
+				}
+			}
+		}
+	}
+
+	// Import PortForwards
+	if s.PortForwards != nil {
+		pfs, err := parseGenericDocs(data, "PortForward")
+		if err == nil {
+			for _, doc := range pfs {
+				meta := doc["metadata"]
+				if m, ok := meta.(map[string]interface{}); ok {
+					ns, _ := m["namespace"].(string)
+					name, _ := m["name"].(string)
+					if ns != "" && name != "" {
+						raw, _ := json.Marshal(doc)
+						_, _ = s.PortForwards.Put(ctx, ns+"."+name, raw)
+					}
+				}
+			}
+		}
+	}
+
+	// Import Secrets
+	if s.Secrets != nil {
+		secrets, err := parseGenericDocs(data, "Secret")
+		if err == nil {
+			for _, doc := range secrets {
+				meta := doc["metadata"]
+				if m, ok := meta.(map[string]interface{}); ok {
+					ns, _ := m["namespace"].(string)
+					name, _ := m["name"].(string)
+					if ns != "" && name != "" {
+						raw, _ := json.Marshal(doc)
+						_, _ = s.Secrets.Put(ctx, ns+"."+name, raw)
+					}
+				}
+			}
+		}
+	}
+
 	// Import JobRunners
 	if s.JobRunners != nil {
 		jrs, err := parseGenericDocs(data, "JobRunner")
@@ -463,6 +682,57 @@ func (s *Store) ImportYAML(ctx context.Context, data []byte) (int, int, error) {
 		}
 	}
 
+	// Import AlertRules
+	if s.AlertRules != nil {
+		ars, err := parseGenericDocs(data, "AlertRule")
+		if err == nil {
+			for _, doc := range ars {
+				meta := doc["metadata"]
+				if m, ok := meta.(map[string]interface{}); ok {
+					name, _ := m["name"].(string)
+					if name != "" {
+						raw, _ := json.Marshal(doc)
+						_, _ = s.AlertRules.Put(ctx, name, raw)
+					}
+				}
+			}
+		}
+	}
+
+	// Import MutatingWebhookConfigurations
+	if s.MutatingWebhooks != nil {
+		whs, err := parseGenericDocs(data, "MutatingWebhookConfiguration")
+		if err == nil {
+			for _, doc := range whs {
+				meta := doc["metadata"]
+				if m, ok := meta.(map[string]interface{}); ok {
+					name, _ := m["name"].(string)
+					if name != "" {
+						raw, _ := json.Marshal(doc)
+						_, _ = s.MutatingWebhooks.Put(ctx, name, raw)
+					}
+				}
+			}
+		}
+	}
+
+	// Import ValidatingWebhookConfigurations
+	if s.ValidatingWebhooks != nil {
+		whs, err := parseGenericDocs(data, "ValidatingWebhookConfiguration")
+		if err == nil {
+			for _, doc := range whs {
+				meta := doc["metadata"]
+				if m, ok := meta.(map[string]interface{}); ok {
+					name, _ := m["name"].(string)
+					if name != "" {
+						raw, _ := json.Marshal(doc)
+						_, _ = s.ValidatingWebhooks.Put(ctx, name, raw)
+					}
+				}
+			}
+		}
+	}
+
 	return podCount, cmCount, nil
 }
 
@@ -595,12 +865,27 @@ func parseManifests(data []byte) ([]*corev1.Pod, []*corev1.ConfigMap, error) {
 		case "HostReservation":
 			// HostReservations are handled separately
 			continue
+		case "IPAddressClaim":
+			// IPAddressClaims are handled separately
+			continue
+		case "PortForward":
+			// PortForwards are handled separately
+			continue
+		case "Secret":
+			// Secrets are handled separately
+			continue
 		case "JobRunner":
 			// JobRunners are handled separately
 			continue
 		case "Job":
 			// Jobs are handled separately
 			continue
+		case "AlertRule":
+			// AlertRules are handled separately
+			continue
+		case "MutatingWebhookConfiguration", "ValidatingWebhookConfiguration":
+			// Webhook configurations are handled separately
+			continue
 		default:
 			var pod corev1.Pod
 			if err := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(doc), 4096).Decode(&pod); err != nil {
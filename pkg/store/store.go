@@ -3,6 +3,7 @@ package store
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -34,9 +35,18 @@ type Store struct {
 	ISCSICdroms            *Bucket
 	BootConfigs            *Bucket
 	HostReservations       *Bucket
+	IPAddressClaims        *Bucket
+	PortForwards           *Bucket
 	JobRunners             *Bucket
 	Jobs                   *Bucket
 	JobLogs                *Bucket
+	AlertRules             *Bucket
+	AlertSilences          *Bucket
+	MutatingWebhooks       *Bucket
+	ValidatingWebhooks     *Bucket
+	Secrets                *Bucket
+	DHCPDevices            *Bucket // DHCP device inventory, one entry per MAC
+	IPAM                   *Bucket // IP claims and node leases, with per-key history
 }
 
 // SetSyncHook sets a callback invoked after every successful local Put or Delete.
@@ -72,12 +82,30 @@ func (s *Store) BucketByName(name string) *Bucket {
 		return s.BootConfigs
 	case "HOSTRESERVATIONS":
 		return s.HostReservations
+	case "IPADDRESSCLAIMS":
+		return s.IPAddressClaims
+	case "PORTFORWARDS":
+		return s.PortForwards
 	case "JOBRUNNERS":
 		return s.JobRunners
 	case "JOBS":
 		return s.Jobs
 	case "JOBLOGS":
 		return s.JobLogs
+	case "ALERTRULES":
+		return s.AlertRules
+	case "ALERTSILENCES":
+		return s.AlertSilences
+	case "MUTATINGWEBHOOKS":
+		return s.MutatingWebhooks
+	case "VALIDATINGWEBHOOKS":
+		return s.ValidatingWebhooks
+	case "SECRETS":
+		return s.Secrets
+	case "DHCPDEVICES":
+		return s.DHCPDevices
+	case "IPAM":
+		return s.IPAM
 	default:
 		return nil
 	}
@@ -211,6 +239,14 @@ func (s *Store) initAllBuckets(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	s.IPAddressClaims, err = s.initBucket(ctx, "IPADDRESSCLAIMS", s.replicas, 0)
+	if err != nil {
+		return err
+	}
+	s.PortForwards, err = s.initBucket(ctx, "PORTFORWARDS", s.replicas, 0)
+	if err != nil {
+		return err
+	}
 	s.JobRunners, err = s.initBucket(ctx, "JOBRUNNERS", s.replicas, 0)
 	if err != nil {
 		return err
@@ -223,9 +259,45 @@ func (s *Store) initAllBuckets(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	s.AlertRules, err = s.initBucket(ctx, "ALERTRULES", s.replicas, 0)
+	if err != nil {
+		return err
+	}
+	s.AlertSilences, err = s.initBucket(ctx, "ALERTSILENCES", s.replicas, 0)
+	if err != nil {
+		return err
+	}
+	s.MutatingWebhooks, err = s.initBucket(ctx, "MUTATINGWEBHOOKS", s.replicas, 0)
+	if err != nil {
+		return err
+	}
+	s.ValidatingWebhooks, err = s.initBucket(ctx, "VALIDATINGWEBHOOKS", s.replicas, 0)
+	if err != nil {
+		return err
+	}
+	s.Secrets, err = s.initBucket(ctx, "SECRETS", s.replicas, 0)
+	if err != nil {
+		return err
+	}
+	s.DHCPDevices, err = s.initBucket(ctx, "DHCPDEVICES", s.replicas, 0)
+	if err != nil {
+		return err
+	}
+	s.IPAM, err = s.initBucketConfig(ctx, jetstream.KeyValueConfig{
+		Bucket:   "IPAM",
+		Replicas: s.replicas,
+		History:  ipamHistory,
+	})
+	if err != nil {
+		return err
+	}
 	return nil
 }
 
+// ipamHistory is how many revisions of each IP claim the IPAM bucket keeps,
+// so allocation history can be read back from the KV store (max 64).
+const ipamHistory = 64
+
 // reinitBuckets re-creates KV bucket handles after a NATS reconnection.
 // The NATS server may have restarted with empty JetStream, so the old
 // bucket handles would point to non-existent streams.
@@ -251,7 +323,11 @@ func (s *Store) initBucket(ctx context.Context, name string, replicas int, ttl t
 	if ttl > 0 {
 		kvCfg.TTL = ttl
 	}
+	return s.initBucketConfig(ctx, kvCfg)
+}
 
+func (s *Store) initBucketConfig(ctx context.Context, kvCfg jetstream.KeyValueConfig) (*Bucket, error) {
+	name := kvCfg.Bucket
 	kv, err := s.js.CreateOrUpdateKeyValue(ctx, kvCfg)
 	if err != nil {
 		return nil, fmt.Errorf("creating KV bucket %s: %w", name, err)
@@ -329,6 +405,79 @@ func (b *Bucket) Delete(ctx context.Context, key string) error {
 	return nil
 }
 
+// Create stores value at key only if the key does not exist (or was
+// deleted). Returns an error matching IsConflict if it already exists.
+// Fires the sync hook after a successful write.
+func (b *Bucket) Create(ctx context.Context, key string, value []byte) (uint64, error) {
+	rev, err := b.kv.Create(ctx, key, value)
+	if err != nil {
+		return 0, err
+	}
+	if b.store != nil && b.store.syncHook != nil {
+		b.store.syncHook(b.name, key, "put", value)
+	}
+	return rev, nil
+}
+
+// Update stores value at key only if the key's current revision is
+// lastRevision (compare-and-swap). Returns an error matching IsConflict if
+// another writer got there first. Fires the sync hook after a successful write.
+func (b *Bucket) Update(ctx context.Context, key string, value []byte, lastRevision uint64) (uint64, error) {
+	rev, err := b.kv.Update(ctx, key, value, lastRevision)
+	if err != nil {
+		return 0, err
+	}
+	if b.store != nil && b.store.syncHook != nil {
+		b.store.syncHook(b.name, key, "put", value)
+	}
+	return rev, nil
+}
+
+// HistoryEntry is one revision of a key, as returned by History.
+type HistoryEntry struct {
+	Value    []byte
+	Revision uint64
+	Created  time.Time
+	Deleted  bool // delete or purge marker
+}
+
+// History returns the retained revisions of key, oldest first. The number
+// of revisions kept depends on the bucket's history setting.
+func (b *Bucket) History(ctx context.Context, key string) ([]HistoryEntry, error) {
+	entries, err := b.kv.History(ctx, key)
+	if err != nil {
+		if errors.Is(err, jetstream.ErrKeyNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	out := make([]HistoryEntry, 0, len(entries))
+	for _, e := range entries {
+		out = append(out, HistoryEntry{
+			Value:    e.Value(),
+			Revision: e.Revision(),
+			Created:  e.Created(),
+			Deleted:  e.Operation() != jetstream.KeyValuePut,
+		})
+	}
+	return out, nil
+}
+
+// IsNotFound reports whether err means the key does not exist.
+func IsNotFound(err error) bool {
+	return errors.Is(err, jetstream.ErrKeyNotFound) || errors.Is(err, jetstream.ErrKeyDeleted)
+}
+
+// IsConflict reports whether err is a failed Create (key exists) or a
+// failed Update (revision changed underneath the caller).
+func IsConflict(err error) bool {
+	if errors.Is(err, jetstream.ErrKeyExists) {
+		return true
+	}
+	var apiErr *jetstream.APIError
+	return errors.As(err, &apiErr) && apiErr.ErrorCode == jetstream.JSErrCodeStreamWrongLastSequence
+}
+
 // DeleteFromPeer removes a key received from a peer node.
 // Does NOT fire the sync hook (prevents ping-pong replication).
 func (b *Bucket) DeleteFromPeer(ctx context.Context, key string) error {
@@ -322,3 +322,44 @@ func TestListJSON(t *testing.T) {
 		t.Logf("item: %s", data)
 	}
 }
+
+func TestBucketCompareAndSwap(t *testing.T) {
+	s, cleanup := testStore(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	rev, err := s.IPAM.Create(ctx, "claims.g10.192-168-10-5", []byte(`{"owner":"a"}`))
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if _, err := s.IPAM.Create(ctx, "claims.g10.192-168-10-5", []byte(`{"owner":"b"}`)); !IsConflict(err) {
+		t.Fatalf("second Create: expected conflict, got %v", err)
+	}
+
+	newRev, err := s.IPAM.Update(ctx, "claims.g10.192-168-10-5", []byte(`{"owner":"b"}`), rev)
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if _, err := s.IPAM.Update(ctx, "claims.g10.192-168-10-5", []byte(`{"owner":"c"}`), rev); !IsConflict(err) {
+		t.Fatalf("stale Update: expected conflict, got %v", err)
+	}
+
+	if err := s.IPAM.Delete(ctx, "claims.g10.192-168-10-5"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, _, err := s.IPAM.Get(ctx, "claims.g10.192-168-10-5"); !IsNotFound(err) {
+		t.Fatalf("Get after delete: expected not found, got %v", err)
+	}
+
+	history, err := s.IPAM.History(ctx, "claims.g10.192-168-10-5")
+	if err != nil {
+		t.Fatalf("History: %v", err)
+	}
+	if len(history) != 3 || history[1].Revision != newRev || !history[2].Deleted {
+		t.Fatalf("unexpected history: %+v", history)
+	}
+	if string(history[0].Value) != `{"owner":"a"}` {
+		t.Errorf("first revision = %s", history[0].Value)
+	}
+}
@@ -88,6 +88,7 @@ func (c *Client) CreateContainer(ctx context.Context, spec runtime.ContainerSpec
 		MemoryLimit:   spec.MemoryLimit,
 		Command:       spec.Command,
 		Env:           spec.Env,
+		Netns:         spec.NetNS,
 	}
 
 	for _, p := range spec.Ports {
@@ -320,6 +320,7 @@ type WorkloadCreateRequest struct {
 	Privileged    bool                   `protobuf:"varint,11,opt,name=privileged,proto3" json:"privileged,omitempty"`
 	Devices       []string               `protobuf:"bytes,12,rep,name=devices,proto3" json:"devices,omitempty"`           // host device paths (e.g. "/dev/vfio/vfio")
 	Capabilities  []string               `protobuf:"bytes,13,rep,name=capabilities,proto3" json:"capabilities,omitempty"` // Linux capabilities (e.g. "SYS_RAWIO")
+	Netns         string                 `protobuf:"bytes,14,opt,name=netns,proto3" json:"netns,omitempty"`                // network namespace path to run in (e.g. "/var/run/netns/web")
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -445,6 +446,13 @@ func (x *WorkloadCreateRequest) GetCapabilities() []string {
 	return nil
 }
 
+func (x *WorkloadCreateRequest) GetNetns() string {
+	if x != nil {
+		return x.Netns
+	}
+	return ""
+}
+
 type PortMapping struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	HostPort      uint32                 `protobuf:"varint,1,opt,name=host_port,json=hostPort,proto3" json:"host_port,omitempty"`
@@ -4312,7 +4320,7 @@ const file_stormd_proto_rawDesc = "" +
 	"\x04name\x18\x01 \x01(\tR\x04name\"L\n" +
 	"\x16WorkloadActionResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\tR\amessage\"\xa0\x03\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"\xb6\x03\n" +
 	"\x15WorkloadCreateRequest\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
 	"\x04kind\x18\x02 \x01(\tR\x04kind\x12\x14\n" +
@@ -4329,7 +4337,8 @@ const file_stormd_proto_rawDesc = "" +
 	"privileged\x18\v \x01(\bR\n" +
 	"privileged\x12\x18\n" +
 	"\adevices\x18\f \x03(\tR\adevices\x12\"\n" +
-	"\fcapabilities\x18\r \x03(\tR\fcapabilities\"m\n" +
+	"\fcapabilities\x18\r \x03(\tR\fcapabilities\x12\x14\n" +
+	"\x05netns\x18\x0e \x01(\tR\x05netns\"m\n" +
 	"\vPortMapping\x12\x1b\n" +
 	"\thost_port\x18\x01 \x01(\rR\bhostPort\x12%\n" +
 	"\x0econtainer_port\x18\x02 \x01(\rR\rcontainerPort\x12\x1a\n" +